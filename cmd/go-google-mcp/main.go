@@ -1,31 +1,61 @@
 package main
 
 import (
+	"bytes"
+	"cmp"
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"mime"
+	"net/http"
+	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/matheusbuniotto/go-google-mcp/pkg/auth"
+	"github.com/matheusbuniotto/go-google-mcp/pkg/config"
+	"github.com/matheusbuniotto/go-google-mcp/pkg/logging"
+	"github.com/matheusbuniotto/go-google-mcp/pkg/mailmerge"
+	"github.com/matheusbuniotto/go-google-mcp/pkg/metrics"
 	activitysvc "github.com/matheusbuniotto/go-google-mcp/pkg/services/activity"
 	calendarsvc "github.com/matheusbuniotto/go-google-mcp/pkg/services/calendar"
+	chatsvc "github.com/matheusbuniotto/go-google-mcp/pkg/services/chat"
 	docssvc "github.com/matheusbuniotto/go-google-mcp/pkg/services/docs"
 	drivesvc "github.com/matheusbuniotto/go-google-mcp/pkg/services/drive"
 	gmailsvc "github.com/matheusbuniotto/go-google-mcp/pkg/services/gmail"
 	keepsvc "github.com/matheusbuniotto/go-google-mcp/pkg/services/keep"
+	memorysvc "github.com/matheusbuniotto/go-google-mcp/pkg/services/memory"
 	peoplesvc "github.com/matheusbuniotto/go-google-mcp/pkg/services/people"
 	sheetssvc "github.com/matheusbuniotto/go-google-mcp/pkg/services/sheets"
 	taskssvc "github.com/matheusbuniotto/go-google-mcp/pkg/services/tasks"
+	"github.com/matheusbuniotto/go-google-mcp/pkg/transport"
 	"google.golang.org/api/calendar/v3"
+	chatapi "google.golang.org/api/chat/v1"
 	"google.golang.org/api/docs/v1"
 	"google.golang.org/api/drive/v3"
 	"google.golang.org/api/driveactivity/v2"
 	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/googleapi"
 	keepapi "google.golang.org/api/keep/v1"
+	"google.golang.org/api/option"
 	"google.golang.org/api/people/v1"
 	"google.golang.org/api/sheets/v4"
 	"google.golang.org/api/tasks/v1"
@@ -39,152 +69,356 @@ func main() {
 	}
 
 	// Normal server mode
-	credentialsFile := flag.String("creds", "", "Path to Google Service Account JSON file (optional)")
+	configPath := flag.String("config", os.Getenv("GO_GOOGLE_MCP_CONFIG"), "Settings file (default ~/.go-google-mcp/config.yaml if it exists); flags and environment variables override it (env GO_GOOGLE_MCP_CONFIG)")
+	authMode := flag.String("auth-mode", os.Getenv("GO_GOOGLE_MCP_AUTH_MODE"), "How to authenticate: 'oauth' (the 'auth login' token), 'service-account' (-creds), 'adc' (application default credentials) or 'metadata' (the service account attached on GCE or Cloud Run); by default the first available of -creds, the stored token and ADC (env GO_GOOGLE_MCP_AUTH_MODE)")
+	credentialsFile := flag.String("creds", os.Getenv("GO_GOOGLE_MCP_CREDS"), "Path to Google Service Account JSON file (optional; env GO_GOOGLE_MCP_CREDS)")
+	account := flag.String("account", os.Getenv("GO_GOOGLE_MCP_ACCOUNT"), "Stored account (see 'auth login --account') or alias (account_aliases in the config file) that tools use by default; with several accounts, tools take an account argument to use another (env GO_GOOGLE_MCP_ACCOUNT)")
+	tokenStorage := flag.String("token-storage", envOr("GO_GOOGLE_MCP_TOKEN_STORAGE", auth.StorageFile), "Where OAuth tokens are stored: 'file', 'keychain' (the OS keychain) or 'encrypted' (files encrypted with the GO_GOOGLE_MCP_TOKEN_KEY passphrase); env GO_GOOGLE_MCP_TOKEN_STORAGE")
+	impersonate := flag.String("impersonate", os.Getenv("GO_GOOGLE_MCP_IMPERSONATE"), "With a service account (-creds) that has domain-wide delegation, act as this Workspace user and let tools take an as_user argument to act as another (env GO_GOOGLE_MCP_IMPERSONATE)")
+	workspace := flag.Bool("workspace", envBool("GO_GOOGLE_MCP_WORKSPACE"), "Also request the Workspace-only Keep and Chat scopes with application default credentials or a service account (OAuth users log in with 'auth login --workspace'; env GO_GOOGLE_MCP_WORKSPACE)")
+	servicesList := flag.String("services", os.Getenv("GO_GOOGLE_MCP_SERVICES"), "Comma-separated services to enable, e.g. 'drive,calendar': only their tools are registered and only their scopes requested (default all; env GO_GOOGLE_MCP_SERVICES). "+serviceNamesHelp)
+	readOnly := flag.Bool("read-only", envBool("GO_GOOGLE_MCP_READ_ONLY"), "Only register tools that do not modify anything (env GO_GOOGLE_MCP_READ_ONLY)")
+	allowTools := flag.String("allow-tools", os.Getenv("GO_GOOGLE_MCP_ALLOW_TOOLS"), "Comma-separated tool names or patterns (e.g. 'gmail_*,drive_search') to register; all others are left out (env GO_GOOGLE_MCP_ALLOW_TOOLS)")
+	denyTools := flag.String("deny-tools", os.Getenv("GO_GOOGLE_MCP_DENY_TOOLS"), "Comma-separated tool names or patterns (e.g. '*_delete_*,gmail_send_*') not to register (env GO_GOOGLE_MCP_DENY_TOOLS)")
+	confirm := flag.Bool("confirm", envBool("GO_GOOGLE_MCP_CONFIRM"), "Hold destructive tool calls (send, trash, delete, overwrite...) as pending actions until confirm_action runs them (env GO_GOOGLE_MCP_CONFIRM)")
+	localRoot := flag.String("local-root", envOr("GO_GOOGLE_MCP_LOCAL_ROOT", "."), "Directory that tools reading or writing local files, such as drive_upload_file, drive_download_file and gmail_send_email attachments, are limited to (default: the working directory); the server's token, client secrets and credentials files are always refused (env GO_GOOGLE_MCP_LOCAL_ROOT)")
+	timeout := flag.Duration("timeout", envDuration("GO_GOOGLE_MCP_TIMEOUT", 30*time.Second), "Default time limit for a tool call, 0 for none (env GO_GOOGLE_MCP_TIMEOUT)")
+	toolTimeouts := flag.String("tool-timeouts", os.Getenv("GO_GOOGLE_MCP_TOOL_TIMEOUTS"), "Per-tool time limits overriding -timeout, e.g. 'drive_download_file=10m,sheets_query=1m' (env GO_GOOGLE_MCP_TOOL_TIMEOUTS)")
+	logLevel := flag.String("log-level", envOr("GO_GOOGLE_MCP_LOG_LEVEL", "info"), "Log verbosity: debug (includes tool arguments), info (one line per tool call), warn or error (env GO_GOOGLE_MCP_LOG_LEVEL)")
+	logFile := flag.String("log-file", os.Getenv("GO_GOOGLE_MCP_LOG_FILE"), "Append logs to this file instead of stderr (env GO_GOOGLE_MCP_LOG_FILE)")
+	metricsAddr := flag.String("metrics-addr", os.Getenv("GO_GOOGLE_MCP_METRICS_ADDR"), "Serve Prometheus metrics at http://<addr>/metrics, e.g. 'localhost:9464'; off when empty (env GO_GOOGLE_MCP_METRICS_ADDR)")
+	output := flag.String("output", envOr("GO_GOOGLE_MCP_OUTPUT", "text"), "Tool result format: 'text' (human-readable) or 'json' (a {tool, ok, data|text|error} JSON envelope; env GO_GOOGLE_MCP_OUTPUT)")
 	flag.Parse()
 
-	if *credentialsFile != "" {
-		fmt.Fprintf(os.Stderr, "Using credentials file: %s\n", *credentialsFile)
-	}
-
-	// Initialize Auth
-	// Keep scope omitted so personal accounts can log in; Keep tools return a clear message if used without Workspace.
-	scopes := []string{
-		drive.DriveScope,
-		gmail.GmailReadonlyScope,
-		gmail.GmailSendScope,
-		gmail.GmailModifyScope,
-		calendar.CalendarScope,
-		sheets.SpreadsheetsScope,
-		people.ContactsScope,
-		docs.DocumentsScope,
-		tasks.TasksScope,
-		driveactivity.DriveActivityReadonlyScope,
-	}
-	opts, err := auth.GetClientOptions(context.Background(), *credentialsFile, scopes)
+	cfg, err := loadConfig(*configPath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Auth error: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Invalid -config: %v\n", err)
 		os.Exit(1)
 	}
-
-	// Initialize Drive Service
-	driveService, err := drivesvc.New(context.Background(), opts...)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to create Drive service: %v\n", err)
+	if err := applyConfigFlags(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config file setting %v\n", err)
 		os.Exit(1)
 	}
 
-	// Initialize Gmail Service
-	gmailService, err := gmailsvc.New(context.Background(), opts...)
+	level, err := logging.ParseLevel(*logLevel)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to create Gmail service: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Invalid -log-level: %v\n", err)
 		os.Exit(1)
 	}
-
-	// Initialize Calendar Service
-	calendarService, err := calendarsvc.New(context.Background(), opts...)
+	logOut, err := logging.Open(*logFile)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to create Calendar service: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Invalid -log-file: %v\n", err)
 		os.Exit(1)
 	}
+	defer func() {
+		_ = logOut.Close()
+	}()
+	slog.SetDefault(logging.New(logOut, level))
 
-	// Initialize Sheets Service
-	sheetsService, err := sheetssvc.New(context.Background(), opts...)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to create Sheets service: %v\n", err)
+	if *output != "text" && *output != "json" {
+		slog.Error("invalid -output: must be 'text' or 'json'", "output", *output)
 		os.Exit(1)
 	}
-
-	// Initialize People Service
-	peopleService, err := peoplesvc.New(context.Background(), opts...)
+	perToolTimeouts, err := parseToolTimeouts(*toolTimeouts)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to create People service: %v\n", err)
+		slog.Error("invalid -tool-timeouts", "error", err)
 		os.Exit(1)
 	}
-
-	// Initialize Docs Service
-	docsService, err := docssvc.New(context.Background(), opts...)
+	services, err := parseServices(*servicesList)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to create Docs service: %v\n", err)
+		slog.Error("invalid -services", "error", err)
 		os.Exit(1)
 	}
+	allowPatterns, denyPatterns := splitList(*allowTools), splitList(*denyTools)
+	for _, pattern := range slices.Concat(allowPatterns, denyPatterns) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			slog.Error("invalid tool pattern", "pattern", pattern, "error", err)
+			os.Exit(1)
+		}
+	}
 
-	// Initialize Tasks Service
-	tasksService, err := taskssvc.New(context.Background(), opts...)
+	configDir, _ := auth.GetConfigDir()
+	local, err := newLocalFiles(*localRoot, configDir, *credentialsFile, *configPath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to create Tasks service: %v\n", err)
+		slog.Error("invalid -local-root", "error", err)
 		os.Exit(1)
 	}
 
-	// Initialize Activity Service (Drive Activity API)
-	activityService, err := activitysvc.New(context.Background(), opts...)
+	if *credentialsFile != "" {
+		slog.Info("using credentials file", "path", *credentialsFile)
+	}
+
+	// Initialize Auth
+	if err := auth.SetTokenStorage(*tokenStorage, os.Getenv("GO_GOOGLE_MCP_TOKEN_KEY")); err != nil {
+		slog.Error("invalid -token-storage", "error", err)
+		os.Exit(1)
+	}
+	scopes := oauthScopes(services, *workspace)
+	transportCfg := transportConfig(cfg)
+	accounts, err := auth.ListAccounts()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to create Activity service: %v\n", err)
+		slog.Warn("unable to list stored accounts", "error", err)
+	}
+	if err := auth.ValidateMode(*authMode); err != nil {
+		slog.Error("invalid -auth-mode", "error", err)
 		os.Exit(1)
 	}
-
-	// Initialize Keep Service (Google Keep API)
-	keepService, err := keepsvc.New(context.Background(), opts...)
+	if *impersonate != "" && *authMode != auth.ModeAuto && *authMode != auth.ModeServiceAccount {
+		slog.Error("invalid -impersonate: domain-wide delegation needs a service account", "auth_mode", *authMode, "required", auth.ModeServiceAccount)
+		os.Exit(1)
+	}
+	// Named accounts (or a default one picked with -account) switch to per-call account selection.
+	// A service account (-creds) always wins.
+	oauthMode := *authMode == auth.ModeAuto && *credentialsFile == "" || *authMode == auth.ModeOAuth
+	multiAccount := oauthMode && *impersonate == "" && (len(accounts) > 0 || *account != "")
+	aliases, err := newAccountAliases(cfg.AccountAliases)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to create Keep service: %v\n", err)
+		slog.Error("invalid account_aliases", "error", err)
 		os.Exit(1)
 	}
+	*account = aliases.resolve(*account)
+	if *account != "" {
+		if err := auth.ValidateAccountName(*account); err != nil {
+			slog.Error("invalid -account", "error", err)
+			os.Exit(1)
+		}
+	}
+	var opts []option.ClientOption
+	if *impersonate != "" {
+		// Domain-wide delegation: tokens are minted per user, so authentication happens in our own
+		// transport (in front of the retry and rate limit middleware) rather than in the client options.
+		rt, err := auth.NewImpersonationTransport(*credentialsFile, *impersonate, scopes, transport.New(nil, transportCfg))
+		if err != nil {
+			slog.Error("authentication failed", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("impersonating workspace user", "user", *impersonate)
+		opts = []option.ClientOption{option.WithHTTPClient(&http.Client{Transport: rt})}
+	} else if multiAccount {
+		// Several stored accounts: the token is chosen per request (account argument or -account), so
+		// authentication happens in our own transport, like impersonation.
+		rt := auth.NewAccountTransport(*account, scopes, transport.New(nil, transportCfg))
+		opts = []option.ClientOption{option.WithHTTPClient(&http.Client{Transport: rt})}
+	} else {
+		opts, err = auth.GetClientOptions(context.Background(), *authMode, *credentialsFile, scopes)
+		if err != nil {
+			slog.Error("authentication failed", "error", err)
+			os.Exit(1)
+		}
+		// Send every API call through the shared retry and rate limit middleware
+		opts, err = transport.ClientOptions(context.Background(), transportCfg, opts...)
+		if err != nil {
+			slog.Error("authentication failed", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Google API clients are created on first use (see serviceRegistry), so a service that cannot be
+	// set up only fails the calls that need it.
+	var (
+		driveService    *drivesvc.DriveService
+		gmailService    *gmailsvc.GmailService
+		calendarService *calendarsvc.CalendarService
+		sheetsService   *sheetssvc.SheetsService
+		peopleService   *peoplesvc.PeopleService
+		docsService     *docssvc.DocsService
+		tasksService    *taskssvc.Service
+		activityService *activitysvc.Service
+		keepService     *keepsvc.Service
+		chatService     *chatsvc.Service
+		memoryService   *memorysvc.Service
+	)
+	registry := newServiceRegistry(map[string]func() error{
+		"drive":    func() (err error) { driveService, err = drivesvc.New(context.Background(), opts...); return err },
+		"gmail":    func() (err error) { gmailService, err = gmailsvc.New(context.Background(), opts...); return err },
+		"calendar": func() (err error) { calendarService, err = calendarsvc.New(context.Background(), opts...); return err },
+		"sheets":   func() (err error) { sheetsService, err = sheetssvc.New(context.Background(), opts...); return err },
+		"people":   func() (err error) { peopleService, err = peoplesvc.New(context.Background(), opts...); return err },
+		"docs":     func() (err error) { docsService, err = docssvc.New(context.Background(), opts...); return err },
+		"tasks":    func() (err error) { tasksService, err = taskssvc.New(context.Background(), opts...); return err },
+		"activity": func() (err error) { activityService, err = activitysvc.New(context.Background(), opts...); return err },
+		"keep":     func() (err error) { keepService, err = keepsvc.New(context.Background(), opts...); return err },
+		"chat":     func() (err error) { chatService, err = chatsvc.New(context.Background(), opts...); return err },
+		"memory":   func() (err error) { memoryService, err = memorysvc.New(context.Background(), opts...); return err },
+	})
 
 	// Initialize MCP Server
-	s := server.NewMCPServer(
-		"go-google-mcp",
-		"0.1.0",
+	hooks := &server.Hooks{}
+	serverOpts := []server.ServerOption{
 		server.WithResourceCapabilities(true, true),
 		server.WithToolCapabilities(true),
 		server.WithLogging(),
-	)
+		server.WithHooks(hooks),
+	}
+	if *output == "json" {
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(jsonOutputMiddleware))
+	}
+	serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(loggingMiddleware), server.WithToolHandlerMiddleware(metricsMiddleware))
+	confirmer := newActionConfirmer()
+	if *confirm {
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(confirmer.middleware))
+	}
+	serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(timeoutMiddleware(*timeout, perToolTimeouts)))
+	if *impersonate != "" {
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(asUserMiddleware))
+	}
+	if multiAccount {
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(accountMiddleware(aliases)))
+	}
+	serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(registry.middleware))
+	s := server.NewMCPServer("go-google-mcp", "0.1.0", serverOpts...)
 
 	// Tool: Ping
 	s.AddTool(mcp.NewTool("ping",
 		mcp.WithDescription("Ping the server to check availability"),
+		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithString("message", mcp.Required(), mcp.Description("Message to echo back")),
 	), pingHandler)
 
+	// Tool: Health Check
+	pingers := map[string]func(ctx context.Context) error{
+		"drive":    func(ctx context.Context) error { return driveService.Ping(ctx) },
+		"activity": func(ctx context.Context) error { return activityService.Ping(ctx) },
+		"gmail":    func(ctx context.Context) error { return gmailService.Ping(ctx) },
+		"calendar": func(ctx context.Context) error { return calendarService.Ping(ctx) },
+		"sheets":   func(ctx context.Context) error { return sheetsService.Ping(ctx) },
+		"people":   func(ctx context.Context) error { return peopleService.Ping(ctx) },
+		"docs":     func(ctx context.Context) error { return docsService.Ping(ctx) },
+		"tasks":    func(ctx context.Context) error { return tasksService.Ping(ctx) },
+		"keep":     func(ctx context.Context) error { return keepService.Ping(ctx) },
+		"chat":     func(ctx context.Context) error { return chatService.Ping(ctx) },
+		"memory":   func(ctx context.Context) error { return memoryService.Ping(ctx) },
+	}
+	s.AddTool(mcp.NewTool("health_check",
+		mcp.WithDescription("Check which Google services work for the current account: for each enabled service, makes a cheap API call and reports ok or why not (API not enabled, scope not granted, quota...)"),
+		mcp.WithReadOnlyHintAnnotation(true),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var names []string
+		for _, name := range slices.Concat(defaultServices, workspaceServices) {
+			if !serviceEnabled(services, name) {
+				continue
+			}
+			names = append(names, name)
+			if name == "drive" {
+				names = append(names, "activity")
+			}
+		}
+		statuses := make([]string, len(names))
+		var wg sync.WaitGroup
+		for i, name := range names {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := registry.ensure(name); err != nil {
+					statuses[i] = "unavailable: " + err.Error()
+					return
+				}
+				pingCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+				defer cancel()
+				scopeService := name
+				if name == "activity" {
+					scopeService = "drive"
+				}
+				requested := !slices.ContainsFunc(serviceScopes[scopeService], func(scope string) bool { return !slices.Contains(scopes, scope) })
+				statuses[i] = serviceHealth(pingers[name](pingCtx), requested)
+			}()
+		}
+		wg.Wait()
+		var b strings.Builder
+		for i, name := range names {
+			fmt.Fprintf(&b, "%-9s %s\n", name, statuses[i])
+		}
+		return mcp.NewToolResultText(strings.TrimSuffix(b.String(), "\n")), nil
+	})
+
+	// Tool: Accounts List
+	s.AddTool(mcp.NewTool("accounts_list",
+		mcp.WithDescription("List the Google accounts logged in on this server and their aliases, for the account argument of other tools"),
+		mcp.WithReadOnlyHintAnnotation(true),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !oauthMode || *impersonate != "" {
+			return mcp.NewToolResultText("This server does not use stored accounts: it authenticates with a service account or application default credentials."), nil
+		}
+		accounts, err := auth.ListAccounts()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list accounts: %v", err)), nil
+		}
+		var lines []string
+		if _, err := auth.LoadToken(); err == nil {
+			line := "(default): logged in without --account"
+			if *account == "" {
+				line += "; used when no account is given"
+			}
+			lines = append(lines, line)
+		}
+		for _, a := range accounts {
+			line := a
+			if names := aliases.of(a); len(names) > 0 {
+				line += " (alias " + strings.Join(names, ", ") + ")"
+			}
+			if a == *account {
+				line += ": used when no account is given"
+			}
+			lines = append(lines, line)
+		}
+		if len(lines) == 0 {
+			return mcp.NewToolResultText("No stored accounts. Log in with 'go-google-mcp auth login --account you@example.com'."), nil
+		}
+		text := strings.Join(lines, "\n")
+		if !multiAccount {
+			text += "\n\nTools have no account argument: add accounts with 'go-google-mcp auth login --account you@example.com' and restart the server."
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
 	// Tool: Drive Search
 	s.AddTool(mcp.NewTool("drive_search",
 		mcp.WithDescription("Search for files in Google Drive. Use raw 'query' (Drive query syntax) OR helper args. Use content_contains for fullText search; set include_snippet to get a short preview without reading the whole file."),
+		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithNumber("limit", mcp.Description("Maximum number of files to return (default 10)")),
 		mcp.WithString("query", mcp.Description("Raw Google Drive query string (e.g. \"name contains 'foo'\")")),
 		mcp.WithString("name_contains", mcp.Description("Filter by name containing this string")),
 		mcp.WithString("content_contains", mcp.Description("Filter by content containing this string (fullText)")),
 		mcp.WithString("mime_type", mcp.Description("Filter by exact mimeType (e.g. 'application/vnd.google-apps.folder')")),
+		mcp.WithString("modified_after", mcp.Description("Only files modified after this time (RFC3339 or YYYY-MM-DD)")),
+		mcp.WithString("modified_before", mcp.Description("Only files modified before this time (RFC3339 or YYYY-MM-DD)")),
+		mcp.WithString("owner", mcp.Description("Only files owned by this email address ('me' for your own files)")),
+		mcp.WithString("folder_id", mcp.Description("Only files directly inside this folder")),
+		mcp.WithString("starred_only", mcp.Description("If 'true', only starred files")),
 		mcp.WithString("include_snippet", mcp.Description("If 'true', include a short content snippet per file when using content_contains (default: false)")),
+		mcp.WithString("corpora", mcp.Description("Where to search: 'user' (default: My Drive and shared with me), 'allDrives' (includes shared drives) or 'domain'")),
+		mcp.WithString("drive_id", mcp.Description("Search only this shared drive (from drive_list_shared_drives)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		limit := int64(request.GetInt("limit", 10))
-		rawQuery := request.GetString("query", "")
-		nameContains := request.GetString("name_contains", "")
-		contentContains := request.GetString("content_contains", "")
-		mimeType := request.GetString("mime_type", "")
 		includeSnippet := request.GetString("include_snippet", "false") == "true"
-
-		var queryParts []string
-		if rawQuery != "" {
-			queryParts = append(queryParts, fmt.Sprintf("(%s)", rawQuery))
-		}
-		if nameContains != "" {
-			queryParts = append(queryParts, fmt.Sprintf("name contains '%s'", nameContains))
-		}
-		if contentContains != "" {
-			queryParts = append(queryParts, fmt.Sprintf("fullText contains '%s'", contentContains))
-		}
-		if mimeType != "" {
-			queryParts = append(queryParts, fmt.Sprintf("mimeType = '%s'", mimeType))
+		scope := drivesvc.SearchScope{
+			Corpora: request.GetString("corpora", ""),
+			DriveID: request.GetString("drive_id", ""),
+		}
+
+		finalQuery, err := drivesvc.Query{
+			Raw:            request.GetString("query", ""),
+			NameContains:   request.GetString("name_contains", ""),
+			FullText:       request.GetString("content_contains", ""),
+			MimeType:       request.GetString("mime_type", ""),
+			ModifiedAfter:  request.GetString("modified_after", ""),
+			ModifiedBefore: request.GetString("modified_before", ""),
+			Owner:          request.GetString("owner", ""),
+			FolderID:       request.GetString("folder_id", ""),
+			Starred:        request.GetString("starred_only", "false") == "true",
+		}.Build()
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		finalQuery := strings.Join(queryParts, " and ")
-
 		if includeSnippet && finalQuery != "" {
-			results, err := driveService.SearchFilesWithSnippets(finalQuery, limit, 300)
+			results, err := driveService.SearchFilesWithSnippets(ctx, finalQuery, limit, 300, scope)
 			if err != nil {
 				return mcp.NewToolResultError(fmt.Sprintf("Failed to search files: %v", err)), nil
 			}
 			var result string
 			for _, r := range results {
-				result += fmt.Sprintf("[%s] %s (%s)\n", r.File.Id, r.File.Name, r.File.MimeType)
+				result += driveFileLine(r.File)
 				if r.Snippet != "" {
 					snip := strings.TrimSpace(r.Snippet)
 					if len(snip) > 280 {
@@ -199,13 +433,13 @@ func main() {
 			return mcp.NewToolResultText(result), nil
 		}
 
-		files, err := driveService.SearchFiles(finalQuery, limit)
+		files, err := driveService.SearchFiles(ctx, finalQuery, limit, scope)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to search files: %v", err)), nil
 		}
 		var result string
 		for _, f := range files {
-			result += fmt.Sprintf("[%s] %s (%s)\n", f.Id, f.Name, f.MimeType)
+			result += driveFileLine(f)
 		}
 		if len(files) == 0 {
 			result = "No files found."
@@ -216,9 +450,12 @@ func main() {
 	// Tool: Drive Find Files (account-wide discovery)
 	s.AddTool(mcp.NewTool("drive_find_files",
 		mcp.WithDescription("Find files across Google Drive by content (fullText search). Optimized for account-wide discovery when you know a phrase or keyword. Use drive_read_file to read a file's full content."),
+		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithString("search_term", mcp.Required(), mcp.Description("Phrase or keyword to search for in file content")),
 		mcp.WithNumber("limit", mcp.Description("Maximum number of files to return (default 20)")),
 		mcp.WithString("include_snippet", mcp.Description("If 'true', include a short content snippet per file (default: false)")),
+		mcp.WithString("corpora", mcp.Description("Where to search: 'user' (default: My Drive and shared with me), 'allDrives' (includes shared drives) or 'domain'")),
+		mcp.WithString("drive_id", mcp.Description("Search only this shared drive (from drive_list_shared_drives)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		searchTerm, err := request.RequireString("search_term")
 		if err != nil {
@@ -226,15 +463,19 @@ func main() {
 		}
 		limit := int64(request.GetInt("limit", 20))
 		includeSnippet := request.GetString("include_snippet", "false") == "true"
+		scope := drivesvc.SearchScope{
+			Corpora: request.GetString("corpora", ""),
+			DriveID: request.GetString("drive_id", ""),
+		}
 
 		if includeSnippet {
-			results, err := driveService.FindFilesWithSnippets(searchTerm, limit, 300)
+			results, err := driveService.FindFilesWithSnippets(ctx, searchTerm, limit, 300, scope)
 			if err != nil {
 				return mcp.NewToolResultError(fmt.Sprintf("Failed to find files: %v", err)), nil
 			}
 			var result string
 			for _, r := range results {
-				result += fmt.Sprintf("[%s] %s (%s)\n", r.File.Id, r.File.Name, r.File.MimeType)
+				result += driveFileLine(r.File)
 				if r.Snippet != "" {
 					snip := strings.TrimSpace(r.Snippet)
 					if len(snip) > 280 {
@@ -249,13 +490,13 @@ func main() {
 			return mcp.NewToolResultText(result), nil
 		}
 
-		files, err := driveService.FindFiles(searchTerm, limit)
+		files, err := driveService.FindFiles(ctx, searchTerm, limit, scope)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to find files: %v", err)), nil
 		}
 		var result string
 		for _, f := range files {
-			result += fmt.Sprintf("[%s] %s (%s)\n", f.Id, f.Name, f.MimeType)
+			result += driveFileLine(f)
 		}
 		if len(files) == 0 {
 			result = "No files found."
@@ -263,28 +504,233 @@ func main() {
 		return mcp.NewToolResultText(result), nil
 	})
 
+	// Tool: Drive About
+	s.AddTool(mcp.NewTool("drive_about",
+		mcp.WithDescription("Show which Google account Drive is acting as, storage used and remaining, and optionally the supported import/export formats"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("include_formats", mcp.Description("If 'true', list import/export formats (default: false)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		includeFormats := request.GetString("include_formats", "false") == "true"
+
+		about, err := driveService.About(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get account info: %v", err)), nil
+		}
+
+		var result string
+		if about.User != nil {
+			result += fmt.Sprintf("Account: %s <%s>\n", about.User.DisplayName, about.User.EmailAddress)
+		}
+		if q := about.StorageQuota; q != nil {
+			result += fmt.Sprintf("Storage used: %s (Drive: %s, Trash: %s)\n", drivesvc.FormatBytes(q.Usage), drivesvc.FormatBytes(q.UsageInDrive), drivesvc.FormatBytes(q.UsageInDriveTrash))
+			if q.Limit > 0 {
+				result += fmt.Sprintf("Storage limit: %s (%s free)\n", drivesvc.FormatBytes(q.Limit), drivesvc.FormatBytes(max(q.Limit-q.Usage, 0)))
+			} else {
+				result += "Storage limit: unlimited\n"
+			}
+		}
+		if includeFormats {
+			result += "\nExport formats:\n"
+			for _, from := range slices.Sorted(maps.Keys(about.ExportFormats)) {
+				result += fmt.Sprintf("  %s -> %s\n", from, strings.Join(about.ExportFormats[from], ", "))
+			}
+			result += "\nImport formats:\n"
+			for _, from := range slices.Sorted(maps.Keys(about.ImportFormats)) {
+				result += fmt.Sprintf("  %s -> %s\n", from, strings.Join(about.ImportFormats[from], ", "))
+			}
+		}
+		return mcp.NewToolResultText(result), nil
+	})
+
+	// Tool: Drive List Shared Drives
+	s.AddTool(mcp.NewTool("drive_list_shared_drives",
+		mcp.WithDescription("List the shared drives you are a member of. Use a drive ID as drive_id in drive_search, or as folder_id in drive_list_folder to browse it."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("limit", mcp.Description("Max shared drives to return (default 50, max 100)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		limit := int64(request.GetInt("limit", 50))
+
+		drives, err := driveService.ListSharedDrives(ctx, limit)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list shared drives: %v", err)), nil
+		}
+
+		var result string
+		for _, d := range drives {
+			result += fmt.Sprintf("[%s] %s\n", d.Id, d.Name)
+		}
+		if len(drives) == 0 {
+			result = "No shared drives found."
+		}
+		return mcp.NewToolResultText(result), nil
+	})
+
+	// Tool: Drive List Folder
+	s.AddTool(mcp.NewTool("drive_list_folder",
+		mcp.WithDescription("List the contents of a Drive folder with type, size and modified time. Set recursive='true' for an indented tree of subfolders."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("folder_id", mcp.Description("ID of the folder (default: 'root', i.e. My Drive)")),
+		mcp.WithString("recursive", mcp.Description("If 'true', descend into subfolders (default: false)")),
+		mcp.WithNumber("max_depth", mcp.Description("Levels to descend when recursive (default 3)")),
+		mcp.WithNumber("limit", mcp.Description("Max entries to return (default 100)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		folderID := request.GetString("folder_id", "root")
+		recursive := request.GetString("recursive", "false") == "true"
+		maxDepth := request.GetInt("max_depth", 3)
+		limit := request.GetInt("limit", 100)
+		if !recursive {
+			maxDepth = 1
+		}
+
+		entries, full, err := driveService.FolderTree(ctx, folderID, maxDepth, limit)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list folder: %v", err)), nil
+		}
+
+		var result string
+		for _, e := range entries {
+			f := e.File
+			indent := strings.Repeat("  ", e.Depth)
+			if f.MimeType == drivesvc.FolderMimeType {
+				more := ""
+				if e.Truncated && recursive {
+					more = " ..."
+				}
+				result += fmt.Sprintf("%s%s/ [%s]%s\n", indent, f.Name, f.Id, more)
+				continue
+			}
+			result += fmt.Sprintf("%s%s [%s] (%s, %d bytes, modified %s)\n", indent, f.Name, f.Id, f.MimeType, f.Size, f.ModifiedTime)
+		}
+		if len(entries) == 0 {
+			result = "Folder is empty."
+		}
+		if full {
+			result += fmt.Sprintf("(stopped after %d entries; raise limit to see more)\n", limit)
+		}
+		return mcp.NewToolResultText(result), nil
+	})
+
 	// Tool: Drive Read File
 	s.AddTool(mcp.NewTool("drive_read_file",
-		mcp.WithDescription("Read the text content of a file from Google Drive. CAUTION: Only use for text-based files."),
+		mcp.WithDescription("Read the text content of a file from Google Drive, 32KB at a time by default. For large files, continue with the offset given at the end of the output. Text is extracted from PDFs and images (including scans) with OCR; other binary files are not supported."),
+		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithString("file_id", mcp.Required(), mcp.Description("ID of the file to read")),
+		mcp.WithNumber("offset", mcp.Description("Byte offset to start reading from (default 0)")),
+		mcp.WithNumber("length", mcp.Description("Max bytes to read (default 32768)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		fileID, err := request.RequireString("file_id")
 		if err != nil {
 			return mcp.NewToolResultError("file_id is required"), nil
 		}
-
+		offset := int64(request.GetInt("offset", 0))
 		// Limit to 32KB by default to avoid blowing up context
-		content, err := driveService.ReadFileContent(fileID, 32*1024)
+		length := int64(request.GetInt("length", 32*1024))
+
+		chunk, err := driveService.ReadFileRange(ctx, fileID, offset, length)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to read file: %v", err)), nil
 		}
 
-		return mcp.NewToolResultText(content), nil
+		result := chunk.Content
+		if chunk.Next >= 0 || chunk.Offset > 0 {
+			end := chunk.Offset + int64(len(chunk.Content))
+			result += fmt.Sprintf("\n\n[bytes %d-%d of %d", chunk.Offset, end, chunk.Total)
+			if chunk.Next >= 0 {
+				result += fmt.Sprintf("; continue with offset=%d", chunk.Next)
+			} else {
+				result += "; end of file"
+			}
+			result += "]"
+		}
+		return mcp.NewToolResultText(result), nil
+	})
+
+	// Tool: Drive Download File
+	s.AddTool(mcp.NewTool("drive_download_file",
+		mcp.WithDescription("Download a Drive file, or export a Google Doc/Sheet/Slide in a chosen format, to a local path. Without local_path, small files are returned as base64."),
+		mcp.WithString("file_id", mcp.Required(), mcp.Description("ID of the file to download")),
+		mcp.WithString("format", mcp.Description("Export format for Google Docs/Sheets/Slides: pdf (default), docx, xlsx, pptx, csv, tsv, txt, html, md, or a mime type. Ignored for other files.")),
+		mcp.WithString("local_path", mcp.Description("Local file or directory to save to, inside the server's local files directory (optional)")),
+		mcp.WithString("overwrite", mcp.Description("If 'true', replace an existing file at local_path (default: false)")),
+		mcp.WithNumber("max_bytes", mcp.Description("Max size to return as base64 when local_path is not set (default 1048576)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		fileID, err := request.RequireString("file_id")
+		if err != nil {
+			return mcp.NewToolResultError("file_id is required"), nil
+		}
+		exportMime, err := drivesvc.ExportMimeType(request.GetString("format", ""))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		localPath := request.GetString("local_path", "")
+		maxBytes := int64(request.GetInt("max_bytes", 1024*1024))
+
+		if localPath != "" {
+			resolved, err := local.resolve(localPath)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to download file: %v", err)), nil
+			}
+			file, path, n, err := driveService.SaveFile(ctx, fileID, exportMime, resolved, request.GetString("overwrite", "false") == "true")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to download file: %v", err)), nil
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Saved %s (%s, %d bytes) to %s", file.Name, file.MimeType, n, path)), nil
+		}
+
+		file, err := driveService.DownloadFileLimit(ctx, fileID, exportMime, maxBytes)
+		if errors.Is(err, drivesvc.ErrFileTooLarge) {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to download file: %v (max_bytes). Use local_path to save it instead.", err)), nil
+		}
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to download file: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Name: %s\nMime type: %s\nSize: %d bytes\nContent (base64):\n%s", file.Name, file.MimeType, len(file.Data), base64.StdEncoding.EncodeToString(file.Data))), nil
+	})
+
+	// Tool: Drive Export PDF
+	s.AddTool(mcp.NewTool("drive_export_pdf",
+		mcp.WithDescription("Export a Google Doc, Sheet or Slides deck as PDF and save it to Drive (next to the original by default), returning its link, or return the PDF as base64 for download, e.g. to send a report as a PDF"),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("file_id", mcp.Required(), mcp.Description("ID of the Doc, Sheet or Slides deck")),
+		mcp.WithString("save_to_drive", mcp.Description("If 'false', return the PDF as base64 instead of saving it to Drive (default: true)")),
+		mcp.WithString("parent_id", mcp.Description("Drive folder ID to save the PDF into (default: the original's folder)")),
+		mcp.WithString("name", mcp.Description("PDF file name (default: the original's name with .pdf)")),
+		mcp.WithNumber("max_bytes", mcp.Description("Max size to return as base64 when save_to_drive is 'false' (default 1048576)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		fileID, err := request.RequireString("file_id")
+		if err != nil {
+			return mcp.NewToolResultError("file_id is required"), nil
+		}
+		saveToDrive := request.GetString("save_to_drive", "true") != "false"
+		maxBytes := int64(request.GetInt("max_bytes", 1024*1024))
+
+		pdf, parents, err := driveService.ExportPDF(ctx, fileID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to export PDF: %v", err)), nil
+		}
+		name := request.GetString("name", pdf.Name)
+		if !saveToDrive {
+			if int64(len(pdf.Data)) > maxBytes {
+				return mcp.NewToolResultError(fmt.Sprintf("PDF is %d bytes, over max_bytes (%d). Save it to Drive instead.", len(pdf.Data), maxBytes)), nil
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Name: %s\nMime type: %s\nSize: %d bytes\nContent (base64):\n%s", name, pdf.MimeType, len(pdf.Data), base64.StdEncoding.EncodeToString(pdf.Data))), nil
+		}
+
+		parentID := request.GetString("parent_id", "")
+		if parentID == "" && len(parents) > 0 {
+			parentID = parents[0]
+		}
+		file, err := driveService.UploadFile(ctx, name, parentID, pdf.MimeType, bytes.NewReader(pdf.Data), nil)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to save PDF: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Saved PDF: %s (ID: %s, %d bytes)\nLink: https://drive.google.com/file/d/%s/view", file.Name, file.Id, len(pdf.Data), file.Id)), nil
 	})
 
 	// Tool: Drive Create File
 	s.AddTool(mcp.NewTool("drive_create_file",
 		mcp.WithDescription("Create a new text file in Google Drive"),
+		mcp.WithDestructiveHintAnnotation(false),
 		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the file")),
 		mcp.WithString("content", mcp.Required(), mcp.Description("Text content of the file")),
 		mcp.WithString("parent_id", mcp.Description("ID of the parent folder (optional)")),
@@ -301,7 +747,7 @@ func main() {
 		parentID := request.GetString("parent_id", "")
 		mimeType := request.GetString("mime_type", "text/plain")
 
-		file, err := driveService.CreateFile(name, parentID, content, mimeType)
+		file, err := driveService.CreateFile(ctx, name, parentID, content, mimeType)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to create file: %v", err)), nil
 		}
@@ -309,9 +755,84 @@ func main() {
 		return mcp.NewToolResultText(fmt.Sprintf("Created file: %s (ID: %s)", file.Name, file.Id)), nil
 	})
 
+	// Tool: Drive Upload File
+	s.AddTool(mcp.NewTool("drive_upload_file",
+		mcp.WithDescription("Upload a binary file (PDF, image, archive...) to Google Drive from a local path or base64 content. Large files use a resumable upload."),
+		mcp.WithString("local_path", mcp.Description("Path of a local file to upload, inside the server's local files directory")),
+		mcp.WithString("content_base64", mcp.Description("File content as base64 (used if local_path is not set)")),
+		mcp.WithString("name", mcp.Description("Name in Drive (default: local file name; required with content_base64)")),
+		mcp.WithString("parent_id", mcp.Description("ID of the parent folder (optional)")),
+		mcp.WithString("mime_type", mcp.Description("Content type (default: detected from name and content)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		localPath := request.GetString("local_path", "")
+		contentB64 := request.GetString("content_base64", "")
+		name := request.GetString("name", "")
+		parentID := request.GetString("parent_id", "")
+		mimeType := request.GetString("mime_type", "")
+
+		var content io.Reader
+		var size int64
+		switch {
+		case localPath != "":
+			resolved, err := local.resolve(localPath)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to open %s: %v", localPath, err)), nil
+			}
+			f, err := os.Open(resolved)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to open %s: %v", localPath, err)), nil
+			}
+			defer func() {
+				_ = f.Close()
+			}()
+			info, err := f.Stat()
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to stat %s: %v", localPath, err)), nil
+			}
+			if info.IsDir() {
+				return mcp.NewToolResultError(fmt.Sprintf("%s is a directory", localPath)), nil
+			}
+			if name == "" {
+				name = filepath.Base(localPath)
+			}
+			content, size = f, info.Size()
+		case contentB64 != "":
+			data, err := base64.StdEncoding.DecodeString(contentB64)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("content_base64 is not valid base64: %v", err)), nil
+			}
+			if name == "" {
+				return mcp.NewToolResultError("name is required with content_base64"), nil
+			}
+			content, size = bytes.NewReader(data), int64(len(data))
+		default:
+			return mcp.NewToolResultError("local_path or content_base64 is required"), nil
+		}
+
+		// Report chunk progress to clients that asked for it.
+		var progress func(sent int64)
+		if meta := request.Params.Meta; meta != nil && meta.ProgressToken != nil {
+			progress = func(sent int64) {
+				_ = s.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+					"progressToken": meta.ProgressToken,
+					"progress":      sent,
+					"total":         size,
+				})
+			}
+		}
+
+		file, err := driveService.UploadFile(ctx, name, parentID, mimeType, content, progress)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to upload file: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Uploaded file: %s (ID: %s, %s, %d bytes)", file.Name, file.Id, file.MimeType, file.Size)), nil
+	})
+
 	// Tool: Drive Create Folder
 	s.AddTool(mcp.NewTool("drive_create_folder",
 		mcp.WithDescription("Create a new folder in Google Drive"),
+		mcp.WithDestructiveHintAnnotation(false),
 		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the folder")),
 		mcp.WithString("parent_id", mcp.Description("ID of the parent folder (optional)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -321,7 +842,7 @@ func main() {
 		}
 		parentID := request.GetString("parent_id", "")
 
-		folder, err := driveService.CreateFolder(name, parentID)
+		folder, err := driveService.CreateFolder(ctx, name, parentID)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to create folder: %v", err)), nil
 		}
@@ -335,7 +856,7 @@ func main() {
 		mcp.WithString("file_id", mcp.Required(), mcp.Description("ID of the file to update")),
 		mcp.WithString("name", mcp.Description("New name (optional)")),
 		mcp.WithString("content", mcp.Description("New text content (optional)")),
-		mcp.WithString("add_parent_id", mcp.Description("Add this parent folder ID (optional, effectively moving/aliasing)")),
+		mcp.WithString("add_parent_id", mcp.Description("Add this parent folder ID (optional; use drive_move_file to move)")),
 		mcp.WithString("remove_parent_id", mcp.Description("Remove this parent folder ID (optional)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		fileID, err := request.RequireString("file_id")
@@ -352,7 +873,7 @@ func main() {
 			contentPtr = &content
 		}
 
-		file, err := driveService.UpdateFile(fileID, name, addParent, removeParent, contentPtr)
+		file, err := driveService.UpdateFile(ctx, fileID, name, addParent, removeParent, contentPtr)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to update file: %v", err)), nil
 		}
@@ -360,1074 +881,5960 @@ func main() {
 		return mcp.NewToolResultText(fmt.Sprintf("Updated file: %s (ID: %s)", file.Name, file.Id)), nil
 	})
 
-	// Tool: Drive Trash File
-	s.AddTool(mcp.NewTool("drive_trash_file",
-		mcp.WithDescription("Move a file or folder to trash (recoverable)"),
-		mcp.WithString("file_id", mcp.Required(), mcp.Description("ID of the file/folder to trash")),
+	// Tool: Drive Copy File
+	s.AddTool(mcp.NewTool("drive_copy_file",
+		mcp.WithDescription("Copy a file in Google Drive, optionally renaming it or placing the copy in another folder"),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("file_id", mcp.Required(), mcp.Description("ID of the file to copy")),
+		mcp.WithString("name", mcp.Description("Name of the copy (default: 'Copy of <name>')")),
+		mcp.WithString("parent_id", mcp.Description("Folder ID for the copy (default: same folder as the original)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		fileID, err := request.RequireString("file_id")
 		if err != nil {
 			return mcp.NewToolResultError("file_id is required"), nil
 		}
+		name := request.GetString("name", "")
+		parentID := request.GetString("parent_id", "")
+
+		file, err := driveService.CopyFile(ctx, fileID, name, parentID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to copy file: %v", err)), nil
+		}
 
-		if err := driveService.TrashFile(fileID); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to trash file: %v", err)), nil
+		return mcp.NewToolResultText(fmt.Sprintf("Copied file: %s (ID: %s)", file.Name, file.Id)), nil
+	})
+
+	// Tool: Drive Backup Folder
+	s.AddTool(mcp.NewTool("drive_backup_folder",
+		mcp.WithDescription("Snapshot a whole folder tree before bulk edits: copy it into a new timestamped folder (mode 'copy'), or save it as a zip archive on Drive with Docs, Sheets and Slides exported to DOCX, XLSX and PPTX (mode 'zip'). Files that cannot be backed up, such as shortcuts and Forms, are listed. Reports progress to clients that ask for it."),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("folder_id", mcp.Required(), mcp.Description("ID of the folder to back up")),
+		mcp.WithString("mode", mcp.Description("'copy' (default) or 'zip'")),
+		mcp.WithString("parent_id", mcp.Description("Folder to create the snapshot in (default: the same folder as the original)")),
+		mcp.WithString("name", mcp.Description("Name of the snapshot (default: '<folder> backup <timestamp>')")),
+		mcp.WithNumber("max_items", mcp.Description("Refuse folders with more files and folders than this (default 1000)")),
+		mcp.WithNumber("max_bytes", mcp.Description("Largest zip archive in bytes (default 200 MiB)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		folderID, err := request.RequireString("folder_id")
+		if err != nil {
+			return mcp.NewToolResultError("folder_id is required"), nil
+		}
+		opts := drivesvc.BackupOptions{
+			Mode:     request.GetString("mode", drivesvc.BackupCopy),
+			ParentID: request.GetString("parent_id", ""),
+			Name:     request.GetString("name", ""),
+			MaxItems: request.GetInt("max_items", 1000),
+			MaxBytes: int64(request.GetInt("max_bytes", 200*1024*1024)),
+		}
+
+		// Report per-file progress to clients that asked for it.
+		var progress func(done, total int)
+		if meta := request.Params.Meta; meta != nil && meta.ProgressToken != nil {
+			progress = func(done, total int) {
+				_ = s.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+					"progressToken": meta.ProgressToken,
+					"progress":      done,
+					"total":         total,
+				})
+			}
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("Trashed file: %s", fileID)), nil
+		res, err := driveService.BackupFolder(ctx, folderID, opts, progress)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to back up folder: %v", err)), nil
+		}
+
+		result := fmt.Sprintf("Backed up %d files and %d folders to %s (ID: %s", res.Files, res.Folders, res.File.Name, res.File.Id)
+		if opts.Mode == drivesvc.BackupZip {
+			result += ", " + drivesvc.FormatBytes(res.File.Size)
+		}
+		result += ")\n"
+		if len(res.Failed) > 0 {
+			result += fmt.Sprintf("\nNot backed up (%d):\n", len(res.Failed))
+			for _, f := range res.Failed {
+				result += fmt.Sprintf("- %s: %s\n", f.Path, f.Error)
+			}
+		}
+		return mcp.NewToolResultText(result), nil
 	})
 
-	// Tool: Drive Share File
-	s.AddTool(mcp.NewTool("drive_share_file",
-		mcp.WithDescription("Share a file/folder with a user"),
-		mcp.WithString("file_id", mcp.Required(), mcp.Description("ID of the file")),
-		mcp.WithString("email", mcp.Required(), mcp.Description("Email address to share with")),
-		mcp.WithString("role", mcp.Description("Role: 'reader', 'commenter', 'writer' (default: reader)")),
+	// Tool: Drive Move File
+	s.AddTool(mcp.NewTool("drive_move_file",
+		mcp.WithDescription("Move a file or folder to another folder, including between My Drive and shared drives"),
+		mcp.WithString("file_id", mcp.Required(), mcp.Description("ID of the file or folder to move")),
+		mcp.WithString("new_parent_id", mcp.Required(), mcp.Description("ID of the destination folder ('root' for My Drive, or a shared drive ID for its top level)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		fileID, err := request.RequireString("file_id")
 		if err != nil {
 			return mcp.NewToolResultError("file_id is required"), nil
 		}
-		email, err := request.RequireString("email")
+		newParentID, err := request.RequireString("new_parent_id")
 		if err != nil {
-			return mcp.NewToolResultError("email is required"), nil
+			return mcp.NewToolResultError("new_parent_id is required"), nil
 		}
-		role := request.GetString("role", "reader")
 
-		if err := driveService.AddPermission(fileID, role, "user", email); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to share file: %v", err)), nil
+		file, err := driveService.MoveFile(ctx, fileID, newParentID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to move file: %v", err)), nil
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("Shared file %s with %s as %s", fileID, email, role)), nil
+		return mcp.NewToolResultText(fmt.Sprintf("Moved file: %s (ID: %s) to %s", file.Name, file.Id, newParentID)), nil
 	})
 
-	// Tool: Drive Get Recent Activity
-	s.AddTool(mcp.NewTool("drive_get_recent_activity",
-		mcp.WithDescription("Get recent Drive activity as human-readable summaries (Edit, Move, Rename, Create, Comment, etc.). Metadata-only to save tokens."),
-		mcp.WithNumber("hours", mcp.Description("How many hours back to look (default 24)")),
-		mcp.WithNumber("limit", mcp.Description("Max activities to return (default 20, max 100)")),
-		mcp.WithString("file_id", mcp.Description("Optional: filter by file ID (items/FILE_ID or just FILE_ID)")),
+	// Tool: Drive Bulk Move
+	s.AddTool(mcp.NewTool("drive_bulk_move",
+		mcp.WithDescription("Move every file matching a search into one folder, with a per-file report. Files already in the folder are skipped. Try dry_run='true' first to preview which files would move."),
+		mcp.WithString("new_parent_id", mcp.Required(), mcp.Description("ID of the destination folder")),
+		mcp.WithString("query", mcp.Description("Raw Google Drive query selecting the files (e.g. \"name contains 'Scan'\")")),
+		mcp.WithString("name_contains", mcp.Description("Only files whose name contains this string")),
+		mcp.WithString("mime_type", mcp.Description("Only files of this exact mimeType")),
+		mcp.WithString("folder_id", mcp.Description("Only files directly inside this folder")),
+		mcp.WithString("modified_after", mcp.Description("Only files modified after this time (RFC3339 or YYYY-MM-DD)")),
+		mcp.WithString("modified_before", mcp.Description("Only files modified before this time (RFC3339 or YYYY-MM-DD)")),
+		mcp.WithNumber("limit", mcp.Description("Most files to change (default 100, max 1000)")),
+		mcp.WithString("dry_run", mcp.Description("If 'true', change nothing and only report what would change (default: false)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		hours := request.GetInt("hours", 24)
-		limit := int64(request.GetInt("limit", 20))
-		fileID := request.GetString("file_id", "")
+		newParentID, err := request.RequireString("new_parent_id")
+		if err != nil {
+			return mcp.NewToolResultError("new_parent_id is required"), nil
+		}
+		files, err := bulkSearch(ctx, driveService, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 
-		summaries, err := activityService.GetRecentActivity(hours, limit, fileID)
+		dryRun := request.GetString("dry_run", "false") == "true"
+		changes, err := driveService.BulkMove(ctx, files, newParentID, dryRun)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get activity: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to move files: %v", err)), nil
 		}
+		return mcp.NewToolResultText(bulkReport(changes, "move", "Moved", dryRun)), nil
+	})
 
-		var result string
-		for _, s := range summaries {
-			result += fmt.Sprintf("%s | %s | %s | %s\n", s.Timestamp, s.Action, s.Actor, s.Target)
+	// Tool: Drive Bulk Rename
+	s.AddTool(mcp.NewTool("drive_bulk_rename",
+		mcp.WithDescription("Rename every file matching a search, replacing the part of each name matched by a regular expression, with a per-file report. "+
+			"The replacement can use groups ($1, ${name}) and the placeholders {n} (the file's position in the results) and {date} (its modified date, YYYY-MM-DD), "+
+			"e.g. find '^Scan (\\d+)' and replace 'Invoice {date} $1'. Try dry_run='true' first to preview the new names."),
+		mcp.WithString("replace", mcp.Required(), mcp.Description("Replacement for the matched part of each name")),
+		mcp.WithString("find", mcp.Description("Regular expression (RE2) for the part of the name to replace (default: the whole name)")),
+		mcp.WithString("query", mcp.Description("Raw Google Drive query selecting the files (e.g. \"name contains 'Scan'\")")),
+		mcp.WithString("name_contains", mcp.Description("Only files whose name contains this string")),
+		mcp.WithString("mime_type", mcp.Description("Only files of this exact mimeType")),
+		mcp.WithString("folder_id", mcp.Description("Only files directly inside this folder")),
+		mcp.WithString("modified_after", mcp.Description("Only files modified after this time (RFC3339 or YYYY-MM-DD)")),
+		mcp.WithString("modified_before", mcp.Description("Only files modified before this time (RFC3339 or YYYY-MM-DD)")),
+		mcp.WithNumber("limit", mcp.Description("Most files to change (default 100, max 1000)")),
+		mcp.WithString("dry_run", mcp.Description("If 'true', change nothing and only report what would change (default: false)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		replace, err := request.RequireString("replace")
+		if err != nil {
+			return mcp.NewToolResultError("replace is required"), nil
 		}
-		if len(summaries) == 0 {
-			result = "No recent activity found."
+		var find *regexp.Regexp
+		if pattern := request.GetString("find", ""); pattern != "" {
+			if find, err = regexp.Compile(pattern); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid find pattern: %v", err)), nil
+			}
 		}
-		return mcp.NewToolResultText(result), nil
+		files, err := bulkSearch(ctx, driveService, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		dryRun := request.GetString("dry_run", "false") == "true"
+		changes := driveService.BulkRename(ctx, files, find, replace, dryRun)
+		return mcp.NewToolResultText(bulkReport(changes, "rename", "Renamed", dryRun)), nil
 	})
 
-	// Tool: Drive List Comments
-	s.AddTool(mcp.NewTool("drive_list_comments",
-		mcp.WithDescription("List comments on a Drive file (e.g. Google Doc, Sheet). Use file_id from drive_search or drive_find_files."),
-		mcp.WithString("file_id", mcp.Required(), mcp.Description("ID of the file")),
-		mcp.WithNumber("limit", mcp.Description("Max comments to return (default 50, max 100)")),
+	// Tool: Drive Create Shortcut
+	s.AddTool(mcp.NewTool("drive_create_shortcut",
+		mcp.WithDescription("Create a shortcut to a file or folder in another folder. Reading a shortcut with drive_read_file or drive_download_file reads its target."),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("target_id", mcp.Required(), mcp.Description("ID of the file or folder the shortcut points to")),
+		mcp.WithString("parent_id", mcp.Description("Folder to create the shortcut in (default: My Drive root)")),
+		mcp.WithString("name", mcp.Description("Shortcut name (default: the target's name)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		targetID, err := request.RequireString("target_id")
+		if err != nil {
+			return mcp.NewToolResultError("target_id is required"), nil
+		}
+		parentID := request.GetString("parent_id", "")
+		name := request.GetString("name", "")
+
+		file, err := driveService.CreateShortcut(ctx, targetID, name, parentID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to create shortcut: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Created shortcut: %s (ID: %s) -> %s", file.Name, file.Id, targetID)), nil
+	})
+
+	// Tool: Drive Star File
+	s.AddTool(mcp.NewTool("drive_star_file",
+		mcp.WithDescription("Star or unstar a file or folder. Find starred files with drive_search starred_only='true'."),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("file_id", mcp.Required(), mcp.Description("ID of the file/folder")),
+		mcp.WithString("starred", mcp.Description("'true' to star (default), 'false' to unstar")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		fileID, err := request.RequireString("file_id")
 		if err != nil {
 			return mcp.NewToolResultError("file_id is required"), nil
 		}
-		limit := int64(request.GetInt("limit", 50))
+		starred := request.GetString("starred", "true") != "false"
 
-		comments, err := driveService.ListComments(fileID, limit)
+		file, err := driveService.SetStarred(ctx, fileID, starred)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list comments: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to update file: %v", err)), nil
 		}
-
-		var result string
-		for _, c := range comments {
-			author := "unknown"
-			if c.Author != nil && c.Author.DisplayName != "" {
-				author = c.Author.DisplayName
-			}
-			resolved := ""
-			if c.Resolved {
-				resolved = " [resolved]"
-			}
-			result += fmt.Sprintf("[%s] %s | %s | %s%s\n", c.Id, c.CreatedTime, author, c.Content, resolved)
+		if file.Starred {
+			return mcp.NewToolResultText(fmt.Sprintf("Starred: %s (ID: %s)", file.Name, file.Id)), nil
 		}
-		if len(comments) == 0 {
-			result = "No comments found."
-		}
-		return mcp.NewToolResultText(result), nil
+		return mcp.NewToolResultText(fmt.Sprintf("Unstarred: %s (ID: %s)", file.Name, file.Id)), nil
 	})
 
-	// Tool: Drive Add Comment
-	s.AddTool(mcp.NewTool("drive_add_comment",
-		mcp.WithDescription("Add a comment to a Drive file (e.g. Google Doc, Sheet). Use file_id from drive_search or drive_find_files."),
-		mcp.WithString("file_id", mcp.Required(), mcp.Description("ID of the file")),
-		mcp.WithString("content", mcp.Required(), mcp.Description("Plain text content of the comment")),
+	// Tool: Drive Trash File
+	s.AddTool(mcp.NewTool("drive_trash_file",
+		mcp.WithDescription("Move a file or folder to trash (recoverable)"),
+		mcp.WithString("file_id", mcp.Required(), mcp.Description("ID of the file/folder to trash")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		fileID, err := request.RequireString("file_id")
 		if err != nil {
 			return mcp.NewToolResultError("file_id is required"), nil
 		}
-		content, err := request.RequireString("content")
-		if err != nil {
-			return mcp.NewToolResultError("content is required"), nil
-		}
 
-		comment, err := driveService.CreateComment(fileID, content)
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to add comment: %v", err)), nil
+		if err := driveService.TrashFile(ctx, fileID); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to trash file: %v", err)), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Comment added (ID: %s)", comment.Id)), nil
+
+		return mcp.NewToolResultText(fmt.Sprintf("Trashed file: %s", fileID)), nil
 	})
 
-	// Tool: Gmail List Threads
-	s.AddTool(mcp.NewTool("gmail_list_threads",
-		mcp.WithDescription("List/Search email threads in Gmail"),
-		mcp.WithString("query", mcp.Description("Gmail search query (e.g. 'from:boss', 'is:unread')")),
-		mcp.WithNumber("limit", mcp.Description("Max threads to return (default 10)")),
+	// Tool: Drive List Trash
+	s.AddTool(mcp.NewTool("drive_list_trash",
+		mcp.WithDescription("List files and folders in the Drive trash. Use drive_restore_file to undo a trash."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("limit", mcp.Description("Max files to return (default 20)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		query := request.GetString("query", "")
-		limit := int64(request.GetInt("limit", 10))
+		limit := int64(request.GetInt("limit", 20))
 
-		threads, err := gmailService.ListThreads(query, limit)
+		files, err := driveService.ListTrash(ctx, limit)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list threads: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list trash: %v", err)), nil
 		}
 
 		var result string
-		for _, t := range threads {
-			result += fmt.Sprintf("[Thread ID: %s] %s\n", t.Id, t.Snippet)
+		for _, f := range files {
+			result += fmt.Sprintf("[%s] %s (%s) trashed %s\n", f.Id, f.Name, f.MimeType, f.TrashedTime)
 		}
-		if len(threads) == 0 {
-			result = "No threads found."
+		if len(files) == 0 {
+			result = "Trash is empty."
 		}
 		return mcp.NewToolResultText(result), nil
 	})
 
-	// Tool: Gmail Read Thread
-	s.AddTool(mcp.NewTool("gmail_read_thread",
-		mcp.WithDescription("Read a specific email thread"),
-		mcp.WithString("thread_id", mcp.Required(), mcp.Description("ID of the thread to read")),
+	// Tool: Drive Restore File
+	s.AddTool(mcp.NewTool("drive_restore_file",
+		mcp.WithDescription("Restore a file or folder from the trash to its original location"),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("file_id", mcp.Required(), mcp.Description("ID of the trashed file/folder")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		threadID, err := request.RequireString("thread_id")
+		fileID, err := request.RequireString("file_id")
 		if err != nil {
-			return mcp.NewToolResultError("thread_id is required"), nil
+			return mcp.NewToolResultError("file_id is required"), nil
 		}
 
-		thread, err := gmailService.GetThread(threadID)
+		file, err := driveService.RestoreFile(ctx, fileID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get thread: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to restore file: %v", err)), nil
 		}
+		return mcp.NewToolResultText(fmt.Sprintf("Restored file: %s (ID: %s)", file.Name, file.Id)), nil
+	})
 
-		var result string
-		result += fmt.Sprintf("Thread ID: %s\n", thread.Id)
-		for _, msg := range thread.Messages {
-			subject := gmailsvc.GetHeader(msg.Payload.Headers, "Subject")
-			from := gmailsvc.GetHeader(msg.Payload.Headers, "From")
-			date := gmailsvc.GetHeader(msg.Payload.Headers, "Date")
-			body := gmailsvc.ExtractMessageBody(msg.Payload)
-
-			// Truncate body if too long for safety
-			if len(body) > 2000 {
-				body = body[:2000] + "...(truncated)"
+	// Tool: Drive Delete Permanently
+	s.AddTool(mcp.NewTool("drive_delete_permanently",
+		mcp.WithDescription("PERMANENTLY delete a file or folder, or empty the whole trash. This cannot be undone; prefer drive_trash_file. Requires confirm='true'."),
+		mcp.WithString("file_id", mcp.Description("ID of the file/folder to delete (omit with empty_trash='true')")),
+		mcp.WithString("empty_trash", mcp.Description("If 'true', permanently delete everything in the trash instead of a single file")),
+		mcp.WithString("confirm", mcp.Required(), mcp.Description("Must be 'true' to confirm the permanent deletion")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if request.GetString("confirm", "") != "true" {
+			return mcp.NewToolResultError("Permanent deletion not confirmed: set confirm='true' after checking with the user"), nil
+		}
+		if request.GetString("empty_trash", "false") == "true" {
+			if err := driveService.EmptyTrash(ctx); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to empty trash: %v", err)), nil
 			}
-
-			result += fmt.Sprintf("---\nMsg ID: %s\nFrom: %s\nDate: %s\nSubject: %s\n\n%s\n", msg.Id, from, date, subject, body)
+			return mcp.NewToolResultText("Emptied trash."), nil
+		}
+		fileID, err := request.RequireString("file_id")
+		if err != nil {
+			return mcp.NewToolResultError("file_id is required (or set empty_trash='true')"), nil
 		}
 
-		return mcp.NewToolResultText(result), nil
+		if err := driveService.DeletePermanently(ctx, fileID); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete file: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Permanently deleted: %s", fileID)), nil
 	})
 
-	// Tool: Gmail Send Email
-	s.AddTool(mcp.NewTool("gmail_send_email",
-		mcp.WithDescription("Send an email"),
-		mcp.WithString("to", mcp.Required(), mcp.Description("Recipient email address")),
-		mcp.WithString("subject", mcp.Required(), mcp.Description("Email subject")),
-		mcp.WithString("body", mcp.Required(), mcp.Description("Email body content")),
+	// Tool: Drive Share File
+	s.AddTool(mcp.NewTool("drive_share_file",
+		mcp.WithDescription("Share a file/folder with a user, group or domain, or turn on 'anyone with the link' sharing"),
+		mcp.WithString("file_id", mcp.Required(), mcp.Description("ID of the file")),
+		mcp.WithString("email", mcp.Description("Email address to share with (required for type 'user' or 'group')")),
+		mcp.WithString("role", mcp.Description("Role: 'reader', 'commenter', 'writer' (default: reader)")),
+		mcp.WithString("type", mcp.Description("Who to share with: 'user' (default), 'group', 'domain' or 'anyone' (anyone with the link)")),
+		mcp.WithString("domain", mcp.Description("Domain to share with when type is 'domain'")),
+		mcp.WithString("expiration_time", mcp.Description("When access expires (RFC3339, optional; user and group shares only)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		to, err := request.RequireString("to")
+		fileID, err := request.RequireString("file_id")
 		if err != nil {
-			return mcp.NewToolResultError("to is required"), nil
+			return mcp.NewToolResultError("file_id is required"), nil
 		}
-		subject, err := request.RequireString("subject")
+		email := request.GetString("email", "")
+		role := request.GetString("role", "reader")
+		shareType := request.GetString("type", "user")
+		expiration := request.GetString("expiration_time", "")
+
+		target := email
+		if shareType == "domain" {
+			target = request.GetString("domain", "")
+		}
+
+		perm, err := driveService.AddPermission(ctx, fileID, role, shareType, target, expiration)
 		if err != nil {
-			return mcp.NewToolResultError("subject is required"), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to share file: %v", err)), nil
 		}
-		body, err := request.RequireString("body")
+
+		who := target
+		if shareType == "anyone" {
+			who = "anyone with the link"
+		}
+		result := fmt.Sprintf("Shared file %s with %s as %s (permission ID: %s)", fileID, who, role, perm.Id)
+		if expiration != "" {
+			result += fmt.Sprintf(", expires %s", expiration)
+		}
+		return mcp.NewToolResultText(result), nil
+	})
+
+	// Tool: Drive List Permissions
+	s.AddTool(mcp.NewTool("drive_list_permissions",
+		mcp.WithDescription("List who a file/folder is shared with, including link sharing, to audit access. Use permission IDs with drive_update_permission and drive_remove_permission."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("file_id", mcp.Required(), mcp.Description("ID of the file")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		fileID, err := request.RequireString("file_id")
 		if err != nil {
-			return mcp.NewToolResultError("body is required"), nil
+			return mcp.NewToolResultError("file_id is required"), nil
 		}
 
-		msg, err := gmailService.SendEmail(to, subject, body)
+		perms, err := driveService.ListPermissions(ctx, fileID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to send email: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list permissions: %v", err)), nil
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("Email sent! ID: %s", msg.Id)), nil
+		var result string
+		for _, p := range perms {
+			who := p.EmailAddress
+			switch p.Type {
+			case "anyone":
+				who = "anyone with the link"
+				if p.AllowFileDiscovery {
+					who = "anyone (public, discoverable)"
+				}
+			case "domain":
+				who = "everyone at " + p.Domain
+			}
+			if p.DisplayName != "" && p.Type != "domain" && p.Type != "anyone" {
+				who = fmt.Sprintf("%s <%s>", p.DisplayName, p.EmailAddress)
+			}
+			line := fmt.Sprintf("[%s] %s: %s (%s)", p.Id, who, p.Role, p.Type)
+			if p.ExpirationTime != "" {
+				line += " expires " + p.ExpirationTime
+			}
+			for _, detail := range p.PermissionDetails {
+				if detail.Inherited {
+					line += " [inherited from " + detail.InheritedFrom + "]"
+					break
+				}
+			}
+			result += line + "\n"
+		}
+		if len(perms) == 0 {
+			result = "No permissions found."
+		}
+		return mcp.NewToolResultText(result), nil
 	})
 
-	// Tool: Gmail Create Draft
-	s.AddTool(mcp.NewTool("gmail_create_draft",
-		mcp.WithDescription("Create a draft email"),
-		mcp.WithString("to", mcp.Required(), mcp.Description("Recipient email address")),
-		mcp.WithString("subject", mcp.Required(), mcp.Description("Email subject")),
-		mcp.WithString("body", mcp.Required(), mcp.Description("Email body content")),
+	// Tool: Drive Update Permission
+	s.AddTool(mcp.NewTool("drive_update_permission",
+		mcp.WithDescription("Change the role or expiration of an existing permission on a file/folder"),
+		mcp.WithString("file_id", mcp.Required(), mcp.Description("ID of the file")),
+		mcp.WithString("permission_id", mcp.Required(), mcp.Description("Permission ID from drive_list_permissions")),
+		mcp.WithString("role", mcp.Description("New role: 'reader', 'commenter', 'writer' (optional)")),
+		mcp.WithString("expiration_time", mcp.Description("New expiration (RFC3339), or 'none' to remove it (optional)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		to, err := request.RequireString("to")
-		if err != nil {
-			return mcp.NewToolResultError("to is required"), nil
-		}
-		subject, err := request.RequireString("subject")
+		fileID, err := request.RequireString("file_id")
 		if err != nil {
-			return mcp.NewToolResultError("subject is required"), nil
+			return mcp.NewToolResultError("file_id is required"), nil
 		}
-		body, err := request.RequireString("body")
+		permissionID, err := request.RequireString("permission_id")
 		if err != nil {
-			return mcp.NewToolResultError("body is required"), nil
+			return mcp.NewToolResultError("permission_id is required"), nil
 		}
+		role := request.GetString("role", "")
+		expiration := request.GetString("expiration_time", "")
 
-		draft, err := gmailService.CreateDraft(to, subject, body)
+		perm, err := driveService.UpdatePermission(ctx, fileID, permissionID, role, expiration)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to create draft: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to update permission: %v", err)), nil
 		}
-
-		return mcp.NewToolResultText(fmt.Sprintf("Draft created! ID: %s", draft.Id)), nil
+		return mcp.NewToolResultText(fmt.Sprintf("Updated permission %s: %s", perm.Id, perm.Role)), nil
 	})
 
-	// Tool: Gmail Trash Thread
-	s.AddTool(mcp.NewTool("gmail_trash_thread",
-		mcp.WithDescription("Move an email thread to trash"),
-		mcp.WithString("thread_id", mcp.Required(), mcp.Description("ID of the thread to trash")),
+	// Tool: Drive Remove Permission
+	s.AddTool(mcp.NewTool("drive_remove_permission",
+		mcp.WithDescription("Revoke a permission on a file/folder, e.g. unshare a user or turn off link sharing"),
+		mcp.WithString("file_id", mcp.Required(), mcp.Description("ID of the file")),
+		mcp.WithString("permission_id", mcp.Required(), mcp.Description("Permission ID from drive_list_permissions")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		threadID, err := request.RequireString("thread_id")
+		fileID, err := request.RequireString("file_id")
 		if err != nil {
-			return mcp.NewToolResultError("thread_id is required"), nil
+			return mcp.NewToolResultError("file_id is required"), nil
 		}
-
-		if err := gmailService.TrashThread(threadID); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to trash thread: %v", err)), nil
+		permissionID, err := request.RequireString("permission_id")
+		if err != nil {
+			return mcp.NewToolResultError("permission_id is required"), nil
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("Thread %s moved to trash.", threadID)), nil
+		if err := driveService.RemovePermission(ctx, fileID, permissionID); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to remove permission: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Removed permission %s from %s", permissionID, fileID)), nil
 	})
 
-	// Tool: Gmail List Labels
-	s.AddTool(mcp.NewTool("gmail_list_labels",
-		mcp.WithDescription("List all Gmail labels"),
+	// resolveActivityActors replaces Drive Activity person IDs with emails through the People API.
+	// Lookups are best effort: IDs that cannot be resolved are shown as is.
+	resolveActivityActors := func(ctx context.Context, summaries []activitysvc.ActivitySummary) {
+		if ids := activitysvc.PersonActors(summaries); len(ids) > 0 && registry.ensure("people") == nil {
+			if resolved, err := peopleService.ResolvePeople(ctx, ids); err == nil {
+				activitysvc.ResolveActors(summaries, resolved)
+			}
+		}
+	}
+
+	// Tool: Drive Get Recent Activity
+	s.AddTool(mcp.NewTool("drive_get_recent_activity",
+		mcp.WithDescription("Get recent Drive activity as human-readable summaries (Edit, Move, Rename, Create, Comment, etc.). Metadata-only to save tokens. Use summary='grouped' or summary='counts' for digests over long ranges."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("hours", mcp.Description("How many hours back to look (default 24; ignored when since is set)")),
+		mcp.WithString("since", mcp.Description("Optional: start of the range (RFC3339, e.g. 2024-05-01T00:00:00Z)")),
+		mcp.WithString("until", mcp.Description("Optional: end of the range (RFC3339, default now)")),
+		mcp.WithNumber("limit", mcp.Description("Max activities to read (default 20, or 500 with a summary; max 1000)")),
+		mcp.WithString("file_id", mcp.Description("Optional: filter by file ID (items/FILE_ID or just FILE_ID)")),
+		mcp.WithString("summary", mcp.Description("'events' lists every activity (default), 'grouped' merges consecutive activities of the same kind by the same person on the same file, 'counts' totals them over the whole range")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		labels, err := gmailService.ListLabels()
+		summary := request.GetString("summary", "events")
+		if summary != "events" && summary != "grouped" && summary != "counts" {
+			return mcp.NewToolResultError("summary must be 'events', 'grouped' or 'counts'"), nil
+		}
+		defaultLimit := 20
+		if summary != "events" {
+			defaultLimit = 500
+		}
+		opts := activitysvc.QueryOptions{
+			ItemName: request.GetString("file_id", ""),
+			Since:    time.Now().Add(-time.Duration(request.GetInt("hours", 24)) * time.Hour),
+			Limit:    min(request.GetInt("limit", defaultLimit), 1000),
+		}
+		var err error
+		if v := request.GetString("since", ""); v != "" {
+			if opts.Since, err = time.Parse(time.RFC3339, v); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid since (expected RFC3339): %v", err)), nil
+			}
+		}
+		if v := request.GetString("until", ""); v != "" {
+			if opts.Until, err = time.Parse(time.RFC3339, v); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid until (expected RFC3339): %v", err)), nil
+			}
+		}
+
+		summaries, err := activityService.Query(ctx, opts)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list labels: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get activity: %v", err)), nil
 		}
+		if len(summaries) == 0 {
+			return mcp.NewToolResultText("No recent activity found."), nil
+		}
+		resolveActivityActors(ctx, summaries)
 
 		var result string
-		for _, l := range labels {
-			result += fmt.Sprintf("ID: %s | Name: %s | Type: %s\n", l.Id, l.Name, l.Type)
+		switch summary {
+		case "grouped":
+			for _, g := range activitysvc.GroupConsecutive(summaries) {
+				if g.Count == 1 {
+					result += fmt.Sprintf("%s | %s\n", g.Last, g)
+				} else {
+					result += fmt.Sprintf("%s – %s | %s\n", g.First, g.Last, g)
+				}
+			}
+		case "counts":
+			for _, g := range activitysvc.Totals(summaries) {
+				result += g.String() + "\n"
+			}
+		default:
+			for _, s := range summaries {
+				result += fmt.Sprintf("%s | %s | %s | %s\n", s.Timestamp, s.Action, s.Actor, s.Target)
+			}
+		}
+		if len(summaries) == opts.Limit {
+			result += fmt.Sprintf("\n(Stopped after %d activities; raise limit or narrow the range for more.)", opts.Limit)
 		}
 		return mcp.NewToolResultText(result), nil
 	})
 
-	// Tool: Calendar List Events
-	s.AddTool(mcp.NewTool("calendar_list_events",
-		mcp.WithDescription("List upcoming events from Google Calendar"),
-		mcp.WithString("calendar_id", mcp.Description("Calendar ID (default: 'primary')")),
-		mcp.WithNumber("max_results", mcp.Description("Max events to return (default 10)")),
-		mcp.WithString("time_min", mcp.Description("Start time (RFC3339). Default: now.")),
-		mcp.WithString("time_max", mcp.Description("End time (RFC3339). Optional.")),
+	// Tool: Drive File Activity
+	s.AddTool(mcp.NewTool("drive_file_activity",
+		mcp.WithDescription("Get the activity history of a single Drive file (or of everything inside a folder): who edited, moved, renamed, shared or commented on it, and when. Most recent first."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("file_id", mcp.Required(), mcp.Description("ID of the file or folder")),
+		mcp.WithNumber("days", mcp.Description("How many days back to look (default 30; 0 = all history)")),
+		mcp.WithNumber("limit", mcp.Description("Max activities to return (default 50, max 500)")),
+		mcp.WithString("include_children", mcp.Description("If 'true' and file_id is a folder, include activity on everything inside it (default: false)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		calendarID := request.GetString("calendar_id", "primary")
-		maxResults := int64(request.GetInt("max_results", 10))
-		timeMin := request.GetString("time_min", "")
-		timeMax := request.GetString("time_max", "")
+		fileID, err := request.RequireString("file_id")
+		if err != nil {
+			return mcp.NewToolResultError("file_id is required"), nil
+		}
+		opts := activitysvc.QueryOptions{Limit: min(request.GetInt("limit", 50), 500)}
+		if request.GetString("include_children", "false") == "true" {
+			opts.AncestorName = fileID
+		} else {
+			opts.ItemName = fileID
+		}
+		if days := request.GetInt("days", 30); days > 0 {
+			opts.Since = time.Now().AddDate(0, 0, -days)
+		}
 
-		events, err := calendarService.ListEvents(calendarID, maxResults, timeMin, timeMax)
+		summaries, err := activityService.Query(ctx, opts)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list events: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get activity: %v", err)), nil
 		}
+		resolveActivityActors(ctx, summaries)
 
 		var result string
-		for _, e := range events {
-			start := e.Start.DateTime
-			if start == "" {
-				start = e.Start.Date // All-day event
-			}
-			result += fmt.Sprintf("[%s] %s (%s)\n", start, e.Summary, e.Id)
+		for _, s := range summaries {
+			result += fmt.Sprintf("%s | %s | %s | %s\n", s.Timestamp, s.Action, s.Actor, s.Target)
 		}
-		if len(events) == 0 {
-			result = "No upcoming events found."
+		if len(summaries) == 0 {
+			result = "No activity found for this file."
 		}
 		return mcp.NewToolResultText(result), nil
 	})
 
-	// Tool: Calendar Create Event
-	s.AddTool(mcp.NewTool("calendar_create_event",
-		mcp.WithDescription("Create a new event in Google Calendar"),
-		mcp.WithString("summary", mcp.Required(), mcp.Description("Event title")),
-		mcp.WithString("start_time", mcp.Required(), mcp.Description("Start time (RFC3339, e.g. '2025-01-31T10:00:00Z')")),
-		mcp.WithString("end_time", mcp.Required(), mcp.Description("End time (RFC3339)")),
-		mcp.WithString("description", mcp.Description("Event description")),
-		mcp.WithString("attendees", mcp.Description("Comma-separated list of attendee emails")),
-		mcp.WithString("calendar_id", mcp.Description("Calendar ID (default: 'primary')")),
+	// Tool: Drive Changes Since
+	s.AddTool(mcp.NewTool("drive_changes_since",
+		mcp.WithDescription("List files added, modified, trashed or removed since the previous call, for reliable incremental indexing. "+
+			"The cursor is saved per account in the config directory, so it survives restarts; the first call only establishes a baseline."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("limit", mcp.Description("Max changes to return (default 100); call again while 'more' is true")),
+		mcp.WithString("page_token", mcp.Description("Continue from this page_token instead of the saved cursor")),
+		mcp.WithString("reset", mcp.Description("If 'true', discard the saved cursor and start a new baseline from now")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		summary, err := request.RequireString("summary")
+		about, err := driveService.About(ctx)
 		if err != nil {
-			return mcp.NewToolResultError("summary is required"), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to identify account: %v", err)), nil
 		}
-		startTime, err := request.RequireString("start_time")
-		if err != nil {
-			return mcp.NewToolResultError("start_time is required"), nil
+		account := ""
+		if about.User != nil {
+			account = about.User.EmailAddress
 		}
-		endTime, err := request.RequireString("end_time")
+		dir, err := auth.GetConfigDir()
 		if err != nil {
-			return mcp.NewToolResultError("end_time is required"), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to locate config directory: %v", err)), nil
 		}
-		description := request.GetString("description", "")
-		attendeesStr := request.GetString("attendees", "")
-		calendarID := request.GetString("calendar_id", "primary")
+		path := filepath.Join(dir, "drive_changes.json")
 
-		var attendees []string
-		if attendeesStr != "" {
-			for _, a := range strings.Split(attendeesStr, ",") {
-				attendees = append(attendees, strings.TrimSpace(a))
+		cursor, ok, err := drivesvc.LoadChangeCursor(path, account)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to load change cursor: %v", err)), nil
+		}
+		if token := request.GetString("page_token", ""); token != "" {
+			cursor, ok = drivesvc.ChangeCursor{PageToken: token, Since: cursor.Since}, true
+		}
+		if !ok || request.GetString("reset", "false") == "true" {
+			token, err := driveService.StartPageToken(ctx)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to start change tracking: %v", err)), nil
+			}
+			if err := drivesvc.SaveChangeCursor(path, account, drivesvc.ChangeCursor{PageToken: token, Since: time.Now().UTC()}); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to save change cursor: %v", err)), nil
 			}
+			return mcp.NewToolResultText(fmt.Sprintf("Baseline established for %s; call again to get changes.\npage_token: %s", account, token)), nil
 		}
 
-		event, err := calendarService.CreateEvent(calendarID, summary, description, startTime, endTime, attendees)
+		started := time.Now().UTC()
+		changes, err := driveService.ChangesSince(ctx, cursor.PageToken, cursor.Since, request.GetInt("limit", 100))
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to create event: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list changes: %v", err)), nil
 		}
-
-		return mcp.NewToolResultText(fmt.Sprintf("Created event: %s (ID: %s)", event.Summary, event.Id)), nil
+		next := drivesvc.ChangeCursor{PageToken: changes.PageToken, Since: cursor.Since}
+		if !changes.More {
+			next.Since = started
+		}
+		if err := drivesvc.SaveChangeCursor(path, account, next); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to save change cursor: %v", err)), nil
+		}
+		jsonBytes, _ := json.MarshalIndent(changes, "", "  ")
+		return mcp.NewToolResultText(string(jsonBytes)), nil
 	})
 
-	// Tool: Calendar Delete Event
-	s.AddTool(mcp.NewTool("calendar_delete_event",
-		mcp.WithDescription("Delete an event from Google Calendar"),
-		mcp.WithString("event_id", mcp.Required(), mcp.Description("ID of the event to delete")),
-		mcp.WithString("calendar_id", mcp.Description("Calendar ID (default: 'primary')")),
+	// Tool: Drive List Comments
+	s.AddTool(mcp.NewTool("drive_list_comments",
+		mcp.WithDescription("List comments on a Drive file (e.g. Google Doc, Sheet). Use file_id from drive_search or drive_find_files."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("file_id", mcp.Required(), mcp.Description("ID of the file")),
+		mcp.WithNumber("limit", mcp.Description("Max comments to return (default 50, max 100)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		eventID, err := request.RequireString("event_id")
+		fileID, err := request.RequireString("file_id")
 		if err != nil {
-			return mcp.NewToolResultError("event_id is required"), nil
+			return mcp.NewToolResultError("file_id is required"), nil
 		}
-		calendarID := request.GetString("calendar_id", "primary")
+		limit := int64(request.GetInt("limit", 50))
 
-		if err := calendarService.DeleteEvent(calendarID, eventID); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete event: %v", err)), nil
+		comments, err := driveService.ListComments(ctx, fileID, limit)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list comments: %v", err)), nil
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("Deleted event: %s", eventID)), nil
-	})
-
-	// Tool: Sheets Create Spreadsheet
-	s.AddTool(mcp.NewTool("sheets_create_spreadsheet",
-		mcp.WithDescription("Create a new Google Sheet"),
-		mcp.WithString("title", mcp.Required(), mcp.Description("Title of the spreadsheet")),
+		var result string
+		for _, c := range comments {
+			author := "unknown"
+			if c.Author != nil && c.Author.DisplayName != "" {
+				author = c.Author.DisplayName
+			}
+			resolved := ""
+			if c.Resolved {
+				resolved = " [resolved]"
+			}
+			result += fmt.Sprintf("[%s] %s | %s | %s%s\n", c.Id, c.CreatedTime, author, c.Content, resolved)
+			if c.QuotedFileContent != nil && c.QuotedFileContent.Value != "" {
+				result += fmt.Sprintf("  on: \"%s\"\n", c.QuotedFileContent.Value)
+			}
+			for _, r := range c.Replies {
+				replyAuthor := "unknown"
+				if r.Author != nil && r.Author.DisplayName != "" {
+					replyAuthor = r.Author.DisplayName
+				}
+				action := ""
+				if r.Action != "" {
+					action = fmt.Sprintf(" (%sd)", r.Action)
+				}
+				result += fmt.Sprintf("  ↳ [%s] %s | %s | %s%s\n", r.Id, r.CreatedTime, replyAuthor, r.Content, action)
+			}
+		}
+		if len(comments) == 0 {
+			result = "No comments found."
+		}
+		return mcp.NewToolResultText(result), nil
+	})
+
+	// Tool: Drive Add Comment
+	s.AddTool(mcp.NewTool("drive_add_comment",
+		mcp.WithDescription("Add a comment to a Drive file (e.g. Google Doc, Sheet). Use file_id from drive_search or drive_find_files."),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("file_id", mcp.Required(), mcp.Description("ID of the file")),
+		mcp.WithString("content", mcp.Required(), mcp.Description("Plain text content of the comment")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		title, err := request.RequireString("title")
+		fileID, err := request.RequireString("file_id")
 		if err != nil {
-			return mcp.NewToolResultError("title is required"), nil
+			return mcp.NewToolResultError("file_id is required"), nil
 		}
-
-		sp, err := sheetsService.CreateSpreadsheet(title)
+		content, err := request.RequireString("content")
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to create spreadsheet: %v", err)), nil
+			return mcp.NewToolResultError("content is required"), nil
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("Created spreadsheet: %s (ID: %s)\nURL: %s", sp.Properties.Title, sp.SpreadsheetId, sp.SpreadsheetUrl)), nil
+		comment, err := driveService.CreateComment(ctx, fileID, content)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to add comment: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Comment added (ID: %s)", comment.Id)), nil
 	})
 
-	// Tool: Sheets Read Values
-	s.AddTool(mcp.NewTool("sheets_read_values",
-		mcp.WithDescription("Read values from a Google Sheet range"),
-		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
-		mcp.WithString("range", mcp.Required(), mcp.Description("A1 notation range (e.g. 'Sheet1!A1:C10')")),
+	// Tool: Drive Reply Comment
+	s.AddTool(mcp.NewTool("drive_reply_comment",
+		mcp.WithDescription("Reply to a comment on a Drive file. Use comment IDs from drive_list_comments."),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("file_id", mcp.Required(), mcp.Description("ID of the file")),
+		mcp.WithString("comment_id", mcp.Required(), mcp.Description("ID of the comment")),
+		mcp.WithString("content", mcp.Required(), mcp.Description("Plain text content of the reply")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		fileID, err := request.RequireString("file_id")
 		if err != nil {
-			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+			return mcp.NewToolResultError("file_id is required"), nil
 		}
-		rangeName, err := request.RequireString("range")
+		commentID, err := request.RequireString("comment_id")
 		if err != nil {
-			return mcp.NewToolResultError("range is required"), nil
+			return mcp.NewToolResultError("comment_id is required"), nil
 		}
-
-		values, err := sheetsService.ReadValues(spreadsheetID, rangeName)
+		content, err := request.RequireString("content")
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to read values: %v", err)), nil
+			return mcp.NewToolResultError("content is required"), nil
 		}
 
-		if len(values) == 0 {
-			return mcp.NewToolResultText("No data found."), nil
+		reply, err := driveService.ReplyToComment(ctx, fileID, commentID, content, "")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to reply: %v", err)), nil
 		}
-
-		// JSON output is often best for structured data analysis by AI
-		jsonBytes, _ := json.MarshalIndent(values, "", "  ")
-		return mcp.NewToolResultText(string(jsonBytes)), nil
+		return mcp.NewToolResultText(fmt.Sprintf("Replied to comment %s (reply ID: %s)", commentID, reply.Id)), nil
 	})
 
-	// Tool: Sheets Append Values
-	s.AddTool(mcp.NewTool("sheets_append_values",
-		mcp.WithDescription("Append values to a Google Sheet (new rows)"),
-		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
-		mcp.WithString("range", mcp.Required(), mcp.Description("A1 notation range (e.g. 'Sheet1!A1')")),
-		mcp.WithString("values_json", mcp.Required(), mcp.Description("JSON array of arrays (e.g. '[[\"A\", \"B\"]]') or single array for one row")),
+	// Tool: Drive Resolve Comment
+	s.AddTool(mcp.NewTool("drive_resolve_comment",
+		mcp.WithDescription("Resolve (or reopen) a comment on a Drive file, optionally with a closing reply"),
+		mcp.WithString("file_id", mcp.Required(), mcp.Description("ID of the file")),
+		mcp.WithString("comment_id", mcp.Required(), mcp.Description("ID of the comment")),
+		mcp.WithString("content", mcp.Description("Reply to post while resolving (optional)")),
+		mcp.WithString("reopen", mcp.Description("If 'true', reopen a resolved comment instead (default: false)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		fileID, err := request.RequireString("file_id")
 		if err != nil {
-			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+			return mcp.NewToolResultError("file_id is required"), nil
 		}
-		rangeName, err := request.RequireString("range")
+		commentID, err := request.RequireString("comment_id")
 		if err != nil {
-			return mcp.NewToolResultError("range is required"), nil
+			return mcp.NewToolResultError("comment_id is required"), nil
 		}
-		valuesJSON, err := request.RequireString("values_json")
-		if err != nil {
-			return mcp.NewToolResultError("values_json is required"), nil
+		content := request.GetString("content", "")
+		action := "resolve"
+		if request.GetString("reopen", "false") == "true" {
+			action = "reopen"
 		}
 
-		resp, err := sheetsService.AppendValues(spreadsheetID, rangeName, valuesJSON)
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to append values: %v", err)), nil
+		if _, err := driveService.ReplyToComment(ctx, fileID, commentID, content, action); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to %s comment: %v", action, err)), nil
 		}
-
-		return mcp.NewToolResultText(fmt.Sprintf("Appended %d cells.", resp.Updates.UpdatedCells)), nil
+		return mcp.NewToolResultText(fmt.Sprintf("Comment %s %sd", commentID, action)), nil
 	})
 
-	// Tool: Sheets Update Values
-	s.AddTool(mcp.NewTool("sheets_update_values",
-		mcp.WithDescription("Update values in a Google Sheet range (overwrite)"),
-		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
-		mcp.WithString("range", mcp.Required(), mcp.Description("A1 notation range (e.g. 'Sheet1!A1')")),
-		mcp.WithString("values_json", mcp.Required(), mcp.Description("JSON array of arrays")),
+	// Tool: Gmail List Threads
+	s.AddTool(mcp.NewTool("gmail_list_threads",
+		mcp.WithDescription("List/Search email threads in Gmail"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("query", mcp.Description("Gmail search query (e.g. 'from:boss', 'is:unread')")),
+		mcp.WithNumber("limit", mcp.Description("Max threads to return (default 10)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		query := request.GetString("query", "")
+		limit := int64(request.GetInt("limit", 10))
+
+		threads, err := gmailService.ListThreads(ctx, query, limit)
 		if err != nil {
-			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list threads: %v", err)), nil
 		}
-		rangeName, err := request.RequireString("range")
-		if err != nil {
-			return mcp.NewToolResultError("range is required"), nil
+
+		var result string
+		for _, t := range threads {
+			result += fmt.Sprintf("[Thread ID: %s] %s\n", t.Id, t.Snippet)
 		}
-		valuesJSON, err := request.RequireString("values_json")
-		if err != nil {
-			return mcp.NewToolResultError("values_json is required"), nil
+		if len(threads) == 0 {
+			result = "No threads found."
 		}
+		return mcp.NewToolResultText(result), nil
+	})
+
+	// Tool: Gmail Search Messages
+	s.AddTool(mcp.NewTool("gmail_search_messages",
+		mcp.WithDescription("Search Gmail messages and return structured JSON metadata per message (from, to, subject, date, labels, has_attachments, size) without bodies. Use next_page_token to page through results."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("query", mcp.Description("Gmail search query (e.g. 'from:boss', 'is:unread newer_than:7d')")),
+		mcp.WithNumber("limit", mcp.Description("Max messages per page (default 10, max 100)")),
+		mcp.WithString("page_token", mcp.Description("Page token from a previous response for the next page")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		query := request.GetString("query", "")
+		limit := int64(request.GetInt("limit", 10))
+		pageToken := request.GetString("page_token", "")
 
-		resp, err := sheetsService.UpdateValues(spreadsheetID, rangeName, valuesJSON)
+		res, err := gmailService.SearchMessages(ctx, query, limit, pageToken)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to update values: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to search messages: %v", err)), nil
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("Updated %d cells.", resp.UpdatedCells)), nil
+		jsonBytes, _ := json.MarshalIndent(res, "", "  ")
+		return mcp.NewToolResultText(string(jsonBytes)), nil
 	})
 
-	// Tool: Sheets Get Spreadsheet (metadata, sheet IDs and titles)
-	s.AddTool(mcp.NewTool("sheets_get_spreadsheet",
-		mcp.WithDescription("Get spreadsheet metadata including sheet IDs and titles"),
-		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
+	// Tool: Gmail Read Thread
+	s.AddTool(mcp.NewTool("gmail_read_thread",
+		mcp.WithDescription("Read a specific email thread, optionally a window of its messages for long threads. HTML-only messages are converted to text; quoted reply history is stripped by default."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("thread_id", mcp.Required(), mcp.Description("ID of the thread to read")),
+		mcp.WithString("strip_quoted", mcp.Description("If 'false', keep quoted reply history in each message (default: true)")),
+		mcp.WithNumber("max_messages", mcp.Description("Max messages to return (default 20, 0 = all)")),
+		mcp.WithNumber("message_offset", mcp.Description("Index of the first message to return (default 0; negative counts from the end, e.g. -3 for the last three)")),
+		mcp.WithString("full_body", mcp.Description("If 'true', do not truncate message bodies at 2000 characters (default: false)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		threadID, err := request.RequireString("thread_id")
 		if err != nil {
-			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+			return mcp.NewToolResultError("thread_id is required"), nil
 		}
-		sp, err := sheetsService.GetSpreadsheet(spreadsheetID)
+		stripQuoted := request.GetString("strip_quoted", "true") != "false"
+		maxMessages := request.GetInt("max_messages", 20)
+		offset := request.GetInt("message_offset", 0)
+		fullBody := request.GetString("full_body", "false") == "true"
+
+		window, err := gmailService.GetThreadWindow(ctx, threadID, offset, maxMessages)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get spreadsheet: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get thread: %v", err)), nil
 		}
-		type sheetInfo struct {
-			SheetId int64  `json:"sheetId"`
-			Title   string `json:"title"`
+
+		var result string
+		result += fmt.Sprintf("Thread ID: %s\n", window.ThreadID)
+		if len(window.Messages) < window.Total {
+			result += fmt.Sprintf("Showing messages %d-%d of %d\n", window.Offset+1, window.Offset+len(window.Messages), window.Total)
 		}
-		var sheets []sheetInfo
-		for _, sh := range sp.Sheets {
-			if sh.Properties != nil {
-				sheets = append(sheets, sheetInfo{SheetId: sh.Properties.SheetId, Title: sh.Properties.Title})
+		for _, msg := range window.Messages {
+			subject := gmailsvc.GetHeader(msg.Payload.Headers, "Subject")
+			from := gmailsvc.GetHeader(msg.Payload.Headers, "From")
+			date := gmailsvc.GetHeader(msg.Payload.Headers, "Date")
+			body := gmailsvc.ExtractMessageText(msg.Payload, stripQuoted)
+
+			// Truncate body if too long for safety
+			if !fullBody && len(body) > 2000 {
+				body = body[:2000] + "...(truncated)"
 			}
+
+			result += fmt.Sprintf("---\nMsg ID: %s\nFrom: %s\nDate: %s\nSubject: %s\n\n%s\n", msg.Id, from, date, subject, body)
 		}
-		out := map[string]interface{}{"spreadsheetId": sp.SpreadsheetId, "title": sp.Properties.Title, "sheets": sheets}
-		jsonBytes, _ := json.MarshalIndent(out, "", "  ")
-		return mcp.NewToolResultText(string(jsonBytes)), nil
+		if next := window.Offset + len(window.Messages); next < window.Total {
+			result += fmt.Sprintf("---\nMore messages available: use message_offset=%d\n", next)
+		}
+
+		return mcp.NewToolResultText(result), nil
 	})
 
-	// Tool: Sheets Batch Update (add sheet, rename sheet, etc.)
-	s.AddTool(mcp.NewTool("sheets_batch_update",
-		mcp.WithDescription("Apply batch update: add sheets, rename sheets. Pass requests as JSON (e.g. {\"requests\": [{\"addSheet\": {\"properties\": {\"title\": \"TabName\"}}}]})"),
+	// Tool: Gmail Extract To Sheet
+	s.AddTool(mcp.NewTool("gmail_extract_to_sheet",
+		mcp.WithDescription("Run a Gmail search and append a row per message to a Google Sheet: date, sender, subject, values captured by regular expressions (amounts, order or invoice numbers), a link to the thread and the message ID. Messages already in the sheet are skipped, so running the same search again only adds new ones: made for tracking receipts and invoices."),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("query", mcp.Required(), mcp.Description("Gmail search query, e.g. 'from:receipts@example.com newer_than:30d'")),
 		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
-		mcp.WithString("requests_json", mcp.Required(), mcp.Description("JSON object with \"requests\" array (Google Sheets BatchUpdateSpreadsheetRequest)")),
+		mcp.WithString("sheet", mcp.Description("Tab title or sheet ID (default: first tab); missing columns are added to its header row")),
+		mcp.WithString("fields", mcp.Description("JSON object of column names to regular expressions, matched against the subject and body; the first capturing group is the value, e.g. {\"Amount\": \"Total:\\\\s*\\\\$?([\\\\d.,]+)\", \"Order\": \"(?i)order #(\\\\w+)\"}")),
+		mcp.WithNumber("max_messages", mcp.Description("Max messages to read, newest first (default 50, max 500)")),
+		mcp.WithString("time_zone", mcp.Description("Time zone of the Date column, e.g. 'America/Sao_Paulo' (default: UTC)")),
+		mcp.WithString("skip_unmatched", mcp.Description("If 'true', leave out messages where no field matched (default: false)")),
+		mcp.WithString("dry_run", mcp.Description("If 'true', write nothing and return a cell-level preview of the rows (default: false)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		query, err := request.RequireString("query")
+		if err != nil {
+			return mcp.NewToolResultError("query is required"), nil
+		}
 		spreadsheetID, err := request.RequireString("spreadsheet_id")
 		if err != nil {
 			return mcp.NewToolResultError("spreadsheet_id is required"), nil
 		}
-		requestsJSON, err := request.RequireString("requests_json")
+		sheet := request.GetString("sheet", "")
+		fields, err := gmailsvc.ParseExtractFields(request.GetString("fields", ""))
 		if err != nil {
-			return mcp.NewToolResultError("requests_json is required"), nil
+			return mcp.NewToolResultError(err.Error()), nil
 		}
-		var req sheets.BatchUpdateSpreadsheetRequest
-		if err := json.Unmarshal([]byte(requestsJSON), &req); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf(
-				"Invalid requests_json format. Expected Google Sheets BatchUpdateSpreadsheetRequest structure. "+
-					"Example: {\"requests\":[{\"addSheet\":{\"properties\":{\"title\":\"Sheet\"}}}]}. Error: %v",
-				err)), nil
+		limit := request.GetInt("max_messages", 50)
+		if limit < 1 || limit > 500 {
+			return mcp.NewToolResultError("max_messages must be between 1 and 500"), nil
 		}
-		resp, err := sheetsService.BatchUpdate(spreadsheetID, &req)
+		tz := request.GetString("time_zone", "UTC")
+		loc, err := time.LoadLocation(tz)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to batch update: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid time_zone %q: %v", tz, err)), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Batch update applied. Replies: %d", len(resp.Replies))), nil
-	})
+		skipUnmatched := request.GetString("skip_unmatched", "false") == "true"
 
-	// Tool: Sheets Clear Values
-	s.AddTool(mcp.NewTool("sheets_clear_values",
-		mcp.WithDescription("Clear values in a range"),
-		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
-		mcp.WithString("range", mcp.Required(), mcp.Description("A1 notation range (e.g. 'Sheet1!A7:Z100')")),
-	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		existing, err := sheetsService.ColumnValues(ctx, spreadsheetID, sheet, 1, gmailsvc.MessageIDColumn)
 		if err != nil {
-			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to read the sheet: %v", err)), nil
 		}
-		rangeName, err := request.RequireString("range")
+		ids, err := gmailService.ListMessageIDs(ctx, query, limit)
 		if err != nil {
-			return mcp.NewToolResultError("range is required"), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to search messages: %v", err)), nil
+		}
+		found := len(ids)
+		ids = slices.DeleteFunc(ids, func(id string) bool { return slices.Contains(existing, id) })
+		known := found - len(ids)
+
+		columns := gmailsvc.ExtractColumns(fields)
+		var records []sheetssvc.Record
+		unmatched := 0
+		// Oldest first, so that the rows stay in date order across runs
+		for _, id := range slices.Backward(ids) {
+			m, err := gmailService.GetMessage(ctx, id)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to get message %s: %v", id, err)), nil
+			}
+			values, matched := gmailsvc.ExtractMessage(m, fields, loc)
+			if matched == 0 && len(fields) > 0 {
+				unmatched++
+				if skipUnmatched {
+					continue
+				}
+			}
+			rec := sheetssvc.Record{Keys: columns, Values: make(map[string]interface{}, len(columns))}
+			for i, c := range columns {
+				rec.Values[c] = values[i]
+			}
+			records = append(records, rec)
+		}
+		if len(records) == 0 {
+			return mcp.NewToolResultText(fmt.Sprintf("No rows to add: %d messages found, %d already in the sheet, %d without any field match", found, known, unmatched)), nil
 		}
-		_, err = sheetsService.ClearValues(spreadsheetID, rangeName)
+
+		// Values are written as-is, so that message content is never run as a formula
+		res, err := sheetsService.AppendRecords(ctx, spreadsheetID, sheet, 1, records, true, true, request.GetString("dry_run", "false") == "true")
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to clear values: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to append rows: %v", err)), nil
+		}
+		if res.Preview != nil {
+			return mcp.NewToolResultText(sheetssvc.FormatPreview(res.Preview)), nil
+		}
+		msg := fmt.Sprintf("Appended %d rows at %s", res.Rows, res.UpdatedRange)
+		if len(res.AddedColumns) > 0 {
+			msg += fmt.Sprintf("\nAdded columns: %s", strings.Join(res.AddedColumns, ", "))
+		}
+		if known > 0 {
+			msg += fmt.Sprintf("\nSkipped %d messages already in the sheet", known)
+		}
+		if unmatched > 0 {
+			verb := "added with empty fields"
+			if skipUnmatched {
+				verb = "left out"
+			}
+			msg += fmt.Sprintf("\n%d messages without any field match were %s", unmatched, verb)
 		}
-		return mcp.NewToolResultText("Range cleared."), nil
+		return mcp.NewToolResultText(msg), nil
 	})
 
-	// Tool: People List Connections
-	s.AddTool(mcp.NewTool("people_list_connections",
-		mcp.WithDescription("List contacts (connections)"),
-		mcp.WithNumber("limit", mcp.Description("Max contacts to return (default 10)")),
+	// Tool: Gmail Export Thread
+	s.AddTool(mcp.NewTool("gmail_export_thread",
+		mcp.WithDescription("Export a whole email thread (headers, bodies without quoted history, attachment names) to Drive, as a Google Doc, a text file or a PDF, to archive a decision or share its context. Returns the file link."),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("thread_id", mcp.Required(), mcp.Description("ID of the thread to export")),
+		mcp.WithString("format", mcp.Description("'doc' (Google Doc), 'text' or 'pdf' (default: doc)")),
+		mcp.WithString("parent_id", mcp.Description("Drive folder ID to save into (default: My Drive)")),
+		mcp.WithString("name", mcp.Description("File name (default: the thread's subject)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		limit := int64(request.GetInt("limit", 10))
-
-		connections, err := peopleService.ListConnections(limit)
+		threadID, err := request.RequireString("thread_id")
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list connections: %v", err)), nil
+			return mcp.NewToolResultError("thread_id is required"), nil
 		}
-
-		var result string
-		for _, p := range connections {
-			name := "Unknown"
-			if len(p.Names) > 0 {
-				name = p.Names[0].DisplayName
+		format := request.GetString("format", "doc")
+		if format != "doc" && format != "text" && format != "pdf" {
+			return mcp.NewToolResultError("format must be 'doc', 'text' or 'pdf'"), nil
+		}
+		if format != "text" {
+			// Docs and PDFs are written through the Docs API
+			if !serviceEnabled(services, "docs") {
+				return mcp.NewToolResultError(fmt.Sprintf("format '%s' needs the docs service, which is not enabled: use format 'text'", format)), nil
 			}
-			email := ""
-			if len(p.EmailAddresses) > 0 {
-				email = p.EmailAddresses[0].Value
+			if err := registry.ensure("docs"); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to initialize service: %v", err)), nil
 			}
-			result += fmt.Sprintf("Name: %s | Email: %s | ResourceName: %s\n", name, email, p.ResourceName)
-		}
-		if len(connections) == 0 {
-			result = "No connections found."
 		}
-		return mcp.NewToolResultText(result), nil
-	})
+		parentID := request.GetString("parent_id", "")
 
-	// Tool: People Create Contact
-	s.AddTool(mcp.NewTool("people_create_contact",
-		mcp.WithDescription("Create a new contact"),
-		mcp.WithString("given_name", mcp.Required(), mcp.Description("First name")),
-		mcp.WithString("family_name", mcp.Description("Last name")),
-		mcp.WithString("email", mcp.Description("Email address")),
-	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		givenName, err := request.RequireString("given_name")
+		thread, err := gmailService.GetThread(ctx, threadID)
 		if err != nil {
-			return mcp.NewToolResultError("given_name is required"), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get thread: %v", err)), nil
+		}
+		name := request.GetString("name", gmailsvc.ThreadSubject(thread))
+		if name == "" {
+			name = "Email thread " + threadID
+		}
+
+		if format == "text" {
+			file, err := driveService.CreateFile(ctx, name+".txt", parentID, gmailsvc.ThreadText(thread), "text/plain")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to export thread: %v", err)), nil
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Exported thread to: %s (ID: %s)\nLink: https://drive.google.com/file/d/%s/view", file.Name, file.Id, file.Id)), nil
 		}
-		familyName := request.GetString("family_name", "")
-		email := request.GetString("email", "")
 
-		person, err := peopleService.CreateContact(givenName, familyName, email)
+		doc, err := docsService.CreateDocument(ctx, name)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to create contact: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to export thread: %v", err)), nil
+		}
+		if err := docsService.InsertMarkdown(ctx, doc.DocumentId, gmailsvc.ThreadMarkdown(thread), false); err != nil {
+			_ = driveService.DeletePermanently(ctx, doc.DocumentId)
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to export thread: %v", err)), nil
+		}
+		if format == "doc" {
+			if parentID != "" {
+				if _, err := driveService.MoveFile(ctx, doc.DocumentId, parentID); err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("Exported thread to document %s, but failed to move it to the folder: %v", doc.DocumentId, err)), nil
+				}
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Exported thread to: %s (ID: %s)\nLink: https://docs.google.com/document/d/%s/edit", doc.Title, doc.DocumentId, doc.DocumentId)), nil
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("Created contact: %s (ID: %s)", givenName, person.ResourceName)), nil
+		// PDF: export the document, which is only a step, then remove it
+		defer func() {
+			_ = driveService.DeletePermanently(context.WithoutCancel(ctx), doc.DocumentId)
+		}()
+		pdf, err := driveService.DownloadFile(ctx, doc.DocumentId, "application/pdf")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to export thread: %v", err)), nil
+		}
+		file, err := driveService.UploadFile(ctx, name+".pdf", parentID, "application/pdf", bytes.NewReader(pdf.Data), nil)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to export thread: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Exported thread to: %s (ID: %s)\nLink: https://drive.google.com/file/d/%s/view", file.Name, file.Id, file.Id)), nil
 	})
 
-	// Tool: Docs Create Document
-	s.AddTool(mcp.NewTool("docs_create_document",
-		mcp.WithDescription("Create a new Google Doc"),
-		mcp.WithString("title", mcp.Required(), mcp.Description("Document title")),
-		mcp.WithString("initial_text", mcp.Description("Initial text content to insert")),
+	// Tool: Gmail Get Message
+	s.AddTool(mcp.NewTool("gmail_get_message",
+		mcp.WithDescription("Get a single email message by ID: headers, decoded body and attachment metadata."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("message_id", mcp.Required(), mcp.Description("ID of the message to read")),
+		mcp.WithString("strip_quoted", mcp.Description("If 'false', keep quoted reply history (default: true)")),
+		mcp.WithString("full_body", mcp.Description("If 'true', do not truncate the body at 2000 characters (default: false)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		title, err := request.RequireString("title")
+		messageID, err := request.RequireString("message_id")
 		if err != nil {
-			return mcp.NewToolResultError("title is required"), nil
+			return mcp.NewToolResultError("message_id is required"), nil
 		}
-		initialText := request.GetString("initial_text", "")
+		stripQuoted := request.GetString("strip_quoted", "true") != "false"
+		fullBody := request.GetString("full_body", "false") == "true"
 
-		doc, err := docsService.CreateDocument(title)
+		msg, err := gmailService.GetMessage(ctx, messageID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to create document: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get message: %v", err)), nil
 		}
 
-		if initialText != "" {
-			if err := docsService.InsertText(doc.DocumentId, initialText); err != nil {
-				// We still return success for creation, but note the error
-				return mcp.NewToolResultText(fmt.Sprintf("Created document: %s (ID: %s)\nWarning: Failed to insert initial text: %v", doc.Title, doc.DocumentId, err)), nil
+		headers := msg.Payload.Headers
+		result := fmt.Sprintf("Msg ID: %s\nThread ID: %s\n", msg.Id, msg.ThreadId)
+		for _, name := range []string{"From", "To", "Cc", "Date", "Subject"} {
+			if v := gmailsvc.GetHeader(headers, name); v != "" {
+				result += fmt.Sprintf("%s: %s\n", name, v)
 			}
 		}
+		if len(msg.LabelIds) > 0 {
+			result += fmt.Sprintf("Labels: %s\n", strings.Join(msg.LabelIds, ", "))
+		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("Created document: %s (ID: %s)", doc.Title, doc.DocumentId)), nil
-	})
+		body := gmailsvc.ExtractMessageText(msg.Payload, stripQuoted)
+		if !fullBody && len(body) > 2000 {
+			body = body[:2000] + "...(truncated)"
+		}
+		result += "\n" + body + "\n"
 
-	// Tool: Docs Read Document
-	s.AddTool(mcp.NewTool("docs_read_document",
-		mcp.WithDescription("Read a Google Doc"),
-		mcp.WithString("document_id", mcp.Required(), mcp.Description("ID of the document")),
-	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		docID, err := request.RequireString("document_id")
-		if err != nil {
-			return mcp.NewToolResultError("document_id is required"), nil
-		}
-
-		doc, err := docsService.GetDocument(docID)
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to read document: %v", err)), nil
-		}
-
-		// Very basic text extraction
-		var text string
-		if doc.Body != nil {
-			for _, elem := range doc.Body.Content {
-				if elem.Paragraph != nil {
-					for _, paraElem := range elem.Paragraph.Elements {
-						if paraElem.TextRun != nil {
-							text += paraElem.TextRun.Content
-						}
-					}
-				}
+		if attachments := gmailsvc.MessageAttachments(msg); len(attachments) > 0 {
+			result += "\nAttachments:\n"
+			for _, a := range attachments {
+				result += fmt.Sprintf("- %s (%s, %d bytes) | attachment_id: %s\n", a.Filename, a.MimeType, a.Size, a.AttachmentID)
 			}
 		}
-
-		return mcp.NewToolResultText(fmt.Sprintf("Title: %s\n\n%s", doc.Title, text)), nil
+		return mcp.NewToolResultText(result), nil
 	})
 
-	// Tool: Tasks List Task Lists
-	s.AddTool(mcp.NewTool("tasks_list_tasklists",
-		mcp.WithDescription("List the user's Google Tasks task lists. Call this first to get task_list_id for other tasks operations."),
-		mcp.WithNumber("max_results", mcp.Description("Max task lists to return (default 100)")),
+	// Tool: Gmail List Attachments
+	s.AddTool(mcp.NewTool("gmail_list_attachments",
+		mcp.WithDescription("List attachments in an email thread or a single message. Use the returned message_id and attachment_id with gmail_download_attachment."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("thread_id", mcp.Description("ID of the thread (lists attachments of all messages)")),
+		mcp.WithString("message_id", mcp.Description("ID of a single message (used if thread_id is not set)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		maxResults := int64(request.GetInt("max_results", 100))
-
-		lists, err := tasksService.ListTaskLists(maxResults)
+		threadID := request.GetString("thread_id", "")
+		messageID := request.GetString("message_id", "")
+
+		var attachments []gmailsvc.AttachmentInfo
+		var err error
+		switch {
+		case threadID != "":
+			attachments, err = gmailService.ListThreadAttachments(ctx, threadID)
+		case messageID != "":
+			attachments, err = gmailService.ListMessageAttachments(ctx, messageID)
+		default:
+			return mcp.NewToolResultError("thread_id or message_id is required"), nil
+		}
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list task lists: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list attachments: %v", err)), nil
 		}
 
 		var result string
-		for _, l := range lists {
-			result += fmt.Sprintf("ID: %s | Title: %s\n", l.Id, l.Title)
+		for _, a := range attachments {
+			result += fmt.Sprintf("%s (%s, %d bytes) | message_id: %s | attachment_id: %s\n", a.Filename, a.MimeType, a.Size, a.MessageID, a.AttachmentID)
 		}
-		if len(lists) == 0 {
-			result = "No task lists found."
+		if len(attachments) == 0 {
+			result = "No attachments found."
 		}
 		return mcp.NewToolResultText(result), nil
 	})
 
-	// Tool: Tasks List Tasks
-	s.AddTool(mcp.NewTool("tasks_list_tasks",
-		mcp.WithDescription("List tasks in a Google Tasks list. Use tasks_list_tasklists first to get task_list_id."),
-		mcp.WithString("task_list_id", mcp.Required(), mcp.Description("ID of the task list")),
-		mcp.WithString("show_completed", mcp.Description("Include completed tasks: 'true' or 'false' (default: false to reduce output)")),
-		mcp.WithNumber("max_results", mcp.Description("Max tasks to return (default 20, max 100)")),
+	// Tool: Gmail Download Attachment
+	s.AddTool(mcp.NewTool("gmail_download_attachment",
+		mcp.WithDescription("Download an email attachment. Returns base64 content (size-limited), or saves it to Google Drive with save_to_drive='true'."),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("message_id", mcp.Required(), mcp.Description("ID of the message containing the attachment")),
+		mcp.WithString("attachment_id", mcp.Required(), mcp.Description("Attachment ID from gmail_list_attachments")),
+		mcp.WithString("save_to_drive", mcp.Description("If 'true', upload the attachment to Drive instead of returning it (default: false)")),
+		mcp.WithString("parent_id", mcp.Description("Drive folder ID to save into when save_to_drive is 'true' (optional)")),
+		mcp.WithString("filename", mcp.Description("File name to use in Drive (default: original attachment name)")),
+		mcp.WithNumber("max_bytes", mcp.Description("Max attachment size to return as base64 (default 1048576)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		taskListID, err := request.RequireString("task_list_id")
+		messageID, err := request.RequireString("message_id")
 		if err != nil {
-			return mcp.NewToolResultError("task_list_id is required"), nil
+			return mcp.NewToolResultError("message_id is required"), nil
 		}
-		showCompleted := request.GetString("show_completed", "false") == "true"
-		maxResults := int64(request.GetInt("max_results", 20))
+		attachmentID, err := request.RequireString("attachment_id")
+		if err != nil {
+			return mcp.NewToolResultError("attachment_id is required"), nil
+		}
+		saveToDrive := request.GetString("save_to_drive", "false") == "true"
+		parentID := request.GetString("parent_id", "")
+		filename := request.GetString("filename", "")
+		maxBytes := int64(request.GetInt("max_bytes", 1024*1024))
 
-		taskList, err := tasksService.ListTasks(taskListID, taskssvc.ListTasksOptions{
-			ShowCompleted: showCompleted,
-			MaxResults:    maxResults,
-		})
+		att, err := gmailService.GetAttachment(ctx, messageID, attachmentID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list tasks: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to download attachment: %v", err)), nil
+		}
+		if filename == "" {
+			filename = att.Filename
+		}
+		if filename == "" {
+			filename = "attachment"
 		}
 
-		var result string
-		for _, t := range taskList {
-			status := t.Status
-			if status == "" {
-				status = "needsAction"
-			}
-			due := ""
-			if t.Due != "" {
-				due = " | Due: " + t.Due
+		if saveToDrive {
+			file, err := driveService.CreateFile(ctx, filename, parentID, string(att.Data), att.MimeType)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to save attachment to Drive: %v", err)), nil
 			}
-			result += fmt.Sprintf("[%s] %s | Status: %s%s\n", t.Id, t.Title, status, due)
+			return mcp.NewToolResultText(fmt.Sprintf("Saved attachment to Drive: %s (ID: %s, %d bytes)", file.Name, file.Id, att.Size)), nil
 		}
-		if len(taskList) == 0 {
-			result = "No tasks found."
+
+		if att.Size > maxBytes {
+			return mcp.NewToolResultError(fmt.Sprintf("Attachment is %d bytes, larger than max_bytes (%d). Use save_to_drive='true' or raise max_bytes.", att.Size, maxBytes)), nil
 		}
-		return mcp.NewToolResultText(result), nil
+		return mcp.NewToolResultText(fmt.Sprintf("Filename: %s\nMimeType: %s\nSize: %d bytes\nBase64:\n%s", filename, att.MimeType, att.Size, base64.StdEncoding.EncodeToString(att.Data))), nil
 	})
 
-	// Tool: Tasks Insert Task
-	s.AddTool(mcp.NewTool("tasks_insert_task",
-		mcp.WithDescription("Create a new task in a Google Tasks list"),
-		mcp.WithString("task_list_id", mcp.Required(), mcp.Description("ID of the task list")),
-		mcp.WithString("title", mcp.Required(), mcp.Description("Task title")),
-		mcp.WithString("notes", mcp.Description("Optional notes")),
-		mcp.WithString("due", mcp.Description("Due date (RFC3339 date, e.g. 2025-02-01)")),
+	// Tool: Gmail Send Email
+	s.AddTool(mcp.NewTool("gmail_send_email",
+		mcp.WithDescription("Send an email with a plain text and/or HTML body, optionally with attachments from Google Drive or local files"),
+		mcp.WithString("to", mcp.Required(), mcp.Description("Recipient email address")),
+		mcp.WithString("subject", mcp.Required(), mcp.Description("Email subject")),
+		mcp.WithString("body", mcp.Description("Plain text body (required unless body_html is set)")),
+		mcp.WithString("body_html", mcp.Description("HTML body (optional; sent with body as a text fallback when both are set)")),
+		mcp.WithString("attachment_drive_ids", mcp.Description("Comma-separated Drive file IDs to attach (Google Docs/Sheets/Slides are attached as PDF)")),
+		mcp.WithString("attachment_paths", mcp.Description("Comma-separated local file paths to attach, inside the server's local files directory")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		taskListID, err := request.RequireString("task_list_id")
+		to, err := request.RequireString("to")
 		if err != nil {
-			return mcp.NewToolResultError("task_list_id is required"), nil
+			return mcp.NewToolResultError("to is required"), nil
 		}
-		title, err := request.RequireString("title")
+		subject, err := request.RequireString("subject")
 		if err != nil {
-			return mcp.NewToolResultError("title is required"), nil
+			return mcp.NewToolResultError("subject is required"), nil
 		}
-		notes := request.GetString("notes", "")
-		due := request.GetString("due", "")
+		body := request.GetString("body", "")
+		bodyHTML := request.GetString("body_html", "")
+		if body == "" && bodyHTML == "" {
+			return mcp.NewToolResultError("body or body_html is required"), nil
+		}
+		driveIDs := request.GetString("attachment_drive_ids", "")
+		paths := request.GetString("attachment_paths", "")
+
+		// Attachments are checked against Gmail's size limit before they are read: local files by their
+		// size on disk, then Drive files by their size in Drive against what is left.
+		tooLarge := fmt.Sprintf("the attachments are over Gmail's %s limit; share a link to the file instead (drive_share_file)", drivesvc.FormatBytes(gmailsvc.MaxAttachmentBytes))
+		remaining := int64(gmailsvc.MaxAttachmentBytes)
+		var fromDisk []gmailsvc.OutgoingAttachment
+		for _, p := range splitList(paths) {
+			resolved, err := local.resolve(p)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to attach file %s: %v", p, err)), nil
+			}
+			info, err := os.Stat(resolved)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to attach file %s: %v", p, err)), nil
+			}
+			if remaining -= info.Size(); remaining < 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to attach file %s: %s", p, tooLarge)), nil
+			}
+			data, err := os.ReadFile(resolved)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to attach file %s: %v", p, err)), nil
+			}
+			fromDisk = append(fromDisk, gmailsvc.OutgoingAttachment{
+				Filename: filepath.Base(p),
+				MimeType: mime.TypeByExtension(filepath.Ext(p)),
+				Data:     data,
+			})
+		}
+		var attachments []gmailsvc.OutgoingAttachment
+		for _, id := range splitList(driveIDs) {
+			if remaining == 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to attach Drive file %s: %s", id, tooLarge)), nil
+			}
+			f, err := driveService.DownloadFileLimit(ctx, id, "", remaining)
+			if errors.Is(err, drivesvc.ErrFileTooLarge) {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to attach Drive file %s: %s", id, tooLarge)), nil
+			}
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to attach Drive file %s: %v", id, err)), nil
+			}
+			remaining -= int64(len(f.Data))
+			attachments = append(attachments, gmailsvc.OutgoingAttachment{Filename: f.Name, MimeType: f.MimeType, Data: f.Data})
+		}
+		attachments = append(attachments, fromDisk...)
 
-		task, err := tasksService.InsertTask(taskListID, title, notes, due)
+		msg, err := gmailService.SendEmail(ctx, to, subject, body, bodyHTML, attachments...)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to insert task: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to send email: %v", err)), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Created task: %s (ID: %s)", task.Title, task.Id)), nil
+
+		return mcp.NewToolResultText(fmt.Sprintf("Email sent! ID: %s", msg.Id)), nil
 	})
 
-	// Tool: Tasks Update Task
-	s.AddTool(mcp.NewTool("tasks_update_task",
-		mcp.WithDescription("Update an existing task (title, notes, due date, or status)"),
-		mcp.WithString("task_list_id", mcp.Required(), mcp.Description("ID of the task list")),
-		mcp.WithString("task_id", mcp.Required(), mcp.Description("ID of the task")),
-		mcp.WithString("title", mcp.Description("New title (optional)")),
-		mcp.WithString("notes", mcp.Description("New notes (optional)")),
-		mcp.WithString("due", mcp.Description("New due date RFC3339 (optional)")),
-		mcp.WithString("status", mcp.Description("'needsAction' or 'completed' (optional)")),
+	// Tool: Gmail Reply Thread
+	s.AddTool(mcp.NewTool("gmail_reply_thread",
+		mcp.WithDescription("Reply within an existing email thread. Replies to the latest message, keeping the subject and threading headers so Gmail shows it in the same conversation."),
+		mcp.WithString("thread_id", mcp.Required(), mcp.Description("ID of the thread to reply to")),
+		mcp.WithString("body", mcp.Required(), mcp.Description("Reply body content")),
+		mcp.WithString("reply_all", mcp.Description("If 'true', also send to the original To/Cc recipients (default: false)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		taskListID, err := request.RequireString("task_list_id")
+		threadID, err := request.RequireString("thread_id")
 		if err != nil {
-			return mcp.NewToolResultError("task_list_id is required"), nil
+			return mcp.NewToolResultError("thread_id is required"), nil
 		}
-		taskID, err := request.RequireString("task_id")
+		body, err := request.RequireString("body")
 		if err != nil {
-			return mcp.NewToolResultError("task_id is required"), nil
-		}
-		title := request.GetString("title", "")
-		notes := request.GetString("notes", "")
-		due := request.GetString("due", "")
-		status := request.GetString("status", "")
-
-		in := taskssvc.UpdateTaskInput{}
-		if title != "" {
-			in.Title = &title
-		}
-		if notes != "" {
-			in.Notes = &notes
-		}
-		if due != "" {
-			in.Due = &due
-		}
-		if status != "" {
-			in.Status = &status
+			return mcp.NewToolResultError("body is required"), nil
 		}
+		replyAll := request.GetString("reply_all", "false") == "true"
 
-		task, err := tasksService.UpdateTask(taskListID, taskID, in)
+		msg, err := gmailService.ReplyToThread(ctx, threadID, body, replyAll)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to update task: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to send reply: %v", err)), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Updated task: %s (ID: %s)", task.Title, task.Id)), nil
+
+		return mcp.NewToolResultText(fmt.Sprintf("Reply sent! ID: %s (Thread ID: %s)", msg.Id, msg.ThreadId)), nil
 	})
 
-	// Tool: Tasks Delete Task
-	s.AddTool(mcp.NewTool("tasks_delete_task",
-		mcp.WithDescription("Delete a task from a Google Tasks list"),
-		mcp.WithString("task_list_id", mcp.Required(), mcp.Description("ID of the task list")),
-		mcp.WithString("task_id", mcp.Required(), mcp.Description("ID of the task to delete")),
+	// Tool: Gmail Create Draft
+	s.AddTool(mcp.NewTool("gmail_create_draft",
+		mcp.WithDescription("Create a draft email with a plain text and/or HTML body"),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("to", mcp.Required(), mcp.Description("Recipient email address")),
+		mcp.WithString("subject", mcp.Required(), mcp.Description("Email subject")),
+		mcp.WithString("body", mcp.Description("Plain text body (required unless body_html is set)")),
+		mcp.WithString("body_html", mcp.Description("HTML body (optional; sent with body as a text fallback when both are set)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		taskListID, err := request.RequireString("task_list_id")
+		to, err := request.RequireString("to")
 		if err != nil {
-			return mcp.NewToolResultError("task_list_id is required"), nil
+			return mcp.NewToolResultError("to is required"), nil
 		}
-		taskID, err := request.RequireString("task_id")
+		subject, err := request.RequireString("subject")
 		if err != nil {
-			return mcp.NewToolResultError("task_id is required"), nil
+			return mcp.NewToolResultError("subject is required"), nil
+		}
+		body := request.GetString("body", "")
+		bodyHTML := request.GetString("body_html", "")
+		if body == "" && bodyHTML == "" {
+			return mcp.NewToolResultError("body or body_html is required"), nil
 		}
 
-		if err := tasksService.DeleteTask(taskListID, taskID); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete task: %v", err)), nil
+		draft, err := gmailService.CreateDraft(ctx, to, subject, body, bodyHTML)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to create draft: %v", err)), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Deleted task: %s", taskID)), nil
+
+		return mcp.NewToolResultText(fmt.Sprintf("Draft created! ID: %s", draft.Id)), nil
 	})
 
-	// Tool: Keep List Notes
-	s.AddTool(mcp.NewTool("keep_list_notes",
-		mcp.WithDescription("List Google Keep notes. Use note name from results for keep_get_note and keep_delete_note."),
-		mcp.WithNumber("page_size", mcp.Description("Max notes per page (default 20, 0 = server default)")),
-		mcp.WithString("page_token", mcp.Description("Page token from previous list response for next page")),
-		mcp.WithString("filter", mcp.Description("Filter (e.g. 'trashed = false' to exclude trashed). AIP-160 syntax.")),
+	// Tool: Gmail List Drafts
+	s.AddTool(mcp.NewTool("gmail_list_drafts",
+		mcp.WithDescription("List draft emails. Use draft IDs with gmail_update_draft and gmail_send_draft."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("query", mcp.Description("Gmail search query to filter drafts (optional)")),
+		mcp.WithNumber("limit", mcp.Description("Max drafts to return (default 10)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		pageSize := int64(request.GetInt("page_size", 20))
-		pageToken := request.GetString("page_token", "")
-		filter := request.GetString("filter", "trashed = false")
+		query := request.GetString("query", "")
+		limit := int64(request.GetInt("limit", 10))
 
-		resp, err := keepService.ListNotes(keepsvc.ListNotesOptions{
-			PageSize:  pageSize,
-			PageToken: pageToken,
-			Filter:    filter,
-		})
+		drafts, err := gmailService.ListDrafts(ctx, query, limit)
 		if err != nil {
-			if isKeepUnavailableError(err) {
-				return mcp.NewToolResultError(keepUnavailableMessage), nil
-			}
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list notes: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list drafts: %v", err)), nil
 		}
 
 		var result string
-		for _, n := range resp.Notes {
-			result += fmt.Sprintf("[%s] %s (updated: %s)\n", n.Name, n.Title, n.UpdateTime)
+		for _, d := range drafts {
+			var to, subject, snippet string
+			if d.Message != nil {
+				snippet = d.Message.Snippet
+				if d.Message.Payload != nil {
+					to = gmailsvc.GetHeader(d.Message.Payload.Headers, "To")
+					subject = gmailsvc.GetHeader(d.Message.Payload.Headers, "Subject")
+				}
+			}
+			result += fmt.Sprintf("[Draft ID: %s] To: %s | Subject: %s | %s\n", d.Id, to, subject, snippet)
 		}
-		if len(resp.Notes) == 0 {
-			result = "No notes found."
-		} else if resp.NextPageToken != "" {
-			result += fmt.Sprintf("\nnext_page_token: %s", resp.NextPageToken)
+		if len(drafts) == 0 {
+			result = "No drafts found."
 		}
 		return mcp.NewToolResultText(result), nil
 	})
 
-	// Tool: Keep Create Note
-	s.AddTool(mcp.NewTool("keep_create_note",
-		mcp.WithDescription("Create a new Google Keep note. Provide title and either body_text (plain note) or list_items_json (checklist). List items: [{\"text\":\"item 1\",\"checked\":false},{\"text\":\"item 2\",\"checked\":true}]"),
-		mcp.WithString("title", mcp.Required(), mcp.Description("Note title (max 1000 chars)")),
-		mcp.WithString("body_text", mcp.Description("Plain text body for the note (max 20000 chars). Omit if using list_items_json.")),
-		mcp.WithString("list_items_json", mcp.Description("JSON array of list items: [{\"text\":\"...\",\"checked\":false}]. Omit for text-only note.")),
+	// Tool: Gmail Update Draft
+	s.AddTool(mcp.NewTool("gmail_update_draft",
+		mcp.WithDescription("Update an existing draft. Only the fields provided are changed; the rest of the draft is kept."),
+		mcp.WithString("draft_id", mcp.Required(), mcp.Description("ID of the draft to update")),
+		mcp.WithString("to", mcp.Description("New recipient email address (optional)")),
+		mcp.WithString("subject", mcp.Description("New subject (optional)")),
+		mcp.WithString("body", mcp.Description("New plain text body (optional)")),
+		mcp.WithString("body_html", mcp.Description("New HTML body (optional)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		title, err := request.RequireString("title")
+		draftID, err := request.RequireString("draft_id")
 		if err != nil {
-			return mcp.NewToolResultError("title is required"), nil
+			return mcp.NewToolResultError("draft_id is required"), nil
 		}
-		bodyText := request.GetString("body_text", "")
-		listItemsJSON := request.GetString("list_items_json", "")
+		to := request.GetString("to", "")
+		subject := request.GetString("subject", "")
+		body := request.GetString("body", "")
+		bodyHTML := request.GetString("body_html", "")
 
-		var listItems []*keepapi.ListItem
-		if listItemsJSON != "" {
-			var decoded []struct {
-				Text    string `json:"text"`
-				Checked bool   `json:"checked"`
-			}
-			if err := json.Unmarshal([]byte(listItemsJSON), &decoded); err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Invalid list_items_json: %v", err)), nil
-			}
-			for _, d := range decoded {
-				listItems = append(listItems, &keepapi.ListItem{
-					Text:    &keepapi.TextContent{Text: d.Text},
-					Checked: d.Checked,
-				})
-			}
+		in := gmailsvc.UpdateDraftInput{}
+		if to != "" {
+			in.To = &to
+		}
+		if subject != "" {
+			in.Subject = &subject
+		}
+		if body != "" {
+			in.Body = &body
+		}
+		if bodyHTML != "" {
+			in.HTMLBody = &bodyHTML
 		}
 
-		note, err := keepService.CreateNote(title, bodyText, listItems)
+		draft, err := gmailService.UpdateDraft(ctx, draftID, in)
 		if err != nil {
-			if isKeepUnavailableError(err) {
-				return mcp.NewToolResultError(keepUnavailableMessage), nil
-			}
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to create note: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to update draft: %v", err)), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Created note: %s (name: %s)", note.Title, note.Name)), nil
+		return mcp.NewToolResultText(fmt.Sprintf("Draft updated! ID: %s", draft.Id)), nil
 	})
 
-	// Tool: Keep Get Note
-	s.AddTool(mcp.NewTool("keep_get_note",
-		mcp.WithDescription("Get a Google Keep note by name or id. Returns title, body text or list items, and metadata."),
-		mcp.WithString("name", mcp.Required(), mcp.Description("Note name (e.g. notes/xyz) or note id (xyz)")),
+	// Tool: Gmail Send Draft
+	s.AddTool(mcp.NewTool("gmail_send_draft",
+		mcp.WithDescription("Send an existing draft (e.g. after it has been reviewed)"),
+		mcp.WithString("draft_id", mcp.Required(), mcp.Description("ID of the draft to send")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		name, err := request.RequireString("name")
+		draftID, err := request.RequireString("draft_id")
 		if err != nil {
-			return mcp.NewToolResultError("name is required"), nil
+			return mcp.NewToolResultError("draft_id is required"), nil
 		}
 
-		note, err := keepService.GetNote(name)
+		msg, err := gmailService.SendDraft(ctx, draftID)
 		if err != nil {
-			if isKeepUnavailableError(err) {
-				return mcp.NewToolResultError(keepUnavailableMessage), nil
-			}
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get note: %v", err)), nil
-		}
-
-		result := fmt.Sprintf("name: %s\ntitle: %s\ncreateTime: %s\nupdateTime: %s\ntrashed: %v\n", note.Name, note.Title, note.CreateTime, note.UpdateTime, note.Trashed)
-		if note.Body != nil {
-			if note.Body.Text != nil {
-				result += fmt.Sprintf("body (text): %s\n", note.Body.Text.Text)
-			}
-			if note.Body.List != nil && len(note.Body.List.ListItems) > 0 {
-				result += "list items:\n"
-				for i, li := range note.Body.List.ListItems {
-					text := ""
-					if li.Text != nil {
-						text = li.Text.Text
-					}
-					checked := ""
-					if li.Checked {
-						checked = " [checked]"
-					}
-					result += fmt.Sprintf("  %d. %s%s\n", i+1, text, checked)
-				}
-			}
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to send draft: %v", err)), nil
 		}
-		return mcp.NewToolResultText(result), nil
+		return mcp.NewToolResultText(fmt.Sprintf("Draft sent! Message ID: %s", msg.Id)), nil
 	})
 
-	// Tool: Keep Update Note (edit)
-	s.AddTool(mcp.NewTool("keep_update_note",
-		mcp.WithDescription("Edit a Google Keep note. API has no native update; replaces note with a new one (new id) and deletes the old. Provide name and any of: title, body_text, list_items_json to change."),
-		mcp.WithString("name", mcp.Required(), mcp.Description("Note name (e.g. notes/xyz) or note id to edit")),
-		mcp.WithString("title", mcp.Description("New title (optional)")),
-		mcp.WithString("body_text", mcp.Description("New plain text body (optional; replaces list if set)")),
-		mcp.WithString("list_items_json", mcp.Description("New list items JSON (optional; e.g. [{\"text\":\"...\",\"checked\":false}]. Replaces text body if set.)")),
+	// Tool: Gmail Trash Thread
+	s.AddTool(mcp.NewTool("gmail_trash_thread",
+		mcp.WithDescription("Move an email thread to trash"),
+		mcp.WithString("thread_id", mcp.Required(), mcp.Description("ID of the thread to trash")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		name, err := request.RequireString("name")
+		threadID, err := request.RequireString("thread_id")
 		if err != nil {
-			return mcp.NewToolResultError("name is required"), nil
+			return mcp.NewToolResultError("thread_id is required"), nil
 		}
-		title := request.GetString("title", "")
-		bodyText := request.GetString("body_text", "")
-		listItemsJSON := request.GetString("list_items_json", "")
 
-		var listItems []*keepapi.ListItem
-		if listItemsJSON != "" {
-			var decoded []struct {
-				Text    string `json:"text"`
-				Checked bool   `json:"checked"`
+		if err := gmailService.TrashThread(ctx, threadID); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to trash thread: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Thread %s moved to trash.", threadID)), nil
+	})
+
+	// Tool: Gmail Modify Thread
+	s.AddTool(mcp.NewTool("gmail_modify_thread",
+		mcp.WithDescription("Triage an email thread in one call: mark read/unread, archive/unarchive, star/unstar, mark spam/not spam, and/or add or remove labels (IDs from gmail_list_labels)."),
+		mcp.WithString("thread_id", mcp.Required(), mcp.Description("ID of the thread to modify")),
+		mcp.WithString("actions", mcp.Description("Comma-separated actions: read, unread, archive, unarchive, star, unstar, spam, not_spam")),
+		mcp.WithString("add_labels", mcp.Description("Comma-separated label IDs to add (optional)")),
+		mcp.WithString("remove_labels", mcp.Description("Comma-separated label IDs to remove (optional)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		threadID, err := request.RequireString("thread_id")
+		if err != nil {
+			return mcp.NewToolResultError("thread_id is required"), nil
+		}
+		actions := splitList(request.GetString("actions", ""))
+		addLabels := splitList(request.GetString("add_labels", ""))
+		removeLabels := splitList(request.GetString("remove_labels", ""))
+
+		add, remove, err := gmailsvc.LabelChangesForActions(actions, addLabels, removeLabels)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if _, err := gmailService.ModifyThread(ctx, threadID, add, remove); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to modify thread: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Thread %s modified. Added labels: %v | Removed labels: %v", threadID, add, remove)), nil
+	})
+
+	// Tool: Gmail Batch Modify
+	s.AddTool(mcp.NewTool("gmail_batch_modify",
+		mcp.WithDescription("Apply the same triage to many threads or messages in one call: actions (read, unread, archive, unarchive, star, unstar, spam, not_spam, trash) and/or label changes. Reports per-ID failures without aborting the rest."),
+		mcp.WithString("ids", mcp.Required(), mcp.Description("Comma-separated thread or message IDs")),
+		mcp.WithString("id_type", mcp.Description("'thread' or 'message' (default: thread)")),
+		mcp.WithString("actions", mcp.Description("Comma-separated actions: read, unread, archive, unarchive, star, unstar, spam, not_spam, trash")),
+		mcp.WithString("add_labels", mcp.Description("Comma-separated label names or IDs to add (optional)")),
+		mcp.WithString("remove_labels", mcp.Description("Comma-separated label names or IDs to remove (optional)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		idsStr, err := request.RequireString("ids")
+		if err != nil {
+			return mcp.NewToolResultError("ids is required"), nil
+		}
+		ids := splitList(idsStr)
+		if len(ids) == 0 {
+			return mcp.NewToolResultError("ids is required"), nil
+		}
+		idType := request.GetString("id_type", "thread")
+		if idType != "thread" && idType != "message" {
+			return mcp.NewToolResultError("id_type must be 'thread' or 'message'"), nil
+		}
+		threads := idType == "thread"
+
+		var actions []string
+		trash := false
+		for _, a := range splitList(request.GetString("actions", "")) {
+			if strings.EqualFold(a, "trash") {
+				trash = true
+				continue
 			}
-			if err := json.Unmarshal([]byte(listItemsJSON), &decoded); err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Invalid list_items_json: %v", err)), nil
+			actions = append(actions, a)
+		}
+		addLabels, err := gmailService.ResolveLabelIDs(ctx, splitList(request.GetString("add_labels", "")))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to resolve labels: %v", err)), nil
+		}
+		removeLabels, err := gmailService.ResolveLabelIDs(ctx, splitList(request.GetString("remove_labels", "")))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to resolve labels: %v", err)), nil
+		}
+
+		var result string
+		if len(actions) > 0 || len(addLabels) > 0 || len(removeLabels) > 0 {
+			add, remove, err := gmailsvc.LabelChangesForActions(actions, addLabels, removeLabels)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
 			}
-			for _, d := range decoded {
-				listItems = append(listItems, &keepapi.ListItem{
-					Text:    &keepapi.TextContent{Text: d.Text},
-					Checked: d.Checked,
-				})
+			res := gmailService.BatchModify(ctx, ids, threads, add, remove)
+			result += fmt.Sprintf("Modified %d of %d %ss (added: %v, removed: %v).\n", len(res.Succeeded), len(ids), idType, add, remove)
+			for _, f := range res.Failed {
+				result += fmt.Sprintf("  failed %s: %s\n", f.ID, f.Error)
+			}
+		} else if !trash {
+			return mcp.NewToolResultError("at least one action or label change is required"), nil
+		}
+		if trash {
+			res := gmailService.BatchTrash(ctx, ids, threads)
+			result += fmt.Sprintf("Trashed %d of %d %ss.\n", len(res.Succeeded), len(ids), idType)
+			for _, f := range res.Failed {
+				result += fmt.Sprintf("  failed %s: %s\n", f.ID, f.Error)
 			}
 		}
+		return mcp.NewToolResultText(result), nil
+	})
 
-		in := keepsvc.UpdateNoteInput{Title: title, BodyText: bodyText, ListItems: listItems}
-		note, err := keepService.UpdateNote(name, in)
+	// Tool: Gmail List Labels
+	s.AddTool(mcp.NewTool("gmail_list_labels",
+		mcp.WithDescription("List all Gmail labels"),
+		mcp.WithReadOnlyHintAnnotation(true),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		labels, err := gmailService.ListLabels(ctx)
 		if err != nil {
-			if isKeepUnavailableError(err) {
-				return mcp.NewToolResultError(keepUnavailableMessage), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list labels: %v", err)), nil
+		}
+
+		var result string
+		for _, l := range labels {
+			result += fmt.Sprintf("ID: %s | Name: %s | Type: %s\n", l.Id, l.Name, l.Type)
+		}
+		return mcp.NewToolResultText(result), nil
+	})
+
+	// Tool: Gmail List Filters
+	s.AddTool(mcp.NewTool("gmail_list_filters",
+		mcp.WithDescription("List Gmail filters (automatic rules applied to incoming mail)"),
+		mcp.WithReadOnlyHintAnnotation(true),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		filters, err := gmailService.ListFilters(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list filters: %v", err)), nil
+		}
+
+		var result string
+		for _, f := range filters {
+			criteria, _ := json.Marshal(f.Criteria)
+			action, _ := json.Marshal(f.Action)
+			result += fmt.Sprintf("[Filter ID: %s] criteria: %s | action: %s\n", f.Id, criteria, action)
+		}
+		if len(filters) == 0 {
+			result = "No filters found."
+		}
+		return mcp.NewToolResultText(result), nil
+	})
+
+	// Tool: Gmail Create Filter
+	s.AddTool(mcp.NewTool("gmail_create_filter",
+		mcp.WithDescription("Create a Gmail filter that automatically labels, archives, stars, marks read, or forwards matching incoming mail. Labels can be given by name or ID."),
+		mcp.WithString("from", mcp.Description("Match sender")),
+		mcp.WithString("to", mcp.Description("Match recipient")),
+		mcp.WithString("subject", mcp.Description("Match phrase in subject")),
+		mcp.WithString("query", mcp.Description("Match Gmail search query (e.g. 'list:newsletter.example.com')")),
+		mcp.WithString("has_attachment", mcp.Description("If 'true', only match messages with attachments")),
+		mcp.WithString("add_labels", mcp.Description("Comma-separated label names or IDs to apply")),
+		mcp.WithString("archive", mcp.Description("If 'true', skip the inbox")),
+		mcp.WithString("mark_read", mcp.Description("If 'true', mark as read")),
+		mcp.WithString("star", mcp.Description("If 'true', star the message")),
+		mcp.WithString("forward", mcp.Description("Verified forwarding address to forward matches to (optional)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		criteria := &gmail.FilterCriteria{
+			From:          request.GetString("from", ""),
+			To:            request.GetString("to", ""),
+			Subject:       request.GetString("subject", ""),
+			Query:         request.GetString("query", ""),
+			HasAttachment: request.GetString("has_attachment", "false") == "true",
+		}
+
+		addLabels, err := gmailService.ResolveLabelIDs(ctx, splitList(request.GetString("add_labels", "")))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to resolve labels: %v", err)), nil
+		}
+		action := &gmail.FilterAction{
+			AddLabelIds: addLabels,
+			Forward:     request.GetString("forward", ""),
+		}
+		if request.GetString("archive", "false") == "true" {
+			action.RemoveLabelIds = append(action.RemoveLabelIds, "INBOX")
+		}
+		if request.GetString("mark_read", "false") == "true" {
+			action.RemoveLabelIds = append(action.RemoveLabelIds, "UNREAD")
+		}
+		if request.GetString("star", "false") == "true" {
+			action.AddLabelIds = append(action.AddLabelIds, "STARRED")
+		}
+
+		filter, err := gmailService.CreateFilter(ctx, criteria, action)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to create filter: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Filter created! ID: %s", filter.Id)), nil
+	})
+
+	// Tool: Gmail Delete Filter
+	s.AddTool(mcp.NewTool("gmail_delete_filter",
+		mcp.WithDescription("Delete a Gmail filter"),
+		mcp.WithString("filter_id", mcp.Required(), mcp.Description("ID of the filter from gmail_list_filters")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		filterID, err := request.RequireString("filter_id")
+		if err != nil {
+			return mcp.NewToolResultError("filter_id is required"), nil
+		}
+
+		if err := gmailService.DeleteFilter(ctx, filterID); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete filter: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Deleted filter: %s", filterID)), nil
+	})
+
+	// Tool: Gmail Watch
+	s.AddTool(mcp.NewTool("gmail_watch",
+		mcp.WithDescription("Register (or stop) Gmail push notifications to a Cloud Pub/Sub topic. The topic must grant the Pub/Sub Publisher role to gmail-api-push@system.gserviceaccount.com; the watch expires after 7 days and must be renewed by calling this again. "+
+			"Agents without a Pub/Sub subscriber can use gmail_wait_for_new_mail instead, which needs no setup."),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("topic_name", mcp.Description("Pub/Sub topic, e.g. projects/my-project/topics/gmail (required unless stop is 'true')")),
+		mcp.WithString("labels", mcp.Description("Comma-separated label names or IDs to limit notifications to (e.g. INBOX)")),
+		mcp.WithString("stop", mcp.Description("If 'true', stop push notifications instead")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if request.GetString("stop", "false") == "true" {
+			if err := gmailService.StopWatch(ctx); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to stop watch: %v", err)), nil
 			}
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to update note: %v", err)), nil
+			return mcp.NewToolResultText("Stopped Gmail push notifications."), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Updated note (new name: %s): %s", note.Name, note.Title)), nil
+		topic := request.GetString("topic_name", "")
+		if topic == "" {
+			return mcp.NewToolResultError("topic_name is required"), nil
+		}
+		labelIDs, err := gmailService.ResolveLabelIDs(ctx, splitList(request.GetString("labels", "")))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to resolve labels: %v", err)), nil
+		}
+		resp, err := gmailService.Watch(ctx, topic, labelIDs)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to watch mailbox: %v", err)), nil
+		}
+		expires := time.UnixMilli(resp.Expiration).Format(time.RFC3339)
+		return mcp.NewToolResultText(fmt.Sprintf("Watching mailbox on %s until %s (history_id: %d)", topic, expires, resp.HistoryId)), nil
 	})
 
-	// Tool: Keep Delete Note
-	s.AddTool(mcp.NewTool("keep_delete_note",
-		mcp.WithDescription("Delete a Google Keep note permanently. Caller must be owner."),
-		mcp.WithString("name", mcp.Required(), mcp.Description("Note name (e.g. notes/xyz) or note id")),
+	// Tool: Gmail Wait For New Mail
+	mailCursors := map[string]uint64{} // Last history ID returned per label, used when history_id is omitted
+	var mailCursorsMu sync.Mutex
+	s.AddTool(mcp.NewTool("gmail_wait_for_new_mail",
+		mcp.WithDescription("Wait until new email arrives and return it (long poll), so an agent can react to incoming mail. "+
+			"Returns the new messages and a history_id; the next call continues after them. Without history_id, the server continues from its previous call, or starts from now."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("history_id", mcp.Description("Only return mail newer than this history_id from a previous call")),
+		mcp.WithString("label", mcp.Description("Only mail with this label name or ID (default INBOX; 'all' for any new message)")),
+		mcp.WithNumber("timeout_seconds", mcp.Description("How long to wait for new mail (default 60, max 300)")),
+		mcp.WithNumber("poll_interval_seconds", mcp.Description("How often to check the mailbox (default 15, min 5)")),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		name, err := request.RequireString("name")
+		label := request.GetString("label", "INBOX")
+		labelID := ""
+		if !strings.EqualFold(label, "all") {
+			ids, err := gmailService.ResolveLabelIDs(ctx, []string{label})
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to resolve label: %v", err)), nil
+			}
+			labelID = ids[0]
+		}
+		var start uint64
+		if v := request.GetString("history_id", ""); v != "" {
+			var err error
+			if start, err = strconv.ParseUint(v, 10, 64); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid history_id %q", v)), nil
+			}
+		} else {
+			mailCursorsMu.Lock()
+			start = mailCursors[labelID]
+			mailCursorsMu.Unlock()
+		}
+		timeout := time.Duration(min(max(request.GetInt("timeout_seconds", 60), 1), 300)) * time.Second
+		interval := time.Duration(max(request.GetInt("poll_interval_seconds", 15), 5)) * time.Second
+
+		mail, err := gmailService.WaitForNewMail(ctx, start, labelID, timeout, interval)
 		if err != nil {
-			return mcp.NewToolResultError("name is required"), nil
+			if errors.Is(err, gmailsvc.ErrHistoryExpired) {
+				mailCursorsMu.Lock()
+				delete(mailCursors, labelID)
+				mailCursorsMu.Unlock()
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to wait for new mail: %v", err)), nil
+		}
+		mailCursorsMu.Lock()
+		mailCursors[labelID] = mail.HistoryID
+		mailCursorsMu.Unlock()
+		if mail.TimedOut {
+			return mcp.NewToolResultText(fmt.Sprintf("No new mail within %s.\nhistory_id: %d", timeout, mail.HistoryID)), nil
 		}
+		jsonBytes, _ := json.MarshalIndent(mail, "", "  ")
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	})
 
-		if err := keepService.DeleteNote(name); err != nil {
-			if isKeepUnavailableError(err) {
-				return mcp.NewToolResultError(keepUnavailableMessage), nil
+	// Tool: Calendar List Calendars
+	s.AddTool(mcp.NewTool("calendar_list_calendars",
+		mcp.WithDescription("List the user's calendars with their IDs, access roles and time zones. Use the IDs as calendar_id in other calendar tools."),
+		mcp.WithReadOnlyHintAnnotation(true),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calendars, err := calendarService.ListCalendars(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list calendars: %v", err)), nil
+		}
+
+		var result string
+		for _, c := range calendars {
+			name := c.Summary
+			if c.SummaryOverride != "" {
+				name = c.SummaryOverride
 			}
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete note: %v", err)), nil
+			primary := ""
+			if c.Primary {
+				primary = " [primary]"
+			}
+			result += fmt.Sprintf("%s%s | ID: %s | Role: %s | Time zone: %s\n", name, primary, c.Id, c.AccessRole, c.TimeZone)
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Deleted note: %s", name)), nil
+		if len(calendars) == 0 {
+			result = "No calendars found."
+		}
+		return mcp.NewToolResultText(result), nil
 	})
 
-	// Start server (stdio)
-	if err := server.ServeStdio(s); err != nil {
-		fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
-		os.Exit(1)
-	}
-}
+	// Tool: Calendar List Events
+	s.AddTool(mcp.NewTool("calendar_list_events",
+		mcp.WithDescription("List or search events from Google Calendar. Upcoming events by default; use order='desc' to find past events, e.g. the last meeting with someone."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("calendar_id", mcp.Description("Calendar ID (default: 'primary')")),
+		mcp.WithNumber("max_results", mcp.Description("Max events to return (default 10)")),
+		mcp.WithString("query", mcp.Description("Free text search over title, description, location and attendees (optional)")),
+		mcp.WithString("time_min", mcp.Description("Start time (RFC3339). Default: now, or one year before time_max with order='desc'.")),
+		mcp.WithString("time_max", mcp.Description("End time (RFC3339). Optional; defaults to now with order='desc'.")),
+		mcp.WithString("order", mcp.Description("'asc' (default, by start time), 'desc' (most recent first) or 'updated' (last modified)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calendarID := request.GetString("calendar_id", "primary")
+		maxResults := int64(request.GetInt("max_results", 10))
+		query := request.GetString("query", "")
+		timeMin := request.GetString("time_min", "")
+		timeMax := request.GetString("time_max", "")
+		order := request.GetString("order", "asc")
 
-func handleAuthCommand() {
-	// We parse subcommands manually since "auth" is the command
-	if len(os.Args) < 3 {
-		fmt.Println("Usage: gogo-mcp auth login --secrets <path>")
-		os.Exit(1)
-	}
+		events, err := calendarService.ListEvents(ctx, calendarID, maxResults, timeMin, timeMax, query, order)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list events: %v", err)), nil
+		}
 
-	if os.Args[2] == "login" {
-		loginCmd := flag.NewFlagSet("login", flag.ExitOnError)
-		secretsPath := loginCmd.String("secrets", "", "Path to client_secrets.json")
-		_ = loginCmd.Parse(os.Args[3:])
+		var result string
+		for _, e := range events {
+			start := e.Start.DateTime
+			if start == "" {
+				start = e.Start.Date // All-day event
+			}
+			if e.RecurringEventId != "" {
+				result += fmt.Sprintf("[%s] %s (%s, series: %s)\n", start, e.Summary, e.Id, e.RecurringEventId)
+				continue
+			}
+			result += fmt.Sprintf("[%s] %s (%s)\n", start, e.Summary, e.Id)
+		}
+		if len(events) == 0 {
+			result = "No events found."
+		}
+		return mcp.NewToolResultText(result), nil
+	})
 
-		if *secretsPath == "" {
-			fmt.Println("Error: --secrets flag is required")
-			loginCmd.Usage()
-			os.Exit(1)
+	// Tool: Calendar Get Event
+	s.AddTool(mcp.NewTool("calendar_get_event",
+		mcp.WithDescription("Get full details of a calendar event: time, location, Meet link, recurrence and attendees' RSVP status."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("event_id", mcp.Required(), mcp.Description("ID of the event")),
+		mcp.WithString("calendar_id", mcp.Description("Calendar ID (default: 'primary')")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		eventID, err := request.RequireString("event_id")
+		if err != nil {
+			return mcp.NewToolResultError("event_id is required"), nil
 		}
+		calendarID := request.GetString("calendar_id", "primary")
 
-		// Read secrets
-		secrets, err := os.ReadFile(*secretsPath)
+		event, err := calendarService.GetEvent(ctx, calendarID, eventID)
 		if err != nil {
-			fmt.Printf("Error reading secrets file: %v\n", err)
-			os.Exit(1)
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get event: %v", err)), nil
 		}
+		return mcp.NewToolResultText(calendarsvc.FormatEventDetails(event)), nil
+	})
 
-		// Perform login
-		fmt.Println("Starting OAuth 2.0 flow...")
-		scopes := []string{
-			"https://www.googleapis.com/auth/drive",
-			"https://www.googleapis.com/auth/gmail.readonly",
-			"https://www.googleapis.com/auth/gmail.send",
-			"https://www.googleapis.com/auth/gmail.modify",
-			"https://www.googleapis.com/auth/calendar",
-			"https://www.googleapis.com/auth/spreadsheets",
-			"https://www.googleapis.com/auth/contacts",
-			"https://www.googleapis.com/auth/documents",
-			tasks.TasksScope,
-			driveactivity.DriveActivityReadonlyScope,
-			// Keep scope omitted: personal accounts get invalid_scope; add keepapi.KeepScope here if using a Workspace account.
-		}
-		if err := auth.Login(context.Background(), secrets, scopes); err != nil {
-			fmt.Printf("Login failed: %v\n", err)
-			os.Exit(1)
+	// Tool: Calendar Agenda
+	s.AddTool(mcp.NewTool("calendar_agenda",
+		mcp.WithDescription("Compact agenda for a date range: events from one or more calendars merged and grouped by day, with the free slots in each working day."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("start_date", mcp.Description("First day (YYYY-MM-DD, default: today)")),
+		mcp.WithString("end_date", mcp.Description("Last day, inclusive (YYYY-MM-DD, default: start_date)")),
+		mcp.WithString("calendar_ids", mcp.Description("Comma-separated calendar IDs (default: 'primary')")),
+		mcp.WithString("time_zone", mcp.Description("Time zone, e.g. 'America/Sao_Paulo' (default: the primary calendar's time zone)")),
+		mcp.WithString("work_start", mcp.Description("Start of the working day for free slots (HH:MM, default 09:00)")),
+		mcp.WithString("work_end", mcp.Description("End of the working day for free slots (HH:MM, default 18:00)")),
+		mcp.WithNumber("min_gap_minutes", mcp.Description("Shortest free slot to report, in minutes (default 15)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ids := splitList(request.GetString("calendar_ids", "primary"))
+
+		tz := request.GetString("time_zone", "")
+		if tz == "" {
+			var err error
+			if tz, err = calendarService.TimeZone(ctx, "primary"); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to get time zone: %v", err)), nil
+			}
+		}
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid time_zone %q: %v", tz, err)), nil
 		}
 
-		// Save secrets for future use
-		if err := auth.SaveSecrets(*secretsPath); err != nil {
-			fmt.Printf("Warning: Failed to save secrets file for future use: %v\n", err)
+		first := time.Now().In(loc)
+		if v := request.GetString("start_date", ""); v != "" {
+			if first, err = time.ParseInLocation("2006-01-02", v, loc); err != nil {
+				return mcp.NewToolResultError("start_date must be YYYY-MM-DD"), nil
+			}
+		}
+		last := first
+		if v := request.GetString("end_date", ""); v != "" {
+			if last, err = time.ParseInLocation("2006-01-02", v, loc); err != nil {
+				return mcp.NewToolResultError("end_date must be YYYY-MM-DD"), nil
+			}
+		}
+		if last.Sub(first) > 31*24*time.Hour {
+			return mcp.NewToolResultError("date range is limited to 31 days"), nil
 		}
 
-		fmt.Println("Setup complete! You can now run 'gogo-mcp' without arguments.")
-	} else {
-		fmt.Printf("Unknown auth command: %s\n", os.Args[2])
-		os.Exit(1)
-	}
-}
+		workStart, err := time.Parse("15:04", request.GetString("work_start", "09:00"))
+		if err != nil {
+			return mcp.NewToolResultError("work_start must be HH:MM"), nil
+		}
+		workEnd, err := time.Parse("15:04", request.GetString("work_end", "18:00"))
+		if err != nil {
+			return mcp.NewToolResultError("work_end must be HH:MM"), nil
+		}
+		opts := calendarsvc.AgendaOptions{
+			DayStart: time.Duration(workStart.Hour())*time.Hour + time.Duration(workStart.Minute())*time.Minute,
+			DayEnd:   time.Duration(workEnd.Hour())*time.Hour + time.Duration(workEnd.Minute())*time.Minute,
+			MinGap:   time.Duration(request.GetInt("min_gap_minutes", 15)) * time.Minute,
+		}
 
-// keepUnavailableMessage is returned when Keep API is not available (e.g. personal account, scope not granted).
-const keepUnavailableMessage = "Google Keep is not available for this account. It may require a Google Workspace account. Enable the Keep API in Cloud Console and add the Keep scope when using a Workspace account."
+		days, err := calendarService.Agenda(ctx, ids, first, last, loc, opts)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to build agenda: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Time zone: %s\n%s", tz, calendarsvc.FormatAgenda(days, len(ids) > 1))), nil
+	})
 
-// isKeepUnavailableError returns true if the error indicates Keep API is not available (scope, 403, not enabled).
-func isKeepUnavailableError(err error) bool {
-	if err == nil {
-		return false
-	}
-	s := strings.ToLower(err.Error())
-	return strings.Contains(s, "403") ||
-		strings.Contains(s, "access not configured") ||
-		strings.Contains(s, "invalid_scope") ||
-		strings.Contains(s, "access_denied") ||
-		strings.Contains(s, "insufficient") ||
-		strings.Contains(s, "permission") ||
-		strings.Contains(s, "not enabled") ||
-		strings.Contains(s, "forbidden")
+	// Tool: Calendar Export To Sheet
+	s.AddTool(mcp.NewTool("calendar_export_to_sheet",
+		mcp.WithDescription("Write the events of a date range (date, times, title, hours, category, calendar, attendees) to a tab of a Google Sheet, and the hours per category to a second tab, for time tracking. Declined, free and all-day events are listed but not counted. Returns the totals."),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("start_date", mcp.Required(), mcp.Description("First day (YYYY-MM-DD)")),
+		mcp.WithString("end_date", mcp.Description("Last day, inclusive (YYYY-MM-DD, default: start_date)")),
+		mcp.WithString("calendar_ids", mcp.Description("Comma-separated calendar IDs (default: 'primary')")),
+		mcp.WithString("time_zone", mcp.Description("Time zone, e.g. 'America/Sao_Paulo' (default: the primary calendar's time zone)")),
+		mcp.WithString("category_by", mcp.Description("'calendar' (default), 'color' (the event color, e.g. Tomato) or 'rules'")),
+		mcp.WithString("rules", mcp.Description("For category_by='rules': 'Category=keyword|keyword,...' matched against titles in order, e.g. 'Meetings=sync|1:1,Focus=focus'; other events go to 'Other'")),
+		mcp.WithString("spreadsheet_id", mcp.Description("Spreadsheet to add the tabs to (default: a new spreadsheet)")),
+		mcp.WithString("tab", mcp.Description("Title of the events tab; the totals go to '<tab> totals' (default: 'Time <start_date>..<end_date>')")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ids := splitList(request.GetString("calendar_ids", "primary"))
+
+		tz := request.GetString("time_zone", "")
+		if tz == "" {
+			var err error
+			if tz, err = calendarService.TimeZone(ctx, "primary"); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to get time zone: %v", err)), nil
+			}
+		}
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid time_zone %q: %v", tz, err)), nil
+		}
+
+		first, err := time.ParseInLocation("2006-01-02", request.GetString("start_date", ""), loc)
+		if err != nil {
+			return mcp.NewToolResultError("start_date must be YYYY-MM-DD"), nil
+		}
+		last := first
+		if v := request.GetString("end_date", ""); v != "" {
+			if last, err = time.ParseInLocation("2006-01-02", v, loc); err != nil {
+				return mcp.NewToolResultError("end_date must be YYYY-MM-DD"), nil
+			}
+		}
+		if last.Sub(first) > 366*24*time.Hour {
+			return mcp.NewToolResultError("date range is limited to 366 days"), nil
+		}
+
+		opts := calendarsvc.ReportOptions{CategoryBy: request.GetString("category_by", calendarsvc.CategoryByCalendar)}
+		switch opts.CategoryBy {
+		case calendarsvc.CategoryByCalendar, calendarsvc.CategoryByColor:
+		case calendarsvc.CategoryByRules:
+			if opts.Rules, err = calendarsvc.ParseCategoryRules(request.GetString("rules", "")); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		default:
+			return mcp.NewToolResultError("category_by must be 'calendar', 'color' or 'rules'"), nil
+		}
+		// Calendar names rather than IDs (often email addresses) in the sheet; IDs are kept if listing fails
+		if calendars, err := calendarService.ListCalendars(ctx); err == nil {
+			opts.CalendarNames = make(map[string]string)
+			for _, c := range calendars {
+				name := cmp.Or(c.SummaryOverride, c.Summary)
+				opts.CalendarNames[c.Id] = name
+				if c.Primary {
+					opts.CalendarNames["primary"] = name
+				}
+			}
+		}
+
+		events, err := calendarService.ReportEvents(ctx, ids, first, last, loc, opts)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get events: %v", err)), nil
+		}
+		totals := calendarsvc.TimeTotals(events)
+
+		period := first.Format("2006-01-02") + ".." + last.Format("2006-01-02")
+		tab := request.GetString("tab", "Time "+period)
+		spreadsheetID := request.GetString("spreadsheet_id", "")
+		var placeholder *int64 // The empty first tab of a new spreadsheet, deleted once the report is written
+		if spreadsheetID == "" {
+			sp, err := sheetsService.CreateSpreadsheet(ctx, "Time report "+period)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to create spreadsheet: %v", err)), nil
+			}
+			spreadsheetID = sp.SpreadsheetId
+			if len(sp.Sheets) > 0 {
+				placeholder = &sp.Sheets[0].Properties.SheetId
+			}
+		}
+		// Values are written as-is, so that titles starting with '=' are never run as formulas
+		eventsTab, err := sheetsService.ImportCSV(ctx, spreadsheetID, tab, calendarsvc.ReportRows(events), true)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to write events: %v", err)), nil
+		}
+		totalsTab, err := sheetsService.ImportCSV(ctx, spreadsheetID, tab+" totals", calendarsvc.TotalsRows(totals), true)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to write totals: %v", err)), nil
+		}
+		if placeholder != nil {
+			if err := sheetsService.DeleteSheet(ctx, spreadsheetID, *placeholder); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to delete the empty first tab: %v", err)), nil
+			}
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "Wrote %d events to tab '%s' and the totals to tab '%s' of spreadsheet %s\n", len(events), eventsTab.Title, totalsTab.Title, spreadsheetID)
+		fmt.Fprintf(&b, "URL: https://docs.google.com/spreadsheets/d/%s/edit\n\nHours by %s (%s, %s):\n", spreadsheetID, opts.CategoryBy, period, tz)
+		if len(totals) == 0 {
+			b.WriteString("(no counted events)\n")
+		} else {
+			for _, row := range calendarsvc.TotalsRows(totals)[1:] {
+				fmt.Fprintf(&b, "- %v: %vh in %v events\n", row[0], row[2], row[1])
+			}
+		}
+		return mcp.NewToolResultText(b.String()), nil
+	})
+
+	// Tool: Calendar Create Event
+	s.AddTool(mcp.NewTool("calendar_create_event",
+		mcp.WithDescription("Create a new event in Google Calendar"),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("summary", mcp.Required(), mcp.Description("Event title")),
+		mcp.WithString("start_time", mcp.Required(), mcp.Description("Start time (RFC3339, e.g. '2025-01-31T10:00:00Z')")),
+		mcp.WithString("end_time", mcp.Required(), mcp.Description("End time (RFC3339)")),
+		mcp.WithString("description", mcp.Description("Event description")),
+		mcp.WithString("attendees", mcp.Description("Comma-separated list of attendee emails")),
+		mcp.WithString("recurrence", mcp.Description("Make it a recurring series: RRULE lines (e.g. 'RRULE:FREQ=WEEKLY;BYDAY=MO,WE') or a description like 'weekly on Mon/Wed until 2025-12-31' or 'every 2 weeks for 6 times'")),
+		mcp.WithString("calendar_id", mcp.Description("Calendar ID (default: 'primary')")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		summary, err := request.RequireString("summary")
+		if err != nil {
+			return mcp.NewToolResultError("summary is required"), nil
+		}
+		startTime, err := request.RequireString("start_time")
+		if err != nil {
+			return mcp.NewToolResultError("start_time is required"), nil
+		}
+		endTime, err := request.RequireString("end_time")
+		if err != nil {
+			return mcp.NewToolResultError("end_time is required"), nil
+		}
+		description := request.GetString("description", "")
+		attendeesStr := request.GetString("attendees", "")
+		calendarID := request.GetString("calendar_id", "primary")
+
+		attendees := splitList(attendeesStr)
+		recurrence, err := calendarsvc.ParseRecurrence(request.GetString("recurrence", ""))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		event, err := calendarService.CreateEvent(ctx, calendarID, summary, description, startTime, endTime, attendees, recurrence)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to create event: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Created event: %s (ID: %s)", event.Summary, event.Id)), nil
+	})
+
+	// Tool: Calendar Quick Add
+	s.AddTool(mcp.NewTool("calendar_quick_add",
+		mcp.WithDescription("Create an event from a natural language description, e.g. 'Lunch with Sam Friday 12pm'. Google parses the date, time and title."),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("text", mcp.Required(), mcp.Description("Event description in natural language")),
+		mcp.WithString("calendar_id", mcp.Description("Calendar ID (default: 'primary')")),
+		mcp.WithString("send_updates", mcp.Description("Notify guests: 'all', 'externalOnly' or 'none'")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		text, err := request.RequireString("text")
+		if err != nil {
+			return mcp.NewToolResultError("text is required"), nil
+		}
+		calendarID := request.GetString("calendar_id", "primary")
+		sendUpdates := request.GetString("send_updates", "")
+
+		event, err := calendarService.QuickAdd(ctx, calendarID, text, sendUpdates)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to create event: %v", err)), nil
+		}
+		start := event.Start.DateTime
+		if start == "" {
+			start = event.Start.Date
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Created event: %s at %s (ID: %s)", event.Summary, start, event.Id)), nil
+	})
+
+	// Tool: Calendar Update Event
+	s.AddTool(mcp.NewTool("calendar_update_event",
+		mcp.WithDescription("Update an existing event in Google Calendar. Only the fields provided are changed. For recurring events, pass the series ID to change every occurrence or an instance ID (from calendar_list_instances) to change just one."),
+		mcp.WithString("event_id", mcp.Required(), mcp.Description("ID of the event, recurring series or single instance to update")),
+		mcp.WithString("calendar_id", mcp.Description("Calendar ID (default: 'primary')")),
+		mcp.WithString("summary", mcp.Description("New event title")),
+		mcp.WithString("description", mcp.Description("New event description")),
+		mcp.WithString("location", mcp.Description("New event location")),
+		mcp.WithString("start_time", mcp.Description("New start time (RFC3339, or YYYY-MM-DD for all-day). Requires end_time.")),
+		mcp.WithString("end_time", mcp.Description("New end time (RFC3339, or YYYY-MM-DD for all-day). Requires start_time.")),
+		mcp.WithString("attendees", mcp.Description("Comma-separated list of attendee emails, replacing the current list ('none' removes all)")),
+		mcp.WithString("recurrence", mcp.Description("RRULE lines or a description like 'weekly on Mon/Wed until 2025-12-31' ('none' removes recurrence). Only valid on the series, not an instance.")),
+		mcp.WithString("send_updates", mcp.Description("Notify guests: 'all', 'externalOnly' or 'none'")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		eventID, err := request.RequireString("event_id")
+		if err != nil {
+			return mcp.NewToolResultError("event_id is required"), nil
+		}
+		calendarID := request.GetString("calendar_id", "primary")
+		sendUpdates := request.GetString("send_updates", "")
+
+		var patch calendarsvc.EventPatch
+		for name, dst := range map[string]**string{
+			"summary":     &patch.Summary,
+			"description": &patch.Description,
+			"location":    &patch.Location,
+			"start_time":  &patch.StartTime,
+			"end_time":    &patch.EndTime,
+		} {
+			if v := request.GetString(name, ""); v != "" {
+				*dst = &v
+			}
+		}
+		if v := request.GetString("attendees", ""); v != "" {
+			attendees := []string{}
+			if v != "none" {
+				attendees = splitList(v)
+			}
+			patch.Attendees = &attendees
+		}
+		if v := request.GetString("recurrence", ""); v != "" {
+			recurrence := []string{}
+			if v != "none" {
+				if recurrence, err = calendarsvc.ParseRecurrence(v); err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+			}
+			patch.Recurrence = &recurrence
+		}
+
+		event, err := calendarService.PatchEvent(ctx, calendarID, eventID, patch, sendUpdates)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to update event: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Updated event: %s (ID: %s)", event.Summary, event.Id)), nil
+	})
+
+	// Tool: Calendar Free/Busy
+	s.AddTool(mcp.NewTool("calendar_freebusy",
+		mcp.WithDescription("Check availability: list busy time blocks for one or more calendars or attendees in a time range."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("time_min", mcp.Required(), mcp.Description("Start of the range (RFC3339)")),
+		mcp.WithString("time_max", mcp.Required(), mcp.Description("End of the range (RFC3339)")),
+		mcp.WithString("calendar_ids", mcp.Description("Comma-separated calendar IDs or attendee emails (default: 'primary')")),
+		mcp.WithString("time_zone", mcp.Description("Time zone for the response, e.g. 'America/Sao_Paulo' (default: UTC)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		timeMin, err := request.RequireString("time_min")
+		if err != nil {
+			return mcp.NewToolResultError("time_min is required"), nil
+		}
+		timeMax, err := request.RequireString("time_max")
+		if err != nil {
+			return mcp.NewToolResultError("time_max is required"), nil
+		}
+		ids := splitList(request.GetString("calendar_ids", "primary"))
+		timeZone := request.GetString("time_zone", "")
+
+		calendars, err := calendarService.FreeBusy(ctx, ids, timeMin, timeMax, timeZone)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to query free/busy: %v", err)), nil
+		}
+
+		var result string
+		for _, id := range ids {
+			cal, ok := calendars[id]
+			result += fmt.Sprintf("%s:\n", id)
+			switch {
+			case !ok:
+				result += "  (no data)\n"
+			case len(cal.Errors) > 0:
+				for _, e := range cal.Errors {
+					result += fmt.Sprintf("  error: %s\n", e.Reason)
+				}
+			case len(cal.Busy) == 0:
+				result += "  free for the whole range\n"
+			default:
+				for _, p := range cal.Busy {
+					result += fmt.Sprintf("  busy %s - %s\n", p.Start, p.End)
+				}
+			}
+		}
+		return mcp.NewToolResultText(result), nil
+	})
+
+	// Tool: Calendar Respond to Event
+	s.AddTool(mcp.NewTool("calendar_respond_event",
+		mcp.WithDescription("RSVP to an event you were invited to: accept, decline or mark as tentative, optionally with a note to the organizer."),
+		mcp.WithString("event_id", mcp.Required(), mcp.Description("ID of the event (or a single instance of a recurring event)")),
+		mcp.WithString("response", mcp.Required(), mcp.Description("'accepted', 'declined' or 'tentative'")),
+		mcp.WithString("comment", mcp.Description("Note for the organizer (optional)")),
+		mcp.WithString("proposed_time", mcp.Description("Alternative time to suggest, added to the comment (optional). The Calendar API cannot send a formal new-time proposal.")),
+		mcp.WithString("calendar_id", mcp.Description("Calendar ID (default: 'primary')")),
+		mcp.WithString("send_updates", mcp.Description("Notify the organizer: 'all', 'externalOnly' or 'none'")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		eventID, err := request.RequireString("event_id")
+		if err != nil {
+			return mcp.NewToolResultError("event_id is required"), nil
+		}
+		response, err := request.RequireString("response")
+		if err != nil {
+			return mcp.NewToolResultError("response is required"), nil
+		}
+		comment := request.GetString("comment", "")
+		if proposed := request.GetString("proposed_time", ""); proposed != "" {
+			comment = strings.TrimSpace(comment + " Proposed new time: " + proposed)
+		}
+		calendarID := request.GetString("calendar_id", "primary")
+		sendUpdates := request.GetString("send_updates", "")
+
+		event, err := calendarService.RespondToEvent(ctx, calendarID, eventID, response, comment, sendUpdates)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to respond to event: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Responded '%s' to: %s (ID: %s)", response, event.Summary, event.Id)), nil
+	})
+
+	// Tool: Calendar List Instances
+	s.AddTool(mcp.NewTool("calendar_list_instances",
+		mcp.WithDescription("List occurrences of a recurring event, with the instance IDs needed to update or cancel a single occurrence."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("event_id", mcp.Required(), mcp.Description("ID of the recurring event (series)")),
+		mcp.WithString("calendar_id", mcp.Description("Calendar ID (default: 'primary')")),
+		mcp.WithNumber("max_results", mcp.Description("Max instances to return (default 10)")),
+		mcp.WithString("time_min", mcp.Description("Start time (RFC3339). Optional.")),
+		mcp.WithString("time_max", mcp.Description("End time (RFC3339). Optional.")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		eventID, err := request.RequireString("event_id")
+		if err != nil {
+			return mcp.NewToolResultError("event_id is required"), nil
+		}
+		calendarID := request.GetString("calendar_id", "primary")
+		maxResults := int64(request.GetInt("max_results", 10))
+		timeMin := request.GetString("time_min", "")
+		timeMax := request.GetString("time_max", "")
+
+		instances, err := calendarService.ListInstances(ctx, calendarID, eventID, maxResults, timeMin, timeMax)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list instances: %v", err)), nil
+		}
+
+		var result string
+		for _, e := range instances {
+			start := e.Start.DateTime
+			if start == "" {
+				start = e.Start.Date
+			}
+			result += fmt.Sprintf("[%s] %s (%s)\n", start, e.Summary, e.Id)
+		}
+		if len(instances) == 0 {
+			result = "No instances found."
+		}
+		return mcp.NewToolResultText(result), nil
+	})
+
+	// Tool: Calendar Delete Event
+	s.AddTool(mcp.NewTool("calendar_delete_event",
+		mcp.WithDescription("Delete an event from Google Calendar. For recurring events, the series ID deletes every occurrence and an instance ID (from calendar_list_instances) cancels just that one."),
+		mcp.WithString("event_id", mcp.Required(), mcp.Description("ID of the event, recurring series or single instance to delete")),
+		mcp.WithString("calendar_id", mcp.Description("Calendar ID (default: 'primary')")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		eventID, err := request.RequireString("event_id")
+		if err != nil {
+			return mcp.NewToolResultError("event_id is required"), nil
+		}
+		calendarID := request.GetString("calendar_id", "primary")
+
+		if err := calendarService.DeleteEvent(ctx, calendarID, eventID); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete event: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Deleted event: %s", eventID)), nil
+	})
+
+	// Tool: Calendar Changes Since
+	calendarSyncTokens := map[string]string{} // Last sync token returned per calendar, used when sync_token is omitted
+	var calendarSyncTokensMu sync.Mutex
+	s.AddTool(mcp.NewTool("calendar_changes_since",
+		mcp.WithDescription("List events created, updated or deleted since the last sync, for cheap incremental tracking of a calendar. "+
+			"The first call (without a sync token) only establishes a baseline; later calls return the changes since the previous one. Without sync_token, the server continues from its previous call for that calendar."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("calendar_id", mcp.Description("Calendar ID (default: 'primary')")),
+		mcp.WithString("sync_token", mcp.Description("next_sync_token from a previous call")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calendarID := request.GetString("calendar_id", "primary")
+		token := request.GetString("sync_token", "")
+		if token == "" {
+			calendarSyncTokensMu.Lock()
+			token = calendarSyncTokens[calendarID]
+			calendarSyncTokensMu.Unlock()
+		}
+
+		changes, err := calendarService.ChangesSince(ctx, calendarID, token)
+		if err != nil {
+			if errors.Is(err, calendarsvc.ErrSyncTokenExpired) {
+				calendarSyncTokensMu.Lock()
+				delete(calendarSyncTokens, calendarID)
+				calendarSyncTokensMu.Unlock()
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get calendar changes: %v", err)), nil
+		}
+		calendarSyncTokensMu.Lock()
+		calendarSyncTokens[calendarID] = changes.NextSyncToken
+		calendarSyncTokensMu.Unlock()
+
+		if changes.Baseline {
+			return mcp.NewToolResultText(fmt.Sprintf("Baseline established; call again to get changes.\nnext_sync_token: %s", changes.NextSyncToken)), nil
+		}
+		jsonBytes, _ := json.MarshalIndent(changes, "", "  ")
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	})
+
+	// Tool: Sheets Create Spreadsheet
+	s.AddTool(mcp.NewTool("sheets_create_spreadsheet",
+		mcp.WithDescription("Create a new Google Sheet"),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("title", mcp.Required(), mcp.Description("Title of the spreadsheet")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		title, err := request.RequireString("title")
+		if err != nil {
+			return mcp.NewToolResultError("title is required"), nil
+		}
+
+		sp, err := sheetsService.CreateSpreadsheet(ctx, title)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to create spreadsheet: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Created spreadsheet: %s (ID: %s)\nURL: %s", sp.Properties.Title, sp.SpreadsheetId, sp.SpreadsheetUrl)), nil
+	})
+
+	// Tool: Sheets Read Values
+	s.AddTool(mcp.NewTool("sheets_read_values",
+		mcp.WithDescription("Read values from a Google Sheet range. Use value_render='FORMULA' to see formulas, or include_details='true' for formulas, notes and formatting per cell."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
+		mcp.WithString("range", mcp.Required(), mcp.Description("A1 notation range (e.g. 'Sheet1!A1:C10')")),
+		mcp.WithString("value_render", mcp.Description("'FORMATTED_VALUE' (default, as displayed), 'UNFORMATTED_VALUE' (raw numbers) or 'FORMULA'")),
+		mcp.WithString("date_time_render", mcp.Description("'SERIAL_NUMBER' (default) or 'FORMATTED_STRING'; only used with UNFORMATTED_VALUE or FORMULA")),
+		mcp.WithString("include_details", mcp.Description("If 'true', return each non-empty cell with its value, formula, note, bold/italic, number format and background color (default: false)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		if err != nil {
+			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+		}
+		rangeName, err := request.RequireString("range")
+		if err != nil {
+			return mcp.NewToolResultError("range is required"), nil
+		}
+
+		if request.GetString("include_details", "false") == "true" {
+			cells, err := sheetsService.ReadCellDetails(ctx, spreadsheetID, rangeName)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to read cells: %v", err)), nil
+			}
+			if len(cells) == 0 {
+				return mcp.NewToolResultText("No data found."), nil
+			}
+			jsonBytes, _ := json.MarshalIndent(cells, "", "  ")
+			return mcp.NewToolResultText(string(jsonBytes)), nil
+		}
+
+		opts := sheetssvc.ReadOptions{
+			ValueRender:    request.GetString("value_render", ""),
+			DateTimeRender: request.GetString("date_time_render", ""),
+		}
+		values, err := sheetsService.ReadValues(ctx, spreadsheetID, rangeName, opts)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to read values: %v", err)), nil
+		}
+
+		if len(values) == 0 {
+			return mcp.NewToolResultText("No data found."), nil
+		}
+
+		// JSON output is often best for structured data analysis by AI
+		jsonBytes, _ := json.MarshalIndent(values, "", "  ")
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	})
+
+	// Tool: Sheets Batch Read
+	s.AddTool(mcp.NewTool("sheets_batch_read",
+		mcp.WithDescription("Read several ranges of a Google Sheet in one call (e.g. headers plus data blocks from different tabs)"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
+		mcp.WithString("ranges", mcp.Required(), mcp.Description("JSON array of A1 ranges (e.g. '[\"Sheet1!A1:F1\", \"Data!A2:F100\"]')")),
+		mcp.WithString("value_render", mcp.Description("'FORMATTED_VALUE' (default), 'UNFORMATTED_VALUE' or 'FORMULA'")),
+		mcp.WithString("date_time_render", mcp.Description("'SERIAL_NUMBER' (default) or 'FORMATTED_STRING'")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		if err != nil {
+			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+		}
+		rangesJSON, err := request.RequireString("ranges")
+		if err != nil {
+			return mcp.NewToolResultError("ranges is required"), nil
+		}
+		var ranges []string
+		if err := json.Unmarshal([]byte(rangesJSON), &ranges); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("ranges must be a JSON array of strings: %v", err)), nil
+		}
+		opts := sheetssvc.ReadOptions{
+			ValueRender:    request.GetString("value_render", ""),
+			DateTimeRender: request.GetString("date_time_render", ""),
+		}
+
+		valueRanges, err := sheetsService.BatchReadValues(ctx, spreadsheetID, ranges, opts)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to read values: %v", err)), nil
+		}
+
+		type rangeValues struct {
+			Range  string          `json:"range"`
+			Values [][]interface{} `json:"values"`
+		}
+		out := make([]rangeValues, 0, len(valueRanges))
+		for _, vr := range valueRanges {
+			out = append(out, rangeValues{Range: vr.Range, Values: vr.Values})
+		}
+		jsonBytes, _ := json.MarshalIndent(out, "", "  ")
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	})
+
+	// Tool: Sheets Append Values
+	s.AddTool(mcp.NewTool("sheets_append_values",
+		mcp.WithDescription("Append values to a Google Sheet (new rows)"),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
+		mcp.WithString("range", mcp.Required(), mcp.Description("A1 notation range (e.g. 'Sheet1!A1')")),
+		mcp.WithString("values_json", mcp.Required(), mcp.Description("JSON array of arrays (e.g. '[[\"A\", \"B\"]]') or single array for one row")),
+		mcp.WithString("dry_run", mcp.Description("If 'true', write nothing and return a cell-level preview of what would change (default: false)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		if err != nil {
+			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+		}
+		rangeName, err := request.RequireString("range")
+		if err != nil {
+			return mcp.NewToolResultError("range is required"), nil
+		}
+		valuesJSON, err := request.RequireString("values_json")
+		if err != nil {
+			return mcp.NewToolResultError("values_json is required"), nil
+		}
+
+		if request.GetString("dry_run", "false") == "true" {
+			preview, err := sheetsService.PreviewAppend(ctx, spreadsheetID, rangeName, valuesJSON)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to preview append: %v", err)), nil
+			}
+			return mcp.NewToolResultText(sheetssvc.FormatPreview(preview)), nil
+		}
+
+		resp, err := sheetsService.AppendValues(ctx, spreadsheetID, rangeName, valuesJSON)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to append values: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Appended %d cells.", resp.Updates.UpdatedCells)), nil
+	})
+
+	// Tool: Sheets Append Rows By Header
+	s.AddTool(mcp.NewTool("sheets_append_rows_by_header",
+		mcp.WithDescription("Append rows given as JSON objects keyed by column header, e.g. {\"Date\": \"2024-05-01\", \"Status\": \"done\"}. Values land under the matching headers regardless of column order; keys match headers case-insensitively."),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
+		mcp.WithString("rows", mcp.Required(), mcp.Description("JSON object or array of objects")),
+		mcp.WithString("sheet", mcp.Description("Tab title or sheet ID (default: first tab)")),
+		mcp.WithNumber("header_row", mcp.Description("Row number holding the headers (default 1)")),
+		mcp.WithString("add_missing_columns", mcp.Description("If 'true', keys without a matching header become new columns (and an empty tab gets a header row); otherwise they are an error (default: false)")),
+		mcp.WithString("dry_run", mcp.Description("If 'true', write nothing and return a cell-level preview of what would change (default: false)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		if err != nil {
+			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+		}
+		rowsJSON, err := request.RequireString("rows")
+		if err != nil {
+			return mcp.NewToolResultError("rows is required"), nil
+		}
+		records, err := sheetssvc.ParseRecords(rowsJSON)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		res, err := sheetsService.AppendRecords(ctx, spreadsheetID, request.GetString("sheet", ""), request.GetInt("header_row", 1), records,
+			request.GetString("add_missing_columns", "false") == "true", false, request.GetString("dry_run", "false") == "true")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to append rows: %v", err)), nil
+		}
+		if res.Preview != nil {
+			return mcp.NewToolResultText(sheetssvc.FormatPreview(res.Preview)), nil
+		}
+		msg := fmt.Sprintf("Appended %d rows at %s", res.Rows, res.UpdatedRange)
+		if len(res.AddedColumns) > 0 {
+			msg += fmt.Sprintf("\nAdded columns: %s", strings.Join(res.AddedColumns, ", "))
+		}
+		return mcp.NewToolResultText(msg), nil
+	})
+
+	// Tool: Sheets Update Values
+	s.AddTool(mcp.NewTool("sheets_update_values",
+		mcp.WithDescription("Update values in a Google Sheet range (overwrite)"),
+		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
+		mcp.WithString("range", mcp.Required(), mcp.Description("A1 notation range (e.g. 'Sheet1!A1')")),
+		mcp.WithString("values_json", mcp.Required(), mcp.Description("JSON array of arrays")),
+		mcp.WithString("dry_run", mcp.Description("If 'true', write nothing and return a cell-level preview of what would change (default: false)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		if err != nil {
+			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+		}
+		rangeName, err := request.RequireString("range")
+		if err != nil {
+			return mcp.NewToolResultError("range is required"), nil
+		}
+		valuesJSON, err := request.RequireString("values_json")
+		if err != nil {
+			return mcp.NewToolResultError("values_json is required"), nil
+		}
+
+		if request.GetString("dry_run", "false") == "true" {
+			preview, err := sheetsService.PreviewUpdate(ctx, spreadsheetID, rangeName, valuesJSON)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to preview update: %v", err)), nil
+			}
+			return mcp.NewToolResultText(sheetssvc.FormatPreview(preview)), nil
+		}
+
+		resp, err := sheetsService.UpdateValues(ctx, spreadsheetID, rangeName, valuesJSON)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to update values: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Updated %d cells.", resp.UpdatedCells)), nil
+	})
+
+	// Tool: Sheets Get Spreadsheet (metadata, sheet IDs and titles)
+	s.AddTool(mcp.NewTool("sheets_get_spreadsheet",
+		mcp.WithDescription("Get spreadsheet metadata: tab names, sheet IDs, grid sizes, frozen rows and named ranges. Call this before reading to avoid guessing tab names."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		if err != nil {
+			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+		}
+		sp, err := sheetsService.GetSpreadsheet(ctx, spreadsheetID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get spreadsheet: %v", err)), nil
+		}
+		type sheetInfo struct {
+			SheetId      int64  `json:"sheetId"`
+			Title        string `json:"title"`
+			Index        int64  `json:"index"`
+			SheetType    string `json:"sheetType,omitempty"`
+			RowCount     int64  `json:"rowCount,omitempty"`
+			ColumnCount  int64  `json:"columnCount,omitempty"`
+			FrozenRows   int64  `json:"frozenRows,omitempty"`
+			FrozenColumn int64  `json:"frozenColumns,omitempty"`
+			Hidden       bool   `json:"hidden,omitempty"`
+		}
+		type namedRange struct {
+			Name  string `json:"name"`
+			Range string `json:"range"`
+		}
+		var sheets []sheetInfo
+		titles := map[int64]string{}
+		for _, sh := range sp.Sheets {
+			if sh.Properties == nil {
+				continue
+			}
+			p := sh.Properties
+			titles[p.SheetId] = p.Title
+			info := sheetInfo{SheetId: p.SheetId, Title: p.Title, Index: p.Index, SheetType: p.SheetType, Hidden: p.Hidden}
+			if g := p.GridProperties; g != nil {
+				info.RowCount, info.ColumnCount = g.RowCount, g.ColumnCount
+				info.FrozenRows, info.FrozenColumn = g.FrozenRowCount, g.FrozenColumnCount
+			}
+			sheets = append(sheets, info)
+		}
+		var named []namedRange
+		for _, nr := range sp.NamedRanges {
+			title := ""
+			if nr.Range != nil {
+				title = titles[nr.Range.SheetId]
+			}
+			named = append(named, namedRange{Name: nr.Name, Range: sheetssvc.GridRangeA1(title, nr.Range)})
+		}
+		out := map[string]interface{}{"spreadsheetId": sp.SpreadsheetId, "title": sp.Properties.Title, "locale": sp.Properties.Locale, "timeZone": sp.Properties.TimeZone, "sheets": sheets}
+		if len(named) > 0 {
+			out["namedRanges"] = named
+		}
+		jsonBytes, _ := json.MarshalIndent(out, "", "  ")
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	})
+
+	// Tool: Sheets Batch Update (add sheet, rename sheet, etc.)
+	s.AddTool(mcp.NewTool("sheets_batch_update",
+		mcp.WithDescription("Apply batch update: add sheets, rename sheets. Pass requests as JSON (e.g. {\"requests\": [{\"addSheet\": {\"properties\": {\"title\": \"TabName\"}}}]})"),
+		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
+		mcp.WithString("requests_json", mcp.Required(), mcp.Description("JSON object with \"requests\" array (Google Sheets BatchUpdateSpreadsheetRequest)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		if err != nil {
+			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+		}
+		requestsJSON, err := request.RequireString("requests_json")
+		if err != nil {
+			return mcp.NewToolResultError("requests_json is required"), nil
+		}
+		var req sheets.BatchUpdateSpreadsheetRequest
+		if err := json.Unmarshal([]byte(requestsJSON), &req); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf(
+				"Invalid requests_json format. Expected Google Sheets BatchUpdateSpreadsheetRequest structure. "+
+					"Example: {\"requests\":[{\"addSheet\":{\"properties\":{\"title\":\"Sheet\"}}}]}. Error: %v",
+				err)), nil
+		}
+		resp, err := sheetsService.BatchUpdate(ctx, spreadsheetID, &req)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to batch update: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Batch update applied. Replies: %d", len(resp.Replies))), nil
+	})
+
+	// Tool: Sheets Add Sheet
+	s.AddTool(mcp.NewTool("sheets_add_sheet",
+		mcp.WithDescription("Add a new tab to a spreadsheet"),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
+		mcp.WithString("title", mcp.Required(), mcp.Description("Title of the new tab")),
+		mcp.WithNumber("rows", mcp.Description("Initial row count (default: API default, 1000)")),
+		mcp.WithNumber("columns", mcp.Description("Initial column count (default: API default, 26)")),
+		mcp.WithNumber("index", mcp.Description("Zero-based position of the tab (default: last)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		if err != nil {
+			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+		}
+		title, err := request.RequireString("title")
+		if err != nil {
+			return mcp.NewToolResultError("title is required"), nil
+		}
+		p, err := sheetsService.AddSheet(ctx, spreadsheetID, title, int64(request.GetInt("rows", 0)), int64(request.GetInt("columns", 0)), int64(request.GetInt("index", -1)))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to add sheet: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Added tab '%s' (sheetId: %d, index: %d)", p.Title, p.SheetId, p.Index)), nil
+	})
+
+	// Tool: Sheets Delete Sheet
+	s.AddTool(mcp.NewTool("sheets_delete_sheet",
+		mcp.WithDescription("Delete a tab from a spreadsheet. All data on the tab is lost."),
+		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
+		mcp.WithString("sheet", mcp.Required(), mcp.Description("Tab title or numeric sheet ID")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		if err != nil {
+			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+		}
+		ref, err := request.RequireString("sheet")
+		if err != nil {
+			return mcp.NewToolResultError("sheet is required"), nil
+		}
+		p, err := sheetsService.ResolveSheet(ctx, spreadsheetID, ref)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to find sheet: %v", err)), nil
+		}
+		if err := sheetsService.DeleteSheet(ctx, spreadsheetID, p.SheetId); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete sheet: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Deleted tab '%s' (sheetId: %d)", p.Title, p.SheetId)), nil
+	})
+
+	// Tool: Sheets Rename Sheet
+	s.AddTool(mcp.NewTool("sheets_rename_sheet",
+		mcp.WithDescription("Rename a tab in a spreadsheet"),
+		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
+		mcp.WithString("sheet", mcp.Required(), mcp.Description("Current tab title or numeric sheet ID")),
+		mcp.WithString("new_title", mcp.Required(), mcp.Description("New tab title")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		if err != nil {
+			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+		}
+		ref, err := request.RequireString("sheet")
+		if err != nil {
+			return mcp.NewToolResultError("sheet is required"), nil
+		}
+		newTitle, err := request.RequireString("new_title")
+		if err != nil {
+			return mcp.NewToolResultError("new_title is required"), nil
+		}
+		p, err := sheetsService.ResolveSheet(ctx, spreadsheetID, ref)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to find sheet: %v", err)), nil
+		}
+		if err := sheetsService.RenameSheet(ctx, spreadsheetID, p.SheetId, newTitle); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to rename sheet: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Renamed tab '%s' to '%s' (sheetId: %d)", p.Title, newTitle, p.SheetId)), nil
+	})
+
+	// Tool: Sheets Duplicate Sheet
+	s.AddTool(mcp.NewTool("sheets_duplicate_sheet",
+		mcp.WithDescription("Duplicate a tab (values, formulas and formatting) within the same spreadsheet"),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
+		mcp.WithString("sheet", mcp.Required(), mcp.Description("Tab title or numeric sheet ID to copy")),
+		mcp.WithString("new_title", mcp.Description("Title of the copy (default: 'Copy of <title>')")),
+		mcp.WithNumber("index", mcp.Description("Zero-based position of the copy (default: last)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		if err != nil {
+			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+		}
+		ref, err := request.RequireString("sheet")
+		if err != nil {
+			return mcp.NewToolResultError("sheet is required"), nil
+		}
+		src, err := sheetsService.ResolveSheet(ctx, spreadsheetID, ref)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to find sheet: %v", err)), nil
+		}
+		p, err := sheetsService.DuplicateSheet(ctx, spreadsheetID, src.SheetId, request.GetString("new_title", ""), int64(request.GetInt("index", -1)))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to duplicate sheet: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Duplicated '%s' as '%s' (sheetId: %d, index: %d)", src.Title, p.Title, p.SheetId, p.Index)), nil
+	})
+
+	// Tool: Sheets Query
+	s.AddTool(mcp.NewTool("sheets_query",
+		mcp.WithDescription("Filter rows of a Google Sheet by column conditions and return only the matches with their row numbers, instead of reading the whole range. "+
+			"Example filters: [{\"column\": \"Status\", \"op\": \"eq\", \"value\": \"Open\"}, {\"column\": \"Amount\", \"op\": \"gt\", \"value\": \"1000\"}]"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
+		mcp.WithString("range", mcp.Required(), mcp.Description("A1 range including the header row (e.g. 'Sheet1!A:F' or 'Data')")),
+		mcp.WithString("filters", mcp.Description("JSON array of {column, op, value}. column is a header name or column letter; op is one of eq, ne, contains, not_contains, starts_with, ends_with, gt, gte, lt, lte, empty, not_empty, regex. Numbers compare numerically, other values as text (ISO dates sort correctly).")),
+		mcp.WithString("match", mcp.Description("'all' (default) or 'any' of the filters")),
+		mcp.WithString("columns", mcp.Description("Comma-separated columns to return (default: all)")),
+		mcp.WithString("has_header", mcp.Description("If 'false', the first row is data and columns are addressed by letter (default: true)")),
+		mcp.WithNumber("limit", mcp.Description("Maximum rows to return (default 100)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		if err != nil {
+			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+		}
+		rangeName, err := request.RequireString("range")
+		if err != nil {
+			return mcp.NewToolResultError("range is required"), nil
+		}
+		var filters []sheetssvc.RowFilter
+		if filtersJSON := request.GetString("filters", ""); filtersJSON != "" {
+			if err := json.Unmarshal([]byte(filtersJSON), &filters); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("filters must be a JSON array of {column, op, value}: %v", err)), nil
+			}
+		}
+		opts := sheetssvc.QueryOptions{
+			HasHeader: request.GetString("has_header", "true") != "false",
+			MatchAny:  request.GetString("match", "all") == "any",
+			Columns:   splitList(request.GetString("columns", "")),
+			Limit:     request.GetInt("limit", 100),
+		}
+		res, err := sheetsService.QueryRows(ctx, spreadsheetID, rangeName, filters, opts)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to query sheet: %v", err)), nil
+		}
+		jsonBytes, _ := json.MarshalIndent(res, "", "  ")
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	})
+
+	// Tool: Sheets Find Replace
+	s.AddTool(mcp.NewTool("sheets_find_replace",
+		mcp.WithDescription("Find and replace text in a spreadsheet, optionally limited to one tab or range"),
+		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
+		mcp.WithString("find", mcp.Required(), mcp.Description("Text (or regex with regex='true') to find")),
+		mcp.WithString("replacement", mcp.Required(), mcp.Description("Replacement text; may be empty. With regex, $1 etc. refer to groups")),
+		mcp.WithString("range", mcp.Description("A1 range to limit the search (e.g. 'Sheet1!A2:D')")),
+		mcp.WithString("sheet", mcp.Description("Tab title or sheet ID to limit the search (default: all tabs)")),
+		mcp.WithString("match_case", mcp.Description("'true' for case-sensitive matching (default: false)")),
+		mcp.WithString("match_entire_cell", mcp.Description("'true' to only match whole cell contents (default: false)")),
+		mcp.WithString("regex", mcp.Description("'true' to treat find as a regular expression (default: false)")),
+		mcp.WithString("include_formulas", mcp.Description("'true' to also search inside formulas (default: false)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		if err != nil {
+			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+		}
+		find, err := request.RequireString("find")
+		if err != nil || find == "" {
+			return mcp.NewToolResultError("find is required"), nil
+		}
+		replacement := request.GetString("replacement", "")
+		opts := sheetssvc.FindReplaceOptions{
+			Range:           request.GetString("range", ""),
+			Sheet:           request.GetString("sheet", ""),
+			MatchCase:       request.GetString("match_case", "false") == "true",
+			MatchEntireCell: request.GetString("match_entire_cell", "false") == "true",
+			Regex:           request.GetString("regex", "false") == "true",
+			IncludeFormulas: request.GetString("include_formulas", "false") == "true",
+		}
+		resp, err := sheetsService.FindReplace(ctx, spreadsheetID, find, replacement, opts)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to find and replace: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Replaced %d occurrences in %d cells (%d rows, %d tabs, %d formulas)",
+			resp.OccurrencesChanged, resp.ValuesChanged, resp.RowsChanged, resp.SheetsChanged, resp.FormulasChanged)), nil
+	})
+
+	// Tool: Sheets Import CSV
+	s.AddTool(mcp.NewTool("sheets_import_csv",
+		mcp.WithDescription("Import CSV data into a new tab of a spreadsheet. Provide the CSV text directly or a Drive file ID (a CSV file, or a Google Sheet whose first tab is exported as CSV)."),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet to import into")),
+		mcp.WithString("tab_title", mcp.Required(), mcp.Description("Title of the new tab to create")),
+		mcp.WithString("csv", mcp.Description("CSV text")),
+		mcp.WithString("file_id", mcp.Description("Drive file ID to read the CSV from, instead of csv")),
+		mcp.WithString("delimiter", mcp.Description("Field delimiter: ',' (default), ';', '|' or 'tab'")),
+		mcp.WithString("raw", mcp.Description("If 'true', store values as plain text instead of parsing numbers, dates and formulas (default: false)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		if err != nil {
+			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+		}
+		title, err := request.RequireString("tab_title")
+		if err != nil {
+			return mcp.NewToolResultError("tab_title is required"), nil
+		}
+		text := request.GetString("csv", "")
+		if fileID := request.GetString("file_id", ""); fileID != "" {
+			if text != "" {
+				return mcp.NewToolResultError("Provide either csv or file_id, not both"), nil
+			}
+			f, err := driveService.DownloadFile(ctx, fileID, "text/csv")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to download CSV: %v", err)), nil
+			}
+			text = string(f.Data)
+		}
+		if text == "" {
+			return mcp.NewToolResultError("csv or file_id is required"), nil
+		}
+		var delimiter rune
+		switch d := request.GetString("delimiter", ","); d {
+		case "tab", `\t`:
+			delimiter = '\t'
+		default:
+			if r := []rune(d); len(r) == 1 {
+				delimiter = r[0]
+			} else {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid delimiter %q", d)), nil
+			}
+		}
+		rows, err := sheetssvc.ParseCSV(text, delimiter)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to import CSV: %v", err)), nil
+		}
+		p, err := sheetsService.ImportCSV(ctx, spreadsheetID, title, rows, request.GetString("raw", "false") == "true")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to import CSV: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Imported %d rows into tab '%s' (sheetId: %d)", len(rows), p.Title, p.SheetId)), nil
+	})
+
+	// Tool: Sheets Export CSV
+	s.AddTool(mcp.NewTool("sheets_export_csv",
+		mcp.WithDescription("Return a range of a Google Sheet as CSV text"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
+		mcp.WithString("range", mcp.Required(), mcp.Description("A1 notation range or tab title (e.g. 'Sheet1!A1:F200' or 'Data')")),
+		mcp.WithString("value_render", mcp.Description("'FORMATTED_VALUE' (default, as displayed), 'UNFORMATTED_VALUE' (raw numbers) or 'FORMULA'")),
+		mcp.WithNumber("max_rows", mcp.Description("Maximum rows to return (default 1000, 0 for all)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		if err != nil {
+			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+		}
+		rangeName, err := request.RequireString("range")
+		if err != nil {
+			return mcp.NewToolResultError("range is required"), nil
+		}
+		values, err := sheetsService.ReadValues(ctx, spreadsheetID, rangeName, sheetssvc.ReadOptions{ValueRender: request.GetString("value_render", "")})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to read values: %v", err)), nil
+		}
+		if len(values) == 0 {
+			return mcp.NewToolResultText("No data found."), nil
+		}
+		total := len(values)
+		if maxRows := request.GetInt("max_rows", 1000); maxRows > 0 && total > maxRows {
+			values = values[:maxRows]
+		}
+		out, err := sheetssvc.FormatCSV(values)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to export CSV: %v", err)), nil
+		}
+		if len(values) < total {
+			out += fmt.Sprintf("\n[Truncated: showing %d of %d rows. Raise max_rows or narrow the range.]", len(values), total)
+		}
+		return mcp.NewToolResultText(out), nil
+	})
+
+	// Tool: Sheets Format Range
+	s.AddTool(mcp.NewTool("sheets_format_range",
+		mcp.WithDescription("Format a range of a Google Sheet: bold/italic, colors, number formats, alignment, borders, frozen rows/columns, column widths and conditional formatting. "+
+			"Example format: {\"bold\": true, \"background_color\": \"#D9EAD3\", \"freeze_rows\": 1, \"auto_resize_columns\": true} for a header row, "+
+			"{\"number_format\": \"#,##0.00\"} or {\"number_format\": \"PERCENT\"} for numbers, "+
+			"{\"conditional\": [{\"formula\": \"=$C2<0\", \"text_color\": \"#CC0000\"}]} to highlight negatives."),
+		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
+		mcp.WithString("range", mcp.Required(), mcp.Description("A1 notation range (e.g. 'Sheet1!A1:F1', 'Data!C:C'); without a tab name the first tab is used")),
+		mcp.WithString("format", mcp.Required(), mcp.Description("JSON object with any of: bold, italic, font_size, text_color, background_color (#RRGGBB), number_format (pattern or type: NUMBER, CURRENCY, PERCENT, DATE, TIME, DATE_TIME, SCIENTIFIC, TEXT), number_format_type, horizontal_alignment (LEFT/CENTER/RIGHT), wrap, borders (all/outer/inner/none), border_color, freeze_rows, freeze_columns, column_width (pixels), auto_resize_columns, conditional (array of {type, values, formula, bold, text_color, background_color})")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		if err != nil {
+			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+		}
+		rangeName, err := request.RequireString("range")
+		if err != nil {
+			return mcp.NewToolResultError("range is required"), nil
+		}
+		formatJSON, err := request.RequireString("format")
+		if err != nil {
+			return mcp.NewToolResultError("format is required"), nil
+		}
+		var spec sheetssvc.FormatSpec
+		dec := json.NewDecoder(strings.NewReader(formatJSON))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&spec); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid format JSON: %v", err)), nil
+		}
+		n, err := sheetsService.FormatRange(ctx, spreadsheetID, rangeName, spec)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to format range: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Formatted %s (%d update requests applied)", rangeName, n)), nil
+	})
+
+	// Tool: Sheets Add Chart
+	s.AddTool(mcp.NewTool("sheets_add_chart",
+		mcp.WithDescription("Add a chart built from a range. The first column of the range holds the categories (x axis or pie slices), each following column is a series, and the first row holds the headers."),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
+		mcp.WithString("range", mcp.Required(), mcp.Description("Source range including headers (e.g. 'Sales!A1:C13')")),
+		mcp.WithString("chart_type", mcp.Description("COLUMN (default), BAR, LINE, AREA, SCATTER, COMBO (first series as columns, rest as lines) or PIE (uses the first series only)")),
+		mcp.WithString("title", mcp.Description("Chart title")),
+		mcp.WithString("x_axis_title", mcp.Description("Title of the category axis")),
+		mcp.WithString("y_axis_title", mcp.Description("Title of the value axis")),
+		mcp.WithString("stacked", mcp.Description("If 'true', stack the series (default: false)")),
+		mcp.WithString("anchor_cell", mcp.Description("Cell where the chart's top-left corner is placed (e.g. 'Sales!E2'); omit to put the chart on its own new tab")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		if err != nil {
+			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+		}
+		rangeName, err := request.RequireString("range")
+		if err != nil {
+			return mcp.NewToolResultError("range is required"), nil
+		}
+		spec := sheetssvc.ChartSpec{
+			Type:    request.GetString("chart_type", ""),
+			Title:   request.GetString("title", ""),
+			XTitle:  request.GetString("x_axis_title", ""),
+			YTitle:  request.GetString("y_axis_title", ""),
+			Stacked: request.GetString("stacked", "false") == "true",
+		}
+		chartID, err := sheetsService.AddChart(ctx, spreadsheetID, rangeName, request.GetString("anchor_cell", ""), spec)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to add chart: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Chart added (chartId: %d)", chartID)), nil
+	})
+
+	// Tool: Sheets Add Pivot Table
+	s.AddTool(mcp.NewTool("sheets_add_pivot_table",
+		mcp.WithDescription("Create a pivot table summarizing a range by header names, e.g. rows='Region', values='Amount:SUM,Order ID:COUNTA'"),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
+		mcp.WithString("source_range", mcp.Required(), mcp.Description("Source data range including the header row (e.g. 'Orders!A1:F500' or 'Orders!A:F')")),
+		mcp.WithString("rows", mcp.Description("Comma-separated columns (header names or letters) to group rows by")),
+		mcp.WithString("columns", mcp.Description("Comma-separated columns to group columns by")),
+		mcp.WithString("values", mcp.Required(), mcp.Description("Comma-separated value columns, each optionally followed by ':FUNCTION' (SUM default, COUNTA, COUNT, COUNTUNIQUE, AVERAGE, MAX, MIN, MEDIAN)")),
+		mcp.WithString("anchor_cell", mcp.Description("Cell where the pivot table starts (e.g. 'Summary!A1'); omit to create a new tab")),
+		mcp.WithString("tab_title", mcp.Description("Title of the new tab when anchor_cell is omitted (default: 'Pivot of <source tab>')")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		if err != nil {
+			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+		}
+		source, err := request.RequireString("source_range")
+		if err != nil {
+			return mcp.NewToolResultError("source_range is required"), nil
+		}
+		spec := sheetssvc.PivotSpec{
+			Rows:    splitList(request.GetString("rows", "")),
+			Columns: splitList(request.GetString("columns", "")),
+		}
+		for _, v := range splitList(request.GetString("values", "")) {
+			column, fn, _ := strings.Cut(v, ":")
+			spec.Values = append(spec.Values, sheetssvc.PivotValue{Column: strings.TrimSpace(column), Summarize: strings.TrimSpace(fn)})
+		}
+		location, err := sheetsService.AddPivotTable(ctx, spreadsheetID, source, request.GetString("anchor_cell", ""), request.GetString("tab_title", ""), spec)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to add pivot table: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Pivot table created at %s", location)), nil
+	})
+
+	// Tool: Sheets Protect Range
+	s.AddTool(mcp.NewTool("sheets_protect_range",
+		mcp.WithDescription("Protect a range or a whole tab so others can't accidentally edit it (e.g. header rows or formula columns of a shared tracker)"),
+		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
+		mcp.WithString("range", mcp.Required(), mcp.Description("A1 range (e.g. 'Tracker!A1:F1') or a tab title to protect the whole tab")),
+		mcp.WithString("description", mcp.Description("Description shown for the protection")),
+		mcp.WithString("warning_only", mcp.Description("If 'true', editors get a warning instead of being blocked (default: false)")),
+		mcp.WithString("editors", mcp.Description("Comma-separated emails allowed to edit besides the owner (default: only the owner and you)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		if err != nil {
+			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+		}
+		rangeName, err := request.RequireString("range")
+		if err != nil {
+			return mcp.NewToolResultError("range is required"), nil
+		}
+		id, err := sheetsService.ProtectRange(ctx, spreadsheetID, rangeName, request.GetString("description", ""),
+			request.GetString("warning_only", "false") == "true", splitList(request.GetString("editors", "")))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to protect range: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Protected %s (protectedRangeId: %d)", rangeName, id)), nil
+	})
+
+	// Tool: Sheets Set Validation
+	s.AddTool(mcp.NewTool("sheets_set_validation",
+		mcp.WithDescription("Add a dropdown or other data validation rule to a range, or remove validation with clear='true'"),
+		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
+		mcp.WithString("range", mcp.Required(), mcp.Description("A1 range to validate (e.g. 'Tracker!C2:C')")),
+		mcp.WithString("type", mcp.Description("list (dropdown of values), range (dropdown from cells, e.g. values='Lists!A2:A'), checkbox, formula (custom formula), email, url, date, or a Sheets condition type such as NUMBER_BETWEEN, NUMBER_GREATER, TEXT_CONTAINS, DATE_AFTER")),
+		mcp.WithString("values", mcp.Description("Comma-separated values, or a JSON array when values contain commas (e.g. 'Open,In progress,Done' or '[\"1\", \"100\"]')")),
+		mcp.WithString("strict", mcp.Description("If 'true', reject invalid input; otherwise it is only flagged (default: true)")),
+		mcp.WithString("input_message", mcp.Description("Help text shown when a cell in the range is selected")),
+		mcp.WithString("clear", mcp.Description("If 'true', remove data validation from the range instead")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		if err != nil {
+			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+		}
+		rangeName, err := request.RequireString("range")
+		if err != nil {
+			return mcp.NewToolResultError("range is required"), nil
+		}
+		if request.GetString("clear", "false") == "true" {
+			if err := sheetsService.SetValidation(ctx, spreadsheetID, rangeName, nil); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to clear validation: %v", err)), nil
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Removed data validation from %s", rangeName)), nil
+		}
+		var values []string
+		if raw := strings.TrimSpace(request.GetString("values", "")); strings.HasPrefix(raw, "[") {
+			if err := json.Unmarshal([]byte(raw), &values); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("values must be a JSON array of strings: %v", err)), nil
+			}
+		} else {
+			values = splitList(raw)
+		}
+		spec := &sheetssvc.ValidationSpec{
+			Type:         request.GetString("type", ""),
+			Values:       values,
+			Strict:       request.GetString("strict", "true") == "true",
+			InputMessage: request.GetString("input_message", ""),
+		}
+		if err := sheetsService.SetValidation(ctx, spreadsheetID, rangeName, spec); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to set validation: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Data validation (%s) set on %s", spec.Type, rangeName)), nil
+	})
+
+	// Tool: Sheets Clear Values
+	s.AddTool(mcp.NewTool("sheets_clear_values",
+		mcp.WithDescription("Clear values (not formatting) in one range or several ranges at once. Provide 'range' or 'ranges'."),
+		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
+		mcp.WithString("range", mcp.Description("A1 notation range (e.g. 'Sheet1!A7:Z100')")),
+		mcp.WithString("ranges", mcp.Description("JSON array of A1 ranges to clear in one request (e.g. '[\"Sheet1!A2:F\", \"Data!B:B\"]')")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		if err != nil {
+			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+		}
+		var ranges []string
+		if rangesJSON := request.GetString("ranges", ""); rangesJSON != "" {
+			if err := json.Unmarshal([]byte(rangesJSON), &ranges); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("ranges must be a JSON array of strings: %v", err)), nil
+			}
+		}
+		if r := request.GetString("range", ""); r != "" {
+			ranges = append(ranges, r)
+		}
+		if len(ranges) == 0 {
+			return mcp.NewToolResultError("range or ranges is required"), nil
+		}
+		if len(ranges) == 1 {
+			resp, err := sheetsService.ClearValues(ctx, spreadsheetID, ranges[0])
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to clear values: %v", err)), nil
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Cleared %s", resp.ClearedRange)), nil
+		}
+		cleared, err := sheetsService.BatchClearValues(ctx, spreadsheetID, ranges)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to clear values: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Cleared %d ranges: %s", len(cleared), strings.Join(cleared, ", "))), nil
+	})
+
+	// Tool: People List Connections
+	s.AddTool(mcp.NewTool("people_list_connections",
+		mcp.WithDescription("List contacts (connections)"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("limit", mcp.Description("Max contacts to return (default 10)")),
+		mcp.WithString("fields", mcp.Description("Comma-separated extra fields: phone, organization, birthday, photo, address, notes, url, or 'all' (default: name and email)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		limit := int64(request.GetInt("limit", 10))
+		fields, err := peoplesvc.PersonFields(request.GetString("fields", ""))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		connections, err := peopleService.ListConnections(ctx, limit, fields)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list connections: %v", err)), nil
+		}
+
+		var result string
+		for _, p := range connections {
+			result += peoplesvc.FormatContact(peoplesvc.Summarize(p)) + "\n"
+		}
+		if len(connections) == 0 {
+			result = "No connections found."
+		}
+		return mcp.NewToolResultText(result), nil
+	})
+
+	// Tool: People Search Contacts
+	s.AddTool(mcp.NewTool("people_search_contacts",
+		mcp.WithDescription("Search contacts by name, email, phone number or organization (prefix match)"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("query", mcp.Required(), mcp.Description("Text to search for, e.g. 'ana' or 'ana@example.com'")),
+		mcp.WithNumber("limit", mcp.Description("Max contacts to return (default 10, max 30)")),
+		mcp.WithString("fields", mcp.Description("Comma-separated extra fields: phone, organization, birthday, photo, address, notes, url, or 'all' (default: name and email)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		query, err := request.RequireString("query")
+		if err != nil || query == "" {
+			return mcp.NewToolResultError("query is required"), nil
+		}
+		fields, err := peoplesvc.PersonFields(request.GetString("fields", ""))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		contacts, err := peopleService.SearchContacts(ctx, query, fields, int64(request.GetInt("limit", 10)))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to search contacts: %v", err)), nil
+		}
+
+		var result string
+		for _, p := range contacts {
+			result += peoplesvc.FormatContact(peoplesvc.Summarize(p)) + "\n"
+		}
+		if len(contacts) == 0 {
+			result = fmt.Sprintf("No contacts matching %q.", query)
+		}
+		return mcp.NewToolResultText(result), nil
+	})
+
+	// Tool: People Create Contact
+	s.AddTool(mcp.NewTool("people_create_contact",
+		mcp.WithDescription("Create a new contact"),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("given_name", mcp.Required(), mcp.Description("First name")),
+		mcp.WithString("family_name", mcp.Description("Last name")),
+		mcp.WithString("email", mcp.Description("Email address")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		givenName, err := request.RequireString("given_name")
+		if err != nil {
+			return mcp.NewToolResultError("given_name is required"), nil
+		}
+		familyName := request.GetString("family_name", "")
+		email := request.GetString("email", "")
+
+		person, err := peopleService.CreateContact(ctx, givenName, familyName, email)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to create contact: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Created contact: %s (ID: %s)", givenName, person.ResourceName)), nil
+	})
+
+	// Tool: People Find Duplicates
+	s.AddTool(mcp.NewTool("people_find_duplicates",
+		mcp.WithDescription("Find probable duplicate contacts: groups of contacts sharing an email address, a phone number or a full name. Review the groups, then use people_merge_contacts."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("limit", mcp.Description("Max groups to return (default 50)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		persons, err := peopleService.AllConnections(ctx, "names,emailAddresses,phoneNumbers,organizations")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list connections: %v", err)), nil
+		}
+		groups := peoplesvc.FindDuplicates(persons)
+		if len(groups) == 0 {
+			return mcp.NewToolResultText(fmt.Sprintf("No duplicates found among %d contacts.", len(persons))), nil
+		}
+		total := len(groups)
+		if limit := request.GetInt("limit", 50); limit > 0 && len(groups) > limit {
+			groups = groups[:limit]
+		}
+		jsonBytes, _ := json.MarshalIndent(groups, "", "  ")
+		return mcp.NewToolResultText(fmt.Sprintf("%d duplicate groups among %d contacts (showing %d):\n%s", total, len(persons), len(groups), jsonBytes)), nil
+	})
+
+	// Tool: People Merge Contacts
+	s.AddTool(mcp.NewTool("people_merge_contacts",
+		mcp.WithDescription("Merge duplicate contacts into one: emails, phones, organizations, addresses and URLs of the others are added to the kept contact (plus birthday and notes if it has none), then the others are deleted. "+
+			"Without confirm='true' only a preview of the merged contact is returned."),
+		mcp.WithString("keep", mcp.Required(), mcp.Description("Resource name of the contact to keep (e.g. people/c123)")),
+		mcp.WithString("merge", mcp.Required(), mcp.Description("Comma-separated resource names of the contacts to merge into it and delete")),
+		mcp.WithString("confirm", mcp.Description("Must be 'true' to apply the merge and delete the other contacts; otherwise a preview is returned")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		keep, err := request.RequireString("keep")
+		if err != nil {
+			return mcp.NewToolResultError("keep is required"), nil
+		}
+		others := splitList(request.GetString("merge", ""))
+		if len(others) == 0 {
+			return mcp.NewToolResultError("merge is required"), nil
+		}
+		dryRun := request.GetString("confirm", "") != "true"
+
+		merged, err := peopleService.MergeContacts(ctx, keep, others, dryRun)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to merge contacts: %v", err)), nil
+		}
+		line := peoplesvc.FormatContact(peoplesvc.Summarize(merged))
+		if dryRun {
+			return mcp.NewToolResultText(fmt.Sprintf("Preview (nothing changed; set confirm='true' after checking with the user):\n%s\nWould delete: %s", line, strings.Join(others, ", "))), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Merged contact:\n%s\nDeleted: %s", line, strings.Join(others, ", "))), nil
+	})
+
+	// Tool: People Export Contacts
+	s.AddTool(mcp.NewTool("people_export_contacts",
+		mcp.WithDescription("Export all contacts (names, emails, phones, organization, birthday, addresses, notes, URLs) as CSV or vCard, returned inline or saved to Drive, for backups or moving them to another account or app"),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("format", mcp.Description("'csv' or 'vcard' (default: csv)")),
+		mcp.WithString("save_to_drive", mcp.Description("If 'true', save the export as a Drive file and return its link instead of the content (default: false)")),
+		mcp.WithString("parent_id", mcp.Description("Drive folder ID to save into (default: My Drive)")),
+		mcp.WithString("name", mcp.Description("File name (default: contacts-<date>.csv or .vcf)")),
+		mcp.WithNumber("max_bytes", mcp.Description("Max size to return inline when save_to_drive is 'false' (default 1048576)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		format := request.GetString("format", peoplesvc.FormatCSV)
+		persons, err := peopleService.AllConnections(ctx, peoplesvc.TransferFields)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list connections: %v", err)), nil
+		}
+		text, err := peoplesvc.FormatContacts(persons, format)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if request.GetString("save_to_drive", "false") != "true" {
+			if maxBytes := request.GetInt("max_bytes", 1024*1024); len(text) > maxBytes {
+				return mcp.NewToolResultError(fmt.Sprintf("The export of %d contacts is %d bytes, over max_bytes (%d). Use save_to_drive='true' or raise max_bytes.", len(persons), len(text), maxBytes)), nil
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("%d contacts:\n%s", len(persons), text)), nil
+		}
+
+		ext, mimeType := ".csv", "text/csv"
+		if format == peoplesvc.FormatVCard {
+			ext, mimeType = ".vcf", "text/vcard"
+		}
+		name := request.GetString("name", "contacts-"+time.Now().Format("2006-01-02")+ext)
+		file, err := driveService.CreateFile(ctx, name, request.GetString("parent_id", ""), text, mimeType)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to save export: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Exported %d contacts to %s (ID: %s)", len(persons), file.Name, file.Id)), nil
+	})
+
+	// Tool: People Import Contacts
+	s.AddTool(mcp.NewTool("people_import_contacts",
+		mcp.WithDescription("Import contacts from CSV (with a header row; Google Contacts and Outlook columns are recognized) or vCard, given inline or as a Drive file. "+
+			"Contacts sharing an email, phone or full name with an existing contact are skipped by default, or merged into it. Try dry_run='true' first."),
+		mcp.WithString("data", mcp.Description("CSV or vCard text (this or file_id is required)")),
+		mcp.WithString("file_id", mcp.Description("Drive file holding the CSV or vCards; a Google Sheet is read as CSV")),
+		mcp.WithString("format", mcp.Description("'csv' or 'vcard' (default: detected from the content)")),
+		mcp.WithString("dedupe", mcp.Description("For contacts matching an existing one: 'skip' them (default), 'merge' their emails, phones and other fields into it, or 'none' to create them anyway")),
+		mcp.WithString("dry_run", mcp.Description("If 'true', only report what would be created, merged and skipped (default: false)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		data := request.GetString("data", "")
+		if fileID := request.GetString("file_id", ""); fileID != "" {
+			if !serviceEnabled(services, "drive") {
+				return mcp.NewToolResultError("file_id needs the drive service, which is not enabled: pass the contacts as data"), nil
+			}
+			if err := registry.ensure("drive"); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to initialize service: %v", err)), nil
+			}
+			file, err := driveService.DownloadFile(ctx, fileID, "text/csv")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to read file: %v", err)), nil
+			}
+			data = string(file.Data)
+		}
+		if strings.TrimSpace(data) == "" {
+			return mcp.NewToolResultError("data or file_id is required"), nil
+		}
+		persons, err := peoplesvc.ParseContacts(data, request.GetString("format", ""))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if len(persons) == 0 {
+			return mcp.NewToolResultError("No contacts found: every row or card lacks a name, email and phone"), nil
+		}
+		dryRun := request.GetString("dry_run", "false") == "true"
+
+		res, err := peopleService.ImportContacts(ctx, persons, request.GetString("dedupe", peoplesvc.DedupeSkip), dryRun)
+		if err != nil {
+			if res == nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to import contacts: %v", err)), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Import stopped after creating %d and merging %d contacts: %v", len(res.Created), len(res.Merged), err)), nil
+		}
+
+		var b strings.Builder
+		if dryRun {
+			b.WriteString("Preview (nothing changed):\n")
+		}
+		fmt.Fprintf(&b, "Read %d contacts: %d created, %d merged into existing contacts, %d skipped as duplicates\n", len(persons), len(res.Created), len(res.Merged), len(res.Skipped))
+		for _, section := range []struct {
+			title    string
+			contacts []peoplesvc.Contact
+		}{{"Created", res.Created}, {"Merged", res.Merged}} {
+			if len(section.contacts) == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "\n%s:\n", section.title)
+			for i, c := range section.contacts {
+				if i == 50 {
+					fmt.Fprintf(&b, "... and %d more\n", len(section.contacts)-i)
+					break
+				}
+				b.WriteString(peoplesvc.FormatContact(c) + "\n")
+			}
+		}
+		if len(res.Skipped) > 0 {
+			b.WriteString("\nSkipped:\n")
+			for _, s := range res.Skipped {
+				b.WriteString("- " + s + "\n")
+			}
+		}
+		return mcp.NewToolResultText(b.String()), nil
+	})
+
+	// Tool: Docs Create Document
+	s.AddTool(mcp.NewTool("docs_create_document",
+		mcp.WithDescription("Create a new Google Doc"),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("title", mcp.Required(), mcp.Description("Document title")),
+		mcp.WithString("initial_text", mcp.Description("Initial text content to insert")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		title, err := request.RequireString("title")
+		if err != nil {
+			return mcp.NewToolResultError("title is required"), nil
+		}
+		initialText := request.GetString("initial_text", "")
+
+		doc, err := docsService.CreateDocument(ctx, title)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to create document: %v", err)), nil
+		}
+
+		if initialText != "" {
+			if err := docsService.InsertText(ctx, doc.DocumentId, initialText); err != nil {
+				// We still return success for creation, but note the error
+				return mcp.NewToolResultText(fmt.Sprintf("Created document: %s (ID: %s)\nWarning: Failed to insert initial text: %v", doc.Title, doc.DocumentId, err)), nil
+			}
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Created document: %s (ID: %s)", doc.Title, doc.DocumentId)), nil
+	})
+
+	// Tool: Docs Read Document
+	s.AddTool(mcp.NewTool("docs_read_document",
+		mcp.WithDescription("Read a Google Doc as Markdown (headings, lists, bold/italic, links and tables preserved), plain text, a heading outline, or its tables. Use section or an index range to read only part of a long document."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("document_id", mcp.Required(), mcp.Description("ID of the document")),
+		mcp.WithString("format", mcp.Description("'markdown' (default), 'text', 'outline' (JSON list of headings with the index range of each section) or 'tables' (JSON list of tables with their cell texts)")),
+		mcp.WithString("section", mcp.Description("Only read the section under the heading matching this text")),
+		mcp.WithNumber("start_index", mcp.Description("Only read content from this document index (see format='outline')")),
+		mcp.WithNumber("end_index", mcp.Description("Only read content before this document index")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		docID, err := request.RequireString("document_id")
+		if err != nil {
+			return mcp.NewToolResultError("document_id is required"), nil
+		}
+
+		doc, err := docsService.GetDocument(ctx, docID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to read document: %v", err)), nil
+		}
+
+		format := request.GetString("format", "markdown")
+		if format == "outline" {
+			jsonBytes, _ := json.MarshalIndent(docssvc.Outline(doc), "", "  ")
+			return mcp.NewToolResultText(fmt.Sprintf("Title: %s\n\n%s", doc.Title, jsonBytes)), nil
+		}
+		if format == "tables" {
+			jsonBytes, _ := json.MarshalIndent(docssvc.Tables(doc), "", "  ")
+			return mcp.NewToolResultText(fmt.Sprintf("Title: %s\n\n%s", doc.Title, jsonBytes)), nil
+		}
+
+		start, end := int64(request.GetInt("start_index", 0)), int64(request.GetInt("end_index", 0))
+		if section := request.GetString("section", ""); section != "" {
+			if start, end, err = docssvc.SectionRange(doc, section); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		var text string
+		switch format {
+		case "markdown":
+			text = docssvc.ToMarkdown(doc, start, end)
+		case "text":
+			text = docssvc.ToPlainText(doc, start, end)
+		default:
+			return mcp.NewToolResultError("format must be 'markdown', 'text', 'outline' or 'tables'"), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Title: %s\n\n%s", doc.Title, text)), nil
+	})
+
+	// Tool: Docs Write Markdown
+	s.AddTool(mcp.NewTool("docs_write_markdown",
+		mcp.WithDescription("Write Markdown into a Google Doc as real formatting: # headings, **bold**, *italic*, `code`, [links](url), nested - and 1. lists, ``` code blocks, > quotes and | tables |. Appends to an existing document or creates a new one."),
+		mcp.WithString("markdown", mcp.Required(), mcp.Description("Markdown content")),
+		mcp.WithString("document_id", mcp.Description("ID of the document to write to; omit to create a new document")),
+		mcp.WithString("title", mcp.Description("Title of the new document when document_id is omitted")),
+		mcp.WithString("mode", mcp.Description("'append' (default) adds to the end; 'replace' removes the existing content first")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		markdown, err := request.RequireString("markdown")
+		if err != nil {
+			return mcp.NewToolResultError("markdown is required"), nil
+		}
+		mode := request.GetString("mode", "append")
+		if mode != "append" && mode != "replace" {
+			return mcp.NewToolResultError("mode must be 'append' or 'replace'"), nil
+		}
+		docID := request.GetString("document_id", "")
+		if docID == "" {
+			title := request.GetString("title", "")
+			if title == "" {
+				return mcp.NewToolResultError("document_id or title is required"), nil
+			}
+			doc, err := docsService.CreateDocument(ctx, title)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to create document: %v", err)), nil
+			}
+			docID = doc.DocumentId
+		}
+		if err := docsService.InsertMarkdown(ctx, docID, markdown, mode == "replace"); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to write markdown (document ID: %s): %v", docID, err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Markdown written to document %s\nLink: https://docs.google.com/document/d/%s/edit", docID, docID)), nil
+	})
+
+	// Tool: Docs Append Text
+	s.AddTool(mcp.NewTool("docs_append_text",
+		mcp.WithDescription("Append text to the end of a Google Doc"),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("document_id", mcp.Required(), mcp.Description("ID of the document")),
+		mcp.WithString("text", mcp.Required(), mcp.Description("Text to append; start with a newline to begin a new paragraph")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		docID, err := request.RequireString("document_id")
+		if err != nil {
+			return mcp.NewToolResultError("document_id is required"), nil
+		}
+		text, err := request.RequireString("text")
+		if err != nil || text == "" {
+			return mcp.NewToolResultError("text is required"), nil
+		}
+		if err := docsService.InsertText(ctx, docID, text); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to append text: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Appended %d characters.", len([]rune(text)))), nil
+	})
+
+	// Tool: Docs Insert Text At
+	s.AddTool(mcp.NewTool("docs_insert_text_at",
+		mcp.WithDescription("Insert text into a Google Doc at an index, or right before/after an existing piece of text"),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("document_id", mcp.Required(), mcp.Description("ID of the document")),
+		mcp.WithString("text", mcp.Required(), mcp.Description("Text to insert")),
+		mcp.WithNumber("index", mcp.Description("Document index to insert at (1 = start of the document)")),
+		mcp.WithString("after_text", mcp.Description("Insert right after the first occurrence of this text, instead of index")),
+		mcp.WithString("before_text", mcp.Description("Insert right before the first occurrence of this text, instead of index")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		docID, err := request.RequireString("document_id")
+		if err != nil {
+			return mcp.NewToolResultError("document_id is required"), nil
+		}
+		text, err := request.RequireString("text")
+		if err != nil || text == "" {
+			return mcp.NewToolResultError("text is required"), nil
+		}
+		index := int64(request.GetInt("index", 0))
+		after, before := request.GetString("after_text", ""), request.GetString("before_text", "")
+		if anchor := after + before; anchor != "" {
+			if after != "" && before != "" {
+				return mcp.NewToolResultError("Provide only one of after_text and before_text"), nil
+			}
+			doc, err := docsService.GetDocument(ctx, docID)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to read document: %v", err)), nil
+			}
+			start, end, ok := docssvc.FindText(doc, anchor, 1)
+			if !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("Text %q not found in the document", anchor)), nil
+			}
+			index = start
+			if after != "" {
+				index = end
+			}
+		}
+		if index < 1 {
+			return mcp.NewToolResultError("index, after_text or before_text is required"), nil
+		}
+		if err := docsService.InsertTextAt(ctx, docID, index, text); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to insert text: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Inserted %d characters at index %d.", len([]rune(text)), index)), nil
+	})
+
+	// Tool: Docs Replace Text
+	s.AddTool(mcp.NewTool("docs_replace_text",
+		mcp.WithDescription("Replace every occurrence of a text in a Google Doc"),
+		mcp.WithString("document_id", mcp.Required(), mcp.Description("ID of the document")),
+		mcp.WithString("find", mcp.Required(), mcp.Description("Text to find")),
+		mcp.WithString("replacement", mcp.Required(), mcp.Description("Replacement text; may be empty to remove the matches")),
+		mcp.WithString("match_case", mcp.Description("'false' for case-insensitive matching (default: true)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		docID, err := request.RequireString("document_id")
+		if err != nil {
+			return mcp.NewToolResultError("document_id is required"), nil
+		}
+		find, err := request.RequireString("find")
+		if err != nil || find == "" {
+			return mcp.NewToolResultError("find is required"), nil
+		}
+		n, err := docsService.ReplaceText(ctx, docID, find, request.GetString("replacement", ""), request.GetString("match_case", "true") == "true")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to replace text: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Replaced %d occurrences.", n)), nil
+	})
+
+	// Tool: Docs Delete Range
+	s.AddTool(mcp.NewTool("docs_delete_range",
+		mcp.WithDescription("Delete content from a Google Doc, either an index range or an occurrence of a text"),
+		mcp.WithString("document_id", mcp.Required(), mcp.Description("ID of the document")),
+		mcp.WithNumber("start_index", mcp.Description("Start index (inclusive)")),
+		mcp.WithNumber("end_index", mcp.Description("End index (exclusive)")),
+		mcp.WithString("text", mcp.Description("Delete this text instead of an index range")),
+		mcp.WithNumber("occurrence", mcp.Description("Which occurrence of text to delete (default 1)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		docID, err := request.RequireString("document_id")
+		if err != nil {
+			return mcp.NewToolResultError("document_id is required"), nil
+		}
+		start, end := int64(request.GetInt("start_index", 0)), int64(request.GetInt("end_index", 0))
+		if text := request.GetString("text", ""); text != "" {
+			doc, err := docsService.GetDocument(ctx, docID)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to read document: %v", err)), nil
+			}
+			var ok bool
+			if start, end, ok = docssvc.FindText(doc, text, request.GetInt("occurrence", 1)); !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("Text %q not found in the document", text)), nil
+			}
+		}
+		if err := docsService.DeleteRange(ctx, docID, start, end); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete range: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Deleted content at indexes %d-%d.", start, end)), nil
+	})
+
+	// Tool: Docs Insert Table
+	s.AddTool(mcp.NewTool("docs_insert_table",
+		mcp.WithDescription("Insert a table into a Google Doc, filled with the given rows (the first row is bolded as a header)"),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("document_id", mcp.Required(), mcp.Description("ID of the document")),
+		mcp.WithString("rows_json", mcp.Required(), mcp.Description("JSON array of rows, each an array of cell texts, e.g. [[\"Task\",\"Status\"],[\"Launch\",\"Done\"]]. Cells may use inline Markdown")),
+		mcp.WithNumber("index", mcp.Description("Document index to insert the table at (default: end of the document)")),
+		mcp.WithString("after_text", mcp.Description("Insert the table after the paragraph containing this text, instead of index")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		docID, err := request.RequireString("document_id")
+		if err != nil {
+			return mcp.NewToolResultError("document_id is required"), nil
+		}
+		rowsJSON, err := request.RequireString("rows_json")
+		if err != nil {
+			return mcp.NewToolResultError("rows_json is required"), nil
+		}
+		var rows [][]string
+		if err := json.Unmarshal([]byte(rowsJSON), &rows); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid rows_json: %v", err)), nil
+		}
+		index := int64(request.GetInt("index", 0))
+		if after := request.GetString("after_text", ""); after != "" {
+			doc, err := docsService.GetDocument(ctx, docID)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to read document: %v", err)), nil
+			}
+			start, _, ok := docssvc.FindText(doc, after, 1)
+			if !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("Text %q not found in the document", after)), nil
+			}
+			if index, err = docssvc.ParagraphEnd(doc, start); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+		n, err := docsService.InsertTable(ctx, docID, rows, index)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to insert table: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Inserted table %d (%d rows).", n, len(rows))), nil
+	})
+
+	// Tool: Docs Update Table Cell
+	s.AddTool(mcp.NewTool("docs_update_table_cell",
+		mcp.WithDescription("Replace the content of a cell in a Google Doc table. Use docs_read_document with format='tables' to find table numbers"),
+		mcp.WithString("document_id", mcp.Required(), mcp.Description("ID of the document")),
+		mcp.WithNumber("table", mcp.Description("1-based number of the table in document order (default 1)")),
+		mcp.WithNumber("row", mcp.Required(), mcp.Description("1-based row (the header row is 1)")),
+		mcp.WithNumber("column", mcp.Required(), mcp.Description("1-based column")),
+		mcp.WithString("text", mcp.Required(), mcp.Description("New cell text; may use inline Markdown and be empty to clear the cell")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		docID, err := request.RequireString("document_id")
+		if err != nil {
+			return mcp.NewToolResultError("document_id is required"), nil
+		}
+		row, col := request.GetInt("row", 0), request.GetInt("column", 0)
+		if row < 1 || col < 1 {
+			return mcp.NewToolResultError("row and column are required"), nil
+		}
+		table := request.GetInt("table", 1)
+		if err := docsService.SetTableCell(ctx, docID, table, row, col, request.GetString("text", "")); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to update table cell: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Updated table %d, row %d, column %d.", table, row, col)), nil
+	})
+
+	// Tool: Docs Apply Style
+	s.AddTool(mcp.NewTool("docs_apply_style",
+		mcp.WithDescription("Style part of a Google Doc: named paragraph styles (headings, title), alignment, bullet or numbered lists, and bold/italic/underline, font, colors and links. "+
+			"Example style: {\"named_style\": \"HEADING_2\"} to make a line a heading, {\"bullets\": \"numbered\"} for a list, {\"bold\": true, \"text_color\": \"#CC0000\"} to highlight text. "+
+			"Paragraph styles and bullets apply to every paragraph the range touches."),
+		mcp.WithString("document_id", mcp.Required(), mcp.Description("ID of the document")),
+		mcp.WithString("style", mcp.Required(), mcp.Description("JSON object with any of: named_style (NORMAL_TEXT, TITLE, SUBTITLE, HEADING_1..HEADING_6), alignment (START/CENTER/END/JUSTIFIED), bullets (bullet/numbered/checkbox/none), bold, italic, underline, strikethrough, font_size (points), font_family, text_color, background_color (#RRGGBB), link (URL, or \"\" to remove)")),
+		mcp.WithNumber("start_index", mcp.Description("Start index (inclusive)")),
+		mcp.WithNumber("end_index", mcp.Description("End index (exclusive)")),
+		mcp.WithString("text", mcp.Description("Style this text instead of an index range")),
+		mcp.WithNumber("occurrence", mcp.Description("Which occurrence of text to style (default 1)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		docID, err := request.RequireString("document_id")
+		if err != nil {
+			return mcp.NewToolResultError("document_id is required"), nil
+		}
+		styleJSON, err := request.RequireString("style")
+		if err != nil {
+			return mcp.NewToolResultError("style is required"), nil
+		}
+		var spec docssvc.StyleSpec
+		dec := json.NewDecoder(strings.NewReader(styleJSON))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&spec); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid style JSON: %v", err)), nil
+		}
+		start, end := int64(request.GetInt("start_index", 0)), int64(request.GetInt("end_index", 0))
+		if text := request.GetString("text", ""); text != "" {
+			doc, err := docsService.GetDocument(ctx, docID)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to read document: %v", err)), nil
+			}
+			var ok bool
+			if start, end, ok = docssvc.FindText(doc, text, request.GetInt("occurrence", 1)); !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("Text %q not found in the document", text)), nil
+			}
+		}
+		n, err := docsService.ApplyStyle(ctx, docID, start, end, spec)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to apply style: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Styled indexes %d-%d (%d update requests applied).", start, end, n)), nil
+	})
+
+	// Tool: Docs Create From Template
+	s.AddTool(mcp.NewTool("docs_create_from_template",
+		mcp.WithDescription("Create a new Google Doc by copying a template and replacing {{placeholder}} markers (in the body, tables, headers and footers) with values. Reports placeholders that were not found or are left unfilled."),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("template_id", mcp.Required(), mcp.Description("ID of the template document")),
+		mcp.WithString("title", mcp.Required(), mcp.Description("Title of the new document")),
+		mcp.WithString("values", mcp.Required(), mcp.Description("JSON object mapping placeholder names to values, e.g. {\"client\": \"Acme\", \"date\": \"2024-05-01\"} replaces {{client}} and {{date}}")),
+		mcp.WithString("parent_id", mcp.Description("Folder ID for the new document (default: same folder as the template)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		templateID, err := request.RequireString("template_id")
+		if err != nil {
+			return mcp.NewToolResultError("template_id is required"), nil
+		}
+		title, err := request.RequireString("title")
+		if err != nil || title == "" {
+			return mcp.NewToolResultError("title is required"), nil
+		}
+		valuesJSON, err := request.RequireString("values")
+		if err != nil {
+			return mcp.NewToolResultError("values is required"), nil
+		}
+		var values map[string]string
+		if err := json.Unmarshal([]byte(valuesJSON), &values); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid values JSON (expected an object of strings): %v", err)), nil
+		}
+
+		file, err := driveService.CopyFile(ctx, templateID, title, request.GetString("parent_id", ""))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to copy template: %v", err)), nil
+		}
+		if file.MimeType != "application/vnd.google-apps.document" {
+			return mcp.NewToolResultError(fmt.Sprintf("Template is not a Google Doc (%s); the copy %s was left as is", file.MimeType, file.Id)), nil
+		}
+		counts, err := docsService.FillPlaceholders(ctx, file.Id, values)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Created %s but failed to fill placeholders: %v", file.Id, err)), nil
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "Created document: %s (ID: %s)\nLink: https://docs.google.com/document/d/%s/edit\n", file.Name, file.Id, file.Id)
+		var missing []string
+		var replaced int64
+		for k, n := range counts {
+			replaced += n
+			if n == 0 {
+				missing = append(missing, k)
+			}
+		}
+		fmt.Fprintf(&b, "Replaced %d placeholder occurrences.\n", replaced)
+		if len(missing) > 0 {
+			slices.Sort(missing)
+			fmt.Fprintf(&b, "Not found in the template: %s\n", strings.Join(missing, ", "))
+		}
+		if doc, err := docsService.GetDocument(ctx, file.Id); err == nil {
+			if left := docssvc.Placeholders(doc); len(left) > 0 {
+				fmt.Fprintf(&b, "Still unfilled in the body: %s\n", strings.Join(left, ", "))
+			}
+		}
+		return mcp.NewToolResultText(b.String()), nil
+	})
+
+	// Tool: Tasks List Task Lists
+	s.AddTool(mcp.NewTool("tasks_list_tasklists",
+		mcp.WithDescription("List the user's Google Tasks task lists. Call this first to get task_list_id for other tasks operations."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("max_results", mcp.Description("Max task lists to return (default 100)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		maxResults := int64(request.GetInt("max_results", 100))
+
+		lists, err := tasksService.ListTaskLists(ctx, maxResults)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list task lists: %v", err)), nil
+		}
+
+		var result string
+		for _, l := range lists {
+			result += fmt.Sprintf("ID: %s | Title: %s\n", l.Id, l.Title)
+		}
+		if len(lists) == 0 {
+			result = "No task lists found."
+		}
+		return mcp.NewToolResultText(result), nil
+	})
+
+	// Tool: Tasks Create Task List
+	s.AddTool(mcp.NewTool("tasks_create_tasklist",
+		mcp.WithDescription("Create a new Google Tasks task list, e.g. for a project checklist. Returns its task_list_id for adding tasks."),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("title", mcp.Required(), mcp.Description("Title of the new task list")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		title, err := request.RequireString("title")
+		if err != nil {
+			return mcp.NewToolResultError("title is required"), nil
+		}
+
+		list, err := tasksService.CreateTaskList(ctx, title)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to create task list: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Created task list: %s (ID: %s)", list.Title, list.Id)), nil
+	})
+
+	// Tool: Tasks Rename Task List
+	s.AddTool(mcp.NewTool("tasks_rename_tasklist",
+		mcp.WithDescription("Rename a Google Tasks task list"),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("task_list_id", mcp.Required(), mcp.Description("ID of the task list")),
+		mcp.WithString("title", mcp.Required(), mcp.Description("New title")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		taskListID, err := request.RequireString("task_list_id")
+		if err != nil {
+			return mcp.NewToolResultError("task_list_id is required"), nil
+		}
+		title, err := request.RequireString("title")
+		if err != nil {
+			return mcp.NewToolResultError("title is required"), nil
+		}
+
+		list, err := tasksService.RenameTaskList(ctx, taskListID, title)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to rename task list: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Renamed task list: %s (ID: %s)", list.Title, list.Id)), nil
+	})
+
+	// Tool: Tasks Delete Task List
+	s.AddTool(mcp.NewTool("tasks_delete_tasklist",
+		mcp.WithDescription("Delete a Google Tasks task list together with all its tasks. This cannot be undone."),
+		mcp.WithString("task_list_id", mcp.Required(), mcp.Description("ID of the task list to delete")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		taskListID, err := request.RequireString("task_list_id")
+		if err != nil {
+			return mcp.NewToolResultError("task_list_id is required"), nil
+		}
+
+		if err := tasksService.DeleteTaskList(ctx, taskListID); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete task list: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Deleted task list: %s", taskListID)), nil
+	})
+
+	// Tool: Tasks List Tasks
+	s.AddTool(mcp.NewTool("tasks_list_tasks",
+		mcp.WithDescription("List tasks in a Google Tasks list in their order, with subtasks indented under their parent and numbered like an outline (2.1 is the first subtask of task 2). Use tasks_list_tasklists first to get task_list_id."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("task_list_id", mcp.Required(), mcp.Description("ID of the task list")),
+		mcp.WithString("show_completed", mcp.Description("Include completed tasks: 'true' or 'false' (default: false to reduce output)")),
+		mcp.WithNumber("max_results", mcp.Description("Max tasks to return (default 20, max 100)")),
+		mcp.WithString("page_token", mcp.Description("Page token from a previous response for the next page")),
+		mcp.WithString("due_min", mcp.Description("Only tasks due on or after this date (YYYY-MM-DD or RFC3339)")),
+		mcp.WithString("due_max", mcp.Description("Only tasks due on or before this date (YYYY-MM-DD or RFC3339)")),
+		mcp.WithString("completed_min", mcp.Description("Only tasks completed on or after this date (YYYY-MM-DD or RFC3339); includes completed tasks")),
+		mcp.WithString("completed_max", mcp.Description("Only tasks completed on or before this date (YYYY-MM-DD or RFC3339); includes completed tasks")),
+		mcp.WithString("overdue_only", mcp.Description("'true' for only open tasks due before today; combine with due_min for e.g. 'overdue this week'")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		taskListID, err := request.RequireString("task_list_id")
+		if err != nil {
+			return mcp.NewToolResultError("task_list_id is required"), nil
+		}
+		showCompleted := request.GetString("show_completed", "false") == "true"
+		maxResults := int64(request.GetInt("max_results", 20))
+
+		resp, err := tasksService.ListTasks(ctx, taskListID, taskssvc.ListTasksOptions{
+			ShowCompleted: showCompleted,
+			MaxResults:    maxResults,
+			PageToken:     request.GetString("page_token", ""),
+			DueMin:        request.GetString("due_min", ""),
+			DueMax:        request.GetString("due_max", ""),
+			CompletedMin:  request.GetString("completed_min", ""),
+			CompletedMax:  request.GetString("completed_max", ""),
+			OverdueOnly:   request.GetString("overdue_only", "false") == "true",
+		})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list tasks: %v", err)), nil
+		}
+		taskList := resp.Items
+
+		var result string
+		for _, o := range taskssvc.Ordered(taskList) {
+			t := o.Task
+			status := t.Status
+			if status == "" {
+				status = "needsAction"
+			}
+			due := ""
+			if t.Due != "" {
+				due = " | Due: " + t.Due
+			}
+			parent := ""
+			if o.Depth == 0 && t.Parent != "" {
+				parent = " | Subtask of: " + t.Parent
+			}
+			result += fmt.Sprintf("%s%s. [%s] %s | Status: %s%s%s\n", strings.Repeat("  ", o.Depth), o.Number, t.Id, t.Title, status, due, parent)
+		}
+		if len(taskList) == 0 {
+			result = "No tasks found."
+		} else if resp.NextPageToken != "" {
+			result += fmt.Sprintf("\nnext_page_token: %s", resp.NextPageToken)
+		}
+		return mcp.NewToolResultText(result), nil
+	})
+
+	// Tool: Tasks Insert Task
+	s.AddTool(mcp.NewTool("tasks_insert_task",
+		mcp.WithDescription("Create a new task in a Google Tasks list"),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("task_list_id", mcp.Required(), mcp.Description("ID of the task list")),
+		mcp.WithString("title", mcp.Required(), mcp.Description("Task title")),
+		mcp.WithString("notes", mcp.Description("Optional notes")),
+		mcp.WithString("due", mcp.Description("Due date (RFC3339 date, e.g. 2025-02-01)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		taskListID, err := request.RequireString("task_list_id")
+		if err != nil {
+			return mcp.NewToolResultError("task_list_id is required"), nil
+		}
+		title, err := request.RequireString("title")
+		if err != nil {
+			return mcp.NewToolResultError("title is required"), nil
+		}
+		notes := request.GetString("notes", "")
+		due := request.GetString("due", "")
+
+		task, err := tasksService.InsertTask(ctx, taskListID, title, notes, due)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to insert task: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Created task: %s (ID: %s)", task.Title, task.Id)), nil
+	})
+
+	// Tool: Tasks Update Task
+	s.AddTool(mcp.NewTool("tasks_update_task",
+		mcp.WithDescription("Update an existing task (title, notes, due date, or status)"),
+		mcp.WithString("task_list_id", mcp.Required(), mcp.Description("ID of the task list")),
+		mcp.WithString("task_id", mcp.Required(), mcp.Description("ID of the task")),
+		mcp.WithString("title", mcp.Description("New title (optional)")),
+		mcp.WithString("notes", mcp.Description("New notes (optional)")),
+		mcp.WithString("due", mcp.Description("New due date RFC3339 (optional)")),
+		mcp.WithString("status", mcp.Description("'needsAction' or 'completed' (optional)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		taskListID, err := request.RequireString("task_list_id")
+		if err != nil {
+			return mcp.NewToolResultError("task_list_id is required"), nil
+		}
+		taskID, err := request.RequireString("task_id")
+		if err != nil {
+			return mcp.NewToolResultError("task_id is required"), nil
+		}
+		title := request.GetString("title", "")
+		notes := request.GetString("notes", "")
+		due := request.GetString("due", "")
+		status := request.GetString("status", "")
+
+		in := taskssvc.UpdateTaskInput{}
+		if title != "" {
+			in.Title = &title
+		}
+		if notes != "" {
+			in.Notes = &notes
+		}
+		if due != "" {
+			in.Due = &due
+		}
+		if status != "" {
+			in.Status = &status
+		}
+
+		task, err := tasksService.UpdateTask(ctx, taskListID, taskID, in)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to update task: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Updated task: %s (ID: %s)", task.Title, task.Id)), nil
+	})
+
+	// Tool: Tasks Move Task
+	s.AddTool(mcp.NewTool("tasks_move_task",
+		mcp.WithDescription("Move a task within its list: make it a subtask of another task (parent), place it after a sibling (previous), or move it to another list. Without parent the task becomes top-level; without previous it becomes the first among its siblings."),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("task_list_id", mcp.Required(), mcp.Description("ID of the task list")),
+		mcp.WithString("task_id", mcp.Required(), mcp.Description("ID of the task to move")),
+		mcp.WithString("parent", mcp.Description("ID of the task to nest it under (optional; omit for a top-level task)")),
+		mcp.WithString("previous", mcp.Description("ID of the sibling to place it after (optional; omit to make it first)")),
+		mcp.WithString("destination_task_list_id", mcp.Description("ID of another task list to move it to (optional)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		taskListID, err := request.RequireString("task_list_id")
+		if err != nil {
+			return mcp.NewToolResultError("task_list_id is required"), nil
+		}
+		taskID, err := request.RequireString("task_id")
+		if err != nil {
+			return mcp.NewToolResultError("task_id is required"), nil
+		}
+
+		task, err := tasksService.MoveTask(ctx, taskListID, taskID, taskssvc.MoveTaskOptions{
+			Parent:              request.GetString("parent", ""),
+			Previous:            request.GetString("previous", ""),
+			DestinationTaskList: request.GetString("destination_task_list_id", ""),
+		})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to move task: %v", err)), nil
+		}
+		where := "top level"
+		if task.Parent != "" {
+			where = "subtask of " + task.Parent
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Moved task: %s (ID: %s) | %s | Position: %s", task.Title, task.Id, where, task.Position)), nil
+	})
+
+	// Tool: Tasks Complete Tasks
+	s.AddTool(mcp.NewTool("tasks_complete_tasks",
+		mcp.WithDescription("Mark many tasks of a list as completed in one call. With repeat, each completed task gets a new open copy due one interval after its due date (or after today), since Google Tasks has no recurrence in its API. Reports per-ID failures without aborting the rest."),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("task_list_id", mcp.Required(), mcp.Description("ID of the task list")),
+		mcp.WithString("task_ids", mcp.Required(), mcp.Description("Comma-separated IDs of the tasks to complete")),
+		mcp.WithString("repeat", mcp.Description("'daily', 'weekly', 'monthly' or 'yearly' to create the next occurrence of each task (optional)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		taskListID, err := request.RequireString("task_list_id")
+		if err != nil {
+			return mcp.NewToolResultError("task_list_id is required"), nil
+		}
+		idsStr, err := request.RequireString("task_ids")
+		if err != nil {
+			return mcp.NewToolResultError("task_ids is required"), nil
+		}
+		ids := splitList(idsStr)
+		if len(ids) == 0 {
+			return mcp.NewToolResultError("task_ids is required"), nil
+		}
+
+		res, err := tasksService.CompleteTasks(ctx, taskListID, ids, request.GetString("repeat", ""))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to complete tasks: %v", err)), nil
+		}
+		result := fmt.Sprintf("Completed %d of %d tasks.\n", len(res.Succeeded), len(ids))
+		for _, t := range res.Next {
+			result += fmt.Sprintf("  next: %s (ID: %s) | Due: %s\n", t.Title, t.Id, t.Due)
+		}
+		for _, f := range res.Failed {
+			result += fmt.Sprintf("  failed %s: %s\n", f.ID, f.Error)
+		}
+		return mcp.NewToolResultText(result), nil
+	})
+
+	// Tool: Tasks Clear Completed
+	s.AddTool(mcp.NewTool("tasks_clear_completed",
+		mcp.WithDescription("Clear all completed tasks from a Google Tasks list. They are hidden rather than deleted: tasks_list_tasks still returns them with show_completed=true."),
+		mcp.WithString("task_list_id", mcp.Required(), mcp.Description("ID of the task list")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		taskListID, err := request.RequireString("task_list_id")
+		if err != nil {
+			return mcp.NewToolResultError("task_list_id is required"), nil
+		}
+
+		if err := tasksService.ClearCompleted(ctx, taskListID); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to clear completed tasks: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Cleared completed tasks from list: %s", taskListID)), nil
+	})
+
+	// Tool: Tasks Delete Task
+	s.AddTool(mcp.NewTool("tasks_delete_task",
+		mcp.WithDescription("Delete a task from a Google Tasks list"),
+		mcp.WithString("task_list_id", mcp.Required(), mcp.Description("ID of the task list")),
+		mcp.WithString("task_id", mcp.Required(), mcp.Description("ID of the task to delete")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		taskListID, err := request.RequireString("task_list_id")
+		if err != nil {
+			return mcp.NewToolResultError("task_list_id is required"), nil
+		}
+		taskID, err := request.RequireString("task_id")
+		if err != nil {
+			return mcp.NewToolResultError("task_id is required"), nil
+		}
+
+		if err := tasksService.DeleteTask(ctx, taskListID, taskID); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete task: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Deleted task: %s", taskID)), nil
+	})
+
+	// Tool: Create Task From Email
+	s.AddTool(mcp.NewTool("create_task_from_email",
+		mcp.WithDescription("Turn a Gmail thread into a Google Task: the task is titled after the thread's subject and its notes hold the sender, a summary and a link back to the thread. Optionally also blocks time for it in Google Calendar."),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("thread_id", mcp.Required(), mcp.Description("ID of the Gmail thread")),
+		mcp.WithString("task_list_id", mcp.Description("ID of the task list (default: @default, the user's default list)")),
+		mcp.WithString("title", mcp.Description("Task title (default: the thread's subject)")),
+		mcp.WithString("summary", mcp.Description("Summary for the task notes (default: the start of the latest message)")),
+		mcp.WithString("due", mcp.Description("Due date (RFC3339 date, e.g. 2025-02-01) (optional)")),
+		mcp.WithString("block_start", mcp.Description("Start of a calendar block to work on the task, RFC3339 (optional; omit for no block)")),
+		mcp.WithNumber("block_minutes", mcp.Description("Length of the calendar block in minutes (default 30)")),
+		mcp.WithString("calendar_id", mcp.Description("Calendar for the block (default: primary)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		threadID, err := request.RequireString("thread_id")
+		if err != nil {
+			return mcp.NewToolResultError("thread_id is required"), nil
+		}
+		var blockStart time.Time
+		if start := request.GetString("block_start", ""); start != "" {
+			if blockStart, err = time.Parse(time.RFC3339, start); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid block_start: %v", err)), nil
+			}
+			if !serviceEnabled(services, "calendar") {
+				return mcp.NewToolResultError("block_start needs the calendar service, which is not enabled"), nil
+			}
+			if err := registry.ensure("calendar"); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to initialize service: %v", err)), nil
+			}
+		}
+		blockMinutes := request.GetInt("block_minutes", 30)
+		if blockMinutes <= 0 {
+			return mcp.NewToolResultError("block_minutes must be positive"), nil
+		}
+
+		thread, err := gmailService.GetThread(ctx, threadID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get thread: %v", err)), nil
+		}
+		digest := gmailsvc.DigestThread(thread, request.GetString("summary", ""))
+		title := request.GetString("title", digest.Subject)
+		if title == "" {
+			title = "Follow up on email from " + digest.From
+		}
+
+		task, err := tasksService.InsertTask(ctx, request.GetString("task_list_id", "@default"), title, digest.Notes(), request.GetString("due", ""))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to insert task: %v", err)), nil
+		}
+		result := fmt.Sprintf("Created task: %s (ID: %s)", task.Title, task.Id)
+		if task.WebViewLink != "" {
+			result += "\nTask link: " + task.WebViewLink
+		}
+		if blockStart.IsZero() {
+			return mcp.NewToolResultText(result), nil
+		}
+
+		description := digest.Notes()
+		if task.WebViewLink != "" {
+			description += "\nTask: " + task.WebViewLink
+		}
+		end := blockStart.Add(time.Duration(blockMinutes) * time.Minute)
+		event, err := calendarService.CreateEvent(ctx, request.GetString("calendar_id", "primary"), title, description, blockStart.Format(time.RFC3339), end.Format(time.RFC3339), nil, nil)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("%s\nFailed to create the calendar block: %v", result, err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("%s\nBlocked %s to %s: %s (ID: %s)", result, blockStart.Format(time.RFC3339), end.Format(time.RFC3339), event.HtmlLink, event.Id)), nil
+	})
+
+	// Tool: Mail Merge
+	s.AddTool(mcp.NewTool("mail_merge",
+		mcp.WithDescription("Mail merge from a Google Sheet: for each row of a range whose first row names the columns (one of them the recipient email), fill the {{column}} placeholders of a subject and body and create a Gmail draft, or send the email. Each row's outcome is written to a status column; rows already drafted or sent are skipped, so running again continues where a run stopped. Try dry_run='true' first."),
+		mcp.WithString("spreadsheet_id", mcp.Required(), mcp.Description("ID of the spreadsheet")),
+		mcp.WithString("range", mcp.Required(), mcp.Description("A1 range including the header row, e.g. 'Contacts!A1:E200'")),
+		mcp.WithString("subject", mcp.Required(), mcp.Description("Subject template, e.g. 'Invitation for {{name}}'")),
+		mcp.WithString("body", mcp.Required(), mcp.Description("Plain text body template with {{column}} placeholders")),
+		mcp.WithString("html_body", mcp.Description("HTML body template (optional; values are HTML-escaped)")),
+		mcp.WithString("email_column", mcp.Description("Column with the recipient address (default: email)")),
+		mcp.WithString("status_column", mcp.Description("Column to write the outcome to, added after the last column if missing (default: merge_status)")),
+		mcp.WithString("mode", mcp.Description("'draft' to create drafts for review or 'send' to send the emails (default: draft)")),
+		mcp.WithNumber("max_emails", mcp.Description("Max rows to process in this run (default 50); run again for the rest")),
+		mcp.WithNumber("delay_seconds", mcp.Description("Seconds to wait between sent emails (default 2)")),
+		mcp.WithString("dry_run", mcp.Description("If 'true', only render the emails, without drafting, sending or writing statuses (default: false)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		spreadsheetID, err := request.RequireString("spreadsheet_id")
+		if err != nil {
+			return mcp.NewToolResultError("spreadsheet_id is required"), nil
+		}
+		rangeName, err := request.RequireString("range")
+		if err != nil {
+			return mcp.NewToolResultError("range is required"), nil
+		}
+		subject, err := request.RequireString("subject")
+		if err != nil {
+			return mcp.NewToolResultError("subject is required"), nil
+		}
+		body, err := request.RequireString("body")
+		if err != nil {
+			return mcp.NewToolResultError("body is required"), nil
+		}
+		mode := request.GetString("mode", "draft")
+		if mode != "draft" && mode != "send" {
+			return mcp.NewToolResultError("mode must be 'draft' or 'send'"), nil
+		}
+		opts := mailmerge.Options{
+			SpreadsheetID: spreadsheetID,
+			Range:         rangeName,
+			Subject:       subject,
+			Body:          body,
+			HTMLBody:      request.GetString("html_body", ""),
+			EmailColumn:   request.GetString("email_column", ""),
+			StatusColumn:  request.GetString("status_column", ""),
+			Send:          mode == "send",
+			MaxEmails:     request.GetInt("max_emails", mailmerge.DefaultMaxEmails),
+			Delay:         time.Duration(request.GetFloat("delay_seconds", 2) * float64(time.Second)),
+			DryRun:        request.GetString("dry_run", "false") == "true",
+		}
+
+		res, err := mailmerge.Run(ctx, sheetsService, gmailService, opts)
+		if res == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to run mail merge: %v", err)), nil
+		}
+		var b strings.Builder
+		switch {
+		case opts.DryRun:
+			action := "drafted"
+			if opts.Send {
+				action = "sent"
+			}
+			fmt.Fprintf(&b, "Dry run: %d emails would be %s.\n", len(res.Done), action)
+			for i, r := range res.Done {
+				if i == 3 {
+					fmt.Fprintf(&b, "... and %d more\n", len(res.Done)-i)
+					break
+				}
+				fmt.Fprintf(&b, "\n--- Row %d: %s\nSubject: %s\n\n%s\n", r.Row, r.To, r.Subject, r.Body)
+			}
+		case opts.Send:
+			fmt.Fprintf(&b, "Sent %d emails.\n", len(res.Done))
+		default:
+			fmt.Fprintf(&b, "Created %d drafts: review and send them from Gmail.\n", len(res.Done))
+		}
+		if res.Skipped > 0 {
+			fmt.Fprintf(&b, "Skipped %d rows already processed.\n", res.Skipped)
+		}
+		if res.NoEmail > 0 {
+			fmt.Fprintf(&b, "Skipped %d rows without an email address.\n", res.NoEmail)
+		}
+		for _, r := range res.Failed {
+			fmt.Fprintf(&b, "  failed row %d (%s): %s\n", r.Row, r.To, strings.TrimPrefix(r.Status, "failed: "))
+		}
+		if res.Remaining > 0 {
+			fmt.Fprintf(&b, "%d rows remain: run again to continue.\n", res.Remaining)
+		}
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("%sMail merge stopped: %v", b.String(), err)), nil
+		}
+		return mcp.NewToolResultText(b.String()), nil
+	})
+
+	// Tool: Keep List Notes
+	s.AddTool(mcp.NewTool("keep_list_notes",
+		mcp.WithDescription("List Google Keep notes. Use note name from results for keep_get_note and keep_delete_note."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("page_size", mcp.Description("Max notes per page (default 20, 0 = server default)")),
+		mcp.WithString("page_token", mcp.Description("Page token from previous list response for next page")),
+		mcp.WithString("filter", mcp.Description("Filter (e.g. 'trashed = false' to exclude trashed). AIP-160 syntax.")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		pageSize := int64(request.GetInt("page_size", 20))
+		pageToken := request.GetString("page_token", "")
+		filter := request.GetString("filter", "trashed = false")
+
+		resp, err := keepService.ListNotes(ctx, keepsvc.ListNotesOptions{
+			PageSize:  pageSize,
+			PageToken: pageToken,
+			Filter:    filter,
+		})
+		if err != nil {
+			if isUnavailableError(err) {
+				return mcp.NewToolResultError(keepUnavailableMessage), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list notes: %v", err)), nil
+		}
+
+		var result string
+		for _, n := range resp.Notes {
+			result += fmt.Sprintf("[%s] %s (updated: %s)\n", n.Name, n.Title, n.UpdateTime)
+		}
+		if len(resp.Notes) == 0 {
+			result = "No notes found."
+		} else if resp.NextPageToken != "" {
+			result += fmt.Sprintf("\nnext_page_token: %s", resp.NextPageToken)
+		}
+		return mcp.NewToolResultText(result), nil
+	})
+
+	// Tool: Keep Search Notes
+	s.AddTool(mcp.NewTool("keep_search_notes",
+		mcp.WithDescription("Search Google Keep notes by words in their title, text or checklist items (case-insensitive, all words must match). Notes are scanned page by page; pass next_page_token to keep scanning."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("query", mcp.Required(), mcp.Description("Words to look for")),
+		mcp.WithNumber("limit", mcp.Description("Max matching notes to return (default 20)")),
+		mcp.WithString("include_trashed", mcp.Description("If 'true', also search trashed notes (default: false)")),
+		mcp.WithString("page_token", mcp.Description("next_page_token from a previous search to continue scanning")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		query, err := request.RequireString("query")
+		if err != nil || strings.TrimSpace(query) == "" {
+			return mcp.NewToolResultError("query is required"), nil
+		}
+		filter := "trashed = false"
+		if request.GetString("include_trashed", "false") == "true" {
+			filter = ""
+		}
+
+		res, err := keepService.SearchNotes(ctx, keepsvc.SearchNotesOptions{
+			Query:     query,
+			Filter:    filter,
+			Limit:     request.GetInt("limit", 20),
+			PageToken: request.GetString("page_token", ""),
+		})
+		if err != nil {
+			if isUnavailableError(err) {
+				return mcp.NewToolResultError(keepUnavailableMessage), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to search notes: %v", err)), nil
+		}
+
+		var result string
+		for _, n := range res.Notes {
+			result += fmt.Sprintf("[%s] %s (updated: %s)\n", n.Name, n.Title, n.UpdateTime)
+		}
+		if len(res.Notes) == 0 {
+			result = fmt.Sprintf("No notes matching %q (scanned %d).", query, res.Scanned)
+		}
+		if res.NextPageToken != "" {
+			result += fmt.Sprintf("\nScanned %d notes; more remain. next_page_token: %s", res.Scanned, res.NextPageToken)
+		}
+		return mcp.NewToolResultText(result), nil
+	})
+
+	// Tool: Keep Create Note
+	s.AddTool(mcp.NewTool("keep_create_note",
+		mcp.WithDescription("Create a new Google Keep note. Provide title and either body_text (plain note) or list_items_json (checklist). List items: [{\"text\":\"item 1\",\"checked\":false},{\"text\":\"item 2\",\"checked\":true}]"),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("title", mcp.Required(), mcp.Description("Note title (max 1000 chars)")),
+		mcp.WithString("body_text", mcp.Description("Plain text body for the note (max 20000 chars). Omit if using list_items_json.")),
+		mcp.WithString("list_items_json", mcp.Description("JSON array of list items: [{\"text\":\"...\",\"checked\":false}]. Omit for text-only note.")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		title, err := request.RequireString("title")
+		if err != nil {
+			return mcp.NewToolResultError("title is required"), nil
+		}
+		bodyText := request.GetString("body_text", "")
+		listItemsJSON := request.GetString("list_items_json", "")
+
+		var listItems []*keepapi.ListItem
+		if listItemsJSON != "" {
+			var decoded []struct {
+				Text    string `json:"text"`
+				Checked bool   `json:"checked"`
+			}
+			if err := json.Unmarshal([]byte(listItemsJSON), &decoded); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid list_items_json: %v", err)), nil
+			}
+			for _, d := range decoded {
+				listItems = append(listItems, &keepapi.ListItem{
+					Text:    &keepapi.TextContent{Text: d.Text},
+					Checked: d.Checked,
+				})
+			}
+		}
+
+		note, err := keepService.CreateNote(ctx, title, bodyText, listItems)
+		if err != nil {
+			if isUnavailableError(err) {
+				return mcp.NewToolResultError(keepUnavailableMessage), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to create note: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Created note: %s (name: %s)", note.Title, note.Name)), nil
+	})
+
+	// Tool: Keep Get Note
+	s.AddTool(mcp.NewTool("keep_get_note",
+		mcp.WithDescription("Get a Google Keep note by name or id. Returns title, body text or list items, and metadata."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Note name (e.g. notes/xyz) or note id (xyz)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		name, err := request.RequireString("name")
+		if err != nil {
+			return mcp.NewToolResultError("name is required"), nil
+		}
+
+		note, err := keepService.GetNote(ctx, name)
+		if err != nil {
+			if isUnavailableError(err) {
+				return mcp.NewToolResultError(keepUnavailableMessage), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get note: %v", err)), nil
+		}
+
+		result := fmt.Sprintf("name: %s\ntitle: %s\ncreateTime: %s\nupdateTime: %s\ntrashed: %v\n", note.Name, note.Title, note.CreateTime, note.UpdateTime, note.Trashed)
+		if note.Body != nil {
+			if note.Body.Text != nil {
+				result += fmt.Sprintf("body (text): %s\n", note.Body.Text.Text)
+			}
+			if note.Body.List != nil && len(note.Body.List.ListItems) > 0 {
+				result += "list items:\n"
+				for i, li := range note.Body.List.ListItems {
+					text := ""
+					if li.Text != nil {
+						text = li.Text.Text
+					}
+					checked := ""
+					if li.Checked {
+						checked = " [checked]"
+					}
+					result += fmt.Sprintf("  %d. %s%s\n", i+1, text, checked)
+				}
+			}
+		}
+		if len(note.Attachments) > 0 {
+			result += "attachments:\n"
+			for _, a := range note.Attachments {
+				result += fmt.Sprintf("  %s (%s)\n", a.Name, strings.Join(a.MimeType, ", "))
+			}
+		}
+		return mcp.NewToolResultText(result), nil
+	})
+
+	// Tool: Keep Download Attachment
+	s.AddTool(mcp.NewTool("keep_download_attachment",
+		mcp.WithDescription("Download an attachment (image, drawing, audio) of a Google Keep note. Returns base64 content (size-limited), or saves it to Google Drive with save_to_drive='true'."),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Attachment name from keep_get_note (notes/<note>/attachments/<attachment>)")),
+		mcp.WithString("mime_type", mcp.Description("Format to download, one of those listed by keep_get_note (default: the first)")),
+		mcp.WithString("save_to_drive", mcp.Description("If 'true', upload the attachment to Drive instead of returning it (default: false)")),
+		mcp.WithString("parent_id", mcp.Description("Drive folder ID to save into when save_to_drive is 'true' (optional)")),
+		mcp.WithString("filename", mcp.Description("File name to use in Drive (default: derived from the attachment name)")),
+		mcp.WithNumber("max_bytes", mcp.Description("Max attachment size to return as base64 (default 1048576)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		name, err := request.RequireString("name")
+		if err != nil {
+			return mcp.NewToolResultError("name is required"), nil
+		}
+		maxBytes := request.GetInt("max_bytes", 1024*1024)
+
+		data, mimeType, err := keepService.DownloadAttachment(ctx, name, request.GetString("mime_type", ""))
+		if err != nil {
+			if isUnavailableError(err) {
+				return mcp.NewToolResultError(keepUnavailableMessage), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to download attachment: %v", err)), nil
+		}
+
+		if request.GetString("save_to_drive", "false") == "true" {
+			filename := request.GetString("filename", "")
+			if filename == "" {
+				filename = "keep-" + filepath.Base(name)
+				if exts, _ := mime.ExtensionsByType(mimeType); len(exts) > 0 {
+					filename += exts[0]
+				}
+			}
+			file, err := driveService.CreateFile(ctx, filename, request.GetString("parent_id", ""), string(data), mimeType)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to save attachment to Drive: %v", err)), nil
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Saved attachment to Drive: %s (ID: %s, %d bytes)", file.Name, file.Id, len(data))), nil
+		}
+
+		if len(data) > maxBytes {
+			return mcp.NewToolResultError(fmt.Sprintf("Attachment is %d bytes, larger than max_bytes (%d). Use save_to_drive='true' or raise max_bytes.", len(data), maxBytes)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("MimeType: %s\nSize: %d bytes\nBase64:\n%s", mimeType, len(data), base64.StdEncoding.EncodeToString(data))), nil
+	})
+
+	// Tool: Keep Update Note (edit)
+	s.AddTool(mcp.NewTool("keep_update_note",
+		mcp.WithDescription("Edit a Google Keep note. API has no native update; replaces note with a new one (new id) and deletes the old. Provide name and any of: title, body_text, list_items_json to change."),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Note name (e.g. notes/xyz) or note id to edit")),
+		mcp.WithString("title", mcp.Description("New title (optional)")),
+		mcp.WithString("body_text", mcp.Description("New plain text body (optional; replaces list if set)")),
+		mcp.WithString("list_items_json", mcp.Description("New list items JSON (optional; e.g. [{\"text\":\"...\",\"checked\":false}]. Replaces text body if set.)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		name, err := request.RequireString("name")
+		if err != nil {
+			return mcp.NewToolResultError("name is required"), nil
+		}
+		title := request.GetString("title", "")
+		bodyText := request.GetString("body_text", "")
+		listItemsJSON := request.GetString("list_items_json", "")
+
+		var listItems []*keepapi.ListItem
+		if listItemsJSON != "" {
+			var decoded []struct {
+				Text    string `json:"text"`
+				Checked bool   `json:"checked"`
+			}
+			if err := json.Unmarshal([]byte(listItemsJSON), &decoded); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid list_items_json: %v", err)), nil
+			}
+			for _, d := range decoded {
+				listItems = append(listItems, &keepapi.ListItem{
+					Text:    &keepapi.TextContent{Text: d.Text},
+					Checked: d.Checked,
+				})
+			}
+		}
+
+		in := keepsvc.UpdateNoteInput{Title: title, BodyText: bodyText, ListItems: listItems}
+		note, err := keepService.UpdateNote(ctx, name, in)
+		if err != nil {
+			if isUnavailableError(err) {
+				return mcp.NewToolResultError(keepUnavailableMessage), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to update note: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Updated note (new name: %s): %s", note.Name, note.Title)), nil
+	})
+
+	// Tool: Keep Delete Note
+	s.AddTool(mcp.NewTool("keep_delete_note",
+		mcp.WithDescription("Delete a Google Keep note permanently. Caller must be owner."),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Note name (e.g. notes/xyz) or note id")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		name, err := request.RequireString("name")
+		if err != nil {
+			return mcp.NewToolResultError("name is required"), nil
+		}
+
+		if err := keepService.DeleteNote(ctx, name); err != nil {
+			if isUnavailableError(err) {
+				return mcp.NewToolResultError(keepUnavailableMessage), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete note: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Deleted note: %s", name)), nil
+	})
+
+	// Tool: Chat List Spaces
+	s.AddTool(mcp.NewTool("chat_list_spaces",
+		mcp.WithDescription("List Google Chat spaces, group chats and direct messages the user belongs to. Use the space name from results for chat_read_messages and chat_send_message."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("space_type", mcp.Description("Only list this type: SPACE, GROUP_CHAT or DIRECT_MESSAGE (default: all)")),
+		mcp.WithNumber("page_size", mcp.Description("Max spaces per page (default 50)")),
+		mcp.WithString("page_token", mcp.Description("Page token from previous list response for next page")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		resp, err := chatService.ListSpaces(ctx, request.GetString("space_type", ""), int64(request.GetInt("page_size", 50)), request.GetString("page_token", ""))
+		if err != nil {
+			if isUnavailableError(err) {
+				return mcp.NewToolResultError(chatUnavailableMessage), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list spaces: %v", err)), nil
+		}
+
+		var result string
+		for _, sp := range resp.Spaces {
+			name := sp.DisplayName
+			if name == "" {
+				name = "(direct message)"
+			}
+			result += fmt.Sprintf("[%s] %s (%s)\n", sp.Name, name, sp.SpaceType)
+		}
+		if len(resp.Spaces) == 0 {
+			result = "No spaces found."
+		} else if resp.NextPageToken != "" {
+			result += fmt.Sprintf("\nnext_page_token: %s", resp.NextPageToken)
+		}
+		return mcp.NewToolResultText(result), nil
+	})
+
+	// Tool: Chat Read Messages
+	s.AddTool(mcp.NewTool("chat_read_messages",
+		mcp.WithDescription("Read the most recent messages in a Google Chat space, oldest first"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("space", mcp.Required(), mcp.Description("Space name (spaces/AAAA...), ID or Chat URL")),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of messages (default 25)")),
+		mcp.WithString("since", mcp.Description("Only messages created after this time (RFC3339, e.g. 2024-05-01T09:00:00Z)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		space, err := request.RequireString("space")
+		if err != nil {
+			return mcp.NewToolResultError("space is required"), nil
+		}
+		var since time.Time
+		if v := request.GetString("since", ""); v != "" {
+			if since, err = time.Parse(time.RFC3339, v); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid since (expected RFC3339): %v", err)), nil
+			}
+		}
+		msgs, err := chatService.RecentMessages(ctx, space, int64(request.GetInt("limit", 25)), since)
+		if err != nil {
+			if isUnavailableError(err) {
+				return mcp.NewToolResultError(chatUnavailableMessage), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to read messages: %v", err)), nil
+		}
+		if len(msgs) == 0 {
+			return mcp.NewToolResultText("No messages found."), nil
+		}
+		jsonBytes, _ := json.MarshalIndent(msgs, "", "  ")
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	})
+
+	// Tool: Chat Send Message
+	s.AddTool(mcp.NewTool("chat_send_message",
+		mcp.WithDescription("Post a message to a Google Chat space, optionally as a reply in a thread or with a card. Text supports Chat formatting (*bold*, _italic_, `code`, <url|label>)."),
+		mcp.WithString("space", mcp.Required(), mcp.Description("Space name (spaces/AAAA...), ID or Chat URL")),
+		mcp.WithString("text", mcp.Description("Message text")),
+		mcp.WithString("thread", mcp.Description("Thread name (spaces/.../threads/...) from chat_read_messages, or a thread key, to reply in that thread")),
+		mcp.WithString("card_json", mcp.Description("Card as a JSON cardsV2 card object, e.g. {\"header\": {\"title\": \"Build failed\"}, \"sections\": [{\"widgets\": [{\"textParagraph\": {\"text\": \"...\"}}]}]}. Cards are only accepted when authenticated as a Chat app")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		space, err := request.RequireString("space")
+		if err != nil {
+			return mcp.NewToolResultError("space is required"), nil
+		}
+		var card *chatapi.GoogleAppsCardV1Card
+		if v := request.GetString("card_json", ""); v != "" {
+			card = &chatapi.GoogleAppsCardV1Card{}
+			if err := json.Unmarshal([]byte(v), card); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid card_json: %v", err)), nil
+			}
+		}
+		msg, err := chatService.SendMessage(ctx, space, request.GetString("text", ""), request.GetString("thread", ""), card)
+		if err != nil {
+			if isUnavailableError(err) {
+				return mcp.NewToolResultError(chatUnavailableMessage), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to send message: %v", err)), nil
+		}
+		thread := ""
+		if msg.Thread != nil {
+			thread = msg.Thread.Name
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Sent message: %s (thread: %s)", msg.Name, thread)), nil
+	})
+
+	// Tool: Memory Set
+	s.AddTool(mcp.NewTool("memory_set",
+		mcp.WithDescription("Remember a value under a key, across sessions: entries are kept in a \""+memorysvc.SpreadsheetTitle+"\" spreadsheet in the account's Drive, created on first use. Replaces any previous value of the key."),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("key", mcp.Required(), mcp.Description("Key, e.g. 'project/deadline'; use prefixes to group entries for memory_list")),
+		mcp.WithString("value", mcp.Required(), mcp.Description("Value to store, as text (up to 50,000 characters; JSON is fine)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		key, err := request.RequireString("key")
+		if err != nil {
+			return mcp.NewToolResultError("key is required"), nil
+		}
+		value, err := request.RequireString("value")
+		if err != nil {
+			return mcp.NewToolResultError("value is required"), nil
+		}
+
+		created, err := memoryService.Set(ctx, key, value)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to set memory entry: %v", err)), nil
+		}
+		if created {
+			return mcp.NewToolResultText(fmt.Sprintf("Stored new entry: %s", key)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Updated entry: %s", key)), nil
+	})
+
+	// Tool: Memory Get
+	s.AddTool(mcp.NewTool("memory_get",
+		mcp.WithDescription("Recall the value stored under a key with memory_set"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("key", mcp.Required(), mcp.Description("Key of the entry")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		key, err := request.RequireString("key")
+		if err != nil {
+			return mcp.NewToolResultError("key is required"), nil
+		}
+
+		entry, err := memoryService.Get(ctx, key)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get memory entry: %v", err)), nil
+		}
+		if entry == nil {
+			return mcp.NewToolResultText(fmt.Sprintf("No entry for key: %s", key)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("%s (updated %s):\n%s", entry.Key, entry.Updated, entry.Value)), nil
+	})
+
+	// Tool: Memory List
+	s.AddTool(mcp.NewTool("memory_list",
+		mcp.WithDescription("List the entries stored with memory_set, sorted by key, with the start of their values"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("prefix", mcp.Description("Only list keys starting with this prefix (optional)")),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		entries, err := memoryService.List(ctx, request.GetString("prefix", ""))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list memory entries: %v", err)), nil
+		}
+		if len(entries) == 0 {
+			return mcp.NewToolResultText("No entries found."), nil
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "Found %d entries:\n", len(entries))
+		for _, e := range entries {
+			value := strings.Join(strings.Fields(e.Value), " ")
+			if r := []rune(value); len(r) > 80 {
+				value = string(r[:79]) + "…"
+			}
+			fmt.Fprintf(&b, "- %s: %s (updated %s)\n", e.Key, value, e.Updated)
+		}
+		return mcp.NewToolResultText(b.String()), nil
+	})
+
+	// Tool: Confirm Action
+	if *confirm {
+		s.AddTool(mcp.NewTool("confirm_action",
+			mcp.WithDescription("Run or cancel a pending action. With -confirm, destructive tools (send, trash, delete, overwrite...) do not run when called: they return a summary and a token, and only run when this tool is called with that token. Without token, lists the pending actions."),
+			mcp.WithString("token", mcp.Description("Token of the pending action")),
+			mcp.WithString("cancel", mcp.Description("If 'true', discard the pending action instead of running it (default: false)")),
+		), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			token := request.GetString("token", "")
+			if token == "" {
+				pending := confirmer.list()
+				if len(pending) == 0 {
+					return mcp.NewToolResultText("No pending actions."), nil
+				}
+				return mcp.NewToolResultText(strings.Join(pending, "\n\n")), nil
+			}
+			action, ok := confirmer.take(token)
+			if !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("No pending action with token %s (it may have expired or already run)", token)), nil
+			}
+			if request.GetString("cancel", "false") == "true" {
+				return mcp.NewToolResultText(fmt.Sprintf("Cancelled %s (%s).", token, action.request.Params.Name)), nil
+			}
+			return action.handler(ctx, action.request)
+		})
+	}
+
+	// Tool filters: tools of services left out by -services, and tools left out by -read-only,
+	// -allow-tools or -deny-tools, are removed after registration, so they are neither listed nor callable.
+	toolNames := slices.Sorted(maps.Keys(s.ListTools()))
+	for _, pattern := range unmatchedToolPatterns(toolNames, slices.Concat(allowPatterns, denyPatterns)) {
+		slog.Warn("tool pattern matches no tool", "pattern", pattern)
+	}
+	var removedTools []string
+	for _, name := range toolNames {
+		if !toolEnabled(services, name) || !toolAllowed(s.GetTool(name).Tool, *readOnly, allowPatterns, denyPatterns) {
+			removedTools = append(removedTools, name)
+		}
+	}
+	s.DeleteTools(removedTools...)
+	confirmer.gate(s.ListTools())
+	if *impersonate != "" {
+		addToolArgument(s, "as_user", "Workspace user email to act as (domain-wide delegation); defaults to the -impersonate user")
+	}
+	if multiAccount {
+		addToolArgument(s, "account", "Stored account or alias to use (see accounts_list); defaults to the default account")
+	}
+
+	// Resources: Drive files, Docs and Sheets that clients can attach directly, without tool calls.
+	readDriveResource := func(ctx context.Context, uri, fileID string) ([]mcp.ResourceContents, error) {
+		if err := registry.ensure("drive"); err != nil {
+			return nil, err
+		}
+		rc, err := driveService.ReadResource(ctx, fileID, maxResourceBytes)
+		if err != nil {
+			return nil, err
+		}
+		if rc.Data != nil {
+			return []mcp.ResourceContents{mcp.BlobResourceContents{URI: uri, MIMEType: rc.MimeType, Blob: base64.StdEncoding.EncodeToString(rc.Data)}}, nil
+		}
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: rc.MimeType, Text: rc.Text}}, nil
+	}
+	readDocResource := func(ctx context.Context, uri, docID string) ([]mcp.ResourceContents, error) {
+		if err := registry.ensure("docs"); err != nil {
+			return nil, err
+		}
+		doc, err := docsService.GetDocument(ctx, docID)
+		if err != nil {
+			return nil, err
+		}
+		text := "# " + doc.Title + "\n\n" + docssvc.ToMarkdown(doc, 0, 0)
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: "text/markdown", Text: text}}, nil
+	}
+	// readSheetResource returns a range as CSV, or every tab (one content per tab) when rangeName is empty.
+	readSheetResource := func(ctx context.Context, uri, spreadsheetID, rangeName string) ([]mcp.ResourceContents, error) {
+		if err := registry.ensure("sheets"); err != nil {
+			return nil, err
+		}
+		ranges, uris := []string{rangeName}, []string{uri}
+		if rangeName == "" {
+			sp, err := sheetsService.GetSpreadsheet(ctx, spreadsheetID)
+			if err != nil {
+				return nil, err
+			}
+			ranges, uris = nil, nil
+			for _, sh := range sp.Sheets {
+				if sh.Properties.SheetType != "" && sh.Properties.SheetType != "GRID" {
+					continue
+				}
+				ranges = append(ranges, sheetssvc.GridRangeA1(sh.Properties.Title, nil))
+				uris = append(uris, uri+"/"+url.PathEscape(sh.Properties.Title))
+			}
+		}
+		vrs, err := sheetsService.BatchReadValues(ctx, spreadsheetID, ranges, sheetssvc.ReadOptions{})
+		if err != nil {
+			return nil, err
+		}
+		var out []mcp.ResourceContents
+		for i, vr := range vrs {
+			text, err := sheetssvc.FormatCSV(vr.Values)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, mcp.TextResourceContents{URI: uris[i], MIMEType: "text/csv", Text: text})
+		}
+		return out, nil
+	}
+
+	if serviceEnabled(services, "drive") {
+		s.AddResourceTemplate(mcp.NewResourceTemplate("gdrive://file/{id}", "Drive file",
+			mcp.WithTemplateDescription("Content of a Drive file: text for text files and Google Workspace documents (Sheets as CSV), binary otherwise (up to 10 MB)"),
+		), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return readDriveResource(ctx, request.Params.URI, resourceArg(request, "id"))
+		})
+	}
+	if serviceEnabled(services, "docs") {
+		s.AddResourceTemplate(mcp.NewResourceTemplate("gdocs://document/{id}", "Google Doc",
+			mcp.WithTemplateDescription("A Google Doc as Markdown"),
+			mcp.WithTemplateMIMEType("text/markdown"),
+		), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return readDocResource(ctx, request.Params.URI, resourceArg(request, "id"))
+		})
+	}
+	if serviceEnabled(services, "sheets") {
+		s.AddResourceTemplate(mcp.NewResourceTemplate("gsheets://spreadsheet/{id}", "Google Sheet",
+			mcp.WithTemplateDescription("Every tab of a Google Sheet as CSV, one content per tab"),
+			mcp.WithTemplateMIMEType("text/csv"),
+		), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return readSheetResource(ctx, request.Params.URI, resourceArg(request, "id"), "")
+		})
+	}
+	if serviceEnabled(services, "sheets") {
+		s.AddResourceTemplate(mcp.NewResourceTemplate("gsheets://spreadsheet/{id}/{+range}", "Google Sheet range",
+			mcp.WithTemplateDescription("A tab or range of a Google Sheet as CSV (A1 notation, e.g. gsheets://spreadsheet/ID/Sheet1!A1:D50)"),
+			mcp.WithTemplateMIMEType("text/csv"),
+		), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return readSheetResource(ctx, request.Params.URI, resourceArg(request, "id"), resourceArg(request, "range"))
+		})
+	}
+
+	// Recent Docs and Sheets are listed as concrete resources. The list is refreshed when a client lists
+	// resources, at most every resourcesTTL, and only replaced when it changed, so the list_changed
+	// notification cannot make clients re-list in a loop.
+	recentKinds := []struct {
+		service, mimeType, uriPrefix, label, contentType string
+		read                                             func(ctx context.Context, uri, id string) ([]mcp.ResourceContents, error)
+	}{
+		{"docs", "application/vnd.google-apps.document", "gdocs://document/", "Google Doc", "text/markdown", readDocResource},
+		{"sheets", "application/vnd.google-apps.spreadsheet", "gsheets://spreadsheet/", "Google Sheet", "text/csv", func(ctx context.Context, uri, id string) ([]mcp.ResourceContents, error) {
+			return readSheetResource(ctx, uri, id, "")
+		}},
+	}
+	var recentMu sync.Mutex
+	var recentURIs []string
+	var recentAt time.Time
+	resourcesTTL := recentResourcesTTL
+	if cfg.ResourcesTTL > 0 {
+		resourcesTTL = cfg.ResourcesTTL
+	}
+	hooks.AddBeforeListResources(func(ctx context.Context, id any, message *mcp.ListResourcesRequest) {
+		recentMu.Lock()
+		defer recentMu.Unlock()
+		if !serviceEnabled(services, "drive") || time.Since(recentAt) < resourcesTTL {
+			return
+		}
+		recentAt = time.Now()
+
+		var resources []server.ServerResource
+		var uris []string
+		if err := registry.ensure("drive"); err != nil {
+			slog.Warn("failed to list recent files for resources", "error", err)
+			return
+		}
+		for _, kind := range recentKinds {
+			if !serviceEnabled(services, kind.service) {
+				continue
+			}
+			files, err := driveService.RecentFiles(ctx, kind.mimeType, 20)
+			if err != nil {
+				slog.Warn("failed to list recent files for resources", "error", err)
+				return
+			}
+			for _, f := range files {
+				uri, fileID, read := kind.uriPrefix+f.Id, f.Id, kind.read
+				resources = append(resources, server.ServerResource{
+					Resource: mcp.NewResource(uri, f.Name,
+						mcp.WithResourceDescription(fmt.Sprintf("%s, modified %s", kind.label, f.ModifiedTime)),
+						mcp.WithMIMEType(kind.contentType),
+					),
+					Handler: func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+						return read(ctx, uri, fileID)
+					},
+				})
+				uris = append(uris, uri)
+			}
+		}
+		if slices.Equal(uris, recentURIs) {
+			return
+		}
+		s.DeleteResources(recentURIs...)
+		s.AddResources(resources...)
+		recentURIs = uris
+	})
+
+	if *metricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		go func() {
+			slog.Info("serving metrics", "addr", *metricsAddr)
+			if err := http.ListenAndServe(*metricsAddr, mux); err != nil {
+				slog.Error("metrics server stopped", "error", err)
+			}
+		}()
+	}
+
+	// Start server (stdio)
+	if err := server.ServeStdio(s); err != nil {
+		slog.Error("server stopped", "error", err)
+		os.Exit(1)
+	}
+}
+
+func handleAuthCommand() {
+	// We parse subcommands manually since "auth" is the command
+	if len(os.Args) < 3 {
+		fmt.Println("Usage: gogo-mcp auth login --secrets <path> [--workspace] [--services drive,calendar,...] [--account <name>] [--no-browser] [--port <n>]")
+		fmt.Println("       gogo-mcp auth login --add-scopes keep,chat [--account <name>]")
+		fmt.Println("       gogo-mcp auth status [--account <name>]")
+		fmt.Println("       gogo-mcp auth logout [--account <name>]")
+		os.Exit(1)
+	}
+
+	storage, aliases := setupAuth()
+	switch os.Args[2] {
+	case "status":
+		authStatus(parseAccountFlag("status", aliases), storage)
+	case "logout":
+		authLogout(parseAccountFlag("logout", aliases))
+	case "login":
+		loginCmd := flag.NewFlagSet("login", flag.ExitOnError)
+		secretsPath := loginCmd.String("secrets", "", "Path to client_secrets.json")
+		workspace := loginCmd.Bool("workspace", false, "Also request the Keep and Chat scopes (Google Workspace accounts only)")
+		servicesList := loginCmd.String("services", "", "Comma-separated services to grant access to, e.g. 'drive,calendar' (default all). "+serviceNamesHelp)
+		account := loginCmd.String("account", "", "Store the token under this account name (usually its email, or an alias from the config file), to log in several accounts")
+		noBrowser := loginCmd.Bool("no-browser", false, "Sign in from a browser on another machine and paste the address it is redirected to (for SSH sessions and containers)")
+		port := loginCmd.Int("port", auth.DefaultCallbackPort, "Localhost port for the OAuth callback; 0 picks a free port")
+		addScopes := loginCmd.String("add-scopes", "", "Comma-separated services whose scopes to add to an existing login, keeping those already granted (e.g. 'keep,chat'). "+serviceNamesHelp)
+		_ = loginCmd.Parse(os.Args[3:])
+
+		*account = aliases.resolve(*account)
+		if *account != "" {
+			if err := auth.ValidateAccountName(*account); err != nil {
+				fmt.Printf("Error: invalid --account: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if *port < 0 || *port > 65535 {
+			fmt.Printf("Error: invalid --port: %d\n", *port)
+			os.Exit(1)
+		}
+
+		if *addScopes != "" {
+			authAddScopes(*addScopes, *secretsPath, auth.LoginOptions{Account: *account, Port: *port, NoBrowser: *noBrowser, AddScopes: true})
+			return
+		}
+
+		if *secretsPath == "" {
+			fmt.Println("Error: --secrets flag is required")
+			loginCmd.Usage()
+			os.Exit(1)
+		}
+
+		// Read secrets
+		secrets, err := os.ReadFile(*secretsPath)
+		if err != nil {
+			fmt.Printf("Error reading secrets file: %v\n", err)
+			os.Exit(1)
+		}
+
+		services, err := parseServices(*servicesList)
+		if err != nil {
+			fmt.Printf("Error: invalid --services: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Perform login
+		fmt.Println("Starting OAuth 2.0 flow...")
+		scopes := oauthScopes(services, *workspace)
+		if err := auth.Login(context.Background(), secrets, scopes, auth.LoginOptions{Account: *account, Port: *port, NoBrowser: *noBrowser}); err != nil {
+			fmt.Printf("Login failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Save secrets for future use
+		if err := auth.SaveSecrets(*secretsPath); err != nil {
+			fmt.Printf("Warning: Failed to save secrets file for future use: %v\n", err)
+		}
+
+		if *account != "" {
+			fmt.Printf("Setup complete! Tools act as %s when called with account=%q, or by default with 'gogo-mcp -account %s'.\n", *account, *account, *account)
+			return
+		}
+		fmt.Println("Setup complete! You can now run 'gogo-mcp' without arguments.")
+	default:
+		fmt.Printf("Unknown auth command: %s\n", os.Args[2])
+		os.Exit(1)
+	}
+}
+
+// authAddScopes grants the scopes of more services to an existing login through incremental consent,
+// asking only for the scopes the stored token lacks.
+func authAddScopes(servicesList, secretsPath string, opts auth.LoginOptions) {
+	services, err := parseServices(servicesList)
+	if err != nil {
+		fmt.Printf("Error: invalid --add-scopes: %v\n", err)
+		os.Exit(1)
+	}
+	// The client secrets saved by the first login are used unless --secrets is given
+	var secrets []byte
+	if secretsPath != "" {
+		secrets, err = os.ReadFile(secretsPath)
+	} else {
+		secrets, err = auth.LoadSecrets()
+	}
+	if err != nil {
+		fmt.Printf("Error reading secrets file (pass it with --secrets): %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	ts, err := auth.AccountTokenSource(ctx, opts.Account, nil)
+	if err != nil {
+		fmt.Printf("Error: not logged in, log in without --add-scopes first: %v\n", err)
+		os.Exit(1)
+	}
+	missing, err := auth.MissingScopes(ctx, ts, oauthScopes(services, false))
+	if err != nil {
+		fmt.Printf("Error: unable to check the granted scopes: %v\n", err)
+		os.Exit(1)
+	}
+	if len(missing) == 0 {
+		fmt.Println("Nothing to do: the scopes of these services are already granted.")
+		return
+	}
+
+	fmt.Println("Requesting these scopes in addition to those already granted:")
+	for _, scope := range missing {
+		fmt.Printf("  %s\n", scope)
+	}
+	if err := auth.Login(context.Background(), secrets, missing, opts); err != nil {
+		fmt.Printf("Login failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("Scopes added! Restart the server to use them.")
+}
+
+// setupAuth reads the settings the auth subcommands share with the server from the config file: it
+// selects the token storage (GO_GOOGLE_MCP_TOKEN_STORAGE overrides the file) and returns its name and
+// the account aliases.
+func setupAuth() (string, accountAliases) {
+	cfg, err := loadConfig(os.Getenv("GO_GOOGLE_MCP_CONFIG"))
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	storage := cmp.Or(os.Getenv("GO_GOOGLE_MCP_TOKEN_STORAGE"), cfg.TokenStorage, auth.StorageFile)
+	if err := auth.SetTokenStorage(storage, os.Getenv("GO_GOOGLE_MCP_TOKEN_KEY")); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	aliases, err := newAccountAliases(cfg.AccountAliases)
+	if err != nil {
+		fmt.Printf("Error: invalid account_aliases: %v\n", err)
+		os.Exit(1)
+	}
+	return storage, aliases
+}
+
+// parseAccountFlag parses the --account flag of an auth subcommand, resolving aliases.
+func parseAccountFlag(name string, aliases accountAliases) string {
+	cmd := flag.NewFlagSet(name, flag.ExitOnError)
+	account := cmd.String("account", "", "The account name given to 'auth login --account', or its alias (default: the account logged in without one)")
+	_ = cmd.Parse(os.Args[3:])
+	*account = aliases.resolve(*account)
+	if *account != "" {
+		if err := auth.ValidateAccountName(*account); err != nil {
+			fmt.Printf("Error: invalid --account: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	return *account
+}
+
+// authStatus prints where credentials are stored, the stored accounts, and the given account's token
+// and what Google reports about it.
+func authStatus(account, storage string) {
+	dir, err := auth.GetConfigDir()
+	if err != nil {
+		fmt.Printf("Error: unable to find the config directory: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Config directory: %s\n", dir)
+	if _, err := auth.LoadSecrets(); err != nil {
+		fmt.Println("Client secrets:   missing (run 'auth login --secrets <path>')")
+	} else {
+		fmt.Println("Client secrets:   saved")
+	}
+	fmt.Printf("Token storage:    %s\n", storage)
+	if accounts, err := auth.ListAccounts(); err == nil && len(accounts) > 0 {
+		fmt.Printf("Named accounts:   %s\n", strings.Join(accounts, ", "))
+	}
+	token, err := auth.LoadAccountToken(account)
+	if err != nil {
+		if account != "" {
+			fmt.Printf("Token:            none for %s (run 'auth login --account %s')\n", account, account)
+			return
+		}
+		fmt.Println("Token:            none (not logged in; the server falls back to application default credentials)")
+		return
+	}
+	refresh := "no refresh token: log in again when it expires"
+	if token.RefreshToken != "" {
+		refresh = "refresh token saved"
+	}
+	fmt.Printf("Token:            saved, %s\n", refresh)
+
+	// Refresh if needed (the refreshed token is saved), then ask Google what the token grants
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	ts, err := auth.AccountTokenSource(ctx, account, nil)
+	if err != nil {
+		fmt.Printf("Status:           unable to use the token: %v\n", err)
+		os.Exit(1)
+	}
+	current, err := ts.Token()
+	if err != nil {
+		fmt.Printf("Status:           unable to refresh the token, log in again: %v\n", err)
+		os.Exit(1)
+	}
+	info, err := auth.FetchTokenInfo(ctx, current.AccessToken)
+	if err != nil {
+		fmt.Printf("Status:           %v\n", err)
+		os.Exit(1)
+	}
+	if info.Email != "" {
+		fmt.Printf("Account:          %s\n", info.Email)
+	}
+	fmt.Printf("Access token:     valid until %s\n", info.Expiry.Local().Format(time.DateTime))
+	fmt.Println("Granted scopes:")
+	for _, scope := range info.Scopes {
+		fmt.Printf("  %s\n", scope)
+	}
+}
+
+// authLogout revokes an account's stored token at Google and deletes it. The client secrets are kept for
+// the next login.
+func authLogout(account string) {
+	token, err := auth.LoadAccountToken(account)
+	if err != nil {
+		fmt.Println("Not logged in: no stored token.")
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	revoke := token.RefreshToken
+	if revoke == "" {
+		revoke = token.AccessToken
+	}
+	revokeErr := auth.RevokeToken(ctx, revoke)
+	if err := auth.DeleteToken(account); err != nil {
+		fmt.Printf("Error: unable to delete the stored token: %v\n", err)
+		os.Exit(1)
+	}
+	if revokeErr != nil {
+		fmt.Printf("Logged out: the token was deleted, but not revoked (%v). Remove the app's access at https://myaccount.google.com/permissions if the token may have been copied.\n", revokeErr)
+		return
+	}
+	fmt.Println("Logged out: the token was revoked and deleted.")
+}
+
+// serviceScopes maps each -services name to the OAuth scopes its tools need.
+var serviceScopes = map[string][]string{
+	"drive":    {drive.DriveScope, driveactivity.DriveActivityReadonlyScope},
+	"gmail":    {gmail.GmailReadonlyScope, gmail.GmailSendScope, gmail.GmailModifyScope, gmail.GmailSettingsBasicScope},
+	"calendar": {calendar.CalendarScope},
+	"sheets":   {sheets.SpreadsheetsScope},
+	"people":   {people.ContactsScope},
+	"docs":     {docs.DocumentsScope},
+	"tasks":    {tasks.TasksScope},
+	"keep":     {keepapi.KeepScope},
+	"chat":     {chatapi.ChatSpacesReadonlyScope, chatapi.ChatMessagesScope},
+	"memory":   {drive.DriveFileScope}, // Only files the server created: the memory spreadsheet
+}
+
+// defaultServices are enabled when -services is not set. Keep and Chat are added for workspace only:
+// personal accounts get invalid_scope for their scopes, which would block the whole login.
+var (
+	defaultServices   = []string{"drive", "gmail", "calendar", "sheets", "people", "docs", "tasks", "memory"}
+	workspaceServices = []string{"keep", "chat"}
+)
+
+const serviceNamesHelp = "Services: drive, gmail, calendar, sheets, people, docs, tasks, memory, keep, chat (keep and chat need a Workspace account)."
+
+// parseServices parses a comma-separated -services list. An empty list means every service.
+func parseServices(list string) ([]string, error) {
+	services := splitList(list)
+	for _, name := range services {
+		if _, ok := serviceScopes[name]; !ok {
+			return nil, fmt.Errorf("unknown service %q. %s", name, serviceNamesHelp)
+		}
+	}
+	return services, nil
+}
+
+// serviceEnabled reports whether a service's tools and resources are available: services is the -services
+// list, empty for all. An empty service (tools such as ping that use no Google API) is always enabled.
+func serviceEnabled(services []string, service string) bool {
+	return service == "" || len(services) == 0 || slices.Contains(services, service)
+}
+
+// toolEnabled reports whether a tool's services are enabled: the one of its name prefix or, for tools
+// spanning services such as create_task_from_email, all of their toolExtraServices.
+func toolEnabled(services []string, name string) bool {
+	if service := toolService(name); service != "" {
+		return serviceEnabled(services, service)
+	}
+	return !slices.ContainsFunc(toolExtraServices[name], func(service string) bool { return !serviceEnabled(services, service) })
+}
+
+// toolService returns the service a tool belongs to, from its name prefix (e.g. "gmail" for gmail_search),
+// or "" for tools that use no Google API.
+func toolService(name string) string {
+	prefix, _, _ := strings.Cut(name, "_")
+	if _, ok := serviceScopes[prefix]; ok {
+		return prefix
+	}
+	return ""
+}
+
+// oauthScopes returns the scopes requested at login and by the server: those of services, or when services
+// is empty, those of defaultServices (plus workspaceServices for workspace).
+func oauthScopes(services []string, workspace bool) []string {
+	if len(services) == 0 {
+		services = defaultServices
+		if workspace {
+			services = slices.Concat(defaultServices, workspaceServices)
+		}
+	}
+	var scopes []string
+	for _, name := range services {
+		for _, scope := range serviceScopes[name] {
+			if !slices.Contains(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	return scopes
+}
+
+// keepUnavailableMessage is returned when Keep API is not available (e.g. personal account, scope not granted).
+const keepUnavailableMessage = "Google Keep is not available for this account. It requires a Google Workspace account: enable the Keep API in Cloud Console and log in again with 'go-google-mcp auth login --workspace'."
+
+const chatUnavailableMessage = "Google Chat is not available for this account. It requires a Google Workspace account: enable the Chat API (and configure a Chat app) in Cloud Console and log in again with 'go-google-mcp auth login --workspace'."
+
+// isUnavailableError returns true if the error indicates a Workspace-only API (Keep, Chat) is not available (scope, 403, not enabled).
+func isUnavailableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	s := strings.ToLower(err.Error())
+	return strings.Contains(s, "403") ||
+		strings.Contains(s, "access not configured") ||
+		strings.Contains(s, "invalid_scope") ||
+		strings.Contains(s, "access_denied") ||
+		strings.Contains(s, "insufficient") ||
+		strings.Contains(s, "permission") ||
+		strings.Contains(s, "not enabled") ||
+		strings.Contains(s, "forbidden")
+}
+
+// serviceHealth describes the outcome of a service's Ping for health_check. requested tells whether
+// the server asked for the service's scopes (for OAuth users, the scopes granted at login decide).
+func serviceHealth(err error, requested bool) string {
+	var disabled *transport.DisabledError
+	var quota *transport.QuotaError
+	var scope *transport.ScopeError
+	var apiErr *googleapi.Error
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.As(err, &disabled):
+		return fmt.Sprintf("API not enabled: enable it at https://console.cloud.google.com/apis/library/%s", disabled.Host)
+	case errors.As(err, &quota):
+		return "quota exceeded: " + quota.Error()
+	case errors.As(err, &scope):
+		return scopeHint(scope.API, requested)
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timed out"
+	case errors.As(err, &apiErr) && apiErr.Code == http.StatusUnauthorized:
+		return "not authenticated: run 'go-google-mcp auth login' again"
+	case errors.As(err, &apiErr) && apiErr.Code == http.StatusForbidden:
+		if !requested {
+			return "scope not granted: run with -workspace or list the service in -services (OAuth users: log in again with the same flags)"
+		}
+		return "permission denied: " + apiErr.Message + " (check that the account may use this service)"
+	}
+	return "error: " + err.Error()
+}
+
+// scopeHint says how to grant a service's missing scope.
+func scopeHint(api string, requested bool) string {
+	if api == "driveactivity" {
+		api = "activity"
+	}
+	if !requested {
+		return fmt.Sprintf("scope not granted: run with -workspace or list the service in -services, and OAuth users run 'go-google-mcp auth login --add-scopes %s'", api)
+	}
+	return fmt.Sprintf("scope not granted: OAuth users run 'go-google-mcp auth login --add-scopes %s' and restart; with domain-wide delegation, add the scope in the Admin console", api)
+}
+
+const (
+	// maxResourceBytes caps the content returned for a resource read.
+	maxResourceBytes = 10 << 20
+	// recentResourcesTTL is how long the list of recent Docs and Sheets resources is reused, unless the
+	// config file sets resources_ttl.
+	recentResourcesTTL = 5 * time.Minute
+)
+
+// loadConfig reads the settings file at path, or at the default location if path is empty. A missing
+// default file is not an error: the settings file is optional.
+func loadConfig(path string) (*config.Config, error) {
+	explicit := path != ""
+	if !explicit {
+		dir, err := auth.GetConfigDir()
+		if err != nil {
+			return &config.Config{}, nil
+		}
+		path = filepath.Join(dir, config.FileName)
+	}
+	cfg, err := config.Load(path)
+	if !explicit && errors.Is(err, fs.ErrNotExist) {
+		return &config.Config{}, nil
+	}
+	return cfg, err
+}
+
+// applyConfigFlags sets the flags given in the settings file, except those set on the command line
+// or through their environment variable, which take precedence.
+func applyConfigFlags(cfg *config.Config) error {
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for name, value := range cfg.Flags() {
+		if set[name] || os.Getenv(flagEnv(name)) != "" {
+			continue
+		}
+		if err := flag.Set(name, value); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// flagEnv returns the environment variable of a flag, e.g. GO_GOOGLE_MCP_READ_ONLY for -read-only.
+func flagEnv(name string) string {
+	return "GO_GOOGLE_MCP_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// transportConfig returns the retry and rate limit settings of the config file, over the defaults.
+func transportConfig(cfg *config.Config) transport.Config {
+	out := transport.Config{
+		MaxRetries:  cfg.Retries.Max,
+		BaseBackoff: cfg.Retries.BaseBackoff,
+		MaxBackoff:  cfg.Retries.MaxBackoff,
+	}
+	if len(cfg.RateLimits) > 0 {
+		out.Limits = maps.Clone(transport.DefaultLimits)
+		for api, l := range cfg.RateLimits {
+			if api == "default" {
+				api = ""
+			}
+			out.Limits[api] = transport.Limit{PerSecond: l.PerSecond, Burst: l.Burst}
+		}
+	}
+	return out
+}
+
+// envBool reports whether an environment variable is set to a true value ("1", "true", ...).
+func envBool(name string) bool {
+	v, _ := strconv.ParseBool(os.Getenv(name))
+	return v
+}
+
+// matchesToolPattern reports whether a tool name matches any of the patterns (path.Match syntax, e.g. "gmail_*").
+func matchesToolPattern(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// unmatchedToolPatterns returns the patterns that match none of the tool names, such as misspelled names.
+func unmatchedToolPatterns(names, patterns []string) []string {
+	var out []string
+	for _, pattern := range patterns {
+		if !slices.ContainsFunc(names, func(name string) bool { return matchesToolPattern(name, []string{pattern}) }) {
+			out = append(out, pattern)
+		}
+	}
+	return out
+}
+
+// toolAllowed applies the -read-only, -allow-tools and -deny-tools filters to a tool. In read-only mode only
+// tools annotated read-only are kept; an empty allow list allows every tool.
+func toolAllowed(tool mcp.Tool, readOnly bool, allow, deny []string) bool {
+	if readOnly && (tool.Annotations.ReadOnlyHint == nil || !*tool.Annotations.ReadOnlyHint) {
+		return false
+	}
+	if len(allow) > 0 && !matchesToolPattern(tool.Name, allow) {
+		return false
+	}
+	return !matchesToolPattern(tool.Name, deny)
+}
+
+// resourceArg returns a variable matched from a resource template URI.
+func resourceArg(request mcp.ReadResourceRequest, name string) string {
+	switch v := request.Params.Arguments[name].(type) {
+	case []string:
+		if len(v) > 0 {
+			return v[0]
+		}
+	case string:
+		return v
+	}
+	return ""
+}
+
+// loggingMiddleware logs every tool call with its duration and outcome, and its arguments at debug level.
+func loggingMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		name := request.Params.Name
+		slog.Debug("tool call", "tool", name, "arguments", request.GetArguments())
+		start := time.Now()
+		res, err := next(ctx, request)
+		attrs := []any{"tool", name, "duration", time.Since(start).Round(time.Millisecond)}
+		switch {
+		case err != nil:
+			slog.Error("tool failed", append(attrs, "error", err)...)
+		case res != nil && res.IsError:
+			slog.Warn("tool returned an error", append(attrs, "error", logging.Truncate(resultText(res), 300))...)
+		default:
+			slog.Info("tool done", attrs...)
+		}
+		return res, err
+	}
+}
+
+// metricsMiddleware counts tool calls by outcome and records their latency.
+func metricsMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		name := request.Params.Name
+		start := time.Now()
+		res, err := next(ctx, request)
+		metrics.ToolDuration.Observe(time.Since(start), name)
+		switch {
+		case err != nil:
+			metrics.ToolCalls.Inc(name, "failed")
+		case res != nil && res.IsError:
+			metrics.ToolCalls.Inc(name, "error")
+		default:
+			metrics.ToolCalls.Inc(name, "ok")
+		}
+		return res, err
+	}
+}
+
+// serviceRegistry creates the Google API clients on first use. A client that fails to be created is
+// retried on the next call that needs it; only those calls fail, with the reason.
+type serviceRegistry struct {
+	mu    sync.Mutex
+	inits map[string]func() error // keyed by service name, e.g. "drive"; each sets its client variable
+	ready map[string]bool
+}
+
+func newServiceRegistry(inits map[string]func() error) *serviceRegistry {
+	return &serviceRegistry{inits: inits, ready: make(map[string]bool)}
+}
+
+// ensure creates the clients of the named services that do not exist yet.
+func (r *serviceRegistry) ensure(names ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range names {
+		if r.ready[name] {
+			continue
+		}
+		if err := r.inits[name](); err != nil {
+			return err
+		}
+		r.ready[name] = true
+	}
+	return nil
+}
+
+// toolExtraServices lists the services a tool uses besides the one in its name prefix.
+var toolExtraServices = map[string][]string{
+	"drive_get_recent_activity": {"activity"},
+	"drive_file_activity":       {"activity"},
+	"gmail_download_attachment": {"drive"},
+	"gmail_send_email":          {"drive"},
+	"gmail_export_thread":       {"drive"}, // and docs for a Google Doc or PDF, created by the tool itself
+	"sheets_import_csv":         {"drive"},
+	"docs_create_from_template": {"drive"},
+	"keep_download_attachment":  {"drive"},
+	"create_task_from_email":    {"gmail", "tasks"}, // and calendar for a block, created by the tool itself
+	"mail_merge":                {"sheets", "gmail"},
+	"calendar_export_to_sheet":  {"sheets"},
+	"gmail_extract_to_sheet":    {"sheets"},
+	"people_export_contacts":    {"drive"},
+}
+
+// middleware creates the clients a tool needs before it runs, failing the call if one cannot be created.
+func (r *serviceRegistry) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		name := request.Params.Name
+		var needed []string
+		if service := toolService(name); service != "" {
+			needed = append(needed, service)
+		}
+		if err := r.ensure(append(needed, toolExtraServices[name]...)...); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to initialize service: %v", err)), nil
+		}
+		return next(ctx, request)
+	}
+}
+
+// localTools are the tools that make no Google API calls, so choosing an account or user means nothing to them.
+var localTools = []string{"ping", "confirm_action", "accounts_list"}
+
+// addToolArgument adds an optional string argument to every tool that calls Google APIs.
+func addToolArgument(s *server.MCPServer, arg, description string) {
+	var updated []server.ServerTool
+	for name, t := range s.ListTools() {
+		if slices.Contains(localTools, name) {
+			continue
+		}
+		props := maps.Clone(t.Tool.InputSchema.Properties)
+		if props == nil {
+			props = make(map[string]any)
+		}
+		props[arg] = map[string]any{
+			"type":        "string",
+			"description": description,
+		}
+		t.Tool.InputSchema.Properties = props
+		updated = append(updated, *t)
+	}
+	s.AddTools(updated...)
+}
+
+// asUserMiddleware makes a call's Google API requests act as the user in its as_user argument, if any.
+func asUserMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		user := request.GetString("as_user", "")
+		if user == "" {
+			return next(ctx, request)
+		}
+		if err := auth.ValidateSubject(user); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid as_user: %v", err)), nil
+		}
+		return next(auth.WithSubject(ctx, user), request)
+	}
+}
+
+// accountMiddleware makes a call's Google API requests use the stored account (or alias) in its
+// account argument, if any.
+func accountMiddleware(aliases accountAliases) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			account := aliases.resolve(request.GetString("account", ""))
+			if account == "" {
+				return next(ctx, request)
+			}
+			if err := auth.ValidateAccountName(account); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid account: %v", err)), nil
+			}
+			return next(auth.WithAccount(ctx, account), request)
+		}
+	}
+}
+
+// accountAliases maps short names such as "work" to stored account names (account_aliases in the config
+// file). Aliases are matched case-insensitively.
+type accountAliases map[string]string
+
+// newAccountAliases checks and normalizes the aliases of the config file.
+func newAccountAliases(m map[string]string) (accountAliases, error) {
+	aliases := make(accountAliases, len(m))
+	for alias, account := range m {
+		if alias == "" || strings.ContainsAny(alias, " \t") {
+			return nil, fmt.Errorf("invalid alias %q", alias)
+		}
+		if err := auth.ValidateAccountName(account); err != nil {
+			return nil, fmt.Errorf("alias %s: %w", alias, err)
+		}
+		aliases[strings.ToLower(alias)] = account
+	}
+	return aliases, nil
+}
+
+// resolve returns the account an alias stands for, or name itself if it is not an alias.
+func (a accountAliases) resolve(name string) string {
+	if account, ok := a[strings.ToLower(name)]; ok {
+		return account
+	}
+	return name
+}
+
+// of returns the aliases of an account, sorted.
+func (a accountAliases) of(account string) []string {
+	var names []string
+	for alias, target := range a {
+		if target == account {
+			names = append(names, alias)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// resultText joins the text content of a tool result.
+func resultText(res *mcp.CallToolResult) string {
+	var texts []string
+	for _, c := range res.Content {
+		if t, ok := c.(mcp.TextContent); ok {
+			texts = append(texts, t.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// envOr returns an environment variable, or def when it is unset.
+func envOr(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
+
+// defaultToolTimeouts are the built-in time limits of tools that routinely take longer than -timeout.
+var defaultToolTimeouts = map[string]time.Duration{
+	"drive_read_file":           2 * time.Minute, // OCR of large PDFs
+	"drive_download_file":       5 * time.Minute,
+	"drive_export_pdf":          2 * time.Minute,
+	"drive_upload_file":         10 * time.Minute,
+	"drive_list_folder":         2 * time.Minute, // Recursive trees
+	"drive_backup_folder":       15 * time.Minute,
+	"drive_bulk_move":           10 * time.Minute,
+	"drive_bulk_rename":         10 * time.Minute,
+	"gmail_send_email":          2 * time.Minute, // Attachments
+	"gmail_download_attachment": 2 * time.Minute,
+	"gmail_wait_for_new_mail":   6 * time.Minute, // Waits up to 5 minutes
+	"sheets_import_csv":         2 * time.Minute,
+	"people_find_duplicates":    2 * time.Minute, // Reads every contact
+	"people_merge_contacts":     2 * time.Minute,
+	"people_export_contacts":    2 * time.Minute, // Reads every contact
+	"people_import_contacts":    5 * time.Minute,
+	"docs_create_from_template": 2 * time.Minute,
+	"keep_download_attachment":  2 * time.Minute,
+	"mail_merge":                10 * time.Minute, // Sends with a delay between emails
+	"calendar_export_to_sheet":  2 * time.Minute,  // Up to a year of events
+	"gmail_extract_to_sheet":    5 * time.Minute,  // Reads up to 500 messages
+	"confirm_action":            0,                // The confirmed call runs under its own tool's limit
+}
+
+// parseToolTimeouts parses -tool-timeouts ("tool=duration,...") on top of defaultToolTimeouts.
+func parseToolTimeouts(s string) (map[string]time.Duration, error) {
+	out := maps.Clone(defaultToolTimeouts)
+	for _, entry := range splitList(s) {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q: expected tool=duration", entry)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", entry, err)
+		}
+		out[strings.TrimSpace(name)] = d
+	}
+	return out, nil
+}
+
+// envDuration reads a duration from an environment variable, or returns def when it is unset or invalid.
+func envDuration(name string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(name)); err == nil {
+		return d
+	}
+	return def
+}
+
+// localFiles implements -local-root: tools reading or writing local files only reach files inside the
+// root directory, and never the server's own credentials, whatever symlinks the path goes through.
+type localFiles struct {
+	root    string   // Resolved root directory
+	private []string // Resolved files and directories that are always refused
+}
+
+// newLocalFiles resolves the root directory and the private paths. Private paths that do not exist
+// are skipped.
+func newLocalFiles(root string, private ...string) (*localFiles, error) {
+	resolved, err := resolvePath(root)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+	l := &localFiles{root: resolved}
+	for _, p := range private {
+		if p == "" {
+			continue
+		}
+		if resolved, err := resolvePath(p); err == nil {
+			l.private = append(l.private, resolved)
+		}
+	}
+	return l, nil
+}
+
+// resolve returns the absolute path, with symlinks resolved, of a local file a tool reads or writes.
+// Files that do not exist yet are resolved through their directory. Paths outside the root directory
+// or inside a private path are refused.
+func (l *localFiles) resolve(p string) (string, error) {
+	resolved, err := resolvePath(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		var dir string
+		if dir, err = resolvePath(filepath.Dir(p)); err == nil {
+			resolved = filepath.Join(dir, filepath.Base(p))
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	if !pathWithin(l.root, resolved) {
+		return "", fmt.Errorf("%s is outside the local files directory %s (see -local-root)", p, l.root)
+	}
+	for _, private := range l.private {
+		if pathWithin(private, resolved) {
+			return "", fmt.Errorf("%s holds the server's credentials and cannot be used", p)
+		}
+	}
+	return resolved, nil
+}
+
+// resolvePath makes a path absolute and resolves its symlinks.
+func resolvePath(p string) (string, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
+
+// pathWithin reports whether p is dir or inside it; both are absolute and clean.
+func pathWithin(dir, p string) bool {
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// timeoutMiddleware bounds each tool call by its time limit (perTool, else def; 0 means none). The handler
+// runs in its own goroutine, so even a call stuck outside the context cannot stall the session.
+func timeoutMiddleware(def time.Duration, perTool map[string]time.Duration) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			name := request.Params.Name
+			limit, ok := perTool[name]
+			if !ok {
+				limit = def
+			}
+			if limit <= 0 {
+				return next(ctx, request)
+			}
+			ctx, cancel := context.WithTimeout(ctx, limit)
+			defer cancel()
+
+			type outcome struct {
+				res *mcp.CallToolResult
+				err error
+			}
+			done := make(chan outcome, 1)
+			go func() {
+				res, err := next(ctx, request)
+				done <- outcome{res, err}
+			}()
+			var o outcome
+			select {
+			case o = <-done:
+				if o.err == nil && o.res != nil && !o.res.IsError {
+					return o.res, nil
+				}
+			case <-ctx.Done():
+			}
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return mcp.NewToolResultError(fmt.Sprintf("%s timed out after %v. The operation may still complete on Google's side, so check before retrying; "+
+					"to allow more time, start the server with -tool-timeouts '%s=<duration>'.", name, limit, name)), nil
+			}
+			if o.res == nil && o.err == nil {
+				return nil, ctx.Err()
+			}
+			return o.res, o.err
+		}
+	}
+}
+
+// pendingActionTTL is how long a held action can be confirmed.
+const pendingActionTTL = 10 * time.Minute
+
+// pendingAction is a destructive tool call held until confirm_action runs it.
+type pendingAction struct {
+	request mcp.CallToolRequest
+	handler server.ToolHandlerFunc
+	expires time.Time
+}
+
+// actionConfirmer implements -confirm: calls to gated tools are held as pending actions under a random
+// token and only run when confirm_action is called with it.
+type actionConfirmer struct {
+	mu      sync.Mutex
+	gated   map[string]bool
+	pending map[string]pendingAction
+}
+
+func newActionConfirmer() *actionConfirmer {
+	return &actionConfirmer{gated: map[string]bool{}, pending: map[string]pendingAction{}}
+}
+
+// gate selects the tools that need confirmation: those neither annotated read-only nor non-destructive.
+func (c *actionConfirmer) gate(tools map[string]*server.ServerTool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, t := range tools {
+		a := t.Tool.Annotations
+		readOnly := a.ReadOnlyHint != nil && *a.ReadOnlyHint
+		destructive := a.DestructiveHint == nil || *a.DestructiveHint
+		c.gated[name] = name != "confirm_action" && !readOnly && destructive
+	}
+}
+
+func (c *actionConfirmer) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		c.mu.Lock()
+		gated := c.gated[request.Params.Name]
+		c.mu.Unlock()
+		if !gated {
+			return next(ctx, request)
+		}
+		token, err := c.hold(request, next)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to hold action: %v", err)), nil
+		}
+		return mcp.NewToolResultText(describeAction(token, request) + "\n\n" +
+			fmt.Sprintf("Nothing has been done yet. Call confirm_action with token '%s' to run it, or with cancel='true' to discard it. Expires in %v.", token, pendingActionTTL)), nil
+	}
+}
+
+// hold stores a call as a pending action and returns its token.
+func (c *actionConfirmer) hold(request mcp.CallToolRequest, handler server.ToolHandlerFunc) (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire()
+	c.pending[token] = pendingAction{request: request, handler: handler, expires: time.Now().Add(pendingActionTTL)}
+	return token, nil
+}
+
+// take removes and returns a pending action.
+func (c *actionConfirmer) take(token string) (pendingAction, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire()
+	action, ok := c.pending[token]
+	delete(c.pending, token)
+	return action, ok
+}
+
+// list describes the pending actions, oldest first.
+func (c *actionConfirmer) list() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire()
+	tokens := slices.SortedFunc(maps.Keys(c.pending), func(a, b string) int {
+		return c.pending[a].expires.Compare(c.pending[b].expires)
+	})
+	var out []string
+	for _, token := range tokens {
+		out = append(out, describeAction(token, c.pending[token].request))
+	}
+	return out
+}
+
+// expire drops pending actions past their deadline. The caller holds c.mu.
+func (c *actionConfirmer) expire() {
+	now := time.Now()
+	maps.DeleteFunc(c.pending, func(_ string, a pendingAction) bool { return now.After(a.expires) })
+}
+
+// describeAction summarizes a held call: the tool and its arguments, long values truncated.
+func describeAction(token string, request mcp.CallToolRequest) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Pending action %s: %s", token, request.Params.Name)
+	args := request.GetArguments()
+	for _, k := range slices.Sorted(maps.Keys(args)) {
+		v := fmt.Sprint(args[k])
+		if r := []rune(v); len(r) > 200 {
+			v = string(r[:200]) + "..."
+		}
+		fmt.Fprintf(&b, "\n  %s: %s", k, v)
+	}
+	return b.String()
+}
+
+// toolOutput is the JSON envelope returned by every tool when the server runs with -output json.
+// Exactly one of Error, Data and Text is set: Data when the tool already produced JSON, Text otherwise.
+type toolOutput struct {
+	Tool  string `json:"tool"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+	Data  any    `json:"data,omitempty"`
+	Text  string `json:"text,omitempty"`
+}
+
+// jsonOutputMiddleware wraps every tool result in a toolOutput envelope.
+func jsonOutputMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		res, err := next(ctx, request)
+		if err != nil || res == nil {
+			return res, err
+		}
+		return jsonToolResult(request.Params.Name, res), nil
+	}
+}
+
+// jsonToolResult converts a tool result to a toolOutput envelope, returned both as text content and as
+// structured content. Non-text content (images, resources) is kept after the envelope.
+func jsonToolResult(tool string, res *mcp.CallToolResult) *mcp.CallToolResult {
+	var texts []string
+	var rest []mcp.Content
+	for _, c := range res.Content {
+		if t, ok := c.(mcp.TextContent); ok {
+			texts = append(texts, t.Text)
+		} else {
+			rest = append(rest, c)
+		}
+	}
+	text := strings.Join(texts, "\n")
+
+	out := toolOutput{Tool: tool, OK: !res.IsError}
+	trimmed := strings.TrimSpace(text)
+	switch {
+	case res.IsError:
+		out.Error = text
+	case res.StructuredContent != nil:
+		out.Data = res.StructuredContent
+	case (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)):
+		out.Data = json.RawMessage(trimmed)
+	default:
+		out.Text = text
+	}
+	b, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return res
+	}
+	return &mcp.CallToolResult{
+		Result:            res.Result,
+		Content:           append([]mcp.Content{mcp.NewTextContent(string(b))}, rest...),
+		StructuredContent: out,
+		IsError:           res.IsError,
+	}
+}
+
+// driveFileLine formats a Drive file as a search result line, showing where shortcuts point to.
+func driveFileLine(f *drive.File) string {
+	if f.ShortcutDetails != nil {
+		return fmt.Sprintf("[%s] %s (shortcut to %s, %s)\n", f.Id, f.Name, f.ShortcutDetails.TargetId, f.ShortcutDetails.TargetMimeType)
+	}
+	return fmt.Sprintf("[%s] %s (%s)\n", f.Id, f.Name, f.MimeType)
+}
+
+// bulkSearch finds the files a Drive bulk tool changes. A filter is required so that a missing argument
+// cannot select the whole Drive.
+func bulkSearch(ctx context.Context, driveService *drivesvc.DriveService, request mcp.CallToolRequest) ([]*drive.File, error) {
+	query, err := drivesvc.Query{
+		Raw:            request.GetString("query", ""),
+		NameContains:   request.GetString("name_contains", ""),
+		MimeType:       request.GetString("mime_type", ""),
+		FolderID:       request.GetString("folder_id", ""),
+		ModifiedAfter:  request.GetString("modified_after", ""),
+		ModifiedBefore: request.GetString("modified_before", ""),
+	}.Build()
+	if err != nil {
+		return nil, err
+	}
+	if query == "" {
+		return nil, fmt.Errorf("a query or filter selecting the files is required")
+	}
+	limit := min(request.GetInt("limit", 100), 1000)
+	return driveService.SearchFiles(ctx, query, int64(limit), drivesvc.SearchScope{})
+}
+
+// bulkReport formats the outcome of a Drive bulk move or rename, one line per file.
+func bulkReport(changes []drivesvc.BulkChange, verb string, past string, dryRun bool) string {
+	var changed, skipped, failed int
+	var lines string
+	for _, c := range changes {
+		line := fmt.Sprintf("- %s [%s]", c.File.Name, c.File.Id)
+		if c.NewName != "" {
+			line += " -> " + c.NewName
+		}
+		switch {
+		case c.Skip != "":
+			skipped++
+			line += fmt.Sprintf(": skipped (%s)", c.Skip)
+		case c.Error != "":
+			failed++
+			line += fmt.Sprintf(": failed (%s)", c.Error)
+		default:
+			changed++
+		}
+		lines += line + "\n"
+	}
+	if len(changes) == 0 {
+		return "No files found."
+	}
+	head := fmt.Sprintf("%s %d of %d files", past, changed, len(changes))
+	if dryRun {
+		head = fmt.Sprintf("Dry run: would %s %d of %d files", verb, changed, len(changes))
+	}
+	return fmt.Sprintf("%s (%d skipped, %d failed):\n%s", head, skipped, failed, lines)
+}
+
+// splitList splits a comma-separated tool argument into trimmed, non-empty values.
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
 }
 
 func pingHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// callRequest builds a call to a tool with the given arguments.
+func callRequest(name string, args map[string]any) mcp.CallToolRequest {
+	var request mcp.CallToolRequest
+	request.Params.Name = name
+	request.Params.Arguments = args
+	return request
+}
+
+func TestActionConfirmer(t *testing.T) {
+	tools := map[string]*server.ServerTool{}
+	for _, tool := range []mcp.Tool{
+		mcp.NewTool("gmail_trash"),
+		mcp.NewTool("gmail_search", mcp.WithReadOnlyHintAnnotation(true)),
+		mcp.NewTool("drive_copy_file", mcp.WithDestructiveHintAnnotation(false)),
+		mcp.NewTool("confirm_action"),
+	} {
+		tools[tool.Name] = &server.ServerTool{Tool: tool}
+	}
+	c := newActionConfirmer()
+	c.gate(tools)
+
+	tests := []struct {
+		tool      string
+		wantGated bool
+	}{
+		{tool: "gmail_trash", wantGated: true},
+		{tool: "gmail_search", wantGated: false},
+		{tool: "drive_copy_file", wantGated: false},
+		{tool: "confirm_action", wantGated: false},
+		{tool: "unknown_tool", wantGated: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			calls := 0
+			handler := c.middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				calls++
+				return mcp.NewToolResultText("done"), nil
+			})
+			res, err := handler(context.Background(), callRequest(tt.tool, map[string]any{"id": "42"}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.wantGated {
+				if calls != 1 || resultText(res) != "done" {
+					t.Errorf("calls = %d, result %q: want the tool run without confirmation", calls, resultText(res))
+				}
+				return
+			}
+
+			if calls != 0 {
+				t.Fatalf("gated tool ran without confirmation")
+			}
+			text := resultText(res)
+			token, _, ok := strings.Cut(strings.TrimPrefix(text, "Pending action "), ":")
+			if !ok || !strings.Contains(text, "id: 42") {
+				t.Fatalf("unexpected hold message %q", text)
+			}
+			if pending := c.list(); len(pending) != 1 {
+				t.Errorf("pending actions = %q, want 1", pending)
+			}
+
+			action, ok := c.take(token)
+			if !ok {
+				t.Fatalf("no pending action for token %q", token)
+			}
+			if res, err := action.handler(context.Background(), action.request); err != nil || resultText(res) != "done" || calls != 1 {
+				t.Errorf("confirmed call: calls = %d, result %v, err %v", calls, res, err)
+			}
+			if _, ok := c.take(token); ok {
+				t.Error("a confirmed action can be taken again")
+			}
+		})
+	}
+}
+
+func TestToolAllowed(t *testing.T) {
+	search := mcp.NewTool("gmail_search", mcp.WithReadOnlyHintAnnotation(true))
+	send := mcp.NewTool("gmail_send_email")
+	copyFile := mcp.NewTool("drive_copy_file", mcp.WithDestructiveHintAnnotation(false))
+
+	tests := []struct {
+		name     string
+		tool     mcp.Tool
+		readOnly bool
+		allow    []string
+		deny     []string
+		want     bool
+	}{
+		{name: "no filters", tool: send, want: true},
+		{name: "read-only keeps read-only tools", tool: search, readOnly: true, want: true},
+		{name: "read-only hides write tools", tool: send, readOnly: true, want: false},
+		{name: "read-only hides non-destructive writes", tool: copyFile, readOnly: true, want: false},
+		{name: "read-only wins over allow", tool: send, readOnly: true, allow: []string{"gmail_send_email"}, want: false},
+		{name: "allowed by pattern", tool: send, allow: []string{"gmail_*"}, want: true},
+		{name: "not in allow list", tool: copyFile, allow: []string{"gmail_*"}, want: false},
+		{name: "deny wins over allow", tool: send, allow: []string{"gmail_*"}, deny: []string{"gmail_send_*"}, want: false},
+		{name: "deny by exact name", tool: search, deny: []string{"gmail_search"}, want: false},
+		{name: "unknown names only in allow list", tool: search, allow: []string{"gmail_serch"}, want: false},
+		{name: "unknown names in deny list", tool: search, deny: []string{"nope", "calendar_*"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toolAllowed(tt.tool, tt.readOnly, tt.allow, tt.deny); got != tt.want {
+				t.Errorf("toolAllowed(%s) = %v, want %v", tt.tool.Name, got, tt.want)
+			}
+		})
+	}
+
+	names := []string{"drive_search", "gmail_search", "gmail_send_email"}
+	got := unmatchedToolPatterns(names, []string{"gmail_*", "gmail_serch", "drive_search", "keep_*", "[bad"})
+	if want := []string{"gmail_serch", "keep_*", "[bad"}; !slices.Equal(got, want) {
+		t.Errorf("unmatchedToolPatterns() = %q, want %q", got, want)
+	}
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	blocking := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	stuck := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		time.Sleep(time.Second) // Ignores ctx
+		return mcp.NewToolResultText("late"), nil
+	}
+	quick := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if _, ok := ctx.Deadline(); ok != (request.Params.Name != "unlimited") {
+			return mcp.NewToolResultError("unexpected deadline"), nil
+		}
+		return mcp.NewToolResultText("done"), nil
+	}
+	perTool := map[string]time.Duration{"slow_tool": 20 * time.Millisecond, "unlimited": 0}
+	middleware := timeoutMiddleware(time.Hour, perTool)
+
+	tests := []struct {
+		name    string
+		tool    string
+		handler server.ToolHandlerFunc
+		want    string // Prefix of the result text
+	}{
+		{name: "per-tool limit", tool: "slow_tool", handler: blocking, want: "slow_tool timed out after 20ms"},
+		{name: "handler ignoring ctx", tool: "slow_tool", handler: stuck, want: "slow_tool timed out after 20ms"},
+		{name: "within the default limit", tool: "other_tool", handler: quick, want: "done"},
+		{name: "no limit", tool: "unlimited", handler: quick, want: "done"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			res, err := middleware(tt.handler)(context.Background(), callRequest(tt.tool, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := resultText(res); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("result = %q, want %q", got, tt.want)
+			}
+			if strings.Contains(tt.want, "timed out") {
+				if !res.IsError || !strings.Contains(resultText(res), "-tool-timeouts 'slow_tool=<duration>'") {
+					t.Errorf("timeout result should be an error naming the flag: %+v", res)
+				}
+				if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+					t.Errorf("returned after %v, want soon after the limit", elapsed)
+				}
+			}
+		})
+	}
+
+	// A cancelled call is not reported as a timeout.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := middleware(blocking)(ctx, callRequest("slow_tool", nil)); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled call: err = %v, want context.Canceled", err)
+	}
+}
+
+func TestJSONOutputMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *mcp.CallToolResult
+		want   string // The envelope, as JSON
+	}{
+		{
+			name:   "text result",
+			result: mcp.NewToolResultText("Moved file: a.txt"),
+			want:   `{"tool":"t","ok":true,"text":"Moved file: a.txt"}`,
+		},
+		{
+			name:   "JSON text result",
+			result: mcp.NewToolResultText(`[{"id": "1"}]`),
+			want:   `{"tool":"t","ok":true,"data":[{"id":"1"}]}`,
+		},
+		{
+			name:   "structured result",
+			result: mcp.NewToolResultStructured(map[string]any{"count": 2}, "2 files"),
+			want:   `{"tool":"t","ok":true,"data":{"count":2}}`,
+		},
+		{
+			name:   "error result",
+			result: mcp.NewToolResultError("Failed to move file: not found"),
+			want:   `{"tool":"t","ok":false,"error":"Failed to move file: not found"}`,
+		},
+		{
+			name:   "image kept after the envelope",
+			result: &mcp.CallToolResult{Content: []mcp.Content{mcp.NewTextContent("chart"), mcp.NewImageContent("aGk=", "image/png")}},
+			want:   `{"tool":"t","ok":true,"text":"chart"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := jsonOutputMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tt.result, nil
+			})
+			res, err := handler(context.Background(), callRequest("t", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			text, ok := res.Content[0].(mcp.TextContent)
+			if !ok {
+				t.Fatalf("first content is %T, want the JSON envelope", res.Content[0])
+			}
+			var got, want any
+			if err := json.Unmarshal([]byte(text.Text), &got); err != nil {
+				t.Fatalf("envelope is not JSON: %v\n%s", err, text.Text)
+			}
+			_ = json.Unmarshal([]byte(tt.want), &want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("envelope = %s, want %s", text.Text, tt.want)
+			}
+			if res.IsError != tt.result.IsError || res.StructuredContent == nil {
+				t.Errorf("IsError = %v, structured content %v", res.IsError, res.StructuredContent)
+			}
+			if len(res.Content) != len(tt.result.Content) {
+				t.Errorf("%d contents, want %d", len(res.Content), len(tt.result.Content))
+			}
+		})
+	}
+
+	// Handler errors are protocol errors and pass through unwrapped.
+	boom := errors.New("boom")
+	handler := jsonOutputMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, boom
+	})
+	if res, err := handler(context.Background(), callRequest("t", nil)); res != nil || !errors.Is(err, boom) {
+		t.Errorf("handler error: res %v, err %v", res, err)
+	}
+}
+
+func TestLocalFiles(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	secrets := filepath.Join(root, ".go-google-mcp")
+	for _, dir := range []string{filepath.Join(root, "docs"), secrets} {
+		if err := os.Mkdir(dir, 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{filepath.Join(root, "docs", "a.pdf"), filepath.Join(secrets, "token.json"), filepath.Join(outside, "id_rsa")} {
+		if err := os.WriteFile(f, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for link, target := range map[string]string{"key": filepath.Join(outside, "id_rsa"), "token": filepath.Join(secrets, "token.json")} {
+		if err := os.Symlink(target, filepath.Join(root, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l, err := newLocalFiles(root, secrets, filepath.Join(root, "missing.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{path: filepath.Join(root, "docs", "a.pdf")},
+		{path: filepath.Join(root, "docs", "new.pdf")},
+		{path: filepath.Join(root, "docs", "..", "docs", "a.pdf")},
+		{path: filepath.Join(root, "docs", "..", "..", filepath.Base(outside), "id_rsa"), wantErr: true},
+		{path: filepath.Join(outside, "id_rsa"), wantErr: true},
+		{path: filepath.Join(root, "key"), wantErr: true},
+		{path: filepath.Join(secrets, "token.json"), wantErr: true},
+		{path: filepath.Join(root, "token"), wantErr: true},
+		{path: secrets, wantErr: true},
+		{path: filepath.Join(root, "nope", "a.pdf"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := l.resolve(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolve() = %q, %v, want error %v", got, err, tt.wantErr)
+			}
+			if err == nil && !pathWithin(l.root, got) {
+				t.Errorf("resolve() = %q, outside %s", got, l.root)
+			}
+		})
+	}
+}
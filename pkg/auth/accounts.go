@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+type accountKey struct{}
+
+// WithAccount returns a context whose API calls, when made through an AccountTransport, use the
+// stored token of the named account instead of the transport's default account.
+func WithAccount(ctx context.Context, account string) context.Context {
+	return context.WithValue(ctx, accountKey{}, account)
+}
+
+// AccountFromContext returns the account set with WithAccount, or "".
+func AccountFromContext(ctx context.Context) string {
+	a, _ := ctx.Value(accountKey{}).(string)
+	return a
+}
+
+// AccountTransport authenticates requests with the stored OAuth token of one of several logged-in
+// accounts: the one in the request context (see WithAccount), or the default account. Token sources
+// are created on first use and kept, so each account's token is refreshed and saved independently.
+type AccountTransport struct {
+	base           http.RoundTripper
+	scopes         []string
+	defaultAccount string // "" is the account logged in without --account
+
+	mu      sync.Mutex
+	sources map[string]oauth2.TokenSource
+}
+
+// NewAccountTransport returns a transport using defaultAccount's token unless the request context names
+// another account, sending requests through base (http.DefaultTransport if nil).
+func NewAccountTransport(defaultAccount string, scopes []string, base http.RoundTripper) *AccountTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &AccountTransport{base: base, scopes: scopes, defaultAccount: defaultAccount, sources: make(map[string]oauth2.TokenSource)}
+}
+
+func (t *AccountTransport) source(account string) (oauth2.TokenSource, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ts, ok := t.sources[account]; ok {
+		return ts, nil
+	}
+	ts, err := AccountTokenSource(context.Background(), account, t.scopes)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not logged in (run 'go-google-mcp auth login%s'): %w", accountLabel(account), loginFlag(account), err)
+	}
+	t.sources[account] = ts
+	return ts, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *AccountTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	account := AccountFromContext(req.Context())
+	if account == "" {
+		account = t.defaultAccount
+	}
+	ts, err := t.source(account)
+	if err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, err
+	}
+	return authorize(req, t.base, ts, func(err error) error {
+		return fmt.Errorf("unable to refresh the token of %s, log in again: %w", accountLabel(account), err)
+	})
+}
+
+// accountLabel names an account in messages.
+func accountLabel(account string) string {
+	if account == "" {
+		return "the default account"
+	}
+	return "account " + account
+}
+
+func loginFlag(account string) string {
+	if account == "" {
+		return ""
+	}
+	return " --account " + account
+}
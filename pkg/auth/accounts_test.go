@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestValidateAccountName(t *testing.T) {
+	tests := []struct {
+		account string
+		valid   bool
+	}{
+		{"alice@example.com", true},
+		{"work+mcp@example.co.uk", true},
+		{"personal", true},
+		{"", false},
+		{"..", false},
+		{"../token", false},
+		{"a/b", false},
+		{"alice @example.com", false},
+	}
+	for _, tt := range tests {
+		if err := ValidateAccountName(tt.account); (err == nil) != tt.valid {
+			t.Errorf("ValidateAccountName(%q) = %v, want valid %v", tt.account, err, tt.valid)
+		}
+	}
+}
+
+func TestAccountTransport(t *testing.T) {
+	BaseDir = t.TempDir()
+	defer func() { BaseDir = "" }()
+
+	dir, err := GetConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	secrets := `{"installed":{"client_id":"id","client_secret":"secret","auth_uri":"https://accounts.example.com/auth","token_uri":"https://accounts.example.com/token","redirect_uris":["http://localhost"]}}`
+	if err := os.WriteFile(filepath.Join(dir, SecretsFileName), []byte(secrets), 0600); err != nil {
+		t.Fatal(err)
+	}
+	expiry := time.Now().Add(time.Hour)
+	for account, token := range map[string]string{"": "default-token", "work@example.com": "work-token", "home@example.com": "home-token"} {
+		if err := SaveAccountToken(account, &oauth2.Token{AccessToken: token, TokenType: "Bearer", Expiry: expiry}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	accounts, err := ListAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"home@example.com", "work@example.com"}; !slices.Equal(accounts, want) {
+		t.Errorf("ListAccounts() = %v, want %v", accounts, want)
+	}
+
+	var got string
+	apiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+	}))
+	defer apiSrv.Close()
+
+	client := &http.Client{Transport: NewAccountTransport("work@example.com", nil, nil)}
+	tests := []struct {
+		account string
+		want    string
+	}{
+		{"", "Bearer work-token"},
+		{"home@example.com", "Bearer home-token"},
+		{"work@example.com", "Bearer work-token"},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequestWithContext(WithAccount(context.Background(), tt.account), http.MethodGet, apiSrv.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("account %q: %v", tt.account, err)
+		}
+		_ = resp.Body.Close()
+		if got != tt.want {
+			t.Errorf("account %q: Authorization = %q, want %q", tt.account, got, tt.want)
+		}
+	}
+
+	req, _ := http.NewRequestWithContext(WithAccount(context.Background(), "nobody@example.com"), http.MethodGet, apiSrv.URL, nil)
+	if _, err := client.Do(req); err == nil || !strings.Contains(err.Error(), "auth login --account nobody@example.com") {
+		t.Errorf("unknown account: got %v, want a login hint", err)
+	}
+}
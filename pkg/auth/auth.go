@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/option"
 )
@@ -57,8 +58,52 @@ func NewClient(ctx context.Context, opts ClientOptions) (*http.Client, error) {
 	return nil, nil
 }
 
-// GetClientOptions builds the necessary options for Google API services.
-func GetClientOptions(ctx context.Context, credentialsFile string, scopes []string) ([]option.ClientOption, error) {
+// Authentication modes for GetClientOptions. ModeAuto tries a service account key, then the stored
+// OAuth token, then application default credentials.
+const (
+	ModeAuto           = ""
+	ModeOAuth          = "oauth"           // The token stored by 'auth login'
+	ModeServiceAccount = "service-account" // A service account key file
+	ModeADC            = "adc"             // Application default credentials
+	ModeMetadata       = "metadata"        // The service account attached to a GCE VM or Cloud Run service
+)
+
+// ValidateMode checks an authentication mode name.
+func ValidateMode(mode string) error {
+	switch mode {
+	case ModeAuto, ModeOAuth, ModeServiceAccount, ModeADC, ModeMetadata:
+		return nil
+	}
+	return fmt.Errorf("unknown auth mode %q: must be %s, %s, %s or %s", mode, ModeADC, ModeOAuth, ModeServiceAccount, ModeMetadata)
+}
+
+// GetClientOptions builds the necessary options for Google API services, authenticating as mode says.
+func GetClientOptions(ctx context.Context, mode, credentialsFile string, scopes []string) ([]option.ClientOption, error) {
+	switch mode {
+	case ModeOAuth:
+		tokenSource, err := UserTokenSource(ctx, scopes)
+		if err != nil {
+			return nil, fmt.Errorf("no usable stored token (run 'go-google-mcp auth login'): %w", err)
+		}
+		return []option.ClientOption{option.WithTokenSource(tokenSource)}, nil
+	case ModeServiceAccount:
+		if credentialsFile == "" {
+			return nil, fmt.Errorf("auth mode %s needs a service account key: pass it with -creds", ModeServiceAccount)
+		}
+	case ModeADC:
+		creds, err := google.FindDefaultCredentials(ctx, scopes...)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find default credentials: %w", err)
+		}
+		return []option.ClientOption{option.WithCredentials(creds)}, nil
+	case ModeMetadata:
+		tokenSource, err := MetadataTokenSource(ctx, scopes)
+		if err != nil {
+			return nil, err
+		}
+		return []option.ClientOption{option.WithTokenSource(tokenSource)}, nil
+	}
+
 	var opts []option.ClientOption
 
 	// 1. If explicit file provided, use it (Service Account).
@@ -67,25 +112,14 @@ func GetClientOptions(ctx context.Context, credentialsFile string, scopes []stri
 			return nil, fmt.Errorf("credentials file not found: %s", credentialsFile)
 		}
 		//nolint:staticcheck
-		opts = append(opts, option.WithCredentialsFile(credentialsFile))
+		opts = append(opts, option.WithCredentialsFile(credentialsFile), option.WithScopes(scopes...))
 		return opts, nil
 	}
 
 	// 2. Check if we have a stored User OAuth token.
-	token, err := LoadToken()
-	if err == nil {
-		// We have a token. We also need the client config to refresh it.
-		secrets, err := LoadSecrets()
-		if err == nil {
-			config, err := google.ConfigFromJSON(secrets, scopes...)
-			if err == nil {
-				// We have both. Create a token source.
-				// Note: ConfigFromJSON might default redirect URL, but for token source it matters less.
-				tokenSource := config.TokenSource(ctx, token)
-				opts = append(opts, option.WithTokenSource(tokenSource))
-				return opts, nil
-			}
-		}
+	if tokenSource, err := UserTokenSource(ctx, scopes); err == nil {
+		opts = append(opts, option.WithTokenSource(tokenSource))
+		return opts, nil
 	}
 
 	// 3. Fallback to ADC.
@@ -99,3 +133,29 @@ func GetClientOptions(ctx context.Context, credentialsFile string, scopes []stri
 	opts = append(opts, option.WithScopes(scopes...))
 	return opts, nil
 }
+
+// UserTokenSource returns a token source for the default account's stored OAuth token.
+func UserTokenSource(ctx context.Context, scopes []string) (oauth2.TokenSource, error) {
+	return AccountTokenSource(ctx, "", scopes)
+}
+
+// AccountTokenSource returns a token source for an account's stored OAuth token ("" is the default
+// account), refreshed with the stored client secrets. It fails if either is missing.
+func AccountTokenSource(ctx context.Context, account string, scopes []string) (oauth2.TokenSource, error) {
+	token, err := LoadAccountToken(account)
+	if err != nil {
+		return nil, err
+	}
+	// We also need the client config to refresh the token.
+	secrets, err := LoadSecrets()
+	if err != nil {
+		return nil, err
+	}
+	// Note: ConfigFromJSON might default redirect URL, but for token source it matters less.
+	config, err := google.ConfigFromJSON(secrets, scopes...)
+	if err != nil {
+		return nil, err
+	}
+	save := func(t *oauth2.Token) error { return SaveAccountToken(account, t) }
+	return NewPersistingTokenSource(ctx, config, token, save), nil
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// openURL opens a URL in the user's browser. A variable so tests can replace the browser.
+var openURL = openBrowser
+
+// openBrowser opens url with the platform's URL handler. It only reports whether the handler could be
+// started, not whether a browser actually showed the page.
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			return errors.New("no graphical display")
+		}
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// Reap the handler once it exits
+	go func() {
+		_ = cmd.Wait()
+	}()
+	return nil
+}
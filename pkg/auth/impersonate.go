@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"golang.org/x/oauth2/jwt"
+)
+
+type subjectKey struct{}
+
+// WithSubject returns a context whose API calls, when made through an ImpersonationTransport,
+// act as the given Workspace user instead of the transport's default subject.
+func WithSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, subjectKey{}, subject)
+}
+
+// SubjectFromContext returns the user set with WithSubject, or "".
+func SubjectFromContext(ctx context.Context) string {
+	s, _ := ctx.Value(subjectKey{}).(string)
+	return s
+}
+
+// ImpersonationTransport authenticates requests as a service account acting on behalf of a Workspace
+// user (domain-wide delegation). The user is taken from the request context (see WithSubject), falling
+// back to the default subject; each user gets its own cached, auto-refreshed token.
+type ImpersonationTransport struct {
+	base    http.RoundTripper
+	conf    *jwt.Config
+	subject string
+
+	mu      sync.Mutex
+	sources map[string]oauth2.TokenSource
+}
+
+// NewImpersonationTransport reads the service account key in credentialsFile and returns a transport
+// that impersonates subject by default, sending requests through base (http.DefaultTransport if nil).
+func NewImpersonationTransport(credentialsFile, subject string, scopes []string, base http.RoundTripper) (*ImpersonationTransport, error) {
+	if credentialsFile == "" {
+		return nil, fmt.Errorf("impersonation needs a service account key: pass it with -creds")
+	}
+	key, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read credentials file: %w", err)
+	}
+	conf, err := google.JWTConfigFromJSON(key, scopes...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse service account key (impersonation needs a service account, not OAuth client secrets): %w", err)
+	}
+	if err := ValidateSubject(subject); err != nil {
+		return nil, err
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &ImpersonationTransport{base: base, conf: conf, subject: subject, sources: make(map[string]oauth2.TokenSource)}, nil
+}
+
+// ValidateSubject checks that subject looks like a user's email address.
+func ValidateSubject(subject string) error {
+	if at := strings.Index(subject, "@"); at <= 0 || at == len(subject)-1 || strings.ContainsAny(subject, " \t\n") {
+		return fmt.Errorf("%q is not a user email address", subject)
+	}
+	return nil
+}
+
+func (t *ImpersonationTransport) source(subject string) oauth2.TokenSource {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ts, ok := t.sources[subject]
+	if !ok {
+		conf := *t.conf
+		conf.Subject = subject
+		ts = conf.TokenSource(context.Background())
+		t.sources[subject] = ts
+	}
+	return ts
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *ImpersonationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	subject := SubjectFromContext(req.Context())
+	if subject == "" {
+		subject = t.subject
+	}
+	return authorize(req, t.base, t.source(subject), func(err error) error {
+		return fmt.Errorf("unable to get a token for %s (check that domain-wide delegation is enabled for the service account with these scopes in the Admin console): %w", subject, err)
+	})
+}
+
+// authorize sends req through base with an access token from ts. Token errors are wrapped by wrapErr.
+func authorize(req *http.Request, base http.RoundTripper, ts oauth2.TokenSource, wrapErr func(error) error) (*http.Response, error) {
+	token, err := ts.Token()
+	if err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, wrapErr(err)
+	}
+	r := req.Clone(req.Context())
+	token.SetAuthHeader(r)
+	return base.RoundTrip(r)
+}
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/oauth2/jws"
+)
+
+func TestValidateSubject(t *testing.T) {
+	for _, s := range []string{"alice@example.com", "a.b+c@sub.example.com"} {
+		if err := ValidateSubject(s); err != nil {
+			t.Errorf("ValidateSubject(%q) = %v", s, err)
+		}
+	}
+	for _, s := range []string{"", "alice", "@example.com", "alice@", "alice @example.com"} {
+		if err := ValidateSubject(s); err == nil {
+			t.Errorf("ValidateSubject(%q) should fail", s)
+		}
+	}
+}
+
+func TestImpersonationTransport(t *testing.T) {
+	// Token endpoint: issues a token naming the subject of the signed assertion
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		claims, err := jws.Decode(r.Form.Get("assertion"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token": "token-for-%s", "token_type": "Bearer", "expires_in": 3600}`, claims.Sub)
+	}))
+	defer tokenSrv.Close()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	creds, _ := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "robot@project.iam.gserviceaccount.com",
+		"private_key":  string(pemKey),
+		"token_uri":    tokenSrv.URL,
+	})
+	credsFile := filepath.Join(t.TempDir(), "sa.json")
+	if err := os.WriteFile(credsFile, creds, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var gotAuth []string
+	apiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = append(gotAuth, r.Header.Get("Authorization"))
+	}))
+	defer apiSrv.Close()
+
+	rt, err := NewImpersonationTransport(credsFile, "admin@example.com", []string{"scope"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: rt}
+	for _, ctx := range []context.Context{context.Background(), WithSubject(context.Background(), "bob@example.com")} {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, apiSrv.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	}
+	want := []string{"Bearer token-for-admin@example.com", "Bearer token-for-bob@example.com"}
+	if strings.Join(gotAuth, ",") != strings.Join(want, ",") {
+		t.Errorf("Authorization headers = %v, want %v", gotAuth, want)
+	}
+
+	if _, err := NewImpersonationTransport("", "admin@example.com", nil, nil); err == nil {
+		t.Error("expected an error without a credentials file")
+	}
+}
@@ -1,16 +1,40 @@
 package auth
 
 import (
+	"bufio"
 	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"strings"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 )
 
+// DefaultCallbackPort is the localhost port the browser is redirected to after signing in.
+const DefaultCallbackPort = 8085
+
+// LoginOptions configures Login.
+type LoginOptions struct {
+	Account string // Store the token for this account ("" for the default account)
+	Port    int    // Callback port on localhost; 0 picks a free one (browser flow only)
+
+	// NoBrowser skips the local callback server, for machines without a browser (SSH sessions,
+	// containers): the user signs in on any machine and pastes the address they are redirected to.
+	NoBrowser bool
+	Input     io.Reader // Where the pasted address is read from (default os.Stdin)
+
+	// AddScopes asks for incremental consent: the account's stored token is replaced by one carrying
+	// both its scopes and the requested ones, so only the new scopes are shown to the user.
+	AddScopes bool
+}
+
 // generateStateToken generates a random state token for CSRF protection.
 func generateStateToken() (string, error) {
 	b := make([]byte, 32)
@@ -21,83 +45,190 @@ func generateStateToken() (string, error) {
 }
 
 // Login performs the User OAuth 2.0 flow.
-// It requires clientSecrets (content of the JSON file) and scopes.
-func Login(ctx context.Context, clientSecrets []byte, scopes []string) error {
+// It requires clientSecrets (content of the JSON file) and scopes, and stores the token for opts.Account.
+func Login(ctx context.Context, clientSecrets []byte, scopes []string, opts LoginOptions) error {
 	config, err := google.ConfigFromJSON(clientSecrets, scopes...)
 	if err != nil {
 		return fmt.Errorf("unable to parse client secret file to config: %w", err)
 	}
 
-	// Use a fixed redirect URL for localhost
-	config.RedirectURL = "http://localhost:8085/callback"
-
 	stateToken, err := generateStateToken()
 	if err != nil {
 		return fmt.Errorf("failed to generate state token: %w", err)
 	}
+	// PKCE, so that an intercepted code (e.g. from a pasted address) cannot be redeemed by someone else
+	verifier := oauth2.GenerateVerifier()
+
+	authOpts := []oauth2.AuthCodeOption{oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(verifier)}
+	var previous *oauth2.Token
+	if opts.AddScopes {
+		if previous, err = LoadAccountToken(opts.Account); err != nil {
+			return fmt.Errorf("%s is not logged in, log in without --add-scopes first: %w", accountLabel(opts.Account), err)
+		}
+		authOpts = append(authOpts, oauth2.SetAuthURLParam("include_granted_scopes", "true"))
+	}
 
-	// Create a channel to signal when the token is received
-	codeChan := make(chan string)
-	errChan := make(chan error)
+	var authCode string
+	if opts.NoBrowser {
+		authCode, err = manualCode(ctx, config, stateToken, authOpts, opts)
+	} else {
+		authCode, err = callbackCode(ctx, config, stateToken, authOpts, opts.Port)
+	}
+	if err != nil {
+		return err
+	}
 
-	// Bind strictly to 127.0.0.1 to prevent network exposure
-	server := &http.Server{Addr: "127.0.0.1:8085"}
+	// Exchange code for token
+	token, err := config.Exchange(ctx, authCode, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return fmt.Errorf("unable to retrieve token from web: %w", err)
+	}
+	if previous != nil && token.RefreshToken == "" {
+		// Google does not always issue a new refresh token; the previous one now covers all the scopes
+		token.RefreshToken = previous.RefreshToken
+	}
 
-	http.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
-		// Verify state token to prevent CSRF
-		if r.URL.Query().Get("state") != stateToken {
-			err := fmt.Errorf("state token mismatch")
-			http.Error(w, "State token mismatch", http.StatusBadRequest)
-			errChan <- err
-			return
-		}
+	// Save token
+	if err := SaveAccountToken(opts.Account, token); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
 
-		code := r.URL.Query().Get("code")
-		if code == "" {
-			err := fmt.Errorf("code not found in URL")
-			_, _ = fmt.Fprintf(w, "Error: %s", err)
-			errChan <- err
+	fmt.Println("Authentication successful! Token saved.")
+	return nil
+}
+
+// callbackCode opens the browser on the consent page and receives the authorization code on a local
+// callback server, which the browser is redirected to after the user signs in. Each login has its own
+// server and mux, so logging in several times in one process works.
+func callbackCode(ctx context.Context, config *oauth2.Config, stateToken string, authOpts []oauth2.AuthCodeOption, port int) (string, error) {
+	// Bind strictly to 127.0.0.1 to prevent network exposure
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil && port != 0 {
+		// Desktop OAuth clients accept any localhost port, so a busy port is not fatal
+		fmt.Printf("Port %d is not available (%v), using a free port instead.\n", port, err)
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to listen for the OAuth callback (use --no-browser instead): %w", err)
+	}
+	config.RedirectURL = fmt.Sprintf("http://localhost:%d/callback", listener.Addr().(*net.TCPAddr).Port)
+
+	// Buffered so that repeated callbacks never block the server
+	codeChan := make(chan string, 1)
+	errChan := make(chan error, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		code, err := codeFromQuery(r.URL.Query(), stateToken)
+		if err != nil {
+			http.Error(w, "Error: "+err.Error(), http.StatusBadRequest)
+			select {
+			case errChan <- err:
+			default:
+			}
 			return
 		}
 		_, _ = fmt.Fprintf(w, "Success! You can close this window now.")
-		codeChan <- code
+		select {
+		case codeChan <- code:
+		default:
+		}
 	})
-
+	server := &http.Server{Handler: mux}
 	go func() {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
 			errChan <- err
 		}
 	}()
+	defer func() {
+		_ = server.Shutdown(context.Background())
+	}()
 
-	authURL := config.AuthCodeURL(stateToken, oauth2.AccessTypeOffline)
-	fmt.Printf("Go to the following link in your browser then type the authorization code: \n%v\n", authURL)
+	authURL := config.AuthCodeURL(stateToken, authOpts...)
+	if err := openURL(authURL); err != nil {
+		fmt.Printf("Go to the following link in your browser: \n%v\n", authURL)
+	} else {
+		fmt.Printf("Your browser has been opened to sign in. If it did not open, go to: \n%v\n", authURL)
+	}
 	fmt.Println("Waiting for authentication...")
 
-	// Open browser if possible (user task, but we print link)
-	// You might use "pkg/browser" but printing is safe fallback.
+	select {
+	case code := <-codeChan:
+		return code, nil
+	case err := <-errChan:
+		return "", fmt.Errorf("server error: %w", err)
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// manualCode asks the user to sign in on any machine and paste the address the browser is redirected
+// to. Nothing listens on that address, so the page fails to load, but it carries the code.
+func manualCode(ctx context.Context, config *oauth2.Config, stateToken string, authOpts []oauth2.AuthCodeOption, opts LoginOptions) (string, error) {
+	port := opts.Port
+	if port == 0 {
+		port = DefaultCallbackPort
+	}
+	config.RedirectURL = fmt.Sprintf("http://localhost:%d/callback", port)
+	input := opts.Input
+	if input == nil {
+		input = os.Stdin
+	}
+
+	authURL := config.AuthCodeURL(stateToken, authOpts...)
+	fmt.Printf("Open the following link in a browser on any machine and sign in: \n%v\n", authURL)
+	fmt.Printf("The browser is then sent to %s, which fails to load: that is expected.\n", config.RedirectURL)
+	fmt.Print("Paste the full address from the browser's address bar here: ")
 
-	var authCode string
+	lines := make(chan string, 1)
+	errChan := make(chan error, 1)
+	go func() {
+		line, err := bufio.NewReader(input).ReadString('\n')
+		if err != nil && line == "" {
+			errChan <- fmt.Errorf("unable to read the address: %w", err)
+			return
+		}
+		lines <- line
+	}()
 	select {
-	case authCode = <-codeChan:
+	case line := <-lines:
+		return parsePastedCode(line, stateToken)
 	case err := <-errChan:
-		return fmt.Errorf("server error: %w", err)
+		return "", err
 	case <-ctx.Done():
-		return ctx.Err()
+		return "", ctx.Err()
 	}
+}
 
-	// Exchange code for token
-	token, err := config.Exchange(ctx, authCode)
+// parsePastedCode extracts the authorization code from the pasted redirect address. A bare code is
+// accepted too, as some browsers make copying the full address awkward.
+func parsePastedCode(pasted, stateToken string) (string, error) {
+	pasted = strings.TrimSpace(pasted)
+	if pasted == "" {
+		return "", fmt.Errorf("no address pasted")
+	}
+	if !strings.Contains(pasted, "?") {
+		return pasted, nil
+	}
+	u, err := url.Parse(pasted)
 	if err != nil {
-		return fmt.Errorf("unable to retrieve token from web: %w", err)
+		return "", fmt.Errorf("unable to parse the pasted address: %w", err)
 	}
+	return codeFromQuery(u.Query(), stateToken)
+}
 
-	// Save token
-	if err := SaveToken(token); err != nil {
-		return fmt.Errorf("failed to save token: %w", err)
+// codeFromQuery returns the authorization code of a redirect, after checking its state token.
+func codeFromQuery(query url.Values, stateToken string) (string, error) {
+	if e := query.Get("error"); e != "" {
+		return "", fmt.Errorf("authorization failed: %s", e)
 	}
-
-	fmt.Println("Authentication successful! Token saved.")
-	// Shutdown server
-	_ = server.Shutdown(ctx)
-	return nil
+	// Verify state token to prevent CSRF
+	if query.Get("state") != stateToken {
+		return "", fmt.Errorf("state token mismatch")
+	}
+	code := query.Get("code")
+	if code == "" {
+		return "", fmt.Errorf("code not found in URL")
+	}
+	return code, nil
 }
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParsePastedCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		pasted  string
+		want    string
+		wantErr bool
+	}{
+		{"redirect address", "http://localhost:8085/callback?state=s1&code=4/abc&scope=email\n", "4/abc", false},
+		{"bare code", "  4/abc  \n", "4/abc", false},
+		{"wrong state", "http://localhost:8085/callback?state=other&code=4/abc", "", true},
+		{"access denied", "http://localhost:8085/callback?error=access_denied&state=s1", "", true},
+		{"no code", "http://localhost:8085/callback?state=s1", "", true},
+		{"empty", "\n", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePastedCode(tt.pasted, "s1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePastedCode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parsePastedCode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoginCallback(t *testing.T) {
+	BaseDir = t.TempDir()
+	defer func() { BaseDir = "" }()
+
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "auth-code" || r.FormValue("code_verifier") == "" {
+			http.Error(w, `{"error": "invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token": "new-token", "refresh_token": "refresh", "token_type": "Bearer", "expires_in": 3600}`))
+	}))
+	defer tokenSrv.Close()
+	secrets := []byte(`{"installed":{"client_id":"id","client_secret":"secret","auth_uri":"https://accounts.example.com/auth","token_uri":"` + tokenSrv.URL + `","redirect_uris":["http://localhost"]}}`)
+
+	// The browser: sign in and follow the redirect back to the callback server
+	defer func(old func(string) error) { openURL = old }(openURL)
+	openURL = func(authURL string) error {
+		u, err := url.Parse(authURL)
+		if err != nil {
+			return err
+		}
+		q := u.Query()
+		if q.Get("code_challenge") == "" {
+			t.Error("auth URL has no PKCE code challenge")
+		}
+		go func() {
+			resp, err := http.Get(q.Get("redirect_uri") + "?state=" + url.QueryEscape(q.Get("state")) + "&code=auth-code")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			_ = resp.Body.Close()
+		}()
+		return nil
+	}
+
+	// A busy port falls back to a free one
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = busy.Close() }()
+	busyPort := busy.Addr().(*net.TCPAddr).Port
+
+	// Logging in twice in one process works (each login has its own callback server)
+	for _, account := range []string{"", "work@example.com"} {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := Login(ctx, secrets, []string{"scope"}, LoginOptions{Account: account, Port: busyPort})
+		cancel()
+		if err != nil {
+			t.Fatalf("Login(%q): %v", account, err)
+		}
+		token, err := LoadAccountToken(account)
+		if err != nil || token.AccessToken != "new-token" {
+			t.Errorf("stored token of %q = %v, %v", account, token, err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"cloud.google.com/go/compute/metadata"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// MetadataTokenSource returns a token source for the service account attached to the GCE VM or Cloud
+// Run service the server runs on, from the metadata server. Scopes the account's tokens do not carry
+// are logged, as the tools that need them will fail.
+func MetadataTokenSource(ctx context.Context, scopes []string) (oauth2.TokenSource, error) {
+	checkCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if !metadata.OnGCEWithContext(checkCtx) {
+		return nil, fmt.Errorf("the GCP metadata server is not reachable: auth mode %s only works on Compute Engine, Cloud Run and other GCP runtimes", ModeMetadata)
+	}
+	ts := google.ComputeTokenSource("", scopes...)
+
+	email, err := metadata.EmailWithContext(checkCtx, "default")
+	if err != nil {
+		return nil, fmt.Errorf("no service account is attached to this instance: %w", err)
+	}
+	slog.Info("using the attached service account", "email", email)
+	missing, err := MissingScopes(checkCtx, ts, scopes)
+	if err != nil {
+		slog.Warn("unable to check the attached service account's scopes", "error", err)
+	} else if len(missing) > 0 {
+		slog.Warn("the attached service account's tokens lack scopes; tools needing them will fail (on Compute Engine, set the instance's access scopes)", "missing", missing)
+	}
+	return ts, nil
+}
+
+// MissingScopes returns the scopes a token from ts was not granted.
+func MissingScopes(ctx context.Context, ts oauth2.TokenSource, scopes []string) ([]string, error) {
+	token, err := ts.Token()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get a token: %w", err)
+	}
+	info, err := FetchTokenInfo(ctx, token.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, scope := range scopes {
+		if !slices.Contains(info.Scopes, scope) {
+			missing = append(missing, scope)
+		}
+	}
+	return missing, nil
+}
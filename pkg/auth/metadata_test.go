@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestMissingScopes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"scope": "https://www.googleapis.com/auth/cloud-platform https://www.googleapis.com/auth/drive", "expires_in": "3000"}`))
+	}))
+	defer srv.Close()
+	defer func(old string) { tokenInfoURL = old }(tokenInfoURL)
+	tokenInfoURL = srv.URL
+
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "metadata-token"})
+	missing, err := MissingScopes(context.Background(), ts, []string{"https://www.googleapis.com/auth/drive", "https://www.googleapis.com/auth/gmail.modify"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"https://www.googleapis.com/auth/gmail.modify"}; !slices.Equal(missing, want) {
+		t.Errorf("MissingScopes() = %v, want %v", missing, want)
+	}
+}
+
+func TestGetClientOptionsModes(t *testing.T) {
+	BaseDir = t.TempDir()
+	defer func() { BaseDir = "" }()
+
+	if err := ValidateMode("workload-identity"); err == nil {
+		t.Error("unknown mode: want an error")
+	}
+	if _, err := GetClientOptions(context.Background(), ModeServiceAccount, "", nil); err == nil {
+		t.Error("service-account mode without -creds: want an error")
+	}
+	// oauth mode must not fall back to application default credentials
+	if _, err := GetClientOptions(context.Background(), ModeOAuth, "", nil); err == nil {
+		t.Error("oauth mode without a stored token: want an error")
+	}
+}
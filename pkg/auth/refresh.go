@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// refreshMargin is how long before expiry an access token is refreshed, so that long tool calls
+// do not start with a token about to expire.
+const refreshMargin = 5 * time.Minute
+
+// persistingTokenSource refreshes a user's OAuth token ahead of expiry and saves every refreshed token,
+// so the stored token (and a refresh token Google may have rotated) stays current.
+type persistingTokenSource struct {
+	ctx  context.Context
+	conf *oauth2.Config
+	save func(*oauth2.Token) error
+
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+// NewPersistingTokenSource returns a token source that starts from token, refreshes it with conf
+// refreshMargin before it expires, and passes each refreshed token to save.
+func NewPersistingTokenSource(ctx context.Context, conf *oauth2.Config, token *oauth2.Token, save func(*oauth2.Token) error) oauth2.TokenSource {
+	return &persistingTokenSource{ctx: ctx, conf: conf, save: save, token: token}
+}
+
+func (s *persistingTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token.Valid() && (s.token.Expiry.IsZero() || time.Until(s.token.Expiry) > refreshMargin) {
+		return s.token, nil
+	}
+	// A token without an access token forces the refresh; the refresh token is kept if Google does not rotate it
+	token, err := s.conf.TokenSource(s.ctx, &oauth2.Token{RefreshToken: s.token.RefreshToken}).Token()
+	if err != nil {
+		if s.token.Valid() {
+			slog.Warn("early token refresh failed, using the current token", "error", err)
+			return s.token, nil
+		}
+		return nil, err
+	}
+	s.token = token
+	if err := s.save(token); err != nil {
+		slog.Warn("failed to save refreshed token", "error", err)
+	}
+	return token, nil
+}
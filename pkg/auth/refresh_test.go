@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestPersistingTokenSource(t *testing.T) {
+	var refreshes atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := refreshes.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token": "access-%d", "token_type": "Bearer", "expires_in": 3600}`, n)
+	}))
+	defer srv.Close()
+	conf := &oauth2.Config{ClientID: "id", Endpoint: oauth2.Endpoint{TokenURL: srv.URL}}
+
+	var saved []*oauth2.Token
+	save := func(tok *oauth2.Token) error {
+		saved = append(saved, tok)
+		return nil
+	}
+
+	// Valid for long enough: used as is
+	fresh := &oauth2.Token{AccessToken: "fresh", RefreshToken: "refresh", Expiry: time.Now().Add(time.Hour)}
+	tok, err := NewPersistingTokenSource(context.Background(), conf, fresh, save).Token()
+	if err != nil || tok.AccessToken != "fresh" || len(saved) != 0 {
+		t.Fatalf("fresh token: got %v, %v, saved %d", tok, err, len(saved))
+	}
+
+	// About to expire: refreshed early and saved, keeping the refresh token
+	ts := NewPersistingTokenSource(context.Background(), conf, &oauth2.Token{AccessToken: "old", RefreshToken: "refresh", Expiry: time.Now().Add(time.Minute)}, save)
+	tok, err = ts.Token()
+	if err != nil || tok.AccessToken != "access-1" {
+		t.Fatalf("expiring token: got %v, %v", tok, err)
+	}
+	if len(saved) != 1 || saved[0].RefreshToken != "refresh" {
+		t.Fatalf("saved = %v, want the refreshed token with its refresh token", saved)
+	}
+	if tok, _ := ts.Token(); tok.AccessToken != "access-1" || refreshes.Load() != 1 {
+		t.Errorf("second call refreshed again: %v (%d refreshes)", tok, refreshes.Load())
+	}
+}
+
+func TestPersistingTokenSourceEarlyRefreshFails(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": "temporarily_unavailable"}`, http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	conf := &oauth2.Config{ClientID: "id", Endpoint: oauth2.Endpoint{TokenURL: srv.URL}}
+	save := func(*oauth2.Token) error { return nil }
+
+	// Still valid: the current token is used
+	ts := NewPersistingTokenSource(context.Background(), conf, &oauth2.Token{AccessToken: "old", RefreshToken: "refresh", Expiry: time.Now().Add(time.Minute)}, save)
+	if tok, err := ts.Token(); err != nil || tok.AccessToken != "old" {
+		t.Errorf("got %v, %v, want the current token", tok, err)
+	}
+	// Expired: the error is returned
+	ts = NewPersistingTokenSource(context.Background(), conf, &oauth2.Token{AccessToken: "old", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Minute)}, save)
+	if _, err := ts.Token(); err == nil {
+		t.Error("expected an error for an expired token")
+	}
+}
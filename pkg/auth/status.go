@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Google's OAuth endpoints for inspecting and revoking tokens. Variables so tests can point them elsewhere.
+var (
+	tokenInfoURL = "https://oauth2.googleapis.com/tokeninfo"
+	revokeURL    = "https://oauth2.googleapis.com/revoke"
+)
+
+// TokenInfo is what Google reports about an access token.
+type TokenInfo struct {
+	Email  string // Only known when the token has an email scope
+	Scopes []string
+	Expiry time.Time
+}
+
+// FetchTokenInfo asks Google which account and scopes an access token is for.
+func FetchTokenInfo(ctx context.Context, accessToken string) (*TokenInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenInfoURL+"?access_token="+url.QueryEscape(accessToken), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get token info: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	var body struct {
+		Email     string `json:"email"`
+		Scope     string `json:"scope"`
+		ExpiresIn string `json:"expires_in"`
+		Error     string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("unable to parse token info: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to get token info: %s (HTTP %d)", body.Error, resp.StatusCode)
+	}
+	info := &TokenInfo{Email: body.Email, Scopes: strings.Fields(body.Scope)}
+	if secs, err := strconv.Atoi(body.ExpiresIn); err == nil {
+		info.Expiry = time.Now().Add(time.Duration(secs) * time.Second)
+	}
+	return info, nil
+}
+
+// RevokeToken revokes a refresh or access token at Google, so it cannot be used even if a copy survives.
+// Revoking a refresh token also revokes the access tokens issued with it.
+func RevokeToken(ctx context.Context, token string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, revokeURL, strings.NewReader(url.Values{"token": {token}}.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to revoke token: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return fmt.Errorf("unable to revoke token: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// DeleteToken removes an account's stored token ("" is the default account); token files are overwritten
+// first. A missing token is not an error.
+func DeleteToken(account string) error {
+	return tokens.Delete(account)
+}
+
+// removeFileSecurely overwrites a file with zeros before removing it, so the secret does not linger in
+// the file's old blocks on filesystems that reuse them in place.
+func removeFileSecurely(path string) error {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, make([]byte, info.Size()), 0600); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestFetchTokenInfo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("access_token") != "good" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error_description": "Invalid Value"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"email": "alice@example.com", "scope": "https://www.googleapis.com/auth/drive https://www.googleapis.com/auth/calendar", "expires_in": "3000"}`))
+	}))
+	defer srv.Close()
+	defer func(old string) { tokenInfoURL = old }(tokenInfoURL)
+	tokenInfoURL = srv.URL
+
+	info, err := FetchTokenInfo(context.Background(), "good")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Email != "alice@example.com" || len(info.Scopes) != 2 || info.Expiry.IsZero() {
+		t.Errorf("info = %+v", info)
+	}
+	if _, err := FetchTokenInfo(context.Background(), "bad"); err == nil {
+		t.Error("expected an error for an invalid token")
+	}
+}
+
+func TestRevokeAndDeleteToken(t *testing.T) {
+	var revoked string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		revoked = r.Form.Get("token")
+	}))
+	defer srv.Close()
+	defer func(old string) { revokeURL = old }(revokeURL)
+	revokeURL = srv.URL
+	BaseDir = t.TempDir()
+	defer func() { BaseDir = "" }()
+
+	if err := RevokeToken(context.Background(), "refresh-123"); err != nil || revoked != "refresh-123" {
+		t.Fatalf("RevokeToken: %v, revoked %q", err, revoked)
+	}
+	if err := SaveToken(&oauth2.Token{AccessToken: "a", RefreshToken: "r"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := DeleteToken(""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(BaseDir, ConfigDirName, TokenFileName)); !os.IsNotExist(err) {
+		t.Errorf("token file still exists: %v", err)
+	}
+	if err := DeleteToken(""); err != nil {
+		t.Errorf("deleting a missing token: %v", err)
+	}
+}
@@ -0,0 +1,329 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+// Token storage backends, selected with SetTokenStorage.
+const (
+	StorageFile      = "file"      // Plaintext JSON files in the config directory, readable by the owner only
+	StorageKeychain  = "keychain"  // The OS keychain: macOS Keychain, Secret Service (Linux), Windows Credential Manager
+	StorageEncrypted = "encrypted" // Files in the config directory encrypted with a passphrase (AES-256-GCM)
+)
+
+// keychainService is the service name the tokens are stored under in the OS keychain.
+const keychainService = "go-google-mcp"
+
+// tokenStore keeps the serialized OAuth tokens of the default ("") and named accounts.
+// Load returns an error wrapping fs.ErrNotExist when the account has no token.
+type tokenStore interface {
+	Load(account string) ([]byte, error)
+	Save(account string, data []byte) error
+	Delete(account string) error
+	List() ([]string, error)
+}
+
+// tokens is the storage used by the token functions; file storage unless SetTokenStorage is called.
+var tokens tokenStore = fileStore{}
+
+// SetTokenStorage selects where OAuth tokens are stored: StorageFile (the default, also for ""),
+// StorageKeychain or StorageEncrypted, which needs a passphrase. Tokens already saved as plaintext files
+// are moved to the selected storage the first time they are loaded.
+func SetTokenStorage(kind, passphrase string) error {
+	switch kind {
+	case "", StorageFile:
+		tokens = fileStore{}
+	case StorageKeychain:
+		tokens = keychainStore{}
+	case StorageEncrypted:
+		if passphrase == "" {
+			return fmt.Errorf("encrypted token storage needs a passphrase: set GO_GOOGLE_MCP_TOKEN_KEY")
+		}
+		tokens = encryptedStore{passphrase: passphrase}
+	default:
+		return fmt.Errorf("unknown token storage %q: must be %s, %s or %s", kind, StorageFile, StorageKeychain, StorageEncrypted)
+	}
+	return nil
+}
+
+// fileStore keeps each token in its own file (see tokenPath).
+type fileStore struct{}
+
+func (fileStore) Load(account string) ([]byte, error) {
+	path, err := tokenPath(account)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if isEncrypted(data) {
+		return nil, fmt.Errorf("the stored token of %s is encrypted: select token_storage: %s and set GO_GOOGLE_MCP_TOKEN_KEY", accountLabel(account), StorageEncrypted)
+	}
+	return data, nil
+}
+
+func (fileStore) Save(account string, data []byte) error {
+	path, err := tokenPath(account)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+func (fileStore) Delete(account string) error {
+	path, err := tokenPath(account)
+	if err != nil {
+		return err
+	}
+	return removeFileSecurely(path)
+}
+
+func (fileStore) List() ([]string, error) {
+	dir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, AccountsDirName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var accounts []string
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".json"); ok && !e.IsDir() && ValidateAccountName(name) == nil {
+			accounts = append(accounts, name)
+		}
+	}
+	return accounts, nil
+}
+
+// keychainStore keeps the tokens in the OS keychain, with an index of the named accounts since
+// keychains cannot be listed portably.
+type keychainStore struct{}
+
+const keychainIndex = "accounts"
+
+func keychainUser(account string) string {
+	if account == "" {
+		return "token"
+	}
+	return "token:" + account
+}
+
+func (k keychainStore) Load(account string) ([]byte, error) {
+	secret, err := keyring.Get(keychainService, keychainUser(account))
+	if err == nil {
+		return []byte(secret), nil
+	}
+	if !errors.Is(err, keyring.ErrNotFound) {
+		return nil, fmt.Errorf("unable to read the token from the keychain: %w", err)
+	}
+	// Move a token saved before the keychain was selected
+	data, err := fileStore{}.Load(account)
+	if err != nil {
+		return nil, err
+	}
+	if err := k.Save(account, data); err != nil {
+		return nil, err
+	}
+	if err := (fileStore{}).Delete(account); err != nil {
+		return nil, fmt.Errorf("token moved to the keychain, but unable to delete the token file: %w", err)
+	}
+	return data, nil
+}
+
+func (k keychainStore) Save(account string, data []byte) error {
+	if err := keyring.Set(keychainService, keychainUser(account), string(data)); err != nil {
+		return fmt.Errorf("unable to save the token in the keychain: %w", err)
+	}
+	if account == "" {
+		return nil
+	}
+	accounts, err := k.index()
+	if err != nil || slices.Contains(accounts, account) {
+		return err
+	}
+	return k.setIndex(append(accounts, account))
+}
+
+func (k keychainStore) Delete(account string) error {
+	if err := keyring.Delete(keychainService, keychainUser(account)); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("unable to delete the token from the keychain: %w", err)
+	}
+	if account != "" {
+		accounts, err := k.index()
+		if err != nil {
+			return err
+		}
+		if i := slices.Index(accounts, account); i >= 0 {
+			if err := k.setIndex(slices.Delete(accounts, i, i+1)); err != nil {
+				return err
+			}
+		}
+	}
+	// Also remove a token file left from before the keychain was selected
+	return fileStore{}.Delete(account)
+}
+
+// List includes accounts still in token files; they move to the keychain when first loaded.
+func (k keychainStore) List() ([]string, error) {
+	accounts, err := k.index()
+	if err != nil {
+		return nil, err
+	}
+	files, err := fileStore{}.List()
+	if err != nil {
+		return nil, err
+	}
+	accounts = append(accounts, files...)
+	slices.Sort(accounts)
+	return slices.Compact(accounts), nil
+}
+
+func (keychainStore) index() ([]string, error) {
+	data, err := keyring.Get(keychainService, keychainIndex)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the account list from the keychain: %w", err)
+	}
+	var accounts []string
+	if err := json.Unmarshal([]byte(data), &accounts); err != nil {
+		return nil, fmt.Errorf("invalid account list in the keychain: %w", err)
+	}
+	return accounts, nil
+}
+
+func (keychainStore) setIndex(accounts []string) error {
+	data, err := json.Marshal(accounts)
+	if err != nil {
+		return err
+	}
+	if err := keyring.Set(keychainService, keychainIndex, string(data)); err != nil {
+		return fmt.Errorf("unable to save the account list in the keychain: %w", err)
+	}
+	return nil
+}
+
+// encryptedStore keeps the tokens in the same files as fileStore, encrypted with AES-256-GCM under a key
+// derived from the passphrase with PBKDF2.
+type encryptedStore struct {
+	passphrase string
+}
+
+// pbkdf2Iterations follows the OWASP recommendation for PBKDF2-HMAC-SHA256.
+const pbkdf2Iterations = 600_000
+
+// encryptedToken is the content of an encrypted token file.
+type encryptedToken struct {
+	Cipher     string `json:"cipher"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+const tokenCipher = "pbkdf2-sha256+aes-256-gcm"
+
+// isEncrypted reports whether a token file holds an encrypted token.
+func isEncrypted(data []byte) bool {
+	var e encryptedToken
+	return json.Unmarshal(data, &e) == nil && e.Cipher != ""
+}
+
+func (s encryptedStore) Load(account string) ([]byte, error) {
+	path, err := tokenPath(account)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !isEncrypted(data) {
+		// A token saved before encryption was selected: encrypt it in place
+		if err := s.Save(account, data); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+	var e encryptedToken
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	if e.Cipher != tokenCipher || e.Iterations <= 0 {
+		return nil, fmt.Errorf("the stored token of %s uses an unknown cipher %q", accountLabel(account), e.Cipher)
+	}
+	gcm, err := s.aead(e.Salt, e.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, e.Nonce, e.Data, []byte(account))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt the stored token of %s: wrong GO_GOOGLE_MCP_TOKEN_KEY?", accountLabel(account))
+	}
+	return plain, nil
+}
+
+func (s encryptedStore) Save(account string, data []byte) error {
+	e := encryptedToken{Cipher: tokenCipher, Iterations: pbkdf2Iterations, Salt: make([]byte, 16)}
+	if _, err := rand.Read(e.Salt); err != nil {
+		return err
+	}
+	gcm, err := s.aead(e.Salt, e.Iterations)
+	if err != nil {
+		return err
+	}
+	e.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(e.Nonce); err != nil {
+		return err
+	}
+	// The account is authenticated too, so a token file cannot be swapped for another account's
+	e.Data = gcm.Seal(nil, e.Nonce, data, []byte(account))
+	out, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return fileStore{}.Save(account, out)
+}
+
+func (encryptedStore) Delete(account string) error {
+	return fileStore{}.Delete(account)
+}
+
+func (encryptedStore) List() ([]string, error) {
+	return fileStore{}.List()
+}
+
+func (s encryptedStore) aead(salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, s.passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
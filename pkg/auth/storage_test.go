@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/oauth2"
+)
+
+// useStorage switches the token storage for one test, in a fresh config directory.
+func useStorage(t *testing.T, kind, passphrase string) {
+	t.Helper()
+	BaseDir = t.TempDir()
+	if err := SetTokenStorage(kind, passphrase); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		BaseDir = ""
+		tokens = fileStore{}
+	})
+}
+
+func TestSetTokenStorageErrors(t *testing.T) {
+	defer func() { tokens = fileStore{} }()
+	if err := SetTokenStorage(StorageEncrypted, ""); err == nil {
+		t.Error("encrypted storage without a passphrase: want an error")
+	}
+	if err := SetTokenStorage("vault", ""); err == nil {
+		t.Error("unknown storage: want an error")
+	}
+}
+
+func TestEncryptedStorage(t *testing.T) {
+	useStorage(t, StorageFile, "")
+	// A plaintext token saved before encryption was selected is encrypted in place on first load
+	if err := SaveAccountToken("work@example.com", &oauth2.Token{AccessToken: "plain"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetTokenStorage(StorageEncrypted, "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	if token, err := LoadAccountToken("work@example.com"); err != nil || token.AccessToken != "plain" {
+		t.Fatalf("migrated token = %v, %v", token, err)
+	}
+	path := filepath.Join(BaseDir, ConfigDirName, AccountsDirName, "work@example.com.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "plain") || !isEncrypted(data) {
+		t.Errorf("token file is not encrypted: %s", data)
+	}
+
+	if err := SaveToken(&oauth2.Token{AccessToken: "secret-token"}); err != nil {
+		t.Fatal(err)
+	}
+	if token, err := LoadToken(); err != nil || token.AccessToken != "secret-token" {
+		t.Errorf("LoadToken() = %v, %v", token, err)
+	}
+	if accounts, err := ListAccounts(); err != nil || !slices.Equal(accounts, []string{"work@example.com"}) {
+		t.Errorf("ListAccounts() = %v, %v", accounts, err)
+	}
+
+	// A token file moved to another account fails authentication
+	if err := os.WriteFile(filepath.Join(BaseDir, ConfigDirName, TokenFileName), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadToken(); err == nil {
+		t.Error("token of another account: want a decryption error")
+	}
+	_ = SetTokenStorage(StorageEncrypted, "wrong")
+	if _, err := LoadAccountToken("work@example.com"); err == nil || !strings.Contains(err.Error(), "GO_GOOGLE_MCP_TOKEN_KEY") {
+		t.Errorf("wrong passphrase: got %v", err)
+	}
+	_ = SetTokenStorage(StorageFile, "")
+	if _, err := LoadAccountToken("work@example.com"); err == nil || !strings.Contains(err.Error(), "encrypted") {
+		t.Errorf("file storage reading an encrypted token: got %v", err)
+	}
+}
+
+func TestKeychainStorage(t *testing.T) {
+	keyring.MockInit()
+	useStorage(t, StorageFile, "")
+	// A token file saved before the keychain was selected moves to the keychain on first load
+	if err := SaveToken(&oauth2.Token{AccessToken: "from-file"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetTokenStorage(StorageKeychain, ""); err != nil {
+		t.Fatal(err)
+	}
+	if token, err := LoadToken(); err != nil || token.AccessToken != "from-file" {
+		t.Fatalf("migrated token = %v, %v", token, err)
+	}
+	if _, err := os.Stat(filepath.Join(BaseDir, ConfigDirName, TokenFileName)); !os.IsNotExist(err) {
+		t.Errorf("token file still exists after moving to the keychain: %v", err)
+	}
+	if token, err := LoadToken(); err != nil || token.AccessToken != "from-file" {
+		t.Errorf("token from the keychain = %v, %v", token, err)
+	}
+
+	for _, account := range []string{"work@example.com", "home@example.com"} {
+		if err := SaveAccountToken(account, &oauth2.Token{AccessToken: account}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if accounts, err := ListAccounts(); err != nil || !slices.Equal(accounts, []string{"home@example.com", "work@example.com"}) {
+		t.Errorf("ListAccounts() = %v, %v", accounts, err)
+	}
+	if err := DeleteToken("work@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadAccountToken("work@example.com"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("deleted token: got %v, want fs.ErrNotExist", err)
+	}
+	if accounts, err := ListAccounts(); err != nil || !slices.Equal(accounts, []string{"home@example.com"}) {
+		t.Errorf("ListAccounts() after delete = %v, %v", accounts, err)
+	}
+}
@@ -2,8 +2,10 @@ package auth
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"golang.org/x/oauth2"
 )
@@ -12,6 +14,7 @@ const (
 	ConfigDirName   = ".go-google-mcp"
 	TokenFileName   = "token.json"
 	SecretsFileName = "client_secrets.json"
+	AccountsDirName = "accounts"
 )
 
 // BaseDir allows overriding the home directory for testing purposes.
@@ -44,48 +47,91 @@ func GetConfigDir() (string, error) {
 	return dir, nil
 }
 
-// SaveToken saves the OAuth2 token to disk.
+// SaveToken saves the OAuth2 token of the default account to disk.
 func SaveToken(token *oauth2.Token) error {
-	dir, err := GetConfigDir()
+	return SaveAccountToken("", token)
+}
+
+// SaveAccountToken saves an account's OAuth2 token ("" is the default account) in the token storage.
+// Token files are replaced atomically, readable by the owner only, so a server refreshing the token
+// cannot leave a truncated file behind.
+func SaveAccountToken(account string, token *oauth2.Token) error {
+	data, err := json.Marshal(token)
 	if err != nil {
 		return err
 	}
-	path := filepath.Join(dir, TokenFileName)
+	return tokens.Save(account, data)
+}
 
-	f, err := os.Create(path)
+// writeFileAtomic writes data to a temporary file with 0600 permissions and renames it over path.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
 	if err != nil {
 		return err
 	}
 	defer func() {
-		_ = f.Close()
+		_ = os.Remove(f.Name())
 	}()
-
-	return json.NewEncoder(f).Encode(token)
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
 }
 
-// LoadToken loads the OAuth2 token from disk.
+// LoadToken loads the OAuth2 token of the default account from disk.
 func LoadToken() (*oauth2.Token, error) {
-	dir, err := GetConfigDir()
-	if err != nil {
-		return nil, err
-	}
-	path := filepath.Join(dir, TokenFileName)
+	return LoadAccountToken("")
+}
 
-	f, err := os.Open(path)
+// LoadAccountToken loads an account's OAuth2 token from the token storage ("" is the default account).
+func LoadAccountToken(account string) (*oauth2.Token, error) {
+	data, err := tokens.Load(account)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		_ = f.Close()
-	}()
-
 	var token oauth2.Token
-	if err := json.NewDecoder(f).Decode(&token); err != nil {
+	if err := json.Unmarshal(data, &token); err != nil {
 		return nil, err
 	}
 	return &token, nil
 }
 
+// tokenPath returns where an account's token is stored: token.json for the default account (""),
+// accounts/<name>.json for named accounts.
+func tokenPath(account string) (string, error) {
+	dir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if account == "" {
+		return filepath.Join(dir, TokenFileName), nil
+	}
+	if err := ValidateAccountName(account); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, AccountsDirName, account+".json"), nil
+}
+
+// ValidateAccountName checks that an account name, usually its email address, can name a token file.
+func ValidateAccountName(account string) error {
+	if account == "" || account == "." || account == ".." || strings.IndexFunc(account, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("@._+-", r))
+	}) >= 0 {
+		return fmt.Errorf("invalid account name %q: use the account's email address or letters, digits and @._+-", account)
+	}
+	return nil
+}
+
+// ListAccounts returns the names of the accounts logged in with 'auth login --account', sorted.
+// The default account is not included.
+func ListAccounts() ([]string, error) {
+	return tokens.List()
+}
+
 // SaveSecrets copies the client secrets file to the config dir.
 func SaveSecrets(srcPath string) error {
 	content, err := os.ReadFile(srcPath)
@@ -50,6 +50,13 @@ func TestStore(t *testing.T) {
 		if loaded.AccessToken != token.AccessToken {
 			t.Errorf("expected %s, got %s", token.AccessToken, loaded.AccessToken)
 		}
+		info, err := os.Stat(filepath.Join(tmpDir, ConfigDirName, TokenFileName))
+		if err != nil {
+			t.Fatalf("token file missing: %v", err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("token file permissions = %o, want 600", perm)
+		}
 	})
 
 	t.Run("SaveAndLoadSecrets", func(t *testing.T) {
@@ -0,0 +1,121 @@
+// Package config loads the server's settings file (by default ~/.go-google-mcp/config.yaml), so that
+// deployments do not need long command lines. Command-line flags and environment variables take
+// precedence over the file.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the settings file in the configuration directory.
+const FileName = "config.yaml"
+
+// Config is the content of the settings file. Unset fields leave the corresponding flag at its default.
+type Config struct {
+	AuthMode     string            `yaml:"auth_mode"`       // -auth-mode: adc, oauth, service-account or metadata
+	Credentials  string            `yaml:"credentials"`     // -creds
+	Workspace    *bool             `yaml:"workspace"`       // -workspace
+	Account      string            `yaml:"default_account"` // -account: the stored account (or alias) tools use by default
+	TokenStorage string            `yaml:"token_storage"`   // -token-storage: file, keychain or encrypted
+	Impersonate  string            `yaml:"impersonate"`     // -impersonate: the Workspace user to act as
+	Services     []string          `yaml:"services"`        // -services
+	ReadOnly     *bool             `yaml:"read_only"`       // -read-only
+	AllowTools   []string          `yaml:"allow_tools"`     // -allow-tools
+	DenyTools    []string          `yaml:"deny_tools"`      // -deny-tools
+	Confirm      *bool             `yaml:"confirm"`         // -confirm
+	LocalRoot    string            `yaml:"local_root"`      // -local-root: the directory local file tools are limited to
+	Output       string            `yaml:"output"`          // -output
+	Timeout      string            `yaml:"timeout"`         // -timeout, e.g. "45s"
+	ToolTimeouts map[string]string `yaml:"tool_timeouts"`   // -tool-timeouts, tool name to duration
+	LogLevel     string            `yaml:"log_level"`       // -log-level
+	LogFile      string            `yaml:"log_file"`        // -log-file
+	MetricsAddr  string            `yaml:"metrics_addr"`    // -metrics-addr
+
+	// Settings without a flag.
+	AccountAliases map[string]string    `yaml:"account_aliases"` // Short names for stored accounts, e.g. work: alice@example.com
+	ResourcesTTL   time.Duration        `yaml:"resources_ttl"`   // How long the list of recent Docs and Sheets resources is reused
+	Retries        Retries              `yaml:"retries"`
+	RateLimits     map[string]RateLimit `yaml:"rate_limits"` // Per-API request rates keyed by API name (drive, gmail, sheets...); "default" for the others
+}
+
+// Retries configures retries of rate-limited and failed Google API requests.
+type Retries struct {
+	Max         int           `yaml:"max"`          // Retries after the first attempt
+	BaseBackoff time.Duration `yaml:"base_backoff"` // First backoff, doubled on each retry
+	MaxBackoff  time.Duration `yaml:"max_backoff"`  // Longest single wait
+}
+
+// RateLimit is a token bucket rate limit for one API.
+type RateLimit struct {
+	PerSecond float64 `yaml:"per_second"`
+	Burst     int     `yaml:"burst"`
+}
+
+// Load reads the settings file at path. Unknown keys are rejected so that typos do not go unnoticed.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read config file: %w", err)
+	}
+	var cfg Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	for api, l := range cfg.RateLimits {
+		if l.PerSecond < 0 || l.Burst < 0 {
+			return nil, fmt.Errorf("invalid config file %s: rate_limits.%s must not be negative", path, api)
+		}
+	}
+	return &cfg, nil
+}
+
+// Flags returns the settings that have a command-line flag, as flag values keyed by flag name.
+func (c *Config) Flags() map[string]string {
+	flags := make(map[string]string)
+	setString := func(name, v string) {
+		if v != "" {
+			flags[name] = v
+		}
+	}
+	setBool := func(name string, v *bool) {
+		if v != nil {
+			flags[name] = strconv.FormatBool(*v)
+		}
+	}
+	setString("auth-mode", c.AuthMode)
+	setString("creds", c.Credentials)
+	setBool("workspace", c.Workspace)
+	setString("account", c.Account)
+	setString("token-storage", c.TokenStorage)
+	setString("impersonate", c.Impersonate)
+	setString("services", strings.Join(c.Services, ","))
+	setBool("read-only", c.ReadOnly)
+	setString("allow-tools", strings.Join(c.AllowTools, ","))
+	setString("deny-tools", strings.Join(c.DenyTools, ","))
+	setBool("confirm", c.Confirm)
+	setString("local-root", c.LocalRoot)
+	setString("output", c.Output)
+	setString("timeout", c.Timeout)
+	var timeouts []string
+	for _, tool := range slices.Sorted(maps.Keys(c.ToolTimeouts)) {
+		timeouts = append(timeouts, tool+"="+c.ToolTimeouts[tool])
+	}
+	setString("tool-timeouts", strings.Join(timeouts, ","))
+	setString("log-level", c.LogLevel)
+	setString("log-file", c.LogFile)
+	setString("metrics-addr", c.MetricsAddr)
+	return flags
+}
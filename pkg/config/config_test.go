@@ -0,0 +1,91 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, `
+credentials: /etc/go-google-mcp/sa.json
+impersonate: admin@example.com
+token_storage: keychain
+default_account: work
+account_aliases:
+  work: alice@example.com
+services: [drive, calendar]
+read_only: false
+deny_tools:
+  - "*_delete_*"
+  - gmail_send_*
+timeout: 45s
+tool_timeouts:
+  sheets_query: 1m
+  drive_download_file: 10m
+resources_ttl: 2m
+retries:
+  max: 3
+  max_backoff: 10s
+rate_limits:
+  sheets: {per_second: 0.5, burst: 5}
+  default: {per_second: 20, burst: 40}
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ResourcesTTL != 2*time.Minute || cfg.Retries.Max != 3 || cfg.Retries.MaxBackoff != 10*time.Second {
+		t.Errorf("durations and retries = %v, %+v", cfg.ResourcesTTL, cfg.Retries)
+	}
+	if cfg.AccountAliases["work"] != "alice@example.com" {
+		t.Errorf("account aliases = %v", cfg.AccountAliases)
+	}
+	if cfg.RateLimits["sheets"] != (RateLimit{PerSecond: 0.5, Burst: 5}) {
+		t.Errorf("sheets rate limit = %+v", cfg.RateLimits["sheets"])
+	}
+	want := map[string]string{
+		"creds":         "/etc/go-google-mcp/sa.json",
+		"impersonate":   "admin@example.com",
+		"token-storage": "keychain",
+		"account":       "work",
+		"services":      "drive,calendar",
+		"read-only":     "false",
+		"deny-tools":    "*_delete_*,gmail_send_*",
+		"timeout":       "45s",
+		"tool-timeouts": "drive_download_file=10m,sheets_query=1m",
+	}
+	if got := cfg.Flags(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Flags() = %v, want %v", got, want)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file: got %v, want fs.ErrNotExist", err)
+	}
+	if _, err := Load(writeConfig(t, "readonly: true\n")); err == nil || !strings.Contains(err.Error(), "readonly") {
+		t.Errorf("unknown key: got %v", err)
+	}
+	if _, err := Load(writeConfig(t, "rate_limits:\n  gmail: {per_second: -1}\n")); err == nil {
+		t.Error("negative rate limit should fail")
+	}
+	cfg, err := Load(writeConfig(t, ""))
+	if err != nil || len(cfg.Flags()) != 0 {
+		t.Errorf("empty file: %v, %v", cfg, err)
+	}
+}
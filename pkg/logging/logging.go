@@ -0,0 +1,127 @@
+// Package logging sets up the server's structured logs (log/slog) and keeps email addresses
+// and credentials out of them.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ParseLevel parses a level name: debug, info, warn or error.
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q (use debug, info, warn or error)", s)
+	}
+	return level, nil
+}
+
+// Open returns the log destination: the file at path, appended to, or stderr when path is empty.
+// The server speaks MCP on stdout, so logs never go there.
+func Open(path string) (io.WriteCloser, error) {
+	if path == "" {
+		return nopCloser{os.Stderr}, nil
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open log file: %w", err)
+	}
+	return f, nil
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// New returns a text logger writing records at or above level to w, with Redact applied to messages and attributes.
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(redactingHandler{slog.NewTextHandler(w, &slog.HandlerOptions{Level: level})})
+}
+
+var (
+	emailPattern = regexp.MustCompile(`([A-Za-z0-9._%+-])[A-Za-z0-9._%+-]*@((?:[A-Za-z0-9-]+\.)+[A-Za-z]{2,})`)
+	// secretPatterns match OAuth access and refresh tokens, bearer headers, JWTs, client secrets and
+	// key=value credentials.
+	secretPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`ya29\.[\w.-]+`),
+		regexp.MustCompile(`1//[\w.-]{20,}`),
+		regexp.MustCompile(`(?i)bearer\s+[\w.~+/-]+=*`),
+		regexp.MustCompile(`eyJ[\w-]+\.[\w-]+\.[\w-]+`),
+		regexp.MustCompile(`GOCSPX-[\w-]+`),
+		regexp.MustCompile(`(?i)((?:access|refresh|id)_token|client_secret|password|code)(["']?\s*[:=]\s*["']?)[^\s"'&,}]+`),
+	}
+)
+
+// Redact masks email addresses (keeping the first letter and the domain, e.g. a***@example.com)
+// and replaces tokens and secrets with [REDACTED].
+func Redact(s string) string {
+	for i, p := range secretPatterns {
+		if i == len(secretPatterns)-1 {
+			s = p.ReplaceAllString(s, "${1}${2}[REDACTED]")
+		} else {
+			s = p.ReplaceAllString(s, "[REDACTED]")
+		}
+	}
+	return emailPattern.ReplaceAllString(s, "${1}***@${2}")
+}
+
+// redactingHandler applies Redact to every message and attribute before passing records on.
+type redactingHandler struct {
+	slog.Handler
+}
+
+func (h redactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, Redact(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.Handler.Handle(ctx, out)
+}
+
+func (h redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redactAttr(a)
+	}
+	return redactingHandler{h.Handler.WithAttrs(redacted)}
+}
+
+func (h redactingHandler) WithGroup(name string) slog.Handler {
+	return redactingHandler{h.Handler.WithGroup(name)}
+}
+
+func redactAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, Redact(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		attrs := make([]any, len(group))
+		for i, g := range group {
+			attrs[i] = redactAttr(g)
+		}
+		return slog.Group(a.Key, attrs...)
+	case slog.KindAny:
+		s := fmt.Sprint(v.Any())
+		if err, ok := v.Any().(error); ok {
+			s = err.Error()
+		}
+		return slog.String(a.Key, Redact(s))
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+// Truncate shortens s to at most n runes for logging, marking the cut.
+func Truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return strings.TrimSpace(string(r[:n])) + "..."
+	}
+	return s
+}
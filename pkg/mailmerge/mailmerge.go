@@ -0,0 +1,293 @@
+// Package mailmerge sends personalized emails to the rows of a spreadsheet: each row fills the
+// {{placeholders}} of a subject and body template, and the outcome is written back to a status column.
+package mailmerge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	gmailsvc "github.com/matheusbuniotto/go-google-mcp/pkg/services/gmail"
+	sheetssvc "github.com/matheusbuniotto/go-google-mcp/pkg/services/sheets"
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/sheets/v4"
+)
+
+// Sheet reads the recipients and writes their status; *sheets.SheetsService implements it.
+type Sheet interface {
+	ReadValues(ctx context.Context, spreadsheetId string, rangeName string, opts sheetssvc.ReadOptions) ([][]interface{}, error)
+	UpdateValues(ctx context.Context, spreadsheetId string, rangeName string, valuesJSON string) (*sheets.UpdateValuesResponse, error)
+}
+
+// Mailer creates the drafts or sends the emails; *gmail.GmailService implements it.
+type Mailer interface {
+	CreateDraft(ctx context.Context, to string, subject string, body string, htmlBody string) (*gmail.Draft, error)
+	SendEmail(ctx context.Context, to string, subject string, body string, htmlBody string, attachments ...gmailsvc.OutgoingAttachment) (*gmail.Message, error)
+}
+
+// Defaults of Options.
+const (
+	DefaultEmailColumn  = "email"
+	DefaultStatusColumn = "merge_status"
+	DefaultMaxEmails    = 50
+)
+
+// Options configures a mail merge.
+type Options struct {
+	SpreadsheetID string
+	Range         string // A1 range whose first row names the columns, e.g. "Contacts!A1:E200"
+	Subject       string // Template: {{column}} is replaced by the row's value in that column
+	Body          string // Plain text template
+	HTMLBody      string // Optional HTML template; values are HTML-escaped
+
+	EmailColumn  string // Column holding the recipient address (default DefaultEmailColumn)
+	StatusColumn string // Column the outcome is written to, added after the last column if missing (default DefaultStatusColumn)
+
+	Send      bool          // Send the emails rather than creating drafts
+	MaxEmails int           // Cap on the rows processed in this run, so large lists go out over several runs (default DefaultMaxEmails)
+	Delay     time.Duration // Pause between two sent emails
+	DryRun    bool          // Render the emails without creating, sending or writing anything
+}
+
+// RowResult is the outcome of one row.
+type RowResult struct {
+	Row     int    `json:"row"` // 1-based spreadsheet row
+	To      string `json:"to"`
+	Status  string `json:"status"`
+	Subject string `json:"subject,omitempty"` // Set for dry runs
+	Body    string `json:"body,omitempty"`    // Set for dry runs
+}
+
+// Result summarizes a mail merge run.
+type Result struct {
+	Done      []RowResult `json:"done"`                // Drafted, sent or, for dry runs, rendered
+	Failed    []RowResult `json:"failed,omitempty"`    // Rows with an invalid address or whose draft or email failed, with the error as status
+	Skipped   int         `json:"skipped"`             // Rows already processed by an earlier run
+	NoEmail   int         `json:"no_email"`            // Rows without an address
+	Remaining int         `json:"remaining,omitempty"` // Rows left for a later run because of MaxEmails
+}
+
+// placeholderPattern matches {{column}} placeholders, capturing the column name.
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([^{}\n]+?)\s*\}\}`)
+
+// Placeholders returns the distinct column names used by templates, in order of appearance.
+func Placeholders(templates ...string) []string {
+	var out []string
+	for _, t := range templates {
+		for _, m := range placeholderPattern.FindAllStringSubmatch(t, -1) {
+			if !slices.Contains(out, m[1]) {
+				out = append(out, m[1])
+			}
+		}
+	}
+	return out
+}
+
+// render fills a template with a row's values; escape, if set, is applied to each value.
+func render(template string, columns map[string]string, escape func(string) string) string {
+	return placeholderPattern.ReplaceAllStringFunc(template, func(m string) string {
+		v := columns[strings.ToLower(placeholderPattern.FindStringSubmatch(m)[1])]
+		if escape != nil {
+			v = escape(v)
+		}
+		return v
+	})
+}
+
+// processed reports whether a status cell shows that an earlier run drafted or sent the row's email.
+// Failed rows are retried; clear a row's status to process it again.
+func processed(status string) bool {
+	return strings.HasPrefix(status, "draft") || strings.HasPrefix(status, "sent")
+}
+
+// Run merges the rows of the range. Each row's status is written as soon as its draft or email is done,
+// so a run that is interrupted, or hits MaxEmails, is continued by running it again.
+func Run(ctx context.Context, sheet Sheet, mailer Mailer, opts Options) (*Result, error) {
+	if opts.SpreadsheetID == "" || opts.Range == "" {
+		return nil, fmt.Errorf("spreadsheet_id and range are required")
+	}
+	if strings.TrimSpace(opts.Subject) == "" || strings.TrimSpace(opts.Body) == "" && strings.TrimSpace(opts.HTMLBody) == "" {
+		return nil, fmt.Errorf("subject and body are required")
+	}
+	if opts.EmailColumn == "" {
+		opts.EmailColumn = DefaultEmailColumn
+	}
+	if opts.StatusColumn == "" {
+		opts.StatusColumn = DefaultStatusColumn
+	}
+	if opts.MaxEmails <= 0 {
+		opts.MaxEmails = DefaultMaxEmails
+	}
+
+	title, gr, err := sheetssvc.ParseA1(opts.Range)
+	if err != nil {
+		return nil, err
+	}
+	values, err := sheet.ReadValues(ctx, opts.SpreadsheetID, readRange(title, gr, opts.Range), sheetssvc.ReadOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("range %s is empty: its first row must name the columns", opts.Range)
+	}
+
+	var header []string
+	for _, v := range values[0] {
+		header = append(header, strings.TrimSpace(fmt.Sprint(v)))
+	}
+	column := func(name string) int {
+		return slices.IndexFunc(header, func(h string) bool { return strings.EqualFold(h, name) })
+	}
+	emailCol := column(opts.EmailColumn)
+	if emailCol < 0 {
+		return nil, fmt.Errorf("no %q column in the first row (have: %s)", opts.EmailColumn, strings.Join(header, ", "))
+	}
+	var unknown []string
+	for _, p := range Placeholders(opts.Subject, opts.Body, opts.HTMLBody) {
+		if column(p) < 0 {
+			unknown = append(unknown, p)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("placeholders without a column: %s (have: %s)", strings.Join(unknown, ", "), strings.Join(header, ", "))
+	}
+
+	headerRow, firstCol := int(gr.StartRowIndex), int(gr.StartColumnIndex)
+	statusCol := column(opts.StatusColumn)
+	if statusCol < 0 {
+		statusCol = len(header)
+		if !opts.DryRun {
+			if err := writeCell(ctx, sheet, opts.SpreadsheetID, title, headerRow, firstCol+statusCol, opts.StatusColumn); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	res := &Result{}
+	count := 0
+	for i, row := range values[1:] {
+		cell := func(col int) string {
+			if col < len(row) {
+				return strings.TrimSpace(fmt.Sprint(row[col]))
+			}
+			return ""
+		}
+		sheetRow := headerRow + i + 1 // 0-based
+		to := cell(emailCol)
+		switch {
+		case processed(cell(statusCol)):
+			res.Skipped++
+			continue
+		case to == "":
+			res.NoEmail++
+			continue
+		}
+		// The address goes into the To header: refuse anything but a single address, so that a cell
+		// cannot add recipients or headers.
+		addr, err := mail.ParseAddress(to)
+		if err != nil {
+			r := RowResult{Row: sheetRow + 1, To: to, Status: "invalid address: " + err.Error()}
+			if !opts.DryRun {
+				_ = writeCell(ctx, sheet, opts.SpreadsheetID, title, sheetRow, firstCol+statusCol, r.Status)
+			}
+			res.Failed = append(res.Failed, r)
+			continue
+		}
+		if addr.Name == "" {
+			to = addr.Address
+		} else {
+			to = addr.String()
+		}
+		if count == opts.MaxEmails {
+			res.Remaining++
+			continue
+		}
+		if count > 0 && opts.Send && opts.Delay > 0 && !opts.DryRun {
+			select {
+			case <-time.After(opts.Delay):
+			case <-ctx.Done():
+				return res, ctx.Err()
+			}
+		}
+		count++
+
+		columns := make(map[string]string, len(header))
+		for c, h := range header {
+			columns[strings.ToLower(h)] = cell(c)
+		}
+		subject := render(opts.Subject, columns, nil)
+		body := render(opts.Body, columns, nil)
+		htmlBody := render(opts.HTMLBody, columns, html.EscapeString)
+		r := RowResult{Row: sheetRow + 1, To: to}
+		if opts.DryRun {
+			r.Status, r.Subject, r.Body = "dry run", subject, body
+			res.Done = append(res.Done, r)
+			continue
+		}
+
+		now := time.Now().UTC().Format(time.RFC3339)
+		if opts.Send {
+			_, err = mailer.SendEmail(ctx, to, subject, body, htmlBody)
+			r.Status = "sent " + now
+		} else {
+			_, err = mailer.CreateDraft(ctx, to, subject, body, htmlBody)
+			r.Status = "draft " + now
+		}
+		if err != nil {
+			r.Status = "failed: " + err.Error()
+		}
+		if werr := writeCell(ctx, sheet, opts.SpreadsheetID, title, sheetRow, firstCol+statusCol, r.Status); werr != nil {
+			if err == nil {
+				// Stop: without its status, the row would be drafted or sent again by the next run
+				res.Done = append(res.Done, r)
+				return res, fmt.Errorf("row %d was processed but its status could not be written, mark it by hand before running again: %w", r.Row, werr)
+			}
+		}
+		if err != nil {
+			res.Failed = append(res.Failed, r)
+			continue
+		}
+		res.Done = append(res.Done, r)
+	}
+	return res, nil
+}
+
+// readRange returns the range to read: the given one, widened by a column when it has an end column, so that
+// the status column added after its last column is read back by later runs.
+func readRange(title string, gr *sheets.GridRange, a1 string) string {
+	if gr.EndColumnIndex == 0 {
+		return a1
+	}
+	end := sheetssvc.ColumnName(int(gr.EndColumnIndex))
+	if gr.EndRowIndex > 0 {
+		end += strconv.Itoa(int(gr.EndRowIndex))
+	}
+	return sheetPrefix(title) + sheetssvc.ColumnName(int(gr.StartColumnIndex)) + strconv.Itoa(int(gr.StartRowIndex)+1) + ":" + end
+}
+
+func sheetPrefix(title string) string {
+	if title == "" {
+		return ""
+	}
+	return sheetssvc.GridRangeA1(title, nil) + "!"
+}
+
+// writeCell writes a value to the cell at the 0-based row and column.
+func writeCell(ctx context.Context, sheet Sheet, spreadsheetID, title string, row, col int, value string) error {
+	data, err := json.Marshal([]interface{}{value})
+	if err != nil {
+		return err
+	}
+	a1 := fmt.Sprintf("%s%s%d", sheetPrefix(title), sheetssvc.ColumnName(col), row+1)
+	if _, err := sheet.UpdateValues(ctx, spreadsheetID, a1, string(data)); err != nil {
+		return fmt.Errorf("unable to write status to %s: %w", a1, err)
+	}
+	return nil
+}
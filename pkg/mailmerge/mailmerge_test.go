@@ -0,0 +1,182 @@
+package mailmerge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	gmailsvc "github.com/matheusbuniotto/go-google-mcp/pkg/services/gmail"
+	sheetssvc "github.com/matheusbuniotto/go-google-mcp/pkg/services/sheets"
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/sheets/v4"
+)
+
+type fakeSheet struct {
+	read   string
+	values [][]interface{}
+	writes map[string]string
+}
+
+func (f *fakeSheet) ReadValues(ctx context.Context, spreadsheetId string, rangeName string, opts sheetssvc.ReadOptions) ([][]interface{}, error) {
+	f.read = rangeName
+	return f.values, nil
+}
+
+func (f *fakeSheet) UpdateValues(ctx context.Context, spreadsheetId string, rangeName string, valuesJSON string) (*sheets.UpdateValuesResponse, error) {
+	var row []string
+	if err := json.Unmarshal([]byte(valuesJSON), &row); err != nil {
+		return nil, err
+	}
+	f.writes[rangeName] = row[0]
+	return &sheets.UpdateValuesResponse{}, nil
+}
+
+type sentEmail struct{ to, subject, body, html string }
+
+type fakeMailer struct {
+	drafts, sent []sentEmail
+	failFor      string
+}
+
+func (f *fakeMailer) CreateDraft(ctx context.Context, to string, subject string, body string, htmlBody string) (*gmail.Draft, error) {
+	if to == f.failFor {
+		return nil, errors.New("invalid address")
+	}
+	f.drafts = append(f.drafts, sentEmail{to, subject, body, htmlBody})
+	return &gmail.Draft{}, nil
+}
+
+func (f *fakeMailer) SendEmail(ctx context.Context, to string, subject string, body string, htmlBody string, attachments ...gmailsvc.OutgoingAttachment) (*gmail.Message, error) {
+	f.sent = append(f.sent, sentEmail{to, subject, body, htmlBody})
+	return &gmail.Message{}, nil
+}
+
+func contacts() [][]interface{} {
+	return [][]interface{}{
+		{"Email", "Name", "Company"},
+		{"ann@example.com", "Ann", "Acme & Co"},
+		{"", "No address"},
+		{"bad@example.com", "Bad", "X"},
+		{"cid@example.com", "Cid", "Initech"},
+	}
+}
+
+func TestRunDrafts(t *testing.T) {
+	sheet := &fakeSheet{values: contacts(), writes: map[string]string{}}
+	mailer := &fakeMailer{failFor: "bad@example.com"}
+	res, err := Run(context.Background(), sheet, mailer, Options{
+		SpreadsheetID: "s1",
+		Range:         "Contacts!B2:D20",
+		Subject:       "Hello {{name}}",
+		Body:          "Hi {{ Name }}, greetings to {{company}}.",
+		HTMLBody:      "<p>{{company}}</p>",
+		MaxEmails:     2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sheet.read != "Contacts!B2:E20" {
+		t.Errorf("read range %q, want the range widened to the status column", sheet.read)
+	}
+	if len(mailer.drafts) != 1 || mailer.drafts[0] != (sentEmail{"ann@example.com", "Hello Ann", "Hi Ann, greetings to Acme & Co.", "<p>Acme &amp; Co</p>"}) {
+		t.Errorf("drafts = %+v", mailer.drafts)
+	}
+	if len(res.Done) != 1 || len(res.Failed) != 1 || res.NoEmail != 1 || res.Remaining != 1 || res.Skipped != 0 {
+		t.Errorf("result = %+v", res)
+	}
+	if sheet.writes["Contacts!E2"] != DefaultStatusColumn {
+		t.Errorf("status header not written: %v", sheet.writes)
+	}
+	if !strings.HasPrefix(sheet.writes["Contacts!E3"], "draft ") || sheet.writes["Contacts!E5"] != "failed: invalid address" {
+		t.Errorf("statuses = %v", sheet.writes)
+	}
+
+	// A second run skips the drafted row, retries the failed one and continues with the rest
+	values := contacts()
+	values[0] = append(values[0], DefaultStatusColumn)
+	values[1] = append(values[1], sheet.writes["Contacts!E3"])
+	values[3] = append(values[3], sheet.writes["Contacts!E5"])
+	sheet = &fakeSheet{values: values, writes: map[string]string{}}
+	mailer = &fakeMailer{}
+	res, err = Run(context.Background(), sheet, mailer, Options{SpreadsheetID: "s1", Range: "Contacts!B2:D20", Subject: "Hello {{name}}", Body: "Hi", Send: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := []string{mailer.sent[0].to, mailer.sent[1].to}; len(mailer.sent) != 2 || !slices.Equal(got, []string{"bad@example.com", "cid@example.com"}) {
+		t.Errorf("sent = %+v", mailer.sent)
+	}
+	if res.Skipped != 1 || len(res.Done) != 2 || !strings.HasPrefix(sheet.writes["Contacts!E6"], "sent ") {
+		t.Errorf("second run: result %+v, writes %v", res, sheet.writes)
+	}
+	if _, ok := sheet.writes["Contacts!E2"]; ok {
+		t.Error("existing status header rewritten")
+	}
+}
+
+func TestRunDryRunAndValidation(t *testing.T) {
+	sheet := &fakeSheet{values: contacts(), writes: map[string]string{}}
+	mailer := &fakeMailer{}
+	res, err := Run(context.Background(), sheet, mailer, Options{SpreadsheetID: "s1", Range: "A1:C", Subject: "Hi {{name}}", Body: "Body", DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sheet.read != "A1:D" || len(sheet.writes) != 0 || len(mailer.drafts) != 0 {
+		t.Errorf("dry run read %q, wrote %v, drafted %v", sheet.read, sheet.writes, mailer.drafts)
+	}
+	if len(res.Done) != 3 || res.Done[0].Subject != "Hi Ann" || res.Done[0].Row != 2 {
+		t.Errorf("dry run result = %+v", res)
+	}
+
+	tests := []struct {
+		opts Options
+		want string
+	}{
+		{Options{Subject: "Hi {{nickname}}", Body: "x"}, "placeholders without a column: nickname"},
+		{Options{Subject: "Hi", Body: "x", EmailColumn: "address"}, `no "address" column`},
+		{Options{Subject: "", Body: "x"}, "subject and body are required"},
+	}
+	for _, tt := range tests {
+		tt.opts.SpreadsheetID, tt.opts.Range = "s1", "A1:C5"
+		if _, err := Run(context.Background(), sheet, mailer, tt.opts); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Run(%+v) = %v, want %q", tt.opts, err, tt.want)
+		}
+	}
+}
+
+func TestRunInvalidAddresses(t *testing.T) {
+	values := [][]interface{}{
+		{"Email", "Name"},
+		{"ann@example.com\r\nBcc: all@example.com", "Ann"},
+		{"bob@example.com, eve@example.com", "Bob"},
+		{"not an address", "Cid"},
+		{"Dee Doe <dee@example.com>", "Dee"},
+	}
+	sheet := &fakeSheet{values: values, writes: map[string]string{}}
+	mailer := &fakeMailer{}
+	res, err := Run(context.Background(), sheet, mailer, Options{SpreadsheetID: "s1", Range: "A1:B", Subject: "Hi {{name}}", Body: "Body", Send: true, MaxEmails: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mailer.sent) != 1 || mailer.sent[0].to != `"Dee Doe" <dee@example.com>` {
+		t.Errorf("sent = %+v, want only the valid address", mailer.sent)
+	}
+	if len(res.Failed) != 3 || len(res.Done) != 1 || res.Remaining != 0 {
+		t.Fatalf("result = %+v", res)
+	}
+	for _, r := range res.Failed {
+		if !strings.HasPrefix(r.Status, "invalid address") || !strings.HasPrefix(sheet.writes[fmt.Sprintf("C%d", r.Row)], "invalid address") {
+			t.Errorf("row %d: status %q, writes %v", r.Row, r.Status, sheet.writes)
+		}
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	got := Placeholders("Hi {{name}}", "{{ name }} at {{company}}, {{name}}")
+	if want := []string{"name", "company"}; !slices.Equal(got, want) {
+		t.Errorf("Placeholders() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,206 @@
+// Package metrics keeps the server's counters and latency histograms and serves them in the
+// Prometheus text exposition format, so a long-running server can be scraped and monitored.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the latency histogram bucket upper bounds, in seconds.
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
+
+var (
+	// ToolCalls counts tool calls by tool and outcome ("ok", "error" for error results, "failed" for handler failures).
+	ToolCalls = NewCounter("go_google_mcp_tool_calls_total", "Tool calls by tool and outcome.", "tool", "outcome")
+	// ToolDuration observes how long tool calls take, by tool.
+	ToolDuration = NewHistogram("go_google_mcp_tool_duration_seconds", "Tool call latency in seconds.", DefaultBuckets, "tool")
+	// APIRequests counts Google API requests by API and HTTP status code ("error" when no response was received).
+	APIRequests = NewCounter("go_google_mcp_api_requests_total", "Google API requests by API and status code.", "api", "code")
+	// APIErrors counts failed Google API requests (HTTP 4xx/5xx or no response) by API and status code.
+	APIErrors = NewCounter("go_google_mcp_api_errors_total", "Failed Google API requests by API and status code.", "api", "code")
+	// APIRetries counts Google API requests retried after a rate limit or server error, by API.
+	APIRetries = NewCounter("go_google_mcp_api_retries_total", "Google API request retries by API.", "api")
+	// APIDuration observes how long single Google API requests take, by API.
+	APIDuration = NewHistogram("go_google_mcp_api_request_duration_seconds", "Google API request latency in seconds.", DefaultBuckets, "api")
+	// TokenRefreshes counts OAuth access token refreshes.
+	TokenRefreshes = NewCounter("go_google_mcp_token_refreshes_total", "OAuth access token refreshes.")
+
+	all = []collector{ToolCalls, ToolDuration, APIRequests, APIErrors, APIRetries, APIDuration, TokenRefreshes}
+)
+
+type collector interface {
+	write(w io.Writer)
+}
+
+// Counter is a monotonically increasing count per combination of label values.
+type Counter struct {
+	name, help string
+	labels     []string
+
+	mu     sync.Mutex
+	values map[string]float64 // keyed by joined label values
+}
+
+// NewCounter returns a counter with the given label names.
+func NewCounter(name, help string, labels ...string) *Counter {
+	return &Counter{name: name, help: help, labels: labels, values: make(map[string]float64)}
+}
+
+// Inc adds one to the count for the label values, given in the order of the counter's labels.
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds n to the count for the label values.
+func (c *Counter) Add(n float64, values ...string) {
+	key := labelKey(c.labels, values)
+	c.mu.Lock()
+	c.values[key] += n
+	c.mu.Unlock()
+}
+
+// Value returns the count for the label values.
+func (c *Counter) Value(values ...string) float64 {
+	key := labelKey(c.labels, values)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[key]
+}
+
+func (c *Counter) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	for _, key := range slices.Sorted(maps.Keys(c.values)) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labels, key, ""), formatFloat(c.values[key]))
+	}
+}
+
+// Histogram counts observations into cumulative buckets per combination of label values.
+type Histogram struct {
+	name, help string
+	labels     []string
+	buckets    []float64
+
+	mu     sync.Mutex
+	series map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	counts []uint64 // per bucket, not cumulative; the last entry is +Inf
+	sum    float64
+	count  uint64
+}
+
+// NewHistogram returns a histogram with the given bucket upper bounds (sorted ascending) and label names.
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return &Histogram{name: name, help: help, labels: labels, buckets: buckets, series: make(map[string]*histogramSeries)}
+}
+
+// Observe records a duration for the label values.
+func (h *Histogram) Observe(d time.Duration, values ...string) {
+	v := d.Seconds()
+	key := labelKey(h.labels, values)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{counts: make([]uint64, len(h.buckets)+1)}
+		h.series[key] = s
+	}
+	i, _ := slices.BinarySearch(h.buckets, v)
+	s.counts[i]++
+	s.sum += v
+	s.count++
+}
+
+// Count returns the number of observations for the label values.
+func (h *Histogram) Count(values ...string) uint64 {
+	key := labelKey(h.labels, values)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s, ok := h.series[key]; ok {
+		return s.count
+	}
+	return 0
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	for _, key := range slices.Sorted(maps.Keys(h.series)) {
+		s := h.series[key]
+		var cumulative uint64
+		for i, n := range s.counts {
+			cumulative += n
+			le := "+Inf"
+			if i < len(h.buckets) {
+				le = formatFloat(h.buckets[i])
+			}
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, key, le), cumulative)
+		}
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(h.labels, key, ""), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labels, key, ""), s.count)
+	}
+}
+
+// labelSep separates label values in series keys; it cannot appear in tool or API names.
+const labelSep = "\xff"
+
+func labelKey(labels, values []string) string {
+	if len(values) != len(labels) {
+		panic(fmt.Sprintf("metrics: got %d label values for %d labels", len(values), len(labels)))
+	}
+	return strings.Join(values, labelSep)
+}
+
+// formatLabels renders a series key as {name="value",...}, adding le for histogram buckets.
+func formatLabels(labels []string, key, le string) string {
+	var pairs []string
+	if len(labels) > 0 {
+		for i, v := range strings.Split(key, labelSep) {
+			pairs = append(pairs, labels[i]+`="`+labelEscaper.Replace(v)+`"`)
+		}
+	}
+	if le != "" {
+		pairs = append(pairs, `le="`+le+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Write writes all metrics in the Prometheus text exposition format.
+func Write(w io.Writer) {
+	for _, c := range all {
+		c.write(w)
+	}
+}
+
+// Handler serves all metrics in the Prometheus text exposition format.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		Write(w)
+	})
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCounter(t *testing.T) {
+	c := NewCounter("test_calls_total", "Calls.", "tool", "outcome")
+	c.Inc("gmail_search", "ok")
+	c.Inc("gmail_search", "ok")
+	c.Inc("drive_search", "error")
+	if got := c.Value("gmail_search", "ok"); got != 2 {
+		t.Errorf("Value = %v, want 2", got)
+	}
+	var buf bytes.Buffer
+	c.write(&buf)
+	want := `# HELP test_calls_total Calls.
+# TYPE test_calls_total counter
+test_calls_total{tool="drive_search",outcome="error"} 1
+test_calls_total{tool="gmail_search",outcome="ok"} 2
+`
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram("test_seconds", "Latency.", []float64{0.1, 1}, "api")
+	h.Observe(50*time.Millisecond, "sheets")
+	h.Observe(time.Second, "sheets")
+	h.Observe(3*time.Second, "sheets")
+	var buf bytes.Buffer
+	h.write(&buf)
+	want := `# HELP test_seconds Latency.
+# TYPE test_seconds histogram
+test_seconds_bucket{api="sheets",le="0.1"} 1
+test_seconds_bucket{api="sheets",le="1"} 2
+test_seconds_bucket{api="sheets",le="+Inf"} 3
+test_seconds_sum{api="sheets"} 4.05
+test_seconds_count{api="sheets"} 3
+`
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestFormatLabels(t *testing.T) {
+	if got := formatLabels(nil, "", ""); got != "" {
+		t.Errorf("no labels = %q", got)
+	}
+	if got := formatLabels([]string{"tool"}, "a\"b\\c", ""); got != `{tool="a\"b\\c"}` {
+		t.Errorf("escaping = %q", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	TokenRefreshes.Inc()
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{"# TYPE go_google_mcp_tool_calls_total counter", "go_google_mcp_token_refreshes_total "} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q:\n%s", want, body)
+		}
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+}
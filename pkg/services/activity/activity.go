@@ -3,6 +3,7 @@ package activity
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -24,17 +25,25 @@ func New(ctx context.Context, opts ...option.ClientOption) (*Service, error) {
 	return &Service{srv: srv}, nil
 }
 
+// Ping makes a cheap request to check that the API is enabled and its scope granted.
+func (s *Service) Ping(ctx context.Context) error {
+	if _, err := s.srv.Activity.Query(&driveactivity.QueryDriveActivityRequest{PageSize: 1}).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("unable to query Drive activity: %w", err)
+	}
+	return nil
+}
+
 // ActivitySummary is a human-readable summary of a Drive activity (metadata-only, for low token usage).
 type ActivitySummary struct {
 	Timestamp string // RFC3339
 	Action    string // e.g. "Edit", "Move", "Rename", "Create", "Comment", etc.
-	Actor     string // e.g. "you" or "user@example.com"
+	Actor     string // e.g. "you", "user@example.com", or a "people/..." ID until resolved
 	Target    string // e.g. file/folder title or "items/FILE_ID"
 }
 
 // GetRecentActivity returns recent Drive activity as human-readable summaries.
 // timeRangeHours: how many hours back (default 24). itemName: optional "items/FILE_ID" to filter by file.
-func (s *Service) GetRecentActivity(timeRangeHours int, pageSize int64, itemName string) ([]ActivitySummary, error) {
+func (s *Service) GetRecentActivity(ctx context.Context, timeRangeHours int, pageSize int64, itemName string) ([]ActivitySummary, error) {
 	if timeRangeHours <= 0 {
 		timeRangeHours = 24
 	}
@@ -44,34 +53,90 @@ func (s *Service) GetRecentActivity(timeRangeHours int, pageSize int64, itemName
 	if pageSize > 100 {
 		pageSize = 100
 	}
+	return s.Query(ctx, QueryOptions{
+		ItemName: itemName,
+		Since:    time.Now().Add(-time.Duration(timeRangeHours) * time.Hour),
+		Limit:    int(pageSize),
+	})
+}
 
-	since := time.Now().Add(-time.Duration(timeRangeHours) * time.Hour)
-	filter := fmt.Sprintf("time >= \"%s\"", since.UTC().Format(time.RFC3339))
+// QueryOptions selects the activity returned by Query.
+type QueryOptions struct {
+	ItemName     string    // Only activity on this file ("items/FILE_ID" or FILE_ID)
+	AncestorName string    // Only activity on items inside this folder, at any depth ("items/FOLDER_ID" or FOLDER_ID)
+	Since        time.Time // Optional lower bound
+	Until        time.Time // Optional upper bound
+	Limit        int       // Max activities (default 50)
+}
 
+// itemRef adds the "items/" prefix to a bare file ID.
+func itemRef(id string) string {
+	if id == "" || strings.HasPrefix(id, "items/") {
+		return id
+	}
+	return "items/" + id
+}
+
+// timeFilter builds the API filter for a time range; zero bounds are left open.
+func timeFilter(since, until time.Time) string {
+	var parts []string
+	if !since.IsZero() {
+		parts = append(parts, fmt.Sprintf("time >= \"%s\"", since.UTC().Format(time.RFC3339)))
+	}
+	if !until.IsZero() {
+		parts = append(parts, fmt.Sprintf("time < \"%s\"", until.UTC().Format(time.RFC3339)))
+	}
+	return strings.Join(parts, " AND ")
+}
+
+// Query returns Drive activity matching opts, most recent first, following pages up to the limit.
+func (s *Service) Query(ctx context.Context, opts QueryOptions) ([]ActivitySummary, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = 50
+	}
 	req := &driveactivity.QueryDriveActivityRequest{
-		Filter:   filter,
-		PageSize: pageSize,
+		Filter:       timeFilter(opts.Since, opts.Until),
+		ItemName:     itemRef(opts.ItemName),
+		AncestorName: itemRef(opts.AncestorName),
 	}
-	if itemName != "" {
-		if !strings.HasPrefix(itemName, "items/") {
-			itemName = "items/" + itemName
+	var out []ActivitySummary
+	for len(out) < opts.Limit {
+		req.PageSize = int64(min(opts.Limit-len(out), 100))
+		resp, err := s.srv.Activity.Query(req).Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to query Drive activity: %w", err)
+		}
+		for _, a := range resp.Activities {
+			if sum := summarizeActivity(a); sum != nil {
+				out = append(out, *sum)
+			}
+		}
+		if resp.NextPageToken == "" {
+			break
 		}
-		req.ItemName = itemName
+		req.PageToken = resp.NextPageToken
 	}
+	return out, nil
+}
 
-	resp, err := s.srv.Activity.Query(req).Do()
-	if err != nil {
-		return nil, fmt.Errorf("unable to query Drive activity: %w", err)
+// PersonActors returns the distinct "people/..." actor IDs in summaries, for resolving them to emails.
+func PersonActors(summaries []ActivitySummary) []string {
+	var out []string
+	for _, s := range summaries {
+		if strings.HasPrefix(s.Actor, "people/") && !slices.Contains(out, s.Actor) {
+			out = append(out, s.Actor)
+		}
 	}
+	return out
+}
 
-	var out []ActivitySummary
-	for _, a := range resp.Activities {
-		sum := summarizeActivity(a)
-		if sum != nil {
-			out = append(out, *sum)
+// ResolveActors replaces actor IDs with the names in resolved (e.g. emails looked up through the People API).
+func ResolveActors(summaries []ActivitySummary, resolved map[string]string) {
+	for i, s := range summaries {
+		if name, ok := resolved[s.Actor]; ok && name != "" {
+			summaries[i].Actor = name
 		}
 	}
-	return out, nil
 }
 
 func summarizeActivity(a *driveactivity.DriveActivity) *ActivitySummary {
@@ -127,13 +192,20 @@ func primaryActor(a *driveactivity.DriveActivity) string {
 		return ""
 	}
 	ac := a.Actors[0]
-	if ac.User != nil {
-		if ac.User.KnownUser != nil {
-			if ac.User.KnownUser.IsCurrentUser {
-				return "you"
-			}
-			return ac.User.KnownUser.PersonName
+	switch {
+	case ac.User != nil && ac.User.KnownUser != nil:
+		if ac.User.KnownUser.IsCurrentUser {
+			return "you"
 		}
+		return ac.User.KnownUser.PersonName
+	case ac.User != nil && ac.User.DeletedUser != nil:
+		return "deleted user"
+	case ac.Anonymous != nil:
+		return "anonymous"
+	case ac.Administrator != nil:
+		return "administrator"
+	case ac.System != nil:
+		return "system"
 	}
 	return "unknown"
 }
@@ -0,0 +1,83 @@
+package activity
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTimeFilter(t *testing.T) {
+	since := time.Date(2024, 5, 1, 9, 0, 0, 0, time.FixedZone("BRT", -3*3600))
+	until := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		since, until time.Time
+		want         string
+	}{
+		{time.Time{}, time.Time{}, ""},
+		{since, time.Time{}, `time >= "2024-05-01T12:00:00Z"`},
+		{time.Time{}, until, `time < "2024-05-02T00:00:00Z"`},
+		{since, until, `time >= "2024-05-01T12:00:00Z" AND time < "2024-05-02T00:00:00Z"`},
+	}
+	for _, tt := range tests {
+		if got := timeFilter(tt.since, tt.until); got != tt.want {
+			t.Errorf("timeFilter = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestItemRef(t *testing.T) {
+	for in, want := range map[string]string{"": "", "abc": "items/abc", "items/abc": "items/abc"} {
+		if got := itemRef(in); got != want {
+			t.Errorf("itemRef(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestResolveActors(t *testing.T) {
+	sums := []ActivitySummary{{Actor: "people/1"}, {Actor: "you"}, {Actor: "people/2"}, {Actor: "people/1"}}
+	if got, want := PersonActors(sums), []string{"people/1", "people/2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PersonActors = %v, want %v", got, want)
+	}
+	ResolveActors(sums, map[string]string{"people/1": "ana@example.com"})
+	var actors []string
+	for _, s := range sums {
+		actors = append(actors, s.Actor)
+	}
+	if want := []string{"ana@example.com", "you", "people/2", "ana@example.com"}; !reflect.DeepEqual(actors, want) {
+		t.Errorf("actors = %v, want %v", actors, want)
+	}
+}
+
+func TestGroupConsecutive(t *testing.T) {
+	sums := []ActivitySummary{
+		{Timestamp: "t5", Action: "Edit", Actor: "ana", Target: "Q3 Plan"},
+		{Timestamp: "t4", Action: "Edit", Actor: "ana", Target: "Q3 Plan"},
+		{Timestamp: "t3", Action: "Comment", Actor: "bob", Target: "Q3 Plan"},
+		{Timestamp: "t2", Action: "Edit", Actor: "ana", Target: "Q3 Plan"},
+		{Timestamp: "t1", Action: "Edit", Actor: "ana", Target: "Budget"},
+	}
+	got := GroupConsecutive(sums)
+	want := []ActivityGroup{
+		{Actor: "ana", Action: "Edit", Target: "Q3 Plan", Count: 2, First: "t4", Last: "t5"},
+		{Actor: "bob", Action: "Comment", Target: "Q3 Plan", Count: 1, First: "t3", Last: "t3"},
+		{Actor: "ana", Action: "Edit", Target: "Q3 Plan", Count: 1, First: "t2", Last: "t2"},
+		{Actor: "ana", Action: "Edit", Target: "Budget", Count: 1, First: "t1", Last: "t1"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupConsecutive = %+v, want %+v", got, want)
+	}
+
+	totals := Totals(sums)
+	if len(totals) != 3 || totals[0].Count != 3 || totals[0].First != "t2" || totals[0].Last != "t5" {
+		t.Errorf("Totals = %+v", totals)
+	}
+	if got, want := totals[0].String(), "3 edits by ana on Q3 Plan"; got != want {
+		t.Errorf("String = %q, want %q", got, want)
+	}
+	if got, want := totals[1].String(), "1 comment by bob on Q3 Plan"; got != want {
+		t.Errorf("String = %q, want %q", got, want)
+	}
+	if got, want := (ActivityGroup{Actor: "you", Action: "Create", Count: 2}).String(), "2 creations by you"; got != want {
+		t.Errorf("String = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,86 @@
+package activity
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ActivityGroup is a run of activities of the same kind by the same actor on the same target.
+type ActivityGroup struct {
+	Actor  string
+	Action string
+	Target string
+	Count  int
+	First  string // RFC3339 timestamp of the oldest activity in the group
+	Last   string // RFC3339 timestamp of the most recent activity in the group
+}
+
+// String renders the group as e.g. "12 edits by alice@example.com on Q3 Plan".
+func (g ActivityGroup) String() string {
+	s := fmt.Sprintf("%d %s by %s", g.Count, actionNoun(g.Action, g.Count), g.Actor)
+	if g.Target != "" {
+		s += " on " + g.Target
+	}
+	return s
+}
+
+// actionNouns maps action names to their singular and plural nouns.
+var actionNouns = map[string][2]string{
+	"Create":            {"creation", "creations"},
+	"Delete":            {"deletion", "deletions"},
+	"Permission change": {"permission change", "permission changes"},
+	"Activity":          {"activity", "activities"},
+}
+
+func actionNoun(action string, n int) string {
+	nouns, ok := actionNouns[action]
+	if !ok {
+		nouns = [2]string{strings.ToLower(action), strings.ToLower(action) + "s"}
+	}
+	if n == 1 {
+		return nouns[0]
+	}
+	return nouns[1]
+}
+
+// sameKind reports whether two activities belong in the same group.
+func sameKind(g ActivityGroup, s ActivitySummary) bool {
+	return g.Actor == s.Actor && g.Action == s.Action && g.Target == s.Target
+}
+
+// GroupConsecutive merges consecutive activities (as returned by Query, most recent first)
+// with the same actor, action and target, so a burst of edits becomes a single entry.
+func GroupConsecutive(summaries []ActivitySummary) []ActivityGroup {
+	var out []ActivityGroup
+	for _, s := range summaries {
+		if n := len(out); n > 0 && sameKind(out[n-1], s) {
+			out[n-1].Count++
+			out[n-1].First = s.Timestamp
+			continue
+		}
+		out = append(out, ActivityGroup{Actor: s.Actor, Action: s.Action, Target: s.Target, Count: 1, First: s.Timestamp, Last: s.Timestamp})
+	}
+	return out
+}
+
+// Totals counts activities per actor, action and target over the whole range,
+// most frequent first (ties keep the order of the most recent activity).
+func Totals(summaries []ActivitySummary) []ActivityGroup {
+	var out []ActivityGroup
+	index := make(map[[3]string]int)
+	for _, s := range summaries {
+		key := [3]string{s.Actor, s.Action, s.Target}
+		i, ok := index[key]
+		if !ok {
+			i = len(out)
+			index[key] = i
+			out = append(out, ActivityGroup{Actor: s.Actor, Action: s.Action, Target: s.Target, Last: s.Timestamp})
+		}
+		out[i].Count++
+		out[i].First = s.Timestamp
+	}
+	slices.SortStableFunc(out, func(a, b ActivityGroup) int { return cmp.Compare(b.Count, a.Count) })
+	return out
+}
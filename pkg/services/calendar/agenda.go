@@ -0,0 +1,246 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// AgendaEvent is an event placed on the agenda.
+type AgendaEvent struct {
+	CalendarID string
+	ID         string
+	Summary    string
+	Location   string
+	Start      time.Time
+	End        time.Time
+	AllDay     bool
+	Busy       bool // False for events marked as free or declined by the user
+}
+
+// Gap is a free slot between events.
+type Gap struct {
+	Start time.Time
+	End   time.Time
+}
+
+// AgendaDay holds the events and free slots of one day.
+type AgendaDay struct {
+	Date   time.Time
+	Events []AgendaEvent
+	Free   []Gap
+}
+
+// AgendaOptions controls how free slots are computed.
+type AgendaOptions struct {
+	DayStart time.Duration // Start of the working day, as an offset from midnight
+	DayEnd   time.Duration
+	MinGap   time.Duration // Free slots shorter than this are left out
+}
+
+// Agenda collects the events of several calendars between the first and last day (inclusive) in loc,
+// and groups them by day with the free slots left in each working day.
+func (c *CalendarService) Agenda(ctx context.Context, calendarIds []string, first time.Time, last time.Time, loc *time.Location, opts AgendaOptions) ([]AgendaDay, error) {
+	if len(calendarIds) == 0 {
+		calendarIds = []string{"primary"}
+	}
+	from, to, err := dayRange(first, last, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []AgendaEvent
+	err = c.eachEvent(ctx, calendarIds, from, to, func(id string, e *calendar.Event) {
+		if ae, ok := toAgendaEvent(id, e, loc); ok {
+			events = append(events, ae)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return BuildAgenda(events, from, to, opts), nil
+}
+
+// eachEvent calls fn with every event (recurring ones expanded) of the calendars between from and to.
+func (c *CalendarService) eachEvent(ctx context.Context, calendarIds []string, from, to time.Time, fn func(calendarID string, e *calendar.Event)) error {
+	for _, id := range calendarIds {
+		err := c.srv.Events.List(id).
+			SingleEvents(true).
+			ShowDeleted(false).
+			OrderBy("startTime").
+			TimeMin(from.Format(time.RFC3339)).
+			TimeMax(to.Format(time.RFC3339)).
+			MaxResults(250).
+			Pages(ctx, func(page *calendar.Events) error {
+				for _, e := range page.Items {
+					fn(id, e)
+				}
+				return nil
+			})
+		if err != nil {
+			return fmt.Errorf("unable to retrieve events for %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// dayRange returns the start of the first day and the end of the last day (inclusive) in loc.
+func dayRange(first, last time.Time, loc *time.Location) (time.Time, time.Time, error) {
+	from := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, loc)
+	to := time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, 1)
+	if !to.After(from) {
+		return from, to, fmt.Errorf("end date must not be before start date")
+	}
+	return from, to, nil
+}
+
+// TimeZone returns the time zone configured on a calendar.
+func (c *CalendarService) TimeZone(ctx context.Context, calendarId string) (string, error) {
+	if calendarId == "" {
+		calendarId = "primary"
+	}
+	cal, err := c.srv.Calendars.Get(calendarId).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve calendar: %w", err)
+	}
+	return cal.TimeZone, nil
+}
+
+// toAgendaEvent converts an API event, reporting false for cancelled or unparseable events.
+func toAgendaEvent(calendarID string, e *calendar.Event, loc *time.Location) (AgendaEvent, bool) {
+	if e.Status == "cancelled" || e.Start == nil || e.End == nil {
+		return AgendaEvent{}, false
+	}
+	ae := AgendaEvent{
+		CalendarID: calendarID,
+		ID:         e.Id,
+		Summary:    e.Summary,
+		Location:   e.Location,
+		Busy:       e.Transparency != "transparent",
+	}
+	for _, a := range e.Attendees {
+		if a.Self && a.ResponseStatus == "declined" {
+			ae.Busy = false
+		}
+	}
+	var err error
+	if e.Start.DateTime != "" {
+		if ae.Start, err = time.Parse(time.RFC3339, e.Start.DateTime); err != nil {
+			return AgendaEvent{}, false
+		}
+		if ae.End, err = time.Parse(time.RFC3339, e.End.DateTime); err != nil {
+			return AgendaEvent{}, false
+		}
+		ae.Start, ae.End = ae.Start.In(loc), ae.End.In(loc)
+		return ae, true
+	}
+	ae.AllDay = true
+	if ae.Start, err = time.ParseInLocation("2006-01-02", e.Start.Date, loc); err != nil {
+		return AgendaEvent{}, false
+	}
+	if ae.End, err = time.ParseInLocation("2006-01-02", e.End.Date, loc); err != nil {
+		return AgendaEvent{}, false
+	}
+	return ae, true
+}
+
+// BuildAgenda groups events by day between from and to (exclusive) and computes free slots in each
+// working day. All-day events are listed but do not block time.
+func BuildAgenda(events []AgendaEvent, from time.Time, to time.Time, opts AgendaOptions) []AgendaDay {
+	slices.SortStableFunc(events, func(a, b AgendaEvent) int { return a.Start.Compare(b.Start) })
+
+	var days []AgendaDay
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+		d := AgendaDay{Date: day}
+		var busy []Gap
+		for _, e := range events {
+			if !e.Start.Before(next) || !e.End.After(day) {
+				continue
+			}
+			d.Events = append(d.Events, e)
+			if e.Busy && !e.AllDay {
+				busy = append(busy, Gap{Start: e.Start, End: e.End})
+			}
+		}
+		workStart, workEnd := day.Add(opts.DayStart), day.Add(opts.DayEnd)
+		d.Free = freeSlots(busy, workStart, workEnd, opts.MinGap)
+		days = append(days, d)
+	}
+	return days
+}
+
+// freeSlots returns the parts of [start, end) not covered by busy, dropping slots shorter than minGap.
+// busy must be sorted by start time.
+func freeSlots(busy []Gap, start time.Time, end time.Time, minGap time.Duration) []Gap {
+	var free []Gap
+	cursor := start
+	for _, b := range busy {
+		if b.Start.After(cursor) {
+			free = appendGap(free, cursor, minTime(b.Start, end), minGap)
+		}
+		if b.End.After(cursor) {
+			cursor = b.End
+		}
+		if !cursor.Before(end) {
+			return free
+		}
+	}
+	return appendGap(free, cursor, end, minGap)
+}
+
+func appendGap(free []Gap, start time.Time, end time.Time, minGap time.Duration) []Gap {
+	if end.Sub(start) > 0 && end.Sub(start) >= minGap {
+		free = append(free, Gap{Start: start, End: end})
+	}
+	return free
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// FormatAgenda renders agenda days as a compact text agenda.
+func FormatAgenda(days []AgendaDay, showCalendar bool) string {
+	var b strings.Builder
+	for _, d := range days {
+		fmt.Fprintf(&b, "%s\n", d.Date.Format("Mon 2006-01-02"))
+		if len(d.Events) == 0 {
+			b.WriteString("  (no events)\n")
+		}
+		for _, e := range d.Events {
+			when := "all day"
+			if !e.AllDay {
+				when = e.Start.Format("15:04") + "-" + e.End.Format("15:04")
+			}
+			line := fmt.Sprintf("  %s %s", when, e.Summary)
+			if e.Location != "" {
+				line += " @ " + e.Location
+			}
+			if !e.Busy {
+				line += " (free)"
+			}
+			if showCalendar {
+				line += " [" + e.CalendarID + "]"
+			}
+			fmt.Fprintf(&b, "%s (%s)\n", line, e.ID)
+		}
+		if len(d.Free) > 0 {
+			var slots []string
+			for _, g := range d.Free {
+				slots = append(slots, g.Start.Format("15:04")+"-"+g.End.Format("15:04"))
+			}
+			fmt.Fprintf(&b, "  Free: %s\n", strings.Join(slots, ", "))
+		} else {
+			b.WriteString("  Free: none\n")
+		}
+	}
+	return b.String()
+}
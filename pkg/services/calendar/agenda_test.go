@@ -0,0 +1,44 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildAgenda(t *testing.T) {
+	loc := time.UTC
+	at := func(day, hour, min int) time.Time { return time.Date(2025, 3, day, hour, min, 0, 0, loc) }
+	events := []AgendaEvent{
+		{ID: "b", Summary: "Overlap", Start: at(3, 10, 30), End: at(3, 11, 30), Busy: true},
+		{ID: "a", Summary: "Standup", Start: at(3, 10, 0), End: at(3, 11, 0), Busy: true},
+		{ID: "c", Summary: "Declined", Start: at(3, 14, 0), End: at(3, 15, 0), Busy: false},
+		{ID: "d", Summary: "Short gap", Start: at(3, 16, 0), End: at(3, 17, 50), Busy: true},
+		{ID: "e", Summary: "Holiday", Start: at(4, 0, 0), End: at(5, 0, 0), AllDay: true, Busy: true},
+	}
+	opts := AgendaOptions{DayStart: 9 * time.Hour, DayEnd: 18 * time.Hour, MinGap: 15 * time.Minute}
+
+	days := BuildAgenda(events, at(3, 0, 0), at(5, 0, 0), opts)
+	if len(days) != 2 {
+		t.Fatalf("expected 2 days, got %d", len(days))
+	}
+
+	if got := days[0].Events; len(got) != 4 || got[0].ID != "a" || got[1].ID != "b" {
+		t.Errorf("expected day 1 events sorted by start, got %+v", got)
+	}
+	want := []Gap{
+		{Start: at(3, 9, 0), End: at(3, 10, 0)},
+		{Start: at(3, 11, 30), End: at(3, 16, 0)},
+	}
+	if len(days[0].Free) != len(want) {
+		t.Fatalf("expected free slots %v, got %v", want, days[0].Free)
+	}
+	for i, g := range want {
+		if !days[0].Free[i].Start.Equal(g.Start) || !days[0].Free[i].End.Equal(g.End) {
+			t.Errorf("slot %d: expected %v-%v, got %v-%v", i, g.Start, g.End, days[0].Free[i].Start, days[0].Free[i].End)
+		}
+	}
+
+	if len(days[1].Events) != 1 || len(days[1].Free) != 1 {
+		t.Errorf("expected all-day event not to block day 2, got %+v", days[1])
+	}
+}
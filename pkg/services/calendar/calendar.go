@@ -3,6 +3,7 @@ package calendar
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"google.golang.org/api/calendar/v3"
@@ -23,8 +24,31 @@ func New(ctx context.Context, opts ...option.ClientOption) (*CalendarService, er
 	return &CalendarService{srv: srv}, nil
 }
 
-// ListEvents lists upcoming events.
-func (c *CalendarService) ListEvents(calendarId string, maxResults int64, timeMin string, timeMax string) ([]*calendar.Event, error) {
+// Ping makes a cheap request to check that the API is enabled and its scope granted.
+func (c *CalendarService) Ping(ctx context.Context) error {
+	if _, err := c.srv.CalendarList.List().MaxResults(1).Fields("items(id)").Context(ctx).Do(); err != nil {
+		return fmt.Errorf("unable to list calendars: %w", err)
+	}
+	return nil
+}
+
+// ListCalendars lists the calendars on the user's calendar list.
+func (c *CalendarService) ListCalendars(ctx context.Context) ([]*calendar.CalendarListEntry, error) {
+	var out []*calendar.CalendarListEntry
+	err := c.srv.CalendarList.List().Pages(ctx, func(page *calendar.CalendarList) error {
+		out = append(out, page.Items...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve calendar list: %w", err)
+	}
+	return out, nil
+}
+
+// ListEvents lists events, by default upcoming ones in start time order.
+// query is a free text search over summary, description, location and attendees.
+// order is "asc" (default), "desc" (most recent first, for searching past events) or "updated" (last modified).
+func (c *CalendarService) ListEvents(ctx context.Context, calendarId string, maxResults int64, timeMin string, timeMax string, query string, order string) ([]*calendar.Event, error) {
 	if calendarId == "" {
 		calendarId = "primary"
 	}
@@ -34,30 +58,67 @@ func (c *CalendarService) ListEvents(calendarId string, maxResults int64, timeMi
 
 	call := c.srv.Events.List(calendarId).
 		ShowDeleted(false).
-		SingleEvents(true).
-		MaxResults(maxResults).
-		OrderBy("startTime")
+		SingleEvents(true)
+	if query != "" {
+		call.Q(query)
+	}
 
-	if timeMin != "" {
-		call.TimeMin(timeMin)
-	} else {
-		// Default to now if not specified?
-		// Actually, standard behavior is usually from now if not specified for "upcoming".
-		call.TimeMin(time.Now().Format(time.RFC3339))
+	switch order {
+	case "", "asc", "desc":
+		call.OrderBy("startTime")
+	case "updated":
+		call.OrderBy("updated")
+	default:
+		return nil, fmt.Errorf("invalid order %q (use asc, desc or updated)", order)
+	}
+
+	if order == "desc" {
+		// The API only sorts ascending, so look back over a bounded window and keep the latest events.
+		now := time.Now()
+		if timeMax == "" {
+			timeMax = now.Format(time.RFC3339)
+		}
+		if timeMin == "" {
+			end, err := time.Parse(time.RFC3339, timeMax)
+			if err != nil {
+				end = now
+			}
+			timeMin = end.AddDate(-1, 0, 0).Format(time.RFC3339)
+		}
+	} else if timeMin == "" {
+		// Default to now: without a lower bound "upcoming" events are what callers expect.
+		timeMin = time.Now().Format(time.RFC3339)
 	}
+	call.TimeMin(timeMin)
 	if timeMax != "" {
 		call.TimeMax(timeMax)
 	}
 
-	events, err := call.Do()
+	if order != "desc" {
+		events, err := call.MaxResults(maxResults).Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve events: %w", err)
+		}
+		return events.Items, nil
+	}
+
+	var all []*calendar.Event
+	err := call.MaxResults(250).Pages(ctx, func(page *calendar.Events) error {
+		all = append(all, page.Items...)
+		if int64(len(all)) > maxResults {
+			all = all[int64(len(all))-maxResults:]
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve events: %w", err)
 	}
-	return events.Items, nil
+	slices.Reverse(all)
+	return all, nil
 }
 
-// CreateEvent creates a new event.
-func (c *CalendarService) CreateEvent(calendarId string, summary string, description string, startTime string, endTime string, attendees []string) (*calendar.Event, error) {
+// CreateEvent creates a new event. recurrence holds optional RRULE lines for a recurring series.
+func (c *CalendarService) CreateEvent(ctx context.Context, calendarId string, summary string, description string, startTime string, endTime string, attendees []string, recurrence []string) (*calendar.Event, error) {
 	if calendarId == "" {
 		calendarId = "primary"
 	}
@@ -82,18 +143,271 @@ func (c *CalendarService) CreateEvent(calendarId string, summary string, descrip
 		}
 		event.Attendees = atts
 	}
+	event.Recurrence = recurrence
 
-	e, err := c.srv.Events.Insert(calendarId, event).Do()
+	e, err := c.srv.Events.Insert(calendarId, event).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to create event: %w", err)
 	}
 	return e, nil
 }
 
-// DeleteEvent deletes an event.
-func (c *CalendarService) DeleteEvent(calendarId string, eventId string) error {
+// GetEvent retrieves a single event.
+func (c *CalendarService) GetEvent(ctx context.Context, calendarId string, eventId string) (*calendar.Event, error) {
+	if calendarId == "" {
+		calendarId = "primary"
+	}
+	if eventId == "" {
+		return nil, fmt.Errorf("event_id is required")
+	}
+	e, err := c.srv.Events.Get(calendarId, eventId).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve event: %w", err)
+	}
+	return e, nil
+}
+
+// validResponses are the RSVP statuses the user can set on an event.
+var validResponses = map[string]bool{"accepted": true, "declined": true, "tentative": true}
+
+// RespondToEvent sets the authenticated user's RSVP on an event they were invited to.
+// response is "accepted", "declined" or "tentative"; comment is an optional note shown to the organizer.
+func (c *CalendarService) RespondToEvent(ctx context.Context, calendarId string, eventId string, response string, comment string, sendUpdates string) (*calendar.Event, error) {
+	if calendarId == "" {
+		calendarId = "primary"
+	}
+	if !validSendUpdates[sendUpdates] {
+		return nil, fmt.Errorf("invalid send_updates %q (use all, externalOnly or none)", sendUpdates)
+	}
+	e, err := c.GetEvent(ctx, calendarId, eventId)
+	if err != nil {
+		return nil, err
+	}
+	if err := setSelfResponse(e.Attendees, response, comment); err != nil {
+		return nil, err
+	}
+
+	patch := &calendar.Event{Attendees: e.Attendees}
+	call := c.srv.Events.Patch(calendarId, eventId, patch)
+	if sendUpdates != "" {
+		call.SendUpdates(sendUpdates)
+	}
+	updated, err := call.Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to update response: %w", err)
+	}
+	return updated, nil
+}
+
+// setSelfResponse updates the response status and comment of the attendee entry marked as the user.
+func setSelfResponse(attendees []*calendar.EventAttendee, response string, comment string) error {
+	if !validResponses[response] {
+		return fmt.Errorf("invalid response %q (use accepted, declined or tentative)", response)
+	}
+	for _, a := range attendees {
+		if a.Self {
+			a.ResponseStatus = response
+			if comment != "" {
+				a.Comment = comment
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("you are not an attendee of this event")
+}
+
+// ListInstances lists the occurrences of a recurring event between timeMin and timeMax (both optional, RFC3339).
+// Instance IDs can be passed to PatchEvent or DeleteEvent to change or cancel a single occurrence.
+func (c *CalendarService) ListInstances(ctx context.Context, calendarId string, eventId string, maxResults int64, timeMin string, timeMax string) ([]*calendar.Event, error) {
+	if calendarId == "" {
+		calendarId = "primary"
+	}
+	if eventId == "" {
+		return nil, fmt.Errorf("event_id is required")
+	}
+	if maxResults <= 0 {
+		maxResults = 10
+	}
+	call := c.srv.Events.Instances(calendarId, eventId).MaxResults(maxResults)
+	if timeMin != "" {
+		call.TimeMin(timeMin)
+	}
+	if timeMax != "" {
+		call.TimeMax(timeMax)
+	}
+	resp, err := call.Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve instances: %w", err)
+	}
+	return resp.Items, nil
+}
+
+// DeleteEvent deletes an event. Passing an instance ID cancels only that occurrence of a recurring event.
+func (c *CalendarService) DeleteEvent(ctx context.Context, calendarId string, eventId string) error {
 	if calendarId == "" {
 		calendarId = "primary"
 	}
-	return c.srv.Events.Delete(calendarId, eventId).Do()
+	return c.srv.Events.Delete(calendarId, eventId).Context(ctx).Do()
+}
+
+// EventPatch holds the fields to change on an existing event. Nil fields are left untouched.
+type EventPatch struct {
+	Summary     *string
+	Description *string
+	Location    *string
+	StartTime   *string // RFC3339 date-time, or YYYY-MM-DD for all-day events
+	EndTime     *string
+	Attendees   *[]string
+	Recurrence  *[]string // RRULE/EXRULE/RDATE/EXDATE lines
+}
+
+// validSendUpdates are the values accepted by the sendUpdates parameter.
+var validSendUpdates = map[string]bool{"": true, "all": true, "externalOnly": true, "none": true}
+
+// QuickAdd creates an event from a natural language description such as "Lunch with Sam Friday 12pm".
+func (c *CalendarService) QuickAdd(ctx context.Context, calendarId string, text string, sendUpdates string) (*calendar.Event, error) {
+	if calendarId == "" {
+		calendarId = "primary"
+	}
+	if text == "" {
+		return nil, fmt.Errorf("text is required")
+	}
+	if !validSendUpdates[sendUpdates] {
+		return nil, fmt.Errorf("invalid send_updates %q (use all, externalOnly or none)", sendUpdates)
+	}
+	call := c.srv.Events.QuickAdd(calendarId, text)
+	if sendUpdates != "" {
+		call.SendUpdates(sendUpdates)
+	}
+	e, err := call.Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to quick-add event: %w", err)
+	}
+	return e, nil
+}
+
+// UpdateEvent replaces an event with the given one. Fields not set on event are cleared.
+func (c *CalendarService) UpdateEvent(ctx context.Context, calendarId string, event *calendar.Event, sendUpdates string) (*calendar.Event, error) {
+	if calendarId == "" {
+		calendarId = "primary"
+	}
+	if event == nil || event.Id == "" {
+		return nil, fmt.Errorf("event with an ID is required")
+	}
+	if !validSendUpdates[sendUpdates] {
+		return nil, fmt.Errorf("invalid send_updates %q (use all, externalOnly or none)", sendUpdates)
+	}
+	call := c.srv.Events.Update(calendarId, event.Id, event)
+	if sendUpdates != "" {
+		call.SendUpdates(sendUpdates)
+	}
+	e, err := call.Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to update event: %w", err)
+	}
+	return e, nil
+}
+
+// PatchEvent applies a partial update to an event.
+// sendUpdates controls guest notifications: "all", "externalOnly" or "none" (default: API default).
+func (c *CalendarService) PatchEvent(ctx context.Context, calendarId string, eventId string, patch EventPatch, sendUpdates string) (*calendar.Event, error) {
+	if calendarId == "" {
+		calendarId = "primary"
+	}
+	if eventId == "" {
+		return nil, fmt.Errorf("event_id is required")
+	}
+	if !validSendUpdates[sendUpdates] {
+		return nil, fmt.Errorf("invalid send_updates %q (use all, externalOnly or none)", sendUpdates)
+	}
+
+	event, err := patch.event()
+	if err != nil {
+		return nil, err
+	}
+	call := c.srv.Events.Patch(calendarId, eventId, event)
+	if sendUpdates != "" {
+		call.SendUpdates(sendUpdates)
+	}
+	e, err := call.Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to patch event: %w", err)
+	}
+	return e, nil
+}
+
+// event builds the partial Event body for a patch. Empty strings and lists clear the field.
+func (p EventPatch) event() (*calendar.Event, error) {
+	event := &calendar.Event{}
+	changed := false
+	setString := func(dst *string, src *string, field string) {
+		if src == nil {
+			return
+		}
+		changed = true
+		*dst = *src
+		if *src == "" {
+			event.ForceSendFields = append(event.ForceSendFields, field)
+		}
+	}
+	setString(&event.Summary, p.Summary, "Summary")
+	setString(&event.Description, p.Description, "Description")
+	setString(&event.Location, p.Location, "Location")
+
+	if (p.StartTime == nil) != (p.EndTime == nil) {
+		return nil, fmt.Errorf("start_time and end_time must be changed together")
+	}
+	if p.StartTime != nil {
+		changed = true
+		event.Start = eventDateTime(*p.StartTime)
+		event.End = eventDateTime(*p.EndTime)
+	}
+	if p.Attendees != nil {
+		changed = true
+		event.Attendees = []*calendar.EventAttendee{}
+		for _, email := range *p.Attendees {
+			event.Attendees = append(event.Attendees, &calendar.EventAttendee{Email: email})
+		}
+		event.ForceSendFields = append(event.ForceSendFields, "Attendees")
+	}
+	if p.Recurrence != nil {
+		changed = true
+		event.Recurrence = *p.Recurrence
+		event.ForceSendFields = append(event.ForceSendFields, "Recurrence")
+	}
+	if !changed {
+		return nil, fmt.Errorf("no fields to update")
+	}
+	return event, nil
+}
+
+// eventDateTime turns a YYYY-MM-DD date into an all-day EventDateTime and anything else into a timed one.
+func eventDateTime(s string) *calendar.EventDateTime {
+	if len(s) == len("2006-01-02") {
+		return &calendar.EventDateTime{Date: s}
+	}
+	return &calendar.EventDateTime{DateTime: s, TimeZone: "UTC"}
+}
+
+// FreeBusy returns the busy periods of the given calendars (or attendee emails) between timeMin and timeMax (RFC3339).
+func (c *CalendarService) FreeBusy(ctx context.Context, calendarIds []string, timeMin string, timeMax string, timeZone string) (map[string]calendar.FreeBusyCalendar, error) {
+	if timeMin == "" || timeMax == "" {
+		return nil, fmt.Errorf("time_min and time_max are required")
+	}
+	if len(calendarIds) == 0 {
+		calendarIds = []string{"primary"}
+	}
+	req := &calendar.FreeBusyRequest{
+		TimeMin:  timeMin,
+		TimeMax:  timeMax,
+		TimeZone: timeZone,
+	}
+	for _, id := range calendarIds {
+		req.Items = append(req.Items, &calendar.FreeBusyRequestItem{Id: id})
+	}
+	resp, err := c.srv.Freebusy.Query(req).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to query free/busy: %w", err)
+	}
+	return resp.Calendars, nil
 }
@@ -0,0 +1,108 @@
+package calendar
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestEventPatch(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	t.Run("partial fields", func(t *testing.T) {
+		attendees := []string{"bob@example.com"}
+		e, err := EventPatch{Summary: str("Sync"), Description: str(""), Attendees: &attendees}.event()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if e.Summary != "Sync" || e.Location != "" || e.Start != nil {
+			t.Errorf("unexpected event: %+v", e)
+		}
+		if len(e.Attendees) != 1 || e.Attendees[0].Email != "bob@example.com" {
+			t.Errorf("unexpected attendees: %+v", e.Attendees)
+		}
+		if !slices.Contains(e.ForceSendFields, "Description") || slices.Contains(e.ForceSendFields, "Summary") {
+			t.Errorf("unexpected force send fields: %v", e.ForceSendFields)
+		}
+	})
+
+	t.Run("all-day and timed", func(t *testing.T) {
+		e, err := EventPatch{StartTime: str("2025-03-01"), EndTime: str("2025-03-02")}.event()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if e.Start.Date != "2025-03-01" || e.Start.DateTime != "" {
+			t.Errorf("expected all-day start, got %+v", e.Start)
+		}
+		e, _ = EventPatch{StartTime: str("2025-03-01T10:00:00Z"), EndTime: str("2025-03-01T11:00:00Z")}.event()
+		if e.End.DateTime != "2025-03-01T11:00:00Z" {
+			t.Errorf("expected timed end, got %+v", e.End)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := (EventPatch{}).event(); err == nil {
+			t.Error("expected error for empty patch")
+		}
+		if _, err := (EventPatch{StartTime: str("2025-03-01T10:00:00Z")}).event(); err == nil {
+			t.Error("expected error for start without end")
+		}
+	})
+}
+
+func TestFormatEventDetails(t *testing.T) {
+	e := &calendar.Event{
+		Id:       "evt1",
+		Summary:  "Planning",
+		Start:    &calendar.EventDateTime{DateTime: "2025-03-03T10:00:00Z"},
+		End:      &calendar.EventDateTime{Date: "2025-03-04"},
+		Location: "Room 1",
+		ConferenceData: &calendar.ConferenceData{EntryPoints: []*calendar.EntryPoint{
+			{EntryPointType: "phone", Uri: "tel:+1"},
+			{EntryPointType: "video", Uri: "https://meet.google.com/abc"},
+		}},
+		Recurrence: []string{"RRULE:FREQ=WEEKLY"},
+		Attendees: []*calendar.EventAttendee{
+			{Email: "bob@example.com", DisplayName: "Bob", ResponseStatus: "accepted", Organizer: true},
+			{Email: "me@example.com", ResponseStatus: "needsAction", Self: true, Optional: true},
+		},
+	}
+	got := FormatEventDetails(e)
+	for _, want := range []string{
+		"Start: 2025-03-03T10:00:00Z\n",
+		"End: 2025-03-04 (all day)\n",
+		"Conference: https://meet.google.com/abc\n",
+		"Recurrence: RRULE:FREQ=WEEKLY\n",
+		"- Bob <bob@example.com>: accepted (organizer)\n",
+		"- me@example.com: no response (optional, you)\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestSetSelfResponse(t *testing.T) {
+	attendees := []*calendar.EventAttendee{
+		{Email: "bob@example.com", ResponseStatus: "accepted"},
+		{Email: "me@example.com", ResponseStatus: "needsAction", Self: true},
+	}
+	if err := setSelfResponse(attendees, "tentative", "Might be late"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attendees[1].ResponseStatus != "tentative" || attendees[1].Comment != "Might be late" {
+		t.Errorf("self attendee not updated: %+v", attendees[1])
+	}
+	if attendees[0].ResponseStatus != "accepted" {
+		t.Errorf("other attendee changed: %+v", attendees[0])
+	}
+
+	if err := setSelfResponse(attendees, "maybe", ""); err == nil {
+		t.Error("expected error for invalid response")
+	}
+	if err := setSelfResponse(attendees[:1], "accepted", ""); err == nil {
+		t.Error("expected error when user is not an attendee")
+	}
+}
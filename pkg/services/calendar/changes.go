@@ -0,0 +1,85 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// ErrSyncTokenExpired is returned when a sync token is no longer valid; start over without one.
+var ErrSyncTokenExpired = errors.New("sync token expired; start again without one")
+
+// EventChange is an event created, updated or deleted since a sync token.
+type EventChange struct {
+	Change           string `json:"change"` // created, updated or deleted
+	ID               string `json:"id"`
+	Summary          string `json:"summary,omitempty"`
+	Start            string `json:"start,omitempty"`
+	End              string `json:"end,omitempty"`
+	Updated          string `json:"updated,omitempty"`
+	RecurringEventID string `json:"recurring_event_id,omitempty"` // Set for changes to a single occurrence
+}
+
+// EventChanges is the result of ChangesSince.
+type EventChanges struct {
+	Changes       []EventChange `json:"changes"`
+	NextSyncToken string        `json:"next_sync_token"`
+	Baseline      bool          `json:"baseline,omitempty"` // True for the initial sync, which only returns a token
+}
+
+// changeKind classifies a synced event: cancelled events were deleted, events whose last update is
+// within a few seconds of their creation were created, anything else was updated.
+func changeKind(e *calendar.Event) string {
+	if e.Status == "cancelled" {
+		return "deleted"
+	}
+	created, err1 := time.Parse(time.RFC3339, e.Created)
+	updated, err2 := time.Parse(time.RFC3339, e.Updated)
+	if err1 == nil && err2 == nil && updated.Sub(created) < 5*time.Second {
+		return "created"
+	}
+	return "updated"
+}
+
+// ChangesSince returns the events of a calendar created, updated or deleted since syncToken, and the token
+// to pass next time. Without a syncToken it performs the initial sync and only returns a baseline token.
+func (c *CalendarService) ChangesSince(ctx context.Context, calendarId string, syncToken string) (*EventChanges, error) {
+	if calendarId == "" {
+		calendarId = "primary"
+	}
+	call := c.srv.Events.List(calendarId).MaxResults(2500)
+	if syncToken != "" {
+		call = call.SyncToken(syncToken)
+	}
+	out := &EventChanges{Changes: []EventChange{}, Baseline: syncToken == ""}
+	err := call.Pages(ctx, func(page *calendar.Events) error {
+		if syncToken != "" {
+			for _, e := range page.Items {
+				out.Changes = append(out.Changes, EventChange{
+					Change:           changeKind(e),
+					ID:               e.Id,
+					Summary:          e.Summary,
+					Start:            formatEventTime(e.Start),
+					End:              formatEventTime(e.End),
+					Updated:          e.Updated,
+					RecurringEventID: e.RecurringEventId,
+				})
+			}
+		}
+		out.NextSyncToken = page.NextSyncToken
+		return nil
+	})
+	if err != nil {
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusGone {
+			return nil, ErrSyncTokenExpired
+		}
+		return nil, fmt.Errorf("unable to sync events: %w", err)
+	}
+	return out, nil
+}
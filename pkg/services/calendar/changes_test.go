@@ -0,0 +1,25 @@
+package calendar
+
+import (
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestChangeKind(t *testing.T) {
+	tests := []struct {
+		name string
+		e    *calendar.Event
+		want string
+	}{
+		{"cancelled", &calendar.Event{Status: "cancelled", Created: "2024-05-01T10:00:00Z", Updated: "2024-05-01T10:00:00Z"}, "deleted"},
+		{"new", &calendar.Event{Status: "confirmed", Created: "2024-05-01T10:00:00.000Z", Updated: "2024-05-01T10:00:01.500Z"}, "created"},
+		{"edited", &calendar.Event{Status: "confirmed", Created: "2024-05-01T10:00:00Z", Updated: "2024-05-02T08:30:00Z"}, "updated"},
+		{"no timestamps", &calendar.Event{Status: "confirmed"}, "updated"},
+	}
+	for _, tt := range tests {
+		if got := changeKind(tt.e); got != tt.want {
+			t.Errorf("%s: changeKind = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
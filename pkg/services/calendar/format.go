@@ -0,0 +1,116 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// FormatEventDetails renders an event as readable text: time, location, conference link,
+// recurrence and each attendee's RSVP status.
+func FormatEventDetails(e *calendar.Event) string {
+	if e == nil {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Summary: %s\n", e.Summary)
+	fmt.Fprintf(&b, "ID: %s\n", e.Id)
+	if e.Status != "" {
+		fmt.Fprintf(&b, "Status: %s\n", e.Status)
+	}
+	fmt.Fprintf(&b, "Start: %s\n", formatEventTime(e.Start))
+	fmt.Fprintf(&b, "End: %s\n", formatEventTime(e.End))
+	if e.Location != "" {
+		fmt.Fprintf(&b, "Location: %s\n", e.Location)
+	}
+	if link := conferenceLink(e); link != "" {
+		fmt.Fprintf(&b, "Conference: %s\n", link)
+	}
+	if e.Organizer != nil {
+		fmt.Fprintf(&b, "Organizer: %s\n", personLabel(e.Organizer.DisplayName, e.Organizer.Email))
+	}
+	if len(e.Recurrence) > 0 {
+		fmt.Fprintf(&b, "Recurrence: %s\n", strings.Join(e.Recurrence, "; "))
+	}
+	if e.RecurringEventId != "" {
+		fmt.Fprintf(&b, "Series ID: %s\n", e.RecurringEventId)
+	}
+	if e.HtmlLink != "" {
+		fmt.Fprintf(&b, "Link: %s\n", e.HtmlLink)
+	}
+	if len(e.Attendees) > 0 {
+		b.WriteString("Attendees:\n")
+		for _, a := range e.Attendees {
+			var notes []string
+			if a.Organizer {
+				notes = append(notes, "organizer")
+			}
+			if a.Optional {
+				notes = append(notes, "optional")
+			}
+			if a.Self {
+				notes = append(notes, "you")
+			}
+			line := fmt.Sprintf("- %s: %s", personLabel(a.DisplayName, a.Email), responseLabel(a.ResponseStatus))
+			if len(notes) > 0 {
+				line += " (" + strings.Join(notes, ", ") + ")"
+			}
+			if a.Comment != "" {
+				line += fmt.Sprintf(" \"%s\"", a.Comment)
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	if e.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", e.Description)
+	}
+	return b.String()
+}
+
+// formatEventTime returns the date-time of a timed event or the date of an all-day event.
+func formatEventTime(t *calendar.EventDateTime) string {
+	if t == nil {
+		return ""
+	}
+	if t.DateTime != "" {
+		if t.TimeZone != "" {
+			return t.DateTime + " (" + t.TimeZone + ")"
+		}
+		return t.DateTime
+	}
+	return t.Date + " (all day)"
+}
+
+// conferenceLink returns the video entry point of an event, falling back to the legacy Hangout link.
+func conferenceLink(e *calendar.Event) string {
+	if e.ConferenceData != nil {
+		for _, ep := range e.ConferenceData.EntryPoints {
+			if ep.EntryPointType == "video" {
+				return ep.Uri
+			}
+		}
+	}
+	return e.HangoutLink
+}
+
+func personLabel(name string, email string) string {
+	if name == "" {
+		return email
+	}
+	return fmt.Sprintf("%s <%s>", name, email)
+}
+
+var responseLabels = map[string]string{
+	"needsAction": "no response",
+	"accepted":    "accepted",
+	"declined":    "declined",
+	"tentative":   "maybe",
+}
+
+func responseLabel(status string) string {
+	if l, ok := responseLabels[status]; ok {
+		return l
+	}
+	return status
+}
@@ -0,0 +1,112 @@
+package calendar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseRecurrence turns a recurrence argument into RFC 5545 recurrence lines.
+// It accepts newline-separated RRULE/EXDATE/RDATE lines ("FREQ=..." without a prefix is treated as an RRULE),
+// or a friendly description such as "weekly on Mon/Wed until 2025-12-31", "every 2 weeks on Fri for 6 times"
+// or "monthly".
+func ParseRecurrence(s string) ([]string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	if strings.Contains(strings.ToUpper(s), "FREQ=") || strings.Contains(s, ":") {
+		var out []string
+		for _, line := range strings.Split(s, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			if !strings.Contains(line, ":") {
+				line = "RRULE:" + line
+			}
+			out = append(out, line)
+		}
+		return out, nil
+	}
+	rule, err := friendlyRRule(s)
+	if err != nil {
+		return nil, err
+	}
+	return []string{rule}, nil
+}
+
+var (
+	frequencies = map[string]string{
+		"daily": "DAILY", "weekly": "WEEKLY", "monthly": "MONTHLY", "yearly": "YEARLY", "annually": "YEARLY",
+		"day": "DAILY", "week": "WEEKLY", "month": "MONTHLY", "year": "YEARLY",
+	}
+	weekdays = map[string]string{
+		"mon": "MO", "tue": "TU", "wed": "WE", "thu": "TH", "fri": "FR", "sat": "SA", "sun": "SU",
+	}
+)
+
+// friendlyRRule compiles "[every N] <frequency> [on <days>] [until YYYY-MM-DD] [for N times]" into an RRULE line.
+func friendlyRRule(s string) (string, error) {
+	fields := strings.Fields(strings.ToLower(strings.NewReplacer(",", " ", "/", " ").Replace(s)))
+	var freq, until, count string
+	interval := 1
+	var days []string
+
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		switch {
+		case f == "every" && i+1 < len(fields):
+			// "every 2 weeks" or "every week"
+			if n, err := strconv.Atoi(fields[i+1]); err == nil {
+				interval = n
+				i++
+			}
+		case frequencies[strings.TrimSuffix(f, "s")] != "" && freq == "":
+			freq = frequencies[strings.TrimSuffix(f, "s")]
+		case frequencies[f] != "" && freq == "":
+			freq = frequencies[f]
+		case f == "on" || f == "and" || f == "times" || f == "time":
+		case f == "until" && i+1 < len(fields):
+			d, err := time.Parse("2006-01-02", fields[i+1])
+			if err != nil {
+				return "", fmt.Errorf("invalid until date %q (use YYYY-MM-DD)", fields[i+1])
+			}
+			until = d.Format("20060102") + "T235959Z"
+			i++
+		case (f == "for" || f == "count") && i+1 < len(fields):
+			n, err := strconv.Atoi(fields[i+1])
+			if err != nil || n <= 0 {
+				return "", fmt.Errorf("invalid occurrence count %q", fields[i+1])
+			}
+			count = strconv.Itoa(n)
+			i++
+		case len(f) >= 3 && weekdays[f[:3]] != "":
+			days = append(days, weekdays[f[:3]])
+		default:
+			return "", fmt.Errorf("unrecognized recurrence %q (try 'weekly on Mon/Wed until 2025-12-31' or an RRULE)", s)
+		}
+	}
+	if freq == "" {
+		return "", fmt.Errorf("recurrence %q has no frequency (daily, weekly, monthly or yearly)", s)
+	}
+	if until != "" && count != "" {
+		return "", fmt.Errorf("recurrence can have 'until' or 'for N times', not both")
+	}
+
+	rule := "RRULE:FREQ=" + freq
+	if interval > 1 {
+		rule += fmt.Sprintf(";INTERVAL=%d", interval)
+	}
+	if len(days) > 0 {
+		rule += ";BYDAY=" + strings.Join(days, ",")
+	}
+	if until != "" {
+		rule += ";UNTIL=" + until
+	}
+	if count != "" {
+		rule += ";COUNT=" + count
+	}
+	return rule, nil
+}
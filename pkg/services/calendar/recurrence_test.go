@@ -0,0 +1,42 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "weekly on Mon/Wed until 2025-12-31", want: "RRULE:FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20251231T235959Z"},
+		{in: "every 2 weeks on Friday for 6 times", want: "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;COUNT=6"},
+		{in: "Monthly", want: "RRULE:FREQ=MONTHLY"},
+		{in: "every day", want: "RRULE:FREQ=DAILY"},
+		{in: "FREQ=DAILY;COUNT=5", want: "RRULE:FREQ=DAILY;COUNT=5"},
+		{in: "RRULE:FREQ=WEEKLY;BYDAY=TU\nEXDATE;VALUE=DATE:20250107", want: "RRULE:FREQ=WEEKLY;BYDAY=TU|EXDATE;VALUE=DATE:20250107"},
+		{in: "on Mon", wantErr: true},
+		{in: "weekly until tomorrow", wantErr: true},
+		{in: "weekly until 2025-12-31 for 3 times", wantErr: true},
+		{in: "fortnightly", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseRecurrence(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if s := strings.Join(got, "|"); s != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, s)
+			}
+		})
+	}
+}
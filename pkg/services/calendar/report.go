@@ -0,0 +1,226 @@
+package calendar
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// Ways to categorize the events of a time report.
+const (
+	CategoryByCalendar = "calendar" // The event's calendar
+	CategoryByColor    = "color"    // The event's color, e.g. "Tomato"
+	CategoryByRules    = "rules"    // The first CategoryRule whose keywords appear in the title
+)
+
+// UncategorizedCategory is the category of events no rule matches.
+const UncategorizedCategory = "Other"
+
+// eventColors are the names Google Calendar shows for event color IDs.
+var eventColors = map[string]string{
+	"1": "Lavender", "2": "Sage", "3": "Grape", "4": "Flamingo", "5": "Banana", "6": "Tangerine",
+	"7": "Peacock", "8": "Graphite", "9": "Blueberry", "10": "Basil", "11": "Tomato",
+}
+
+// CategoryRule puts the events whose title contains one of its keywords (case-insensitive) in a category.
+type CategoryRule struct {
+	Category string
+	Keywords []string
+}
+
+// ParseCategoryRules parses rules written as "Category=keyword|keyword,...", e.g.
+// "Meetings=sync|standup|1:1,Focus=focus|deep work". Rules are tried in order.
+func ParseCategoryRules(s string) ([]CategoryRule, error) {
+	var rules []CategoryRule
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		name, keywords, ok := strings.Cut(part, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid rule %q: want Category=keyword|keyword", strings.TrimSpace(part))
+		}
+		r := CategoryRule{Category: name}
+		for _, k := range strings.Split(keywords, "|") {
+			if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+				r.Keywords = append(r.Keywords, k)
+			}
+		}
+		if len(r.Keywords) == 0 {
+			return nil, fmt.Errorf("rule %q has no keywords", name)
+		}
+		rules = append(rules, r)
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("no category rules given")
+	}
+	return rules, nil
+}
+
+// ReportOptions controls how the events of a time report are categorized.
+type ReportOptions struct {
+	CategoryBy    string            // CategoryByCalendar (default), CategoryByColor or CategoryByRules
+	Rules         []CategoryRule    // For CategoryByRules
+	CalendarNames map[string]string // Display names by calendar ID; IDs are shown for calendars left out
+}
+
+// ReportEvent is an event of a time report.
+type ReportEvent struct {
+	AgendaEvent
+	Calendar  string   // Display name of the calendar
+	Attendees []string // Emails of the other attendees
+	Category  string
+}
+
+// Counted reports whether the event's time counts toward the totals: all-day events and events the user
+// is not busy for (marked as free or declined) do not.
+func (e ReportEvent) Counted() bool {
+	return !e.AllDay && e.Busy
+}
+
+// ReportEvents collects the events of several calendars between the first and last day (inclusive) in loc,
+// sorted by start time and categorized according to opts.
+func (c *CalendarService) ReportEvents(ctx context.Context, calendarIds []string, first time.Time, last time.Time, loc *time.Location, opts ReportOptions) ([]ReportEvent, error) {
+	if len(calendarIds) == 0 {
+		calendarIds = []string{"primary"}
+	}
+	from, to, err := dayRange(first, last, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []ReportEvent
+	err = c.eachEvent(ctx, calendarIds, from, to, func(id string, e *calendar.Event) {
+		if re, ok := toReportEvent(id, e, loc, opts); ok {
+			events = append(events, re)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(events, func(a, b ReportEvent) int { return a.Start.Compare(b.Start) })
+	return events, nil
+}
+
+// toReportEvent converts an API event, reporting false for cancelled or unparseable events.
+func toReportEvent(calendarID string, e *calendar.Event, loc *time.Location, opts ReportOptions) (ReportEvent, bool) {
+	ae, ok := toAgendaEvent(calendarID, e, loc)
+	if !ok {
+		return ReportEvent{}, false
+	}
+	re := ReportEvent{AgendaEvent: ae, Calendar: calendarID}
+	if name := opts.CalendarNames[calendarID]; name != "" {
+		re.Calendar = name
+	}
+	for _, a := range e.Attendees {
+		if !a.Self && !a.Resource && a.Email != "" {
+			re.Attendees = append(re.Attendees, a.Email)
+		}
+	}
+	re.Category = eventCategory(re, e.ColorId, opts)
+	return re, true
+}
+
+// eventCategory returns the category of an event.
+func eventCategory(e ReportEvent, colorID string, opts ReportOptions) string {
+	switch opts.CategoryBy {
+	case CategoryByColor:
+		if name, ok := eventColors[colorID]; ok {
+			return name
+		}
+		return "Calendar color"
+	case CategoryByRules:
+		title := strings.ToLower(e.Summary)
+		for _, r := range opts.Rules {
+			if slices.ContainsFunc(r.Keywords, func(k string) bool { return strings.Contains(title, k) }) {
+				return r.Category
+			}
+		}
+		return UncategorizedCategory
+	default:
+		return e.Calendar
+	}
+}
+
+// CategoryTotal is the time spent in the counted events of a category.
+type CategoryTotal struct {
+	Category string
+	Events   int
+	Duration time.Duration
+}
+
+// TimeTotals sums the duration of the counted events by category, longest first. Overlapping events are
+// each counted in full.
+func TimeTotals(events []ReportEvent) []CategoryTotal {
+	var totals []CategoryTotal
+	for _, e := range events {
+		if !e.Counted() {
+			continue
+		}
+		i := slices.IndexFunc(totals, func(t CategoryTotal) bool { return t.Category == e.Category })
+		if i < 0 {
+			totals = append(totals, CategoryTotal{Category: e.Category})
+			i = len(totals) - 1
+		}
+		totals[i].Events++
+		totals[i].Duration += e.End.Sub(e.Start)
+	}
+	slices.SortFunc(totals, func(a, b CategoryTotal) int {
+		return cmp.Or(cmp.Compare(b.Duration, a.Duration), strings.Compare(a.Category, b.Category))
+	})
+	return totals
+}
+
+// ReportRows renders events as spreadsheet rows below a header. Durations are numbers of hours, so that
+// they can be summed and charted; all-day events have none.
+func ReportRows(events []ReportEvent) [][]interface{} {
+	rows := [][]interface{}{{"Date", "Start", "End", "Title", "Hours", "Category", "Calendar", "Attendees", "Counted"}}
+	for _, e := range events {
+		start, end, hours := "all day", "", interface{}("")
+		if !e.AllDay {
+			start, end, hours = e.Start.Format("15:04"), e.End.Format("15:04"), roundHours(e.End.Sub(e.Start))
+		}
+		counted := "no"
+		if e.Counted() {
+			counted = "yes"
+		}
+		rows = append(rows, []interface{}{
+			e.Start.Format("2006-01-02"), start, end, e.Summary, hours, e.Category, e.Calendar,
+			strings.Join(e.Attendees, ", "), counted,
+		})
+	}
+	return rows
+}
+
+// TotalsRows renders category totals as spreadsheet rows below a header, followed by an overall total.
+func TotalsRows(totals []CategoryTotal) [][]interface{} {
+	rows := [][]interface{}{{"Category", "Events", "Hours", "Share"}}
+	var sum time.Duration
+	events := 0
+	for _, t := range totals {
+		sum += t.Duration
+		events += t.Events
+	}
+	share := func(d time.Duration) float64 {
+		if sum == 0 {
+			return 0
+		}
+		return math.Round(float64(d)/float64(sum)*1000) / 1000
+	}
+	for _, t := range totals {
+		rows = append(rows, []interface{}{t.Category, t.Events, roundHours(t.Duration), share(t.Duration)})
+	}
+	return append(rows, []interface{}{"Total", events, roundHours(sum), share(sum)})
+}
+
+// roundHours returns a duration in hours, rounded to two decimals.
+func roundHours(d time.Duration) float64 {
+	return math.Round(d.Hours()*100) / 100
+}
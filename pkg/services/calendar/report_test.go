@@ -0,0 +1,126 @@
+package calendar
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestParseCategoryRules(t *testing.T) {
+	got, err := ParseCategoryRules(" Meetings = Sync|standup| ,Focus=deep work,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CategoryRule{{"Meetings", []string{"sync", "standup"}}, {"Focus", []string{"deep work"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseCategoryRules() = %+v, want %+v", got, want)
+	}
+
+	for _, in := range []string{"", "Meetings", "=sync", "Meetings=|"} {
+		if _, err := ParseCategoryRules(in); err == nil {
+			t.Errorf("ParseCategoryRules(%q) should fail", in)
+		}
+	}
+}
+
+func TestReportEvents(t *testing.T) {
+	loc := time.UTC
+	rules, _ := ParseCategoryRules("Meetings=sync|1:1,Focus=focus")
+	event := func(summary, start, end, color string, attendees ...*calendar.EventAttendee) *calendar.Event {
+		return &calendar.Event{
+			Summary:   summary,
+			Start:     &calendar.EventDateTime{DateTime: start},
+			End:       &calendar.EventDateTime{DateTime: end},
+			ColorId:   color,
+			Attendees: attendees,
+		}
+	}
+	api := []*calendar.Event{
+		event("Team sync", "2025-03-03T10:00:00Z", "2025-03-03T11:30:00Z", "11",
+			&calendar.EventAttendee{Email: "me@example.com", Self: true, ResponseStatus: "accepted"},
+			&calendar.EventAttendee{Email: "ann@example.com"},
+			&calendar.EventAttendee{Email: "room@resource.calendar.google.com", Resource: true}),
+		event("Focus time", "2025-03-03T13:00:00Z", "2025-03-03T15:00:00Z", ""),
+		event("1:1 Bob", "2025-03-04T09:00:00Z", "2025-03-04T09:30:00Z", "11"),
+		event("Lunch", "2025-03-04T12:00:00Z", "2025-03-04T13:00:00Z", "2",
+			&calendar.EventAttendee{Email: "me@example.com", Self: true, ResponseStatus: "declined"}),
+		{Summary: "Holiday", Start: &calendar.EventDateTime{Date: "2025-03-05"}, End: &calendar.EventDateTime{Date: "2025-03-06"}},
+	}
+
+	var events []ReportEvent
+	for _, opts := range []ReportOptions{
+		{CategoryBy: CategoryByRules, Rules: rules},
+		{CategoryBy: CategoryByColor},
+		{CalendarNames: map[string]string{"primary": "Work"}},
+	} {
+		events = events[:0]
+		for _, e := range api {
+			if re, ok := toReportEvent("primary", e, loc, opts); ok {
+				events = append(events, re)
+			}
+		}
+		var categories []string
+		for _, e := range events {
+			categories = append(categories, e.Category)
+		}
+		var want []string
+		switch opts.CategoryBy {
+		case CategoryByRules:
+			want = []string{"Meetings", "Focus", "Meetings", "Other", "Other"}
+		case CategoryByColor:
+			want = []string{"Tomato", "Calendar color", "Tomato", "Sage", "Calendar color"}
+		default:
+			want = []string{"Work", "Work", "Work", "Work", "Work"}
+		}
+		if !reflect.DeepEqual(categories, want) {
+			t.Errorf("categories by %q = %v, want %v", opts.CategoryBy, categories, want)
+		}
+	}
+	if got := events[0].Attendees; !reflect.DeepEqual(got, []string{"ann@example.com"}) {
+		t.Errorf("attendees = %v, want only the other people", got)
+	}
+
+	totals := TimeTotals(events)
+	if len(totals) != 1 || totals[0] != (CategoryTotal{"Work", 3, 4 * time.Hour}) {
+		t.Errorf("TimeTotals() = %+v, want the declined and all-day events left out", totals)
+	}
+
+	rows := ReportRows(events)
+	if len(rows) != 6 {
+		t.Fatalf("ReportRows() returned %d rows, want a header and 5 events", len(rows))
+	}
+	if want := []interface{}{"2025-03-03", "10:00", "11:30", "Team sync", 1.5, "Work", "Work", "ann@example.com", "yes"}; !reflect.DeepEqual(rows[1], want) {
+		t.Errorf("event row = %v, want %v", rows[1], want)
+	}
+	if got := rows[5]; got[1] != "all day" || got[4] != "" || got[8] != "no" {
+		t.Errorf("all-day row = %v", got)
+	}
+}
+
+func TestTotalsRows(t *testing.T) {
+	events := []ReportEvent{
+		{AgendaEvent: AgendaEvent{Start: time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC), End: time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC), Busy: true}, Category: "B"},
+		{AgendaEvent: AgendaEvent{Start: time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC), End: time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC), Busy: true}, Category: "A"},
+		{AgendaEvent: AgendaEvent{Start: time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC), End: time.Date(2025, 3, 3, 13, 0, 0, 0, time.UTC), Busy: true}, Category: "C"},
+	}
+	var got []string
+	for _, row := range TotalsRows(TimeTotals(events)) {
+		var cells []string
+		for _, v := range row {
+			cells = append(cells, fmt.Sprint(v))
+		}
+		got = append(got, strings.Join(cells, "|"))
+	}
+	want := []string{"Category|Events|Hours|Share", "A|1|2|0.5", "B|1|1|0.25", "C|1|1|0.25", "Total|3|4|1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TotalsRows() = %v, want %v", got, want)
+	}
+
+	if rows := TotalsRows(nil); !reflect.DeepEqual(rows[1], []interface{}{"Total", 0, 0.0, 0.0}) {
+		t.Errorf("TotalsRows(nil) total = %v", rows[1])
+	}
+}
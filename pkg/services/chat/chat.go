@@ -0,0 +1,144 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/api/chat/v1"
+	"google.golang.org/api/option"
+)
+
+// Service wraps the Google Chat API (google-api-go-client chat/v1).
+type Service struct {
+	srv *chat.Service
+}
+
+// New creates a new Service using the given client options (e.g. from auth).
+func New(ctx context.Context, opts ...option.ClientOption) (*Service, error) {
+	srv, err := chat.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve Chat client: %w", err)
+	}
+	return &Service{srv: srv}, nil
+}
+
+// Ping makes a cheap request to check that the API is enabled and its scope granted.
+func (s *Service) Ping(ctx context.Context) error {
+	if _, err := s.srv.Spaces.List().PageSize(1).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("unable to list spaces: %w", err)
+	}
+	return nil
+}
+
+// SpaceName normalizes a space ID, resource name or Chat URL to a space resource name ("AAAA123" -> "spaces/AAAA123").
+func SpaceName(space string) string {
+	space = strings.TrimSpace(space)
+	for _, marker := range []string{"spaces/", "/space/", "/room/"} {
+		if i := strings.Index(space, marker); i >= 0 {
+			space = space[i+len(marker):]
+			break
+		}
+	}
+	if i := strings.IndexAny(space, "/?#"); i >= 0 {
+		space = space[:i]
+	}
+	return "spaces/" + space
+}
+
+// ListSpaces lists the spaces (rooms, group chats and direct messages) the user is a member of.
+// spaceType may be SPACE, GROUP_CHAT or DIRECT_MESSAGE to filter, or empty for all.
+func (s *Service) ListSpaces(ctx context.Context, spaceType string, pageSize int64, pageToken string) (*chat.ListSpacesResponse, error) {
+	call := s.srv.Spaces.List()
+	if spaceType != "" {
+		call = call.Filter(fmt.Sprintf("spaceType = %q", strings.ToUpper(spaceType)))
+	}
+	if pageSize > 0 {
+		call = call.PageSize(pageSize)
+	}
+	if pageToken != "" {
+		call = call.PageToken(pageToken)
+	}
+	resp, err := call.Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list spaces: %w", err)
+	}
+	return resp, nil
+}
+
+// Message is a compact view of a Chat message.
+type Message struct {
+	Name       string `json:"name"`
+	Sender     string `json:"sender,omitempty"`
+	CreateTime string `json:"create_time"`
+	Thread     string `json:"thread,omitempty"`
+	Text       string `json:"text"`
+}
+
+// messageView converts an API message to its compact view.
+func messageView(m *chat.Message) Message {
+	out := Message{Name: m.Name, CreateTime: m.CreateTime, Text: m.Text}
+	if m.Sender != nil {
+		out.Sender = m.Sender.DisplayName
+		if out.Sender == "" {
+			out.Sender = m.Sender.Name
+		}
+	}
+	if m.Thread != nil {
+		out.Thread = m.Thread.Name
+	}
+	if out.Text == "" && len(m.CardsV2) > 0 {
+		out.Text = "[card]"
+	}
+	return out
+}
+
+// RecentMessages returns up to limit of the most recent messages in a space, oldest first.
+// A non-zero since only returns messages created after it.
+func (s *Service) RecentMessages(ctx context.Context, space string, limit int64, since time.Time) ([]Message, error) {
+	if limit <= 0 {
+		limit = 25
+	}
+	call := s.srv.Spaces.Messages.List(SpaceName(space)).OrderBy("createTime desc").PageSize(limit)
+	if !since.IsZero() {
+		call = call.Filter(fmt.Sprintf("createTime > %q", since.UTC().Format(time.RFC3339)))
+	}
+	resp, err := call.Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list messages: %w", err)
+	}
+	out := make([]Message, len(resp.Messages))
+	for i, m := range resp.Messages {
+		out[i] = messageView(m)
+	}
+	slices.Reverse(out)
+	return out, nil
+}
+
+// SendMessage posts a message to a space. thread may be a thread resource name (spaces/x/threads/y) or
+// an app-defined thread key to reply in; card, if set, is attached as a card (cards require app authentication).
+func (s *Service) SendMessage(ctx context.Context, space, text, thread string, card *chat.GoogleAppsCardV1Card) (*chat.Message, error) {
+	if text == "" && card == nil {
+		return nil, fmt.Errorf("text or card is required")
+	}
+	msg := &chat.Message{Text: text}
+	if card != nil {
+		msg.CardsV2 = []*chat.CardWithId{{CardId: "card", Card: card}}
+	}
+	call := s.srv.Spaces.Messages.Create(SpaceName(space), msg)
+	if thread != "" {
+		if strings.Contains(thread, "/threads/") {
+			msg.Thread = &chat.Thread{Name: thread}
+		} else {
+			call = call.ThreadKey(thread)
+		}
+		call = call.MessageReplyOption("REPLY_MESSAGE_FALLBACK_TO_NEW_THREAD")
+	}
+	sent, err := call.Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to send message: %w", err)
+	}
+	return sent, nil
+}
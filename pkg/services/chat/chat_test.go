@@ -0,0 +1,18 @@
+package chat
+
+import "testing"
+
+func TestSpaceName(t *testing.T) {
+	tests := map[string]string{
+		"AAAA123":                     "spaces/AAAA123",
+		"spaces/AAAA123":              "spaces/AAAA123",
+		"spaces/AAAA123/messages/x.y": "spaces/AAAA123",
+		"https://mail.google.com/chat/u/0/#chat/space/AAAA123": "spaces/AAAA123",
+		"https://chat.google.com/room/AAAA123?cls=7":           "spaces/AAAA123",
+	}
+	for in, want := range tests {
+		if got := SpaceName(in); got != want {
+			t.Errorf("SpaceName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -2,9 +2,12 @@ package docs
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 
 	"google.golang.org/api/docs/v1"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 )
 
@@ -22,12 +25,23 @@ func New(ctx context.Context, opts ...option.ClientOption) (*DocsService, error)
 	return &DocsService{srv: srv}, nil
 }
 
+// Ping makes a cheap request to check that the API is enabled and its scope granted.
+func (d *DocsService) Ping(ctx context.Context) error {
+	// There is no cheap call without a document: a missing document (404) means the API answered
+	_, err := d.srv.Documents.Get("health-check").Context(ctx).Do()
+	var apiErr *googleapi.Error
+	if err == nil || errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+		return nil
+	}
+	return fmt.Errorf("unable to reach Docs API: %w", err)
+}
+
 // CreateDocument creates a new document.
-func (d *DocsService) CreateDocument(title string) (*docs.Document, error) {
+func (d *DocsService) CreateDocument(ctx context.Context, title string) (*docs.Document, error) {
 	doc := &docs.Document{
 		Title: title,
 	}
-	resp, err := d.srv.Documents.Create(doc).Do()
+	resp, err := d.srv.Documents.Create(doc).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to create document: %w", err)
 	}
@@ -35,30 +49,66 @@ func (d *DocsService) CreateDocument(title string) (*docs.Document, error) {
 }
 
 // GetDocument reads a document.
-func (d *DocsService) GetDocument(documentId string) (*docs.Document, error) {
-	doc, err := d.srv.Documents.Get(documentId).Do()
+func (d *DocsService) GetDocument(ctx context.Context, documentId string) (*docs.Document, error) {
+	doc, err := d.srv.Documents.Get(documentId).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve document: %w", err)
 	}
 	return doc, nil
 }
 
-// InsertText inserts text at an index (or end if index=0, though Docs API is precise).
-// Simpler: Insert at end using EndOfSegmentLocation.
-func (d *DocsService) InsertText(documentId string, text string) error {
-	req := &docs.Request{
+// InsertText appends text at the end of the document body.
+func (d *DocsService) InsertText(ctx context.Context, documentId string, text string) error {
+	_, err := d.batchUpdate(ctx, documentId, &docs.Request{
 		InsertText: &docs.InsertTextRequest{
-			Text: text,
-			EndOfSegmentLocation: &docs.EndOfSegmentLocation{
-				SegmentId: "", // Body
-			},
+			Text:                 text,
+			EndOfSegmentLocation: &docs.EndOfSegmentLocation{}, // Body
 		},
+	})
+	return err
+}
+
+// batchUpdate applies requests to a document.
+func (d *DocsService) batchUpdate(ctx context.Context, documentId string, reqs ...*docs.Request) (*docs.BatchUpdateDocumentResponse, error) {
+	resp, err := d.srv.Documents.BatchUpdate(documentId, &docs.BatchUpdateDocumentRequest{Requests: reqs}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to update document: %w", err)
 	}
+	return resp, nil
+}
 
-	batchUpdate := &docs.BatchUpdateDocumentRequest{
-		Requests: []*docs.Request{req},
+// InsertTextAt inserts text at a body index. Index 1 is the start of the document.
+func (d *DocsService) InsertTextAt(ctx context.Context, documentId string, index int64, text string) error {
+	if index < 1 {
+		return fmt.Errorf("index must be at least 1")
 	}
+	_, err := d.batchUpdate(ctx, documentId, &docs.Request{
+		InsertText: &docs.InsertTextRequest{Text: text, Location: &docs.Location{Index: index}},
+	})
+	return err
+}
+
+// ReplaceText replaces every occurrence of find in the document and returns how many were replaced.
+func (d *DocsService) ReplaceText(ctx context.Context, documentId, find, replacement string, matchCase bool) (int64, error) {
+	resp, err := d.batchUpdate(ctx, documentId, &docs.Request{
+		ReplaceAllText: &docs.ReplaceAllTextRequest{
+			ContainsText: &docs.SubstringMatchCriteria{Text: find, MatchCase: matchCase},
+			ReplaceText:  replacement,
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return resp.Replies[0].ReplaceAllText.OccurrencesChanged, nil
+}
 
-	_, err := d.srv.Documents.BatchUpdate(documentId, batchUpdate).Do()
+// DeleteRange deletes the body content in [start, end).
+func (d *DocsService) DeleteRange(ctx context.Context, documentId string, start, end int64) error {
+	if start < 1 || end <= start {
+		return fmt.Errorf("invalid range %d-%d: start must be at least 1 and end greater than start", start, end)
+	}
+	_, err := d.batchUpdate(ctx, documentId, &docs.Request{
+		DeleteContentRange: &docs.DeleteContentRangeRequest{Range: &docs.Range{StartIndex: start, EndIndex: end}},
+	})
 	return err
 }
@@ -0,0 +1,313 @@
+package docs
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// monospaceFonts are rendered as inline code or code blocks.
+var monospaceFonts = map[string]bool{
+	"Courier New": true, "Roboto Mono": true, "Source Code Pro": true, "Consolas": true,
+	"Inconsolata": true, "Fira Code": true, "JetBrains Mono": true, "Ubuntu Mono": true, "Space Mono": true,
+}
+
+// Heading is an entry of a document outline. EndIndex is where the heading's section ends:
+// the next heading of the same or a higher level, or the end of the body.
+type Heading struct {
+	Level      int    `json:"level"`
+	Text       string `json:"text"`
+	StartIndex int64  `json:"start_index"`
+	EndIndex   int64  `json:"end_index"`
+}
+
+// headingLevel returns the Markdown heading level of a named style, or 0 for body text.
+func headingLevel(style string) int {
+	switch style {
+	case "TITLE":
+		return 1
+	case "SUBTITLE":
+		return 2
+	}
+	var n int
+	if _, err := fmt.Sscanf(style, "HEADING_%d", &n); err == nil {
+		return n
+	}
+	return 0
+}
+
+// paragraphText returns the text of a paragraph without its trailing newline.
+func paragraphText(p *docs.Paragraph) string {
+	var b strings.Builder
+	for _, el := range p.Elements {
+		if el.TextRun != nil {
+			b.WriteString(el.TextRun.Content)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// Outline lists the headings of the document body with the index range of their sections.
+func Outline(doc *docs.Document) []Heading {
+	var out []Heading
+	if doc.Body == nil {
+		return out
+	}
+	var end int64
+	for _, el := range doc.Body.Content {
+		end = max(end, el.EndIndex)
+		if el.Paragraph == nil || el.Paragraph.ParagraphStyle == nil {
+			continue
+		}
+		level := headingLevel(el.Paragraph.ParagraphStyle.NamedStyleType)
+		if level == 0 {
+			continue
+		}
+		for i := range out {
+			if out[i].EndIndex == 0 && out[i].Level >= level {
+				out[i].EndIndex = el.StartIndex
+			}
+		}
+		out = append(out, Heading{Level: level, Text: strings.TrimSpace(paragraphText(el.Paragraph)), StartIndex: el.StartIndex})
+	}
+	for i := range out {
+		if out[i].EndIndex == 0 {
+			out[i].EndIndex = end
+		}
+	}
+	return out
+}
+
+// SectionRange returns the index range of the section under the first heading containing title
+// (case-insensitive; an exact match wins).
+func SectionRange(doc *docs.Document, title string) (int64, int64, error) {
+	headings := Outline(doc)
+	want := strings.ToLower(strings.TrimSpace(title))
+	var match *Heading
+	for i, h := range headings {
+		text := strings.ToLower(h.Text)
+		if text == want {
+			match = &headings[i]
+			break
+		}
+		if match == nil && strings.Contains(text, want) {
+			match = &headings[i]
+		}
+	}
+	if match == nil {
+		names := make([]string, len(headings))
+		for i, h := range headings {
+			names[i] = h.Text
+		}
+		if len(names) == 0 {
+			return 0, 0, fmt.Errorf("section %q not found: the document has no headings", title)
+		}
+		return 0, 0, fmt.Errorf("section %q not found (headings: %s)", title, strings.Join(names, "; "))
+	}
+	return match.StartIndex, match.EndIndex, nil
+}
+
+// bodyElements returns the top-level body elements overlapping [start, end); end <= 0 means the end of the body.
+func bodyElements(doc *docs.Document, start, end int64) []*docs.StructuralElement {
+	if doc.Body == nil {
+		return nil
+	}
+	var out []*docs.StructuralElement
+	for _, el := range doc.Body.Content {
+		if el.EndIndex <= start || end > 0 && el.StartIndex >= end {
+			continue
+		}
+		out = append(out, el)
+	}
+	return out
+}
+
+// ToMarkdown renders the body elements overlapping [start, end) as Markdown, keeping headings, lists,
+// bold/italic, links, code and tables. end <= 0 means the end of the body.
+func ToMarkdown(doc *docs.Document, start, end int64) string {
+	r := mdRenderer{doc: doc}
+	r.render(bodyElements(doc, start, end))
+	return strings.TrimSpace(r.out.String()) + "\n"
+}
+
+// ToPlainText returns the text of the body elements overlapping [start, end), including table cells.
+func ToPlainText(doc *docs.Document, start, end int64) string {
+	var b strings.Builder
+	walkParagraphs(bodyElements(doc, start, end), func(p *docs.Paragraph) {
+		for _, el := range p.Elements {
+			if el.TextRun != nil {
+				b.WriteString(el.TextRun.Content)
+			}
+		}
+	})
+	return b.String()
+}
+
+type mdRenderer struct {
+	doc    *docs.Document
+	out    strings.Builder
+	inList bool
+	code   []string // Lines of an open code block
+}
+
+// block starts a new block, separated from the previous one by a blank line (list items stay together).
+func (r *mdRenderer) block(listItem bool) {
+	if r.out.Len() > 0 && !(listItem && r.inList) {
+		r.out.WriteString("\n")
+	}
+	r.inList = listItem
+}
+
+func (r *mdRenderer) flushCode() {
+	if r.code == nil {
+		return
+	}
+	r.block(false)
+	r.out.WriteString("```\n" + strings.Join(r.code, "\n") + "\n```\n")
+	r.code = nil
+}
+
+func (r *mdRenderer) render(elems []*docs.StructuralElement) {
+	for _, el := range elems {
+		switch {
+		case el.Paragraph != nil:
+			r.paragraph(el.Paragraph)
+		case el.Table != nil:
+			r.flushCode()
+			r.block(false)
+			r.out.WriteString(r.table(el.Table))
+		}
+	}
+	r.flushCode()
+}
+
+func (r *mdRenderer) paragraph(p *docs.Paragraph) {
+	if isCodeParagraph(p) {
+		r.code = append(r.code, strings.ReplaceAll(paragraphText(p), "\v", "\n"))
+		return
+	}
+	r.flushCode()
+	text := r.inline(p)
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	if p.Bullet != nil {
+		r.block(true)
+		level := p.Bullet.NestingLevel
+		marker := "- "
+		if r.ordered(p.Bullet.ListId, level) {
+			marker = "1. "
+		}
+		r.out.WriteString(strings.Repeat("  ", int(level)) + marker + text + "\n")
+		return
+	}
+	r.block(false)
+	if p.ParagraphStyle != nil {
+		if level := headingLevel(p.ParagraphStyle.NamedStyleType); level > 0 {
+			r.out.WriteString(strings.Repeat("#", level) + " " + strings.TrimSpace(text) + "\n")
+			return
+		}
+	}
+	r.out.WriteString(text + "\n")
+}
+
+// ordered reports whether a list level is numbered rather than bulleted.
+func (r *mdRenderer) ordered(listID string, level int64) bool {
+	list, ok := r.doc.Lists[listID]
+	if !ok || list.ListProperties == nil || int(level) >= len(list.ListProperties.NestingLevels) {
+		return false
+	}
+	nl := list.ListProperties.NestingLevels[level]
+	return nl.GlyphType != "" && nl.GlyphType != "GLYPH_TYPE_UNSPECIFIED" && nl.GlyphType != "NONE"
+}
+
+func isCodeParagraph(p *docs.Paragraph) bool {
+	hasText := false
+	for _, el := range p.Elements {
+		if el.TextRun == nil || strings.TrimSpace(el.TextRun.Content) == "" {
+			continue
+		}
+		hasText = true
+		if !isCode(el.TextRun.TextStyle) {
+			return false
+		}
+	}
+	return hasText
+}
+
+func isCode(s *docs.TextStyle) bool {
+	return s != nil && s.WeightedFontFamily != nil && monospaceFonts[s.WeightedFontFamily.FontFamily]
+}
+
+// inline renders the runs of a paragraph with Markdown inline styles.
+func (r *mdRenderer) inline(p *docs.Paragraph) string {
+	var b strings.Builder
+	for _, el := range p.Elements {
+		switch {
+		case el.TextRun != nil:
+			b.WriteString(styledRun(strings.TrimRight(el.TextRun.Content, "\n"), el.TextRun.TextStyle))
+		case el.InlineObjectElement != nil:
+			b.WriteString("[image]")
+		case el.Person != nil && el.Person.PersonProperties != nil:
+			b.WriteString(el.Person.PersonProperties.Email)
+		case el.RichLink != nil && el.RichLink.RichLinkProperties != nil:
+			b.WriteString(fmt.Sprintf("[%s](%s)", el.RichLink.RichLinkProperties.Title, el.RichLink.RichLinkProperties.Uri))
+		}
+	}
+	return strings.ReplaceAll(b.String(), "\v", "  \n")
+}
+
+// styledRun wraps text in Markdown markers, keeping surrounding whitespace outside of them.
+func styledRun(text string, s *docs.TextStyle) string {
+	core := strings.TrimSpace(text)
+	if core == "" || s == nil {
+		return text
+	}
+	lead := text[:strings.Index(text, core)]
+	trail := text[len(lead)+len(core):]
+	switch {
+	case isCode(s):
+		core = "`" + core + "`"
+	default:
+		if s.Bold && s.Italic {
+			core = "***" + core + "***"
+		} else if s.Bold {
+			core = "**" + core + "**"
+		} else if s.Italic {
+			core = "*" + core + "*"
+		}
+		if s.Strikethrough {
+			core = "~~" + core + "~~"
+		}
+	}
+	if s.Link != nil && s.Link.Url != "" {
+		core = "[" + core + "](" + s.Link.Url + ")"
+	}
+	return lead + core + trail
+}
+
+// table renders a table as a pipe table; the first row is treated as the header.
+func (r *mdRenderer) table(t *docs.Table) string {
+	var b strings.Builder
+	for i, row := range t.TableRows {
+		b.WriteString("|")
+		for _, cell := range row.TableCells {
+			var parts []string
+			for _, el := range cell.Content {
+				if el.Paragraph != nil {
+					if text := strings.TrimSpace(r.inline(el.Paragraph)); text != "" {
+						parts = append(parts, text)
+					}
+				}
+			}
+			text := strings.ReplaceAll(strings.Join(parts, " <br> "), "|", `\|`)
+			b.WriteString(" " + strings.ReplaceAll(text, "\n", " ") + " |")
+		}
+		b.WriteString("\n")
+		if i == 0 {
+			b.WriteString("|" + strings.Repeat(" --- |", len(row.TableCells)) + "\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,105 @@
+package docs
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/docs/v1"
+)
+
+func styledParagraph(start int64, style string, runs ...*docs.ParagraphElement) *docs.StructuralElement {
+	end := start
+	for _, r := range runs {
+		end += utf16Len(r.TextRun.Content)
+	}
+	return &docs.StructuralElement{StartIndex: start, EndIndex: end, Paragraph: &docs.Paragraph{
+		Elements:       runs,
+		ParagraphStyle: &docs.ParagraphStyle{NamedStyleType: style},
+	}}
+}
+
+func styledRunElem(text string, style *docs.TextStyle) *docs.ParagraphElement {
+	return &docs.ParagraphElement{TextRun: &docs.TextRun{Content: text, TextStyle: style}}
+}
+
+func testDocument() *docs.Document {
+	mono := &docs.TextStyle{WeightedFontFamily: &docs.WeightedFontFamily{FontFamily: "Courier New"}}
+	bullet := styledParagraph(29, "NORMAL_TEXT", styledRunElem("item\n", nil))
+	bullet.Paragraph.Bullet = &docs.Bullet{ListId: "l1"}
+	nested := styledParagraph(34, "NORMAL_TEXT", styledRunElem("sub\n", nil))
+	nested.Paragraph.Bullet = &docs.Bullet{ListId: "l1", NestingLevel: 1}
+	return &docs.Document{
+		Lists: map[string]docs.List{"l1": {ListProperties: &docs.ListProperties{NestingLevels: []*docs.NestingLevel{
+			{GlyphType: "DECIMAL"}, {GlyphSymbol: "●"},
+		}}}},
+		Body: &docs.Body{Content: []*docs.StructuralElement{
+			styledParagraph(1, "HEADING_1", styledRunElem("Intro\n", nil)),
+			styledParagraph(7, "NORMAL_TEXT", styledRunElem("Hello ", nil), styledRunElem("bold ", &docs.TextStyle{Bold: true}), styledRunElem("site", &docs.TextStyle{Link: &docs.Link{Url: "https://x.io"}}), styledRunElem("\n", nil)),
+			styledParagraph(23, "HEADING_2", styledRunElem("Todo\n", nil)),
+			bullet,
+			nested,
+			styledParagraph(38, "NORMAL_TEXT", styledRunElem("x := 1\n", mono)),
+			{StartIndex: 45, EndIndex: 60, Table: &docs.Table{TableRows: []*docs.TableRow{
+				{TableCells: []*docs.TableCell{{Content: []*docs.StructuralElement{styledParagraph(47, "NORMAL_TEXT", styledRunElem("A|B\n", nil))}}}},
+				{TableCells: []*docs.TableCell{{Content: []*docs.StructuralElement{styledParagraph(53, "NORMAL_TEXT", styledRunElem("1\n", nil))}}}},
+			}}},
+			styledParagraph(60, "HEADING_1", styledRunElem("Next\n", nil)),
+		}},
+	}
+}
+
+func TestToMarkdown(t *testing.T) {
+	got := ToMarkdown(testDocument(), 0, 0)
+	want := strings.Join([]string{
+		"# Intro",
+		"",
+		"Hello **bold** [site](https://x.io)",
+		"",
+		"## Todo",
+		"",
+		"1. item",
+		"  - sub",
+		"",
+		"```",
+		"x := 1",
+		"```",
+		"",
+		`| A\|B |`,
+		"| --- |",
+		"| 1 |",
+		"",
+		"# Next",
+		"",
+	}, "\n")
+	if got != want {
+		t.Errorf("ToMarkdown:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestOutlineAndSections(t *testing.T) {
+	doc := testDocument()
+	want := []Heading{
+		{Level: 1, Text: "Intro", StartIndex: 1, EndIndex: 60},
+		{Level: 2, Text: "Todo", StartIndex: 23, EndIndex: 60},
+		{Level: 1, Text: "Next", StartIndex: 60, EndIndex: 65},
+	}
+	if got := Outline(doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("Outline = %+v, want %+v", got, want)
+	}
+
+	start, end, err := SectionRange(doc, "todo")
+	if err != nil || start != 23 || end != 60 {
+		t.Errorf("SectionRange(todo) = %d, %d, %v", start, end, err)
+	}
+	if md := ToMarkdown(doc, start, end); !strings.HasPrefix(md, "## Todo") || strings.Contains(md, "Intro") || strings.Contains(md, "Next") {
+		t.Errorf("unexpected section markdown:\n%s", md)
+	}
+	if _, _, err := SectionRange(doc, "missing"); err == nil || !strings.Contains(err.Error(), "Intro; Todo; Next") {
+		t.Errorf("expected error listing headings, got %v", err)
+	}
+
+	if text := ToPlainText(doc, 45, 60); text != "A|B\n1\n" {
+		t.Errorf("ToPlainText of the table = %q", text)
+	}
+}
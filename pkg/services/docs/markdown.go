@@ -0,0 +1,604 @@
+package docs
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// blockKind is the kind of a Markdown block.
+type blockKind int
+
+const (
+	blockParagraph blockKind = iota
+	blockHeading
+	blockBullet
+	blockNumbered
+	blockCode
+	blockQuote
+	blockTable
+)
+
+// mdBlock is a parsed Markdown block. Level is the heading level (1-6) or the list nesting depth (0-based).
+type mdBlock struct {
+	Kind  blockKind
+	Level int
+	Text  string
+	Rows  [][]string // Table cells; the first row is the header
+}
+
+var (
+	headingRe   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	listItemRe  = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	tableSepRe  = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	ruleRe      = regexp.MustCompile(`^\s*([-*_])(\s*([-*_])){2,}\s*$`)
+	codeFenceRe = regexp.MustCompile("^\\s*(```|~~~)")
+)
+
+// parseMarkdown splits Markdown into blocks. It understands ATX headings, paragraphs, nested bullet and
+// numbered lists, fenced code blocks, block quotes and pipe tables; horizontal rules are dropped.
+func parseMarkdown(md string) []mdBlock {
+	lines := strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n")
+	var blocks []mdBlock
+	var para []string
+	var indents []int // Indent widths of the open list levels
+
+	flush := func() {
+		if len(para) > 0 {
+			blocks = append(blocks, mdBlock{Kind: blockParagraph, Text: strings.Join(para, " ")})
+			para = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if m := codeFenceRe.FindStringSubmatch(line); m != nil {
+			flush()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), m[1]); i++ {
+				code = append(code, lines[i])
+			}
+			blocks = append(blocks, mdBlock{Kind: blockCode, Text: strings.Join(code, "\n")})
+			indents = nil
+			continue
+		}
+		if trimmed == "" {
+			flush()
+			continue
+		}
+		if m := headingRe.FindStringSubmatch(trimmed); m != nil {
+			flush()
+			blocks = append(blocks, mdBlock{Kind: blockHeading, Level: len(m[1]), Text: m[2]})
+			indents = nil
+			continue
+		}
+		if ruleRe.MatchString(line) {
+			flush()
+			indents = nil
+			continue
+		}
+		if m := listItemRe.FindStringSubmatch(line); m != nil {
+			flush()
+			indent := len(strings.ReplaceAll(m[1], "\t", "    "))
+			for len(indents) > 0 && indent < indents[len(indents)-1] {
+				indents = indents[:len(indents)-1]
+			}
+			if len(indents) == 0 || indent > indents[len(indents)-1] {
+				indents = append(indents, indent)
+			}
+			kind := blockBullet
+			if m[2][0] >= '0' && m[2][0] <= '9' {
+				kind = blockNumbered
+			}
+			blocks = append(blocks, mdBlock{Kind: kind, Level: len(indents) - 1, Text: m[3]})
+			continue
+		}
+		if strings.HasPrefix(trimmed, ">") {
+			flush()
+			var quote []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				quote = append(quote, strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")))
+			}
+			i--
+			blocks = append(blocks, mdBlock{Kind: blockQuote, Text: strings.Join(quote, " ")})
+			indents = nil
+			continue
+		}
+		if strings.HasPrefix(trimmed, "|") && i+1 < len(lines) && tableSepRe.MatchString(lines[i+1]) {
+			flush()
+			rows := [][]string{splitTableRow(trimmed)}
+			for i += 2; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+				rows = append(rows, splitTableRow(strings.TrimSpace(lines[i])))
+			}
+			i--
+			blocks = append(blocks, mdBlock{Kind: blockTable, Rows: rows})
+			indents = nil
+			continue
+		}
+		if len(para) == 0 && len(indents) > 0 && len(blocks) > 0 && strings.HasPrefix(line, " ") {
+			// Indented continuation of the previous list item.
+			blocks[len(blocks)-1].Text += " " + trimmed
+			continue
+		}
+		indents = nil
+		para = append(para, trimmed)
+	}
+	flush()
+	return blocks
+}
+
+// splitTableRow splits "| a | b |" into its trimmed cells, honouring escaped pipes.
+func splitTableRow(line string) []string {
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+	var cells []string
+	var cur strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cur.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cur.String()))
+			cur.Reset()
+		default:
+			cur.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cur.String()))
+}
+
+// inlineSpan is a styled part of a paragraph's text, in UTF-16 offsets relative to the paragraph start.
+type inlineSpan struct {
+	Start, End int64
+	Bold       bool
+	Italic     bool
+	Code       bool
+	Link       string
+}
+
+// parseInline strips inline Markdown (**bold**, *italic*, _italic_, `code`, [text](url), \escapes)
+// from s and returns the plain text with the styled spans.
+func parseInline(s string) (string, []inlineSpan) {
+	p := &inlineParser{}
+	p.parse([]rune(s), inlineSpan{})
+	return p.out.String(), p.spans
+}
+
+type inlineParser struct {
+	out   strings.Builder
+	pos   int64 // UTF-16 length of out
+	spans []inlineSpan
+}
+
+// emit appends text with the given style, merging it into the previous span when the style matches.
+func (p *inlineParser) emit(text []rune, style inlineSpan) {
+	if len(text) == 0 {
+		return
+	}
+	n := int64(len(utf16.Encode(text)))
+	p.out.WriteString(string(text))
+	if style.Bold || style.Italic || style.Code || style.Link != "" {
+		if last := len(p.spans) - 1; last >= 0 && p.spans[last].End == p.pos && sameStyle(p.spans[last], style) {
+			p.spans[last].End += n
+		} else {
+			style.Start, style.End = p.pos, p.pos+n
+			p.spans = append(p.spans, style)
+		}
+	}
+	p.pos += n
+}
+
+func sameStyle(a, b inlineSpan) bool {
+	return a.Bold == b.Bold && a.Italic == b.Italic && a.Code == b.Code && a.Link == b.Link
+}
+
+func (p *inlineParser) parse(s []rune, style inlineSpan) {
+	var plain []rune
+	flushPlain := func() {
+		p.emit(plain, style)
+		plain = nil
+	}
+	for i := 0; i < len(s); i++ {
+		r := s[i]
+		rest := string(s[i:])
+		switch {
+		case r == '\\' && i+1 < len(s) && strings.ContainsRune("\\`*_[]()#+-.!|>", s[i+1]):
+			plain = append(plain, s[i+1])
+			i++
+		case r == '`':
+			end := indexRunes(s, i+1, "`")
+			if end < 0 {
+				plain = append(plain, r)
+				continue
+			}
+			flushPlain()
+			code := style
+			code.Code = true
+			p.emit(s[i+1:end], code)
+			i = end
+		case r == '[':
+			closeText := indexRunes(s, i+1, "](")
+			if closeText < 0 {
+				plain = append(plain, r)
+				continue
+			}
+			closeURL := indexRunes(s, closeText+2, ")")
+			if closeURL < 0 {
+				plain = append(plain, r)
+				continue
+			}
+			flushPlain()
+			link := style
+			link.Link = strings.TrimSpace(string(s[closeText+2 : closeURL]))
+			p.parse(s[i+1:closeText], link)
+			i = closeURL
+		case strings.HasPrefix(rest, "***") || strings.HasPrefix(rest, "**") || strings.HasPrefix(rest, "__") || r == '*' || r == '_':
+			marker := string(r)
+			switch {
+			case strings.HasPrefix(rest, "***"):
+				marker = "***"
+			case strings.HasPrefix(rest, "**"), strings.HasPrefix(rest, "__"):
+				marker = rest[:2]
+			}
+			n := len([]rune(marker))
+			// Underscores inside words (snake_case) are literal.
+			if r == '_' && i > 0 && isWordRune(s[i-1]) {
+				plain = append(plain, s[i:i+n]...)
+				i += n - 1
+				continue
+			}
+			end := closingMarker(s, i+n, marker)
+			if end < 0 || unicode.IsSpace(s[i+n]) || (r == '_' && end+n < len(s) && isWordRune(s[end+n])) {
+				plain = append(plain, s[i:i+n]...)
+				i += n - 1
+				continue
+			}
+			flushPlain()
+			inner := style
+			switch marker {
+			case "***":
+				inner.Bold, inner.Italic = true, true
+			case "**", "__":
+				inner.Bold = true
+			default:
+				inner.Italic = true
+			}
+			p.parse(s[i+n:end], inner)
+			i = end + n - 1
+		default:
+			plain = append(plain, r)
+		}
+	}
+	flushPlain()
+}
+
+// closingMarker finds the emphasis marker closing a span that starts at from: it must not be escaped,
+// must follow a non-space and must not be the start of the span. It returns -1 if there is none.
+func closingMarker(s []rune, from int, marker string) int {
+	for i := from; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if i > from && !unicode.IsSpace(s[i-1]) && strings.HasPrefix(string(s[i:]), marker) {
+			return i
+		}
+	}
+	return -1
+}
+
+// indexRunes returns the index of sub in s at or after from, or -1.
+func indexRunes(s []rune, from int, sub string) int {
+	if from > len(s) {
+		return -1
+	}
+	idx := strings.Index(string(s[from:]), sub)
+	if idx < 0 {
+		return -1
+	}
+	return from + len([]rune(string(s[from:])[:idx]))
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+const (
+	codeFont  = "Roboto Mono"
+	linkColor = 0x1155CC
+)
+
+// resetTextFields are cleared on inserted text so it doesn't inherit the style of neighbouring text.
+const resetTextFields = "bold,italic,underline,strikethrough,link,weightedFontFamily,backgroundColor,foregroundColor"
+
+func utf16Len(s string) int64 {
+	return int64(len(utf16.Encode([]rune(s))))
+}
+
+func textRange(start, end int64) *docs.Range {
+	return &docs.Range{StartIndex: start, EndIndex: end}
+}
+
+func rgb(hex int) *docs.OptionalColor {
+	return &docs.OptionalColor{Color: &docs.Color{RgbColor: &docs.RgbColor{
+		Red:   float64(hex>>16&0xFF) / 255,
+		Green: float64(hex>>8&0xFF) / 255,
+		Blue:  float64(hex&0xFF) / 255,
+	}}}
+}
+
+// spanRequest styles one inline span whose offsets are relative to base.
+func spanRequest(base int64, sp inlineSpan) *docs.Request {
+	style := &docs.TextStyle{}
+	var fields []string
+	if sp.Bold {
+		style.Bold = true
+		fields = append(fields, "bold")
+	}
+	if sp.Italic {
+		style.Italic = true
+		fields = append(fields, "italic")
+	}
+	if sp.Code {
+		style.WeightedFontFamily = &docs.WeightedFontFamily{FontFamily: codeFont}
+		fields = append(fields, "weightedFontFamily")
+	}
+	if sp.Link != "" {
+		style.Link = &docs.Link{Url: sp.Link}
+		style.Underline = true
+		style.ForegroundColor = rgb(linkColor)
+		fields = append(fields, "link", "underline", "foregroundColor")
+	}
+	return &docs.Request{UpdateTextStyle: &docs.UpdateTextStyleRequest{
+		Range:     textRange(base+sp.Start, base+sp.End),
+		TextStyle: style,
+		Fields:    strings.Join(fields, ","),
+	}}
+}
+
+// segmentRequests builds the requests that insert a run of non-table blocks at index at, which must be
+// the start of an empty last paragraph or, with leadingNewline, the end of a non-empty one.
+func segmentRequests(blocks []mdBlock, at int64, leadingNewline bool) []*docs.Request {
+	type para struct {
+		block      mdBlock
+		start, end int64
+	}
+	var text strings.Builder
+	pos := at
+	if leadingNewline {
+		text.WriteString("\n")
+		pos++
+	}
+	first := pos
+	var paras []para
+	var styles []*docs.Request
+	for i, b := range blocks {
+		if i > 0 {
+			text.WriteString("\n")
+			pos++
+		}
+		start := pos
+		switch b.Kind {
+		case blockCode:
+			code := strings.ReplaceAll(b.Text, "\n", "\v")
+			text.WriteString(code)
+			pos += utf16Len(code)
+			if pos > start {
+				styles = append(styles, &docs.Request{UpdateTextStyle: &docs.UpdateTextStyleRequest{
+					Range:     textRange(start, pos),
+					TextStyle: &docs.TextStyle{WeightedFontFamily: &docs.WeightedFontFamily{FontFamily: codeFont}},
+					Fields:    "weightedFontFamily",
+				}})
+			}
+		default:
+			if b.Kind == blockBullet || b.Kind == blockNumbered {
+				// Leading tabs set the nesting level; CreateParagraphBullets removes them.
+				tabs := strings.Repeat("\t", b.Level)
+				text.WriteString(tabs)
+				pos += int64(len(tabs))
+			}
+			plain, spans := parseInline(b.Text)
+			for _, sp := range spans {
+				styles = append(styles, spanRequest(pos, sp))
+			}
+			text.WriteString(plain)
+			pos += utf16Len(plain)
+		}
+		paras = append(paras, para{block: b, start: start, end: pos})
+	}
+
+	reqs := []*docs.Request{{InsertText: &docs.InsertTextRequest{Text: text.String(), Location: &docs.Location{Index: at}}}}
+	// Inserted paragraphs inherit the style of the paragraph they were inserted into, so reset them first.
+	whole := textRange(first, pos+1)
+	reqs = append(reqs,
+		&docs.Request{DeleteParagraphBullets: &docs.DeleteParagraphBulletsRequest{Range: whole}},
+		&docs.Request{UpdateParagraphStyle: &docs.UpdateParagraphStyleRequest{
+			Range:          whole,
+			ParagraphStyle: &docs.ParagraphStyle{NamedStyleType: "NORMAL_TEXT"},
+			Fields:         "namedStyleType,indentStart,indentFirstLine,alignment",
+		}},
+	)
+	if pos > first {
+		reqs = append(reqs, &docs.Request{UpdateTextStyle: &docs.UpdateTextStyleRequest{
+			Range: textRange(first, pos), TextStyle: &docs.TextStyle{}, Fields: resetTextFields,
+		}})
+	}
+	for _, p := range paras {
+		r := textRange(p.start, p.end+1)
+		switch p.block.Kind {
+		case blockHeading:
+			reqs = append(reqs, &docs.Request{UpdateParagraphStyle: &docs.UpdateParagraphStyleRequest{
+				Range:          r,
+				ParagraphStyle: &docs.ParagraphStyle{NamedStyleType: fmt.Sprintf("HEADING_%d", p.block.Level)},
+				Fields:         "namedStyleType",
+			}})
+		case blockQuote:
+			indent := &docs.Dimension{Magnitude: 36, Unit: "PT"}
+			reqs = append(reqs, &docs.Request{UpdateParagraphStyle: &docs.UpdateParagraphStyleRequest{
+				Range:          r,
+				ParagraphStyle: &docs.ParagraphStyle{IndentStart: indent, IndentFirstLine: indent},
+				Fields:         "indentStart,indentFirstLine",
+			}})
+		}
+	}
+	reqs = append(reqs, styles...)
+
+	// Bullets go last and in reverse order, because removing the nesting tabs shifts later indexes.
+	var bullets []*docs.Request
+	for i := 0; i < len(paras); {
+		kind := paras[i].block.Kind
+		if kind != blockBullet && kind != blockNumbered {
+			i++
+			continue
+		}
+		j := i
+		for j+1 < len(paras) && (paras[j+1].block.Kind == blockBullet || paras[j+1].block.Kind == blockNumbered) {
+			j++
+		}
+		preset := "BULLET_DISC_CIRCLE_SQUARE"
+		if kind == blockNumbered {
+			preset = "NUMBERED_DECIMAL_ALPHA_ROMAN"
+		}
+		bullets = append(bullets, &docs.Request{CreateParagraphBullets: &docs.CreateParagraphBulletsRequest{
+			Range:        textRange(paras[i].start, paras[j].end+1),
+			BulletPreset: preset,
+		}})
+		i = j + 1
+	}
+	for i := len(bullets) - 1; i >= 0; i-- {
+		reqs = append(reqs, bullets[i])
+	}
+	return reqs
+}
+
+// tableCellRequests fills an empty table with rows, bolding the header row. Cells are filled from the
+// last to the first so that each insertion leaves the indexes of the cells before it unchanged.
+func tableCellRequests(table *docs.Table, rows [][]string) []*docs.Request {
+	var reqs []*docs.Request
+	for r := len(table.TableRows) - 1; r >= 0; r-- {
+		cells := table.TableRows[r].TableCells
+		for c := len(cells) - 1; c >= 0; c-- {
+			if r >= len(rows) || c >= len(rows[r]) || rows[r][c] == "" || len(cells[c].Content) == 0 {
+				continue
+			}
+			at := cells[c].Content[0].StartIndex
+			plain, spans := parseInline(rows[r][c])
+			if plain == "" {
+				continue
+			}
+			reqs = append(reqs, &docs.Request{InsertText: &docs.InsertTextRequest{Text: plain, Location: &docs.Location{Index: at}}})
+			if r == 0 {
+				reqs = append(reqs, &docs.Request{UpdateTextStyle: &docs.UpdateTextStyleRequest{
+					Range: textRange(at, at+utf16Len(plain)), TextStyle: &docs.TextStyle{Bold: true}, Fields: "bold",
+				}})
+			}
+			for _, sp := range spans {
+				reqs = append(reqs, spanRequest(at, sp))
+			}
+		}
+	}
+	return reqs
+}
+
+// endOfBody returns where new content can be appended: just before the body's final newline.
+// needsNewline reports whether the last paragraph has text, so new content must start a new paragraph.
+func endOfBody(doc *docs.Document) (at int64, needsNewline bool) {
+	if doc.Body == nil || len(doc.Body.Content) == 0 {
+		return 1, false
+	}
+	last := doc.Body.Content[len(doc.Body.Content)-1]
+	at = max(last.EndIndex-1, 1)
+	if last.Paragraph != nil && at > 1 {
+		var text strings.Builder
+		for _, el := range last.Paragraph.Elements {
+			if el.TextRun != nil {
+				text.WriteString(el.TextRun.Content)
+			}
+		}
+		needsNewline = text.String() != "\n"
+	}
+	return at, needsNewline
+}
+
+// lastTable returns the last table in the document body.
+func lastTable(doc *docs.Document) *docs.Table {
+	if doc.Body == nil {
+		return nil
+	}
+	for i := len(doc.Body.Content) - 1; i >= 0; i-- {
+		if t := doc.Body.Content[i].Table; t != nil {
+			return t
+		}
+	}
+	return nil
+}
+
+// InsertMarkdown renders Markdown (headings, bold/italic, inline code, links, nested lists, code blocks,
+// quotes and tables) at the end of the document. With replace, the existing body content is removed first.
+func (d *DocsService) InsertMarkdown(ctx context.Context, documentId, markdown string, replace bool) error {
+	blocks := parseMarkdown(markdown)
+	if len(blocks) == 0 {
+		return fmt.Errorf("markdown has no content")
+	}
+	doc, err := d.GetDocument(ctx, documentId)
+	if err != nil {
+		return err
+	}
+	if at, _ := endOfBody(doc); replace && at > 1 {
+		if _, err := d.batchUpdate(ctx, documentId, &docs.Request{
+			DeleteContentRange: &docs.DeleteContentRangeRequest{Range: textRange(1, at)},
+		}); err != nil {
+			return err
+		}
+		if doc, err = d.GetDocument(ctx, documentId); err != nil {
+			return err
+		}
+	}
+
+	for len(blocks) > 0 {
+		n := 0
+		for n < len(blocks) && blocks[n].Kind != blockTable {
+			n++
+		}
+		if n > 0 {
+			at, newline := endOfBody(doc)
+			if _, err := d.batchUpdate(ctx, documentId, segmentRequests(blocks[:n], at, newline)...); err != nil {
+				return err
+			}
+			blocks = blocks[n:]
+		} else {
+			rows := blocks[0].Rows
+			cols := 0
+			for _, row := range rows {
+				cols = max(cols, len(row))
+			}
+			if _, err := d.batchUpdate(ctx, documentId, &docs.Request{InsertTable: &docs.InsertTableRequest{
+				Rows: int64(len(rows)), Columns: int64(cols), EndOfSegmentLocation: &docs.EndOfSegmentLocation{},
+			}}); err != nil {
+				return err
+			}
+			if doc, err = d.GetDocument(ctx, documentId); err != nil {
+				return err
+			}
+			if reqs := tableCellRequests(lastTable(doc), rows); len(reqs) > 0 {
+				if _, err := d.batchUpdate(ctx, documentId, reqs...); err != nil {
+					return err
+				}
+			}
+			blocks = blocks[1:]
+		}
+		if len(blocks) > 0 {
+			if doc, err = d.GetDocument(ctx, documentId); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
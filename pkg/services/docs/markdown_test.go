@@ -0,0 +1,127 @@
+package docs
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/api/docs/v1"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	md := "# Title\n\nSome *intro*\ncontinued.\n\n- one\n- two\n  - nested\n    more\n1. first\n\n```go\nfmt.Println(1)\n\nx := 2\n```\n> quoted\n> text\n\n---\n| Name | Status |\n|------|:------:|\n| A \\| B | done |\n"
+	got := parseMarkdown(md)
+	want := []mdBlock{
+		{Kind: blockHeading, Level: 1, Text: "Title"},
+		{Kind: blockParagraph, Text: "Some *intro* continued."},
+		{Kind: blockBullet, Text: "one"},
+		{Kind: blockBullet, Text: "two"},
+		{Kind: blockBullet, Level: 1, Text: "nested more"},
+		{Kind: blockNumbered, Text: "first"},
+		{Kind: blockCode, Text: "fmt.Println(1)\n\nx := 2"},
+		{Kind: blockQuote, Text: "quoted text"},
+		{Kind: blockTable, Rows: [][]string{{"Name", "Status"}, {"A | B", "done"}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseMarkdown:\n got  %+v\n want %+v", got, want)
+	}
+}
+
+func TestParseInline(t *testing.T) {
+	tests := []struct {
+		in    string
+		text  string
+		spans []inlineSpan
+	}{
+		{"plain snake_case_name", "plain snake_case_name", nil},
+		{"a **bold** and *it* `x`", "a bold and it x", []inlineSpan{
+			{Start: 2, End: 6, Bold: true}, {Start: 11, End: 13, Italic: true}, {Start: 14, End: 15, Code: true},
+		}},
+		{"***both***", "both", []inlineSpan{{Start: 0, End: 4, Bold: true, Italic: true}}},
+		{"see [the **docs**](https://x.io)", "see the docs", []inlineSpan{
+			{Start: 4, End: 8, Link: "https://x.io"}, {Start: 8, End: 12, Bold: true, Link: "https://x.io"},
+		}},
+		{"😀 _hi_", "😀 hi", []inlineSpan{{Start: 3, End: 5, Italic: true}}},
+		{`2 * 3 and \*lit\*`, "2 * 3 and *lit*", nil},
+	}
+	for _, tt := range tests {
+		text, spans := parseInline(tt.in)
+		if text != tt.text || !reflect.DeepEqual(spans, tt.spans) {
+			t.Errorf("parseInline(%q) = %q %+v; want %q %+v", tt.in, text, spans, tt.text, tt.spans)
+		}
+	}
+}
+
+func TestSegmentRequests(t *testing.T) {
+	blocks := []mdBlock{
+		{Kind: blockHeading, Level: 2, Text: "Plan"},
+		{Kind: blockBullet, Text: "**a**"},
+		{Kind: blockBullet, Level: 1, Text: "b"},
+	}
+	reqs := segmentRequests(blocks, 10, true)
+	ins := reqs[0].InsertText
+	if ins == nil || ins.Text != "\nPlan\na\n\tb" || ins.Location.Index != 10 {
+		t.Fatalf("unexpected insert: %+v", ins)
+	}
+	var heading, bold, bullets *docs.Request
+	for _, r := range reqs {
+		switch {
+		case r.UpdateParagraphStyle != nil && r.UpdateParagraphStyle.ParagraphStyle.NamedStyleType == "HEADING_2":
+			heading = r
+		case r.UpdateTextStyle != nil && r.UpdateTextStyle.Fields == "bold":
+			bold = r
+		case r.CreateParagraphBullets != nil:
+			bullets = r
+		}
+	}
+	if heading == nil || heading.UpdateParagraphStyle.Range.StartIndex != 11 || heading.UpdateParagraphStyle.Range.EndIndex != 16 {
+		t.Errorf("unexpected heading style: %+v", heading)
+	}
+	if bold == nil || bold.UpdateTextStyle.Range.StartIndex != 16 || bold.UpdateTextStyle.Range.EndIndex != 17 {
+		t.Errorf("unexpected bold range: %+v", bold)
+	}
+	if bullets == nil || bullets.CreateParagraphBullets.Range.StartIndex != 16 || bullets.CreateParagraphBullets.Range.EndIndex != 21 {
+		t.Errorf("unexpected bullets: %+v", bullets)
+	}
+	if reqs[len(reqs)-1] != bullets {
+		t.Error("bullets must be the last request")
+	}
+}
+
+func TestTableCellRequests(t *testing.T) {
+	cell := func(start int64) *docs.TableCell {
+		return &docs.TableCell{Content: []*docs.StructuralElement{{StartIndex: start, Paragraph: &docs.Paragraph{}}}}
+	}
+	table := &docs.Table{TableRows: []*docs.TableRow{
+		{TableCells: []*docs.TableCell{cell(5), cell(7)}},
+		{TableCells: []*docs.TableCell{cell(10), cell(12)}},
+	}}
+	reqs := tableCellRequests(table, [][]string{{"H1", "H2"}, {"x"}})
+	var inserts []int64
+	for _, r := range reqs {
+		if r.InsertText != nil {
+			inserts = append(inserts, r.InsertText.Location.Index)
+		}
+	}
+	if !reflect.DeepEqual(inserts, []int64{10, 7, 5}) {
+		t.Errorf("expected inserts from last to first cell, got %v", inserts)
+	}
+	if r := reqs[2]; r.UpdateTextStyle == nil || r.UpdateTextStyle.Range.StartIndex != 7 || r.UpdateTextStyle.Range.EndIndex != 9 {
+		t.Errorf("expected header cell to be bolded right after its insert, got %+v", r)
+	}
+}
+
+func TestEndOfBody(t *testing.T) {
+	empty := &docs.Document{Body: &docs.Body{Content: []*docs.StructuralElement{
+		{EndIndex: 1, SectionBreak: &docs.SectionBreak{}},
+		{StartIndex: 1, EndIndex: 2, Paragraph: &docs.Paragraph{Elements: []*docs.ParagraphElement{textRun(1, "\n")}}},
+	}}}
+	if at, nl := endOfBody(empty); at != 1 || nl {
+		t.Errorf("empty doc: got %d, %v", at, nl)
+	}
+	text := &docs.Document{Body: &docs.Body{Content: []*docs.StructuralElement{
+		{StartIndex: 1, EndIndex: 5, Paragraph: &docs.Paragraph{Elements: []*docs.ParagraphElement{textRun(1, "foo\n")}}},
+	}}}
+	if at, nl := endOfBody(text); at != 4 || !nl {
+		t.Errorf("doc with text: got %d, %v", at, nl)
+	}
+}
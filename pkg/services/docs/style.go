@@ -0,0 +1,183 @@
+package docs
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// StyleSpec is a friendly description of paragraph and text styling to apply to a range. Unset fields are left unchanged.
+type StyleSpec struct {
+	NamedStyle      string  `json:"named_style,omitempty"` // NORMAL_TEXT, TITLE, SUBTITLE, HEADING_1..HEADING_6 (also "h1", "heading 2")
+	Alignment       string  `json:"alignment,omitempty"`   // START, CENTER, END or JUSTIFIED
+	Bullets         string  `json:"bullets,omitempty"`     // bullet, numbered, checkbox or none
+	Bold            *bool   `json:"bold,omitempty"`
+	Italic          *bool   `json:"italic,omitempty"`
+	Underline       *bool   `json:"underline,omitempty"`
+	Strikethrough   *bool   `json:"strikethrough,omitempty"`
+	FontSize        float64 `json:"font_size,omitempty"` // Points
+	FontFamily      string  `json:"font_family,omitempty"`
+	TextColor       string  `json:"text_color,omitempty"`       // #RRGGBB
+	BackgroundColor string  `json:"background_color,omitempty"` // #RRGGBB highlight
+	Link            *string `json:"link,omitempty"`             // URL; "" removes an existing link
+}
+
+// bulletPresets maps the bullets values of a StyleSpec to Docs bullet presets.
+var bulletPresets = map[string]string{
+	"bullet":   "BULLET_DISC_CIRCLE_SQUARE",
+	"numbered": "NUMBERED_DECIMAL_ALPHA_ROMAN",
+	"checkbox": "BULLET_CHECKBOX",
+}
+
+// namedStyle normalizes a named style such as "h2", "Heading 2" or "heading_2" to HEADING_2.
+func namedStyle(s string) (string, error) {
+	n := strings.ToUpper(strings.Join(strings.Fields(s), ""))
+	n = strings.ReplaceAll(n, "_", "")
+	if rest, ok := strings.CutPrefix(n, "HEADING"); ok {
+		n = "H" + rest
+	}
+	if len(n) == 2 && n[0] == 'H' {
+		n = "HEADING_" + n[1:]
+	}
+	switch n {
+	case "NORMAL", "NORMALTEXT", "BODY":
+		return "NORMAL_TEXT", nil
+	case "TITLE", "SUBTITLE", "HEADING_1", "HEADING_2", "HEADING_3", "HEADING_4", "HEADING_5", "HEADING_6":
+		return n, nil
+	}
+	return "", fmt.Errorf("unknown named style %q: use NORMAL_TEXT, TITLE, SUBTITLE or HEADING_1 to HEADING_6", s)
+}
+
+// parseHexColor parses "#RRGGBB" or "RRGGBB" (also the short "#RGB") into a Docs color.
+func parseHexColor(s string) (*docs.OptionalColor, error) {
+	h := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(h) == 3 {
+		h = string([]byte{h[0], h[0], h[1], h[1], h[2], h[2]})
+	}
+	v, err := strconv.ParseUint(h, 16, 32)
+	if len(h) != 6 || err != nil {
+		return nil, fmt.Errorf("invalid color %q, expected #RRGGBB", s)
+	}
+	return rgb(int(v)), nil
+}
+
+// Requests builds the requests applying spec to [start, end). Paragraph styles apply to every paragraph
+// the range touches; bullets are created last since removing nesting tabs can shift indexes.
+func (spec StyleSpec) Requests(start, end int64) ([]*docs.Request, error) {
+	var reqs []*docs.Request
+	r := textRange(start, end)
+
+	para := &docs.ParagraphStyle{}
+	var paraFields []string
+	if spec.NamedStyle != "" {
+		name, err := namedStyle(spec.NamedStyle)
+		if err != nil {
+			return nil, err
+		}
+		para.NamedStyleType = name
+		paraFields = append(paraFields, "namedStyleType")
+	}
+	if spec.Alignment != "" {
+		a := strings.ToUpper(spec.Alignment)
+		switch a {
+		case "LEFT":
+			a = "START"
+		case "RIGHT":
+			a = "END"
+		case "START", "CENTER", "END", "JUSTIFIED":
+		default:
+			return nil, fmt.Errorf("invalid alignment %q: use START, CENTER, END or JUSTIFIED", spec.Alignment)
+		}
+		para.Alignment = a
+		paraFields = append(paraFields, "alignment")
+	}
+	if len(paraFields) > 0 {
+		reqs = append(reqs, &docs.Request{UpdateParagraphStyle: &docs.UpdateParagraphStyleRequest{
+			Range: r, ParagraphStyle: para, Fields: strings.Join(paraFields, ","),
+		}})
+	}
+
+	text := &docs.TextStyle{}
+	var textFields []string
+	flag := func(v *bool, dst *bool, field, name string) {
+		if v != nil {
+			*dst = *v
+			text.ForceSendFields = append(text.ForceSendFields, name)
+			textFields = append(textFields, field)
+		}
+	}
+	flag(spec.Bold, &text.Bold, "bold", "Bold")
+	flag(spec.Italic, &text.Italic, "italic", "Italic")
+	flag(spec.Underline, &text.Underline, "underline", "Underline")
+	flag(spec.Strikethrough, &text.Strikethrough, "strikethrough", "Strikethrough")
+	if spec.FontSize > 0 {
+		text.FontSize = &docs.Dimension{Magnitude: spec.FontSize, Unit: "PT"}
+		textFields = append(textFields, "fontSize")
+	}
+	if spec.FontFamily != "" {
+		text.WeightedFontFamily = &docs.WeightedFontFamily{FontFamily: spec.FontFamily}
+		textFields = append(textFields, "weightedFontFamily")
+	}
+	if spec.TextColor != "" {
+		c, err := parseHexColor(spec.TextColor)
+		if err != nil {
+			return nil, err
+		}
+		text.ForegroundColor = c
+		textFields = append(textFields, "foregroundColor")
+	}
+	if spec.BackgroundColor != "" {
+		c, err := parseHexColor(spec.BackgroundColor)
+		if err != nil {
+			return nil, err
+		}
+		text.BackgroundColor = c
+		textFields = append(textFields, "backgroundColor")
+	}
+	if spec.Link != nil {
+		if *spec.Link != "" {
+			text.Link = &docs.Link{Url: *spec.Link}
+		}
+		textFields = append(textFields, "link")
+	}
+	if len(textFields) > 0 {
+		reqs = append(reqs, &docs.Request{UpdateTextStyle: &docs.UpdateTextStyleRequest{
+			Range: r, TextStyle: text, Fields: strings.Join(textFields, ","),
+		}})
+	}
+
+	switch b := strings.ToLower(spec.Bullets); b {
+	case "":
+	case "none":
+		reqs = append(reqs, &docs.Request{DeleteParagraphBullets: &docs.DeleteParagraphBulletsRequest{Range: r}})
+	default:
+		preset, ok := bulletPresets[b]
+		if !ok {
+			return nil, fmt.Errorf("invalid bullets %q: use bullet, numbered, checkbox or none", spec.Bullets)
+		}
+		reqs = append(reqs, &docs.Request{CreateParagraphBullets: &docs.CreateParagraphBulletsRequest{Range: r, BulletPreset: preset}})
+	}
+
+	if len(reqs) == 0 {
+		return nil, fmt.Errorf("style has no properties to apply")
+	}
+	return reqs, nil
+}
+
+// ApplyStyle applies spec to the body content in [start, end) and returns the number of requests applied.
+func (d *DocsService) ApplyStyle(ctx context.Context, documentId string, start, end int64, spec StyleSpec) (int, error) {
+	if start < 1 || end <= start {
+		return 0, fmt.Errorf("invalid range %d-%d: start must be at least 1 and end greater than start", start, end)
+	}
+	reqs, err := spec.Requests(start, end)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := d.batchUpdate(ctx, documentId, reqs...); err != nil {
+		return 0, err
+	}
+	return len(reqs), nil
+}
@@ -0,0 +1,55 @@
+package docs
+
+import "testing"
+
+func TestNamedStyle(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{"HEADING_1", "HEADING_1", false},
+		{"h2", "HEADING_2", false},
+		{"Heading 3", "HEADING_3", false},
+		{"heading3", "HEADING_3", false},
+		{"title", "TITLE", false},
+		{"normal", "NORMAL_TEXT", false},
+		{"HEADING_7", "", true},
+		{"caption", "", true},
+	}
+	for _, tt := range tests {
+		got, err := namedStyle(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("namedStyle(%q) = %q, %v; want %q (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestStyleSpecRequests(t *testing.T) {
+	yes, no, empty := true, false, ""
+	reqs, err := StyleSpec{NamedStyle: "h1", Bold: &yes, Italic: &no, TextColor: "#C00", Link: &empty, Bullets: "numbered"}.Requests(5, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reqs) != 3 {
+		t.Fatalf("got %d requests, want 3", len(reqs))
+	}
+	if p := reqs[0].UpdateParagraphStyle; p == nil || p.ParagraphStyle.NamedStyleType != "HEADING_1" || p.Fields != "namedStyleType" {
+		t.Errorf("paragraph style request = %+v", reqs[0])
+	}
+	ts := reqs[1].UpdateTextStyle
+	if ts == nil || ts.Fields != "bold,italic,foregroundColor,link" || !ts.TextStyle.Bold || ts.TextStyle.Link != nil {
+		t.Errorf("text style request = %+v", reqs[1])
+	}
+	if c := ts.TextStyle.ForegroundColor.Color.RgbColor; c.Red != 0.8 || c.Green != 0 {
+		t.Errorf("text color = %+v", c)
+	}
+	if b := reqs[2].CreateParagraphBullets; b == nil || b.BulletPreset != "NUMBERED_DECIMAL_ALPHA_ROMAN" || b.Range.StartIndex != 5 || b.Range.EndIndex != 20 {
+		t.Errorf("bullets request = %+v", reqs[2])
+	}
+
+	for _, bad := range []StyleSpec{{}, {Alignment: "middle"}, {Bullets: "stars"}, {TextColor: "red"}} {
+		if _, err := bad.Requests(1, 2); err == nil {
+			t.Errorf("expected error for %+v", bad)
+		}
+	}
+}
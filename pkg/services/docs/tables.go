@@ -0,0 +1,166 @@
+package docs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// TableInfo is a table of the document body with its cell texts. Number is 1-based in document order.
+type TableInfo struct {
+	Number     int        `json:"table"`
+	StartIndex int64      `json:"start_index"`
+	EndIndex   int64      `json:"end_index"`
+	Rows       [][]string `json:"rows"`
+}
+
+// cellText returns the text of a table cell, with paragraphs joined by newlines.
+func cellText(cell *docs.TableCell) string {
+	var parts []string
+	walkParagraphs(cell.Content, func(p *docs.Paragraph) {
+		parts = append(parts, paragraphText(p))
+	})
+	return strings.Join(parts, "\n")
+}
+
+// Tables lists the top-level tables of the document body.
+func Tables(doc *docs.Document) []TableInfo {
+	var out []TableInfo
+	if doc.Body == nil {
+		return out
+	}
+	for _, el := range doc.Body.Content {
+		if el.Table == nil {
+			continue
+		}
+		info := TableInfo{Number: len(out) + 1, StartIndex: el.StartIndex, EndIndex: el.EndIndex}
+		for _, row := range el.Table.TableRows {
+			cells := make([]string, len(row.TableCells))
+			for i, cell := range row.TableCells {
+				cells[i] = cellText(cell)
+			}
+			info.Rows = append(info.Rows, cells)
+		}
+		out = append(out, info)
+	}
+	return out
+}
+
+// tableElement returns the n-th (1-based) top-level table of the body.
+func tableElement(doc *docs.Document, n int) (*docs.StructuralElement, error) {
+	count := 0
+	if doc.Body != nil {
+		for _, el := range doc.Body.Content {
+			if el.Table != nil {
+				if count++; count == n {
+					return el, nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("table %d not found (the document has %d tables)", n, count)
+}
+
+// ParagraphEnd returns the index just before the newline of the top-level paragraph containing index,
+// which is where content can be inserted to follow that paragraph.
+func ParagraphEnd(doc *docs.Document, index int64) (int64, error) {
+	if doc.Body != nil {
+		for _, el := range doc.Body.Content {
+			if el.Paragraph != nil && el.StartIndex <= index && index < el.EndIndex {
+				return el.EndIndex - 1, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("index %d is not inside a body paragraph", index)
+}
+
+// InsertTable inserts a table filled with rows (the first row bolded as a header) at a body index,
+// or at the end of the document when index is 0. It returns the table's number in document order.
+func (d *DocsService) InsertTable(ctx context.Context, documentId string, rows [][]string, index int64) (int, error) {
+	cols := 0
+	for _, row := range rows {
+		cols = max(cols, len(row))
+	}
+	if len(rows) == 0 || cols == 0 {
+		return 0, fmt.Errorf("table needs at least one row and one column")
+	}
+	req := &docs.InsertTableRequest{Rows: int64(len(rows)), Columns: int64(cols)}
+	if index > 0 {
+		req.Location = &docs.Location{Index: index}
+	} else {
+		req.EndOfSegmentLocation = &docs.EndOfSegmentLocation{}
+	}
+	if _, err := d.batchUpdate(ctx, documentId, &docs.Request{InsertTable: req}); err != nil {
+		return 0, err
+	}
+	doc, err := d.GetDocument(ctx, documentId)
+	if err != nil {
+		return 0, err
+	}
+	// The new table is the first one at or after the insertion point, or the last one when appending.
+	var table *docs.Table
+	number := 0
+	for _, el := range doc.Body.Content {
+		if el.Table == nil {
+			continue
+		}
+		number++
+		table = el.Table
+		if index > 0 && el.StartIndex >= index {
+			break
+		}
+	}
+	if table == nil {
+		return 0, fmt.Errorf("inserted table not found")
+	}
+	if reqs := tableCellRequests(table, rows); len(reqs) > 0 {
+		if _, err := d.batchUpdate(ctx, documentId, reqs...); err != nil {
+			return 0, err
+		}
+	}
+	return number, nil
+}
+
+// SetTableCell replaces the content of a cell. table, row and column are 1-based; text may use inline Markdown.
+func (d *DocsService) SetTableCell(ctx context.Context, documentId string, table, row, column int, text string) error {
+	doc, err := d.GetDocument(ctx, documentId)
+	if err != nil {
+		return err
+	}
+	el, err := tableElement(doc, table)
+	if err != nil {
+		return err
+	}
+	t := el.Table
+	if row < 1 || row > len(t.TableRows) {
+		return fmt.Errorf("row %d out of range: table %d has %d rows", row, table, len(t.TableRows))
+	}
+	cells := t.TableRows[row-1].TableCells
+	if column < 1 || column > len(cells) {
+		return fmt.Errorf("column %d out of range: table %d has %d columns", column, table, len(cells))
+	}
+	cell := cells[column-1]
+	if len(cell.Content) == 0 {
+		return fmt.Errorf("cell has no content to replace")
+	}
+	start := cell.Content[0].StartIndex
+	end := cell.Content[len(cell.Content)-1].EndIndex - 1 // Keep the cell's final newline
+
+	var reqs []*docs.Request
+	if end > start {
+		reqs = append(reqs, &docs.Request{DeleteContentRange: &docs.DeleteContentRangeRequest{Range: textRange(start, end)}})
+	}
+	if plain, spans := parseInline(text); plain != "" {
+		reqs = append(reqs, &docs.Request{InsertText: &docs.InsertTextRequest{Text: plain, Location: &docs.Location{Index: start}}})
+		for _, sp := range spans {
+			reqs = append(reqs, spanRequest(start, sp))
+		}
+	}
+	if len(reqs) == 0 {
+		return nil
+	}
+	_, err = d.batchUpdate(ctx, documentId, reqs...)
+	return err
+}
@@ -0,0 +1,28 @@
+package docs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTables(t *testing.T) {
+	doc := testDocument()
+	got := Tables(doc)
+	want := []TableInfo{{Number: 1, StartIndex: 45, EndIndex: 60, Rows: [][]string{{"A|B"}, {"1"}}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tables = %+v, want %+v", got, want)
+	}
+	if _, err := tableElement(doc, 2); err == nil {
+		t.Error("expected error for missing table")
+	}
+}
+
+func TestParagraphEnd(t *testing.T) {
+	doc := testDocument()
+	if end, err := ParagraphEnd(doc, 10); err != nil || end != 22 {
+		t.Errorf("ParagraphEnd(10) = %d, %v; want 22", end, err)
+	}
+	if _, err := ParagraphEnd(doc, 50); err == nil {
+		t.Error("expected error for an index inside a table")
+	}
+}
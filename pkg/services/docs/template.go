@@ -0,0 +1,64 @@
+package docs
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// placeholderPattern matches {{placeholder}} markers left in a document.
+var placeholderPattern = regexp.MustCompile(`\{\{\s*[^{}\n]+?\s*\}\}`)
+
+// placeholderText returns the text to search for a placeholder key, wrapping it in {{ }} unless it already is.
+func placeholderText(key string) string {
+	key = strings.TrimSpace(key)
+	if strings.HasPrefix(key, "{{") && strings.HasSuffix(key, "}}") {
+		return key
+	}
+	return "{{" + key + "}}"
+}
+
+// Placeholders lists the distinct {{placeholder}} markers in the document body, in order of appearance.
+func Placeholders(doc *docs.Document) []string {
+	var out []string
+	for _, m := range placeholderPattern.FindAllString(string(bodyText(doc).runes), -1) {
+		if !slices.Contains(out, m) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// FillPlaceholders replaces every {{key}} in the document (including headers, footers and tables) with its value
+// and returns how many occurrences of each placeholder were replaced.
+func (d *DocsService) FillPlaceholders(ctx context.Context, documentId string, values map[string]string) (map[string]int64, error) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	if len(keys) == 0 {
+		return map[string]int64{}, nil
+	}
+	reqs := make([]*docs.Request, len(keys))
+	for i, k := range keys {
+		reqs[i] = &docs.Request{ReplaceAllText: &docs.ReplaceAllTextRequest{
+			ContainsText: &docs.SubstringMatchCriteria{Text: placeholderText(k), MatchCase: true},
+			ReplaceText:  values[k],
+		}}
+	}
+	resp, err := d.batchUpdate(ctx, documentId, reqs...)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(keys))
+	for i, k := range keys {
+		if i < len(resp.Replies) && resp.Replies[i].ReplaceAllText != nil {
+			counts[placeholderText(k)] = resp.Replies[i].ReplaceAllText.OccurrencesChanged
+		}
+	}
+	return counts, nil
+}
@@ -0,0 +1,27 @@
+package docs
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/api/docs/v1"
+)
+
+func TestPlaceholderText(t *testing.T) {
+	for in, want := range map[string]string{"name": "{{name}}", " client ": "{{client}}", "{{date}}": "{{date}}"} {
+		if got := placeholderText(in); got != want {
+			t.Errorf("placeholderText(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	doc := &docs.Document{Body: &docs.Body{Content: []*docs.StructuralElement{
+		paragraph(textRun(1, "Dear {{name}}, your order {{ order_id }} ships {{date}}.\n")),
+		paragraph(textRun(60, "Thanks, {{name}} {not} {{}}\n")),
+	}}}
+	want := []string{"{{name}}", "{{ order_id }}", "{{date}}"}
+	if got := Placeholders(doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("Placeholders = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+package docs
+
+import (
+	"unicode/utf16"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// walkParagraphs calls fn for every paragraph in content, including those inside tables
+// and tables of contents, in document order.
+func walkParagraphs(content []*docs.StructuralElement, fn func(*docs.Paragraph)) {
+	for _, el := range content {
+		switch {
+		case el.Paragraph != nil:
+			fn(el.Paragraph)
+		case el.Table != nil:
+			for _, row := range el.Table.TableRows {
+				for _, cell := range row.TableCells {
+					walkParagraphs(cell.Content, fn)
+				}
+			}
+		case el.TableOfContents != nil:
+			walkParagraphs(el.TableOfContents.Content, fn)
+		}
+	}
+}
+
+// indexedText is the body text as runes with the document index of each rune.
+// Docs indexes count UTF-16 code units, so runes outside the BMP take two.
+type indexedText struct {
+	runes []rune
+	index []int64
+	end   int64 // Index just past the last rune
+}
+
+func bodyText(doc *docs.Document) indexedText {
+	var t indexedText
+	if doc.Body == nil {
+		return t
+	}
+	walkParagraphs(doc.Body.Content, func(p *docs.Paragraph) {
+		for _, el := range p.Elements {
+			if el.TextRun == nil {
+				continue
+			}
+			idx := el.StartIndex
+			for _, r := range el.TextRun.Content {
+				t.runes = append(t.runes, r)
+				t.index = append(t.index, idx)
+				idx += int64(len(utf16.Encode([]rune{r})))
+			}
+			t.end = idx
+		}
+	})
+	return t
+}
+
+// FindText returns the document index range [start, end) of the n-th (1-based) occurrence of s
+// in the document body, or ok=false if there is no such occurrence.
+func FindText(doc *docs.Document, s string, n int) (start, end int64, ok bool) {
+	needle := []rune(s)
+	if len(needle) == 0 || n < 1 {
+		return 0, 0, false
+	}
+	t := bodyText(doc)
+	for i := 0; i+len(needle) <= len(t.runes); i++ {
+		if string(t.runes[i:i+len(needle)]) != s {
+			continue
+		}
+		if n--; n > 0 {
+			continue
+		}
+		last := i + len(needle) - 1
+		return t.index[i], t.index[last] + int64(len(utf16.Encode([]rune{t.runes[last]}))), true
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,45 @@
+package docs
+
+import (
+	"testing"
+
+	"google.golang.org/api/docs/v1"
+)
+
+func textRun(start int64, s string) *docs.ParagraphElement {
+	return &docs.ParagraphElement{StartIndex: start, TextRun: &docs.TextRun{Content: s}}
+}
+
+func paragraph(els ...*docs.ParagraphElement) *docs.StructuralElement {
+	return &docs.StructuralElement{Paragraph: &docs.Paragraph{Elements: els}}
+}
+
+func TestFindText(t *testing.T) {
+	doc := &docs.Document{Body: &docs.Body{Content: []*docs.StructuralElement{
+		paragraph(textRun(1, "Hi 😀 "), textRun(7, "there\n")), // the emoji takes two indexes
+		{Table: &docs.Table{TableRows: []*docs.TableRow{{TableCells: []*docs.TableCell{
+			{Content: []*docs.StructuralElement{paragraph(textRun(16, "there\n"))}},
+		}}}}},
+	}}}
+
+	tests := []struct {
+		s          string
+		n          int
+		start, end int64
+		ok         bool
+	}{
+		{"Hi", 1, 1, 3, true},
+		{"😀", 1, 4, 6, true},
+		{"😀 there", 1, 4, 12, true},
+		{"there", 2, 16, 21, true},
+		{"there", 3, 0, 0, false},
+		{"absent", 1, 0, 0, false},
+		{"", 1, 0, 0, false},
+	}
+	for _, tt := range tests {
+		start, end, ok := FindText(doc, tt.s, tt.n)
+		if ok != tt.ok || start != tt.start || end != tt.end {
+			t.Errorf("FindText(%q, %d) = %d, %d, %v; expected %d, %d, %v", tt.s, tt.n, start, end, ok, tt.start, tt.end, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package drive
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// Backup modes.
+const (
+	BackupCopy = "copy" // Copy the folder tree into a new snapshot folder
+	BackupZip  = "zip"  // Download the files, Google Workspace files exported to Office formats, into a zip on Drive
+)
+
+// maxBackupDepth is how deep BackupFolder descends; deeper folders are reported as failures.
+const maxBackupDepth = 50
+
+// officeFormats maps Google Workspace types to the export format they are stored as in zip backups.
+// Other Workspace types, such as Forms and Sites, cannot be exported.
+var officeFormats = map[string]string{
+	"application/vnd.google-apps.document":     "docx",
+	"application/vnd.google-apps.spreadsheet":  "xlsx",
+	"application/vnd.google-apps.presentation": "pptx",
+	"application/vnd.google-apps.drawing":      "pdf",
+}
+
+// BackupOptions configures BackupFolder.
+type BackupOptions struct {
+	Mode     string // BackupCopy (default) or BackupZip
+	ParentID string // Folder the snapshot is created in (default: the backed up folder's parent)
+	Name     string // Name of the snapshot (default: "<folder> backup <timestamp>"; ".zip" is added to archives)
+	MaxItems int    // Most files and folders to back up (default 1000); larger trees are refused
+	MaxBytes int64  // Largest zip archive, which is built in memory (default 200 MiB)
+}
+
+// BackupFailure is a file or folder that could not be backed up, by its path below the backed up folder.
+type BackupFailure struct {
+	Path  string
+	Error string
+}
+
+// BackupResult describes a snapshot made by BackupFolder.
+type BackupResult struct {
+	File    *drive.File // The snapshot folder or zip archive
+	Folders int         // Folders backed up, the backed up folder itself not included
+	Files   int         // Files backed up
+	Failed  []BackupFailure
+}
+
+// BackupFolder snapshots a folder tree before it is changed. In BackupCopy mode the tree is recreated in
+// a new folder with copies of every file; in BackupZip mode it is stored as a zip archive, with Google
+// Workspace files exported to DOCX, XLSX and PPTX. Files that cannot be backed up are reported in the
+// result rather than stopping the backup. progress, if set, is called after each file or folder with the
+// number done and the total.
+func (d *DriveService) BackupFolder(ctx context.Context, folderID string, opts BackupOptions, progress func(done, total int)) (*BackupResult, error) {
+	if folderID == "" {
+		return nil, fmt.Errorf("folder_id is required")
+	}
+	if opts.Mode == "" {
+		opts.Mode = BackupCopy
+	}
+	if opts.Mode != BackupCopy && opts.Mode != BackupZip {
+		return nil, fmt.Errorf("unknown backup mode %q (use copy or zip)", opts.Mode)
+	}
+	if opts.MaxItems <= 0 {
+		opts.MaxItems = 1000
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = 200 * 1024 * 1024
+	}
+
+	src, err := d.getResolved(ctx, folderID, "name", "parents")
+	if err != nil {
+		return nil, err
+	}
+	if src.MimeType != FolderMimeType {
+		return nil, fmt.Errorf("%s is not a folder", src.Name)
+	}
+	if opts.ParentID == "" && len(src.Parents) > 0 {
+		opts.ParentID = src.Parents[0]
+	}
+	if opts.Name == "" {
+		opts.Name = fmt.Sprintf("%s backup %s", src.Name, time.Now().Format("2006-01-02 15-04-05"))
+	}
+
+	entries, _, err := d.FolderTree(ctx, src.Id, maxBackupDepth, opts.MaxItems+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > opts.MaxItems {
+		return nil, fmt.Errorf("%s has more than %d files and folders; raise the limit or back up its subfolders separately", src.Name, opts.MaxItems)
+	}
+	if progress == nil {
+		progress = func(int, int) {}
+	}
+
+	if opts.Mode == BackupZip {
+		return d.backupZip(ctx, entries, opts, progress)
+	}
+	return d.backupCopy(ctx, entries, opts, progress)
+}
+
+// backupCopy recreates the folder tree of entries, as listed by FolderTree, in a new folder.
+func (d *DriveService) backupCopy(ctx context.Context, entries []TreeEntry, opts BackupOptions, progress func(done, total int)) (*BackupResult, error) {
+	root, err := d.CreateFolder(ctx, opts.Name, opts.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	res := &BackupResult{File: root}
+	paths := treePaths(entries, func(f *drive.File) string { return f.Name })
+	// parents[depth] is the ID of the copied folder holding the entries at that depth, or "" when it
+	// could not be created.
+	parents := []string{root.Id}
+	for i, e := range entries {
+		parents = parents[:e.Depth+1]
+		isFolder := e.File.MimeType == FolderMimeType
+		err := backupEntryError(e)
+		if err == nil && parents[e.Depth] == "" {
+			err = fmt.Errorf("its folder could not be copied")
+		}
+		var copied *drive.File
+		if err == nil {
+			if isFolder {
+				copied, err = d.CreateFolder(ctx, e.File.Name, parents[e.Depth])
+			} else {
+				copied, err = d.CopyFile(ctx, e.File.Id, e.File.Name, parents[e.Depth])
+			}
+		}
+		switch {
+		case err != nil:
+			res.Failed = append(res.Failed, BackupFailure{Path: paths[i], Error: err.Error()})
+		case isFolder:
+			res.Folders++
+		default:
+			res.Files++
+		}
+		if isFolder {
+			id := ""
+			if copied != nil {
+				id = copied.Id
+			}
+			parents = append(parents, id)
+		}
+		progress(i+1, len(entries))
+	}
+	return res, nil
+}
+
+// backupZip downloads the files of entries, as listed by FolderTree, into a zip archive uploaded to Drive.
+func (d *DriveService) backupZip(ctx context.Context, entries []TreeEntry, opts BackupOptions, progress func(done, total int)) (*BackupResult, error) {
+	res := &BackupResult{}
+	paths := treePaths(entries, archiveName)
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for i, e := range entries {
+		f := e.File
+		err := backupEntryError(e)
+		switch {
+		case err != nil:
+		case f.MimeType == FolderMimeType:
+			if _, err = zw.Create(paths[i] + "/"); err == nil {
+				res.Folders++
+			}
+		default:
+			var data []byte
+			if data, err = d.downloadForArchive(ctx, f, opts.MaxBytes-int64(buf.Len())); err == nil {
+				err = writeArchiveFile(zw, paths[i], f.ModifiedTime, data)
+			}
+			if err == nil {
+				res.Files++
+			}
+		}
+		if err != nil {
+			res.Failed = append(res.Failed, BackupFailure{Path: paths[i], Error: err.Error()})
+		}
+		if int64(buf.Len()) > opts.MaxBytes {
+			return nil, fmt.Errorf("the archive is larger than %s; back up subfolders separately or use copy mode", FormatBytes(opts.MaxBytes))
+		}
+		progress(i+1, len(entries))
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("unable to write archive: %w", err)
+	}
+
+	name := opts.Name
+	if !strings.HasSuffix(strings.ToLower(name), ".zip") {
+		name += ".zip"
+	}
+	file, err := d.UploadFile(ctx, name, opts.ParentID, "application/zip", &buf, nil)
+	if err != nil {
+		return nil, err
+	}
+	res.File = file
+	return res, nil
+}
+
+// backupEntryError reports why an entry of a folder tree cannot be backed up, if it cannot.
+func backupEntryError(e TreeEntry) error {
+	switch {
+	case e.Truncated:
+		return fmt.Errorf("nested more than %d folders deep", maxBackupDepth)
+	case e.File.MimeType == ShortcutMimeType:
+		return fmt.Errorf("shortcuts are not backed up")
+	}
+	return nil
+}
+
+// downloadForArchive downloads a file's content for a zip backup, exporting Google Workspace files to
+// their Office format. Files larger than limit are refused.
+func (d *DriveService) downloadForArchive(ctx context.Context, f *drive.File, limit int64) ([]byte, error) {
+	exportMime := ""
+	if strings.HasPrefix(f.MimeType, "application/vnd.google-apps.") {
+		format, ok := officeFormats[f.MimeType]
+		if !ok {
+			return nil, fmt.Errorf("%s files cannot be exported", f.MimeType)
+		}
+		exportMime = exportFormats[format].mimeType
+	}
+	resp, _, err := d.openDownload(ctx, f.Id, exportMime, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read file content: %w", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("the file does not fit in the archive")
+	}
+	return data, nil
+}
+
+// writeArchiveFile adds a file to a zip archive with its Drive modified time (RFC 3339).
+func writeArchiveFile(zw *zip.Writer, name string, modified string, data []byte) error {
+	h := &zip.FileHeader{Name: name, Method: zip.Deflate}
+	if t, err := time.Parse(time.RFC3339, modified); err == nil {
+		h.Modified = t
+	}
+	w, err := zw.CreateHeader(h)
+	if err != nil {
+		return fmt.Errorf("unable to write archive: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("unable to write archive: %w", err)
+	}
+	return nil
+}
+
+// archiveName is the name of a file in zip backups: Google Workspace files get their Office extension.
+func archiveName(f *drive.File) string {
+	if format, ok := officeFormats[f.MimeType]; ok {
+		return exportedName(f.Name, exportFormats[format].mimeType)
+	}
+	return f.Name
+}
+
+// pathSeparators replaces the characters that would make a Drive name a path when extracting an archive.
+var pathSeparators = strings.NewReplacer("/", "_", "\\", "_")
+
+// treePaths returns the slash-separated path of each entry of a folder tree listed by FolderTree, using
+// name for each file or folder. Slashes and backslashes in names are replaced, as are the names "." and
+// "..", so that no path leaves the archive. Names repeated in a folder, which Drive allows, are numbered
+// ("report (2).pdf") so that each path is unique, ignoring case.
+func treePaths(entries []TreeEntry, name func(*drive.File) string) []string {
+	paths := make([]string, len(entries))
+	used := map[string]bool{}
+	// dirs[depth] is the path prefix of the entries at that depth.
+	dirs := []string{""}
+	for i, e := range entries {
+		dirs = dirs[:e.Depth+1]
+		isFolder := e.File.MimeType == FolderMimeType
+		n := strings.TrimSpace(pathSeparators.Replace(name(e.File)))
+		switch n {
+		case "":
+			n = "untitled"
+		case ".", "..":
+			n = "_"
+		}
+		ext := ""
+		if !isFolder {
+			ext = path.Ext(n)
+			n = strings.TrimSuffix(n, ext)
+		}
+		p := dirs[e.Depth] + n + ext
+		for k := 2; used[strings.ToLower(p)]; k++ {
+			p = fmt.Sprintf("%s%s (%d)%s", dirs[e.Depth], n, k, ext)
+		}
+		used[strings.ToLower(p)] = true
+		paths[i] = p
+		if isFolder {
+			dirs = append(dirs, p+"/")
+		}
+	}
+	return paths
+}
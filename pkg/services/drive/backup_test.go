@@ -0,0 +1,55 @@
+package drive
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+)
+
+func TestTreePaths(t *testing.T) {
+	folder := func(name string, depth int) TreeEntry {
+		return TreeEntry{File: &drive.File{Name: name, MimeType: FolderMimeType}, Depth: depth}
+	}
+	file := func(name, mimeType string, depth int) TreeEntry {
+		return TreeEntry{File: &drive.File{Name: name, MimeType: mimeType}, Depth: depth}
+	}
+	entries := []TreeEntry{
+		folder("Reports", 0),
+		file("Q1", "application/vnd.google-apps.spreadsheet", 1),
+		file("Q1.xlsx", "application/octet-stream", 1),
+		folder("2024/25", 1),
+		file("notes.txt", "text/plain", 2),
+		file("Plan", "application/vnd.google-apps.document", 0),
+		folder("reports", 0),
+		file("notes.txt", "text/plain", 0),
+		file("NOTES.txt", "text/plain", 0),
+		folder("..", 0),
+		file(`..\..\evil.exe`, "application/octet-stream", 1),
+		file(" . ", "text/plain", 1),
+	}
+
+	got := treePaths(entries, archiveName)
+	want := []string{
+		"Reports",
+		"Reports/Q1.xlsx",
+		"Reports/Q1 (2).xlsx",
+		"Reports/2024_25",
+		"Reports/2024_25/notes.txt",
+		"Plan.docx",
+		"reports (2)",
+		"notes.txt",
+		"NOTES (2).txt",
+		"_",
+		"_/.._.._evil.exe",
+		"_/_",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("treePaths() = %q, want %q", got, want)
+	}
+
+	got = treePaths(entries[:2], func(f *drive.File) string { return f.Name })
+	if want := []string{"Reports", "Reports/Q1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("treePaths() with Drive names = %q, want %q", got, want)
+	}
+}
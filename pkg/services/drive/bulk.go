@@ -0,0 +1,124 @@
+package drive
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// BulkChange is the change a bulk move or rename makes, or would make on a dry run, to one file.
+type BulkChange struct {
+	File    *drive.File
+	NewName string // Name after a rename
+	Skip    string // Why the file is left as it is, if it is
+	Error   string // Why the change failed
+}
+
+// PlanMove plans moving files into folderID, skipping the folder itself and files already in it.
+func PlanMove(files []*drive.File, folderID string) []BulkChange {
+	changes := make([]BulkChange, len(files))
+	for i, f := range files {
+		changes[i].File = f
+		switch {
+		case f.Id == folderID:
+			changes[i].Skip = "it is the destination folder"
+		case slices.Contains(f.Parents, folderID):
+			changes[i].Skip = "already in the folder"
+		}
+	}
+	return changes
+}
+
+// wholeName matches a whole file name, for renames without a find pattern.
+var wholeName = regexp.MustCompile(`(?s)^.*$`)
+
+// PlanRename plans renaming files by replacing the matches of find (nil: the whole name) in their names
+// with replace. replace can refer to groups of find as $1 or ${name}, and use the placeholders {n}, the
+// file's position in files (zero-padded, from 1), and {date}, its modified date (YYYY-MM-DD). Files whose
+// name does not match or would not change are skipped, as are files that would get the same name as an
+// earlier one in the same folder.
+func PlanRename(files []*drive.File, find *regexp.Regexp, replace string) []BulkChange {
+	if find == nil {
+		find = wholeName
+	}
+	width := len(strconv.Itoa(len(files)))
+	taken := map[string]string{} // Folder and new name, lowercased, to the file renamed to it
+	changes := make([]BulkChange, len(files))
+	for i, f := range files {
+		changes[i].File = f
+		matches := find.FindAllStringSubmatchIndex(f.Name, -1)
+		if matches == nil {
+			changes[i].Skip = "the name does not match"
+			continue
+		}
+		date := ""
+		if len(f.ModifiedTime) >= 10 {
+			date = f.ModifiedTime[:10]
+		}
+		placeholders := strings.NewReplacer("{n}", fmt.Sprintf("%0*d", width, i+1), "{date}", date)
+
+		var b strings.Builder
+		last := 0
+		for _, m := range matches {
+			b.WriteString(f.Name[last:m[0]])
+			b.WriteString(placeholders.Replace(string(find.ExpandString(nil, replace, f.Name, m))))
+			last = m[1]
+		}
+		b.WriteString(f.Name[last:])
+		name := strings.TrimSpace(b.String())
+
+		key := strings.Join(f.Parents, ",") + "/" + strings.ToLower(name)
+		switch {
+		case name == "":
+			changes[i].Skip = "the new name would be empty"
+		case name == f.Name:
+			changes[i].Skip = "the name would not change"
+		case taken[key] != "":
+			changes[i].Skip = fmt.Sprintf("%s would also be renamed to %s", taken[key], name)
+		default:
+			changes[i].NewName = name
+			taken[key] = f.Name
+		}
+	}
+	return changes
+}
+
+// BulkMove moves files into folderID as planned by PlanMove and reports the outcome for each file.
+// A failed move does not stop the others. With dryRun nothing is moved.
+func (d *DriveService) BulkMove(ctx context.Context, files []*drive.File, folderID string, dryRun bool) ([]BulkChange, error) {
+	folder, err := d.getResolved(ctx, folderID, "name")
+	if err != nil {
+		return nil, err
+	}
+	if folder.MimeType != FolderMimeType {
+		return nil, fmt.Errorf("%s is not a folder", folder.Name)
+	}
+	changes := PlanMove(files, folder.Id)
+	for i := range changes {
+		if c := &changes[i]; c.Skip == "" && !dryRun {
+			if _, err := d.MoveFile(ctx, c.File.Id, folder.Id); err != nil {
+				c.Error = err.Error()
+			}
+		}
+	}
+	return changes, nil
+}
+
+// BulkRename renames files as planned by PlanRename and reports the outcome for each file. A failed
+// rename does not stop the others. With dryRun nothing is renamed.
+func (d *DriveService) BulkRename(ctx context.Context, files []*drive.File, find *regexp.Regexp, replace string, dryRun bool) []BulkChange {
+	changes := PlanRename(files, find, replace)
+	for i := range changes {
+		if c := &changes[i]; c.Skip == "" && !dryRun {
+			if _, err := d.UpdateFile(ctx, c.File.Id, c.NewName, "", "", nil); err != nil {
+				c.Error = err.Error()
+			}
+		}
+	}
+	return changes
+}
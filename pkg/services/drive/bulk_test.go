@@ -0,0 +1,111 @@
+package drive
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+)
+
+func TestPlanMove(t *testing.T) {
+	files := []*drive.File{
+		{Id: "a", Parents: []string{"root"}},
+		{Id: "dest", Parents: []string{"root"}},
+		{Id: "b", Parents: []string{"dest"}},
+	}
+	var skips []string
+	for _, c := range PlanMove(files, "dest") {
+		skips = append(skips, c.Skip)
+	}
+	if want := []string{"", "it is the destination folder", "already in the folder"}; !reflect.DeepEqual(skips, want) {
+		t.Errorf("PlanMove() skips = %q, want %q", skips, want)
+	}
+}
+
+func TestPlanRename(t *testing.T) {
+	file := func(name, parent, modified string) *drive.File {
+		return &drive.File{Name: name, Parents: []string{parent}, ModifiedTime: modified}
+	}
+	files := []*drive.File{
+		file("Scan 0042.pdf", "p", "2024-03-05T10:00:00Z"),
+		file("Scan 0043.pdf", "p", "2024-03-06T10:00:00Z"),
+		file("notes.txt", "p", "2024-03-07T10:00:00Z"),
+		file("Scan 0042.PDF", "q", "2024-03-05T10:00:00Z"),
+		file("Invoice 2024-03-05.pdf", "p", "2024-03-05T10:00:00Z"),
+	}
+
+	tests := []struct {
+		name    string
+		find    *regexp.Regexp
+		replace string
+		want    []string // New name, or "skip: <reason>"
+	}{
+		{
+			name:    "groups and placeholders",
+			find:    regexp.MustCompile(`(?i)^Scan (\d+)\.pdf$`),
+			replace: "Invoice {date} #${1} ({n}).pdf",
+			want: []string{
+				"Invoice 2024-03-05 #0042 (1).pdf",
+				"Invoice 2024-03-06 #0043 (2).pdf",
+				"skip: the name does not match",
+				"Invoice 2024-03-05 #0042 (4).pdf",
+				"skip: the name does not match",
+			},
+		},
+		{
+			name:    "every match",
+			find:    regexp.MustCompile(`\d`),
+			replace: "",
+			want: []string{
+				"Scan .pdf",
+				"skip: Scan 0042.pdf would also be renamed to Scan .pdf",
+				"skip: the name does not match",
+				"Scan .PDF",
+				"Invoice --.pdf",
+			},
+		},
+		{
+			name:    "whole name",
+			replace: "{date}",
+			want: []string{
+				"2024-03-05",
+				"2024-03-06",
+				"2024-03-07",
+				"2024-03-05",
+				"skip: Scan 0042.pdf would also be renamed to 2024-03-05",
+			},
+		},
+		{
+			name:    "unchanged or empty",
+			find:    regexp.MustCompile(`^Scan.*|\.txt$`),
+			replace: "$0",
+			want: []string{
+				"skip: the name would not change",
+				"skip: the name would not change",
+				"skip: the name would not change",
+				"skip: the name would not change",
+				"skip: the name does not match",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, c := range PlanRename(files, tt.find, tt.replace) {
+				if c.Skip != "" {
+					got = append(got, "skip: "+c.Skip)
+				} else {
+					got = append(got, c.NewName)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PlanRename() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if c := PlanRename(files[2:3], regexp.MustCompile(`.*`), " "); c[0].Skip != "the new name would be empty" {
+		t.Errorf("renaming to blank: %+v", c[0])
+	}
+}
@@ -0,0 +1,140 @@
+package drive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// FileChange is a file added, modified, trashed or removed since a change cursor.
+type FileChange struct {
+	Change       string `json:"change"` // added, modified, trashed or removed
+	FileID       string `json:"file_id"`
+	Name         string `json:"name,omitempty"`
+	MimeType     string `json:"mime_type,omitempty"`
+	ModifiedTime string `json:"modified_time,omitempty"`
+	Time         string `json:"time"` // When the change was recorded
+}
+
+// FileChanges is one batch of changes from ChangesSince.
+type FileChanges struct {
+	Changes   []FileChange `json:"changes"`
+	PageToken string       `json:"page_token"`     // Cursor to continue from
+	More      bool         `json:"more,omitempty"` // More changes are pending beyond the limit
+	Since     time.Time    `json:"since"`          // Start of the window the changes cover
+}
+
+// ChangeCursor is a saved position in the Drive changes feed.
+type ChangeCursor struct {
+	PageToken string    `json:"page_token"`
+	Since     time.Time `json:"since"` // When the cursor's window started; files created after it count as added
+}
+
+// LoadChangeCursor reads the cursor saved for account in the JSON file at path. ok is false when none is saved.
+func LoadChangeCursor(path, account string) (cursor ChangeCursor, ok bool, err error) {
+	cursors, err := readChangeCursors(path)
+	if err != nil {
+		return ChangeCursor{}, false, err
+	}
+	cursor, ok = cursors[account]
+	return cursor, ok, nil
+}
+
+// SaveChangeCursor stores the cursor for account in the JSON file at path, keeping other accounts' cursors.
+func SaveChangeCursor(path, account string, cursor ChangeCursor) error {
+	cursors, err := readChangeCursors(path)
+	if err != nil {
+		return err
+	}
+	cursors[account] = cursor
+	data, err := json.MarshalIndent(cursors, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func readChangeCursors(path string) (map[string]ChangeCursor, error) {
+	cursors := map[string]ChangeCursor{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cursors, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &cursors); err != nil {
+		return nil, fmt.Errorf("invalid change cursor file %s: %w", path, err)
+	}
+	return cursors, nil
+}
+
+// StartPageToken returns a cursor for changes from now on.
+func (d *DriveService) StartPageToken(ctx context.Context) (string, error) {
+	t, err := d.srv.Changes.GetStartPageToken().SupportsAllDrives(true).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("unable to get start page token: %w", err)
+	}
+	return t.StartPageToken, nil
+}
+
+// fileChange converts a change, classifying files created after since as added.
+func fileChange(c *drive.Change, since time.Time) FileChange {
+	fc := FileChange{FileID: c.FileId, Time: c.Time, Change: "modified"}
+	switch {
+	case c.Removed || c.File == nil:
+		fc.Change = "removed"
+	default:
+		fc.Name, fc.MimeType, fc.ModifiedTime = c.File.Name, c.File.MimeType, c.File.ModifiedTime
+		created, err := time.Parse(time.RFC3339, c.File.CreatedTime)
+		if c.File.Trashed {
+			fc.Change = "trashed"
+		} else if err == nil && !since.IsZero() && !created.Before(since) {
+			fc.Change = "added"
+		}
+	}
+	return fc
+}
+
+// ChangesSince lists file changes (not shared drive changes) after pageToken, up to limit. When more changes
+// are pending, More is set and PageToken continues the batch; otherwise PageToken is the cursor for future changes.
+func (d *DriveService) ChangesSince(ctx context.Context, pageToken string, since time.Time, limit int) (*FileChanges, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	out := &FileChanges{Changes: []FileChange{}, Since: since}
+	token := pageToken
+	for {
+		r, err := d.srv.Changes.List(token).
+			IncludeRemoved(true).
+			SupportsAllDrives(true).
+			IncludeItemsFromAllDrives(true).
+			PageSize(int64(min(limit, 1000))).
+			Fields("nextPageToken, newStartPageToken, changes(changeType, fileId, removed, time, file(name, mimeType, modifiedTime, createdTime, trashed))").
+			Context(ctx).
+			Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to list changes: %w", err)
+		}
+		for _, c := range r.Changes {
+			if c.ChangeType == "drive" {
+				continue
+			}
+			out.Changes = append(out.Changes, fileChange(c, since))
+		}
+		if r.NewStartPageToken != "" {
+			out.PageToken = r.NewStartPageToken
+			return out, nil
+		}
+		token = r.NextPageToken
+		if len(out.Changes) >= limit {
+			out.PageToken, out.More = token, true
+			return out, nil
+		}
+	}
+}
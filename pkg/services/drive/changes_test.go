@@ -0,0 +1,49 @@
+package drive
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+func TestFileChange(t *testing.T) {
+	since := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		c    *drive.Change
+		want string
+	}{
+		{"removed", &drive.Change{FileId: "a", Removed: true}, "removed"},
+		{"trashed", &drive.Change{FileId: "b", File: &drive.File{Trashed: true, CreatedTime: "2024-05-02T00:00:00Z"}}, "trashed"},
+		{"new", &drive.Change{FileId: "c", File: &drive.File{Name: "Notes", CreatedTime: "2024-05-01T13:00:00Z"}}, "added"},
+		{"edited", &drive.Change{FileId: "d", File: &drive.File{Name: "Plan", CreatedTime: "2023-01-01T00:00:00Z"}}, "modified"},
+	}
+	for _, tt := range tests {
+		if got := fileChange(tt.c, since); got.Change != tt.want || got.FileID != tt.c.FileId {
+			t.Errorf("%s: fileChange = %+v, want change %q", tt.name, got, tt.want)
+		}
+	}
+	if got := fileChange(tests[2].c, time.Time{}); got.Change != "modified" {
+		t.Errorf("without since, new files should be reported as modified, got %q", got.Change)
+	}
+}
+
+func TestChangeCursorStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "drive_changes.json")
+	if _, ok, err := LoadChangeCursor(path, "a@example.com"); err != nil || ok {
+		t.Fatalf("LoadChangeCursor on missing file = %v, %v", ok, err)
+	}
+	since := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := SaveChangeCursor(path, "a@example.com", ChangeCursor{PageToken: "10", Since: since}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveChangeCursor(path, "b@example.com", ChangeCursor{PageToken: "20"}); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := LoadChangeCursor(path, "a@example.com")
+	if err != nil || !ok || got.PageToken != "10" || !got.Since.Equal(since) {
+		t.Errorf("LoadChangeCursor = %+v, %v, %v", got, ok, err)
+	}
+}
@@ -1,13 +1,23 @@
 package drive
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 )
 
@@ -25,14 +35,25 @@ func New(ctx context.Context, opts ...option.ClientOption) (*DriveService, error
 	return &DriveService{srv: srv}, nil
 }
 
+// Ping makes a cheap request to check that the API is enabled and its scope granted.
+func (d *DriveService) Ping(ctx context.Context) error {
+	if _, err := d.srv.About.Get().Fields("user(emailAddress)").Context(ctx).Do(); err != nil {
+		return fmt.Errorf("unable to get account info: %w", err)
+	}
+	return nil
+}
+
 // ListFiles lists the first n files.
-func (d *DriveService) ListFiles(limit int64) ([]*drive.File, error) {
+func (d *DriveService) ListFiles(ctx context.Context, limit int64) ([]*drive.File, error) {
 	if limit <= 0 {
 		limit = 10
 	}
 	r, err := d.srv.Files.List().
+		SupportsAllDrives(true).
+		IncludeItemsFromAllDrives(true).
 		PageSize(limit).
-		Fields("nextPageToken, files(id, name, mimeType, parents)").
+		Fields("nextPageToken, files(id, name, mimeType, parents, driveId, shortcutDetails)").
+		Context(ctx).
 		Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve files: %w", err)
@@ -40,9 +61,78 @@ func (d *DriveService) ListFiles(limit int64) ([]*drive.File, error) {
 	return r.Files, nil
 }
 
+// ShortcutMimeType is the mime type of Drive shortcuts.
+const ShortcutMimeType = "application/vnd.google-apps.shortcut"
+
+// getResolved fetches file metadata with the given fields, following a shortcut to its target.
+// The returned file's Id is the target's ID, so content calls should use it instead of the shortcut ID.
+func (d *DriveService) getResolved(ctx context.Context, fileID string, fields ...googleapi.Field) (*drive.File, error) {
+	fields = append(fields, "id", "mimeType", "shortcutDetails")
+	f, err := d.srv.Files.Get(fileID).SupportsAllDrives(true).Fields(fields...).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get file metadata: %w", err)
+	}
+	if f.MimeType != ShortcutMimeType || f.ShortcutDetails == nil {
+		return f, nil
+	}
+	f, err = d.srv.Files.Get(f.ShortcutDetails.TargetId).SupportsAllDrives(true).Fields(fields...).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get shortcut target metadata: %w", err)
+	}
+	return f, nil
+}
+
+// CreateShortcut creates a shortcut to targetID in parentID (default: My Drive root).
+// An empty name uses the target's name.
+func (d *DriveService) CreateShortcut(ctx context.Context, targetID string, name string, parentID string) (*drive.File, error) {
+	if targetID == "" {
+		return nil, fmt.Errorf("target_id is required")
+	}
+	if name == "" {
+		target, err := d.srv.Files.Get(targetID).SupportsAllDrives(true).Fields("name").Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get target metadata: %w", err)
+		}
+		name = target.Name
+	}
+	f := &drive.File{
+		Name:            name,
+		MimeType:        ShortcutMimeType,
+		ShortcutDetails: &drive.FileShortcutDetails{TargetId: targetID},
+	}
+	if parentID != "" {
+		f.Parents = []string{parentID}
+	}
+	file, err := d.srv.Files.Create(f).SupportsAllDrives(true).Fields("id", "name", "parents", "shortcutDetails").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create shortcut: %w", err)
+	}
+	return file, nil
+}
+
+// SetStarred stars or unstars a file.
+func (d *DriveService) SetStarred(ctx context.Context, fileID string, starred bool) (*drive.File, error) {
+	if fileID == "" {
+		return nil, fmt.Errorf("file_id is required")
+	}
+	f := &drive.File{Starred: starred, ForceSendFields: []string{"Starred"}}
+	file, err := d.srv.Files.Update(fileID, f).SupportsAllDrives(true).Fields("id", "name", "starred").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to update starred: %w", err)
+	}
+	return file, nil
+}
+
+// SearchScope selects which corpus a search runs over.
+// The zero value searches My Drive and files shared with the user (including shared drive files they can access).
+type SearchScope struct {
+	Corpora string // "user", "drive", "allDrives" or "domain"
+	DriveID string // Shared drive to search; implies Corpora "drive"
+}
+
 // SearchFiles searches for files using specific criteria.
 // Use empty query to list non-trashed files (account-wide). Default filter is trashed = false.
-func (d *DriveService) SearchFiles(query string, limit int64) ([]*drive.File, error) {
+func (d *DriveService) SearchFiles(ctx context.Context, query string, limit int64, scope SearchScope) ([]*drive.File, error) {
 	if limit <= 0 {
 		limit = 10
 	}
@@ -52,15 +142,114 @@ func (d *DriveService) SearchFiles(query string, limit int64) ([]*drive.File, er
 		query = fmt.Sprintf("(%s) and trashed = false", query)
 	}
 
-	r, err := d.srv.Files.List().
-		Q(query).
-		PageSize(limit).
-		Fields("nextPageToken, files(id, name, mimeType, parents)").
-		Do()
-	if err != nil {
-		return nil, fmt.Errorf("unable to search files: %w", err)
+	var out []*drive.File
+	pageToken := ""
+	for int64(len(out)) < limit {
+		call := d.srv.Files.List().
+			Q(query).
+			SupportsAllDrives(true).
+			IncludeItemsFromAllDrives(true).
+			PageSize(min(limit-int64(len(out)), 1000)).
+			Fields("nextPageToken, files(id, name, mimeType, parents, driveId, modifiedTime, shortcutDetails)")
+		if scope.DriveID != "" {
+			call.Corpora("drive").DriveId(scope.DriveID)
+		} else if scope.Corpora != "" {
+			call.Corpora(scope.Corpora)
+		}
+		if pageToken != "" {
+			call.PageToken(pageToken)
+		}
+		r, err := call.Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to search files: %w", err)
+		}
+		out = append(out, r.Files...)
+		if r.NextPageToken == "" {
+			break
+		}
+		pageToken = r.NextPageToken
 	}
-	return r.Files, nil
+	return out, nil
+}
+
+// FolderMimeType is the mime type of Drive folders.
+const FolderMimeType = "application/vnd.google-apps.folder"
+
+// ListFolder lists the direct children of a folder, folders first. Use "root" for My Drive.
+func (d *DriveService) ListFolder(ctx context.Context, folderID string, limit int64) ([]*drive.File, error) {
+	if folderID == "" {
+		folderID = "root"
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	var out []*drive.File
+	pageToken := ""
+	for int64(len(out)) < limit {
+		call := d.srv.Files.List().
+			Q(fmt.Sprintf("%s in parents and trashed = false", quoteQueryValue(folderID))).
+			SupportsAllDrives(true).
+			IncludeItemsFromAllDrives(true).
+			Corpora("allDrives").
+			OrderBy("folder,name").
+			PageSize(min(limit-int64(len(out)), 1000)).
+			Fields("nextPageToken, files(id, name, mimeType, size, modifiedTime)")
+		if pageToken != "" {
+			call.PageToken(pageToken)
+		}
+		r, err := call.Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to list folder: %w", err)
+		}
+		out = append(out, r.Files...)
+		if r.NextPageToken == "" {
+			break
+		}
+		pageToken = r.NextPageToken
+	}
+	return out, nil
+}
+
+// TreeEntry is a file in a folder tree with its depth below the root (0 for direct children).
+type TreeEntry struct {
+	File      *drive.File
+	Depth     int
+	Truncated bool // The folder has children that were not listed because of maxDepth
+}
+
+// FolderTree walks a folder depth-first down to maxDepth levels (1 = direct children only),
+// stopping after maxItems entries. The second return value reports whether maxItems was reached.
+func (d *DriveService) FolderTree(ctx context.Context, folderID string, maxDepth int, maxItems int) ([]TreeEntry, bool, error) {
+	if maxDepth <= 0 {
+		maxDepth = 1
+	}
+	if maxItems <= 0 {
+		maxItems = 500
+	}
+	var out []TreeEntry
+	var walk func(id string, depth int) (bool, error)
+	walk = func(id string, depth int) (bool, error) {
+		children, err := d.ListFolder(ctx, id, int64(maxItems-len(out)))
+		if err != nil {
+			return false, err
+		}
+		for _, f := range children {
+			if len(out) >= maxItems {
+				return true, nil
+			}
+			isFolder := f.MimeType == FolderMimeType
+			out = append(out, TreeEntry{File: f, Depth: depth, Truncated: isFolder && depth+1 >= maxDepth})
+			if isFolder && depth+1 < maxDepth {
+				full, err := walk(f.Id, depth+1)
+				if err != nil || full {
+					return full, err
+				}
+			}
+		}
+		return len(out) >= maxItems, nil
+	}
+	full, err := walk(folderID, 0)
+	return out, full, err
 }
 
 // SearchFileResult holds a file and an optional content snippet (e.g. first N bytes).
@@ -71,81 +260,131 @@ type SearchFileResult struct {
 
 // SearchFilesWithSnippets runs SearchFiles and optionally fetches a short content snippet per file.
 // maxSnippetBytes limits snippet length per file; 0 disables snippets. Snippet fetch errors are ignored.
-func (d *DriveService) SearchFilesWithSnippets(query string, limit int64, maxSnippetBytes int64) ([]SearchFileResult, error) {
-	files, err := d.SearchFiles(query, limit)
+// Snippets are fetched concurrently, each with its own timeout; files that are not text-like are skipped.
+func (d *DriveService) SearchFilesWithSnippets(ctx context.Context, query string, limit int64, maxSnippetBytes int64, scope SearchScope) ([]SearchFileResult, error) {
+	files, err := d.SearchFiles(ctx, query, limit, scope)
 	if err != nil {
 		return nil, err
 	}
 	out := make([]SearchFileResult, len(files))
 	for i, f := range files {
 		out[i] = SearchFileResult{File: f}
-		if maxSnippetBytes <= 0 {
-			continue
+	}
+	if maxSnippetBytes <= 0 {
+		return out, nil
+	}
+
+	sem := make(chan struct{}, snippetWorkers)
+	var wg sync.WaitGroup
+	for i, f := range files {
+		id, mimeType := f.Id, f.MimeType
+		if f.ShortcutDetails != nil {
+			id, mimeType = f.ShortcutDetails.TargetId, f.ShortcutDetails.TargetMimeType
 		}
-		snippet, err := d.ReadFileContent(f.Id, maxSnippetBytes)
-		if err != nil {
-			continue // leave Snippet empty on error
+		if !IsTextual(mimeType) {
+			continue
 		}
-		out[i].Snippet = snippet
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			ctx, cancel := context.WithTimeout(ctx, snippetTimeout)
+			defer cancel()
+			snippet, err := d.readFileContent(ctx, id, mimeType, maxSnippetBytes)
+			if err != nil {
+				return // leave Snippet empty on error
+			}
+			out[i].Snippet = snippet
+		}()
 	}
+	wg.Wait()
 	return out, nil
 }
 
+// Snippet fetching limits: concurrent downloads and time allowed per file.
+const (
+	snippetWorkers = 5
+	snippetTimeout = 10 * time.Second
+)
+
+// IsTextual reports whether a file of this mime type has readable text content
+// (plain text formats and Google Docs, Sheets and Slides).
+func IsTextual(mimeType string) bool {
+	switch mimeType {
+	case "application/vnd.google-apps.document",
+		"application/vnd.google-apps.spreadsheet",
+		"application/vnd.google-apps.presentation",
+		"application/json", "application/xml", "application/javascript",
+		"application/x-yaml", "application/yaml", "application/sql":
+		return true
+	}
+	return strings.HasPrefix(mimeType, "text/")
+}
+
 // findFilesQuery builds the Drive fullText query for a search term (escapes ' and \).
 func findFilesQuery(searchTerm string) string {
-	escaped := strings.ReplaceAll(searchTerm, `\`, `\\`)
-	escaped = strings.ReplaceAll(escaped, `'`, `\'`)
-	return fmt.Sprintf("fullText contains '%s' and trashed = false", escaped)
+	return fmt.Sprintf("fullText contains %s and trashed = false", quoteQueryValue(searchTerm))
 }
 
 // FindFiles runs an account-wide fullText search. Use for discovery when you know a phrase to search for.
-func (d *DriveService) FindFiles(searchTerm string, limit int64) ([]*drive.File, error) {
+func (d *DriveService) FindFiles(ctx context.Context, searchTerm string, limit int64, scope SearchScope) ([]*drive.File, error) {
 	if searchTerm == "" {
-		return d.SearchFiles("", limit)
+		return d.SearchFiles(ctx, "", limit, scope)
 	}
-	return d.SearchFiles(findFilesQuery(searchTerm), limit)
+	return d.SearchFiles(ctx, findFilesQuery(searchTerm), limit, scope)
 }
 
 // FindFilesWithSnippets runs FindFiles and optionally fetches a short content snippet per file.
-func (d *DriveService) FindFilesWithSnippets(searchTerm string, limit int64, maxSnippetBytes int64) ([]SearchFileResult, error) {
+func (d *DriveService) FindFilesWithSnippets(ctx context.Context, searchTerm string, limit int64, maxSnippetBytes int64, scope SearchScope) ([]SearchFileResult, error) {
 	if searchTerm == "" {
-		return d.SearchFilesWithSnippets("trashed = false", limit, maxSnippetBytes)
+		return d.SearchFilesWithSnippets(ctx, "trashed = false", limit, maxSnippetBytes, scope)
 	}
-	return d.SearchFilesWithSnippets(findFilesQuery(searchTerm), limit, maxSnippetBytes)
+	return d.SearchFilesWithSnippets(ctx, findFilesQuery(searchTerm), limit, maxSnippetBytes, scope)
 }
 
 // ReadFileContent downloads and reads the content of a file.
 // limitBytes limits the number of bytes read. -1 for no limit (use with caution).
-func (d *DriveService) ReadFileContent(fileID string, limitBytes int64) (string, error) {
-	// Check file metadata first to see if we need to export
-	f, err := d.srv.Files.Get(fileID).Fields("mimeType").Do()
-	if err != nil {
-		return "", fmt.Errorf("unable to get file metadata: %w", err)
+func (d *DriveService) ReadFileContent(ctx context.Context, fileID string, limitBytes int64) (string, error) {
+	return d.readFileContent(ctx, fileID, "", limitBytes)
+}
+
+// readFileContent implements ReadFileContent. If mimeType is empty it is looked up first.
+func (d *DriveService) readFileContent(ctx context.Context, fileID string, mimeType string, limitBytes int64) (string, error) {
+	if mimeType == "" || mimeType == ShortcutMimeType {
+		// Check file metadata first to see if we need to export
+		f, err := d.getResolved(ctx, fileID, "mimeType")
+		if err != nil {
+			return "", err
+		}
+		fileID, mimeType = f.Id, f.MimeType
 	}
 
 	var resp *http.Response
+	var err error
 
 	// Handle Google Workspace documents by Exporting
-	if strings.HasPrefix(f.MimeType, "application/vnd.google-apps.") {
+	if strings.HasPrefix(mimeType, "application/vnd.google-apps.") {
 		// Default export formats:
 		// Docs -> text/plain
 		// Sheets -> application/pdf (no text export), or csv? Sheets CSV export is usually via "text/csv"
 		// Slides -> text/plain
 
 		exportMime := "text/plain"
-		if f.MimeType == "application/vnd.google-apps.spreadsheet" {
+		if mimeType == "application/vnd.google-apps.spreadsheet" {
 			exportMime = "text/csv"
 		}
 		// Try export
-		resp, err = d.srv.Files.Export(fileID, exportMime).Download()
+		resp, err = d.srv.Files.Export(fileID, exportMime).Context(ctx).Download()
 		if err != nil {
 			// Fallback or specific error handling
 			// If text/plain isn't supported for this type, return error
-			return "", fmt.Errorf("unable to export file (mime: %s) as %s: %w", f.MimeType, exportMime, err)
+			return "", fmt.Errorf("unable to export file (mime: %s) as %s: %w", mimeType, exportMime, err)
 		}
 	} else {
 		// Standard binary download
-		resp, err = d.srv.Files.Get(fileID).Download()
+		resp, err = d.srv.Files.Get(fileID).SupportsAllDrives(true).Context(ctx).Download()
 		if err != nil {
 			return "", fmt.Errorf("unable to download file: %w", err)
 		}
@@ -166,17 +405,346 @@ func (d *DriveService) ReadFileContent(fileID string, limitBytes int64) (string,
 	return string(content), nil
 }
 
+// FileChunk is a slice of a file's content read with ReadFileRange.
+type FileChunk struct {
+	Content string
+	Offset  int64 // Byte offset of Content in the file (or export)
+	Total   int64 // Total size in bytes, -1 if unknown
+	Next    int64 // Offset to continue reading from, -1 at the end of the file
+}
+
+// ReadFileRange reads up to length bytes of a file starting at offset, for reading large files incrementally.
+// Regular files are fetched with an HTTP Range request; Google Workspace documents are exported as text
+// (CSV for Sheets) and PDFs and images are converted with OCR (see ExtractText), then sliced. Chunks end on a UTF-8 character boundary, so Next may be less than offset+length.
+func (d *DriveService) ReadFileRange(ctx context.Context, fileID string, offset int64, length int64) (*FileChunk, error) {
+	if offset < 0 {
+		return nil, fmt.Errorf("offset must not be negative")
+	}
+	if length <= 0 {
+		length = 32 * 1024
+	}
+	f, err := d.getResolved(ctx, fileID, "mimeType", "size")
+	if err != nil {
+		return nil, err
+	}
+	fileID = f.Id
+
+	var data []byte
+	total := int64(-1)
+	if strings.HasPrefix(f.MimeType, "application/vnd.google-apps.") || NeedsOCR(f.MimeType) {
+		var all []byte
+		if NeedsOCR(f.MimeType) {
+			text, err := d.ExtractText(ctx, fileID, "")
+			if err != nil {
+				return nil, err
+			}
+			all = []byte(text)
+		} else {
+			exportMime := "text/plain"
+			if f.MimeType == "application/vnd.google-apps.spreadsheet" {
+				exportMime = "text/csv"
+			}
+			resp, err := d.srv.Files.Export(fileID, exportMime).Context(ctx).Download()
+			if err != nil {
+				return nil, fmt.Errorf("unable to export file (mime: %s) as %s: %w", f.MimeType, exportMime, err)
+			}
+			all, err = io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			if err != nil {
+				return nil, fmt.Errorf("unable to read file content: %w", err)
+			}
+		}
+		total = int64(len(all))
+		start := min(offset, total)
+		data = all[start:min(start+length, total)]
+	} else {
+		total = f.Size
+		if offset >= total {
+			return &FileChunk{Offset: offset, Total: total, Next: -1}, nil
+		}
+		call := d.srv.Files.Get(fileID).SupportsAllDrives(true)
+		call.Header().Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+		resp, err := call.Context(ctx).Download()
+		if err != nil {
+			return nil, fmt.Errorf("unable to download file: %w", err)
+		}
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		// Servers that ignore Range return the whole file; skip to the offset ourselves.
+		if resp.StatusCode != http.StatusPartialContent && offset > 0 {
+			if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil {
+				return nil, fmt.Errorf("unable to read file content: %w", err)
+			}
+		}
+		data, err = io.ReadAll(io.LimitReader(resp.Body, length))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read file content: %w", err)
+		}
+	}
+
+	end := offset + int64(len(data))
+	if trimmed := trimToRuneBoundary(data); end < total && len(trimmed) > 0 {
+		data = trimmed
+		end = offset + int64(len(data))
+	}
+	chunk := &FileChunk{Content: string(data), Offset: offset, Total: total, Next: end}
+	if end >= total || len(data) == 0 {
+		chunk.Next = -1
+	}
+	return chunk, nil
+}
+
+// NeedsOCR reports whether text can only be read from files of this mime type by converting them.
+func NeedsOCR(mimeType string) bool {
+	return mimeType == "application/pdf" || strings.HasPrefix(mimeType, "image/")
+}
+
+// ExtractText returns the text of a PDF or image file. Drive converts a temporary copy to a Google Doc,
+// running OCR on scanned pages and images, exports it as plain text and then deletes the copy.
+// ocrLanguage is an optional ISO 639-1 hint such as "en" or "pt".
+func (d *DriveService) ExtractText(ctx context.Context, fileID string, ocrLanguage string) (string, error) {
+	call := d.srv.Files.Copy(fileID, &drive.File{
+		Name:     "OCR temp " + fileID,
+		MimeType: "application/vnd.google-apps.document",
+		Parents:  []string{"root"},
+	}).SupportsAllDrives(true).Fields("id")
+	if ocrLanguage != "" {
+		call.OcrLanguage(ocrLanguage)
+	}
+	doc, err := call.Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("unable to convert file for text extraction: %w", err)
+	}
+	defer func() {
+		_ = d.srv.Files.Delete(doc.Id).Context(ctx).Do()
+	}()
+
+	resp, err := d.srv.Files.Export(doc.Id, "text/plain").Context(ctx).Download()
+	if err != nil {
+		return "", fmt.Errorf("unable to export extracted text: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	text, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("unable to read extracted text: %w", err)
+	}
+	return strings.TrimPrefix(string(text), "\ufeff"), nil
+}
+
+// trimToRuneBoundary drops a trailing incomplete UTF-8 sequence, so a chunk never splits a character.
+func trimToRuneBoundary(b []byte) []byte {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				return b[:i]
+			}
+			return b
+		}
+	}
+	return b
+}
+
+// DownloadedFile is the full binary content of a Drive file.
+type DownloadedFile struct {
+	Name     string
+	MimeType string
+	Data     []byte
+}
+
+// exportFormats maps short export format names to their mime type and file extension.
+var exportFormats = map[string]struct{ mimeType, ext string }{
+	"pdf":  {"application/pdf", ".pdf"},
+	"docx": {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", ".docx"},
+	"xlsx": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", ".xlsx"},
+	"pptx": {"application/vnd.openxmlformats-officedocument.presentationml.presentation", ".pptx"},
+	"odt":  {"application/vnd.oasis.opendocument.text", ".odt"},
+	"csv":  {"text/csv", ".csv"},
+	"tsv":  {"text/tab-separated-values", ".tsv"},
+	"txt":  {"text/plain", ".txt"},
+	"html": {"text/html", ".html"},
+	"md":   {"text/markdown", ".md"},
+	"png":  {"image/png", ".png"},
+	"svg":  {"image/svg+xml", ".svg"},
+}
+
+// ExportMimeType resolves an export format name ("pdf", "docx", "xlsx", "csv", "txt", ...) or a mime type
+// to the mime type to export Google Workspace files as.
+func ExportMimeType(format string) (string, error) {
+	if format == "" || strings.Contains(format, "/") {
+		return format, nil
+	}
+	if f, ok := exportFormats[strings.ToLower(format)]; ok {
+		return f.mimeType, nil
+	}
+	return "", fmt.Errorf("unsupported export format %q", format)
+}
+
+// exportedName adds the extension matching exportMime to name, unless it already has it.
+func exportedName(name string, exportMime string) string {
+	for _, f := range exportFormats {
+		if f.mimeType == exportMime {
+			if !strings.HasSuffix(strings.ToLower(name), f.ext) {
+				return name + f.ext
+			}
+			return name
+		}
+	}
+	return name
+}
+
+// ErrFileTooLarge is returned when a file is over the size limit of a download.
+var ErrFileTooLarge = errors.New("file too large")
+
+// openDownload starts downloading a file. Google Workspace documents cannot be downloaded directly,
+// so they are exported as exportMime (default application/pdf) and the matching extension is added to the name.
+// Other files larger than limit bytes (0 for no limit) are refused before downloading; the size of
+// exports is only known once read.
+func (d *DriveService) openDownload(ctx context.Context, fileID string, exportMime string, limit int64) (*http.Response, *DownloadedFile, error) {
+	f, err := d.getResolved(ctx, fileID, "name", "mimeType", "size")
+	if err != nil {
+		return nil, nil, err
+	}
+	fileID = f.Id
+
+	out := &DownloadedFile{Name: f.Name, MimeType: f.MimeType}
+	var resp *http.Response
+	if strings.HasPrefix(f.MimeType, "application/vnd.google-apps.") {
+		if exportMime == "" {
+			exportMime = "application/pdf"
+		}
+		resp, err = d.srv.Files.Export(fileID, exportMime).Context(ctx).Download()
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to export file (mime: %s) as %s: %w", f.MimeType, exportMime, err)
+		}
+		out.MimeType = exportMime
+		out.Name = exportedName(out.Name, exportMime)
+	} else {
+		if limit > 0 && f.Size > limit {
+			return nil, nil, fmt.Errorf("%w: %s is %s, over the %s limit", ErrFileTooLarge, f.Name, FormatBytes(f.Size), FormatBytes(limit))
+		}
+		resp, err = d.srv.Files.Get(fileID).SupportsAllDrives(true).Context(ctx).Download()
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to download file: %w", err)
+		}
+	}
+	return resp, out, nil
+}
+
+// DownloadFile downloads a file's binary content into memory.
+// Google Workspace documents are exported as exportMime (default application/pdf).
+func (d *DriveService) DownloadFile(ctx context.Context, fileID string, exportMime string) (*DownloadedFile, error) {
+	return d.DownloadFileLimit(ctx, fileID, exportMime, 0)
+}
+
+// DownloadFileLimit is DownloadFile for files of at most limit bytes (0 for no limit). Larger files fail
+// with ErrFileTooLarge, without being read into memory: stored files are refused by their size, and
+// exports as soon as more than limit bytes have been read.
+func (d *DriveService) DownloadFileLimit(ctx context.Context, fileID string, exportMime string, limit int64) (*DownloadedFile, error) {
+	resp, out, err := d.openDownload(ctx, fileID, exportMime, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body := io.Reader(resp.Body)
+	if limit > 0 {
+		body = io.LimitReader(resp.Body, limit+1)
+	}
+	out.Data, err = io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read file content: %w", err)
+	}
+	if limit > 0 && int64(len(out.Data)) > limit {
+		return nil, fmt.Errorf("%w: %s is over the %s limit", ErrFileTooLarge, out.Name, FormatBytes(limit))
+	}
+	return out, nil
+}
+
+// pdfExportable lists the Google Workspace types that can be exported as PDF.
+var pdfExportable = []string{
+	"application/vnd.google-apps.document",
+	"application/vnd.google-apps.spreadsheet",
+	"application/vnd.google-apps.presentation",
+	"application/vnd.google-apps.drawing",
+}
+
+// ExportPDF exports a Google Doc, Sheet, Slides deck or Drawing as PDF, named after the file with a .pdf
+// extension. It also returns the file's parent folders, so the PDF can be saved next to it.
+func (d *DriveService) ExportPDF(ctx context.Context, fileID string) (*DownloadedFile, []string, error) {
+	f, err := d.getResolved(ctx, fileID, "name", "parents")
+	if err != nil {
+		return nil, nil, err
+	}
+	if !slices.Contains(pdfExportable, f.MimeType) {
+		return nil, nil, fmt.Errorf("%s is not a Google Doc, Sheet or Slides deck (mime: %s): only those can be exported as PDF", f.Name, f.MimeType)
+	}
+	out, err := d.DownloadFile(ctx, f.Id, "application/pdf")
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, f.Parents, nil
+}
+
+// SaveFile streams a file (or an export of a Google Workspace document) to a local path.
+// If path is an existing directory, the file is saved inside it under its Drive name. An existing file
+// is only replaced when overwrite is set. The content goes to a temporary file in the same directory,
+// renamed to path once complete, so a failed or interrupted download leaves path untouched.
+// It returns the metadata of the saved file, the path written and the number of bytes.
+func (d *DriveService) SaveFile(ctx context.Context, fileID string, exportMime string, path string, overwrite bool) (*DownloadedFile, string, int64, error) {
+	resp, out, err := d.openDownload(ctx, fileID, exportMime, 0)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		name := filepath.Base(out.Name)
+		if name == "." || name == ".." || name == string(filepath.Separator) {
+			return nil, "", 0, fmt.Errorf("%q cannot name a local file; give the file path to save to", out.Name)
+		}
+		path = filepath.Join(path, name)
+	}
+	if _, err := os.Lstat(path); err == nil && !overwrite {
+		return nil, "", 0, fmt.Errorf("%s already exists; set overwrite to replace it", path)
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("unable to create %s: %w", path, err)
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+	n, err := io.Copy(f, resp.Body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("unable to write %s: %w", path, err)
+	}
+	return out, path, n, nil
+}
+
 // CreateFolder creates a new folder.
-func (d *DriveService) CreateFolder(name string, parentID string) (*drive.File, error) {
+func (d *DriveService) CreateFolder(ctx context.Context, name string, parentID string) (*drive.File, error) {
 	f := &drive.File{
 		Name:     name,
-		MimeType: "application/vnd.google-apps.folder",
+		MimeType: FolderMimeType,
 	}
 	if parentID != "" {
 		f.Parents = []string{parentID}
 	}
 
-	file, err := d.srv.Files.Create(f).Fields("id", "name", "parents").Do()
+	file, err := d.srv.Files.Create(f).SupportsAllDrives(true).Fields("id", "name", "parents").Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to create folder: %w", err)
 	}
@@ -184,7 +752,7 @@ func (d *DriveService) CreateFolder(name string, parentID string) (*drive.File,
 }
 
 // CreateFile creates a new file with content.
-func (d *DriveService) CreateFile(name string, parentID string, content string, mimeType string) (*drive.File, error) {
+func (d *DriveService) CreateFile(ctx context.Context, name string, parentID string, content string, mimeType string) (*drive.File, error) {
 	f := &drive.File{
 		Name: name,
 	}
@@ -194,26 +762,67 @@ func (d *DriveService) CreateFile(name string, parentID string, content string,
 
 	media := strings.NewReader(content)
 
-	call := d.srv.Files.Create(f).Media(media)
+	call := d.srv.Files.Create(f).SupportsAllDrives(true).Media(media)
 	if mimeType != "" {
 		f.MimeType = mimeType
 	}
 
-	file, err := call.Fields("id", "name", "mimeType", "parents").Do()
+	file, err := call.Fields("id", "name", "mimeType", "parents").Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to create file: %w", err)
 	}
 	return file, nil
 }
 
+// resumableChunkSize is the chunk size for uploads; larger media is sent with a resumable upload.
+const resumableChunkSize = 8 * 1024 * 1024
+
+// DetectMimeType guesses a file's content type from its extension, falling back to sniffing its first bytes.
+func DetectMimeType(name string, head []byte) string {
+	if t := mime.TypeByExtension(filepath.Ext(name)); t != "" {
+		return t
+	}
+	return http.DetectContentType(head)
+}
+
+// UploadFile uploads binary content as a new file. Content larger than 8 MiB is sent in chunks using a
+// resumable upload; progress, if set, is called after each chunk with the bytes sent so far.
+// An empty mimeType is detected from the name and content.
+func (d *DriveService) UploadFile(ctx context.Context, name string, parentID string, mimeType string, content io.Reader, progress func(sent int64)) (*drive.File, error) {
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	br := bufio.NewReaderSize(content, 512)
+	if mimeType == "" {
+		head, _ := br.Peek(512)
+		mimeType = DetectMimeType(name, head)
+	}
+
+	f := &drive.File{Name: name, MimeType: mimeType}
+	if parentID != "" {
+		f.Parents = []string{parentID}
+	}
+	call := d.srv.Files.Create(f).
+		SupportsAllDrives(true).
+		Media(br, googleapi.ContentType(mimeType), googleapi.ChunkSize(resumableChunkSize))
+	if progress != nil {
+		call.ProgressUpdater(func(current, _ int64) { progress(current) })
+	}
+	file, err := call.Fields("id", "name", "mimeType", "parents", "size", "md5Checksum").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to upload file: %w", err)
+	}
+	return file, nil
+}
+
 // UpdateFile updates a file's name, parent, or content.
-func (d *DriveService) UpdateFile(fileID string, name string, addParents string, removeParents string, content *string) (*drive.File, error) {
+func (d *DriveService) UpdateFile(ctx context.Context, fileID string, name string, addParents string, removeParents string, content *string) (*drive.File, error) {
 	f := &drive.File{}
 	if name != "" {
 		f.Name = name
 	}
 
-	call := d.srv.Files.Update(fileID, f)
+	call := d.srv.Files.Update(fileID, f).SupportsAllDrives(true)
 
 	if addParents != "" {
 		call.AddParents(addParents)
@@ -227,38 +836,258 @@ func (d *DriveService) UpdateFile(fileID string, name string, addParents string,
 		call.Media(media)
 	}
 
-	file, err := call.Fields("id", "name", "mimeType", "parents").Do()
+	file, err := call.Fields("id", "name", "mimeType", "parents").Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to update file: %w", err)
 	}
 	return file, nil
 }
 
+// CopyFile copies a file, optionally with a new name and into another folder (including a shared drive folder).
+// Folders cannot be copied.
+func (d *DriveService) CopyFile(ctx context.Context, fileID string, name string, parentID string) (*drive.File, error) {
+	if fileID == "" {
+		return nil, fmt.Errorf("file_id is required")
+	}
+	f := &drive.File{Name: name}
+	if parentID != "" {
+		f.Parents = []string{parentID}
+	}
+	file, err := d.srv.Files.Copy(fileID, f).
+		SupportsAllDrives(true).
+		Fields("id", "name", "mimeType", "parents").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to copy file: %w", err)
+	}
+	return file, nil
+}
+
+// MoveFile moves a file or folder into newParentID, removing it from all its current parents.
+// Moves between My Drive and shared drives are supported for files; moving folders into a shared drive
+// requires organizer permissions and may be rejected by the API.
+func (d *DriveService) MoveFile(ctx context.Context, fileID string, newParentID string) (*drive.File, error) {
+	if fileID == "" || newParentID == "" {
+		return nil, fmt.Errorf("file_id and new_parent_id are required")
+	}
+	current, err := d.srv.Files.Get(fileID).SupportsAllDrives(true).Fields("parents").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get file metadata: %w", err)
+	}
+	call := d.srv.Files.Update(fileID, &drive.File{}).
+		SupportsAllDrives(true).
+		AddParents(newParentID)
+	if len(current.Parents) > 0 {
+		call.RemoveParents(strings.Join(current.Parents, ","))
+	}
+	file, err := call.Fields("id", "name", "mimeType", "parents", "driveId").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to move file: %w", err)
+	}
+	return file, nil
+}
+
 // DeleteFile deletes a file or folder (Trash).
 // Renaming to TrashFile for clarity, but standard Delete usually means trash in Drive UI unless 'delete' API is used.
 // API: Files.Delete permanently deletes. Files.Update(trashed=true) moves to trash.
 // We should probably implement explicit Trash and explicit Delete.
 // Current implementation uses Files.Delete which is PERMANENT. This is dangerous.
 // Recommendation: Change DeleteFile to TrashFile.
-func (d *DriveService) TrashFile(fileID string) error {
+func (d *DriveService) TrashFile(ctx context.Context, fileID string) error {
 	f := &drive.File{Trashed: true}
-	_, err := d.srv.Files.Update(fileID, f).Do()
+	_, err := d.srv.Files.Update(fileID, f).SupportsAllDrives(true).Context(ctx).Do()
 	return err
 }
 
-// AddPermission shares a file.
-func (d *DriveService) AddPermission(fileID string, role string, type_ string, emailAddress string) error {
+// ListTrash lists files and folders in the trash, most recently trashed first.
+func (d *DriveService) ListTrash(ctx context.Context, limit int64) ([]*drive.File, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	r, err := d.srv.Files.List().
+		Q("trashed = true").
+		SupportsAllDrives(true).
+		IncludeItemsFromAllDrives(true).
+		OrderBy("modifiedTime desc").
+		PageSize(limit).
+		Fields("files(id, name, mimeType, size, trashedTime, parents)").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list trash: %w", err)
+	}
+	return r.Files, nil
+}
+
+// RestoreFile moves a file or folder out of the trash, back to its original location.
+func (d *DriveService) RestoreFile(ctx context.Context, fileID string) (*drive.File, error) {
+	if fileID == "" {
+		return nil, fmt.Errorf("file_id is required")
+	}
+	f := &drive.File{Trashed: false, ForceSendFields: []string{"Trashed"}}
+	file, err := d.srv.Files.Update(fileID, f).SupportsAllDrives(true).Fields("id", "name", "parents").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to restore file: %w", err)
+	}
+	return file, nil
+}
+
+// DeletePermanently deletes a file or folder without going through the trash. This cannot be undone.
+func (d *DriveService) DeletePermanently(ctx context.Context, fileID string) error {
+	if fileID == "" {
+		return fmt.Errorf("file_id is required")
+	}
+	if err := d.srv.Files.Delete(fileID).SupportsAllDrives(true).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("unable to delete file: %w", err)
+	}
+	return nil
+}
+
+// EmptyTrash permanently deletes every file in the user's My Drive trash. This cannot be undone.
+func (d *DriveService) EmptyTrash(ctx context.Context) error {
+	if err := d.srv.Files.EmptyTrash().Context(ctx).Do(); err != nil {
+		return fmt.Errorf("unable to empty trash: %w", err)
+	}
+	return nil
+}
+
+// AddPermission shares a file. type_ is "user", "group", "domain" or "anyone" (anyone with the link);
+// target is the email address for users and groups, the domain name for domains, and empty for anyone.
+// expirationTime (RFC3339, optional) is only supported by Drive for user and group permissions.
+func (d *DriveService) AddPermission(ctx context.Context, fileID string, role string, type_ string, target string, expirationTime string) (*drive.Permission, error) {
 	perm := &drive.Permission{
-		Role:         role,
-		Type:         type_,
-		EmailAddress: emailAddress,
+		Role:           role,
+		Type:           type_,
+		ExpirationTime: expirationTime,
 	}
-	_, err := d.srv.Permissions.Create(fileID, perm).Do()
-	return err
+	switch type_ {
+	case "user", "group":
+		if target == "" {
+			return nil, fmt.Errorf("email is required to share with a %s", type_)
+		}
+		perm.EmailAddress = target
+	case "domain":
+		if target == "" {
+			return nil, fmt.Errorf("domain is required to share with a domain")
+		}
+		perm.Domain = target
+	case "anyone":
+		// Link sharing: anyone with the link, not discoverable in search.
+		perm.AllowFileDiscovery = false
+		perm.ForceSendFields = []string{"AllowFileDiscovery"}
+	default:
+		return nil, fmt.Errorf("invalid type %q (use user, group, domain or anyone)", type_)
+	}
+	if expirationTime != "" && type_ != "user" && type_ != "group" {
+		return nil, fmt.Errorf("expiration is only supported when sharing with a user or group")
+	}
+	p, err := d.srv.Permissions.Create(fileID, perm).SupportsAllDrives(true).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to share file: %w", err)
+	}
+	return p, nil
+}
+
+// ListPermissions lists who a file is shared with, including link sharing and inherited permissions.
+func (d *DriveService) ListPermissions(ctx context.Context, fileID string) ([]*drive.Permission, error) {
+	if fileID == "" {
+		return nil, fmt.Errorf("file_id is required")
+	}
+	var out []*drive.Permission
+	err := d.srv.Permissions.List(fileID).
+		SupportsAllDrives(true).
+		Fields("nextPageToken, permissions(id, type, role, emailAddress, domain, displayName, expirationTime, allowFileDiscovery, deleted, permissionDetails)").
+		Pages(ctx, func(page *drive.PermissionList) error {
+			out = append(out, page.Permissions...)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list permissions: %w", err)
+	}
+	return out, nil
+}
+
+// UpdatePermission changes the role and/or expiration of a permission.
+// An empty role keeps the current one; expirationTime "none" removes the expiration.
+func (d *DriveService) UpdatePermission(ctx context.Context, fileID string, permissionID string, role string, expirationTime string) (*drive.Permission, error) {
+	if fileID == "" || permissionID == "" {
+		return nil, fmt.Errorf("file_id and permission_id are required")
+	}
+	if role == "" && expirationTime == "" {
+		return nil, fmt.Errorf("role or expiration_time is required")
+	}
+	perm := &drive.Permission{Role: role}
+	switch expirationTime {
+	case "":
+	case "none":
+		perm.NullFields = []string{"ExpirationTime"}
+	default:
+		perm.ExpirationTime = expirationTime
+	}
+	p, err := d.srv.Permissions.Update(fileID, permissionID, perm).
+		SupportsAllDrives(true).
+		Fields("id, type, role, emailAddress, domain, expirationTime").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to update permission: %w", err)
+	}
+	return p, nil
+}
+
+// RemovePermission revokes a permission, e.g. to stop link sharing or unshare a user.
+func (d *DriveService) RemovePermission(ctx context.Context, fileID string, permissionID string) error {
+	if fileID == "" || permissionID == "" {
+		return fmt.Errorf("file_id and permission_id are required")
+	}
+	if err := d.srv.Permissions.Delete(fileID, permissionID).SupportsAllDrives(true).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("unable to remove permission: %w", err)
+	}
+	return nil
+}
+
+// About returns the account's user, storage quota and supported import/export formats.
+func (d *DriveService) About(ctx context.Context) (*drive.About, error) {
+	a, err := d.srv.About.Get().Fields("user(displayName, emailAddress)", "storageQuota", "importFormats", "exportFormats").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get account info: %w", err)
+	}
+	return a, nil
+}
+
+// FormatBytes renders a byte count with a binary unit, e.g. "1.5 GiB".
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// ListSharedDrives lists the shared drives the user is a member of.
+func (d *DriveService) ListSharedDrives(ctx context.Context, limit int64) ([]*drive.Drive, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	r, err := d.srv.Drives.List().
+		PageSize(min(limit, 100)).
+		Fields("drives(id, name, createdTime)").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list shared drives: %w", err)
+	}
+	return r.Drives, nil
 }
 
 // ListComments lists comments on a Drive file (e.g. Doc, Sheet).
-func (d *DriveService) ListComments(fileID string, pageSize int64) ([]*drive.Comment, error) {
+func (d *DriveService) ListComments(ctx context.Context, fileID string, pageSize int64) ([]*drive.Comment, error) {
 	if fileID == "" {
 		return nil, fmt.Errorf("file_id is required")
 	}
@@ -268,7 +1097,7 @@ func (d *DriveService) ListComments(fileID string, pageSize int64) ([]*drive.Com
 	if pageSize > 100 {
 		pageSize = 100
 	}
-	resp, err := d.srv.Comments.List(fileID).PageSize(pageSize).Fields("comments(id,content,createdTime,author,resolved)").Do()
+	resp, err := d.srv.Comments.List(fileID).PageSize(pageSize).Fields("comments(id,content,createdTime,author,resolved,quotedFileContent,anchor,replies(id,content,createdTime,author,action))").Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to list comments: %w", err)
 	}
@@ -276,7 +1105,7 @@ func (d *DriveService) ListComments(fileID string, pageSize int64) ([]*drive.Com
 }
 
 // CreateComment adds a comment to a Drive file.
-func (d *DriveService) CreateComment(fileID string, content string) (*drive.Comment, error) {
+func (d *DriveService) CreateComment(ctx context.Context, fileID string, content string) (*drive.Comment, error) {
 	if fileID == "" {
 		return nil, fmt.Errorf("file_id is required")
 	}
@@ -284,9 +1113,29 @@ func (d *DriveService) CreateComment(fileID string, content string) (*drive.Comm
 		return nil, fmt.Errorf("content is required")
 	}
 	comment := &drive.Comment{Content: content}
-	c, err := d.srv.Comments.Create(fileID, comment).Do()
+	c, err := d.srv.Comments.Create(fileID, comment).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to create comment: %w", err)
 	}
 	return c, nil
 }
+
+// ReplyToComment adds a reply to a comment. With action "resolve" or "reopen" the reply also changes the
+// comment's state; content may then be empty.
+func (d *DriveService) ReplyToComment(ctx context.Context, fileID string, commentID string, content string, action string) (*drive.Reply, error) {
+	if fileID == "" || commentID == "" {
+		return nil, fmt.Errorf("file_id and comment_id are required")
+	}
+	if action != "" && action != "resolve" && action != "reopen" {
+		return nil, fmt.Errorf("invalid action %q (use resolve or reopen)", action)
+	}
+	if content == "" && action == "" {
+		return nil, fmt.Errorf("content is required")
+	}
+	reply := &drive.Reply{Content: content, Action: action}
+	r, err := d.srv.Replies.Create(fileID, commentID, reply).Fields("id", "content", "action", "createdTime").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to reply to comment: %w", err)
+	}
+	return r, nil
+}
@@ -0,0 +1,199 @@
+package drive
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/option"
+)
+
+func TestDetectMimeType(t *testing.T) {
+	tests := []struct {
+		name string
+		head []byte
+		want string
+	}{
+		{name: "report.pdf", head: nil, want: "application/pdf"},
+		{name: "photo.PNG", head: nil, want: "image/png"},
+		{name: "no-extension", head: []byte("%PDF-1.7\n"), want: "application/pdf"},
+		{name: "blob", head: []byte{0x00, 0x01, 0x02}, want: "application/octet-stream"},
+		{name: "notes", head: []byte("plain words"), want: "text/plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectMimeType(tt.name, tt.head); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestExportMimeType(t *testing.T) {
+	if got, err := ExportMimeType("XLSX"); err != nil || got != "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet" {
+		t.Errorf("unexpected xlsx mime: %q (%v)", got, err)
+	}
+	if got, _ := ExportMimeType("text/csv"); got != "text/csv" {
+		t.Errorf("expected mime types to pass through, got %q", got)
+	}
+	if _, err := ExportMimeType("rtf2"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestExportedName(t *testing.T) {
+	tests := []struct{ name, mime, want string }{
+		{"Budget", "application/pdf", "Budget.pdf"},
+		{"Budget.PDF", "application/pdf", "Budget.PDF"},
+		{"Budget", "text/csv", "Budget.csv"},
+		{"Budget", "application/zip", "Budget"},
+	}
+	for _, tt := range tests {
+		if got := exportedName(tt.name, tt.mime); got != tt.want {
+			t.Errorf("exportedName(%q, %q): expected %q, got %q", tt.name, tt.mime, tt.want, got)
+		}
+	}
+}
+
+func TestTrimToRuneBoundary(t *testing.T) {
+	s := []byte("olá") // "á" is two bytes
+	if got := string(trimToRuneBoundary(s)); got != "olá" {
+		t.Errorf("expected complete text to be kept, got %q", got)
+	}
+	if got := string(trimToRuneBoundary(s[:len(s)-1])); got != "ol" {
+		t.Errorf("expected split character to be dropped, got %q", got)
+	}
+	if got := trimToRuneBoundary(nil); len(got) != 0 {
+		t.Errorf("expected empty result, got %q", got)
+	}
+}
+
+func TestIsTextual(t *testing.T) {
+	for mime, want := range map[string]bool{
+		"text/plain":                               true,
+		"text/markdown":                            true,
+		"application/vnd.google-apps.document":     true,
+		"application/json":                         true,
+		"application/pdf":                          false,
+		"image/png":                                false,
+		"application/vnd.google-apps.folder":       false,
+		"application/vnd.google-apps.presentation": true,
+	} {
+		if got := IsTextual(mime); got != want {
+			t.Errorf("IsTextual(%q): expected %v, got %v", mime, want, got)
+		}
+	}
+}
+
+func TestTextMimeType(t *testing.T) {
+	for mime, want := range map[string]string{
+		"application/vnd.google-apps.spreadsheet":  "text/csv",
+		"application/vnd.google-apps.document":     "text/plain",
+		"application/vnd.google-apps.presentation": "text/plain",
+		"text/markdown": "text/markdown",
+	} {
+		if got := textMimeType(mime); got != want {
+			t.Errorf("textMimeType(%q): expected %q, got %q", mime, want, got)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for n, want := range map[int64]string{
+		0:                       "0 B",
+		1023:                    "1023 B",
+		1536:                    "1.5 KiB",
+		15 * 1024 * 1024 * 1024: "15.0 GiB",
+	} {
+		if got := FormatBytes(n); got != want {
+			t.Errorf("FormatBytes(%d): expected %q, got %q", n, want, got)
+		}
+	}
+}
+
+// fakeDrive serves the metadata and content of a stored file ("bin", 10 bytes) and a Google Doc ("doc",
+// exported as 10 bytes).
+func fakeDrive(t *testing.T) *DriveService {
+	t.Helper()
+	content := "0123456789"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/files/doc/export":
+			_, _ = io.WriteString(w, content)
+		case r.URL.Query().Get("alt") == "media":
+			_, _ = io.WriteString(w, content)
+		case r.URL.Path == "/files/bin":
+			_, _ = io.WriteString(w, `{"id": "bin", "name": "data.bin", "mimeType": "application/octet-stream", "size": "10"}`)
+		case r.URL.Path == "/files/doc":
+			_, _ = io.WriteString(w, `{"id": "doc", "name": "Notes", "mimeType": "application/vnd.google-apps.document"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	d, err := New(context.Background(), option.WithEndpoint(srv.URL+"/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestDownloadFileLimit(t *testing.T) {
+	d := fakeDrive(t)
+	tests := []struct {
+		fileID  string
+		limit   int64
+		wantErr bool
+	}{
+		{fileID: "bin", limit: 0},
+		{fileID: "bin", limit: 10},
+		{fileID: "bin", limit: 9, wantErr: true},
+		{fileID: "doc", limit: 10},
+		{fileID: "doc", limit: 9, wantErr: true},
+	}
+	for _, tt := range tests {
+		f, err := d.DownloadFileLimit(context.Background(), tt.fileID, "", tt.limit)
+		if tt.wantErr {
+			if !errors.Is(err, ErrFileTooLarge) {
+				t.Errorf("DownloadFileLimit(%s, %d) error = %v, want ErrFileTooLarge", tt.fileID, tt.limit, err)
+			}
+			continue
+		}
+		if err != nil || string(f.Data) != "0123456789" {
+			t.Errorf("DownloadFileLimit(%s, %d) = %v, %v", tt.fileID, tt.limit, f, err)
+		}
+	}
+}
+
+func TestSaveFile(t *testing.T) {
+	d := fakeDrive(t)
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "data.bin")
+	if err := os.WriteFile(existing, []byte("keep"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, _, err := d.SaveFile(context.Background(), "bin", "", dir, false); err == nil {
+		t.Error("SaveFile() replaced an existing file without overwrite")
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "keep" {
+		t.Errorf("existing file changed to %q", data)
+	}
+	_, path, n, err := d.SaveFile(context.Background(), "bin", "", dir, true)
+	if err != nil || path != existing || n != 10 {
+		t.Fatalf("SaveFile() with overwrite = %s, %d, %v", path, n, err)
+	}
+	if _, _, _, err := d.SaveFile(context.Background(), "missing", "", filepath.Join(dir, "new.bin"), false); err == nil {
+		t.Error("SaveFile() of a missing file succeeded")
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("files left in %s: %v, want only data.bin", dir, entries)
+	}
+}
@@ -0,0 +1,74 @@
+package drive
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Query describes a Drive file search. Fields are combined with "and"; empty fields are ignored.
+type Query struct {
+	Raw            string // Raw Drive query syntax, wrapped in parentheses as-is
+	NameContains   string
+	FullText       string
+	MimeType       string
+	ModifiedAfter  string // RFC3339 or YYYY-MM-DD
+	ModifiedBefore string // RFC3339 or YYYY-MM-DD
+	Owner          string // Owner email address, or "me"
+	FolderID       string // Only direct children of this folder
+	Starred        bool
+}
+
+// Build returns the Drive query string, validating dates and escaping every value.
+func (q Query) Build() (string, error) {
+	var parts []string
+	if q.Raw != "" {
+		parts = append(parts, "("+q.Raw+")")
+	}
+	if q.NameContains != "" {
+		parts = append(parts, fmt.Sprintf("name contains %s", quoteQueryValue(q.NameContains)))
+	}
+	if q.FullText != "" {
+		parts = append(parts, fmt.Sprintf("fullText contains %s", quoteQueryValue(q.FullText)))
+	}
+	if q.MimeType != "" {
+		parts = append(parts, fmt.Sprintf("mimeType = %s", quoteQueryValue(q.MimeType)))
+	}
+	for _, c := range []struct{ value, op, arg string }{
+		{q.ModifiedAfter, ">", "modified_after"},
+		{q.ModifiedBefore, "<", "modified_before"},
+	} {
+		if c.value == "" {
+			continue
+		}
+		t, err := parseQueryTime(c.value)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s %q: use RFC3339 or YYYY-MM-DD", c.arg, c.value)
+		}
+		parts = append(parts, fmt.Sprintf("modifiedTime %s '%s'", c.op, t.UTC().Format(time.RFC3339)))
+	}
+	if q.Owner != "" {
+		parts = append(parts, fmt.Sprintf("%s in owners", quoteQueryValue(q.Owner)))
+	}
+	if q.FolderID != "" {
+		parts = append(parts, fmt.Sprintf("%s in parents", quoteQueryValue(q.FolderID)))
+	}
+	if q.Starred {
+		parts = append(parts, "starred = true")
+	}
+	return strings.Join(parts, " and "), nil
+}
+
+// quoteQueryValue wraps a value in single quotes for a Drive query, escaping \ and '.
+func quoteQueryValue(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `'`, `\'`)
+	return "'" + s + "'"
+}
+
+func parseQueryTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
@@ -0,0 +1,54 @@
+package drive
+
+import "testing"
+
+func TestQueryBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		q       Query
+		want    string
+		wantErr bool
+	}{
+		{name: "empty", q: Query{}, want: ""},
+		{
+			name: "escapes quotes and backslashes",
+			q:    Query{NameContains: `Bob's \ notes`},
+			want: `name contains 'Bob\'s \\ notes'`,
+		},
+		{
+			name: "combined filters",
+			q: Query{
+				Raw:           "mimeType != 'application/pdf'",
+				FullText:      "budget",
+				ModifiedAfter: "2025-01-31",
+				Owner:         "me",
+				FolderID:      "folder1",
+				Starred:       true,
+			},
+			want: "(mimeType != 'application/pdf') and fullText contains 'budget' and modifiedTime > '2025-01-31T00:00:00Z' and 'me' in owners and 'folder1' in parents and starred = true",
+		},
+		{
+			name: "rfc3339 dates normalized to UTC",
+			q:    Query{ModifiedBefore: "2025-02-01T10:00:00-03:00"},
+			want: "modifiedTime < '2025-02-01T13:00:00Z'",
+		},
+		{name: "invalid date", q: Query{ModifiedAfter: "last week"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.q.Build()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package drive
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// RecentFiles lists the most recently modified non-trashed files of a mime type, newest first.
+func (d *DriveService) RecentFiles(ctx context.Context, mimeType string, limit int64) ([]*drive.File, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	r, err := d.srv.Files.List().
+		Q(fmt.Sprintf("mimeType = %s and trashed = false", quoteQueryValue(mimeType))).
+		OrderBy("modifiedTime desc").
+		SupportsAllDrives(true).
+		IncludeItemsFromAllDrives(true).
+		PageSize(limit).
+		Fields("files(id, name, mimeType, modifiedTime)").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list recent files: %w", err)
+	}
+	return r.Files, nil
+}
+
+// ResourceContent is a Drive file read as an MCP resource: Text is set for textual files
+// (Google Workspace documents are exported), Data for everything else.
+type ResourceContent struct {
+	Name     string
+	MimeType string // Mime type of Text or Data, i.e. the export format for Google Workspace documents
+	Text     string
+	Data     []byte
+}
+
+// textMimeType returns the mime type of the text readFileContent returns for a textual file.
+func textMimeType(mimeType string) string {
+	switch mimeType {
+	case "application/vnd.google-apps.spreadsheet":
+		return "text/csv"
+	case "application/vnd.google-apps.document", "application/vnd.google-apps.presentation":
+		return "text/plain"
+	}
+	return mimeType
+}
+
+// ReadResource reads a file's content for an MCP resource. Text is truncated to maxBytes;
+// binary files larger than maxBytes are rejected rather than loaded into memory.
+func (d *DriveService) ReadResource(ctx context.Context, fileID string, maxBytes int64) (*ResourceContent, error) {
+	f, err := d.getResolved(ctx, fileID, "name", "size")
+	if err != nil {
+		return nil, err
+	}
+	out := &ResourceContent{Name: f.Name, MimeType: f.MimeType}
+	if IsTextual(f.MimeType) {
+		out.MimeType = textMimeType(f.MimeType)
+		out.Text, err = d.readFileContent(ctx, f.Id, f.MimeType, maxBytes)
+		if err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+	if f.Size > maxBytes {
+		return nil, fmt.Errorf("%s is %s, more than the %s resource limit; download it with drive_download_file instead", f.Name, FormatBytes(f.Size), FormatBytes(maxBytes))
+	}
+	file, err := d.DownloadFile(ctx, f.Id, "")
+	if err != nil {
+		return nil, err
+	}
+	out.MimeType, out.Data = file.MimeType, file.Data
+	return out, nil
+}
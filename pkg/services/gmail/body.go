@@ -0,0 +1,228 @@
+package gmail
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"google.golang.org/api/gmail/v1"
+)
+
+// ExtractMessageText returns the readable body of a message.
+// It prefers the text/plain part and falls back to the text/html part converted to plain text.
+// With stripQuoted, quoted reply history ("On ... wrote:", "> " lines, Gmail/Outlook quote blocks) is removed.
+func ExtractMessageText(payload *gmail.MessagePart, stripQuoted bool) string {
+	if payload == nil {
+		return ""
+	}
+	if text := findBodyPart(payload, "text/plain"); text != "" {
+		if stripQuoted {
+			text = StripQuotedText(text)
+		}
+		return strings.TrimSpace(text)
+	}
+	if h := findBodyPart(payload, "text/html"); h != "" {
+		text := HTMLToText(h, stripQuoted)
+		if stripQuoted {
+			text = StripQuotedText(text)
+		}
+		return text
+	}
+	return rawBodyData(payload)
+}
+
+// rawBodyData concatenates the decoded data of every part, for messages with no text/plain or text/html part.
+func rawBodyData(payload *gmail.MessagePart) string {
+	if payload == nil {
+		return ""
+	}
+	var body string
+	if payload.Body != nil && payload.Body.Data != "" {
+		data, _ := base64.URLEncoding.DecodeString(payload.Body.Data)
+		body += string(data)
+	}
+	for _, part := range payload.Parts {
+		body += rawBodyData(part)
+	}
+	return body
+}
+
+// lineElements start a new line when converting HTML to text; paragraphElements are also set off by a blank line.
+var (
+	lineElements = map[string]bool{
+		"br": true, "div": true, "li": true, "tr": true, "hr": true, "pre": true, "section": true, "article": true,
+	}
+	paragraphElements = map[string]bool{
+		"p": true, "ul": true, "ol": true, "table": true, "blockquote": true,
+		"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	}
+)
+
+// skippedElements have no readable content.
+var skippedElements = map[string]bool{"script": true, "style": true, "head": true, "title": true}
+
+// HTMLToText converts an HTML email body into plain text, keeping line structure and link targets.
+// With skipQuotes, <blockquote> elements and Gmail/Outlook quote containers are dropped.
+func HTMLToText(s string, skipQuotes bool) string {
+	root, err := html.Parse(strings.NewReader(s))
+	if err != nil {
+		return s
+	}
+	var b strings.Builder
+	// breakLines makes sure the output ends with at least n newlines.
+	breakLines := func(n int) {
+		out := b.String()
+		have := len(out) - len(strings.TrimRight(out, "\n"))
+		for ; have < n; have++ {
+			b.WriteString("\n")
+		}
+	}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if skippedElements[n.Data] || (skipQuotes && isQuoteNode(n)) {
+				return
+			}
+			switch {
+			case paragraphElements[n.Data]:
+				breakLines(2)
+			case lineElements[n.Data]:
+				breakLines(1)
+			}
+			if n.Data == "li" {
+				b.WriteString("- ")
+			}
+		}
+		if n.Type == html.TextNode {
+			b.WriteString(collapseSpaces(n.Data))
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if n.Type == html.ElementNode {
+			if n.Data == "a" {
+				if href := attr(n, "href"); strings.HasPrefix(href, "http") && !strings.Contains(textOf(n), href) {
+					b.WriteString(" (" + href + ")")
+				}
+			}
+			switch {
+			case paragraphElements[n.Data]:
+				breakLines(2)
+			case lineElements[n.Data]:
+				breakLines(1)
+			}
+		}
+	}
+	walk(root)
+	return tidyLines(b.String())
+}
+
+// isQuoteNode reports whether n holds quoted reply history.
+func isQuoteNode(n *html.Node) bool {
+	if n.Data == "blockquote" {
+		return true
+	}
+	class := attr(n, "class")
+	id := attr(n, "id")
+	return strings.Contains(class, "gmail_quote") || strings.Contains(class, "yahoo_quoted") ||
+		id == "divRplyFwdMsg" || id == "appendonsend"
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func textOf(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+var spaceRun = regexp.MustCompile(`[ \t\r\n\f\x{00a0}]+`)
+
+func collapseSpaces(s string) string {
+	return spaceRun.ReplaceAllString(s, " ")
+}
+
+// tidyLines trims each line and collapses runs of blank lines to a single blank line.
+func tidyLines(s string) string {
+	var out []string
+	blank := false
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if !blank && len(out) > 0 {
+				out = append(out, "")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+		out = append(out, line)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+// quoteHeader matches lines that introduce quoted reply history.
+var quoteHeader = regexp.MustCompile(`(?i)^(on .+wrote:|on .+,$|-+ ?original message ?-+|_{20,}|from: .+|em .+escreveu:|le .+a écrit :|am .+schrieb .+:)$`)
+
+// StripQuotedText removes quoted reply history from a plain-text body.
+// Everything from the first reply header ("On ... wrote:", "-----Original Message-----", an Outlook
+// "From:" block) onwards is dropped, as are lines starting with ">".
+func StripQuotedText(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	var out []string
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if quoteHeader.MatchString(trimmed) {
+			// "On <date>," may wrap onto a second line ending in "wrote:".
+			if strings.HasSuffix(trimmed, ",") && !strings.HasPrefix(strings.ToLower(trimmed), "from:") {
+				if i+1 < len(lines) && strings.HasSuffix(strings.TrimSpace(lines[i+1]), "wrote:") {
+					break
+				}
+				out = append(out, line)
+				continue
+			}
+			if strings.HasPrefix(strings.ToLower(trimmed), "from:") && !looksLikeForwardBlock(lines[i:]) {
+				out = append(out, line)
+				continue
+			}
+			break
+		}
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+		out = append(out, line)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+// looksLikeForwardBlock reports whether lines start an Outlook-style header block (From:, then Sent:/Date: and To: or Subject:).
+func looksLikeForwardBlock(lines []string) bool {
+	seen := 0
+	for _, l := range lines[1:min(len(lines), 5)] {
+		lower := strings.ToLower(strings.TrimSpace(l))
+		for _, prefix := range []string{"sent:", "date:", "to:", "subject:", "cc:"} {
+			if strings.HasPrefix(lower, prefix) {
+				seen++
+				break
+			}
+		}
+	}
+	return seen >= 2
+}
@@ -0,0 +1,110 @@
+package gmail
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		name       string
+		html       string
+		skipQuotes bool
+		want       string
+	}{
+		{
+			name: "paragraphs, lists and links",
+			html: `<html><head><style>p{color:red}</style></head><body>` +
+				`<p>Hi&nbsp;team,</p><ul><li>First</li><li>Second</li></ul>` +
+				`<p>See <a href="https://example.com/doc">the doc</a>.</p></body></html>`,
+			want: "Hi team,\n\n- First\n- Second\n\nSee the doc (https://example.com/doc).",
+		},
+		{
+			name:       "gmail quote dropped",
+			html:       `<div>Sounds good.</div><div class="gmail_quote">On Mon, Bob wrote:<blockquote>Old text</blockquote></div>`,
+			skipQuotes: true,
+			want:       "Sounds good.",
+		},
+		{
+			name: "gmail quote kept",
+			html: `<div>Sounds good.</div><blockquote>Old text</blockquote>`,
+			want: "Sounds good.\n\nOld text",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HTMLToText(tt.html, tt.skipQuotes); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestStripQuotedText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "on wrote header",
+			text: "Thanks, will do.\n\nOn Mon, Jan 6, 2025 at 10:00 AM Bob <bob@example.com> wrote:\n> Can you send it?\n",
+			want: "Thanks, will do.",
+		},
+		{
+			name: "wrapped on wrote header",
+			text: "Yes.\n\nOn Mon, Jan 6, 2025 at 10:00 AM,\nBob <bob@example.com> wrote:\n> Ready?",
+			want: "Yes.",
+		},
+		{
+			name: "outlook original message",
+			text: "Approved.\r\n\r\n-----Original Message-----\r\nFrom: Bob\r\nSent: Monday\r\n",
+			want: "Approved.",
+		},
+		{
+			name: "outlook header block",
+			text: "Approved.\n\nFrom: Bob <bob@example.com>\nSent: Monday, January 6, 2025\nTo: Alice\nSubject: Budget\n\nOld body",
+			want: "Approved.",
+		},
+		{
+			name: "inline from line is kept",
+			text: "From: the finance team's perspective this works.\nCheers",
+			want: "From: the finance team's perspective this works.\nCheers",
+		},
+		{
+			name: "interleaved quotes dropped",
+			text: "> Question one?\nAnswer one.\n> Question two?\nAnswer two.",
+			want: "Answer one.\nAnswer two.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripQuotedText(tt.text); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestExtractMessageTextPrefersPlain(t *testing.T) {
+	enc := func(s string) *gmail.MessagePartBody {
+		return &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte(s))}
+	}
+	alternative := &gmail.MessagePart{
+		MimeType: "multipart/alternative",
+		Parts: []*gmail.MessagePart{
+			{MimeType: "text/plain", Body: enc("Plain body")},
+			{MimeType: "text/html", Body: enc("<p>HTML body</p>")},
+		},
+	}
+	if got := ExtractMessageText(alternative, false); got != "Plain body" {
+		t.Errorf("expected plain part, got %q", got)
+	}
+
+	htmlOnly := &gmail.MessagePart{MimeType: "text/html", Body: enc("<p>Only <b>HTML</b></p>")}
+	if got := ExtractMessageText(htmlOnly, false); got != "Only HTML" {
+		t.Errorf("expected converted HTML, got %q", got)
+	}
+}
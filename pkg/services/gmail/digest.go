@@ -0,0 +1,73 @@
+package gmail
+
+import (
+	"strings"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+// digestSummaryRunes caps the summary taken from a message's text, keeping task notes short.
+const digestSummaryRunes = 500
+
+// ThreadDigest is a short description of a thread, for handing it off to another service (a task, a
+// calendar event) with a way back to it.
+type ThreadDigest struct {
+	Subject string
+	From    string // Sender of the latest message
+	Date    string // Date of the latest message
+	Summary string
+	Link    string // Gmail web address of the thread
+}
+
+// ThreadLink returns the Gmail web address of a thread.
+func ThreadLink(threadID string) string {
+	return "https://mail.google.com/mail/#all/" + threadID
+}
+
+// DigestThread describes a thread fetched with its full payload. When summary is empty, the start of the
+// latest message's text, without quoted history, is used.
+func DigestThread(t *gmail.Thread, summary string) ThreadDigest {
+	d := ThreadDigest{Summary: strings.TrimSpace(summary), Link: ThreadLink(t.Id)}
+	if len(t.Messages) == 0 {
+		return d
+	}
+	d.Subject = ThreadSubject(t)
+	last := t.Messages[len(t.Messages)-1]
+	if last.Payload != nil {
+		d.From = decodeHeader(GetHeader(last.Payload.Headers, "From"))
+		d.Date = GetHeader(last.Payload.Headers, "Date")
+		if d.Summary == "" {
+			d.Summary = ExtractMessageText(last.Payload, true)
+		}
+	}
+	if d.Summary == "" {
+		d.Summary = last.Snippet
+	}
+	d.Summary = truncateRunes(strings.Join(strings.Fields(d.Summary), " "), digestSummaryRunes)
+	return d
+}
+
+// Notes renders the digest as plain text notes: sender and date, the summary and the link to the thread.
+func (d ThreadDigest) Notes() string {
+	var b strings.Builder
+	if d.From != "" {
+		b.WriteString("From: " + d.From + "\n")
+	}
+	if d.Date != "" {
+		b.WriteString("Date: " + d.Date + "\n")
+	}
+	if d.Summary != "" {
+		b.WriteString("\n" + d.Summary + "\n")
+	}
+	b.WriteString("\nThread: " + d.Link)
+	return strings.TrimPrefix(b.String(), "\n")
+}
+
+// truncateRunes shortens s to at most n runes, marking the cut with an ellipsis.
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return strings.TrimSpace(string(r[:n-1])) + "…"
+}
@@ -0,0 +1,116 @@
+package gmail
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+// exportedMessage is a message of an exported thread.
+type exportedMessage struct {
+	From, To, Cc, Date string
+	Body               string
+	Attachments        []AttachmentInfo
+}
+
+// exportMessages extracts what an export shows of each message of a thread fetched with its full
+// payload. Quoted history is stripped from the bodies, since the earlier messages are exported too.
+func exportMessages(t *gmail.Thread) []exportedMessage {
+	var out []exportedMessage
+	for _, m := range t.Messages {
+		if m.Payload == nil {
+			continue
+		}
+		h := m.Payload.Headers
+		out = append(out, exportedMessage{
+			From:        decodeHeader(GetHeader(h, "From")),
+			To:          decodeHeader(GetHeader(h, "To")),
+			Cc:          decodeHeader(GetHeader(h, "Cc")),
+			Date:        GetHeader(h, "Date"),
+			Body:        strings.TrimSpace(ExtractMessageText(m.Payload, true)),
+			Attachments: MessageAttachments(m),
+		})
+	}
+	return out
+}
+
+// ThreadSubject returns the subject of a thread, from its first message.
+func ThreadSubject(t *gmail.Thread) string {
+	if len(t.Messages) == 0 || t.Messages[0].Payload == nil {
+		return ""
+	}
+	return decodeHeader(GetHeader(t.Messages[0].Payload.Headers, "Subject"))
+}
+
+// ThreadMarkdown renders a thread fetched with its full payload as Markdown, for a Google Doc: the subject
+// and a link to the thread, then each message's headers, body and attachment names. Bodies are escaped, so
+// that their text is never taken for Markdown formatting.
+func ThreadMarkdown(t *gmail.Thread) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\nThread: [%s](%s)\n", escapeMarkdown(subjectOrDefault(t)), ThreadLink(t.Id), ThreadLink(t.Id))
+	for i, m := range exportMessages(t) {
+		fmt.Fprintf(&b, "\n## %d. %s\n\n", i+1, escapeMarkdown(m.From))
+		fmt.Fprintf(&b, "**Date:** %s\n\n**To:** %s\n\n", escapeMarkdown(m.Date), escapeMarkdown(m.To))
+		if m.Cc != "" {
+			fmt.Fprintf(&b, "**Cc:** %s\n\n", escapeMarkdown(m.Cc))
+		}
+		for _, line := range strings.Split(m.Body, "\n") {
+			b.WriteString(escapeMarkdown(line) + "\n")
+		}
+		if len(m.Attachments) > 0 {
+			b.WriteString("\n**Attachments:**\n\n")
+			for _, a := range m.Attachments {
+				fmt.Fprintf(&b, "- %s\n", escapeMarkdown(attachmentLabel(a)))
+			}
+		}
+	}
+	return b.String()
+}
+
+// ThreadText renders a thread fetched with its full payload as plain text, with the same content as
+// ThreadMarkdown.
+func ThreadText(t *gmail.Thread) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\nThread: %s\n", subjectOrDefault(t), ThreadLink(t.Id))
+	for i, m := range exportMessages(t) {
+		fmt.Fprintf(&b, "\n%s\n%d. From: %s\nDate: %s\nTo: %s\n", strings.Repeat("=", 72), i+1, m.From, m.Date, m.To)
+		if m.Cc != "" {
+			fmt.Fprintf(&b, "Cc: %s\n", m.Cc)
+		}
+		fmt.Fprintf(&b, "\n%s\n", m.Body)
+		if len(m.Attachments) > 0 {
+			b.WriteString("\nAttachments:\n")
+			for _, a := range m.Attachments {
+				fmt.Fprintf(&b, "  - %s\n", attachmentLabel(a))
+			}
+		}
+	}
+	return b.String()
+}
+
+func subjectOrDefault(t *gmail.Thread) string {
+	if s := ThreadSubject(t); s != "" {
+		return s
+	}
+	return "(no subject)"
+}
+
+func attachmentLabel(a AttachmentInfo) string {
+	return fmt.Sprintf("%s (%s, %d bytes)", a.Filename, a.MimeType, a.Size)
+}
+
+// markdownSpecial are the characters escaped with a backslash by escapeMarkdown.
+const markdownSpecial = "\\`*_[]()#+-.!|>"
+
+// escapeMarkdown backslash-escapes the characters Markdown could interpret as formatting.
+func escapeMarkdown(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(markdownSpecial, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,143 @@
+package gmail
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+// MessageIDColumn is the column ExtractMessage puts the message ID in, used to skip messages that were
+// already extracted.
+const MessageIDColumn = "Message ID"
+
+// ExtractField is a value captured from each message by a regular expression.
+type ExtractField struct {
+	Name    string
+	Pattern *regexp.Regexp // The first capturing group is the value; without groups, the whole match
+}
+
+// ParseExtractFields parses a JSON object mapping column names to regular expressions, e.g.
+// {"Amount": "Total:\\s*\\$?([\\d.,]+)", "Order": "(?i)order #(\\w+)"}, keeping the order of the columns.
+func ParseExtractFields(text string) ([]ExtractField, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader([]byte(text)))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("fields must be a JSON object of column names to regular expressions")
+	}
+	var fields []ExtractField
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse fields: %w", err)
+		}
+		name := strings.TrimSpace(tok.(string))
+		var pattern string
+		if err := dec.Decode(&pattern); err != nil {
+			return nil, fmt.Errorf("field %q: the pattern must be a string", name)
+		}
+		if name == "" {
+			return nil, fmt.Errorf("field names must not be empty")
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", name, err)
+		}
+		for _, c := range ExtractColumns(fields) {
+			if strings.EqualFold(c, name) {
+				return nil, fmt.Errorf("duplicate column %q", name)
+			}
+		}
+		fields = append(fields, ExtractField{Name: name, Pattern: re})
+	}
+	return fields, nil
+}
+
+// ExtractColumns returns the columns of ExtractMessage's values: the message's date, sender and subject,
+// one per field, then a link to the thread and the message ID.
+func ExtractColumns(fields []ExtractField) []string {
+	columns := []string{"Date", "From", "Subject"}
+	for _, f := range fields {
+		columns = append(columns, f.Name)
+	}
+	return append(columns, "Link", MessageIDColumn)
+}
+
+// ExtractMessage returns the values of ExtractColumns for a message fetched with its full payload, with
+// the date in loc, and how many fields matched. Fields are searched in the subject and the body, quoted
+// text included, so that forwarded receipts are matched too. Captured numbers such as "1,234.50" become
+// numbers; other values are kept as text.
+func ExtractMessage(m *gmail.Message, fields []ExtractField, loc *time.Location) ([]interface{}, int) {
+	var from, subject string
+	if m.Payload != nil {
+		from = decodeHeader(GetHeader(m.Payload.Headers, "From"))
+		subject = decodeHeader(GetHeader(m.Payload.Headers, "Subject"))
+	}
+	text := subject + "\n" + ExtractMessageText(m.Payload, false)
+
+	values := []interface{}{time.UnixMilli(m.InternalDate).In(loc).Format("2006-01-02 15:04"), from, subject}
+	matched := 0
+	for _, f := range fields {
+		v := ""
+		if sm := f.Pattern.FindStringSubmatch(text); sm != nil {
+			v = sm[0]
+			if len(sm) > 1 {
+				v = sm[1]
+			}
+			matched++
+		}
+		values = append(values, extractedValue(strings.TrimSpace(v)))
+	}
+	return append(values, ThreadLink(m.ThreadId), m.Id), matched
+}
+
+// numberPattern matches plain numbers and numbers with comma thousands separators. Other notations, such
+// as "1.234,50", are ambiguous and kept as text.
+var numberPattern = regexp.MustCompile(`^-?(\d{1,3}(,\d{3})+|\d+)(\.\d+)?$`)
+
+// extractedValue returns a captured value as a number when it is one, so that amounts can be summed.
+func extractedValue(v string) interface{} {
+	if !numberPattern.MatchString(v) {
+		return v
+	}
+	n, err := strconv.ParseFloat(strings.ReplaceAll(v, ",", ""), 64)
+	if err != nil {
+		return v
+	}
+	return n
+}
+
+// ListMessageIDs returns the IDs of up to limit messages matching a Gmail query, newest first.
+func (g *GmailService) ListMessageIDs(ctx context.Context, query string, limit int) ([]string, error) {
+	var ids []string
+	pageToken := ""
+	for len(ids) < limit {
+		call := g.srv.Users.Messages.List("me").MaxResults(int64(min(limit-len(ids), 500))).Fields("nextPageToken,messages(id)")
+		if query != "" {
+			call.Q(query)
+		}
+		if pageToken != "" {
+			call.PageToken(pageToken)
+		}
+		r, err := call.Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to search messages: %w", err)
+		}
+		for _, m := range r.Messages {
+			ids = append(ids, m.Id)
+		}
+		if r.NextPageToken == "" {
+			break
+		}
+		pageToken = r.NextPageToken
+	}
+	return ids, nil
+}
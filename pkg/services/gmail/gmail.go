@@ -1,9 +1,17 @@
 package gmail
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"slices"
 	"strings"
 
 	"google.golang.org/api/gmail/v1"
@@ -24,8 +32,16 @@ func New(ctx context.Context, opts ...option.ClientOption) (*GmailService, error
 	return &GmailService{srv: srv}, nil
 }
 
+// Ping makes a cheap request to check that the API is enabled and its scope granted.
+func (g *GmailService) Ping(ctx context.Context) error {
+	if _, err := g.srv.Users.GetProfile("me").Fields("emailAddress").Context(ctx).Do(); err != nil {
+		return fmt.Errorf("unable to get Gmail profile: %w", err)
+	}
+	return nil
+}
+
 // ListThreads lists threads matching the query.
-func (g *GmailService) ListThreads(query string, limit int64) ([]*gmail.Thread, error) {
+func (g *GmailService) ListThreads(ctx context.Context, query string, limit int64) ([]*gmail.Thread, error) {
 	if limit <= 0 {
 		limit = 10
 	}
@@ -35,7 +51,7 @@ func (g *GmailService) ListThreads(query string, limit int64) ([]*gmail.Thread,
 		call.Q(query)
 	}
 
-	r, err := call.Do()
+	r, err := call.Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve threads: %w", err)
 	}
@@ -43,76 +59,971 @@ func (g *GmailService) ListThreads(query string, limit int64) ([]*gmail.Thread,
 }
 
 // GetThread retrieves a thread by ID.
-func (g *GmailService) GetThread(threadID string) (*gmail.Thread, error) {
-	t, err := g.srv.Users.Threads.Get("me", threadID).Do()
+func (g *GmailService) GetThread(ctx context.Context, threadID string) (*gmail.Thread, error) {
+	t, err := g.srv.Users.Threads.Get("me", threadID).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve thread: %w", err)
 	}
 	return t, nil
 }
 
-// SendEmail sends an email.
-func (g *GmailService) SendEmail(to string, subject string, body string) (*gmail.Message, error) {
-	msgStr := fmt.Sprintf("To: %s\r\nSubject: %s\r\n\r\n%s", to, subject, body)
+// GetMessage retrieves a single message with its full payload.
+func (g *GmailService) GetMessage(ctx context.Context, messageID string) (*gmail.Message, error) {
+	if messageID == "" {
+		return nil, fmt.Errorf("message_id is required")
+	}
+	m, err := g.srv.Users.Messages.Get("me", messageID).Format("full").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve message: %w", err)
+	}
+	return m, nil
+}
+
+// ThreadWindow is a slice of a thread's messages, for reading long threads incrementally.
+type ThreadWindow struct {
+	ThreadID string
+	Total    int // Number of messages in the whole thread
+	Offset   int // Index of the first returned message
+	Messages []*gmail.Message
+}
+
+// GetThreadWindow retrieves up to limit messages of a thread starting at offset (0-based).
+// A negative offset counts from the end, so -3 returns the last three messages. limit <= 0 means no limit.
+// Only the messages in the window are fetched in full.
+func (g *GmailService) GetThreadWindow(ctx context.Context, threadID string, offset int, limit int) (*ThreadWindow, error) {
+	if threadID == "" {
+		return nil, fmt.Errorf("thread_id is required")
+	}
+	t, err := g.srv.Users.Threads.Get("me", threadID).Format("minimal").Fields("id,messages/id").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve thread: %w", err)
+	}
+
+	total := len(t.Messages)
+	start, end := windowBounds(total, offset, limit)
+	w := &ThreadWindow{ThreadID: t.Id, Total: total, Offset: start}
+	for _, ref := range t.Messages[start:end] {
+		m, err := g.srv.Users.Messages.Get("me", ref.Id).Format("full").Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve message %s: %w", ref.Id, err)
+		}
+		w.Messages = append(w.Messages, m)
+	}
+	return w, nil
+}
+
+// windowBounds clamps an offset/limit window to [0, total). Negative offsets count from the end.
+func windowBounds(total int, offset int, limit int) (int, int) {
+	if offset < 0 {
+		offset += total
+	}
+	start := max(0, min(offset, total))
+	end := total
+	if limit > 0 {
+		end = min(start+limit, total)
+	}
+	return start, end
+}
+
+// MessageSummary is structured per-message metadata for triage, without the body.
+type MessageSummary struct {
+	ID             string   `json:"id"`
+	ThreadID       string   `json:"thread_id"`
+	From           string   `json:"from"`
+	To             string   `json:"to"`
+	Subject        string   `json:"subject"`
+	Date           string   `json:"date"`
+	Labels         []string `json:"labels"`
+	HasAttachments bool     `json:"has_attachments"`
+	SizeEstimate   int64    `json:"size_estimate"`
+	Snippet        string   `json:"snippet"`
+}
+
+// MessageSearchResult is one page of SearchMessages results.
+type MessageSearchResult struct {
+	Messages           []MessageSummary `json:"messages"`
+	NextPageToken      string           `json:"next_page_token,omitempty"`
+	ResultSizeEstimate int64            `json:"result_size_estimate"`
+}
+
+// summaryFields limits message fetches to headers and part structure, skipping body data.
+const summaryFields = "id,threadId,labelIds,snippet,sizeEstimate," +
+	"payload(headers,filename,body/attachmentId," +
+	"parts(filename,body/attachmentId,parts(filename,body/attachmentId,parts(filename,body/attachmentId))))"
+
+// SearchMessages lists messages matching a Gmail query and returns structured metadata for each.
+// Pass the returned NextPageToken as pageToken to fetch the next page.
+func (g *GmailService) SearchMessages(ctx context.Context, query string, limit int64, pageToken string) (*MessageSearchResult, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	call := g.srv.Users.Messages.List("me").MaxResults(limit)
+	if query != "" {
+		call.Q(query)
+	}
+	if pageToken != "" {
+		call.PageToken(pageToken)
+	}
+	r, err := call.Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to search messages: %w", err)
+	}
+
+	out := &MessageSearchResult{
+		Messages:           make([]MessageSummary, 0, len(r.Messages)),
+		NextPageToken:      r.NextPageToken,
+		ResultSizeEstimate: r.ResultSizeEstimate,
+	}
+	for _, ref := range r.Messages {
+		m, err := g.srv.Users.Messages.Get("me", ref.Id).Fields(summaryFields).Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve message %s: %w", ref.Id, err)
+		}
+		out.Messages = append(out.Messages, summarizeMessage(m))
+	}
+	return out, nil
+}
+
+// summarizeMessage builds a MessageSummary from a message fetched with headers and part structure.
+func summarizeMessage(m *gmail.Message) MessageSummary {
+	sum := MessageSummary{
+		ID:           m.Id,
+		ThreadID:     m.ThreadId,
+		Labels:       m.LabelIds,
+		SizeEstimate: m.SizeEstimate,
+		Snippet:      m.Snippet,
+	}
+	if m.Payload != nil {
+		sum.From = GetHeader(m.Payload.Headers, "From")
+		sum.To = GetHeader(m.Payload.Headers, "To")
+		sum.Subject = GetHeader(m.Payload.Headers, "Subject")
+		sum.Date = GetHeader(m.Payload.Headers, "Date")
+		sum.HasAttachments = len(collectAttachments(m.Id, m.Payload, nil)) > 0
+	}
+	return sum
+}
+
+// MaxAttachmentBytes is the most Gmail accepts as attachments of one email; larger files have to be
+// shared as a link instead.
+const MaxAttachmentBytes = 25 * 1024 * 1024
+
+// OutgoingAttachment is a file to attach to an outgoing email.
+type OutgoingAttachment struct {
+	Filename string
+	MimeType string // Defaults to application/octet-stream
+	Data     []byte
+}
+
+// outgoingMessage holds the parts of an email to be sent or saved as a draft.
+type outgoingMessage struct {
+	To          string
+	Cc          string
+	Subject     string
+	InReplyTo   string
+	References  string
+	Body        string // Plain text body
+	HTMLBody    string // HTML body; sent alongside Body as multipart/alternative when both are set
+	Attachments []OutgoingAttachment
+}
+
+// mimePart is a rendered MIME entity: its headers and encoded content.
+type mimePart struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+// raw renders the message as a base64url-encoded RFC 2822 string, as expected by Message.Raw.
+// Text and HTML bodies become multipart/alternative; attachments wrap the body in multipart/mixed.
+// Non-ASCII subjects are encoded per RFC 2047. To and Cc must be valid address lists; they are written
+// back from the parsed addresses, so a value cannot add headers of its own.
+func (m outgoingMessage) raw() (string, error) {
+	to, err := formatAddressList(m.To)
+	if err != nil {
+		return "", fmt.Errorf("invalid To: %w", err)
+	}
+	cc, err := formatAddressList(m.Cc)
+	if err != nil {
+		return "", fmt.Errorf("invalid Cc: %w", err)
+	}
+	content, err := m.content()
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	writeHeader := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s: %s\r\n", name, value)
+		}
+	}
+	writeHeader("To", to)
+	writeHeader("Cc", cc)
+	writeHeader("Subject", mime.QEncoding.Encode("UTF-8", m.Subject))
+	writeHeader("In-Reply-To", m.InReplyTo)
+	writeHeader("References", m.References)
+	writeHeader("MIME-Version", "1.0")
+	for _, name := range []string{"Content-Type", "Content-Disposition", "Content-Transfer-Encoding"} {
+		writeHeader(name, content.header.Get(name))
+	}
+	b.WriteString("\r\n")
+	b.Write(content.body)
+	return base64.URLEncoding.EncodeToString(b.Bytes()), nil
+}
+
+// content builds the top-level MIME entity for the message body and attachments.
+func (m outgoingMessage) content() (mimePart, error) {
+	var body mimePart
+	switch {
+	case m.HTMLBody != "" && m.Body != "":
+		alt, err := multipartOf("alternative", []mimePart{textPart("text/plain", m.Body), textPart("text/html", m.HTMLBody)})
+		if err != nil {
+			return mimePart{}, err
+		}
+		body = alt
+	case m.HTMLBody != "":
+		body = textPart("text/html", m.HTMLBody)
+	default:
+		body = textPart("text/plain", m.Body)
+	}
+	if len(m.Attachments) == 0 {
+		return body, nil
+	}
+
+	parts := []mimePart{body}
+	for _, a := range m.Attachments {
+		parts = append(parts, attachmentPart(a))
+	}
+	return multipartOf("mixed", parts)
+}
+
+// textPart renders a UTF-8 text entity using quoted-printable encoding.
+func textPart(mediaType string, text string) mimePart {
+	var buf bytes.Buffer
+	qp := quotedprintable.NewWriter(&buf)
+	_, _ = io.WriteString(qp, text)
+	_ = qp.Close()
+	return mimePart{
+		header: textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(mediaType, map[string]string{"charset": "UTF-8"})},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		},
+		body: buf.Bytes(),
+	}
+}
+
+// attachmentPart renders a file attachment as a base64 entity.
+func attachmentPart(a OutgoingAttachment) mimePart {
+	mimeType := a.MimeType
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	var buf bytes.Buffer
+	_ = writeBase64Lines(&buf, a.Data)
+	return mimePart{
+		header: textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(mimeType, map[string]string{"name": a.Filename})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		},
+		body: buf.Bytes(),
+	}
+}
+
+// multipartOf wraps parts in a multipart/<subtype> entity with a fresh boundary.
+func multipartOf(subtype string, parts []mimePart) (mimePart, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for _, p := range parts {
+		w, err := mw.CreatePart(p.header)
+		if err != nil {
+			return mimePart{}, err
+		}
+		if _, err := w.Write(p.body); err != nil {
+			return mimePart{}, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return mimePart{}, err
+	}
+	return mimePart{
+		header: textproto.MIMEHeader{
+			"Content-Type": {mime.FormatMediaType("multipart/"+subtype, map[string]string{"boundary": mw.Boundary()})},
+		},
+		body: buf.Bytes(),
+	}, nil
+}
+
+// writeBase64Lines writes data as standard base64 wrapped at 76 characters per line (RFC 2045).
+func writeBase64Lines(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := io.WriteString(w, encoded[:76]+"\r\n"); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err := io.WriteString(w, encoded+"\r\n")
+	return err
+}
+
+// SendEmail sends an email with a plain text and/or HTML body, optionally with file attachments.
+func (g *GmailService) SendEmail(ctx context.Context, to string, subject string, body string, htmlBody string, attachments ...OutgoingAttachment) (*gmail.Message, error) {
+	raw, err := outgoingMessage{To: to, Subject: subject, Body: body, HTMLBody: htmlBody, Attachments: attachments}.raw()
+	if err != nil {
+		return nil, fmt.Errorf("unable to build message: %w", err)
+	}
 	msg := &gmail.Message{
-		Raw: base64.URLEncoding.EncodeToString([]byte(msgStr)),
+		Raw: raw,
 	}
 
-	m, err := g.srv.Users.Messages.Send("me", msg).Do()
+	m, err := g.srv.Users.Messages.Send("me", msg).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to send message: %w", err)
 	}
 	return m, nil
 }
 
-// CreateDraft creates a draft email.
-func (g *GmailService) CreateDraft(to string, subject string, body string) (*gmail.Draft, error) {
-	msgStr := fmt.Sprintf("To: %s\r\nSubject: %s\r\n\r\n%s", to, subject, body)
+// CreateDraft creates a draft email with a plain text and/or HTML body.
+func (g *GmailService) CreateDraft(ctx context.Context, to string, subject string, body string, htmlBody string) (*gmail.Draft, error) {
+	raw, err := outgoingMessage{To: to, Subject: subject, Body: body, HTMLBody: htmlBody}.raw()
+	if err != nil {
+		return nil, fmt.Errorf("unable to build message: %w", err)
+	}
 	msg := &gmail.Message{
-		Raw: base64.URLEncoding.EncodeToString([]byte(msgStr)),
+		Raw: raw,
 	}
 
 	draft := &gmail.Draft{
 		Message: msg,
 	}
 
-	d, err := g.srv.Users.Drafts.Create("me", draft).Do()
+	d, err := g.srv.Users.Drafts.Create("me", draft).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to create draft: %w", err)
 	}
 	return d, nil
 }
 
+// ListDrafts lists drafts, optionally filtered by a Gmail search query.
+// Each draft's message is fetched with metadata headers so callers can show recipients and subject.
+func (g *GmailService) ListDrafts(ctx context.Context, query string, limit int64) ([]*gmail.Draft, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	call := g.srv.Users.Drafts.List("me").MaxResults(limit)
+	if query != "" {
+		call.Q(query)
+	}
+	r, err := call.Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list drafts: %w", err)
+	}
+
+	drafts := make([]*gmail.Draft, 0, len(r.Drafts))
+	for _, ref := range r.Drafts {
+		d, err := g.srv.Users.Drafts.Get("me", ref.Id).Format("metadata").Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve draft %s: %w", ref.Id, err)
+		}
+		drafts = append(drafts, d)
+	}
+	return drafts, nil
+}
+
+// UpdateDraftInput holds optional fields for updating a draft. Nil fields keep the draft's current value.
+type UpdateDraftInput struct {
+	To       *string
+	Subject  *string
+	Body     *string
+	HTMLBody *string
+}
+
+// UpdateDraft replaces a draft's content, keeping any fields not set in the input.
+// Threading headers are preserved so reply drafts stay in their conversation.
+func (g *GmailService) UpdateDraft(ctx context.Context, draftID string, in UpdateDraftInput) (*gmail.Draft, error) {
+	if draftID == "" {
+		return nil, fmt.Errorf("draft_id is required")
+	}
+	existing, err := g.srv.Users.Drafts.Get("me", draftID).Format("full").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve draft: %w", err)
+	}
+
+	var out outgoingMessage
+	var threadID string
+	if existing.Message != nil {
+		threadID = existing.Message.ThreadId
+		if p := existing.Message.Payload; p != nil {
+			out = outgoingMessage{
+				To:         GetHeader(p.Headers, "To"),
+				Cc:         GetHeader(p.Headers, "Cc"),
+				Subject:    decodeHeader(GetHeader(p.Headers, "Subject")),
+				InReplyTo:  GetHeader(p.Headers, "In-Reply-To"),
+				References: GetHeader(p.Headers, "References"),
+				Body:       findBodyPart(p, "text/plain"),
+				HTMLBody:   findBodyPart(p, "text/html"),
+			}
+		}
+	}
+	if in.To != nil {
+		out.To = *in.To
+	}
+	if in.Subject != nil {
+		out.Subject = *in.Subject
+	}
+	if in.Body != nil {
+		out.Body = *in.Body
+	}
+	if in.HTMLBody != nil {
+		out.HTMLBody = *in.HTMLBody
+	}
+
+	raw, err := out.raw()
+	if err != nil {
+		return nil, fmt.Errorf("unable to build message: %w", err)
+	}
+	draft := &gmail.Draft{
+		Id:      draftID,
+		Message: &gmail.Message{Raw: raw, ThreadId: threadID},
+	}
+	d, err := g.srv.Users.Drafts.Update("me", draftID, draft).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to update draft: %w", err)
+	}
+	return d, nil
+}
+
+// SendDraft sends an existing draft.
+func (g *GmailService) SendDraft(ctx context.Context, draftID string) (*gmail.Message, error) {
+	if draftID == "" {
+		return nil, fmt.Errorf("draft_id is required")
+	}
+	m, err := g.srv.Users.Drafts.Send("me", &gmail.Draft{Id: draftID}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to send draft: %w", err)
+	}
+	return m, nil
+}
+
+// findBodyPart returns the decoded content of the first non-attachment part with the given mime type.
+func findBodyPart(part *gmail.MessagePart, mimeType string) string {
+	if part == nil {
+		return ""
+	}
+	if part.MimeType == mimeType && part.Filename == "" && part.Body != nil && part.Body.Data != "" {
+		data, _ := base64.URLEncoding.DecodeString(part.Body.Data)
+		return string(data)
+	}
+	for _, p := range part.Parts {
+		if body := findBodyPart(p, mimeType); body != "" {
+			return body
+		}
+	}
+	return ""
+}
+
+// decodeHeader decodes RFC 2047 encoded words, returning the input unchanged if it is not encoded.
+func decodeHeader(value string) string {
+	decoded, err := new(mime.WordDecoder).DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+// ReplyToThread sends a reply to the latest message in a thread.
+// The reply carries In-Reply-To/References headers and the thread ID so Gmail keeps the conversation together.
+// When the user sent the latest message, as with a follow-up, the reply goes to that message's recipients.
+// With replyAll, the original To and Cc recipients (minus the authenticated user) are copied onto the reply.
+func (g *GmailService) ReplyToThread(ctx context.Context, threadID string, body string, replyAll bool) (*gmail.Message, error) {
+	if threadID == "" {
+		return nil, fmt.Errorf("thread_id is required")
+	}
+	t, err := g.srv.Users.Threads.Get("me", threadID).
+		Format("metadata").
+		MetadataHeaders("From", "Reply-To", "To", "Cc", "Subject", "Message-ID", "References").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve thread: %w", err)
+	}
+	if len(t.Messages) == 0 || t.Messages[len(t.Messages)-1].Payload == nil {
+		return nil, fmt.Errorf("thread %s has no messages to reply to", threadID)
+	}
+	last := t.Messages[len(t.Messages)-1]
+
+	profile, err := g.srv.Users.GetProfile("me").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve profile: %w", err)
+	}
+
+	out := buildReply(last.Payload.Headers, profile.EmailAddress, slices.Contains(last.LabelIds, "SENT"), replyAll)
+	out.Body = body
+	raw, err := out.raw()
+	if err != nil {
+		return nil, fmt.Errorf("unable to build message: %w", err)
+	}
+	msg := &gmail.Message{
+		Raw:      raw,
+		ThreadId: threadID,
+	}
+
+	m, err := g.srv.Users.Messages.Send("me", msg).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to send reply: %w", err)
+	}
+	return m, nil
+}
+
+// buildReply derives the recipients, subject and threading headers of a reply from the original message headers.
+// self is the authenticated user's address and is excluded from reply-all recipients. A message sent by the
+// user (sent, or from self) is answered to its own To, not back to the user.
+func buildReply(headers []*gmail.MessagePartHeader, self string, sent bool, replyAll bool) outgoingMessage {
+	from := parseAddresses(GetHeader(headers, "From"))
+	if len(from) > 0 && self != "" && strings.EqualFold(from[0].Address, self) {
+		sent = true
+	}
+	to := GetHeader(headers, "Reply-To")
+	if to == "" {
+		to = GetHeader(headers, "From")
+	}
+	if sent {
+		to = GetHeader(headers, "To")
+	}
+
+	subject := GetHeader(headers, "Subject")
+	if !strings.HasPrefix(strings.ToLower(subject), "re:") {
+		subject = "Re: " + subject
+	}
+
+	messageID := GetHeader(headers, "Message-ID")
+	references := strings.TrimSpace(GetHeader(headers, "References") + " " + messageID)
+
+	out := outgoingMessage{
+		To:         to,
+		Subject:    subject,
+		InReplyTo:  messageID,
+		References: references,
+	}
+	if replyAll {
+		var cc []string
+		seen := map[string]bool{strings.ToLower(self): true}
+		for _, a := range parseAddresses(to) {
+			seen[strings.ToLower(a.Address)] = true
+		}
+		original := append(parseAddresses(GetHeader(headers, "To")), parseAddresses(GetHeader(headers, "Cc"))...)
+		for _, a := range original {
+			key := strings.ToLower(a.Address)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			cc = append(cc, a.String())
+		}
+		out.Cc = strings.Join(cc, ", ")
+	}
+	return out
+}
+
+// parseAddresses parses a comma-separated address header.
+// If the header as a whole is malformed, entries are parsed one by one and invalid ones are skipped.
+func parseAddresses(header string) []*mail.Address {
+	if strings.TrimSpace(header) == "" {
+		return nil
+	}
+	if list, err := mail.ParseAddressList(header); err == nil {
+		return list
+	}
+	var out []*mail.Address
+	for _, part := range strings.Split(header, ",") {
+		if a, err := mail.ParseAddress(part); err == nil {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// formatAddressList parses a comma-separated list of recipients and renders it as a header value,
+// with names encoded per RFC 2047 where needed. An empty list renders as "".
+func formatAddressList(list string) (string, error) {
+	if strings.TrimSpace(list) == "" {
+		return "", nil
+	}
+	addrs, err := mail.ParseAddressList(list)
+	if err != nil {
+		return "", fmt.Errorf("%q: %w", list, err)
+	}
+	out := make([]string, len(addrs))
+	for i, a := range addrs {
+		if a.Name == "" {
+			out[i] = a.Address
+		} else {
+			out[i] = a.String()
+		}
+	}
+	return strings.Join(out, ", "), nil
+}
+
+// AttachmentInfo describes an attachment on a message, without its content.
+type AttachmentInfo struct {
+	MessageID    string
+	AttachmentID string
+	Filename     string
+	MimeType     string
+	Size         int64
+}
+
+// Attachment is a downloaded attachment with its decoded content.
+type Attachment struct {
+	AttachmentInfo
+	Data []byte
+}
+
+// ListMessageAttachments lists the attachments of a single message.
+func (g *GmailService) ListMessageAttachments(ctx context.Context, messageID string) ([]AttachmentInfo, error) {
+	if messageID == "" {
+		return nil, fmt.Errorf("message_id is required")
+	}
+	m, err := g.GetMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	return MessageAttachments(m), nil
+}
+
+// MessageAttachments lists the attachments of an already fetched message.
+func MessageAttachments(m *gmail.Message) []AttachmentInfo {
+	if m == nil {
+		return nil
+	}
+	return collectAttachments(m.Id, m.Payload, nil)
+}
+
+// ListThreadAttachments lists the attachments of every message in a thread.
+func (g *GmailService) ListThreadAttachments(ctx context.Context, threadID string) ([]AttachmentInfo, error) {
+	if threadID == "" {
+		return nil, fmt.Errorf("thread_id is required")
+	}
+	t, err := g.srv.Users.Threads.Get("me", threadID).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve thread: %w", err)
+	}
+	var out []AttachmentInfo
+	for _, m := range t.Messages {
+		out = collectAttachments(m.Id, m.Payload, out)
+	}
+	return out, nil
+}
+
+// collectAttachments walks a message payload and appends every part that carries an attachment ID.
+func collectAttachments(messageID string, part *gmail.MessagePart, out []AttachmentInfo) []AttachmentInfo {
+	if part == nil {
+		return out
+	}
+	if part.Body != nil && part.Body.AttachmentId != "" {
+		out = append(out, AttachmentInfo{
+			MessageID:    messageID,
+			AttachmentID: part.Body.AttachmentId,
+			Filename:     part.Filename,
+			MimeType:     part.MimeType,
+			Size:         part.Body.Size,
+		})
+	}
+	for _, p := range part.Parts {
+		out = collectAttachments(messageID, p, out)
+	}
+	return out
+}
+
+// GetAttachment downloads and decodes an attachment.
+// Filename and mime type are looked up from the message on a best-effort basis and may be empty.
+func (g *GmailService) GetAttachment(ctx context.Context, messageID string, attachmentID string) (*Attachment, error) {
+	if messageID == "" || attachmentID == "" {
+		return nil, fmt.Errorf("message_id and attachment_id are required")
+	}
+	body, err := g.srv.Users.Messages.Attachments.Get("me", messageID, attachmentID).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve attachment: %w", err)
+	}
+	data, err := base64.URLEncoding.DecodeString(body.Data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode attachment: %w", err)
+	}
+
+	att := &Attachment{
+		AttachmentInfo: AttachmentInfo{MessageID: messageID, AttachmentID: attachmentID, Size: int64(len(data))},
+		Data:           data,
+	}
+	if infos, err := g.ListMessageAttachments(ctx, messageID); err == nil {
+		for _, info := range infos {
+			if info.AttachmentID == attachmentID {
+				att.Filename = info.Filename
+				att.MimeType = info.MimeType
+				break
+			}
+		}
+	}
+	return att, nil
+}
+
+// threadActions maps triage action names to the label changes they imply.
+var threadActions = map[string]struct{ add, remove string }{
+	"read":      {remove: "UNREAD"},
+	"unread":    {add: "UNREAD"},
+	"archive":   {remove: "INBOX"},
+	"unarchive": {add: "INBOX"},
+	"star":      {add: "STARRED"},
+	"unstar":    {remove: "STARRED"},
+	"spam":      {add: "SPAM", remove: "INBOX"},
+	"not_spam":  {add: "INBOX", remove: "SPAM"},
+}
+
+// LabelChangesForActions converts triage actions (read, unread, archive, unarchive, star, unstar, spam, not_spam)
+// plus explicit label IDs into the add/remove label lists for a modify call.
+// It returns an error for unknown actions or when a label would be both added and removed.
+func LabelChangesForActions(actions []string, addLabels []string, removeLabels []string) ([]string, []string, error) {
+	add := append([]string(nil), addLabels...)
+	remove := append([]string(nil), removeLabels...)
+	for _, a := range actions {
+		change, ok := threadActions[strings.ToLower(strings.TrimSpace(a))]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown action %q (valid: read, unread, archive, unarchive, star, unstar, spam, not_spam)", a)
+		}
+		if change.add != "" {
+			add = append(add, change.add)
+		}
+		if change.remove != "" {
+			remove = append(remove, change.remove)
+		}
+	}
+	for _, l := range add {
+		for _, r := range remove {
+			if l == r {
+				return nil, nil, fmt.Errorf("label %s cannot be both added and removed", l)
+			}
+		}
+	}
+	if len(add) == 0 && len(remove) == 0 {
+		return nil, nil, fmt.Errorf("no label changes requested")
+	}
+	return add, remove, nil
+}
+
+// ModifyThread adds and removes labels on every message in a thread.
+func (g *GmailService) ModifyThread(ctx context.Context, threadID string, addLabels []string, removeLabels []string) (*gmail.Thread, error) {
+	if threadID == "" {
+		return nil, fmt.Errorf("thread_id is required")
+	}
+	req := &gmail.ModifyThreadRequest{
+		AddLabelIds:    addLabels,
+		RemoveLabelIds: removeLabels,
+	}
+	t, err := g.srv.Users.Threads.Modify("me", threadID, req).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to modify thread: %w", err)
+	}
+	return t, nil
+}
+
+// ResolveLabelIDs maps label names or IDs to label IDs, matching names case-insensitively.
+func (g *GmailService) ResolveLabelIDs(ctx context.Context, namesOrIDs []string) ([]string, error) {
+	if len(namesOrIDs) == 0 {
+		return nil, nil
+	}
+	labels, err := g.ListLabels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(namesOrIDs))
+	for _, want := range namesOrIDs {
+		var found string
+		for _, l := range labels {
+			if l.Id == want || strings.EqualFold(l.Name, want) {
+				found = l.Id
+				break
+			}
+		}
+		if found == "" {
+			return nil, fmt.Errorf("label %q not found", want)
+		}
+		ids = append(ids, found)
+	}
+	return ids, nil
+}
+
+// ListFilters lists the user's Gmail filters.
+func (g *GmailService) ListFilters(ctx context.Context) ([]*gmail.Filter, error) {
+	r, err := g.srv.Users.Settings.Filters.List("me").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list filters: %w", err)
+	}
+	return r.Filter, nil
+}
+
+// CreateFilter creates a filter that applies action to incoming messages matching criteria.
+func (g *GmailService) CreateFilter(ctx context.Context, criteria *gmail.FilterCriteria, action *gmail.FilterAction) (*gmail.Filter, error) {
+	if criteria == nil || (criteria.From == "" && criteria.To == "" && criteria.Subject == "" &&
+		criteria.Query == "" && criteria.NegatedQuery == "" && !criteria.HasAttachment && criteria.Size == 0) {
+		return nil, fmt.Errorf("at least one filter criterion is required")
+	}
+	if action == nil || (len(action.AddLabelIds) == 0 && len(action.RemoveLabelIds) == 0 && action.Forward == "") {
+		return nil, fmt.Errorf("at least one filter action is required")
+	}
+	f, err := g.srv.Users.Settings.Filters.Create("me", &gmail.Filter{Criteria: criteria, Action: action}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create filter: %w", err)
+	}
+	return f, nil
+}
+
+// DeleteFilter deletes a filter by ID.
+func (g *GmailService) DeleteFilter(ctx context.Context, filterID string) error {
+	if filterID == "" {
+		return fmt.Errorf("filter_id is required")
+	}
+	if err := g.srv.Users.Settings.Filters.Delete("me", filterID).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("unable to delete filter: %w", err)
+	}
+	return nil
+}
+
+// batchModifyChunkSize is the maximum number of message IDs accepted by one batchModify call.
+const batchModifyChunkSize = 1000
+
+// BatchFailure records an ID that could not be processed in a batch operation.
+type BatchFailure struct {
+	ID    string
+	Error string
+}
+
+// BatchResult reports which IDs a batch operation succeeded and failed on.
+type BatchResult struct {
+	Succeeded []string
+	Failed    []BatchFailure
+}
+
+// batchUnit is one requested ID (message or thread) and the message IDs it covers.
+type batchUnit struct {
+	id         string
+	messageIDs []string
+}
+
+// BatchModify adds and removes labels on many messages or threads using Messages.BatchModify.
+// When threads is true, ids are thread IDs and every message in each thread is modified.
+// Requests are chunked to the API limit; a failed chunk marks all of its IDs as failed without aborting the rest.
+func (g *GmailService) BatchModify(ctx context.Context, ids []string, threads bool, addLabels []string, removeLabels []string) *BatchResult {
+	res := &BatchResult{}
+	var units []batchUnit
+	for _, id := range ids {
+		if !threads {
+			units = append(units, batchUnit{id: id, messageIDs: []string{id}})
+			continue
+		}
+		t, err := g.srv.Users.Threads.Get("me", id).Format("minimal").Fields("messages/id").Context(ctx).Do()
+		if err != nil {
+			res.Failed = append(res.Failed, BatchFailure{ID: id, Error: err.Error()})
+			continue
+		}
+		unit := batchUnit{id: id}
+		for _, m := range t.Messages {
+			unit.messageIDs = append(unit.messageIDs, m.Id)
+		}
+		units = append(units, unit)
+	}
+
+	// A unit split across chunks fails if any of its chunks does.
+	errs := map[string]error{}
+	for _, chunk := range chunkUnits(units, batchModifyChunkSize) {
+		req := &gmail.BatchModifyMessagesRequest{AddLabelIds: addLabels, RemoveLabelIds: removeLabels}
+		for _, u := range chunk {
+			req.Ids = append(req.Ids, u.messageIDs...)
+		}
+		if err := g.srv.Users.Messages.BatchModify("me", req).Context(ctx).Do(); err != nil {
+			for _, u := range chunk {
+				if errs[u.id] == nil {
+					errs[u.id] = err
+				}
+			}
+		}
+	}
+	for _, u := range units {
+		if err := errs[u.id]; err != nil {
+			res.Failed = append(res.Failed, BatchFailure{ID: u.id, Error: err.Error()})
+		} else {
+			res.Succeeded = append(res.Succeeded, u.id)
+		}
+	}
+	return res
+}
+
+// chunkUnits groups units so each chunk covers at most size message IDs.
+// A unit larger than size, such as a long thread, is split across several chunks.
+func chunkUnits(units []batchUnit, size int) [][]batchUnit {
+	var chunks [][]batchUnit
+	var current []batchUnit
+	count := 0
+	for _, u := range units {
+		for ids := u.messageIDs; ; {
+			part := batchUnit{id: u.id, messageIDs: ids[:min(len(ids), size)]}
+			ids = ids[len(part.messageIDs):]
+			if len(current) > 0 && count+len(part.messageIDs) > size {
+				chunks = append(chunks, current)
+				current, count = nil, 0
+			}
+			current = append(current, part)
+			count += len(part.messageIDs)
+			if len(ids) == 0 {
+				break
+			}
+		}
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// BatchTrash moves many messages or threads to trash, one call per ID, reporting failures individually.
+func (g *GmailService) BatchTrash(ctx context.Context, ids []string, threads bool) *BatchResult {
+	res := &BatchResult{}
+	for _, id := range ids {
+		var err error
+		if threads {
+			_, err = g.srv.Users.Threads.Trash("me", id).Context(ctx).Do()
+		} else {
+			_, err = g.srv.Users.Messages.Trash("me", id).Context(ctx).Do()
+		}
+		if err != nil {
+			res.Failed = append(res.Failed, BatchFailure{ID: id, Error: err.Error()})
+		} else {
+			res.Succeeded = append(res.Succeeded, id)
+		}
+	}
+	return res
+}
+
 // TrashThread moves a thread to trash.
-func (g *GmailService) TrashThread(threadID string) error {
-	_, err := g.srv.Users.Threads.Trash("me", threadID).Do()
+func (g *GmailService) TrashThread(ctx context.Context, threadID string) error {
+	_, err := g.srv.Users.Threads.Trash("me", threadID).Context(ctx).Do()
 	return err
 }
 
 // ListLabels lists all labels.
-func (g *GmailService) ListLabels() ([]*gmail.Label, error) {
-	r, err := g.srv.Users.Labels.List("me").Do()
+func (g *GmailService) ListLabels(ctx context.Context) ([]*gmail.Label, error) {
+	r, err := g.srv.Users.Labels.List("me").Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to list labels: %w", err)
 	}
 	return r.Labels, nil
 }
 
-// Helper to extract text from a message payload
+// ExtractMessageBody returns the readable body of a message, preferring text/plain over text/html.
+// See ExtractMessageText for stripping quoted reply history.
 func ExtractMessageBody(payload *gmail.MessagePart) string {
-	if payload == nil {
-		return ""
-	}
-	var body string
-	if payload.Body != nil && payload.Body.Data != "" {
-		data, _ := base64.URLEncoding.DecodeString(payload.Body.Data)
-		body += string(data)
-	}
-
-	for _, part := range payload.Parts {
-		body += ExtractMessageBody(part)
-	}
-	return body
+	return ExtractMessageText(payload, false)
 }
 
 // Helper to find headers
@@ -0,0 +1,510 @@
+package gmail
+
+import (
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+func TestBuildReply(t *testing.T) {
+	headers := []*gmail.MessagePartHeader{
+		{Name: "From", Value: "Alice <alice@example.com>"},
+		{Name: "To", Value: "me@example.com, \"Doe, Bob\" <bob@example.com>"},
+		{Name: "Cc", Value: "carol@example.com"},
+		{Name: "Subject", Value: "Quarterly plan"},
+		{Name: "Message-ID", Value: "<msg-2@example.com>"},
+		{Name: "References", Value: "<msg-1@example.com>"},
+	}
+	// The user's own follow-up, the last message of a thread they are waiting on
+	followUp := []*gmail.MessagePartHeader{
+		{Name: "From", Value: "Me <ME@example.com>"},
+		{Name: "To", Value: "Alice <alice@example.com>"},
+		{Name: "Cc", Value: "carol@example.com, me@example.com"},
+		{Name: "Subject", Value: "Re: Quarterly plan"},
+		{Name: "Message-ID", Value: "<msg-3@example.com>"},
+	}
+
+	tests := []struct {
+		name     string
+		headers  []*gmail.MessagePartHeader
+		sent     bool
+		replyAll bool
+		wantTo   string
+		wantCc   string
+		wantSubj string
+		wantRefs string
+	}{
+		{
+			name:     "reply to sender",
+			headers:  headers,
+			wantTo:   "Alice <alice@example.com>",
+			wantSubj: "Re: Quarterly plan",
+			wantRefs: "<msg-1@example.com> <msg-2@example.com>",
+		},
+		{
+			name:     "reply all excludes self and sender",
+			headers:  headers,
+			replyAll: true,
+			wantTo:   "Alice <alice@example.com>",
+			wantCc:   "\"Doe, Bob\" <bob@example.com>, <carol@example.com>",
+			wantSubj: "Re: Quarterly plan",
+			wantRefs: "<msg-1@example.com> <msg-2@example.com>",
+		},
+		{
+			name: "prefers Reply-To and keeps existing Re prefix",
+			headers: []*gmail.MessagePartHeader{
+				{Name: "From", Value: "alice@example.com"},
+				{Name: "Reply-To", Value: "list@example.com"},
+				{Name: "Subject", Value: "RE: Lunch"},
+				{Name: "Message-ID", Value: "<m@example.com>"},
+			},
+			wantTo:   "list@example.com",
+			wantSubj: "RE: Lunch",
+			wantRefs: "<m@example.com>",
+		},
+		{
+			name:     "own follow-up goes to its recipients",
+			headers:  followUp,
+			replyAll: true,
+			wantTo:   "Alice <alice@example.com>",
+			wantCc:   "<carol@example.com>",
+			wantSubj: "Re: Quarterly plan",
+			wantRefs: "<msg-3@example.com>",
+		},
+		{
+			name:     "sent from an alias",
+			headers:  append([]*gmail.MessagePartHeader{{Name: "From", Value: "alias@example.com"}}, followUp[1:]...),
+			sent:     true,
+			wantTo:   "Alice <alice@example.com>",
+			wantSubj: "Re: Quarterly plan",
+			wantRefs: "<msg-3@example.com>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildReply(tt.headers, "me@example.com", tt.sent, tt.replyAll)
+			if got.To != tt.wantTo {
+				t.Errorf("To: expected %q, got %q", tt.wantTo, got.To)
+			}
+			if got.Cc != tt.wantCc {
+				t.Errorf("Cc: expected %q, got %q", tt.wantCc, got.Cc)
+			}
+			if got.Subject != tt.wantSubj {
+				t.Errorf("Subject: expected %q, got %q", tt.wantSubj, got.Subject)
+			}
+			if got.References != tt.wantRefs {
+				t.Errorf("References: expected %q, got %q", tt.wantRefs, got.References)
+			}
+		})
+	}
+}
+
+func TestOutgoingMessageRawWithAttachments(t *testing.T) {
+	m := outgoingMessage{
+		To:      "bob@example.com",
+		Subject: "Report",
+		Body:    "See attached.",
+		Attachments: []OutgoingAttachment{
+			{Filename: "report.pdf", MimeType: "application/pdf", Data: []byte("%PDF-1.4 fake")},
+			{Filename: "notes.bin", Data: []byte{0x00, 0x01, 0x02}},
+		},
+	}
+	raw, err := m.raw()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded, err := base64.URLEncoding.DecodeString(raw)
+	if err != nil {
+		t.Fatalf("raw is not base64url: %v", err)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(decoded)))
+	if err != nil {
+		t.Fatalf("failed to parse message: %v", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("expected multipart/mixed, got %q (%v)", mediaType, err)
+	}
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	var parts []*multipart.Part
+	var contents []string
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read part: %v", err)
+		}
+		b, _ := io.ReadAll(p)
+		parts = append(parts, p)
+		contents = append(contents, string(b))
+	}
+	if len(parts) != 3 {
+		t.Fatalf("expected 3 parts, got %d", len(parts))
+	}
+	if contents[0] != "See attached." {
+		t.Errorf("expected body text, got %q", contents[0])
+	}
+	if parts[1].FileName() != "report.pdf" {
+		t.Errorf("expected filename report.pdf, got %q", parts[1].FileName())
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(contents[1], "\r\n", ""))
+	if err != nil || string(data) != "%PDF-1.4 fake" {
+		t.Errorf("attachment content mismatch: %q (%v)", data, err)
+	}
+	if ct := parts[2].Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/octet-stream") {
+		t.Errorf("expected default content type, got %q", ct)
+	}
+}
+
+func TestLabelChangesForActions(t *testing.T) {
+	tests := []struct {
+		name       string
+		actions    []string
+		add        []string
+		remove     []string
+		wantAdd    string
+		wantRemove string
+		wantErr    bool
+	}{
+		{name: "read and archive", actions: []string{"read", "archive"}, wantRemove: "UNREAD,INBOX"},
+		{name: "star with custom label", actions: []string{"star"}, add: []string{"Label_1"}, wantAdd: "Label_1,STARRED"},
+		{name: "spam", actions: []string{"Spam"}, wantAdd: "SPAM", wantRemove: "INBOX"},
+		{name: "conflicting actions", actions: []string{"read", "unread"}, wantErr: true},
+		{name: "unknown action", actions: []string{"snooze"}, wantErr: true},
+		{name: "nothing to do", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			add, remove, err := LabelChangesForActions(tt.actions, tt.add, tt.remove)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got add=%v remove=%v", add, remove)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := strings.Join(add, ","); got != tt.wantAdd {
+				t.Errorf("add: expected %q, got %q", tt.wantAdd, got)
+			}
+			if got := strings.Join(remove, ","); got != tt.wantRemove {
+				t.Errorf("remove: expected %q, got %q", tt.wantRemove, got)
+			}
+		})
+	}
+}
+
+func TestOutgoingMessageRawHTMLAlternative(t *testing.T) {
+	m := outgoingMessage{
+		To:       "bob@example.com",
+		Subject:  "Relatório de ação",
+		Body:     "Olá, segue o relatório.",
+		HTMLBody: "<p>Olá, segue o <b>relatório</b>.</p>",
+	}
+	raw, err := m.raw()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded, err := base64.URLEncoding.DecodeString(raw)
+	if err != nil {
+		t.Fatalf("raw is not base64url: %v", err)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(string(decoded)))
+	if err != nil {
+		t.Fatalf("failed to parse message: %v", err)
+	}
+
+	rawSubject := msg.Header.Get("Subject")
+	if !strings.HasPrefix(rawSubject, "=?UTF-8?q?") {
+		t.Errorf("expected RFC 2047 encoded subject, got %q", rawSubject)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(rawSubject)
+	if err != nil || subject != m.Subject {
+		t.Errorf("expected subject %q, got %q (%v)", m.Subject, subject, err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("expected multipart/alternative, got %q (%v)", mediaType, err)
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	want := []struct{ mediaType, content string }{
+		{"text/plain", m.Body},
+		{"text/html", m.HTMLBody},
+	}
+	for _, w := range want {
+		p, err := mr.NextPart()
+		if err != nil {
+			t.Fatalf("failed to read %s part: %v", w.mediaType, err)
+		}
+		mt, params, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		if mt != w.mediaType || !strings.EqualFold(params["charset"], "UTF-8") {
+			t.Errorf("expected %s with UTF-8 charset, got %q", w.mediaType, p.Header.Get("Content-Type"))
+		}
+		b, _ := io.ReadAll(p)
+		if string(b) != w.content {
+			t.Errorf("expected %s content %q, got %q", w.mediaType, w.content, b)
+		}
+	}
+}
+
+func TestOutgoingMessageRawAddresses(t *testing.T) {
+	tests := []struct {
+		name    string
+		to, cc  string
+		wantTo  string
+		wantCc  string
+		wantErr bool
+	}{
+		{name: "plain", to: "bob@example.com", wantTo: "bob@example.com"},
+		{name: "names and lists", to: "Bob <bob@example.com>,carol@example.com", cc: "José <jose@example.com>", wantTo: `"Bob" <bob@example.com>, carol@example.com`, wantCc: "=?utf-8?q?Jos=C3=A9?= <jose@example.com>"},
+		{name: "injected Bcc in To", to: "bob@example.com\r\nBcc: eve@example.com", wantErr: true},
+		{name: "injected Bcc in Cc", to: "bob@example.com", cc: "carol@example.com,\r\nBcc: eve@example.com", wantErr: true},
+		{name: "injected Bcc in a name", to: "Bob\r\nBcc: eve@example.com <bob@example.com>", wantErr: true},
+		{name: "not an address", to: "bob", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := outgoingMessage{To: tt.to, Cc: tt.cc, Subject: "Hi", Body: "Hello"}.raw()
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("raw() accepted To %q, Cc %q", tt.to, tt.cc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			decoded, _ := base64.URLEncoding.DecodeString(raw)
+			msg, err := mail.ReadMessage(strings.NewReader(string(decoded)))
+			if err != nil {
+				t.Fatalf("failed to parse message: %v", err)
+			}
+			if got := msg.Header.Get("To"); got != tt.wantTo {
+				t.Errorf("To = %q, want %q", got, tt.wantTo)
+			}
+			if got := msg.Header.Get("Cc"); got != tt.wantCc {
+				t.Errorf("Cc = %q, want %q", got, tt.wantCc)
+			}
+		})
+	}
+}
+
+func TestChunkUnits(t *testing.T) {
+	unit := func(id string, n int) batchUnit {
+		u := batchUnit{id: id}
+		for i := 0; i < n; i++ {
+			u.messageIDs = append(u.messageIDs, id)
+		}
+		return u
+	}
+	tests := []struct {
+		name  string
+		units []batchUnit
+		want  []int // units per chunk
+	}{
+		{name: "empty", units: nil, want: nil},
+		{name: "fits in one chunk", units: []batchUnit{unit("a", 2), unit("b", 2)}, want: []int{2}},
+		{name: "splits at limit", units: []batchUnit{unit("a", 3), unit("b", 2), unit("c", 1)}, want: []int{1, 2}},
+		{name: "oversized unit is split", units: []batchUnit{unit("a", 1), unit("b", 7), unit("c", 1)}, want: []int{1, 1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := chunkUnits(tt.units, 4)
+			if len(chunks) != len(tt.want) {
+				t.Fatalf("expected %d chunks, got %d", len(tt.want), len(chunks))
+			}
+			for i, c := range chunks {
+				if len(c) != tt.want[i] {
+					t.Errorf("chunk %d: expected %d units, got %d", i, tt.want[i], len(c))
+				}
+			}
+		})
+	}
+
+	// A thread longer than the batchModify limit is sent in several requests.
+	chunks := chunkUnits([]batchUnit{unit("long", 1500), unit("short", 3)}, batchModifyChunkSize)
+	var sizes []int
+	for _, c := range chunks {
+		n := 0
+		for _, u := range c {
+			n += len(u.messageIDs)
+		}
+		sizes = append(sizes, n)
+	}
+	if !reflect.DeepEqual(sizes, []int{1000, 503}) {
+		t.Errorf("message IDs per chunk = %v, want [1000 503]", sizes)
+	}
+}
+
+func TestWindowBounds(t *testing.T) {
+	tests := []struct {
+		name                 string
+		total, offset, limit int
+		wantStart, wantEnd   int
+	}{
+		{name: "all", total: 5, wantStart: 0, wantEnd: 5},
+		{name: "first page", total: 5, limit: 2, wantStart: 0, wantEnd: 2},
+		{name: "middle", total: 5, offset: 2, limit: 2, wantStart: 2, wantEnd: 4},
+		{name: "clamped end", total: 5, offset: 4, limit: 10, wantStart: 4, wantEnd: 5},
+		{name: "offset past end", total: 5, offset: 9, limit: 2, wantStart: 5, wantEnd: 5},
+		{name: "last three", total: 5, offset: -3, wantStart: 2, wantEnd: 5},
+		{name: "negative beyond start", total: 2, offset: -5, limit: 1, wantStart: 0, wantEnd: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := windowBounds(tt.total, tt.offset, tt.limit)
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("expected [%d,%d), got [%d,%d)", tt.wantStart, tt.wantEnd, start, end)
+			}
+		})
+	}
+}
+
+func TestDigestThread(t *testing.T) {
+	body := base64.URLEncoding.EncodeToString([]byte("Can you send the Q3 figures by Friday?\n\nOn Mon, Bob wrote:\n> old text"))
+	thread := &gmail.Thread{Id: "t1", Messages: []*gmail.Message{
+		{Payload: &gmail.MessagePart{Headers: []*gmail.MessagePartHeader{{Name: "Subject", Value: "Q3 report"}, {Name: "From", Value: "Bob <bob@example.com>"}}}},
+		{Snippet: "Can you send", Payload: &gmail.MessagePart{
+			MimeType: "text/plain",
+			Headers:  []*gmail.MessagePartHeader{{Name: "Subject", Value: "Re: Q3 report"}, {Name: "From", Value: "Alice <alice@example.com>"}, {Name: "Date", Value: "Mon, 3 Mar 2025 10:00:00 +0000"}},
+			Body:     &gmail.MessagePartBody{Data: body},
+		}},
+	}}
+
+	d := DigestThread(thread, "")
+	want := ThreadDigest{
+		Subject: "Q3 report",
+		From:    "Alice <alice@example.com>",
+		Date:    "Mon, 3 Mar 2025 10:00:00 +0000",
+		Summary: "Can you send the Q3 figures by Friday?",
+		Link:    "https://mail.google.com/mail/#all/t1",
+	}
+	if d != want {
+		t.Errorf("DigestThread() = %+v, want %+v", d, want)
+	}
+	wantNotes := "From: Alice <alice@example.com>\nDate: Mon, 3 Mar 2025 10:00:00 +0000\n\nCan you send the Q3 figures by Friday?\n\nThread: https://mail.google.com/mail/#all/t1"
+	if got := d.Notes(); got != wantNotes {
+		t.Errorf("Notes() = %q, want %q", got, wantNotes)
+	}
+
+	if d := DigestThread(thread, "  Send Q3 figures  "); d.Summary != "Send Q3 figures" {
+		t.Errorf("summary = %q, want the given one", d.Summary)
+	}
+	if d := DigestThread(&gmail.Thread{Id: "t2"}, ""); d.Notes() != "Thread: https://mail.google.com/mail/#all/t2" {
+		t.Errorf("empty thread notes = %q", d.Notes())
+	}
+	if got := truncateRunes(strings.Repeat("é", 10), 5); got != "éééé…" {
+		t.Errorf("truncateRunes = %q", got)
+	}
+}
+
+func TestThreadExport(t *testing.T) {
+	body := base64.URLEncoding.EncodeToString([]byte("Agreed:\n- ship on *Friday*\n# not a heading"))
+	thread := &gmail.Thread{Id: "t1", Messages: []*gmail.Message{{
+		Id: "m1",
+		Payload: &gmail.MessagePart{
+			MimeType: "multipart/mixed",
+			Headers: []*gmail.MessagePartHeader{
+				{Name: "Subject", Value: "Release plan"},
+				{Name: "From", Value: "Alice <alice@example.com>"},
+				{Name: "To", Value: "bob@example.com"},
+				{Name: "Date", Value: "Mon, 3 Mar 2025 10:00:00 +0000"},
+			},
+			Parts: []*gmail.MessagePart{
+				{MimeType: "text/plain", Body: &gmail.MessagePartBody{Data: body}},
+				{MimeType: "application/pdf", Filename: "plan.pdf", Body: &gmail.MessagePartBody{AttachmentId: "a1", Size: 2048}},
+			},
+		},
+	}}}
+
+	md := ThreadMarkdown(thread)
+	for _, want := range []string{
+		"# Release plan\n",
+		"Thread: [https://mail.google.com/mail/#all/t1](https://mail.google.com/mail/#all/t1)",
+		"## 1. Alice <alice@example\\.com\\>\n",
+		"**To:** bob@example\\.com",
+		"\\- ship on \\*Friday\\*\n\\# not a heading\n",
+		"- plan\\.pdf \\(application/pdf, 2048 bytes\\)\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("ThreadMarkdown() = %q, missing %q", md, want)
+		}
+	}
+	if strings.Contains(md, "Cc:") {
+		t.Errorf("ThreadMarkdown() = %q, want no empty Cc", md)
+	}
+
+	text := ThreadText(thread)
+	for _, want := range []string{
+		"Release plan\nThread: https://mail.google.com/mail/#all/t1\n",
+		"1. From: Alice <alice@example.com>\n",
+		"- ship on *Friday*\n# not a heading\n",
+		"  - plan.pdf (application/pdf, 2048 bytes)\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("ThreadText() = %q, missing %q", text, want)
+		}
+	}
+
+	if got := ThreadSubject(&gmail.Thread{}); got != "" {
+		t.Errorf("ThreadSubject(empty) = %q", got)
+	}
+}
+
+func TestExtractMessage(t *testing.T) {
+	fields, err := ParseExtractFields(`{"Total": "Total:\\s*\\$?([\\d.,]+)", "Order": "(?i)order #(\\w+)", "Ship date": "\\d{4}-\\d{2}-\\d{2}", "Coupon": "CODE-(\\w+)"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantColumns := []string{"Date", "From", "Subject", "Total", "Order", "Ship date", "Coupon", "Link", MessageIDColumn}
+	if got := ExtractColumns(fields); !reflect.DeepEqual(got, wantColumns) {
+		t.Errorf("ExtractColumns() = %v, want %v", got, wantColumns)
+	}
+
+	body := base64.URLEncoding.EncodeToString([]byte("Thanks!\nShips 2025-03-05.\n> Total: $1,234.50"))
+	m := &gmail.Message{
+		Id:           "m1",
+		ThreadId:     "t1",
+		InternalDate: time.Date(2025, 3, 3, 13, 30, 0, 0, time.UTC).UnixMilli(),
+		Payload: &gmail.MessagePart{
+			MimeType: "text/plain",
+			Headers: []*gmail.MessagePartHeader{
+				{Name: "Subject", Value: "Your ORDER #A12 receipt"},
+				{Name: "From", Value: "Shop <shop@example.com>"},
+			},
+			Body: &gmail.MessagePartBody{Data: body},
+		},
+	}
+	loc := time.FixedZone("BRT", -3*60*60)
+	values, matched := ExtractMessage(m, fields, loc)
+	want := []interface{}{"2025-03-03 10:30", "Shop <shop@example.com>", "Your ORDER #A12 receipt", 1234.5, "A12", "2025-03-05", "", "https://mail.google.com/mail/#all/t1", "m1"}
+	if !reflect.DeepEqual(values, want) || matched != 3 {
+		t.Errorf("ExtractMessage() = %v, %d; want %v, 3", values, matched, want)
+	}
+
+	for _, in := range []string{`["Total"]`, `{"Total": 5}`, `{"Total": "("}`, `{"subject": "x"}`, `{" ": "x"}`} {
+		if _, err := ParseExtractFields(in); err == nil {
+			t.Errorf("ParseExtractFields(%s) should fail", in)
+		}
+	}
+	for in, want := range map[string]interface{}{"42": 42.0, "-3.5": -3.5, "12,345": 12345.0, "1.234,50": "1.234,50", "12,34": "12,34", "A1": "A1"} {
+		if got := extractedValue(in); got != want {
+			t.Errorf("extractedValue(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package gmail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// ErrHistoryExpired is returned when a history ID is too old for Gmail to list changes from it
+// (history is typically kept for about a week). Start over from the current history ID.
+var ErrHistoryExpired = errors.New("history ID is too old; start again without one")
+
+// Watch registers push notifications for the mailbox on a Cloud Pub/Sub topic
+// ("projects/<project>/topics/<topic>", which must grant publish rights to gmail-api-push@system.gserviceaccount.com).
+// labelIDs optionally limits notifications to those labels. The watch expires after 7 days and must be renewed.
+func (g *GmailService) Watch(ctx context.Context, topicName string, labelIDs []string) (*gmail.WatchResponse, error) {
+	req := &gmail.WatchRequest{TopicName: topicName, LabelIds: labelIDs}
+	if len(labelIDs) > 0 {
+		req.LabelFilterBehavior = "INCLUDE"
+	}
+	resp, err := g.srv.Users.Watch("me", req).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to watch mailbox: %w", err)
+	}
+	return resp, nil
+}
+
+// StopWatch stops push notifications for the mailbox.
+func (g *GmailService) StopWatch(ctx context.Context) error {
+	if err := g.srv.Users.Stop("me").Context(ctx).Do(); err != nil {
+		return fmt.Errorf("unable to stop watch: %w", err)
+	}
+	return nil
+}
+
+// CurrentHistoryID returns the mailbox's latest history ID.
+func (g *GmailService) CurrentHistoryID(ctx context.Context) (uint64, error) {
+	p, err := g.srv.Users.GetProfile("me").Fields("historyId").Context(ctx).Do()
+	if err != nil {
+		return 0, fmt.Errorf("unable to get profile: %w", err)
+	}
+	return p.HistoryId, nil
+}
+
+// addedMessageIDs returns the distinct messages added in histories, in order, skipping drafts.
+func addedMessageIDs(histories []*gmail.History) []string {
+	var ids []string
+	for _, h := range histories {
+		for _, added := range h.MessagesAdded {
+			m := added.Message
+			if m == nil || slices.Contains(m.LabelIds, "DRAFT") || slices.Contains(ids, m.Id) {
+				continue
+			}
+			ids = append(ids, m.Id)
+		}
+	}
+	return ids
+}
+
+// MessagesAddedSince lists the IDs of messages added (optionally with labelID) after startHistoryID,
+// and the history ID to continue from.
+func (g *GmailService) MessagesAddedSince(ctx context.Context, startHistoryID uint64, labelID string) ([]string, uint64, error) {
+	call := g.srv.Users.History.List("me").StartHistoryId(startHistoryID).HistoryTypes("messageAdded")
+	if labelID != "" {
+		call = call.LabelId(labelID)
+	}
+	var histories []*gmail.History
+	latest := startHistoryID
+	err := call.Pages(ctx, func(r *gmail.ListHistoryResponse) error {
+		histories = append(histories, r.History...)
+		latest = max(latest, r.HistoryId)
+		return nil
+	})
+	if err != nil {
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+			return nil, 0, ErrHistoryExpired
+		}
+		return nil, 0, fmt.Errorf("unable to list history: %w", err)
+	}
+	return addedMessageIDs(histories), latest, nil
+}
+
+// NewMail is the result of WaitForNewMail.
+type NewMail struct {
+	Messages  []MessageSummary `json:"messages"`
+	HistoryID uint64           `json:"history_id,string"` // Pass back to continue after these messages
+	TimedOut  bool             `json:"timed_out,omitempty"`
+}
+
+// WaitForNewMail polls the mailbox history every interval until messages (optionally with labelID) arrive
+// after startHistoryID, timeout elapses or ctx is done. A zero startHistoryID starts from now.
+func (g *GmailService) WaitForNewMail(ctx context.Context, startHistoryID uint64, labelID string, timeout, interval time.Duration) (*NewMail, error) {
+	cursor := startHistoryID
+	if cursor == 0 {
+		var err error
+		if cursor, err = g.CurrentHistoryID(ctx); err != nil {
+			return nil, err
+		}
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		ids, latest, err := g.MessagesAddedSince(ctx, cursor, labelID)
+		if err != nil {
+			return nil, err
+		}
+		cursor = latest
+		if len(ids) > 0 {
+			out := &NewMail{HistoryID: cursor}
+			for _, id := range ids {
+				m, err := g.srv.Users.Messages.Get("me", id).Fields(summaryFields).Context(ctx).Do()
+				if err != nil {
+					var apiErr *googleapi.Error
+					if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+						continue // Deleted since it arrived
+					}
+					return nil, fmt.Errorf("unable to retrieve message %s: %w", id, err)
+				}
+				out.Messages = append(out.Messages, summarizeMessage(m))
+			}
+			if len(out.Messages) > 0 {
+				return out, nil
+			}
+		}
+		wait := min(interval, time.Until(deadline))
+		if wait <= 0 {
+			return &NewMail{HistoryID: cursor, TimedOut: true}, nil
+		}
+		select {
+		case <-ctx.Done():
+			return &NewMail{HistoryID: cursor, TimedOut: true}, nil
+		case <-time.After(wait):
+		}
+	}
+}
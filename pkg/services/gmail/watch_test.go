@@ -0,0 +1,23 @@
+package gmail
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+func TestAddedMessageIDs(t *testing.T) {
+	added := func(id string, labels ...string) *gmail.HistoryMessageAdded {
+		return &gmail.HistoryMessageAdded{Message: &gmail.Message{Id: id, LabelIds: labels}}
+	}
+	histories := []*gmail.History{
+		{Id: 10, MessagesAdded: []*gmail.HistoryMessageAdded{added("a", "INBOX", "UNREAD"), added("d", "DRAFT")}},
+		{Id: 11},
+		{Id: 12, MessagesAdded: []*gmail.HistoryMessageAdded{added("b", "INBOX"), added("a", "INBOX"), {}}},
+	}
+	want := []string{"a", "b"}
+	if got := addedMessageIDs(histories); !reflect.DeepEqual(got, want) {
+		t.Errorf("addedMessageIDs = %v, want %v", got, want)
+	}
+}
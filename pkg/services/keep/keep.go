@@ -22,6 +22,14 @@ func New(ctx context.Context, opts ...option.ClientOption) (*Service, error) {
 	return &Service{srv: srv}, nil
 }
 
+// Ping makes a cheap request to check that the API is enabled and its scope granted.
+func (s *Service) Ping(ctx context.Context) error {
+	if _, err := s.srv.Notes.List().PageSize(1).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("unable to list notes: %w", err)
+	}
+	return nil
+}
+
 // ListNotesOptions configures list behavior.
 type ListNotesOptions struct {
 	PageSize  int64 // Max notes per page (0 = server default)
@@ -30,7 +38,7 @@ type ListNotesOptions struct {
 }
 
 // ListNotes lists notes. Use filter "trashed = false" to exclude trashed.
-func (s *Service) ListNotes(opts ListNotesOptions) (*keep.ListNotesResponse, error) {
+func (s *Service) ListNotes(ctx context.Context, opts ListNotesOptions) (*keep.ListNotesResponse, error) {
 	call := s.srv.Notes.List()
 	if opts.PageSize > 0 {
 		call = call.PageSize(opts.PageSize)
@@ -41,12 +49,12 @@ func (s *Service) ListNotes(opts ListNotesOptions) (*keep.ListNotesResponse, err
 	if opts.Filter != "" {
 		call = call.Filter(opts.Filter)
 	}
-	return call.Do()
+	return call.Context(ctx).Do()
 }
 
 // CreateNote creates a new note. Body can be text-only, list-only, or nil.
 // For list notes, pass listItems; each item can have text and checked.
-func (s *Service) CreateNote(title string, bodyText string, listItems []*keep.ListItem) (*keep.Note, error) {
+func (s *Service) CreateNote(ctx context.Context, title string, bodyText string, listItems []*keep.ListItem) (*keep.Note, error) {
 	note := &keep.Note{Title: title}
 	if bodyText != "" {
 		note.Body = &keep.Section{
@@ -57,29 +65,29 @@ func (s *Service) CreateNote(title string, bodyText string, listItems []*keep.Li
 			List: &keep.ListContent{ListItems: listItems},
 		}
 	}
-	return s.srv.Notes.Create(note).Do()
+	return s.srv.Notes.Create(note).Context(ctx).Do()
 }
 
 // GetNote returns a note by name (e.g. "notes/abc123" or id "abc123").
-func (s *Service) GetNote(name string) (*keep.Note, error) {
+func (s *Service) GetNote(ctx context.Context, name string) (*keep.Note, error) {
 	if name == "" {
 		return nil, fmt.Errorf("note name is required")
 	}
 	if len(name) < 6 || name[:6] != "notes/" {
 		name = "notes/" + name
 	}
-	return s.srv.Notes.Get(name).Do()
+	return s.srv.Notes.Get(name).Context(ctx).Do()
 }
 
 // DeleteNote deletes a note by name. Caller must be owner.
-func (s *Service) DeleteNote(name string) error {
+func (s *Service) DeleteNote(ctx context.Context, name string) error {
 	if name == "" {
 		return fmt.Errorf("note name is required")
 	}
 	if len(name) < 6 || name[:6] != "notes/" {
 		name = "notes/" + name
 	}
-	_, err := s.srv.Notes.Delete(name).Do()
+	_, err := s.srv.Notes.Delete(name).Context(ctx).Do()
 	return err
 }
 
@@ -92,8 +100,8 @@ type UpdateNoteInput struct {
 }
 
 // UpdateNote "edits" a note by creating a new note with merged content and deleting the old one. Returns the new note (new name/id).
-func (s *Service) UpdateNote(name string, in UpdateNoteInput) (*keep.Note, error) {
-	existing, err := s.GetNote(name)
+func (s *Service) UpdateNote(ctx context.Context, name string, in UpdateNoteInput) (*keep.Note, error) {
+	existing, err := s.GetNote(ctx, name)
 	if err != nil {
 		return nil, fmt.Errorf("get note: %w", err)
 	}
@@ -110,11 +118,11 @@ func (s *Service) UpdateNote(name string, in UpdateNoteInput) (*keep.Note, error
 		body = existing.Body
 	}
 	newNote := &keep.Note{Title: title, Body: body}
-	created, err := s.srv.Notes.Create(newNote).Do()
+	created, err := s.srv.Notes.Create(newNote).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("create updated note: %w", err)
 	}
-	if err := s.DeleteNote(name); err != nil {
+	if err := s.DeleteNote(ctx, name); err != nil {
 		return nil, fmt.Errorf("delete old note (new note %s was created): %w", created.Name, err)
 	}
 	return created, nil
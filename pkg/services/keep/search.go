@@ -0,0 +1,127 @@
+package keep
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"google.golang.org/api/keep/v1"
+)
+
+// NoteText returns the searchable text of a note: its title, body text and list items (including nested ones).
+func NoteText(n *keep.Note) string {
+	parts := []string{n.Title}
+	if n.Body != nil {
+		if n.Body.Text != nil {
+			parts = append(parts, n.Body.Text.Text)
+		}
+		if n.Body.List != nil {
+			var walk func([]*keep.ListItem)
+			walk = func(items []*keep.ListItem) {
+				for _, li := range items {
+					if li.Text != nil {
+						parts = append(parts, li.Text.Text)
+					}
+					walk(li.ChildListItems)
+				}
+			}
+			walk(n.Body.List.ListItems)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// matchesNote reports whether every word of query appears in the note's text, ignoring case.
+func matchesNote(n *keep.Note, query string) bool {
+	text := strings.ToLower(NoteText(n))
+	for _, w := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(text, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// SearchNotesOptions configures SearchNotes.
+type SearchNotesOptions struct {
+	Query     string // Words that must all appear in the title, body or list items (case-insensitive)
+	Filter    string // API filter applied before matching, e.g. "trashed = false"
+	Limit     int    // Max matches to return (default 20)
+	PageToken string // Resume scanning from a previous NextPageToken
+	MaxPages  int    // Max pages of 100 notes to scan per call (default 10)
+}
+
+// SearchNotesResult holds the matching notes and, when scanning stopped early, the token to resume from.
+type SearchNotesResult struct {
+	Notes         []*keep.Note
+	Scanned       int
+	NextPageToken string
+}
+
+// SearchNotes scans notes page by page and keeps those matching the query, since the API filter
+// cannot match note content.
+func (s *Service) SearchNotes(ctx context.Context, opts SearchNotesOptions) (*SearchNotesResult, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = 20
+	}
+	if opts.MaxPages <= 0 {
+		opts.MaxPages = 10
+	}
+	out := &SearchNotesResult{}
+	token := opts.PageToken
+	for page := 0; page < opts.MaxPages; page++ {
+		resp, err := s.ListNotes(ctx, ListNotesOptions{PageSize: 100, PageToken: token, Filter: opts.Filter})
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range resp.Notes {
+			out.Scanned++
+			if matchesNote(n, opts.Query) {
+				out.Notes = append(out.Notes, n)
+			}
+		}
+		token = resp.NextPageToken
+		// Stop at a page boundary so the token resumes without skipping notes.
+		if token == "" || len(out.Notes) >= opts.Limit {
+			break
+		}
+	}
+	out.NextPageToken = token
+	return out, nil
+}
+
+// DownloadAttachment downloads an attachment by resource name (notes/<note>/attachments/<attachment>).
+// An empty mimeType uses the first format the attachment is available in.
+func (s *Service) DownloadAttachment(ctx context.Context, name string, mimeType string) ([]byte, string, error) {
+	noteName, _, ok := strings.Cut(name, "/attachments/")
+	if !ok {
+		return nil, "", fmt.Errorf("invalid attachment name %q, expected notes/<note>/attachments/<attachment>", name)
+	}
+	if mimeType == "" {
+		note, err := s.GetNote(ctx, noteName)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, a := range note.Attachments {
+			if a.Name == name && len(a.MimeType) > 0 {
+				mimeType = a.MimeType[0]
+			}
+		}
+		if mimeType == "" {
+			return nil, "", fmt.Errorf("attachment %s not found on note %s", name, noteName)
+		}
+	}
+	resp, err := s.srv.Media.Download(name).MimeType(mimeType).Context(ctx).Download()
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to download attachment: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to read attachment: %w", err)
+	}
+	return data, mimeType, nil
+}
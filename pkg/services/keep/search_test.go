@@ -0,0 +1,33 @@
+package keep
+
+import (
+	"testing"
+
+	"google.golang.org/api/keep/v1"
+)
+
+func TestMatchesNote(t *testing.T) {
+	text := &keep.Note{Title: "Trip plan", Body: &keep.Section{Text: &keep.TextContent{Text: "Book hotel in Lisbon"}}}
+	list := &keep.Note{Title: "Groceries", Body: &keep.Section{List: &keep.ListContent{ListItems: []*keep.ListItem{
+		{Text: &keep.TextContent{Text: "Milk"}},
+		{Text: &keep.TextContent{Text: "Fruit"}, ChildListItems: []*keep.ListItem{{Text: &keep.TextContent{Text: "Bananas"}}}},
+	}}}}
+	tests := []struct {
+		note  *keep.Note
+		query string
+		want  bool
+	}{
+		{text, "lisbon", true},
+		{text, "TRIP hotel", true},
+		{text, "trip paris", false},
+		{list, "bananas", true},
+		{list, "groceries milk", true},
+		{list, "", true},
+		{&keep.Note{}, "x", false},
+	}
+	for _, tt := range tests {
+		if got := matchesNote(tt.note, tt.query); got != tt.want {
+			t.Errorf("matchesNote(%q, %q) = %v, want %v", tt.note.Title, tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	driveapi "google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+	sheetsapi "google.golang.org/api/sheets/v4"
+)
+
+// SpreadsheetTitle is the title of the spreadsheet the entries are kept in, in the account's Drive.
+const SpreadsheetTitle = "go-google-mcp memory"
+
+// The memory spreadsheet is found by this app property rather than its title, so renaming or moving it
+// does not lose the entries.
+const (
+	appPropertyKey   = "go-google-mcp"
+	appPropertyValue = "memory"
+)
+
+// entriesRange holds the entries: key, value and time of the last update, below a header row. Without a
+// tab name it refers to the first tab, whatever its name in the user's locale.
+const entriesRange = "A:C"
+
+var header = []interface{}{"key", "value", "updated"}
+
+// Limits of an entry: Sheets cells hold at most 50,000 characters.
+const (
+	maxKeyLength   = 256
+	maxValueLength = 50_000
+)
+
+// Service is a key-value store for agents, kept in a spreadsheet in the user's Drive so that it lasts
+// across sessions and machines. Each account (or impersonated user) has its own spreadsheet, created on
+// first write.
+type Service struct {
+	sheets *sheetsapi.Service
+	drive  *driveapi.Service
+	mu     sync.Mutex // Serializes writes, so that concurrent calls neither create two spreadsheets nor duplicate a key
+}
+
+// New creates a new Service.
+func New(ctx context.Context, opts ...option.ClientOption) (*Service, error) {
+	sheetsSrv, err := sheetsapi.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve Sheets client: %w", err)
+	}
+	driveSrv, err := driveapi.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve Drive client: %w", err)
+	}
+	return &Service{sheets: sheetsSrv, drive: driveSrv}, nil
+}
+
+// Ping makes a cheap request to check that the API is enabled and its scope granted.
+func (s *Service) Ping(ctx context.Context) error {
+	if _, err := s.find(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Entry is a stored key-value pair.
+type Entry struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Updated string `json:"updated"` // RFC3339
+	row     int    // 1-based spreadsheet row
+}
+
+// Get returns the entry stored under key, or nil if there is none.
+func (s *Service) Get(ctx context.Context, key string) (*Entry, error) {
+	entries, err := s.entries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if i := slices.IndexFunc(entries, func(e Entry) bool { return e.Key == key }); i >= 0 {
+		return &entries[i], nil
+	}
+	return nil, nil
+}
+
+// List returns the entries whose key starts with prefix, sorted by key.
+func (s *Service) List(ctx context.Context, prefix string) ([]Entry, error) {
+	entries, err := s.entries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries = slices.DeleteFunc(entries, func(e Entry) bool { return !strings.HasPrefix(e.Key, prefix) })
+	slices.SortFunc(entries, func(a, b Entry) int { return strings.Compare(a.Key, b.Key) })
+	return entries, nil
+}
+
+// Set stores value under key, replacing any previous value, and reports whether the key is new.
+func (s *Service) Set(ctx context.Context, key string, value string) (bool, error) {
+	if err := validate(key, value); err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, err := s.find(ctx)
+	if err != nil {
+		return false, err
+	}
+	if id == "" {
+		if id, err = s.create(ctx); err != nil {
+			return false, err
+		}
+	}
+	rows, err := s.readRows(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	rows, fixes := repairHeader(rows)
+	for _, r := range slices.Sorted(maps.Keys(fixes)) {
+		if err := s.write(ctx, id, fmt.Sprintf("A%d:C%d", r, r), fixes[r]); err != nil {
+			return false, err
+		}
+	}
+	// New keys go below the last row rather than being appended: append looks for a table, which rows
+	// edited by hand could make it find elsewhere.
+	target, created := len(rows)+1, true
+	entries := parseEntries(rows)
+	if i := slices.IndexFunc(entries, func(e Entry) bool { return e.Key == key }); i >= 0 {
+		target, created = entries[i].row, false
+	}
+	row := []interface{}{key, value, time.Now().UTC().Format(time.RFC3339)}
+	if err := s.write(ctx, id, fmt.Sprintf("A%d:C%d", target, target), row); err != nil {
+		return false, err
+	}
+	return created, nil
+}
+
+// validate checks that an entry fits the limits of a spreadsheet cell.
+func validate(key, value string) error {
+	if strings.TrimSpace(key) == "" {
+		return fmt.Errorf("key is required")
+	}
+	if utf8.RuneCountInString(key) > maxKeyLength {
+		return fmt.Errorf("key is longer than %d characters", maxKeyLength)
+	}
+	if utf8.RuneCountInString(value) > maxValueLength {
+		return fmt.Errorf("value is longer than %d characters", maxValueLength)
+	}
+	return nil
+}
+
+// entries reads the stored entries; there are none before the spreadsheet is created by the first Set.
+func (s *Service) entries(ctx context.Context) ([]Entry, error) {
+	id, err := s.find(ctx)
+	if err != nil || id == "" {
+		return nil, err
+	}
+	rows, err := s.readRows(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return parseEntries(rows), nil
+}
+
+// find returns the ID of the memory spreadsheet, or "" if it does not exist yet.
+func (s *Service) find(ctx context.Context) (string, error) {
+	q := fmt.Sprintf("appProperties has { key='%s' and value='%s' } and trashed = false", appPropertyKey, appPropertyValue)
+	r, err := s.drive.Files.List().Q(q).Spaces("drive").OrderBy("createdTime").PageSize(1).Fields("files(id)").Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("unable to find the memory spreadsheet: %w", err)
+	}
+	if len(r.Files) == 0 {
+		return "", nil
+	}
+	return r.Files[0].Id, nil
+}
+
+// create creates the memory spreadsheet with its header row.
+func (s *Service) create(ctx context.Context) (string, error) {
+	f, err := s.drive.Files.Create(&driveapi.File{
+		Name:          SpreadsheetTitle,
+		MimeType:      "application/vnd.google-apps.spreadsheet",
+		AppProperties: map[string]string{appPropertyKey: appPropertyValue},
+	}).Fields("id").Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("unable to create the memory spreadsheet: %w", err)
+	}
+	if err := s.write(ctx, f.Id, "A1:C1", header); err != nil {
+		return "", err
+	}
+	return f.Id, nil
+}
+
+func (s *Service) readRows(ctx context.Context, id string) ([][]interface{}, error) {
+	r, err := s.sheets.Spreadsheets.Values.Get(id, entriesRange).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the memory spreadsheet: %w", err)
+	}
+	return r.Values, nil
+}
+
+// write stores a row as-is: values are never parsed as numbers, dates or formulas.
+func (s *Service) write(ctx context.Context, id, rng string, row []interface{}) error {
+	vr := &sheetsapi.ValueRange{Values: [][]interface{}{row}}
+	if _, err := s.sheets.Spreadsheets.Values.Update(id, rng, vr).ValueInputOption("RAW").Context(ctx).Do(); err != nil {
+		return fmt.Errorf("unable to write to the memory spreadsheet: %w", err)
+	}
+	return nil
+}
+
+// isHeader reports whether row is the header row.
+func isHeader(row []interface{}) bool {
+	for i, h := range header {
+		if !strings.EqualFold(cell(row, i), h.(string)) {
+			return false
+		}
+	}
+	return true
+}
+
+// repairHeader restores the header as the first row when it was cleared or overwritten by hand, so that
+// the first entry is not written where parseEntries skips it. It returns the repaired rows and the rows to
+// write, by 1-based row number: the header and, if an entry was found in its place, that entry moved
+// below the other rows.
+func repairHeader(rows [][]interface{}) ([][]interface{}, map[int][]interface{}) {
+	if len(rows) > 0 && isHeader(rows[0]) {
+		return rows, nil
+	}
+	fixes := map[int][]interface{}{1: header}
+	if len(rows) == 0 {
+		return [][]interface{}{header}, fixes
+	}
+	rows = slices.Clone(rows)
+	if cell(rows[0], 0) != "" {
+		fixes[len(rows)+1] = rows[0]
+		rows = append(rows, rows[0])
+	}
+	rows[0] = header
+	return rows, fixes
+}
+
+// parseEntries turns the rows of the memory spreadsheet into entries, skipping the header and rows
+// without a key. When a key was duplicated by hand, the first row wins, as Set updates that one.
+func parseEntries(rows [][]interface{}) []Entry {
+	var entries []Entry
+	seen := make(map[string]bool)
+	for i, row := range rows {
+		if i == 0 && isHeader(row) {
+			continue
+		}
+		e := Entry{Key: cell(row, 0), Value: cell(row, 1), Updated: cell(row, 2), row: i + 1}
+		if e.Key == "" || seen[e.Key] {
+			continue
+		}
+		seen[e.Key] = true
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func cell(row []interface{}, i int) string {
+	if i >= len(row) {
+		return ""
+	}
+	return fmt.Sprint(row[i])
+}
@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseEntries(t *testing.T) {
+	rows := [][]interface{}{
+		{"key", "value", "updated"},
+		{"project", "go-google-mcp", "2025-03-01T10:00:00Z"},
+		{},
+		{"count", 42},
+		{"project", "duplicate added by hand"},
+	}
+	got := parseEntries(rows)
+	want := []Entry{
+		{Key: "project", Value: "go-google-mcp", Updated: "2025-03-01T10:00:00Z", row: 2},
+		{Key: "count", Value: "42", row: 4},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("parseEntries() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if got := parseEntries(nil); len(got) != 0 {
+		t.Errorf("parseEntries(nil) = %+v, want none", got)
+	}
+}
+
+func TestRepairHeader(t *testing.T) {
+	// A cleared sheet: the header is rewritten and the first entry goes to row 2
+	rows, fixes := repairHeader(nil)
+	if len(rows) != 1 || len(fixes) != 1 || !isHeader(fixes[1]) {
+		t.Fatalf("repairHeader(nil) = %v, %v", rows, fixes)
+	}
+	if target := len(rows) + 1; target != 2 {
+		t.Errorf("first entry written to row %d, want 2", target)
+	}
+	entries := parseEntries(append(rows, []interface{}{"project", "go-google-mcp"}))
+	if len(entries) != 1 || entries[0].row != 2 {
+		t.Errorf("entries after repair = %+v", entries)
+	}
+
+	// The header row deleted by hand: the entry now in row 1 is kept and moved below the others
+	rows = [][]interface{}{{"first", "1"}, {"second", "2"}}
+	if got := parseEntries(rows); len(got) != 2 {
+		t.Errorf("parseEntries() without a header = %+v, want both entries", got)
+	}
+	rows, fixes = repairHeader(rows)
+	if len(fixes) != 2 || !isHeader(fixes[1]) || cell(fixes[3], 0) != "first" {
+		t.Errorf("fixes = %v, want the header in row 1 and the first entry in row 3", fixes)
+	}
+	if got := parseEntries(rows); len(got) != 2 || got[0].Key != "second" || got[1].row != 3 {
+		t.Errorf("entries after repair = %+v", got)
+	}
+
+	rows = [][]interface{}{{"Key", "Value", "Updated"}, {"a", "1"}}
+	if _, fixes := repairHeader(rows); fixes != nil {
+		t.Errorf("an existing header was rewritten: %v", fixes)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		key, value string
+		valid      bool
+	}{
+		{"notes/today", "buy milk", true},
+		{"empty", "", true},
+		{"", "value", false},
+		{"  ", "value", false},
+		{strings.Repeat("k", maxKeyLength+1), "value", false},
+		{"big", strings.Repeat("v", maxValueLength+1), false},
+	}
+	for _, tt := range tests {
+		if err := validate(tt.key, tt.value); (err == nil) != tt.valid {
+			t.Errorf("validate(%.10q, %.10q) = %v, want valid %v", tt.key, tt.value, err, tt.valid)
+		}
+	}
+}
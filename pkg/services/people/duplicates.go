@@ -0,0 +1,275 @@
+package people
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+	"google.golang.org/api/people/v1"
+)
+
+// mergeFields are the person fields read and written when merging contacts.
+const mergeFields = "names,emailAddresses,phoneNumbers,organizations,birthdays,addresses,biographies,urls,metadata"
+
+// DuplicateGroup is a set of contacts that probably describe the same person.
+type DuplicateGroup struct {
+	Contacts []Contact `json:"contacts"`
+	Reasons  []string  `json:"reasons"` // e.g. "same email ana@example.com"
+}
+
+// normalizeEmail lowercases an email address.
+func normalizeEmail(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// normalizePhone keeps the last 10 digits of a phone number, so local and international forms match.
+// Numbers with fewer than 7 digits are ignored.
+func normalizePhone(s string) string {
+	var digits []rune
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			digits = append(digits, r)
+		}
+	}
+	if len(digits) < 7 {
+		return ""
+	}
+	if len(digits) > 10 {
+		digits = digits[len(digits)-10:]
+	}
+	return string(digits)
+}
+
+// normalizeName lowercases a name, strips accents and punctuation and sorts its words,
+// so "Silva, Ana" and "ana silva" match. Single-word names are ignored as too ambiguous.
+func normalizeName(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(s)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	words := strings.Fields(b.String())
+	if len(words) < 2 {
+		return ""
+	}
+	slices.Sort(words)
+	return strings.Join(words, " ")
+}
+
+// FindDuplicates clusters contacts sharing an email address, a phone number or a full name.
+// Groups are returned in the order of their first contact.
+func FindDuplicates(persons []*people.Person) []DuplicateGroup {
+	parent := make([]int, len(persons))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	type key struct{ kind, value string }
+	first := map[key]int{}
+	reasons := map[key]bool{}
+	link := func(i int, k key) {
+		if k.value == "" {
+			return
+		}
+		if j, ok := first[k]; ok {
+			if j != i {
+				parent[find(i)] = find(j)
+				reasons[k] = true
+			}
+			return
+		}
+		first[k] = i
+	}
+	for i, p := range persons {
+		for _, e := range p.EmailAddresses {
+			link(i, key{"email", normalizeEmail(e.Value)})
+		}
+		for _, ph := range p.PhoneNumbers {
+			link(i, key{"phone", normalizePhone(ph.Value)})
+		}
+		if len(p.Names) > 0 {
+			link(i, key{"name", normalizeName(p.Names[0].DisplayName)})
+		}
+	}
+
+	members := map[int][]int{}
+	var roots []int
+	for i := range persons {
+		r := find(i)
+		if _, ok := members[r]; !ok {
+			roots = append(roots, r)
+		}
+		members[r] = append(members[r], i)
+	}
+	var groups []DuplicateGroup
+	for _, r := range roots {
+		idx := members[r]
+		if len(idx) < 2 {
+			continue
+		}
+		g := DuplicateGroup{}
+		for _, i := range idx {
+			g.Contacts = append(g.Contacts, Summarize(persons[i]))
+		}
+		for k := range reasons {
+			if find(first[k]) == r {
+				g.Reasons = append(g.Reasons, fmt.Sprintf("same %s %s", k.kind, k.value))
+			}
+		}
+		slices.Sort(g.Reasons)
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+// AllConnections lists every contact of the user with the given person field mask.
+func (p *PeopleService) AllConnections(ctx context.Context, personFields string) ([]*people.Person, error) {
+	var out []*people.Person
+	call := p.srv.People.Connections.List("people/me").PageSize(1000).PersonFields(personFields)
+	for {
+		resp, err := call.Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to list connections: %w", err)
+		}
+		out = append(out, resp.Connections...)
+		if resp.NextPageToken == "" {
+			return out, nil
+		}
+		call = call.PageToken(resp.NextPageToken)
+	}
+}
+
+// mergePersons returns keep with the emails, phones, organizations, addresses and URLs of others added
+// (skipping ones it already has), and their birthday and notes when keep has none.
+func mergePersons(keep *people.Person, others []*people.Person) *people.Person {
+	merged := &people.Person{
+		ResourceName:   keep.ResourceName,
+		Etag:           keep.Etag,
+		Names:          keep.Names,
+		EmailAddresses: slices.Clone(keep.EmailAddresses),
+		PhoneNumbers:   slices.Clone(keep.PhoneNumbers),
+		Organizations:  slices.Clone(keep.Organizations),
+		Birthdays:      keep.Birthdays,
+		Addresses:      slices.Clone(keep.Addresses),
+		Biographies:    keep.Biographies,
+		Urls:           slices.Clone(keep.Urls),
+	}
+	for _, o := range others {
+		for _, e := range o.EmailAddresses {
+			if !slices.ContainsFunc(merged.EmailAddresses, func(x *people.EmailAddress) bool { return normalizeEmail(x.Value) == normalizeEmail(e.Value) }) {
+				merged.EmailAddresses = append(merged.EmailAddresses, &people.EmailAddress{Value: e.Value, Type: e.Type})
+			}
+		}
+		for _, ph := range o.PhoneNumbers {
+			if !slices.ContainsFunc(merged.PhoneNumbers, func(x *people.PhoneNumber) bool {
+				return normalizePhone(x.Value) == normalizePhone(ph.Value) && normalizePhone(ph.Value) != "" || x.Value == ph.Value
+			}) {
+				merged.PhoneNumbers = append(merged.PhoneNumbers, &people.PhoneNumber{Value: ph.Value, Type: ph.Type})
+			}
+		}
+		for _, org := range o.Organizations {
+			if !slices.ContainsFunc(merged.Organizations, func(x *people.Organization) bool {
+				return strings.EqualFold(x.Name, org.Name) && strings.EqualFold(x.Title, org.Title)
+			}) {
+				merged.Organizations = append(merged.Organizations, &people.Organization{Name: org.Name, Title: org.Title, Department: org.Department, Type: org.Type})
+			}
+		}
+		for _, a := range o.Addresses {
+			if !slices.ContainsFunc(merged.Addresses, func(x *people.Address) bool { return strings.EqualFold(x.FormattedValue, a.FormattedValue) }) {
+				merged.Addresses = append(merged.Addresses, &people.Address{
+					StreetAddress: a.StreetAddress, ExtendedAddress: a.ExtendedAddress, City: a.City, Region: a.Region,
+					PostalCode: a.PostalCode, Country: a.Country, CountryCode: a.CountryCode, PoBox: a.PoBox,
+					FormattedValue: a.FormattedValue, Type: a.Type,
+				})
+			}
+		}
+		for _, u := range o.Urls {
+			if !slices.ContainsFunc(merged.Urls, func(x *people.Url) bool { return x.Value == u.Value }) {
+				merged.Urls = append(merged.Urls, &people.Url{Value: u.Value, Type: u.Type})
+			}
+		}
+		if len(merged.Birthdays) == 0 && len(o.Birthdays) > 0 {
+			merged.Birthdays = []*people.Birthday{{Date: o.Birthdays[0].Date, Text: o.Birthdays[0].Text}}
+		}
+		if len(merged.Biographies) == 0 && len(o.Biographies) > 0 {
+			merged.Biographies = []*people.Biography{{Value: o.Biographies[0].Value, ContentType: o.Biographies[0].ContentType}}
+		}
+	}
+	return merged
+}
+
+// contactName adds the "people/" prefix to a bare contact ID.
+func contactName(id string) string {
+	if strings.HasPrefix(id, "people/") {
+		return id
+	}
+	return "people/" + id
+}
+
+// MergeContacts consolidates the fields of others into keep. Unless dryRun, it updates keep and deletes others.
+// It returns the merged contact.
+func (p *PeopleService) MergeContacts(ctx context.Context, keep string, others []string, dryRun bool) (*people.Person, error) {
+	keep = contactName(keep)
+	names := []string{keep}
+	for _, o := range others {
+		if o = contactName(o); o != keep && !slices.Contains(names, o) {
+			names = append(names, o)
+		}
+	}
+	if len(names) < 2 {
+		return nil, fmt.Errorf("at least one other contact to merge is required")
+	}
+	resp, err := p.srv.People.GetBatchGet().ResourceNames(names...).PersonFields(mergeFields).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get contacts: %w", err)
+	}
+	byName := map[string]*people.Person{}
+	for _, r := range resp.Responses {
+		if r.Person != nil {
+			byName[r.RequestedResourceName] = r.Person
+		}
+	}
+	var rest []*people.Person
+	for _, n := range names {
+		if byName[n] == nil {
+			return nil, fmt.Errorf("contact %s not found", n)
+		}
+		if n != keep {
+			rest = append(rest, byName[n])
+		}
+	}
+
+	merged := mergePersons(byName[keep], rest)
+	if dryRun {
+		return merged, nil
+	}
+	updated, err := p.srv.People.UpdateContact(keep, merged).
+		UpdatePersonFields("emailAddresses,phoneNumbers,organizations,birthdays,addresses,biographies,urls").
+		PersonFields(mergeFields).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to update contact: %w", err)
+	}
+	for _, n := range names[1:] {
+		if _, err := p.srv.People.DeleteContact(n).Context(ctx).Do(); err != nil {
+			return nil, fmt.Errorf("merged into %s but unable to delete %s: %w", keep, n, err)
+		}
+	}
+	return updated, nil
+}
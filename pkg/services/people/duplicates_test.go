@@ -0,0 +1,91 @@
+package people
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/api/people/v1"
+)
+
+func person(id, name string, emails, phones []string) *people.Person {
+	p := &people.Person{ResourceName: "people/" + id, Names: []*people.Name{{DisplayName: name}}}
+	for _, e := range emails {
+		p.EmailAddresses = append(p.EmailAddresses, &people.EmailAddress{Value: e})
+	}
+	for _, ph := range phones {
+		p.PhoneNumbers = append(p.PhoneNumbers, &people.PhoneNumber{Value: ph})
+	}
+	return p
+}
+
+func TestNormalize(t *testing.T) {
+	if got := normalizePhone("+55 (11) 98765-4321"); got != "1987654321" {
+		t.Errorf("normalizePhone = %q", got)
+	}
+	if normalizePhone("+55 (11) 98765-4321") != normalizePhone("011 98765 4321") {
+		t.Error("international and local forms should match")
+	}
+	if got := normalizePhone("123"); got != "" {
+		t.Errorf("short numbers should be ignored, got %q", got)
+	}
+	if got := normalizeName("Silva, Ána"); got != "ana silva" {
+		t.Errorf("normalizeName = %q", got)
+	}
+	if got := normalizeName("Ana"); got != "" {
+		t.Errorf("single names should be ignored, got %q", got)
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	persons := []*people.Person{
+		person("1", "Ana Silva", []string{"ana@example.com"}, nil),
+		person("2", "Bob Stone", nil, []string{"+1 415 555 0100"}),
+		person("3", "A. Silva", []string{"ANA@example.com"}, []string{"(415) 555-0199"}),
+		person("4", "Robert Stone", nil, []string{"415-555-0100"}),
+		person("5", "Carla Dias", nil, nil),
+		person("6", "Silva Ana", nil, []string{"4155550199"}),
+	}
+	groups := FindDuplicates(persons)
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups, want 2: %+v", len(groups), groups)
+	}
+	var ids [][]string
+	for _, g := range groups {
+		var g2 []string
+		for _, c := range g.Contacts {
+			g2 = append(g2, c.ResourceName)
+		}
+		ids = append(ids, g2)
+	}
+	want := [][]string{{"people/1", "people/3", "people/6"}, {"people/2", "people/4"}}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("groups = %v, want %v", ids, want)
+	}
+	wantReasons := []string{"same email ana@example.com", "same name ana silva", "same phone 4155550199"}
+	if !reflect.DeepEqual(groups[0].Reasons, wantReasons) {
+		t.Errorf("reasons = %v, want %v", groups[0].Reasons, wantReasons)
+	}
+}
+
+func TestMergePersons(t *testing.T) {
+	keep := person("1", "Ana Silva", []string{"ana@example.com"}, []string{"+1 415 555 0100"})
+	other := person("2", "Ana S.", []string{"ANA@example.com", "ana@work.com"}, []string{"415-555-0100", "415-555-0111"})
+	other.Organizations = []*people.Organization{{Name: "Acme", Title: "CTO"}}
+	other.Birthdays = []*people.Birthday{{Date: &people.Date{Month: 3, Day: 9}}}
+
+	got := Summarize(mergePersons(keep, []*people.Person{other}))
+	want := Contact{
+		ResourceName:  "people/1",
+		Name:          "Ana Silva",
+		Emails:        []string{"ana@example.com", "ana@work.com"},
+		Phones:        []string{"+1 415 555 0100", "415-555-0111"},
+		Organizations: []string{"CTO at Acme"},
+		Birthday:      "--03-09",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("merged = %+v, want %+v", got, want)
+	}
+	if len(keep.EmailAddresses) != 1 {
+		t.Error("mergePersons must not modify keep")
+	}
+}
@@ -0,0 +1,157 @@
+package people
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/api/people/v1"
+)
+
+// DefaultPersonFields is the person field mask used when no fields are requested.
+const DefaultPersonFields = "names,emailAddresses"
+
+// fieldAliases maps friendly field names to People API person fields.
+var fieldAliases = map[string]string{
+	"name": "names", "names": "names",
+	"email": "emailAddresses", "emails": "emailAddresses", "emailaddresses": "emailAddresses",
+	"phone": "phoneNumbers", "phones": "phoneNumbers", "phonenumbers": "phoneNumbers",
+	"organization": "organizations", "organizations": "organizations", "company": "organizations",
+	"birthday": "birthdays", "birthdays": "birthdays",
+	"photo": "photos", "photos": "photos",
+	"address": "addresses", "addresses": "addresses",
+	"notes": "biographies", "biography": "biographies", "biographies": "biographies",
+	"url": "urls", "urls": "urls",
+}
+
+// allFields are the fields requested by "all".
+var allFields = []string{"names", "emailAddresses", "phoneNumbers", "organizations", "birthdays", "photos", "addresses", "biographies", "urls"}
+
+// PersonFields builds a person field mask from a comma-separated list of friendly or API field names
+// (e.g. "phones,organizations" or "all"). Names are always included; empty means DefaultPersonFields.
+func PersonFields(fields string) (string, error) {
+	if strings.TrimSpace(fields) == "" {
+		return DefaultPersonFields, nil
+	}
+	out := []string{"names"}
+	for _, f := range strings.Split(fields, ",") {
+		key := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(f), "_", ""))
+		if key == "" {
+			continue
+		}
+		if key == "all" {
+			return strings.Join(allFields, ","), nil
+		}
+		api, ok := fieldAliases[key]
+		if !ok {
+			return "", fmt.Errorf("unknown contact field %q (use name, email, phone, organization, birthday, photo, address, notes, url or all)", f)
+		}
+		if !slices.Contains(out, api) {
+			out = append(out, api)
+		}
+	}
+	return strings.Join(out, ","), nil
+}
+
+// Contact is a flattened view of a person with the fields that were requested.
+type Contact struct {
+	ResourceName  string   `json:"resource_name"`
+	Name          string   `json:"name"`
+	Emails        []string `json:"emails,omitempty"`
+	Phones        []string `json:"phones,omitempty"`
+	Organizations []string `json:"organizations,omitempty"`
+	Birthday      string   `json:"birthday,omitempty"`
+	PhotoURL      string   `json:"photo_url,omitempty"`
+	Addresses     []string `json:"addresses,omitempty"`
+	Notes         string   `json:"notes,omitempty"`
+	URLs          []string `json:"urls,omitempty"`
+}
+
+// Summarize flattens a person into a Contact.
+func Summarize(p *people.Person) Contact {
+	c := Contact{ResourceName: p.ResourceName}
+	if len(p.Names) > 0 {
+		c.Name = p.Names[0].DisplayName
+	}
+	for _, e := range p.EmailAddresses {
+		c.Emails = append(c.Emails, e.Value)
+	}
+	for _, ph := range p.PhoneNumbers {
+		c.Phones = append(c.Phones, withType(ph.Value, ph.FormattedType))
+	}
+	for _, o := range p.Organizations {
+		org := o.Name
+		if o.Title != "" {
+			org = strings.TrimSuffix(o.Title+" at "+o.Name, " at ")
+		}
+		if org != "" {
+			c.Organizations = append(c.Organizations, org)
+		}
+	}
+	for _, b := range p.Birthdays {
+		if b.Date != nil {
+			c.Birthday = formatDate(b.Date)
+		} else {
+			c.Birthday = b.Text
+		}
+		if c.Birthday != "" {
+			break
+		}
+	}
+	for _, ph := range p.Photos {
+		if !ph.Default {
+			c.PhotoURL = ph.Url
+			break
+		}
+	}
+	for _, a := range p.Addresses {
+		if v := strings.ReplaceAll(a.FormattedValue, "\n", ", "); v != "" {
+			c.Addresses = append(c.Addresses, withType(v, a.FormattedType))
+		}
+	}
+	if len(p.Biographies) > 0 {
+		c.Notes = p.Biographies[0].Value
+	}
+	for _, u := range p.Urls {
+		c.URLs = append(c.URLs, u.Value)
+	}
+	return c
+}
+
+func withType(value, typ string) string {
+	if typ == "" {
+		return value
+	}
+	return value + " (" + typ + ")"
+}
+
+// formatDate renders a People date as YYYY-MM-DD, or --MM-DD when the year is unknown.
+func formatDate(d *people.Date) string {
+	if d.Year == 0 {
+		return fmt.Sprintf("--%02d-%02d", d.Month, d.Day)
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// FormatContact renders a contact as one line, including only the fields it has.
+func FormatContact(c Contact) string {
+	name := c.Name
+	if name == "" {
+		name = "Unknown"
+	}
+	parts := []string{"Name: " + name, "Email: " + strings.Join(c.Emails, ", ")}
+	add := func(label string, values ...string) {
+		if v := strings.Join(slices.DeleteFunc(values, func(s string) bool { return s == "" }), "; "); v != "" {
+			parts = append(parts, label+": "+v)
+		}
+	}
+	add("Phone", c.Phones...)
+	add("Organization", c.Organizations...)
+	add("Birthday", c.Birthday)
+	add("Address", c.Addresses...)
+	add("Notes", strings.ReplaceAll(c.Notes, "\n", " "))
+	add("URL", c.URLs...)
+	add("Photo", c.PhotoURL)
+	parts = append(parts, "ResourceName: "+c.ResourceName)
+	return strings.Join(parts, " | ")
+}
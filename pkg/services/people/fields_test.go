@@ -0,0 +1,63 @@
+package people
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/api/people/v1"
+)
+
+func TestPersonFields(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{"", DefaultPersonFields, false},
+		{"phones, organizations", "names,phoneNumbers,organizations", false},
+		{"email,emailAddresses,Phone_Numbers", "names,emailAddresses,phoneNumbers", false},
+		{"birthday,photo,address", "names,birthdays,photos,addresses", false},
+		{"all", "names,emailAddresses,phoneNumbers,organizations,birthdays,photos,addresses,biographies,urls", false},
+		{"shoe_size", "", true},
+	}
+	for _, tt := range tests {
+		got, err := PersonFields(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("PersonFields(%q) = %q, %v; want %q (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestSummarizeAndFormat(t *testing.T) {
+	p := &people.Person{
+		ResourceName:   "people/c1",
+		Names:          []*people.Name{{DisplayName: "Ana Silva"}},
+		EmailAddresses: []*people.EmailAddress{{Value: "ana@example.com"}},
+		PhoneNumbers:   []*people.PhoneNumber{{Value: "+55 11 5555-0000", FormattedType: "Mobile"}},
+		Organizations:  []*people.Organization{{Name: "Acme", Title: "CTO"}, {Name: "Side Co"}},
+		Birthdays:      []*people.Birthday{{Date: &people.Date{Month: 3, Day: 9}}},
+		Photos:         []*people.Photo{{Url: "https://x/default", Default: true}, {Url: "https://x/me"}},
+		Addresses:      []*people.Address{{FormattedValue: "Rua A, 1\nSão Paulo", FormattedType: "Home"}},
+	}
+	want := Contact{
+		ResourceName:  "people/c1",
+		Name:          "Ana Silva",
+		Emails:        []string{"ana@example.com"},
+		Phones:        []string{"+55 11 5555-0000 (Mobile)"},
+		Organizations: []string{"CTO at Acme", "Side Co"},
+		Birthday:      "--03-09",
+		PhotoURL:      "https://x/me",
+		Addresses:     []string{"Rua A, 1, São Paulo (Home)"},
+	}
+	got := Summarize(p)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Summarize = %+v, want %+v", got, want)
+	}
+	line := "Name: Ana Silva | Email: ana@example.com | Phone: +55 11 5555-0000 (Mobile) | Organization: CTO at Acme; Side Co | " +
+		"Birthday: --03-09 | Address: Rua A, 1, São Paulo (Home) | Photo: https://x/me | ResourceName: people/c1"
+	if s := FormatContact(got); s != line {
+		t.Errorf("FormatContact = %q\nwant %q", s, line)
+	}
+	if s := FormatContact(Contact{ResourceName: "people/c2"}); s != "Name: Unknown | Email:  | ResourceName: people/c2" {
+		t.Errorf("FormatContact(empty) = %q", s)
+	}
+}
@@ -0,0 +1,193 @@
+package people
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/api/people/v1"
+)
+
+// How imported contacts that match an existing contact (same email, phone or full name) are handled.
+const (
+	DedupeNone  = "none"  // Create them anyway
+	DedupeSkip  = "skip"  // Leave them out
+	DedupeMerge = "merge" // Add their emails, phones and other fields to the existing contact
+)
+
+// createBatchSize is the most contacts the API creates per request.
+const createBatchSize = 200
+
+// ImportResult describes what ImportContacts did, or would do on a dry run.
+type ImportResult struct {
+	Created []Contact `json:"created"`
+	Merged  []Contact `json:"merged,omitempty"`  // Existing contacts updated with imported fields, as merged
+	Skipped []string  `json:"skipped,omitempty"` // Imported contacts left out, with the reason
+}
+
+// importMatch is the contact an imported one duplicates: an existing contact, or one created earlier in
+// the same import.
+type importMatch struct {
+	person   *people.Person
+	existing bool
+}
+
+// ImportContacts creates contacts from persons parsed by ParseContacts. Imported contacts duplicating an
+// existing contact, or an earlier one of the same import, are handled according to dedupe. With dryRun
+// nothing is written and the result shows what would be.
+func (p *PeopleService) ImportContacts(ctx context.Context, persons []*people.Person, dedupe string, dryRun bool) (*ImportResult, error) {
+	switch dedupe {
+	case "":
+		dedupe = DedupeSkip
+	case DedupeNone, DedupeSkip, DedupeMerge:
+	default:
+		return nil, fmt.Errorf("unknown dedupe mode %q (use none, skip or merge)", dedupe)
+	}
+
+	var existing []*people.Person
+	if dedupe != DedupeNone {
+		var err error
+		if existing, err = p.AllConnections(ctx, mergeFields); err != nil {
+			return nil, err
+		}
+	}
+	creates, updates, skipped := planImport(existing, persons, dedupe)
+
+	res := &ImportResult{Skipped: skipped}
+	for _, u := range updates {
+		if !dryRun {
+			updated, err := p.srv.People.UpdateContact(u.person.ResourceName, u.person).
+				UpdatePersonFields("emailAddresses,phoneNumbers,organizations,birthdays,addresses,biographies,urls").
+				PersonFields(mergeFields).
+				Context(ctx).
+				Do()
+			if err != nil {
+				return res, fmt.Errorf("unable to update contact %s: %w", u.person.ResourceName, err)
+			}
+			u.person = updated
+		}
+		res.Merged = append(res.Merged, summarizeImported(u.person))
+	}
+	for start := 0; start < len(creates); start += createBatchSize {
+		batch := creates[start:min(start+createBatchSize, len(creates))]
+		if dryRun {
+			for _, c := range batch {
+				res.Created = append(res.Created, summarizeImported(c.person))
+			}
+			continue
+		}
+		req := &people.BatchCreateContactsRequest{ReadMask: DefaultPersonFields}
+		for _, c := range batch {
+			req.Contacts = append(req.Contacts, &people.ContactToCreate{ContactPerson: c.person})
+		}
+		resp, err := p.srv.People.BatchCreateContacts(req).Context(ctx).Do()
+		if err != nil {
+			return res, fmt.Errorf("unable to create contacts (%d created so far): %w", len(res.Created), err)
+		}
+		for _, r := range resp.CreatedPeople {
+			if r.Person != nil {
+				res.Created = append(res.Created, Summarize(r.Person))
+			}
+		}
+	}
+	return res, nil
+}
+
+// planImport decides which imported persons are created, which existing contacts are updated with the
+// fields of their duplicates, merged in, and which imported persons are skipped, with the reason.
+func planImport(existing, persons []*people.Person, dedupe string) (creates, updates []*importMatch, skipped []string) {
+	index := map[matchKey]*importMatch{}
+	for _, e := range existing {
+		m := &importMatch{person: e, existing: true}
+		for _, k := range matchKeys(e) {
+			if index[k] == nil {
+				index[k] = m
+			}
+		}
+	}
+	for _, in := range persons {
+		var match *importMatch
+		var reason matchKey
+		if dedupe != DedupeNone {
+			for _, k := range matchKeys(in) {
+				if match = index[k]; match != nil {
+					reason = k
+					break
+				}
+			}
+		}
+		switch {
+		case match == nil:
+			match = &importMatch{person: in}
+			creates = append(creates, match)
+		case dedupe == DedupeSkip:
+			skipped = append(skipped, fmt.Sprintf("%s: same %s %s as %s", label(in), reason.kind, reason.value, label(match.person)))
+			continue
+		default:
+			if match.existing && !slices.Contains(updates, match) {
+				updates = append(updates, match)
+			}
+			match.person = mergePersons(match.person, []*people.Person{in})
+		}
+		for _, k := range matchKeys(in) {
+			if index[k] == nil {
+				index[k] = match
+			}
+		}
+	}
+	return creates, updates, skipped
+}
+
+// matchKey is a normalized email, phone or full name identifying a contact.
+type matchKey struct{ kind, value string }
+
+// matchKeys returns the keys a contact is matched by, as used by FindDuplicates.
+func matchKeys(p *people.Person) []matchKey {
+	var keys []matchKey
+	add := func(kind, value string) {
+		if value != "" {
+			keys = append(keys, matchKey{kind, value})
+		}
+	}
+	for _, e := range p.EmailAddresses {
+		add("email", normalizeEmail(e.Value))
+	}
+	for _, ph := range p.PhoneNumbers {
+		add("phone", normalizePhone(ph.Value))
+	}
+	add("name", normalizeName(personName(p)))
+	return keys
+}
+
+// personName returns the name of a contact, whether read from the API or parsed from a file.
+func personName(p *people.Person) string {
+	if len(p.Names) == 0 {
+		return ""
+	}
+	n := p.Names[0]
+	return cmp.Or(n.DisplayName, n.UnstructuredName, strings.TrimSpace(n.GivenName+" "+n.FamilyName))
+}
+
+// label names a contact in messages: its name, or else its first email or phone.
+func label(p *people.Person) string {
+	switch {
+	case personName(p) != "":
+		return personName(p)
+	case len(p.EmailAddresses) > 0:
+		return p.EmailAddresses[0].Value
+	case len(p.PhoneNumbers) > 0:
+		return p.PhoneNumbers[0].Value
+	}
+	return "(no name)"
+}
+
+// summarizeImported is Summarize for contacts parsed from a file, which have no display name yet.
+func summarizeImported(p *people.Person) Contact {
+	c := Summarize(p)
+	if c.Name == "" {
+		c.Name = personName(p)
+	}
+	return c
+}
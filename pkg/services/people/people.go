@@ -3,6 +3,7 @@ package people
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"google.golang.org/api/option"
 	"google.golang.org/api/people/v1"
@@ -10,7 +11,8 @@ import (
 
 // PeopleService wraps the Google People API.
 type PeopleService struct {
-	srv *people.Service
+	srv    *people.Service
+	warmup sync.Once
 }
 
 // New creates a new PeopleService.
@@ -22,8 +24,16 @@ func New(ctx context.Context, opts ...option.ClientOption) (*PeopleService, erro
 	return &PeopleService{srv: srv}, nil
 }
 
+// Ping makes a cheap request to check that the API is enabled and its scope granted.
+func (p *PeopleService) Ping(ctx context.Context) error {
+	if _, err := p.srv.ContactGroups.List().PageSize(1).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("unable to list contact groups: %w", err)
+	}
+	return nil
+}
+
 // CreateContact creates a new contact.
-func (p *PeopleService) CreateContact(givenName string, familyName string, email string) (*people.Person, error) {
+func (p *PeopleService) CreateContact(ctx context.Context, givenName string, familyName string, email string) (*people.Person, error) {
 	contact := &people.Person{
 		Names: []*people.Name{
 			{
@@ -40,24 +50,30 @@ func (p *PeopleService) CreateContact(givenName string, familyName string, email
 		}
 	}
 
-	resp, err := p.srv.People.CreateContact(contact).Do()
+	resp, err := p.srv.People.CreateContact(contact).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to create contact: %w", err)
 	}
 	return resp, nil
 }
 
-// SearchContacts searches for contacts.
-func (p *PeopleService) SearchContacts(query string) ([]*people.Person, error) {
-	// People API search is a bit complex.
-	// Simplest is SearchContacts method if enabled, or listing "people/me" and filtering.
-	// Let's use SearchContacts.
+// SearchContacts searches contacts by name, email, phone or organization prefix.
+// personFields is a field mask as built by PersonFields; limit is capped at 30 by the API.
+func (p *PeopleService) SearchContacts(ctx context.Context, query string, personFields string, limit int64) ([]*people.Person, error) {
+	if limit <= 0 || limit > 30 {
+		limit = 30
+	}
+	// The API serves searches from a cache that an empty query warms up; without it, the first results can be empty.
+	p.warmup.Do(func() {
+		_, _ = p.srv.People.SearchContacts().Query("").ReadMask("names").Context(ctx).Do()
+	})
 
-	call := p.srv.People.SearchContacts().
+	resp, err := p.srv.People.SearchContacts().
 		Query(query).
-		ReadMask("names,emailAddresses")
-
-	resp, err := call.Do()
+		ReadMask(personFields).
+		PageSize(limit).
+		Context(ctx).
+		Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to search contacts: %w", err)
 	}
@@ -71,17 +87,43 @@ func (p *PeopleService) SearchContacts(query string) ([]*people.Person, error) {
 	return results, nil
 }
 
-// ListConnections lists the authenticated user's contacts.
-func (p *PeopleService) ListConnections(limit int64) ([]*people.Person, error) {
+// ListConnections lists the authenticated user's contacts with the given person field mask.
+func (p *PeopleService) ListConnections(ctx context.Context, limit int64, personFields string) ([]*people.Person, error) {
 	if limit <= 0 {
 		limit = 10
 	}
 	resp, err := p.srv.People.Connections.List("people/me").
 		PageSize(limit).
-		PersonFields("names,emailAddresses").
+		PersonFields(personFields).
+		Context(ctx).
 		Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to list connections: %w", err)
 	}
 	return resp.Connections, nil
 }
+
+// ResolvePeople looks up person IDs ("people/...", e.g. Drive activity actors) and returns each one's
+// primary email, or display name when no email is visible. IDs that cannot be resolved are left out.
+func (p *PeopleService) ResolvePeople(ctx context.Context, resourceNames []string) (map[string]string, error) {
+	out := map[string]string{}
+	for start := 0; start < len(resourceNames); start += 200 {
+		batch := resourceNames[start:min(start+200, len(resourceNames))]
+		resp, err := p.srv.People.GetBatchGet().ResourceNames(batch...).PersonFields("names,emailAddresses").Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve people: %w", err)
+		}
+		for _, r := range resp.Responses {
+			if r.Person == nil {
+				continue
+			}
+			c := Summarize(r.Person)
+			if len(c.Emails) > 0 {
+				out[r.RequestedResourceName] = c.Emails[0]
+			} else if c.Name != "" {
+				out[r.RequestedResourceName] = c.Name
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,534 @@
+package people
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/api/people/v1"
+)
+
+// Contact file formats.
+const (
+	FormatCSV   = "csv"
+	FormatVCard = "vcard"
+)
+
+// TransferFields are the person fields written to and read from contact files.
+const TransferFields = "names,emailAddresses,phoneNumbers,organizations,birthdays,addresses,biographies,urls"
+
+// multiValueSeparator joins several emails, phones, addresses or URLs in a CSV cell, as in Google
+// Contacts exports.
+const multiValueSeparator = " ::: "
+
+var csvHeader = []string{"Name", "Given Name", "Family Name", "Emails", "Phones", "Organization", "Title", "Birthday", "Addresses", "Notes", "URLs"}
+
+// FormatContacts renders persons as a CSV file with a header row, or as vCards (version 3.0).
+func FormatContacts(persons []*people.Person, format string) (string, error) {
+	switch format {
+	case FormatCSV:
+		return formatCSV(persons)
+	case FormatVCard:
+		var b strings.Builder
+		for _, p := range persons {
+			writeVCard(&b, p)
+		}
+		return b.String(), nil
+	default:
+		return "", fmt.Errorf("unknown contact format %q (use csv or vcard)", format)
+	}
+}
+
+func formatCSV(persons []*people.Person) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(csvHeader); err != nil {
+		return "", fmt.Errorf("unable to write CSV: %w", err)
+	}
+	for _, p := range persons {
+		var name, given, family, org, title, birthday, notes string
+		if len(p.Names) > 0 {
+			name, given, family = p.Names[0].DisplayName, p.Names[0].GivenName, p.Names[0].FamilyName
+		}
+		if len(p.Organizations) > 0 {
+			org, title = p.Organizations[0].Name, p.Organizations[0].Title
+		}
+		if len(p.Birthdays) > 0 {
+			birthday = birthdayText(p.Birthdays[0])
+		}
+		if len(p.Biographies) > 0 {
+			notes = p.Biographies[0].Value
+		}
+		var emails, phones, addresses, urls []string
+		for _, e := range p.EmailAddresses {
+			emails = append(emails, e.Value)
+		}
+		for _, ph := range p.PhoneNumbers {
+			phones = append(phones, ph.Value)
+		}
+		for _, a := range p.Addresses {
+			addresses = append(addresses, a.FormattedValue)
+		}
+		for _, u := range p.Urls {
+			urls = append(urls, u.Value)
+		}
+		err := w.Write([]string{
+			name, given, family,
+			strings.Join(emails, multiValueSeparator), strings.Join(phones, multiValueSeparator),
+			org, title, birthday,
+			strings.Join(addresses, multiValueSeparator), notes, strings.Join(urls, multiValueSeparator),
+		})
+		if err != nil {
+			return "", fmt.Errorf("unable to write CSV: %w", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("unable to write CSV: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func birthdayText(b *people.Birthday) string {
+	if b.Date != nil {
+		return formatDate(b.Date)
+	}
+	return b.Text
+}
+
+// writeVCard writes a person as a vCard 3.0, with CRLF line endings and long lines folded.
+func writeVCard(b *strings.Builder, p *people.Person) {
+	line := func(name string, typ string, values ...string) {
+		for i, v := range values {
+			values[i] = escapeVCard(v)
+		}
+		if typ != "" {
+			name += ";TYPE=" + escapeVCard(typ)
+		}
+		writeFolded(b, name+":"+strings.Join(values, ";"))
+	}
+	line("BEGIN", "", "VCARD")
+	writeFolded(b, "VERSION:3.0")
+	line("FN", "", personName(p)) // Required by vCard 3.0, even if empty
+	if len(p.Names) > 0 {
+		n := p.Names[0]
+		line("N", "", n.FamilyName, n.GivenName, n.MiddleName, n.HonorificPrefix, n.HonorificSuffix)
+	}
+	for _, e := range p.EmailAddresses {
+		line("EMAIL", e.Type, e.Value)
+	}
+	for _, ph := range p.PhoneNumbers {
+		line("TEL", ph.Type, ph.Value)
+	}
+	if len(p.Organizations) > 0 {
+		o := p.Organizations[0]
+		if o.Name != "" || o.Department != "" {
+			line("ORG", "", o.Name, o.Department)
+		}
+		if o.Title != "" {
+			line("TITLE", "", o.Title)
+		}
+	}
+	if len(p.Birthdays) > 0 {
+		if v := birthdayText(p.Birthdays[0]); v != "" {
+			line("BDAY", "", v)
+		}
+	}
+	for _, a := range p.Addresses {
+		if a.StreetAddress == "" && a.City == "" && a.Region == "" && a.PostalCode == "" && a.Country == "" {
+			// Only a formatted address: keep it whole as the street
+			line("ADR", a.Type, a.PoBox, a.ExtendedAddress, a.FormattedValue, "", "", "", "")
+			continue
+		}
+		line("ADR", a.Type, a.PoBox, a.ExtendedAddress, a.StreetAddress, a.City, a.Region, a.PostalCode, a.Country)
+	}
+	if len(p.Biographies) > 0 && p.Biographies[0].Value != "" {
+		line("NOTE", "", p.Biographies[0].Value)
+	}
+	for _, u := range p.Urls {
+		line("URL", u.Type, u.Value)
+	}
+	line("END", "", "VCARD")
+}
+
+// escapeVCard escapes a vCard value: backslashes, commas, semicolons and newlines.
+func escapeVCard(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\n", `\n`).Replace(s)
+}
+
+// writeFolded writes a content line, folding it into lines of at most 75 bytes without splitting characters.
+func writeFolded(b *strings.Builder, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut] + "\r\n ")
+		s = s[cut:]
+		limit = 74 // The leading space of continuation lines counts
+	}
+	b.WriteString(s + "\r\n")
+}
+
+// ParseContacts parses a CSV file with a header row, or vCards; an empty format detects it from the content.
+// CSV columns are recognized by their header, including the columns of Google Contacts and Outlook exports;
+// others are ignored. Rows and cards without a name, email or phone are skipped.
+func ParseContacts(text string, format string) ([]*people.Person, error) {
+	text = strings.TrimPrefix(text, "\ufeff")
+	if format == "" {
+		format = FormatCSV
+		if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(text)), "BEGIN:VCARD") {
+			format = FormatVCard
+		}
+	}
+	switch format {
+	case FormatCSV:
+		return parseCSV(text)
+	case FormatVCard:
+		return parseVCards(text)
+	default:
+		return nil, fmt.Errorf("unknown contact format %q (use csv or vcard)", format)
+	}
+}
+
+// csvField returns the contact field of a CSV column from its header, or "" for columns that are ignored.
+func csvField(header string) string {
+	h := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, header)
+	if strings.HasSuffix(h, "type") || strings.HasSuffix(h, "label") {
+		return ""
+	}
+	switch {
+	case h == "name" || h == "fullname" || h == "displayname":
+		return "name"
+	case h == "givenname" || h == "firstname":
+		return "given"
+	case h == "familyname" || h == "lastname" || h == "surname":
+		return "family"
+	case strings.Contains(h, "email"):
+		return "email"
+	case strings.Contains(h, "phone") || h == "mobile":
+		return "phone"
+	case h == "title" || h == "jobtitle" || strings.HasPrefix(h, "organization") && strings.HasSuffix(h, "title"):
+		return "title"
+	case h == "organization" || h == "company" || strings.HasPrefix(h, "organization") && strings.HasSuffix(h, "name"):
+		return "organization"
+	case h == "birthday":
+		return "birthday"
+	case h == "address" || h == "addresses" || strings.HasPrefix(h, "address") && strings.HasSuffix(h, "formatted"):
+		return "address"
+	case h == "notes" || h == "note":
+		return "notes"
+	case h == "url" || h == "urls" || strings.HasPrefix(h, "website") && strings.HasSuffix(h, "value"):
+		return "url"
+	}
+	return ""
+}
+
+func parseCSV(text string) ([]*people.Person, error) {
+	r := csv.NewReader(strings.NewReader(text))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("CSV has no header row")
+	}
+	fields := make([]string, len(records[0]))
+	known := false
+	for i, h := range records[0] {
+		fields[i] = csvField(h)
+		known = known || fields[i] != ""
+	}
+	if !known {
+		return nil, fmt.Errorf("no contact columns in the CSV header (%s): name them e.g. Name, Email, Phone", strings.Join(records[0], ", "))
+	}
+
+	var out []*people.Person
+	for _, rec := range records[1:] {
+		p := &people.Person{}
+		name := &people.Name{}
+		for i, v := range rec {
+			if i >= len(fields) || strings.TrimSpace(v) == "" {
+				continue
+			}
+			v = strings.TrimSpace(v)
+			switch fields[i] {
+			case "name":
+				name.UnstructuredName = v
+			case "given":
+				name.GivenName = v
+			case "family":
+				name.FamilyName = v
+			case "email":
+				for _, e := range splitValues(v, true) {
+					p.EmailAddresses = append(p.EmailAddresses, &people.EmailAddress{Value: e})
+				}
+			case "phone":
+				for _, ph := range splitValues(v, true) {
+					p.PhoneNumbers = append(p.PhoneNumbers, &people.PhoneNumber{Value: ph})
+				}
+			case "organization":
+				organization(p).Name = v
+			case "title":
+				organization(p).Title = v
+			case "birthday":
+				p.Birthdays = []*people.Birthday{parseBirthday(v)}
+			case "address":
+				for _, a := range splitValues(v, false) {
+					p.Addresses = append(p.Addresses, &people.Address{FormattedValue: a})
+				}
+			case "notes":
+				p.Biographies = []*people.Biography{{Value: v, ContentType: "TEXT_PLAIN"}}
+			case "url":
+				for _, u := range splitValues(v, true) {
+					p.Urls = append(p.Urls, &people.Url{Value: u})
+				}
+			}
+		}
+		if keep(p, name) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+// splitValues splits a cell holding several values, separated by " ::: " and, unless a value may itself
+// contain them (addresses), by semicolons.
+func splitValues(v string, semicolons bool) []string {
+	parts := strings.Split(v, ":::")
+	if semicolons {
+		var split []string
+		for _, p := range parts {
+			split = append(split, strings.Split(p, ";")...)
+		}
+		parts = split
+	}
+	var out []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// organization returns the first organization of a person, adding one if it has none.
+func organization(p *people.Person) *people.Organization {
+	if len(p.Organizations) == 0 {
+		p.Organizations = []*people.Organization{{}}
+	}
+	return p.Organizations[0]
+}
+
+// keep sets the name of a parsed contact and reports whether it has enough to be imported.
+func keep(p *people.Person, name *people.Name) bool {
+	if name.GivenName != "" || name.FamilyName != "" {
+		name.UnstructuredName = ""
+	}
+	if name.UnstructuredName != "" || name.GivenName != "" || name.FamilyName != "" {
+		p.Names = []*people.Name{name}
+	}
+	return len(p.Names) > 0 || len(p.EmailAddresses) > 0 || len(p.PhoneNumbers) > 0
+}
+
+// parseBirthday parses YYYY-MM-DD, YYYYMMDD, --MM-DD or --MMDD; other text is kept as is.
+func parseBirthday(v string) *people.Birthday {
+	digits := strings.ReplaceAll(v, "-", "")
+	if strings.HasPrefix(v, "--") && len(digits) == 4 {
+		digits = "0000" + digits
+	}
+	if len(digits) == 8 && strings.Trim(digits, "0123456789") == "" {
+		year, _ := strconv.Atoi(digits[:4])
+		month, _ := strconv.Atoi(digits[4:6])
+		day, _ := strconv.Atoi(digits[6:])
+		if month >= 1 && month <= 12 && day >= 1 && day <= 31 {
+			return &people.Birthday{Date: &people.Date{Year: int64(year), Month: int64(month), Day: int64(day)}}
+		}
+	}
+	return &people.Birthday{Text: v}
+}
+
+// vcardLine is a content line of a vCard: "group.NAME;PARAM=value:value".
+type vcardLine struct {
+	name  string // Upper case, without group
+	types []string
+	value string // Raw, still escaped
+}
+
+// parseVCards parses vCards of versions 3.0 and 4.0 (and 2.1 without quoted-printable values).
+func parseVCards(text string) ([]*people.Person, error) {
+	// Unfold: a line starting with a space or tab continues the previous one
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.NewReplacer("\n ", "", "\n\t", "").Replace(text)
+
+	var out []*people.Person
+	var card []vcardLine
+	inCard, cards := false, 0
+	for _, raw := range strings.Split(text, "\n") {
+		l, ok := parseVCardLine(raw)
+		if !ok {
+			continue
+		}
+		switch {
+		case l.name == "BEGIN" && strings.EqualFold(l.value, "VCARD"):
+			inCard, card = true, nil
+		case l.name == "END" && strings.EqualFold(l.value, "VCARD"):
+			if inCard {
+				cards++
+				if p := vcardPerson(card); p != nil {
+					out = append(out, p)
+				}
+			}
+			inCard = false
+		case inCard:
+			card = append(card, l)
+		}
+	}
+	if cards == 0 {
+		return nil, fmt.Errorf("no vCard found (BEGIN:VCARD ... END:VCARD)")
+	}
+	return out, nil
+}
+
+func parseVCardLine(raw string) (vcardLine, bool) {
+	head, value, ok := strings.Cut(strings.TrimRight(raw, "\r"), ":")
+	if !ok {
+		return vcardLine{}, false
+	}
+	params := strings.Split(head, ";")
+	l := vcardLine{name: strings.ToUpper(params[0]), value: value}
+	if _, name, ok := strings.Cut(l.name, "."); ok {
+		l.name = name
+	}
+	for _, p := range params[1:] {
+		k, v, ok := strings.Cut(p, "=")
+		if !ok {
+			v = k // vCard 2.1: TEL;HOME;VOICE:...
+		} else if !strings.EqualFold(k, "TYPE") {
+			continue
+		}
+		for _, t := range strings.Split(strings.Trim(v, `"`), ",") {
+			if t = strings.ToLower(t); t != "pref" && t != "internet" && t != "voice" && t != "" {
+				l.types = append(l.types, t)
+			}
+		}
+	}
+	return l, true
+}
+
+func (l vcardLine) typ() string {
+	if len(l.types) == 0 {
+		return ""
+	}
+	return l.types[0]
+}
+
+// components splits a structured value at its unescaped semicolons and unescapes the parts.
+func (l vcardLine) components() []string {
+	var parts []string
+	var cur strings.Builder
+	escaped := false
+	for _, r := range l.value {
+		switch {
+		case escaped:
+			cur.WriteRune('\\')
+			cur.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ';':
+			parts = append(parts, unescapeVCard(cur.String()))
+			cur.Reset()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	return append(parts, unescapeVCard(cur.String()))
+}
+
+func (l vcardLine) text() string {
+	return strings.TrimSpace(unescapeVCard(l.value))
+}
+
+func unescapeVCard(s string) string {
+	return strings.TrimSpace(strings.NewReplacer(`\\`, `\`, `\,`, ",", `\;`, ";", `\n`, "\n", `\N`, "\n").Replace(s))
+}
+
+// vcardPerson converts the lines of a card, returning nil for cards without a name, email or phone.
+func vcardPerson(lines []vcardLine) *people.Person {
+	p := &people.Person{}
+	name := &people.Name{}
+	component := func(c []string, i int) string {
+		if i < len(c) {
+			return c[i]
+		}
+		return ""
+	}
+	for _, l := range lines {
+		switch l.name {
+		case "FN":
+			name.UnstructuredName = l.text()
+		case "N":
+			c := l.components()
+			name.FamilyName, name.GivenName, name.MiddleName = component(c, 0), component(c, 1), component(c, 2)
+			name.HonorificPrefix, name.HonorificSuffix = component(c, 3), component(c, 4)
+		case "EMAIL":
+			if v := l.text(); v != "" {
+				p.EmailAddresses = append(p.EmailAddresses, &people.EmailAddress{Value: v, Type: l.typ()})
+			}
+		case "TEL":
+			if v := strings.TrimPrefix(l.text(), "tel:"); v != "" {
+				p.PhoneNumbers = append(p.PhoneNumbers, &people.PhoneNumber{Value: v, Type: l.typ()})
+			}
+		case "ORG":
+			c := l.components()
+			organization(p).Name, organization(p).Department = component(c, 0), component(c, 1)
+		case "TITLE":
+			organization(p).Title = l.text()
+		case "BDAY":
+			if v := l.text(); v != "" {
+				p.Birthdays = []*people.Birthday{parseBirthday(v)}
+			}
+		case "ADR":
+			c := l.components()
+			a := &people.Address{
+				PoBox: component(c, 0), ExtendedAddress: component(c, 1), StreetAddress: component(c, 2), City: component(c, 3),
+				Region: component(c, 4), PostalCode: component(c, 5), Country: component(c, 6), Type: l.typ(),
+			}
+			var parts []string
+			for _, v := range []string{a.StreetAddress, a.ExtendedAddress, a.PoBox, a.City, a.Region, a.PostalCode, a.Country} {
+				if v != "" {
+					parts = append(parts, v)
+				}
+			}
+			if a.FormattedValue = strings.Join(parts, ", "); a.FormattedValue != "" {
+				p.Addresses = append(p.Addresses, a)
+			}
+		case "NOTE":
+			if v := l.text(); v != "" {
+				p.Biographies = []*people.Biography{{Value: v, ContentType: "TEXT_PLAIN"}}
+			}
+		case "URL":
+			if v := l.text(); v != "" {
+				p.Urls = append(p.Urls, &people.Url{Value: v, Type: l.typ()})
+			}
+		}
+	}
+	if !keep(p, name) {
+		return nil
+	}
+	return p
+}
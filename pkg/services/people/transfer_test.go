@@ -0,0 +1,133 @@
+package people
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/people/v1"
+)
+
+func TestContactsRoundTrip(t *testing.T) {
+	ana := &people.Person{
+		Names:          []*people.Name{{DisplayName: "Ana Silva", GivenName: "Ana", FamilyName: "Silva"}},
+		EmailAddresses: []*people.EmailAddress{{Value: "ana@example.com", Type: "work"}, {Value: "ana@home.example"}},
+		PhoneNumbers:   []*people.PhoneNumber{{Value: "+55 11 98765-4321", Type: "mobile"}},
+		Organizations:  []*people.Organization{{Name: "Acme; Inc", Title: "CTO"}},
+		Birthdays:      []*people.Birthday{{Date: &people.Date{Month: 5, Day: 17}}},
+		Addresses:      []*people.Address{{FormattedValue: "Rua A, 10\nSão Paulo", Type: "home"}},
+		Biographies:    []*people.Biography{{Value: "Met at " + strings.Repeat("the conference, ", 8) + "in Lisbon"}},
+		Urls:           []*people.Url{{Value: "https://ana.example"}},
+	}
+	bob := &people.Person{EmailAddresses: []*people.EmailAddress{{Value: "bob@example.com"}}}
+
+	for _, format := range []string{FormatCSV, FormatVCard} {
+		text, err := FormatContacts([]*people.Person{ana, bob}, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ParseContacts(text, "")
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", format, err, text)
+		}
+		if len(got) != 2 {
+			t.Fatalf("%s: parsed %d contacts, want 2:\n%s", format, len(got), text)
+		}
+		c := summarizeImported(got[0])
+		want := Contact{
+			Name:          "Ana Silva",
+			Emails:        []string{"ana@example.com", "ana@home.example"},
+			Phones:        []string{"+55 11 98765-4321"},
+			Organizations: []string{"CTO at Acme; Inc"},
+			Birthday:      "--05-17",
+			Addresses:     []string{"Rua A, 10, São Paulo"},
+			Notes:         ana.Biographies[0].Value,
+			URLs:          []string{"https://ana.example"},
+		}
+		if format == FormatVCard {
+			want.Phones = []string{"+55 11 98765-4321 (mobile)"}
+			want.Addresses = []string{"Rua A, 10, São Paulo (home)"}
+			got[0].PhoneNumbers[0].FormattedType = got[0].PhoneNumbers[0].Type
+			got[0].Addresses[0].FormattedType = got[0].Addresses[0].Type
+			c = summarizeImported(got[0])
+		}
+		if !reflect.DeepEqual(c, want) {
+			t.Errorf("%s round trip = %+v, want %+v\n%s", format, c, want, text)
+		}
+		if len(got[1].Names) != 0 || got[1].EmailAddresses[0].Value != "bob@example.com" {
+			t.Errorf("%s: contact without a name = %+v", format, got[1])
+		}
+		if format == FormatVCard {
+			for _, line := range strings.Split(text, "\r\n") {
+				if len(line) > 75 {
+					t.Errorf("vCard line longer than 75 bytes: %q", line)
+				}
+			}
+		}
+	}
+}
+
+func TestParseContactsCSVHeaders(t *testing.T) {
+	csv := "First Name,Last Name,E-mail 1 - Type,E-mail 1 - Value,Phone 1 - Value,Organization 1 - Name,Unknown\n" +
+		"Ana,Silva,Work,ana@example.com ::: ana@home.example,555-0100-22,Acme,x\n" +
+		",,,,,,only unknown\n"
+	got, err := ParseContacts(csv, FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("parsed %d contacts, want 1", len(got))
+	}
+	c := summarizeImported(got[0])
+	if c.Name != "Ana Silva" || !reflect.DeepEqual(c.Emails, []string{"ana@example.com", "ana@home.example"}) || c.Organizations[0] != "Acme" {
+		t.Errorf("parsed %+v", c)
+	}
+
+	if _, err := ParseContacts("Foo,Bar\n1,2\n", FormatCSV); err == nil {
+		t.Error("a CSV without contact columns should fail")
+	}
+	if _, err := ParseContacts("no cards here", FormatVCard); err == nil {
+		t.Error("text without vCards should fail")
+	}
+}
+
+func TestParseVCard21(t *testing.T) {
+	card := "BEGIN:VCARD\nVERSION:2.1\nN:Silva;Ana\nitem1.TEL;CELL;PREF:555 0100\nEMAIL;TYPE=\"INTERNET,HOME\":ana@example.com\nBDAY:19900517\nEND:VCARD\n"
+	got, err := ParseContacts(card, "")
+	if err != nil || len(got) != 1 {
+		t.Fatalf("ParseContacts() = %v, %v", got, err)
+	}
+	p := got[0]
+	if p.Names[0].GivenName != "Ana" || p.PhoneNumbers[0].Type != "cell" || p.EmailAddresses[0].Type != "home" || p.Birthdays[0].Date.Year != 1990 {
+		t.Errorf("parsed %+v", summarizeImported(p))
+	}
+}
+
+func TestPlanImport(t *testing.T) {
+	existing := []*people.Person{person("1", "Ana Silva", []string{"ana@example.com"}, nil)}
+	imported := []*people.Person{
+		{Names: []*people.Name{{UnstructuredName: "Ana S."}}, EmailAddresses: []*people.EmailAddress{{Value: "ANA@example.com"}}, PhoneNumbers: []*people.PhoneNumber{{Value: "555-0100-22"}}},
+		{Names: []*people.Name{{GivenName: "Bob", FamilyName: "Lee"}}},
+		{Names: []*people.Name{{UnstructuredName: "Bob Lee"}}, EmailAddresses: []*people.EmailAddress{{Value: "bob@example.com"}}},
+	}
+
+	creates, updates, skipped := planImport(existing, imported, DedupeSkip)
+	if len(creates) != 1 || len(updates) != 0 || len(skipped) != 2 || skipped[0] != "Ana S.: same email ana@example.com as Ana Silva" {
+		t.Errorf("skip: creates %d, updates %d, skipped %q", len(creates), len(updates), skipped)
+	}
+
+	creates, updates, skipped = planImport(existing, imported, DedupeMerge)
+	if len(creates) != 1 || len(updates) != 1 || len(skipped) != 0 {
+		t.Fatalf("merge: creates %d, updates %d, skipped %q", len(creates), len(updates), skipped)
+	}
+	if got := updates[0].person; got.ResourceName != "people/1" || len(got.EmailAddresses) != 1 || len(got.PhoneNumbers) != 1 {
+		t.Errorf("merged existing contact = %+v", Summarize(got))
+	}
+	if got := creates[0].person; len(got.EmailAddresses) != 1 || personName(got) != "Bob Lee" {
+		t.Errorf("duplicates within the import should be merged, got %+v", summarizeImported(got))
+	}
+
+	if creates, _, _ := planImport(nil, imported, DedupeNone); len(creates) != 3 {
+		t.Errorf("none: %d creates, want 3", len(creates))
+	}
+}
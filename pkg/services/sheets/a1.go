@@ -0,0 +1,116 @@
+package sheets
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// ParseA1 splits an A1 range such as "Sheet1!A1:C10", "'My Tab'!B:B", "Data!2:5" or "A1:D"
+// into the sheet title (empty when the range has none) and a GridRange without a sheet ID.
+// Ends that are not given are left unbounded, and a bare sheet title selects the whole sheet.
+func ParseA1(a1 string) (string, *sheets.GridRange, error) {
+	a1 = strings.TrimSpace(a1)
+	if a1 == "" {
+		return "", nil, fmt.Errorf("empty range")
+	}
+	title, cells := "", a1
+	if strings.HasPrefix(a1, "'") {
+		end := 1
+		var b strings.Builder
+		for ; end < len(a1); end++ {
+			if a1[end] == '\'' {
+				if end+1 < len(a1) && a1[end+1] == '\'' {
+					b.WriteByte('\'')
+					end++
+					continue
+				}
+				break
+			}
+			b.WriteByte(a1[end])
+		}
+		if end >= len(a1) {
+			return "", nil, fmt.Errorf("unterminated sheet name in %q", a1)
+		}
+		title, cells = b.String(), a1[end+1:]
+		if cells == "" {
+			return title, &sheets.GridRange{}, nil
+		}
+		if cells[0] != '!' {
+			return "", nil, fmt.Errorf("expected '!' after sheet name in %q", a1)
+		}
+		cells = cells[1:]
+	} else if i := strings.LastIndex(a1, "!"); i >= 0 {
+		title, cells = a1[:i], a1[i+1:]
+	} else if _, err := parseCellRange(a1); err != nil {
+		// Not a cell range, so it must be a sheet name.
+		return a1, &sheets.GridRange{}, nil
+	}
+	gr, err := parseCellRange(cells)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid range %q: %w", a1, err)
+	}
+	return title, gr, nil
+}
+
+// parseCellRange parses the part of an A1 range after the '!'.
+func parseCellRange(s string) (*sheets.GridRange, error) {
+	startRef, endRef, isRange := strings.Cut(strings.ToUpper(s), ":")
+	startCol, startRow, err := parseCellRef(startRef)
+	if err != nil {
+		return nil, err
+	}
+	endCol, endRow := startCol, startRow
+	if isRange {
+		if endCol, endRow, err = parseCellRef(endRef); err != nil {
+			return nil, err
+		}
+	} else if startCol < 0 || startRow < 0 {
+		return nil, fmt.Errorf("%q is not a cell", s)
+	}
+	gr := &sheets.GridRange{}
+	if startCol >= 0 {
+		gr.StartColumnIndex = int64(startCol)
+	}
+	if startRow >= 0 {
+		gr.StartRowIndex = int64(startRow)
+	}
+	if endCol >= 0 {
+		gr.EndColumnIndex = int64(endCol) + 1
+	}
+	if endRow >= 0 {
+		gr.EndRowIndex = int64(endRow) + 1
+	}
+	if gr.EndColumnIndex > 0 && gr.EndColumnIndex <= gr.StartColumnIndex || gr.EndRowIndex > 0 && gr.EndRowIndex <= gr.StartRowIndex {
+		return nil, fmt.Errorf("range end is before its start")
+	}
+	return gr, nil
+}
+
+// parseCellRef parses "B7", "B" or "7" into 0-based column and row indexes; a missing part is -1.
+func parseCellRef(ref string) (col, row int, err error) {
+	i := 0
+	col, row = -1, -1
+	for i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z' {
+		col = (col+1)*26 + int(ref[i]-'A')
+		i++
+	}
+	if i < len(ref) {
+		n := 0
+		for _, r := range ref[i:] {
+			if r < '0' || r > '9' {
+				return 0, 0, fmt.Errorf("invalid cell reference %q", ref)
+			}
+			n = n*10 + int(r-'0')
+		}
+		if n == 0 {
+			return 0, 0, fmt.Errorf("invalid cell reference %q", ref)
+		}
+		row = n - 1
+	}
+	if col < 0 && row < 0 {
+		return 0, 0, fmt.Errorf("invalid cell reference %q", ref)
+	}
+	return col, row, nil
+}
@@ -0,0 +1,41 @@
+package sheets
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+func TestParseA1(t *testing.T) {
+	tests := []struct {
+		in        string
+		wantTitle string
+		want      sheets.GridRange
+		wantErr   bool
+	}{
+		{"Sheet1!A1:C10", "Sheet1", sheets.GridRange{EndRowIndex: 10, EndColumnIndex: 3}, false},
+		{"'My Tab'!B:B", "My Tab", sheets.GridRange{StartColumnIndex: 1, EndColumnIndex: 2}, false},
+		{"'Bob''s'!2:5", "Bob's", sheets.GridRange{StartRowIndex: 1, EndRowIndex: 5}, false},
+		{"A2:D", "", sheets.GridRange{StartRowIndex: 1, EndColumnIndex: 4}, false},
+		{"Data!AA3", "Data", sheets.GridRange{StartRowIndex: 2, EndRowIndex: 3, StartColumnIndex: 26, EndColumnIndex: 27}, false},
+		{"Summary", "Summary", sheets.GridRange{}, false},
+		{"'Q1 Report'", "Q1 Report", sheets.GridRange{}, false},
+		{"Sheet1!C1:A1", "", sheets.GridRange{}, true},
+		{"Sheet1!A0", "", sheets.GridRange{}, true},
+		{"'Open!A1", "", sheets.GridRange{}, true},
+	}
+	for _, tt := range tests {
+		title, gr, err := ParseA1(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseA1(%q): unexpected error state: %v", tt.in, err)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if title != tt.wantTitle || !reflect.DeepEqual(*gr, tt.want) {
+			t.Errorf("ParseA1(%q) = %q %+v, expected %q %+v", tt.in, title, *gr, tt.wantTitle, tt.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package sheets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// CellDetail describes a cell's value together with its formula, note and basic formatting.
+type CellDetail struct {
+	Cell         string `json:"cell"`
+	Value        string `json:"value,omitempty"`
+	Formula      string `json:"formula,omitempty"`
+	Note         string `json:"note,omitempty"`
+	Bold         bool   `json:"bold,omitempty"`
+	Italic       bool   `json:"italic,omitempty"`
+	NumberFormat string `json:"number_format,omitempty"`
+	Background   string `json:"background,omitempty"` // Hex color, omitted when white
+}
+
+// cellDetailFields is the field mask for ReadCellDetails.
+const cellDetailFields = "sheets(properties(title),data(startRow,startColumn,rowData(values(formattedValue,userEnteredValue(formulaValue),note,effectiveFormat(numberFormat,backgroundColor,textFormat(bold,italic))))))"
+
+// ReadCellDetails reads a range with formulas, notes and basic formatting for every non-empty cell.
+func (s *SheetsService) ReadCellDetails(ctx context.Context, spreadsheetId string, rangeName string) ([]CellDetail, error) {
+	resp, err := s.srv.Spreadsheets.Get(spreadsheetId).
+		Ranges(rangeName).
+		IncludeGridData(true).
+		Fields(cellDetailFields).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve cells: %w", err)
+	}
+
+	var out []CellDetail
+	for _, sh := range resp.Sheets {
+		title := ""
+		if sh.Properties != nil {
+			title = sh.Properties.Title
+		}
+		for _, grid := range sh.Data {
+			for r, row := range grid.RowData {
+				for c, cell := range row.Values {
+					d := cellDetail(cell)
+					if d == (CellDetail{}) {
+						continue
+					}
+					d.Cell = fmt.Sprintf("%s!%s%d", quoteSheetTitle(title), ColumnName(int(grid.StartColumn)+c), int(grid.StartRow)+r+1)
+					out = append(out, d)
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// cellDetail extracts the interesting parts of a cell, leaving Cell empty.
+func cellDetail(cell *sheets.CellData) CellDetail {
+	d := CellDetail{Value: cell.FormattedValue, Note: cell.Note}
+	if cell.UserEnteredValue != nil && cell.UserEnteredValue.FormulaValue != nil {
+		d.Formula = *cell.UserEnteredValue.FormulaValue
+	}
+	if f := cell.EffectiveFormat; f != nil {
+		if f.TextFormat != nil {
+			d.Bold = f.TextFormat.Bold
+			d.Italic = f.TextFormat.Italic
+		}
+		if f.NumberFormat != nil && f.NumberFormat.Pattern != "" {
+			d.NumberFormat = f.NumberFormat.Pattern
+		}
+		if hex := colorHex(f.BackgroundColor); hex != "#FFFFFF" {
+			d.Background = hex
+		}
+	}
+	if d.Value == "" && d.Formula == "" && d.Note == "" {
+		// Formatting alone is not worth reporting for empty cells.
+		return CellDetail{}
+	}
+	return d
+}
+
+// ColumnName converts a 0-based column index to its A1 letters (0 -> A, 26 -> AA).
+func ColumnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// quoteSheetTitle quotes a sheet title for A1 notation when it contains anything but letters, digits and _.
+func quoteSheetTitle(title string) string {
+	for _, r := range title {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return "'" + strings.ReplaceAll(title, "'", "''") + "'"
+		}
+	}
+	return title
+}
+
+// colorHex renders a Sheets color as #RRGGBB; nil (the default) is white.
+func colorHex(c *sheets.Color) string {
+	if c == nil {
+		return "#FFFFFF"
+	}
+	return fmt.Sprintf("#%02X%02X%02X", int(c.Red*255+0.5), int(c.Green*255+0.5), int(c.Blue*255+0.5))
+}
+
+// GridRangeA1 renders a grid range on the sheet with the given title in A1 notation.
+// Unbounded ends (whole rows or columns) are left open, e.g. "Sheet1!A:C" or "Sheet1!2:5".
+func GridRangeA1(title string, r *sheets.GridRange) string {
+	sheet := quoteSheetTitle(title)
+	if r == nil {
+		return sheet
+	}
+	hasCols := r.EndColumnIndex > 0
+	hasRows := r.EndRowIndex > 0
+	switch {
+	case hasCols && hasRows:
+		return fmt.Sprintf("%s!%s%d:%s%d", sheet, ColumnName(int(r.StartColumnIndex)), r.StartRowIndex+1, ColumnName(int(r.EndColumnIndex)-1), r.EndRowIndex)
+	case hasCols:
+		return fmt.Sprintf("%s!%s:%s", sheet, ColumnName(int(r.StartColumnIndex)), ColumnName(int(r.EndColumnIndex)-1))
+	case hasRows:
+		return fmt.Sprintf("%s!%d:%d", sheet, r.StartRowIndex+1, r.EndRowIndex)
+	}
+	return sheet
+}
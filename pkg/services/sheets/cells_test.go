@@ -0,0 +1,67 @@
+package sheets
+
+import (
+	"testing"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := ColumnName(i); got != want {
+			t.Errorf("ColumnName(%d): expected %q, got %q", i, want, got)
+		}
+	}
+}
+
+func TestQuoteSheetTitle(t *testing.T) {
+	for title, want := range map[string]string{
+		"Sheet1":     "Sheet1",
+		"Q1 Budget":  "'Q1 Budget'",
+		"Bob's data": "'Bob''s data'",
+	} {
+		if got := quoteSheetTitle(title); got != want {
+			t.Errorf("quoteSheetTitle(%q): expected %q, got %q", title, want, got)
+		}
+	}
+}
+
+func TestCellDetail(t *testing.T) {
+	formula := "=SUM(A1:A3)"
+	got := cellDetail(&sheets.CellData{
+		FormattedValue:   "$1,200.00",
+		UserEnteredValue: &sheets.ExtendedValue{FormulaValue: &formula},
+		Note:             "Checked",
+		EffectiveFormat: &sheets.CellFormat{
+			TextFormat:      &sheets.TextFormat{Bold: true},
+			NumberFormat:    &sheets.NumberFormat{Type: "CURRENCY", Pattern: "$#,##0.00"},
+			BackgroundColor: &sheets.Color{Red: 1, Green: 1, Blue: 0},
+		},
+	})
+	want := CellDetail{Value: "$1,200.00", Formula: formula, Note: "Checked", Bold: true, NumberFormat: "$#,##0.00", Background: "#FFFF00"}
+	if got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	empty := cellDetail(&sheets.CellData{EffectiveFormat: &sheets.CellFormat{TextFormat: &sheets.TextFormat{Bold: true}}})
+	if empty != (CellDetail{}) {
+		t.Errorf("expected empty formatted cell to be skipped, got %+v", empty)
+	}
+}
+
+func TestGridRangeA1(t *testing.T) {
+	tests := []struct {
+		r    *sheets.GridRange
+		want string
+	}{
+		{&sheets.GridRange{StartRowIndex: 0, EndRowIndex: 10, StartColumnIndex: 0, EndColumnIndex: 3}, "Data!A1:C10"},
+		{&sheets.GridRange{StartColumnIndex: 1, EndColumnIndex: 2}, "Data!B:B"},
+		{&sheets.GridRange{StartRowIndex: 1, EndRowIndex: 5}, "Data!2:5"},
+		{&sheets.GridRange{}, "Data"},
+	}
+	for _, tt := range tests {
+		if got := GridRangeA1("Data", tt.r); got != tt.want {
+			t.Errorf("expected %q, got %q", tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package sheets
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// ChartSpec is a simple description of a chart. The source range's first column holds the categories
+// (x axis or pie slices) and each following column is a series; the first row holds the headers.
+type ChartSpec struct {
+	Type    string // COLUMN (default), BAR, LINE, AREA, SCATTER, COMBO or PIE
+	Title   string
+	XTitle  string
+	YTitle  string
+	Stacked bool
+}
+
+var basicChartTypes = []string{"COLUMN", "BAR", "LINE", "AREA", "SCATTER", "COMBO"}
+
+// chartSpec translates spec into the API chart spec for source, which must carry its sheet ID.
+func (spec ChartSpec) chartSpec(source *sheets.GridRange) (*sheets.ChartSpec, error) {
+	if source.EndColumnIndex == 0 || source.EndColumnIndex-source.StartColumnIndex < 2 {
+		return nil, fmt.Errorf("chart range needs a bounded set of at least two columns (categories and one series), e.g. 'Sheet1!A1:C20'")
+	}
+	column := func(i int64) *sheets.ChartData {
+		r := *source
+		r.StartColumnIndex, r.EndColumnIndex = i, i+1
+		return &sheets.ChartData{SourceRange: &sheets.ChartSourceRange{Sources: []*sheets.GridRange{&r}}}
+	}
+	chartType := strings.ToUpper(spec.Type)
+	if chartType == "" {
+		chartType = "COLUMN"
+	}
+	out := &sheets.ChartSpec{Title: spec.Title}
+	if chartType == "PIE" {
+		out.PieChart = &sheets.PieChartSpec{
+			Domain:         column(source.StartColumnIndex),
+			Series:         column(source.StartColumnIndex + 1),
+			LegendPosition: "RIGHT_LEGEND",
+		}
+		return out, nil
+	}
+	if !slices.Contains(basicChartTypes, chartType) {
+		return nil, fmt.Errorf("unsupported chart type %q, expected one of %s or PIE", spec.Type, strings.Join(basicChartTypes, ", "))
+	}
+	basic := &sheets.BasicChartSpec{
+		ChartType:      chartType,
+		HeaderCount:    1,
+		LegendPosition: "BOTTOM_LEGEND",
+		Domains:        []*sheets.BasicChartDomain{{Domain: column(source.StartColumnIndex)}},
+	}
+	// Bar charts have the categories on the vertical axis.
+	xPos, yPos := "BOTTOM_AXIS", "LEFT_AXIS"
+	if chartType == "BAR" {
+		xPos, yPos = "LEFT_AXIS", "BOTTOM_AXIS"
+	}
+	if spec.XTitle != "" {
+		basic.Axis = append(basic.Axis, &sheets.BasicChartAxis{Position: xPos, Title: spec.XTitle})
+	}
+	if spec.YTitle != "" {
+		basic.Axis = append(basic.Axis, &sheets.BasicChartAxis{Position: yPos, Title: spec.YTitle})
+	}
+	if spec.Stacked {
+		basic.StackedType = "STACKED"
+	}
+	for i := source.StartColumnIndex + 1; i < source.EndColumnIndex; i++ {
+		s := &sheets.BasicChartSeries{Series: column(i), TargetAxis: yPos}
+		if chartType == "COMBO" {
+			// Combo charts draw the first series as columns and the rest as lines.
+			s.Type = "LINE"
+			if i == source.StartColumnIndex+1 {
+				s.Type = "COLUMN"
+			}
+		}
+		basic.Series = append(basic.Series, s)
+	}
+	out.BasicChart = basic
+	return out, nil
+}
+
+// AddChart adds a chart over sourceA1. The chart is placed with its top-left corner at anchorA1
+// (e.g. "Sheet1!H2"), or on a new tab when anchorA1 is empty. It returns the chart ID.
+func (s *SheetsService) AddChart(ctx context.Context, spreadsheetId, sourceA1, anchorA1 string, spec ChartSpec) (int64, error) {
+	source, _, err := s.gridRange(ctx, spreadsheetId, sourceA1)
+	if err != nil {
+		return 0, err
+	}
+	cs, err := spec.chartSpec(source)
+	if err != nil {
+		return 0, err
+	}
+	pos := &sheets.EmbeddedObjectPosition{NewSheet: true}
+	if anchorA1 != "" {
+		anchor, _, err := s.gridRange(ctx, spreadsheetId, anchorA1)
+		if err != nil {
+			return 0, err
+		}
+		pos = &sheets.EmbeddedObjectPosition{OverlayPosition: &sheets.OverlayPosition{AnchorCell: &sheets.GridCoordinate{
+			SheetId:         anchor.SheetId,
+			RowIndex:        anchor.StartRowIndex,
+			ColumnIndex:     anchor.StartColumnIndex,
+			ForceSendFields: []string{"SheetId"},
+		}}}
+	}
+	resp, err := s.BatchUpdate(ctx, spreadsheetId, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{AddChart: &sheets.AddChartRequest{Chart: &sheets.EmbeddedChart{Spec: cs, Position: pos}}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return resp.Replies[0].AddChart.Chart.ChartId, nil
+}
+
+// PivotValue is a summarized column of a pivot table.
+type PivotValue struct {
+	Column    string // Header name or column letter
+	Summarize string // SUM (default), COUNTA, COUNT, COUNTUNIQUE, AVERAGE, MAX, MIN, MEDIAN, PRODUCT, STDEV, VAR
+}
+
+// PivotSpec describes a pivot table by source column names (or letters).
+type PivotSpec struct {
+	Rows    []string
+	Columns []string
+	Values  []PivotValue
+}
+
+// pivotTable translates spec into an API pivot table for source, given the headers of its first row.
+func (spec PivotSpec) pivotTable(source *sheets.GridRange, headers []string) (*sheets.PivotTable, error) {
+	if len(spec.Values) == 0 {
+		return nil, fmt.Errorf("pivot table needs at least one value column")
+	}
+	offset := func(ref string) (int64, error) {
+		for i, h := range headers {
+			if strings.EqualFold(strings.TrimSpace(h), strings.TrimSpace(ref)) {
+				return int64(i), nil
+			}
+		}
+		if col, row, err := parseCellRef(strings.ToUpper(strings.TrimSpace(ref))); err == nil && row < 0 &&
+			int64(col) >= source.StartColumnIndex && (source.EndColumnIndex == 0 && col-int(source.StartColumnIndex) < len(headers) || int64(col) < source.EndColumnIndex) {
+			return int64(col) - source.StartColumnIndex, nil
+		}
+		return 0, fmt.Errorf("unknown column %q (have: %s)", ref, strings.Join(headers, ", "))
+	}
+	groups := func(refs []string) ([]*sheets.PivotGroup, error) {
+		var out []*sheets.PivotGroup
+		for _, ref := range refs {
+			off, err := offset(ref)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, &sheets.PivotGroup{SourceColumnOffset: off, SortOrder: "ASCENDING", ShowTotals: true, ForceSendFields: []string{"SourceColumnOffset"}})
+		}
+		return out, nil
+	}
+	pt := &sheets.PivotTable{Source: source, ValueLayout: "HORIZONTAL"}
+	var err error
+	if pt.Rows, err = groups(spec.Rows); err != nil {
+		return nil, err
+	}
+	if pt.Columns, err = groups(spec.Columns); err != nil {
+		return nil, err
+	}
+	for _, v := range spec.Values {
+		off, err := offset(v.Column)
+		if err != nil {
+			return nil, err
+		}
+		fn := strings.ToUpper(v.Summarize)
+		if fn == "" {
+			fn = "SUM"
+		}
+		pt.Values = append(pt.Values, &sheets.PivotValue{SourceColumnOffset: off, SummarizeFunction: fn, ForceSendFields: []string{"SourceColumnOffset"}})
+	}
+	return pt, nil
+}
+
+// AddPivotTable builds a pivot table over sourceA1 (including its header row). It is written with its
+// top-left corner at anchorA1, or at A1 of a new tab named newTabTitle when anchorA1 is empty.
+// It returns the A1 location of the pivot table.
+func (s *SheetsService) AddPivotTable(ctx context.Context, spreadsheetId, sourceA1, anchorA1, newTabTitle string, spec PivotSpec) (string, error) {
+	source, title, err := s.gridRange(ctx, spreadsheetId, sourceA1)
+	if err != nil {
+		return "", err
+	}
+	header := *source
+	header.EndRowIndex = header.StartRowIndex + 1
+	headerRows, err := s.ReadValues(ctx, spreadsheetId, GridRangeA1(title, &header), ReadOptions{})
+	if err != nil {
+		return "", err
+	}
+	var headers []string
+	if len(headerRows) > 0 {
+		for _, v := range headerRows[0] {
+			headers = append(headers, fmt.Sprint(v))
+		}
+	}
+	pt, err := spec.pivotTable(source, headers)
+	if err != nil {
+		return "", err
+	}
+
+	var at *sheets.GridCoordinate
+	var location string
+	if anchorA1 != "" {
+		anchor, _, err := s.gridRange(ctx, spreadsheetId, anchorA1)
+		if err != nil {
+			return "", err
+		}
+		at = &sheets.GridCoordinate{SheetId: anchor.SheetId, RowIndex: anchor.StartRowIndex, ColumnIndex: anchor.StartColumnIndex}
+		location = anchorA1
+	} else {
+		if newTabTitle == "" {
+			newTabTitle = "Pivot of " + title
+		}
+		p, err := s.AddSheet(ctx, spreadsheetId, newTabTitle, 0, 0, -1)
+		if err != nil {
+			return "", err
+		}
+		at = &sheets.GridCoordinate{SheetId: p.SheetId}
+		location = quoteSheetTitle(p.Title) + "!A1"
+	}
+	at.ForceSendFields = []string{"SheetId"}
+	_, err = s.BatchUpdate(ctx, spreadsheetId, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{UpdateCells: &sheets.UpdateCellsRequest{
+			Start:  at,
+			Rows:   []*sheets.RowData{{Values: []*sheets.CellData{{PivotTable: pt}}}},
+			Fields: "pivotTable",
+		}}},
+	})
+	if err != nil {
+		return "", err
+	}
+	return location, nil
+}
@@ -0,0 +1,75 @@
+package sheets
+
+import (
+	"testing"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+func TestChartSpec(t *testing.T) {
+	source := &sheets.GridRange{SheetId: 3, StartColumnIndex: 1, EndColumnIndex: 4, EndRowIndex: 13}
+
+	cs, err := ChartSpec{Title: "Sales", YTitle: "USD", Stacked: true}.chartSpec(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := cs.BasicChart
+	if b == nil || b.ChartType != "COLUMN" || b.StackedType != "STACKED" || len(b.Series) != 2 || len(b.Axis) != 1 {
+		t.Fatalf("unexpected basic chart: %+v", b)
+	}
+	if d := b.Domains[0].Domain.SourceRange.Sources[0]; d.StartColumnIndex != 1 || d.EndColumnIndex != 2 || d.SheetId != 3 {
+		t.Errorf("unexpected domain range: %+v", d)
+	}
+	if s := b.Series[1].Series.SourceRange.Sources[0]; s.StartColumnIndex != 3 || s.EndColumnIndex != 4 {
+		t.Errorf("unexpected series range: %+v", s)
+	}
+	if source.StartColumnIndex != 1 || source.EndColumnIndex != 4 {
+		t.Errorf("source range was modified: %+v", source)
+	}
+
+	cs, err = ChartSpec{Type: "pie"}.chartSpec(source)
+	if err != nil || cs.PieChart == nil || cs.BasicChart != nil {
+		t.Errorf("expected pie chart, got %+v, %v", cs, err)
+	}
+	cs, err = ChartSpec{Type: "combo"}.chartSpec(source)
+	if err != nil || cs.BasicChart.Series[0].Type != "COLUMN" || cs.BasicChart.Series[1].Type != "LINE" {
+		t.Errorf("unexpected combo series: %+v, %v", cs, err)
+	}
+
+	if _, err := (ChartSpec{Type: "radar"}).chartSpec(source); err == nil {
+		t.Error("expected error for unsupported type")
+	}
+	if _, err := (ChartSpec{}).chartSpec(&sheets.GridRange{StartColumnIndex: 0, EndColumnIndex: 1}); err == nil {
+		t.Error("expected error for a single column")
+	}
+}
+
+func TestPivotTable(t *testing.T) {
+	source := &sheets.GridRange{SheetId: 1, StartColumnIndex: 2, EndColumnIndex: 6}
+	headers := []string{"Region", "Product", "Quarter", "Amount"}
+
+	pt, err := PivotSpec{
+		Rows:    []string{"region"},
+		Columns: []string{"E"},
+		Values:  []PivotValue{{Column: "Amount"}, {Column: "Product", Summarize: "counta"}},
+	}.pivotTable(source, headers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pt.Rows) != 1 || pt.Rows[0].SourceColumnOffset != 0 {
+		t.Errorf("unexpected rows: %+v", pt.Rows)
+	}
+	if len(pt.Columns) != 1 || pt.Columns[0].SourceColumnOffset != 2 {
+		t.Errorf("unexpected columns: %+v", pt.Columns)
+	}
+	if len(pt.Values) != 2 || pt.Values[0].SummarizeFunction != "SUM" || pt.Values[0].SourceColumnOffset != 3 || pt.Values[1].SummarizeFunction != "COUNTA" {
+		t.Errorf("unexpected values: %+v", pt.Values)
+	}
+
+	if _, err := (PivotSpec{Rows: []string{"Region"}}).pivotTable(source, headers); err == nil {
+		t.Error("expected error without values")
+	}
+	if _, err := (PivotSpec{Values: []PivotValue{{Column: "Cost"}}}).pivotTable(source, headers); err == nil {
+		t.Error("expected error for unknown column")
+	}
+}
@@ -0,0 +1,87 @@
+package sheets
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// csvChunkRows is how many rows ImportCSV writes per request, keeping payloads well below API limits.
+const csvChunkRows = 2000
+
+// ParseCSV parses CSV text into rows. Rows may have different lengths; a UTF-8 BOM is ignored.
+// delimiter 0 means a comma.
+func ParseCSV(text string, delimiter rune) ([][]interface{}, error) {
+	r := csv.NewReader(strings.NewReader(strings.TrimPrefix(text, "\ufeff")))
+	if delimiter != 0 {
+		r.Comma = delimiter
+	}
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse CSV: %w", err)
+	}
+	rows := make([][]interface{}, len(records))
+	for i, rec := range records {
+		row := make([]interface{}, len(rec))
+		for j, v := range rec {
+			row[j] = v
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+// FormatCSV renders rows as CSV text, quoting fields where needed.
+func FormatCSV(values [][]interface{}) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	for _, row := range values {
+		rec := make([]string, len(row))
+		for i, v := range row {
+			rec[i] = fmt.Sprint(v)
+		}
+		if err := w.Write(rec); err != nil {
+			return "", fmt.Errorf("unable to write CSV: %w", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("unable to write CSV: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// ImportCSV writes rows to a new tab sized to fit them, in chunks of csvChunkRows.
+// With raw, values are stored as-is; otherwise they are parsed as if typed by a user (numbers, dates, formulas).
+func (s *SheetsService) ImportCSV(ctx context.Context, spreadsheetId, title string, rows [][]interface{}, raw bool) (*sheets.SheetProperties, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("CSV has no rows")
+	}
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	props, err := s.AddSheet(ctx, spreadsheetId, title, int64(len(rows)), int64(max(width, 1)), -1)
+	if err != nil {
+		return nil, err
+	}
+	input := "USER_ENTERED"
+	if raw {
+		input = "RAW"
+	}
+	for start := 0; start < len(rows); start += csvChunkRows {
+		end := min(start+csvChunkRows, len(rows))
+		rng := fmt.Sprintf("%s!A%d", quoteSheetTitle(props.Title), start+1)
+		vr := &sheets.ValueRange{Values: rows[start:end]}
+		if _, err := s.srv.Spreadsheets.Values.Update(spreadsheetId, rng, vr).ValueInputOption(input).Context(ctx).Do(); err != nil {
+			return nil, fmt.Errorf("unable to write rows %d-%d (earlier rows were written to tab %q): %w", start+1, end, props.Title, err)
+		}
+	}
+	return props, nil
+}
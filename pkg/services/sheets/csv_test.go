@@ -0,0 +1,36 @@
+package sheets
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCSV(t *testing.T) {
+	rows, err := ParseCSV("\ufeffname,note\n\"Smith, J\",\"said \"\"hi\"\"\"\nsolo\n", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]interface{}{{"name", "note"}, {"Smith, J", `said "hi"`}, {"solo"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("expected %v, got %v", want, rows)
+	}
+
+	rows, err = ParseCSV("a;b\n1;2\n", ';')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[1][1] != "2" {
+		t.Errorf("unexpected semicolon parse: %v", rows)
+	}
+}
+
+func TestFormatCSV(t *testing.T) {
+	got, err := FormatCSV([][]interface{}{{"name", "amount"}, {"Smith, J", 12.5}, {"multi\nline", `q"uote`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "name,amount\n\"Smith, J\",12.5\n\"multi\nline\",\"q\"\"uote\"\n"
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,177 @@
+package sheets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// maxPreviewChanges caps how many cell changes FormatChanges lists.
+const maxPreviewChanges = 200
+
+// CellChange is a cell that a write would change.
+type CellChange struct {
+	Cell string
+	Old  string
+	New  string
+}
+
+// WritePreview describes what a write would do without performing it.
+type WritePreview struct {
+	Range     string // A1 range that would be written
+	Changes   []CellChange
+	Unchanged int
+	Note      string
+}
+
+// diffCells compares the values about to be written at (startRow, startCol), 0-based, with the current
+// values read from the same place, and returns the cells that differ and how many stay the same.
+func diffCells(title string, startRow, startCol int, current, next [][]interface{}) ([]CellChange, int) {
+	cell := func(rows [][]interface{}, r, c int) string {
+		if r < len(rows) && c < len(rows[r]) && rows[r][c] != nil {
+			return fmt.Sprint(rows[r][c])
+		}
+		return ""
+	}
+	var changes []CellChange
+	unchanged := 0
+	for r, row := range next {
+		for c := range row {
+			old, nw := cell(current, r, c), cell(next, r, c)
+			if old == nw {
+				unchanged++
+				continue
+			}
+			changes = append(changes, CellChange{
+				Cell: fmt.Sprintf("%s!%s%d", quoteSheetTitle(title), ColumnName(startCol+c), startRow+r+1),
+				Old:  old,
+				New:  nw,
+			})
+		}
+	}
+	return changes, unchanged
+}
+
+// blockRange returns the A1 range covered by a block of rows written at (startRow, startCol), 0-based.
+func blockRange(title string, startRow, startCol int, rows [][]interface{}) string {
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	return fmt.Sprintf("%s!%s%d:%s%d", quoteSheetTitle(title), ColumnName(startCol), startRow+1, ColumnName(startCol+max(width, 1)-1), startRow+max(len(rows), 1))
+}
+
+// PreviewUpdate reports the cell-level changes UpdateValues would make, without writing.
+func (s *SheetsService) PreviewUpdate(ctx context.Context, spreadsheetId, rangeName, valuesJSON string) (*WritePreview, error) {
+	next, err := s.parseValuesJSON(valuesJSON)
+	if err != nil {
+		return nil, err
+	}
+	title, gr, err := ParseA1(rangeName)
+	if err != nil {
+		return nil, err
+	}
+	if title == "" {
+		p, err := s.ResolveSheet(ctx, spreadsheetId, "")
+		if err != nil {
+			return nil, err
+		}
+		title = p.Title
+	}
+	width := 0
+	for _, row := range next {
+		width = max(width, len(row))
+	}
+	if gr.EndRowIndex > 0 && int64(len(next)) > gr.EndRowIndex-gr.StartRowIndex ||
+		gr.EndColumnIndex > 0 && int64(width) > gr.EndColumnIndex-gr.StartColumnIndex {
+		return nil, fmt.Errorf("%d rows x %d columns do not fit in %s", len(next), width, rangeName)
+	}
+	target := blockRange(title, int(gr.StartRowIndex), int(gr.StartColumnIndex), next)
+	current, err := s.ReadValues(ctx, spreadsheetId, target, ReadOptions{ValueRender: "FORMULA"})
+	if err != nil {
+		return nil, err
+	}
+	changes, unchanged := diffCells(title, int(gr.StartRowIndex), int(gr.StartColumnIndex), current, next)
+	return &WritePreview{Range: target, Changes: changes, Unchanged: unchanged}, nil
+}
+
+// nextFreeRow returns the 0-based row after the last non-empty row of the given columns, starting at startRow.
+func (s *SheetsService) nextFreeRow(ctx context.Context, spreadsheetId, title string, startRow, startCol, endCol int) (int, error) {
+	rng := fmt.Sprintf("%s!%s%d:%s", quoteSheetTitle(title), ColumnName(startCol), startRow+1, ColumnName(endCol))
+	values, err := s.ReadValues(ctx, spreadsheetId, rng, ReadOptions{})
+	if err != nil {
+		return 0, err
+	}
+	// Trailing empty rows are omitted by the API, but rows of empty strings can remain.
+	last := -1
+	for i, row := range values {
+		for _, v := range row {
+			if fmt.Sprint(v) != "" {
+				last = i
+				break
+			}
+		}
+	}
+	return startRow + last + 1, nil
+}
+
+// previewAppend describes appending rows below the data in the given columns of a tab.
+func (s *SheetsService) previewAppend(ctx context.Context, spreadsheetId, title string, startRow, startCol int, rows [][]interface{}) (*WritePreview, error) {
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	row, err := s.nextFreeRow(ctx, spreadsheetId, title, startRow, startCol, startCol+max(width, 1)-1)
+	if err != nil {
+		return nil, err
+	}
+	changes, unchanged := diffCells(title, row, startCol, nil, rows)
+	return &WritePreview{
+		Range:     blockRange(title, row, startCol, rows),
+		Changes:   changes,
+		Unchanged: unchanged,
+		Note:      "rows are added after the last non-empty row of the target columns",
+	}, nil
+}
+
+// PreviewAppend reports where AppendValues would add rows and what they contain, without writing.
+func (s *SheetsService) PreviewAppend(ctx context.Context, spreadsheetId, rangeName, valuesJSON string) (*WritePreview, error) {
+	rows, err := s.parseValuesJSON(valuesJSON)
+	if err != nil {
+		return nil, err
+	}
+	title, gr, err := ParseA1(rangeName)
+	if err != nil {
+		return nil, err
+	}
+	if title == "" {
+		p, err := s.ResolveSheet(ctx, spreadsheetId, "")
+		if err != nil {
+			return nil, err
+		}
+		title = p.Title
+	}
+	return s.previewAppend(ctx, spreadsheetId, title, int(gr.StartRowIndex), int(gr.StartColumnIndex), rows)
+}
+
+// FormatPreview renders a WritePreview for display.
+func FormatPreview(p *WritePreview) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Dry run, nothing was written. Target: %s\n", p.Range)
+	fmt.Fprintf(&b, "%d cells would change, %d unchanged.\n", len(p.Changes), p.Unchanged)
+	if p.Note != "" {
+		fmt.Fprintf(&b, "Note: %s.\n", p.Note)
+	}
+	for i, c := range p.Changes {
+		if i == maxPreviewChanges {
+			fmt.Fprintf(&b, "... and %d more changes\n", len(p.Changes)-maxPreviewChanges)
+			break
+		}
+		if c.Old == "" {
+			fmt.Fprintf(&b, "%s: (empty) -> %q\n", c.Cell, c.New)
+		} else {
+			fmt.Fprintf(&b, "%s: %q -> %q\n", c.Cell, c.Old, c.New)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,54 @@
+package sheets
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffCells(t *testing.T) {
+	current := [][]interface{}{
+		{"Name", "Total"},
+		{"Alpha", float64(10)},
+	}
+	next := [][]interface{}{
+		{"Name", "Total"},
+		{"Alpha", "12"},
+		{"Beta", "=B2*2"},
+	}
+	changes, unchanged := diffCells("My Tab", 4, 1, current, next)
+	if unchanged != 3 {
+		t.Errorf("expected 3 unchanged cells, got %d", unchanged)
+	}
+	want := []CellChange{
+		{Cell: "'My Tab'!C6", Old: "10", New: "12"},
+		{Cell: "'My Tab'!B7", Old: "", New: "Beta"},
+		{Cell: "'My Tab'!C7", Old: "", New: "=B2*2"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %v, got %v", want, changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d: expected %+v, got %+v", i, want[i], changes[i])
+		}
+	}
+}
+
+func TestBlockRange(t *testing.T) {
+	if got := blockRange("Data", 1, 2, [][]interface{}{{1, 2, 3}, {4}}); got != "Data!C2:E3" {
+		t.Errorf("expected Data!C2:E3, got %s", got)
+	}
+}
+
+func TestFormatPreview(t *testing.T) {
+	out := FormatPreview(&WritePreview{
+		Range:     "Data!A1:B1",
+		Changes:   []CellChange{{Cell: "Data!A1", New: "x"}, {Cell: "Data!B1", Old: "1", New: "2"}},
+		Unchanged: 0,
+	})
+	for _, want := range []string{"Dry run", "2 cells would change", `Data!A1: (empty) -> "x"`, `Data!B1: "1" -> "2"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package sheets
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// RowFilter is a predicate on one column. Column is a header name (case-insensitive) or a column letter.
+type RowFilter struct {
+	Column string `json:"column"`
+	Op     string `json:"op"` // eq, ne, contains, not_contains, starts_with, ends_with, gt, gte, lt, lte, empty, not_empty, regex
+	Value  string `json:"value,omitempty"`
+}
+
+// QueryOptions controls how QueryRows matches and reports rows.
+type QueryOptions struct {
+	HasHeader bool     // First row of the range holds column names
+	MatchAny  bool     // Rows must match any filter instead of all
+	Columns   []string // Columns to return (header names or letters); empty returns all
+	Limit     int      // Maximum rows to return; 0 means no limit
+}
+
+// QueryRow is a matching row with its 1-based sheet row number and values keyed by column.
+type QueryRow struct {
+	Row    int               `json:"row"`
+	Values map[string]string `json:"values"`
+}
+
+// QueryResult holds the matches of a QueryRows call.
+type QueryResult struct {
+	Columns   []string   `json:"columns"`
+	Rows      []QueryRow `json:"rows"`
+	Matched   int        `json:"matched"`
+	Scanned   int        `json:"scanned"`
+	Truncated bool       `json:"truncated,omitempty"`
+}
+
+// QueryRows reads a range and returns only the rows matching the filters, so callers don't need the whole sheet.
+func (s *SheetsService) QueryRows(ctx context.Context, spreadsheetId, rangeName string, filters []RowFilter, opts QueryOptions) (*QueryResult, error) {
+	_, gr, err := ParseA1(rangeName)
+	if err != nil {
+		return nil, err
+	}
+	values, err := s.ReadValues(ctx, spreadsheetId, rangeName, ReadOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return filterRows(values, int(gr.StartRowIndex), int(gr.StartColumnIndex), filters, opts)
+}
+
+// compiledFilter is a RowFilter resolved to a column index.
+type compiledFilter struct {
+	RowFilter
+	col int
+	re  *regexp.Regexp
+}
+
+// filterRows applies filters to values read from a range whose top-left cell is at (startRow, startCol), 0-based.
+func filterRows(values [][]interface{}, startRow, startCol int, filters []RowFilter, opts QueryOptions) (*QueryResult, error) {
+	var names []string
+	data := values
+	firstRow := startRow + 1
+	if opts.HasHeader && len(values) > 0 {
+		for _, v := range values[0] {
+			names = append(names, fmt.Sprint(v))
+		}
+		data = values[1:]
+		firstRow++
+	}
+	width := len(names)
+	for _, row := range data {
+		width = max(width, len(row))
+	}
+	for i := len(names); i < width; i++ {
+		names = append(names, ColumnName(startCol+i))
+	}
+	// Blank header cells fall back to the column letter.
+	for i, n := range names {
+		if strings.TrimSpace(n) == "" {
+			names[i] = ColumnName(startCol + i)
+		}
+	}
+
+	lookup := func(ref string) (int, error) {
+		for i, n := range names {
+			if strings.EqualFold(n, ref) {
+				return i, nil
+			}
+		}
+		if col, row, err := parseCellRef(strings.ToUpper(ref)); err == nil && row < 0 && col >= startCol && col-startCol < len(names) {
+			return col - startCol, nil
+		}
+		return 0, fmt.Errorf("unknown column %q (have: %s)", ref, strings.Join(names, ", "))
+	}
+
+	compiled := make([]compiledFilter, len(filters))
+	for i, f := range filters {
+		col, err := lookup(f.Column)
+		if err != nil {
+			return nil, err
+		}
+		compiled[i] = compiledFilter{RowFilter: f, col: col}
+		switch strings.ToLower(f.Op) {
+		case "regex":
+			if compiled[i].re, err = regexp.Compile(f.Value); err != nil {
+				return nil, fmt.Errorf("invalid regex %q: %w", f.Value, err)
+			}
+		case "eq", "ne", "contains", "not_contains", "starts_with", "ends_with", "gt", "gte", "lt", "lte", "empty", "not_empty":
+		default:
+			return nil, fmt.Errorf("unknown op %q", f.Op)
+		}
+	}
+
+	outCols := make([]int, 0, len(names))
+	if len(opts.Columns) == 0 {
+		for i := range names {
+			outCols = append(outCols, i)
+		}
+	} else {
+		for _, c := range opts.Columns {
+			col, err := lookup(c)
+			if err != nil {
+				return nil, err
+			}
+			outCols = append(outCols, col)
+		}
+	}
+	res := &QueryResult{Rows: []QueryRow{}}
+	for _, c := range outCols {
+		res.Columns = append(res.Columns, names[c])
+	}
+
+	for i, row := range data {
+		cell := func(c int) string {
+			if c < len(row) {
+				return fmt.Sprint(row[c])
+			}
+			return ""
+		}
+		if len(row) == 0 {
+			continue
+		}
+		res.Scanned++
+		if !matchRow(compiled, cell, opts.MatchAny) {
+			continue
+		}
+		res.Matched++
+		if opts.Limit > 0 && len(res.Rows) >= opts.Limit {
+			res.Truncated = true
+			continue
+		}
+		qr := QueryRow{Row: firstRow + i, Values: make(map[string]string, len(outCols))}
+		for _, c := range outCols {
+			qr.Values[names[c]] = cell(c)
+		}
+		res.Rows = append(res.Rows, qr)
+	}
+	return res, nil
+}
+
+// matchRow reports whether a row satisfies all (or, with any, at least one) of the filters.
+func matchRow(filters []compiledFilter, cell func(int) string, any bool) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, f := range filters {
+		if f.match(cell(f.col)) == any {
+			return any
+		}
+	}
+	return !any
+}
+
+func (f compiledFilter) match(v string) bool {
+	lv, lf := strings.ToLower(strings.TrimSpace(v)), strings.ToLower(strings.TrimSpace(f.Value))
+	switch strings.ToLower(f.Op) {
+	case "eq":
+		if a, b, ok := numbers(v, f.Value); ok {
+			return a == b
+		}
+		return lv == lf
+	case "ne":
+		if a, b, ok := numbers(v, f.Value); ok {
+			return a != b
+		}
+		return lv != lf
+	case "contains":
+		return strings.Contains(lv, lf)
+	case "not_contains":
+		return !strings.Contains(lv, lf)
+	case "starts_with":
+		return strings.HasPrefix(lv, lf)
+	case "ends_with":
+		return strings.HasSuffix(lv, lf)
+	case "empty":
+		return lv == ""
+	case "not_empty":
+		return lv != ""
+	case "regex":
+		return f.re.MatchString(v)
+	case "gt", "gte", "lt", "lte":
+		var cmp int
+		if a, b, ok := numbers(v, f.Value); ok {
+			switch {
+			case a < b:
+				cmp = -1
+			case a > b:
+				cmp = 1
+			}
+		} else if lv == "" {
+			return false
+		} else {
+			// Text comparison works for ISO dates (2024-05-01) and plain strings.
+			cmp = strings.Compare(lv, lf)
+		}
+		switch strings.ToLower(f.Op) {
+		case "gt":
+			return cmp > 0
+		case "gte":
+			return cmp >= 0
+		case "lt":
+			return cmp < 0
+		default:
+			return cmp <= 0
+		}
+	}
+	return false
+}
+
+// numbers parses both values as numbers, tolerating thousands separators, currency symbols and percent signs.
+func numbers(a, b string) (float64, float64, bool) {
+	x, ok := parseNumber(a)
+	if !ok {
+		return 0, 0, false
+	}
+	y, ok := parseNumber(b)
+	return x, y, ok
+}
+
+func parseNumber(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimLeft(s, "$€£R ")
+	s = strings.TrimSuffix(s, "%")
+	s = strings.ReplaceAll(s, ",", "")
+	if s == "" {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	return f, err == nil
+}
+
+// FindReplaceOptions controls FindReplace. Without Range or Sheet every tab is searched.
+type FindReplaceOptions struct {
+	Range           string // A1 range to limit the search
+	Sheet           string // Tab title or ID to limit the search
+	MatchCase       bool
+	MatchEntireCell bool
+	Regex           bool
+	IncludeFormulas bool
+}
+
+// FindReplace replaces text across the spreadsheet, a tab or a range.
+func (s *SheetsService) FindReplace(ctx context.Context, spreadsheetId, find, replacement string, opts FindReplaceOptions) (*sheets.FindReplaceResponse, error) {
+	req := &sheets.FindReplaceRequest{
+		Find:            find,
+		Replacement:     replacement,
+		MatchCase:       opts.MatchCase,
+		MatchEntireCell: opts.MatchEntireCell,
+		SearchByRegex:   opts.Regex,
+		IncludeFormulas: opts.IncludeFormulas,
+		ForceSendFields: []string{"Replacement"},
+	}
+	switch {
+	case opts.Range != "":
+		gr, _, err := s.gridRange(ctx, spreadsheetId, opts.Range)
+		if err != nil {
+			return nil, err
+		}
+		req.Range = gr
+	case opts.Sheet != "":
+		p, err := s.ResolveSheet(ctx, spreadsheetId, opts.Sheet)
+		if err != nil {
+			return nil, err
+		}
+		req.SheetId = p.SheetId
+		req.ForceSendFields = append(req.ForceSendFields, "SheetId")
+	default:
+		req.AllSheets = true
+	}
+	resp, err := s.BatchUpdate(ctx, spreadsheetId, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{FindReplace: req}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Replies[0].FindReplace, nil
+}
@@ -0,0 +1,78 @@
+package sheets
+
+import (
+	"testing"
+)
+
+func TestFilterRows(t *testing.T) {
+	values := [][]interface{}{
+		{"Name", "Status", "Amount", "Due"},
+		{"Alpha", "Open", "1,200", "2024-05-01"},
+		{"Beta", "Closed", "300", "2024-04-15"},
+		{},
+		{"Gamma", "open", "$950.50", "2024-06-10"},
+		{"Delta", "Blocked"},
+	}
+	tests := []struct {
+		name    string
+		filters []RowFilter
+		opts    QueryOptions
+		want    []int
+	}{
+		{"eq ignores case", []RowFilter{{Column: "status", Op: "eq", Value: "OPEN"}}, QueryOptions{HasHeader: true}, []int{2, 5}},
+		{"numeric gt", []RowFilter{{Column: "Amount", Op: "gt", Value: "900"}}, QueryOptions{HasHeader: true}, []int{2, 5}},
+		{"date lt", []RowFilter{{Column: "Due", Op: "lt", Value: "2024-05-02"}}, QueryOptions{HasHeader: true}, []int{2, 3}},
+		{"empty", []RowFilter{{Column: "D", Op: "empty"}}, QueryOptions{HasHeader: true}, []int{6}},
+		{"match any", []RowFilter{{Column: "Name", Op: "eq", Value: "Beta"}, {Column: "Status", Op: "eq", Value: "Blocked"}}, QueryOptions{HasHeader: true, MatchAny: true}, []int{3, 6}},
+		{"match all", []RowFilter{{Column: "Status", Op: "contains", Value: "open"}, {Column: "Amount", Op: "lte", Value: "1000"}}, QueryOptions{HasHeader: true}, []int{5}},
+		{"regex", []RowFilter{{Column: "Name", Op: "regex", Value: "^[AB]"}}, QueryOptions{HasHeader: true}, []int{2, 3}},
+		{"limit", nil, QueryOptions{HasHeader: true, Limit: 2}, []int{2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := filterRows(values, 0, 0, tt.filters, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int
+			for _, r := range res.Rows {
+				got = append(got, r.Row)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected rows %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("expected rows %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+
+	res, err := filterRows(values, 0, 0, nil, QueryOptions{HasHeader: true, Limit: 1, Columns: []string{"Name", "C"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Truncated || res.Matched != 4 || res.Scanned != 4 {
+		t.Errorf("unexpected counts: %+v", res)
+	}
+	if v := res.Rows[0].Values; len(v) != 2 || v["Name"] != "Alpha" || v["Amount"] != "1,200" {
+		t.Errorf("unexpected projected values: %v", v)
+	}
+
+	// Without a header, columns are addressed by letter and rows are offset by the range start.
+	res, err = filterRows(values[1:], 1, 2, []RowFilter{{Column: "C", Op: "eq", Value: "Beta"}}, QueryOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Rows) != 1 || res.Rows[0].Row != 3 {
+		t.Errorf("unexpected headerless result: %+v", res)
+	}
+
+	if _, err := filterRows(values, 0, 0, []RowFilter{{Column: "Nope", Op: "eq"}}, QueryOptions{HasHeader: true}); err == nil {
+		t.Error("expected error for unknown column")
+	}
+	if _, err := filterRows(values, 0, 0, []RowFilter{{Column: "Name", Op: "like"}}, QueryOptions{HasHeader: true}); err == nil {
+		t.Error("expected error for unknown op")
+	}
+}
@@ -0,0 +1,284 @@
+package sheets
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// FormatSpec is a friendly description of formatting to apply to a range. Unset fields are left unchanged.
+type FormatSpec struct {
+	Bold                *bool      `json:"bold,omitempty"`
+	Italic              *bool      `json:"italic,omitempty"`
+	FontSize            int64      `json:"font_size,omitempty"`
+	TextColor           string     `json:"text_color,omitempty"`           // #RRGGBB
+	BackgroundColor     string     `json:"background_color,omitempty"`     // #RRGGBB
+	NumberFormat        string     `json:"number_format,omitempty"`        // Pattern such as "#,##0.00" or a type such as "PERCENT"
+	NumberFormatType    string     `json:"number_format_type,omitempty"`   // Type for a pattern: NUMBER (default), CURRENCY, PERCENT, DATE, TIME, DATE_TIME, SCIENTIFIC, TEXT
+	HorizontalAlignment string     `json:"horizontal_alignment,omitempty"` // LEFT, CENTER or RIGHT
+	Wrap                *bool      `json:"wrap,omitempty"`
+	Borders             string     `json:"borders,omitempty"` // all, outer, inner or none
+	BorderColor         string     `json:"border_color,omitempty"`
+	FreezeRows          *int64     `json:"freeze_rows,omitempty"`
+	FreezeColumns       *int64     `json:"freeze_columns,omitempty"`
+	ColumnWidth         int64      `json:"column_width,omitempty"` // Pixels, applied to every column in the range
+	AutoResizeColumns   bool       `json:"auto_resize_columns,omitempty"`
+	Conditional         []CondRule `json:"conditional,omitempty"`
+}
+
+// CondRule is a conditional formatting rule applied to the range. Either Formula (a custom formula such as
+// "=$C2>100") or Type (a Sheets condition type such as NUMBER_GREATER, TEXT_CONTAINS or BLANK) with Values is required.
+type CondRule struct {
+	Type            string   `json:"type,omitempty"`
+	Values          []string `json:"values,omitempty"`
+	Formula         string   `json:"formula,omitempty"`
+	Bold            *bool    `json:"bold,omitempty"`
+	TextColor       string   `json:"text_color,omitempty"`
+	BackgroundColor string   `json:"background_color,omitempty"`
+}
+
+// numberFormatTypes are the Sheets number format types accepted as a bare number_format.
+var numberFormatTypes = []string{"TEXT", "NUMBER", "PERCENT", "CURRENCY", "DATE", "TIME", "DATE_TIME", "SCIENTIFIC"}
+
+// ParseHexColor parses "#RRGGBB" or "RRGGBB" (also the short "#RGB") into a Sheets color.
+func ParseHexColor(s string) (*sheets.Color, error) {
+	h := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(h) == 3 {
+		h = string([]byte{h[0], h[0], h[1], h[1], h[2], h[2]})
+	}
+	if len(h) != 6 {
+		return nil, fmt.Errorf("invalid color %q, expected #RRGGBB", s)
+	}
+	v, err := strconv.ParseUint(h, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid color %q, expected #RRGGBB", s)
+	}
+	return &sheets.Color{
+		Red:             float64(v>>16&0xFF) / 255,
+		Green:           float64(v>>8&0xFF) / 255,
+		Blue:            float64(v&0xFF) / 255,
+		ForceSendFields: []string{"Red", "Green", "Blue"},
+	}, nil
+}
+
+// cellFormat builds the CellFormat and field mask (relative to the format) for the cell-level parts of spec.
+func (spec FormatSpec) cellFormat() (*sheets.CellFormat, []string, error) {
+	f := &sheets.CellFormat{}
+	var fields []string
+	text := &sheets.TextFormat{}
+	if spec.Bold != nil {
+		text.Bold = *spec.Bold
+		text.ForceSendFields = append(text.ForceSendFields, "Bold")
+		fields = append(fields, "textFormat.bold")
+	}
+	if spec.Italic != nil {
+		text.Italic = *spec.Italic
+		text.ForceSendFields = append(text.ForceSendFields, "Italic")
+		fields = append(fields, "textFormat.italic")
+	}
+	if spec.FontSize > 0 {
+		text.FontSize = spec.FontSize
+		fields = append(fields, "textFormat.fontSize")
+	}
+	if spec.TextColor != "" {
+		c, err := ParseHexColor(spec.TextColor)
+		if err != nil {
+			return nil, nil, err
+		}
+		text.ForegroundColor = c
+		fields = append(fields, "textFormat.foregroundColor")
+	}
+	if len(fields) > 0 {
+		f.TextFormat = text
+	}
+	if spec.BackgroundColor != "" {
+		c, err := ParseHexColor(spec.BackgroundColor)
+		if err != nil {
+			return nil, nil, err
+		}
+		f.BackgroundColor = c
+		fields = append(fields, "backgroundColor")
+	}
+	if spec.NumberFormat != "" {
+		nf := &sheets.NumberFormat{Type: strings.ToUpper(spec.NumberFormatType)}
+		if upper := strings.ToUpper(spec.NumberFormat); nf.Type == "" && slices.Contains(numberFormatTypes, upper) {
+			nf.Type = upper
+		} else {
+			nf.Pattern = spec.NumberFormat
+		}
+		if nf.Type == "" {
+			nf.Type = "NUMBER"
+		}
+		if !slices.Contains(numberFormatTypes, nf.Type) {
+			return nil, nil, fmt.Errorf("invalid number_format_type %q", spec.NumberFormatType)
+		}
+		f.NumberFormat = nf
+		fields = append(fields, "numberFormat")
+	}
+	if spec.HorizontalAlignment != "" {
+		a := strings.ToUpper(spec.HorizontalAlignment)
+		if a != "LEFT" && a != "CENTER" && a != "RIGHT" {
+			return nil, nil, fmt.Errorf("invalid horizontal_alignment %q, expected LEFT, CENTER or RIGHT", spec.HorizontalAlignment)
+		}
+		f.HorizontalAlignment = a
+		fields = append(fields, "horizontalAlignment")
+	}
+	if spec.Wrap != nil {
+		f.WrapStrategy = "OVERFLOW_CELL"
+		if *spec.Wrap {
+			f.WrapStrategy = "WRAP"
+		}
+		fields = append(fields, "wrapStrategy")
+	}
+	return f, fields, nil
+}
+
+// bordersRequest builds the UpdateBorders request for spec.Borders, or nil when unset.
+func (spec FormatSpec) bordersRequest(gr *sheets.GridRange) (*sheets.Request, error) {
+	if spec.Borders == "" {
+		return nil, nil
+	}
+	border := &sheets.Border{Style: "SOLID"}
+	if spec.BorderColor != "" {
+		c, err := ParseHexColor(spec.BorderColor)
+		if err != nil {
+			return nil, err
+		}
+		border.Color = c
+	}
+	ub := &sheets.UpdateBordersRequest{Range: gr}
+	switch strings.ToLower(spec.Borders) {
+	case "all":
+		ub.Top, ub.Bottom, ub.Left, ub.Right = border, border, border, border
+		ub.InnerHorizontal, ub.InnerVertical = border, border
+	case "outer":
+		ub.Top, ub.Bottom, ub.Left, ub.Right = border, border, border, border
+	case "inner":
+		ub.InnerHorizontal, ub.InnerVertical = border, border
+	case "none":
+		none := &sheets.Border{Style: "NONE"}
+		ub.Top, ub.Bottom, ub.Left, ub.Right = none, none, none, none
+		ub.InnerHorizontal, ub.InnerVertical = none, none
+	default:
+		return nil, fmt.Errorf("invalid borders %q, expected all, outer, inner or none", spec.Borders)
+	}
+	return &sheets.Request{UpdateBorders: ub}, nil
+}
+
+// conditionalRequest builds an AddConditionalFormatRule request for one rule.
+func (r CondRule) conditionalRequest(gr *sheets.GridRange) (*sheets.Request, error) {
+	cond := &sheets.BooleanCondition{Type: strings.ToUpper(r.Type)}
+	values := r.Values
+	if r.Formula != "" {
+		cond.Type = "CUSTOM_FORMULA"
+		values = []string{r.Formula}
+	}
+	if cond.Type == "" {
+		return nil, fmt.Errorf("conditional rule needs a type or a formula")
+	}
+	for _, v := range values {
+		cond.Values = append(cond.Values, &sheets.ConditionValue{UserEnteredValue: v})
+	}
+	spec := FormatSpec{Bold: r.Bold, TextColor: r.TextColor, BackgroundColor: r.BackgroundColor}
+	f, fields, err := spec.cellFormat()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("conditional rule needs bold, text_color or background_color")
+	}
+	return &sheets.Request{AddConditionalFormatRule: &sheets.AddConditionalFormatRuleRequest{
+		Rule: &sheets.ConditionalFormatRule{
+			Ranges:      []*sheets.GridRange{gr},
+			BooleanRule: &sheets.BooleanRule{Condition: cond, Format: f},
+		},
+		ForceSendFields: []string{"Index"},
+	}}, nil
+}
+
+// Requests translates the spec into batchUpdate requests for the given range, which must carry its sheet ID.
+func (spec FormatSpec) Requests(gr *sheets.GridRange) ([]*sheets.Request, error) {
+	var reqs []*sheets.Request
+	f, fields, err := spec.cellFormat()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) > 0 {
+		mask := make([]string, len(fields))
+		for i, field := range fields {
+			mask[i] = "userEnteredFormat." + field
+		}
+		reqs = append(reqs, &sheets.Request{RepeatCell: &sheets.RepeatCellRequest{
+			Range:  gr,
+			Cell:   &sheets.CellData{UserEnteredFormat: f},
+			Fields: strings.Join(mask, ","),
+		}})
+	}
+	borders, err := spec.bordersRequest(gr)
+	if err != nil {
+		return nil, err
+	}
+	if borders != nil {
+		reqs = append(reqs, borders)
+	}
+	if spec.FreezeRows != nil || spec.FreezeColumns != nil {
+		grid := &sheets.GridProperties{}
+		var mask []string
+		if spec.FreezeRows != nil {
+			grid.FrozenRowCount = *spec.FreezeRows
+			grid.ForceSendFields = append(grid.ForceSendFields, "FrozenRowCount")
+			mask = append(mask, "gridProperties.frozenRowCount")
+		}
+		if spec.FreezeColumns != nil {
+			grid.FrozenColumnCount = *spec.FreezeColumns
+			grid.ForceSendFields = append(grid.ForceSendFields, "FrozenColumnCount")
+			mask = append(mask, "gridProperties.frozenColumnCount")
+		}
+		reqs = append(reqs, &sheets.Request{UpdateSheetProperties: &sheets.UpdateSheetPropertiesRequest{
+			Properties: &sheets.SheetProperties{SheetId: gr.SheetId, GridProperties: grid, ForceSendFields: []string{"SheetId"}},
+			Fields:     strings.Join(mask, ","),
+		}})
+	}
+	columns := &sheets.DimensionRange{SheetId: gr.SheetId, Dimension: "COLUMNS", StartIndex: gr.StartColumnIndex, EndIndex: gr.EndColumnIndex, ForceSendFields: []string{"SheetId"}}
+	if spec.ColumnWidth > 0 {
+		reqs = append(reqs, &sheets.Request{UpdateDimensionProperties: &sheets.UpdateDimensionPropertiesRequest{
+			Range:      columns,
+			Properties: &sheets.DimensionProperties{PixelSize: spec.ColumnWidth},
+			Fields:     "pixelSize",
+		}})
+	} else if spec.AutoResizeColumns {
+		reqs = append(reqs, &sheets.Request{AutoResizeDimensions: &sheets.AutoResizeDimensionsRequest{Dimensions: columns}})
+	}
+	for _, rule := range spec.Conditional {
+		req, err := rule.conditionalRequest(gr)
+		if err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, req)
+	}
+	if len(reqs) == 0 {
+		return nil, fmt.Errorf("format spec has nothing to apply")
+	}
+	return reqs, nil
+}
+
+// FormatRange applies spec to an A1 range. A range without a sheet name targets the first tab.
+// It returns the number of batchUpdate requests sent.
+func (s *SheetsService) FormatRange(ctx context.Context, spreadsheetId, a1 string, spec FormatSpec) (int, error) {
+	gr, _, err := s.gridRange(ctx, spreadsheetId, a1)
+	if err != nil {
+		return 0, err
+	}
+	reqs, err := spec.Requests(gr)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := s.BatchUpdate(ctx, spreadsheetId, &sheets.BatchUpdateSpreadsheetRequest{Requests: reqs}); err != nil {
+		return 0, err
+	}
+	return len(reqs), nil
+}
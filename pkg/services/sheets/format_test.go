@@ -0,0 +1,78 @@
+package sheets
+
+import (
+	"testing"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+func TestParseHexColor(t *testing.T) {
+	c, err := ParseHexColor("#FF8000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := colorHex(c); got != "#FF8000" {
+		t.Errorf("expected #FF8000, got %s", got)
+	}
+	if c, err = ParseHexColor("0f0"); err != nil || colorHex(c) != "#00FF00" {
+		t.Errorf("short form: got %v, %v", c, err)
+	}
+	for _, bad := range []string{"", "#12345", "#GGGGGG", "red"} {
+		if _, err := ParseHexColor(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestFormatSpecRequests(t *testing.T) {
+	yes := true
+	zero := int64(0)
+	one := int64(1)
+	gr := &sheets.GridRange{SheetId: 7, EndRowIndex: 1, EndColumnIndex: 4}
+
+	reqs, err := FormatSpec{Bold: &yes, BackgroundColor: "#EEEEEE", NumberFormat: "percent", FreezeRows: &one, FreezeColumns: &zero, ColumnWidth: 120}.Requests(gr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reqs) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(reqs))
+	}
+	rc := reqs[0].RepeatCell
+	if rc == nil || rc.Fields != "userEnteredFormat.textFormat.bold,userEnteredFormat.backgroundColor,userEnteredFormat.numberFormat" {
+		t.Errorf("unexpected repeatCell: %+v", rc)
+	} else if nf := rc.Cell.UserEnteredFormat.NumberFormat; nf.Type != "PERCENT" || nf.Pattern != "" {
+		t.Errorf("expected bare PERCENT type, got %+v", nf)
+	}
+	if usp := reqs[1].UpdateSheetProperties; usp == nil || usp.Fields != "gridProperties.frozenRowCount,gridProperties.frozenColumnCount" || usp.Properties.SheetId != 7 {
+		t.Errorf("unexpected sheet properties update: %+v", usp)
+	}
+	if udp := reqs[2].UpdateDimensionProperties; udp == nil || udp.Range.EndIndex != 4 || udp.Properties.PixelSize != 120 {
+		t.Errorf("unexpected dimension update: %+v", udp)
+	}
+
+	reqs, err = FormatSpec{NumberFormat: "#,##0.00", Borders: "outer", Conditional: []CondRule{{Formula: "=$B1>100", BackgroundColor: "#F4CCCC"}}}.Requests(gr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nf := reqs[0].RepeatCell.Cell.UserEnteredFormat.NumberFormat; nf.Type != "NUMBER" || nf.Pattern != "#,##0.00" {
+		t.Errorf("expected NUMBER pattern, got %+v", nf)
+	}
+	if ub := reqs[1].UpdateBorders; ub == nil || ub.Top == nil || ub.InnerHorizontal != nil {
+		t.Errorf("expected outer borders only, got %+v", ub)
+	}
+	if cf := reqs[2].AddConditionalFormatRule; cf == nil || cf.Rule.BooleanRule.Condition.Type != "CUSTOM_FORMULA" {
+		t.Errorf("expected custom formula rule, got %+v", cf)
+	}
+
+	for _, bad := range []FormatSpec{
+		{},
+		{Borders: "thick"},
+		{HorizontalAlignment: "middle"},
+		{NumberFormat: "0.0", NumberFormatType: "money"},
+		{Conditional: []CondRule{{Type: "NUMBER_GREATER", Values: []string{"5"}}}},
+	} {
+		if _, err := bad.Requests(gr); err == nil {
+			t.Errorf("expected error for %+v", bad)
+		}
+	}
+}
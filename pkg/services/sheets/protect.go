@@ -0,0 +1,118 @@
+package sheets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// ProtectRange protects an A1 range, or a whole tab when a1 is just a tab title. With warningOnly,
+// editors are only warned before changing it; otherwise only the owner and editors may edit it.
+// It returns the protected range ID.
+func (s *SheetsService) ProtectRange(ctx context.Context, spreadsheetId, a1, description string, warningOnly bool, editors []string) (int64, error) {
+	gr, _, err := s.gridRange(ctx, spreadsheetId, a1)
+	if err != nil {
+		return 0, err
+	}
+	pr := &sheets.ProtectedRange{Range: gr, Description: description, WarningOnly: warningOnly}
+	if len(editors) > 0 {
+		if warningOnly {
+			return 0, fmt.Errorf("editors cannot be set on a warning-only protection")
+		}
+		pr.Editors = &sheets.Editors{Users: editors}
+	}
+	resp, err := s.BatchUpdate(ctx, spreadsheetId, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{AddProtectedRange: &sheets.AddProtectedRangeRequest{ProtectedRange: pr}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return resp.Replies[0].AddProtectedRange.ProtectedRange.ProtectedRangeId, nil
+}
+
+// ValidationSpec is a friendly description of a data validation rule.
+type ValidationSpec struct {
+	// Type is list (dropdown of Values), range (dropdown from the cells in Values[0], e.g. "Lists!A2:A"),
+	// checkbox, formula (custom formula in Values[0]), email, url, date, or any Sheets condition type
+	// such as NUMBER_BETWEEN, NUMBER_GREATER, TEXT_CONTAINS or DATE_AFTER with its Values.
+	Type         string
+	Values       []string
+	Strict       bool   // Reject invalid input instead of only flagging it
+	InputMessage string // Help text shown when the cell is selected
+}
+
+// rule translates the spec into an API validation rule.
+func (v ValidationSpec) rule() (*sheets.DataValidationRule, error) {
+	values := v.Values
+	need := func(n int) error {
+		if len(values) < n {
+			return fmt.Errorf("validation type %q needs %d value(s)", v.Type, n)
+		}
+		return nil
+	}
+	cond := &sheets.BooleanCondition{}
+	dropdown := false
+	switch t := strings.ToLower(v.Type); t {
+	case "list":
+		if err := need(1); err != nil {
+			return nil, err
+		}
+		cond.Type, dropdown = "ONE_OF_LIST", true
+	case "range":
+		if err := need(1); err != nil {
+			return nil, err
+		}
+		cond.Type, dropdown = "ONE_OF_RANGE", true
+		ref := values[0]
+		if !strings.HasPrefix(ref, "=") {
+			ref = "=" + ref
+		}
+		values = []string{ref}
+	case "checkbox":
+		cond.Type = "BOOLEAN"
+	case "formula":
+		if err := need(1); err != nil {
+			return nil, err
+		}
+		cond.Type = "CUSTOM_FORMULA"
+	case "email":
+		cond.Type, values = "TEXT_IS_EMAIL", nil
+	case "url":
+		cond.Type, values = "TEXT_IS_URL", nil
+	case "date":
+		cond.Type, values = "DATE_IS_VALID", nil
+	case "":
+		return nil, fmt.Errorf("validation type is required")
+	default:
+		cond.Type = strings.ToUpper(t)
+	}
+	for _, val := range values {
+		cond.Values = append(cond.Values, &sheets.ConditionValue{UserEnteredValue: val})
+	}
+	return &sheets.DataValidationRule{
+		Condition:    cond,
+		Strict:       v.Strict,
+		ShowCustomUi: dropdown,
+		InputMessage: v.InputMessage,
+	}, nil
+}
+
+// SetValidation applies a data validation rule to an A1 range; a nil spec removes validation from it.
+func (s *SheetsService) SetValidation(ctx context.Context, spreadsheetId, a1 string, spec *ValidationSpec) error {
+	gr, _, err := s.gridRange(ctx, spreadsheetId, a1)
+	if err != nil {
+		return err
+	}
+	req := &sheets.SetDataValidationRequest{Range: gr}
+	if spec != nil {
+		if req.Rule, err = spec.rule(); err != nil {
+			return err
+		}
+	}
+	_, err = s.BatchUpdate(ctx, spreadsheetId, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{SetDataValidation: req}},
+	})
+	return err
+}
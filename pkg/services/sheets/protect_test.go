@@ -0,0 +1,40 @@
+package sheets
+
+import "testing"
+
+func TestValidationRule(t *testing.T) {
+	tests := []struct {
+		spec     ValidationSpec
+		wantType string
+		values   []string
+		dropdown bool
+	}{
+		{ValidationSpec{Type: "list", Values: []string{"Open", "Done"}}, "ONE_OF_LIST", []string{"Open", "Done"}, true},
+		{ValidationSpec{Type: "range", Values: []string{"Lists!A2:A"}}, "ONE_OF_RANGE", []string{"=Lists!A2:A"}, true},
+		{ValidationSpec{Type: "checkbox"}, "BOOLEAN", nil, false},
+		{ValidationSpec{Type: "email", Values: []string{"ignored"}}, "TEXT_IS_EMAIL", nil, false},
+		{ValidationSpec{Type: "number_between", Values: []string{"1", "10"}}, "NUMBER_BETWEEN", []string{"1", "10"}, false},
+	}
+	for _, tt := range tests {
+		r, err := tt.spec.rule()
+		if err != nil {
+			t.Errorf("%s: %v", tt.spec.Type, err)
+			continue
+		}
+		if r.Condition.Type != tt.wantType || r.ShowCustomUi != tt.dropdown || len(r.Condition.Values) != len(tt.values) {
+			t.Errorf("%s: unexpected rule %+v", tt.spec.Type, r)
+			continue
+		}
+		for i, v := range tt.values {
+			if r.Condition.Values[i].UserEnteredValue != v {
+				t.Errorf("%s: expected value %q, got %q", tt.spec.Type, v, r.Condition.Values[i].UserEnteredValue)
+			}
+		}
+	}
+
+	for _, bad := range []ValidationSpec{{}, {Type: "list"}, {Type: "formula"}} {
+		if _, err := bad.rule(); err == nil {
+			t.Errorf("expected error for %+v", bad)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package sheets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// Record is a JSON object with its keys in their original order.
+type Record struct {
+	Keys   []string
+	Values map[string]interface{}
+}
+
+// ParseRecords parses a JSON object or an array of objects, keeping key order so that new columns
+// are added in the order the caller wrote them.
+func ParseRecords(text string) ([]Record, error) {
+	text = strings.TrimSpace(text)
+	var raws []json.RawMessage
+	if strings.HasPrefix(text, "[") {
+		if err := json.Unmarshal([]byte(text), &raws); err != nil {
+			return nil, fmt.Errorf("unable to parse rows JSON: %w", err)
+		}
+	} else {
+		raws = []json.RawMessage{json.RawMessage(text)}
+	}
+	records := make([]Record, 0, len(raws))
+	for i, raw := range raws {
+		rec := Record{Values: map[string]interface{}{}}
+		if err := json.Unmarshal(raw, &rec.Values); err != nil {
+			return nil, fmt.Errorf("row %d is not a JSON object: %w", i+1, err)
+		}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		if _, err := dec.Token(); err != nil { // opening brace
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", i+1, err)
+			}
+			key := tok.(string)
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, fmt.Errorf("row %d: %w", i+1, err)
+			}
+			if !slices.Contains(rec.Keys, key) {
+				rec.Keys = append(rec.Keys, key)
+			}
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// mapRecords lays records out in header order. Keys match headers case-insensitively; unknown keys are
+// appended as new headers when addMissing is set and are an error otherwise. It returns the rows and
+// the headers that have to be added.
+func mapRecords(headers []string, records []Record, addMissing bool) ([][]interface{}, []string, error) {
+	index := map[string]int{}
+	for i, h := range headers {
+		if k := strings.ToLower(strings.TrimSpace(h)); k != "" {
+			if _, dup := index[k]; !dup {
+				index[k] = i
+			}
+		}
+	}
+	width := len(headers)
+	var added, unknown []string
+	for _, rec := range records {
+		for _, key := range rec.Keys {
+			k := strings.ToLower(strings.TrimSpace(key))
+			if _, ok := index[k]; ok {
+				continue
+			}
+			if !addMissing {
+				if !slices.Contains(unknown, key) {
+					unknown = append(unknown, key)
+				}
+				continue
+			}
+			index[k] = width
+			width++
+			added = append(added, key)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, nil, fmt.Errorf("no column for %s (headers: %s); set add_missing_columns to add them", strings.Join(unknown, ", "), strings.Join(headers, ", "))
+	}
+
+	rows := make([][]interface{}, len(records))
+	for r, rec := range records {
+		last := -1
+		for _, key := range rec.Keys {
+			last = max(last, index[strings.ToLower(strings.TrimSpace(key))])
+		}
+		row := make([]interface{}, last+1)
+		for i := range row {
+			row[i] = ""
+		}
+		for _, key := range rec.Keys {
+			row[index[strings.ToLower(strings.TrimSpace(key))]] = cellValue(rec.Values[key])
+		}
+		rows[r] = row
+	}
+	return rows, added, nil
+}
+
+// cellValue converts a decoded JSON value into something a cell can hold; objects and arrays are kept as JSON text.
+func cellValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string, float64, bool:
+		return v
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+// AppendByHeaderResult describes what AppendRecords wrote.
+type AppendByHeaderResult struct {
+	UpdatedRange string
+	Rows         int
+	AddedColumns []string
+	Preview      *WritePreview // Set instead of writing on a dry run
+}
+
+// AppendRecords appends records below the data of a tab, placing each value under the header
+// (in headerRow, 1-based) matching its key. sheetRef is a tab title or ID; empty means the first tab.
+// With raw, values are stored as-is rather than parsed as if typed by a user (numbers, dates, formulas).
+// With dryRun nothing is written and the result carries a preview of the new header and data cells.
+func (s *SheetsService) AppendRecords(ctx context.Context, spreadsheetId, sheetRef string, headerRow int, records []Record, addMissing, raw, dryRun bool) (*AppendByHeaderResult, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no rows to append")
+	}
+	if headerRow < 1 {
+		headerRow = 1
+	}
+	p, err := s.ResolveSheet(ctx, spreadsheetId, sheetRef)
+	if err != nil {
+		return nil, err
+	}
+	tab := quoteSheetTitle(p.Title)
+	headerValues, err := s.ReadValues(ctx, spreadsheetId, fmt.Sprintf("%s!%d:%d", tab, headerRow, headerRow), ReadOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var headers []string
+	if len(headerValues) > 0 {
+		for _, v := range headerValues[0] {
+			headers = append(headers, fmt.Sprint(v))
+		}
+	}
+	if len(headers) == 0 && !addMissing {
+		return nil, fmt.Errorf("tab %q has no header row at row %d; set add_missing_columns to create it", p.Title, headerRow)
+	}
+	rows, added, err := mapRecords(headers, records, addMissing)
+	if err != nil {
+		return nil, err
+	}
+
+	width := int64(len(headers) + len(added))
+	newHeaders := make([]interface{}, len(added))
+	for i, h := range added {
+		newHeaders[i] = h
+	}
+	if dryRun {
+		preview, err := s.previewAppend(ctx, spreadsheetId, p.Title, headerRow, 0, rows)
+		if err != nil {
+			return nil, err
+		}
+		headerChanges, _ := diffCells(p.Title, headerRow-1, len(headers), nil, [][]interface{}{newHeaders})
+		preview.Changes = append(headerChanges, preview.Changes...)
+		return &AppendByHeaderResult{Rows: len(rows), AddedColumns: added, Preview: preview}, nil
+	}
+	if len(added) > 0 {
+		if grid := p.GridProperties; grid != nil && width > grid.ColumnCount {
+			_, err := s.BatchUpdate(ctx, spreadsheetId, &sheets.BatchUpdateSpreadsheetRequest{
+				Requests: []*sheets.Request{{AppendDimension: &sheets.AppendDimensionRequest{
+					SheetId: p.SheetId, Dimension: "COLUMNS", Length: width - grid.ColumnCount, ForceSendFields: []string{"SheetId"},
+				}}},
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		rng := fmt.Sprintf("%s!%s%d", tab, ColumnName(len(headers)), headerRow)
+		if _, err := s.srv.Spreadsheets.Values.Update(spreadsheetId, rng, &sheets.ValueRange{Values: [][]interface{}{newHeaders}}).ValueInputOption("RAW").Context(ctx).Do(); err != nil {
+			return nil, fmt.Errorf("unable to add header columns: %w", err)
+		}
+	}
+
+	input := "USER_ENTERED"
+	if raw {
+		input = "RAW"
+	}
+	rng := fmt.Sprintf("%s!A%d:%s", tab, headerRow, ColumnName(int(width)-1))
+	resp, err := s.srv.Spreadsheets.Values.Append(spreadsheetId, rng, &sheets.ValueRange{Values: rows}).
+		ValueInputOption(input).
+		InsertDataOption("INSERT_ROWS").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to append data: %w", err)
+	}
+	out := &AppendByHeaderResult{Rows: len(rows), AddedColumns: added}
+	if resp.Updates != nil {
+		out.UpdatedRange = resp.Updates.UpdatedRange
+	}
+	return out, nil
+}
+
+// ColumnValues returns the values below the header named header (matched case-insensitively) in
+// headerRow (1-based) of a tab, or nil if the tab has no such header. sheetRef is a tab title or ID;
+// empty means the first tab.
+func (s *SheetsService) ColumnValues(ctx context.Context, spreadsheetId, sheetRef string, headerRow int, header string) ([]string, error) {
+	if headerRow < 1 {
+		headerRow = 1
+	}
+	p, err := s.ResolveSheet(ctx, spreadsheetId, sheetRef)
+	if err != nil {
+		return nil, err
+	}
+	tab := quoteSheetTitle(p.Title)
+	headerValues, err := s.ReadValues(ctx, spreadsheetId, fmt.Sprintf("%s!%d:%d", tab, headerRow, headerRow), ReadOptions{})
+	if err != nil || len(headerValues) == 0 {
+		return nil, err
+	}
+	col := slices.IndexFunc(headerValues[0], func(v interface{}) bool { return strings.EqualFold(fmt.Sprint(v), header) })
+	if col < 0 {
+		return nil, nil
+	}
+	name := ColumnName(col)
+	values, err := s.ReadValues(ctx, spreadsheetId, fmt.Sprintf("%s!%s%d:%s", tab, name, headerRow+1, name), ReadOptions{})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(values))
+	for _, row := range values {
+		if len(row) > 0 {
+			out = append(out, fmt.Sprint(row[0]))
+		}
+	}
+	return out, nil
+}
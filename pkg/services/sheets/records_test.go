@@ -0,0 +1,58 @@
+package sheets
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRecords(t *testing.T) {
+	recs, err := ParseRecords(`[{"b": 1, "a": "x"}, {"c": null, "b": [1, 2]}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 || !reflect.DeepEqual(recs[0].Keys, []string{"b", "a"}) || !reflect.DeepEqual(recs[1].Keys, []string{"c", "b"}) {
+		t.Errorf("unexpected records: %+v", recs)
+	}
+	if recs, err = ParseRecords(`{"only": true}`); err != nil || len(recs) != 1 {
+		t.Errorf("single object: %+v, %v", recs, err)
+	}
+	if _, err := ParseRecords(`[1, 2]`); err == nil {
+		t.Error("expected error for non-object rows")
+	}
+}
+
+func TestMapRecords(t *testing.T) {
+	headers := []string{"Date", "Event", "", "Count"}
+	recs, err := ParseRecords(`[{"count": 3, "date": "2024-05-01"}, {"Event": "deploy", "tags": ["a"], "Owner": "ops"}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := mapRecords(headers, recs, false); err == nil {
+		t.Error("expected error for unknown keys without addMissing")
+	}
+
+	rows, added, err := mapRecords(headers, recs, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(added, []string{"tags", "Owner"}) {
+		t.Errorf("unexpected added columns: %v", added)
+	}
+	want := [][]interface{}{
+		{"2024-05-01", "", "", float64(3)},
+		{"", "deploy", "", "", `["a"]`, "ops"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("expected %v, got %v", want, rows)
+	}
+
+	// An empty sheet gets its headers from the keys.
+	rows, added, err = mapRecords(nil, recs[:1], true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(added, []string{"count", "date"}) || !reflect.DeepEqual(rows, [][]interface{}{{float64(3), "2024-05-01"}}) {
+		t.Errorf("unexpected result for empty sheet: %v %v", added, rows)
+	}
+}
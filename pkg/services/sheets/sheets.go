@@ -3,8 +3,11 @@ package sheets
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 	"google.golang.org/api/sheets/v4"
 )
@@ -23,29 +26,72 @@ func New(ctx context.Context, opts ...option.ClientOption) (*SheetsService, erro
 	return &SheetsService{srv: srv}, nil
 }
 
+// Ping makes a cheap request to check that the API is enabled and its scope granted.
+func (s *SheetsService) Ping(ctx context.Context) error {
+	// There is no cheap call without a spreadsheet: a missing spreadsheet (404) means the API answered
+	_, err := s.srv.Spreadsheets.Get("health-check").Fields("spreadsheetId").Context(ctx).Do()
+	var apiErr *googleapi.Error
+	if err == nil || errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+		return nil
+	}
+	return fmt.Errorf("unable to reach Sheets API: %w", err)
+}
+
 // CreateSpreadsheet creates a new spreadsheet.
-func (s *SheetsService) CreateSpreadsheet(title string) (*sheets.Spreadsheet, error) {
+func (s *SheetsService) CreateSpreadsheet(ctx context.Context, title string) (*sheets.Spreadsheet, error) {
 	sp := &sheets.Spreadsheet{
 		Properties: &sheets.SpreadsheetProperties{
 			Title: title,
 		},
 	}
-	resp, err := s.srv.Spreadsheets.Create(sp).Do()
+	resp, err := s.srv.Spreadsheets.Create(sp).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to create spreadsheet: %w", err)
 	}
 	return resp, nil
 }
 
+// ReadOptions controls how values are rendered when reading.
+type ReadOptions struct {
+	ValueRender    string // FORMATTED_VALUE (default), UNFORMATTED_VALUE or FORMULA
+	DateTimeRender string // SERIAL_NUMBER (default) or FORMATTED_STRING; ignored for FORMATTED_VALUE
+}
+
 // ReadValues reads values from a range.
-func (s *SheetsService) ReadValues(spreadsheetId string, rangeName string) ([][]interface{}, error) {
-	resp, err := s.srv.Spreadsheets.Values.Get(spreadsheetId, rangeName).Do()
+func (s *SheetsService) ReadValues(ctx context.Context, spreadsheetId string, rangeName string, opts ReadOptions) ([][]interface{}, error) {
+	call := s.srv.Spreadsheets.Values.Get(spreadsheetId, rangeName)
+	if opts.ValueRender != "" {
+		call.ValueRenderOption(opts.ValueRender)
+	}
+	if opts.DateTimeRender != "" {
+		call.DateTimeRenderOption(opts.DateTimeRender)
+	}
+	resp, err := call.Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve data from sheet: %w", err)
 	}
 	return resp.Values, nil
 }
 
+// BatchReadValues reads several ranges in one request. The result has one ValueRange per requested range, in order.
+func (s *SheetsService) BatchReadValues(ctx context.Context, spreadsheetId string, ranges []string, opts ReadOptions) ([]*sheets.ValueRange, error) {
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("at least one range is required")
+	}
+	call := s.srv.Spreadsheets.Values.BatchGet(spreadsheetId).Ranges(ranges...)
+	if opts.ValueRender != "" {
+		call.ValueRenderOption(opts.ValueRender)
+	}
+	if opts.DateTimeRender != "" {
+		call.DateTimeRenderOption(opts.DateTimeRender)
+	}
+	resp, err := call.Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve data from sheet: %w", err)
+	}
+	return resp.ValueRanges, nil
+}
+
 // parseValuesJSON parses a JSON string into a slice of value rows.
 // Accepts either [][]interface{} (array of arrays) or []interface{} (single row).
 func (s *SheetsService) parseValuesJSON(valuesJSON string) ([][]interface{}, error) {
@@ -66,7 +112,7 @@ func (s *SheetsService) parseValuesJSON(valuesJSON string) ([][]interface{}, err
 
 // AppendValues appends values to a sheet.
 // values should be a JSON string representing [][]interface{} or []interface{} (single row)
-func (s *SheetsService) AppendValues(spreadsheetId string, rangeName string, valuesJSON string) (*sheets.AppendValuesResponse, error) {
+func (s *SheetsService) AppendValues(ctx context.Context, spreadsheetId string, rangeName string, valuesJSON string) (*sheets.AppendValuesResponse, error) {
 	data, err := s.parseValuesJSON(valuesJSON)
 	if err != nil {
 		return nil, err
@@ -77,7 +123,7 @@ func (s *SheetsService) AppendValues(spreadsheetId string, rangeName string, val
 	}
 
 	// valueInputOption: USER_ENTERED allows formulas and number parsing
-	resp, err := s.srv.Spreadsheets.Values.Append(spreadsheetId, rangeName, vr).ValueInputOption("USER_ENTERED").Do()
+	resp, err := s.srv.Spreadsheets.Values.Append(spreadsheetId, rangeName, vr).ValueInputOption("USER_ENTERED").Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to append data: %w", err)
 	}
@@ -85,7 +131,7 @@ func (s *SheetsService) AppendValues(spreadsheetId string, rangeName string, val
 }
 
 // UpdateValues updates values in a range.
-func (s *SheetsService) UpdateValues(spreadsheetId string, rangeName string, valuesJSON string) (*sheets.UpdateValuesResponse, error) {
+func (s *SheetsService) UpdateValues(ctx context.Context, spreadsheetId string, rangeName string, valuesJSON string) (*sheets.UpdateValuesResponse, error) {
 	data, err := s.parseValuesJSON(valuesJSON)
 	if err != nil {
 		return nil, err
@@ -95,7 +141,7 @@ func (s *SheetsService) UpdateValues(spreadsheetId string, rangeName string, val
 		Values: data,
 	}
 
-	resp, err := s.srv.Spreadsheets.Values.Update(spreadsheetId, rangeName, vr).ValueInputOption("USER_ENTERED").Do()
+	resp, err := s.srv.Spreadsheets.Values.Update(spreadsheetId, rangeName, vr).ValueInputOption("USER_ENTERED").Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to update data: %w", err)
 	}
@@ -103,8 +149,8 @@ func (s *SheetsService) UpdateValues(spreadsheetId string, rangeName string, val
 }
 
 // GetSpreadsheet returns spreadsheet metadata including sheet IDs and titles.
-func (s *SheetsService) GetSpreadsheet(spreadsheetId string) (*sheets.Spreadsheet, error) {
-	resp, err := s.srv.Spreadsheets.Get(spreadsheetId).Do()
+func (s *SheetsService) GetSpreadsheet(ctx context.Context, spreadsheetId string) (*sheets.Spreadsheet, error) {
+	resp, err := s.srv.Spreadsheets.Get(spreadsheetId).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to get spreadsheet: %w", err)
 	}
@@ -112,8 +158,8 @@ func (s *SheetsService) GetSpreadsheet(spreadsheetId string) (*sheets.Spreadshee
 }
 
 // BatchUpdate applies one or more update requests (add sheet, rename sheet, etc.).
-func (s *SheetsService) BatchUpdate(spreadsheetId string, req *sheets.BatchUpdateSpreadsheetRequest) (*sheets.BatchUpdateSpreadsheetResponse, error) {
-	resp, err := s.srv.Spreadsheets.BatchUpdate(spreadsheetId, req).Do()
+func (s *SheetsService) BatchUpdate(ctx context.Context, spreadsheetId string, req *sheets.BatchUpdateSpreadsheetRequest) (*sheets.BatchUpdateSpreadsheetResponse, error) {
+	resp, err := s.srv.Spreadsheets.BatchUpdate(spreadsheetId, req).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to batch update: %w", err)
 	}
@@ -121,10 +167,22 @@ func (s *SheetsService) BatchUpdate(spreadsheetId string, req *sheets.BatchUpdat
 }
 
 // ClearValues clears values (and optionally format) in a range.
-func (s *SheetsService) ClearValues(spreadsheetId string, rangeName string) (*sheets.ClearValuesResponse, error) {
-	resp, err := s.srv.Spreadsheets.Values.Clear(spreadsheetId, rangeName, &sheets.ClearValuesRequest{}).Do()
+func (s *SheetsService) ClearValues(ctx context.Context, spreadsheetId string, rangeName string) (*sheets.ClearValuesResponse, error) {
+	resp, err := s.srv.Spreadsheets.Values.Clear(spreadsheetId, rangeName, &sheets.ClearValuesRequest{}).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to clear range: %w", err)
 	}
 	return resp, nil
 }
+
+// BatchClearValues clears values in several ranges in one request and returns the ranges that were cleared.
+func (s *SheetsService) BatchClearValues(ctx context.Context, spreadsheetId string, ranges []string) ([]string, error) {
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("at least one range is required")
+	}
+	resp, err := s.srv.Spreadsheets.Values.BatchClear(spreadsheetId, &sheets.BatchClearValuesRequest{Ranges: ranges}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to clear ranges: %w", err)
+	}
+	return resp.ClearedRanges, nil
+}
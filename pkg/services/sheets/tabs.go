@@ -0,0 +1,127 @@
+package sheets
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// findSheet returns the properties of the tab matching ref, which may be a tab title
+// (case-insensitive) or a numeric sheet ID.
+func findSheet(sp *sheets.Spreadsheet, ref string) (*sheets.SheetProperties, error) {
+	ref = strings.TrimSpace(ref)
+	id, idErr := strconv.ParseInt(ref, 10, 64)
+	var byTitle *sheets.SheetProperties
+	for _, sh := range sp.Sheets {
+		p := sh.Properties
+		if p == nil {
+			continue
+		}
+		if p.Title == ref {
+			return p, nil
+		}
+		if byTitle == nil && strings.EqualFold(p.Title, ref) {
+			byTitle = p
+		}
+	}
+	if byTitle != nil {
+		return byTitle, nil
+	}
+	if idErr == nil {
+		for _, sh := range sp.Sheets {
+			if sh.Properties != nil && sh.Properties.SheetId == id {
+				return sh.Properties, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no tab named or with ID %q", ref)
+}
+
+// ResolveSheet looks up a tab by title or numeric sheet ID; an empty ref means the first tab.
+func (s *SheetsService) ResolveSheet(ctx context.Context, spreadsheetId, ref string) (*sheets.SheetProperties, error) {
+	sp, err := s.srv.Spreadsheets.Get(spreadsheetId).Fields("sheets.properties").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get spreadsheet: %w", err)
+	}
+	if ref == "" {
+		if len(sp.Sheets) == 0 || sp.Sheets[0].Properties == nil {
+			return nil, fmt.Errorf("spreadsheet has no tabs")
+		}
+		return sp.Sheets[0].Properties, nil
+	}
+	return findSheet(sp, ref)
+}
+
+// gridRange parses an A1 range and resolves its tab, so it can be used in batchUpdate requests.
+// It also returns the tab's title.
+func (s *SheetsService) gridRange(ctx context.Context, spreadsheetId, a1 string) (*sheets.GridRange, string, error) {
+	title, gr, err := ParseA1(a1)
+	if err != nil {
+		return nil, "", err
+	}
+	p, err := s.ResolveSheet(ctx, spreadsheetId, title)
+	if err != nil {
+		return nil, "", err
+	}
+	gr.SheetId = p.SheetId
+	gr.ForceSendFields = []string{"SheetId"}
+	return gr, p.Title, nil
+}
+
+// AddSheet adds a new tab. rows and cols may be 0 to use the API defaults; index < 0 appends at the end.
+func (s *SheetsService) AddSheet(ctx context.Context, spreadsheetId, title string, rows, cols int64, index int64) (*sheets.SheetProperties, error) {
+	props := &sheets.SheetProperties{Title: title}
+	if rows > 0 || cols > 0 {
+		props.GridProperties = &sheets.GridProperties{RowCount: rows, ColumnCount: cols}
+	}
+	if index >= 0 {
+		props.Index = index
+		props.ForceSendFields = []string{"Index"}
+	}
+	resp, err := s.BatchUpdate(ctx, spreadsheetId, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{AddSheet: &sheets.AddSheetRequest{Properties: props}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Replies[0].AddSheet.Properties, nil
+}
+
+// DeleteSheet removes a tab by sheet ID.
+func (s *SheetsService) DeleteSheet(ctx context.Context, spreadsheetId string, sheetId int64) error {
+	_, err := s.BatchUpdate(ctx, spreadsheetId, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{DeleteSheet: &sheets.DeleteSheetRequest{SheetId: sheetId, ForceSendFields: []string{"SheetId"}}}},
+	})
+	return err
+}
+
+// RenameSheet changes the title of a tab.
+func (s *SheetsService) RenameSheet(ctx context.Context, spreadsheetId string, sheetId int64, title string) error {
+	_, err := s.BatchUpdate(ctx, spreadsheetId, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{UpdateSheetProperties: &sheets.UpdateSheetPropertiesRequest{
+			Properties: &sheets.SheetProperties{SheetId: sheetId, Title: title, ForceSendFields: []string{"SheetId"}},
+			Fields:     "title",
+		}}},
+	})
+	return err
+}
+
+// DuplicateSheet copies a tab within the spreadsheet. An empty newTitle lets the API pick
+// ("Copy of ..."); index < 0 places the copy at the end.
+func (s *SheetsService) DuplicateSheet(ctx context.Context, spreadsheetId string, sheetId int64, newTitle string, index int64) (*sheets.SheetProperties, error) {
+	dup := &sheets.DuplicateSheetRequest{SourceSheetId: sheetId, NewSheetName: newTitle, ForceSendFields: []string{"SourceSheetId"}}
+	if index >= 0 {
+		dup.InsertSheetIndex = index
+		dup.ForceSendFields = append(dup.ForceSendFields, "InsertSheetIndex")
+	}
+	resp, err := s.BatchUpdate(ctx, spreadsheetId, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{DuplicateSheet: dup}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Replies[0].DuplicateSheet.Properties, nil
+}
@@ -0,0 +1,37 @@
+package sheets
+
+import (
+	"testing"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+func TestFindSheet(t *testing.T) {
+	sp := &sheets.Spreadsheet{Sheets: []*sheets.Sheet{
+		{Properties: &sheets.SheetProperties{SheetId: 0, Title: "Summary"}},
+		{Properties: &sheets.SheetProperties{SheetId: 1234, Title: "Data"}},
+		{Properties: &sheets.SheetProperties{SheetId: 99, Title: "2024"}},
+	}}
+	tests := []struct {
+		ref    string
+		wantID int64
+		ok     bool
+	}{
+		{"Data", 1234, true},
+		{"data", 1234, true},
+		{"1234", 1234, true},
+		{"2024", 99, true}, // title wins over ID
+		{"0", 0, true},
+		{"Missing", 0, false},
+	}
+	for _, tt := range tests {
+		p, err := findSheet(sp, tt.ref)
+		if (err == nil) != tt.ok {
+			t.Errorf("findSheet(%q): unexpected error state: %v", tt.ref, err)
+			continue
+		}
+		if tt.ok && p.SheetId != tt.wantID {
+			t.Errorf("findSheet(%q): expected ID %d, got %d", tt.ref, tt.wantID, p.SheetId)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package tasks
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"google.golang.org/api/option"
 	tasksapi "google.golang.org/api/tasks/v1"
@@ -22,28 +23,78 @@ func New(ctx context.Context, opts ...option.ClientOption) (*Service, error) {
 	return &Service{srv: srv}, nil
 }
 
+// Ping makes a cheap request to check that the API is enabled and its scope granted.
+func (s *Service) Ping(ctx context.Context) error {
+	if _, err := s.srv.Tasklists.List().MaxResults(1).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("unable to list task lists: %w", err)
+	}
+	return nil
+}
+
 // ListTaskLists returns the authenticated user's task lists.
 // Call this first so the AI can pick the correct task list ID for subsequent operations.
-func (s *Service) ListTaskLists(maxResults int64) ([]*tasksapi.TaskList, error) {
+func (s *Service) ListTaskLists(ctx context.Context, maxResults int64) ([]*tasksapi.TaskList, error) {
 	if maxResults <= 0 {
 		maxResults = 100
 	}
-	resp, err := s.srv.Tasklists.List().MaxResults(maxResults).Do()
+	resp, err := s.srv.Tasklists.List().MaxResults(maxResults).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to list task lists: %w", err)
 	}
 	return resp.Items, nil
 }
 
+// CreateTaskList creates a new, empty task list.
+func (s *Service) CreateTaskList(ctx context.Context, title string) (*tasksapi.TaskList, error) {
+	if title == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+	l, err := s.srv.Tasklists.Insert(&tasksapi.TaskList{Title: title}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create task list: %w", err)
+	}
+	return l, nil
+}
+
+// RenameTaskList changes the title of a task list.
+func (s *Service) RenameTaskList(ctx context.Context, taskListID string, title string) (*tasksapi.TaskList, error) {
+	if taskListID == "" || title == "" {
+		return nil, fmt.Errorf("task_list_id and title are required")
+	}
+	l, err := s.srv.Tasklists.Patch(taskListID, &tasksapi.TaskList{Title: title}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to rename task list: %w", err)
+	}
+	return l, nil
+}
+
+// DeleteTaskList deletes a task list and all the tasks in it.
+func (s *Service) DeleteTaskList(ctx context.Context, taskListID string) error {
+	if taskListID == "" {
+		return fmt.Errorf("task_list_id is required")
+	}
+	if err := s.srv.Tasklists.Delete(taskListID).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("unable to delete task list: %w", err)
+	}
+	return nil
+}
+
 // ListTasksOptions configures how tasks are listed.
+// Bounds are RFC 3339 timestamps or YYYY-MM-DD dates; dates are inclusive on both ends.
 type ListTasksOptions struct {
-	ShowCompleted bool  // Include completed tasks (default: false to reduce output)
-	MaxResults    int64 // Max tasks per page (default: 20, max: 100)
+	ShowCompleted bool   // Include completed tasks (default: false to reduce output)
+	MaxResults    int64  // Max tasks per page (default: 20, max: 100)
+	PageToken     string // Continue from a previous response's NextPageToken
+	DueMin        string // Only tasks due on or after this
+	DueMax        string // Only tasks due on or before this
+	CompletedMin  string // Only tasks completed on or after this (implies ShowCompleted)
+	CompletedMax  string // Only tasks completed on or before this (implies ShowCompleted)
+	OverdueOnly   bool   // Only open tasks due before today
 }
 
-// ListTasks returns tasks in the given task list.
+// ListTasks returns a page of tasks in the given task list.
 // Use ShowCompleted: true to include completed tasks; false keeps output smaller.
-func (s *Service) ListTasks(taskListID string, opts ListTasksOptions) ([]*tasksapi.Task, error) {
+func (s *Service) ListTasks(ctx context.Context, taskListID string, opts ListTasksOptions) (*tasksapi.Tasks, error) {
 	if taskListID == "" {
 		return nil, fmt.Errorf("task_list_id is required")
 	}
@@ -53,20 +104,77 @@ func (s *Service) ListTasks(taskListID string, opts ListTasksOptions) ([]*tasksa
 	if opts.MaxResults > 100 {
 		opts.MaxResults = 100
 	}
+	// Lower bounds at even indexes, upper bounds at odd ones
+	for i, bound := range []*string{&opts.DueMin, &opts.DueMax, &opts.CompletedMin, &opts.CompletedMax} {
+		if *bound == "" {
+			continue
+		}
+		v, err := boundTime(*bound, i%2 == 1)
+		if err != nil {
+			return nil, err
+		}
+		*bound = v
+	}
+	if opts.OverdueOnly {
+		if opts.ShowCompleted || opts.CompletedMin != "" || opts.CompletedMax != "" {
+			return nil, fmt.Errorf("overdue_only lists open tasks only: it cannot be combined with completed tasks")
+		}
+		// Due dates are stored as midnight UTC, so today's date excludes tasks due today
+		today, _ := time.Parse(time.DateOnly, time.Now().Format(time.DateOnly))
+		if dueMax, err := time.Parse(time.RFC3339, opts.DueMax); err != nil || dueMax.After(today) {
+			opts.DueMax = today.Format(time.RFC3339)
+		}
+	}
+	if opts.CompletedMin != "" || opts.CompletedMax != "" {
+		opts.ShowCompleted = true
+	}
 
+	// Tasks completed in the Google Tasks apps are hidden, so they only show up with ShowHidden
 	call := s.srv.Tasks.List(taskListID).
 		ShowCompleted(opts.ShowCompleted).
+		ShowHidden(opts.ShowCompleted).
 		MaxResults(opts.MaxResults)
+	if opts.PageToken != "" {
+		call = call.PageToken(opts.PageToken)
+	}
+	if opts.DueMin != "" {
+		call = call.DueMin(opts.DueMin)
+	}
+	if opts.DueMax != "" {
+		call = call.DueMax(opts.DueMax)
+	}
+	if opts.CompletedMin != "" {
+		call = call.CompletedMin(opts.CompletedMin)
+	}
+	if opts.CompletedMax != "" {
+		call = call.CompletedMax(opts.CompletedMax)
+	}
 
-	resp, err := call.Do()
+	resp, err := call.Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to list tasks: %w", err)
 	}
-	return resp.Items, nil
+	return resp, nil
+}
+
+// boundTime converts a filter bound to the RFC 3339 timestamp the API expects. The API's upper bounds
+// are exclusive, so a date used as an upper bound becomes midnight of the next day to include it.
+func boundTime(v string, upper bool) (string, error) {
+	if _, err := time.Parse(time.RFC3339, v); err == nil {
+		return v, nil
+	}
+	d, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return "", fmt.Errorf("invalid date %q: use YYYY-MM-DD or an RFC 3339 timestamp", v)
+	}
+	if upper {
+		d = d.AddDate(0, 0, 1)
+	}
+	return d.Format(time.RFC3339), nil
 }
 
 // InsertTask creates a new task in the given task list.
-func (s *Service) InsertTask(taskListID string, title string, notes string, due string) (*tasksapi.Task, error) {
+func (s *Service) InsertTask(ctx context.Context, taskListID string, title string, notes string, due string) (*tasksapi.Task, error) {
 	if taskListID == "" {
 		return nil, fmt.Errorf("task_list_id is required")
 	}
@@ -82,7 +190,7 @@ func (s *Service) InsertTask(taskListID string, title string, notes string, due
 		task.Due = due
 	}
 
-	t, err := s.srv.Tasks.Insert(taskListID, task).Do()
+	t, err := s.srv.Tasks.Insert(taskListID, task).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to insert task: %w", err)
 	}
@@ -98,12 +206,12 @@ type UpdateTaskInput struct {
 }
 
 // UpdateTask updates an existing task. Only non-nil fields are applied.
-func (s *Service) UpdateTask(taskListID string, taskID string, in UpdateTaskInput) (*tasksapi.Task, error) {
+func (s *Service) UpdateTask(ctx context.Context, taskListID string, taskID string, in UpdateTaskInput) (*tasksapi.Task, error) {
 	if taskListID == "" || taskID == "" {
 		return nil, fmt.Errorf("task_list_id and task_id are required")
 	}
 
-	existing, err := s.srv.Tasks.Get(taskListID, taskID).Do()
+	existing, err := s.srv.Tasks.Get(taskListID, taskID).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to get task: %w", err)
 	}
@@ -121,17 +229,136 @@ func (s *Service) UpdateTask(taskListID string, taskID string, in UpdateTaskInpu
 		existing.Status = *in.Status
 	}
 
-	t, err := s.srv.Tasks.Update(taskListID, taskID, existing).Do()
+	t, err := s.srv.Tasks.Update(taskListID, taskID, existing).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to update task: %w", err)
 	}
 	return t, nil
 }
 
+// MoveTaskOptions says where MoveTask puts a task. Empty fields mean the top of the list.
+type MoveTaskOptions struct {
+	Parent              string // Make the task a subtask of this task
+	Previous            string // Put the task after this sibling task
+	DestinationTaskList string // Move the task to another task list
+}
+
+// MoveTask moves a task to another position: under a parent task (as a subtask), after a sibling, or
+// into another task list. With no parent the task becomes a top-level task; with no previous sibling it
+// becomes the first of its siblings.
+func (s *Service) MoveTask(ctx context.Context, taskListID string, taskID string, opts MoveTaskOptions) (*tasksapi.Task, error) {
+	if taskListID == "" || taskID == "" {
+		return nil, fmt.Errorf("task_list_id and task_id are required")
+	}
+	if opts.Parent == taskID || opts.Previous == taskID {
+		return nil, fmt.Errorf("a task cannot be moved under or after itself")
+	}
+	call := s.srv.Tasks.Move(taskListID, taskID)
+	if opts.Parent != "" {
+		call = call.Parent(opts.Parent)
+	}
+	if opts.Previous != "" {
+		call = call.Previous(opts.Previous)
+	}
+	if opts.DestinationTaskList != "" {
+		call = call.DestinationTasklist(opts.DestinationTaskList)
+	}
+	t, err := call.Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to move task: %w", err)
+	}
+	return t, nil
+}
+
 // DeleteTask removes a task from the task list.
-func (s *Service) DeleteTask(taskListID string, taskID string) error {
+func (s *Service) DeleteTask(ctx context.Context, taskListID string, taskID string) error {
 	if taskListID == "" || taskID == "" {
 		return fmt.Errorf("task_list_id and task_id are required")
 	}
-	return s.srv.Tasks.Delete(taskListID, taskID).Do()
+	return s.srv.Tasks.Delete(taskListID, taskID).Context(ctx).Do()
+}
+
+// BatchFailure records a task that could not be processed in a batch operation.
+type BatchFailure struct {
+	ID    string
+	Error string
+}
+
+// BatchResult reports which tasks a batch operation succeeded and failed on.
+type BatchResult struct {
+	Succeeded []string
+	Failed    []BatchFailure
+	Next      []*tasksapi.Task // Next occurrences created for repeating tasks
+}
+
+// repeatIntervals are the intervals CompleteTasks can repeat tasks at, as years, months and days.
+var repeatIntervals = map[string][3]int{
+	"daily":   {0, 0, 1},
+	"weekly":  {0, 0, 7},
+	"monthly": {0, 1, 0},
+	"yearly":  {1, 0, 0},
+}
+
+// nextDue returns the due date of a repeating task's next occurrence: one interval after its due date,
+// or after today if it had none.
+func nextDue(due, repeat string, now time.Time) (string, error) {
+	interval, ok := repeatIntervals[repeat]
+	if !ok {
+		return "", fmt.Errorf("invalid repeat %q: must be daily, weekly, monthly or yearly", repeat)
+	}
+	from, err := time.Parse(time.RFC3339, due)
+	if err != nil {
+		from, _ = time.Parse(time.DateOnly, now.Format(time.DateOnly))
+	}
+	return from.AddDate(interval[0], interval[1], interval[2]).Format(time.RFC3339), nil
+}
+
+// CompleteTasks marks many tasks as completed, one call per task, reporting failures individually.
+// The Tasks API has no recurrence, so with repeat ("daily", "weekly", "monthly" or "yearly") each
+// completed task gets a new open copy due one interval later, in the same place in the list.
+func (s *Service) CompleteTasks(ctx context.Context, taskListID string, taskIDs []string, repeat string) (*BatchResult, error) {
+	if taskListID == "" || len(taskIDs) == 0 {
+		return nil, fmt.Errorf("task_list_id and task_ids are required")
+	}
+	if repeat != "" {
+		if _, err := nextDue("", repeat, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	res := &BatchResult{}
+	for _, id := range taskIDs {
+		t, err := s.srv.Tasks.Patch(taskListID, id, &tasksapi.Task{Status: "completed"}).Context(ctx).Do()
+		if err != nil {
+			res.Failed = append(res.Failed, BatchFailure{ID: id, Error: err.Error()})
+			continue
+		}
+		res.Succeeded = append(res.Succeeded, id)
+		if repeat == "" {
+			continue
+		}
+		due, _ := nextDue(t.Due, repeat, time.Now())
+		call := s.srv.Tasks.Insert(taskListID, &tasksapi.Task{Title: t.Title, Notes: t.Notes, Due: due}).Previous(t.Id)
+		if t.Parent != "" {
+			call = call.Parent(t.Parent)
+		}
+		next, err := call.Context(ctx).Do()
+		if err != nil {
+			res.Failed = append(res.Failed, BatchFailure{ID: id, Error: "completed, but unable to create the next occurrence: " + err.Error()})
+			continue
+		}
+		res.Next = append(res.Next, next)
+	}
+	return res, nil
+}
+
+// ClearCompleted hides all completed tasks of a task list, as "Delete all completed tasks" does in the
+// Google Tasks apps. Hidden tasks can still be listed with ShowCompleted.
+func (s *Service) ClearCompleted(ctx context.Context, taskListID string) error {
+	if taskListID == "" {
+		return fmt.Errorf("task_list_id is required")
+	}
+	if err := s.srv.Tasks.Clear(taskListID).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("unable to clear completed tasks: %w", err)
+	}
+	return nil
 }
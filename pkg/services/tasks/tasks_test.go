@@ -0,0 +1,50 @@
+package tasks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBoundTime(t *testing.T) {
+	tests := []struct {
+		in      string
+		upper   bool
+		want    string
+		wantErr bool
+	}{
+		{"2025-02-03", false, "2025-02-03T00:00:00Z", false},
+		{"2025-02-09", true, "2025-02-10T00:00:00Z", false},
+		{"2025-02-28", true, "2025-03-01T00:00:00Z", false},
+		{"2025-02-03T15:04:05+01:00", true, "2025-02-03T15:04:05+01:00", false},
+		{"next week", false, "", true},
+	}
+	for _, tt := range tests {
+		got, err := boundTime(tt.in, tt.upper)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("boundTime(%q, %v) = %q, %v; want %q", tt.in, tt.upper, got, err, tt.want)
+		}
+	}
+}
+
+func TestNextDue(t *testing.T) {
+	now := time.Date(2025, 3, 14, 18, 30, 0, 0, time.UTC)
+	tests := []struct {
+		due     string
+		repeat  string
+		want    string
+		wantErr bool
+	}{
+		{"2025-01-31T00:00:00.000Z", "daily", "2025-02-01T00:00:00Z", false},
+		{"2025-01-31T00:00:00.000Z", "weekly", "2025-02-07T00:00:00Z", false},
+		{"2025-01-15T00:00:00.000Z", "monthly", "2025-02-15T00:00:00Z", false},
+		{"2024-02-29T00:00:00.000Z", "yearly", "2025-03-01T00:00:00Z", false},
+		{"", "weekly", "2025-03-21T00:00:00Z", false},
+		{"", "hourly", "", true},
+	}
+	for _, tt := range tests {
+		got, err := nextDue(tt.due, tt.repeat, now)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("nextDue(%q, %q) = %q, %v; want %q", tt.due, tt.repeat, got, err, tt.want)
+		}
+	}
+}
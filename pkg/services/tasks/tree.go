@@ -0,0 +1,47 @@
+package tasks
+
+import (
+	"sort"
+	"strconv"
+
+	tasksapi "google.golang.org/api/tasks/v1"
+)
+
+// OrderedTask is a task with its place in the list's hierarchy.
+type OrderedTask struct {
+	Task   *tasksapi.Task
+	Depth  int    // 0 for top-level tasks, 1 for their subtasks, ...
+	Number string // Outline number, e.g. "2" or "2.1"
+}
+
+// Ordered arranges tasks as they appear in Google Tasks: by position, each task followed by its subtasks.
+// Subtasks whose parent is not among tasks (e.g. on another page) are listed at the top level.
+func Ordered(tasks []*tasksapi.Task) []OrderedTask {
+	ids := make(map[string]bool, len(tasks))
+	for _, t := range tasks {
+		ids[t.Id] = true
+	}
+	children := make(map[string][]*tasksapi.Task)
+	for _, t := range tasks {
+		parent := t.Parent
+		if !ids[parent] {
+			parent = ""
+		}
+		children[parent] = append(children[parent], t)
+	}
+
+	var out []OrderedTask
+	var walk func(parent, prefix string, depth int)
+	walk = func(parent, prefix string, depth int) {
+		siblings := children[parent]
+		// Positions are zero-padded strings, so they sort lexically
+		sort.SliceStable(siblings, func(i, j int) bool { return siblings[i].Position < siblings[j].Position })
+		for i, t := range siblings {
+			number := prefix + strconv.Itoa(i+1)
+			out = append(out, OrderedTask{Task: t, Depth: depth, Number: number})
+			walk(t.Id, number+".", depth+1)
+		}
+	}
+	walk("", "", 0)
+	return out
+}
@@ -0,0 +1,37 @@
+package tasks
+
+import (
+	"testing"
+
+	tasksapi "google.golang.org/api/tasks/v1"
+)
+
+func TestOrdered(t *testing.T) {
+	tasks := []*tasksapi.Task{
+		{Id: "b", Position: "00000000000000000001"},
+		{Id: "b2", Parent: "b", Position: "00000000000000000001"},
+		{Id: "a", Position: "00000000000000000000"},
+		{Id: "b1", Parent: "b", Position: "00000000000000000000"},
+		{Id: "orphan", Parent: "elsewhere", Position: "00000000000000000002"},
+	}
+	want := []struct {
+		id     string
+		depth  int
+		number string
+	}{
+		{"a", 0, "1"},
+		{"b", 0, "2"},
+		{"b1", 1, "2.1"},
+		{"b2", 1, "2.2"},
+		{"orphan", 0, "3"},
+	}
+	got := Ordered(tasks)
+	if len(got) != len(want) {
+		t.Fatalf("got %d tasks, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		if got[i].Task.Id != w.id || got[i].Depth != w.depth || got[i].Number != w.number {
+			t.Errorf("task %d = %s depth %d number %s, want %s depth %d number %s", i, got[i].Task.Id, got[i].Depth, got[i].Number, w.id, w.depth, w.number)
+		}
+	}
+}
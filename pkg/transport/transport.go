@@ -0,0 +1,426 @@
+// Package transport provides the HTTP middleware shared by all Google API services: it retries
+// rate-limited and failed requests with exponential backoff, paces requests per API with token
+// buckets, and turns exhausted quotas into errors that say what to do about them.
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/matheusbuniotto/go-google-mcp/pkg/metrics"
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
+)
+
+// Limit is a token bucket rate limit. A zero PerSecond means unlimited.
+type Limit struct {
+	PerSecond float64
+	Burst     int
+}
+
+// DefaultLimits pace each API a little below the per-user quotas Google documents for it.
+var DefaultLimits = map[string]Limit{
+	"sheets": {PerSecond: 1, Burst: 10},   // 60 requests per minute per user
+	"people": {PerSecond: 1.5, Burst: 10}, // 90 requests per minute per user
+	"docs":   {PerSecond: 5, Burst: 10},   // 300 requests per minute per user
+	"gmail":  {PerSecond: 25, Burst: 50},  // 250 quota units per second per user
+	"":       {PerSecond: 10, Burst: 20},  // Any other API
+}
+
+// Config controls retries and rate limits. Zero fields use the defaults.
+type Config struct {
+	MaxRetries  int              // Retries after the first attempt (default 5)
+	BaseBackoff time.Duration    // First backoff, doubled on each retry (default 1s)
+	MaxBackoff  time.Duration    // Longest single wait, including Retry-After (default 32s)
+	Limits      map[string]Limit // Per-API limits keyed by API name (see apiName); "" is the fallback (default DefaultLimits)
+}
+
+// Transport is an http.RoundTripper that rate limits and retries requests to Google APIs.
+type Transport struct {
+	base http.RoundTripper
+	cfg  Config
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	tokens  map[string]bool // Authorization headers seen so far, to count token refreshes
+}
+
+// New wraps base (http.DefaultTransport if nil) in a Transport.
+func New(base http.RoundTripper, cfg Config) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = 5
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 32 * time.Second
+	}
+	if cfg.Limits == nil {
+		cfg.Limits = DefaultLimits
+	}
+	return &Transport{base: base, cfg: cfg, buckets: make(map[string]*bucket), tokens: make(map[string]bool)}
+}
+
+// ClientOptions returns client options that send every API call through a Transport,
+// keeping the authentication configured in opts.
+func ClientOptions(ctx context.Context, cfg Config, opts ...option.ClientOption) ([]option.ClientOption, error) {
+	rt, err := htransport.NewTransport(ctx, New(nil, cfg), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create API transport: %w", err)
+	}
+	return []option.ClientOption{option.WithHTTPClient(&http.Client{Transport: rt})}, nil
+}
+
+// apiName identifies the API a request goes to, e.g. "sheets" for sheets.googleapis.com and "drive"
+// for www.googleapis.com/drive/v3 (or /upload/drive/v3).
+func apiName(req *http.Request) string {
+	host := strings.TrimSuffix(req.URL.Hostname(), ".googleapis.com")
+	if host != "www" {
+		return host
+	}
+	parts := strings.Split(strings.TrimPrefix(req.URL.Path, "/"), "/")
+	if len(parts) > 1 && (parts[0] == "upload" || parts[0] == "batch") {
+		return parts[1]
+	}
+	return parts[0]
+}
+
+// apiHost returns the API's service name as used in the Cloud Console, e.g. "drive.googleapis.com".
+func apiHost(req *http.Request, api string) string {
+	if host := req.URL.Hostname(); strings.HasSuffix(host, ".googleapis.com") && !strings.HasPrefix(host, "www.") {
+		return host
+	}
+	return api + ".googleapis.com"
+}
+
+func (t *Transport) bucket(api string) *bucket {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b, ok := t.buckets[api]
+	if !ok {
+		limit, ok := t.cfg.Limits[api]
+		if !ok {
+			limit = t.cfg.Limits[""]
+		}
+		b = newBucket(limit)
+		t.buckets[api] = b
+	}
+	return b
+}
+
+// noteToken counts a token refresh when a request carries an access token not seen before. The first
+// token is counted too, as it was fetched (or refreshed from a stored refresh token) at startup. Tokens
+// are remembered rather than compared with the previous one so that requests alternating between
+// impersonated users are not counted as refreshes.
+func (t *Transport) noteToken(authorization string) {
+	if authorization == "" {
+		return
+	}
+	t.mu.Lock()
+	fresh := !t.tokens[authorization]
+	if fresh {
+		if len(t.tokens) >= 1000 {
+			clear(t.tokens)
+		}
+		t.tokens[authorization] = true
+	}
+	t.mu.Unlock()
+	if fresh {
+		metrics.TokenRefreshes.Inc()
+		slog.Debug("access token refreshed")
+	}
+}
+
+// idempotent reports whether a request can be repeated after a server error without side effects.
+// Rate limited requests were not processed, so they are retried whatever the method.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	api := apiName(req)
+	rewindable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	for attempt := 0; ; attempt++ {
+		if err := t.bucket(api).wait(ctx); err != nil {
+			return nil, err
+		}
+		r := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+		t.noteToken(r.Header.Get("Authorization"))
+		start := time.Now()
+		resp, err := t.base.RoundTrip(r)
+		metrics.APIDuration.Observe(time.Since(start), api)
+		if err != nil {
+			metrics.APIRequests.Inc(api, "error")
+			metrics.APIErrors.Inc(api, "error")
+			return nil, err
+		}
+		code := strconv.Itoa(resp.StatusCode)
+		metrics.APIRequests.Inc(api, code)
+		if resp.StatusCode >= 400 {
+			metrics.APIErrors.Inc(api, code)
+		}
+		slog.Debug("api request", "api", api, "method", req.Method, "path", req.URL.Path, "status", resp.StatusCode, "duration", time.Since(start).Round(time.Millisecond))
+
+		var apiErr *googleError
+		retry := false
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests:
+			apiErr, retry = readGoogleError(resp), true
+		case resp.StatusCode == http.StatusForbidden:
+			apiErr = readGoogleError(resp)
+			if apiErr.serviceDisabled() {
+				drainClose(resp)
+				return nil, &DisabledError{API: api, Host: apiHost(req, api), Message: apiErr.Message}
+			}
+			if apiErr.insufficientScope() || strings.Contains(resp.Header.Get("WWW-Authenticate"), "insufficient_scope") {
+				drainClose(resp)
+				return nil, &ScopeError{API: api, Message: apiErr.Message}
+			}
+			retry = apiErr.rateLimited()
+			if !retry && !apiErr.quotaExhausted() {
+				return resp, nil
+			}
+		case resp.StatusCode >= 500:
+			retry = idempotent(req.Method)
+		}
+		if apiErr == nil && !retry {
+			return resp, nil
+		}
+
+		wait := t.backoff(attempt, resp.Header.Get("Retry-After"))
+		if !retry || !rewindable || attempt >= t.cfg.MaxRetries || wait > t.cfg.MaxBackoff {
+			if apiErr != nil {
+				drainClose(resp)
+				return nil, &QuotaError{API: api, Status: resp.StatusCode, Reason: apiErr.reason(), Message: apiErr.Message, Retries: attempt, RetryAfter: wait}
+			}
+			return resp, nil
+		}
+		drainClose(resp)
+		metrics.APIRetries.Inc(api)
+		slog.Info("retrying api request", "api", api, "status", resp.StatusCode, "attempt", attempt+1, "wait", wait.Round(time.Millisecond))
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// backoff returns how long to wait before the next attempt: the server's Retry-After if it sent one,
+// otherwise BaseBackoff doubled on every attempt, plus up to 50% jitter, capped at MaxBackoff. Only a
+// Retry-After can exceed MaxBackoff, which makes RoundTrip give up rather than wait that long.
+func (t *Transport) backoff(attempt int, retryAfter string) time.Duration {
+	if d, ok := parseRetryAfter(retryAfter, time.Now()); ok {
+		return d
+	}
+	d := t.cfg.BaseBackoff << attempt
+	if d <= 0 || d > t.cfg.MaxBackoff {
+		d = t.cfg.MaxBackoff
+	}
+	return min(d+time.Duration(rand.Int64N(int64(d)/2+1)), t.cfg.MaxBackoff)
+}
+
+// parseRetryAfter parses a Retry-After header, in seconds or as an HTTP date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+func drainClose(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+}
+
+// googleError is the error body returned by Google APIs.
+type googleError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Status  string `json:"status"`
+	Errors  []struct {
+		Reason string `json:"reason"`
+	} `json:"errors"`
+}
+
+// readGoogleError decodes the error in resp and restores the body so the response can still be returned.
+func readGoogleError(resp *http.Response) *googleError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	var wrapper struct {
+		Error googleError `json:"error"`
+	}
+	_ = json.Unmarshal(body, &wrapper)
+	return &wrapper.Error
+}
+
+func (e *googleError) reason() string {
+	if len(e.Errors) > 0 && e.Errors[0].Reason != "" {
+		return e.Errors[0].Reason
+	}
+	return e.Status
+}
+
+// rateLimited reports whether a 403 is a short-term rate limit, which clears after a pause.
+func (e *googleError) rateLimited() bool {
+	switch e.reason() {
+	case "rateLimitExceeded", "userRateLimitExceeded", "RESOURCE_EXHAUSTED":
+		return true
+	}
+	return false
+}
+
+// quotaExhausted reports whether a 403 is a daily or project quota, which retrying does not fix.
+func (e *googleError) quotaExhausted() bool {
+	switch e.reason() {
+	case "quotaExceeded", "dailyLimitExceeded", "dailyLimitExceededUnreg":
+		return true
+	}
+	return false
+}
+
+// serviceDisabled reports whether a 403 means the API is not enabled in the Google Cloud project.
+func (e *googleError) serviceDisabled() bool {
+	switch e.reason() {
+	case "accessNotConfigured", "SERVICE_DISABLED":
+		return true
+	}
+	return strings.Contains(e.Message, "has not been used in project")
+}
+
+// DisabledError is returned when an API is not enabled in the Google Cloud project of the credentials.
+type DisabledError struct {
+	API     string
+	Host    string // Service name in the Cloud Console, e.g. "drive.googleapis.com"
+	Message string // Google's message
+}
+
+func (e *DisabledError) Error() string {
+	return fmt.Sprintf("%s API is not enabled (HTTP 403, accessNotConfigured): enable it at https://console.cloud.google.com/apis/library/%s for the project of your credentials, wait a minute and try again", e.API, e.Host)
+}
+
+// insufficientScope reports whether a 403 means the token was not granted the scope the API needs.
+func (e *googleError) insufficientScope() bool {
+	return e.reason() == "ACCESS_TOKEN_SCOPE_INSUFFICIENT" || strings.Contains(e.Message, "insufficient authentication scopes")
+}
+
+// ScopeError is returned when the credentials lack the OAuth scope an API needs, typically because the
+// service was added after logging in.
+type ScopeError struct {
+	API     string
+	Message string // Google's message
+}
+
+func (e *ScopeError) Error() string {
+	service := e.API
+	if service == "driveactivity" {
+		service = "activity"
+	}
+	return fmt.Sprintf("%s API denied access: the credentials lack its OAuth scope (HTTP 403, insufficient scopes). Grant it with 'go-google-mcp auth login --add-scopes %s' and restart the server; with domain-wide delegation, add the scope for the service account in the Admin console", e.API, service)
+}
+
+// QuotaError is returned when a request is still rate limited after retrying, or hits an exhausted quota.
+type QuotaError struct {
+	API        string
+	Status     int
+	Reason     string        // Google's reason, e.g. "rateLimitExceeded" or "quotaExceeded"
+	Message    string        // Google's message
+	Retries    int           // Retries made before giving up
+	RetryAfter time.Duration // Suggested wait before trying again
+}
+
+func (e *QuotaError) Error() string {
+	s := fmt.Sprintf("%s API quota exceeded (HTTP %d", e.API, e.Status)
+	if e.Reason != "" {
+		s += ", " + e.Reason
+	}
+	s += ")"
+	if e.Message != "" {
+		s += ": " + e.Message
+	}
+	switch {
+	case e.Reason == "quotaExceeded" || strings.HasPrefix(e.Reason, "dailyLimitExceeded"):
+		s += ". The daily quota is used up: it resets at midnight Pacific time, or can be raised in the Google Cloud Console (APIs & Services > Quotas)."
+	default:
+		s += fmt.Sprintf(". Still rate limited after %d retries: wait about %s and try again with fewer requests at once, or raise the quota in the Google Cloud Console (APIs & Services > Quotas).", e.Retries, e.RetryAfter.Round(time.Second))
+	}
+	return s
+}
+
+// bucket is a token bucket: it holds up to Burst tokens, refilled at PerSecond, and each request takes one.
+type bucket struct {
+	mu     sync.Mutex
+	limit  Limit
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(limit Limit) *bucket {
+	return &bucket{limit: limit, tokens: float64(max(limit.Burst, 1)), last: time.Now()}
+}
+
+// take takes a token if one is available at now, or returns how long until one is.
+func (b *bucket) take(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.limit.PerSecond <= 0 {
+		return 0
+	}
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*b.limit.PerSecond, float64(max(b.limit.Burst, 1)))
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.limit.PerSecond * float64(time.Second))
+}
+
+// wait blocks until a token is available or ctx is done.
+func (b *bucket) wait(ctx context.Context) error {
+	for {
+		d := b.take(time.Now())
+		if d == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d):
+		}
+	}
+}
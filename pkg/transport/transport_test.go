@@ -0,0 +1,192 @@
+package transport
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// server replies with the given statuses in turn, then 200.
+func server(t *testing.T, statuses []int, body string) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1)) - 1
+		if n < len(statuses) {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(statuses[n])
+			_, _ = io.WriteString(w, body)
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func testClient() *http.Client {
+	return &http.Client{Transport: New(nil, Config{MaxRetries: 2, BaseBackoff: time.Millisecond, Limits: map[string]Limit{}})}
+}
+
+func TestRetries(t *testing.T) {
+	const rateLimited = `{"error": {"code": 403, "message": "Rate Limit Exceeded", "errors": [{"reason": "userRateLimitExceeded"}]}}`
+	tests := []struct {
+		name      string
+		method    string
+		statuses  []int
+		body      string
+		wantCalls int32
+		wantCode  int
+		wantQuota string // Expected QuotaError reason, if any
+	}{
+		{name: "429 then ok", method: "POST", statuses: []int{429}, wantCalls: 2, wantCode: 200},
+		{name: "503 on GET", method: "GET", statuses: []int{503, 503}, wantCalls: 3, wantCode: 200},
+		{name: "500 on POST not retried", method: "POST", statuses: []int{500}, wantCalls: 1, wantCode: 500},
+		{name: "403 rate limit", method: "GET", statuses: []int{403}, body: rateLimited, wantCalls: 2, wantCode: 200},
+		{name: "403 permission", method: "GET", statuses: []int{403}, body: `{"error": {"code": 403, "errors": [{"reason": "insufficientPermissions"}]}}`, wantCalls: 1, wantCode: 403},
+		{name: "403 daily quota", method: "GET", statuses: []int{403}, body: `{"error": {"code": 403, "errors": [{"reason": "quotaExceeded"}]}}`, wantCalls: 1, wantQuota: "quotaExceeded"},
+		{name: "rate limited too long", method: "GET", statuses: []int{403, 403, 403}, body: rateLimited, wantCalls: 3, wantQuota: "userRateLimitExceeded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, calls := server(t, tt.statuses, tt.body)
+			req, _ := http.NewRequest(tt.method, srv.URL, strings.NewReader("payload"))
+			resp, err := testClient().Do(req)
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+			if tt.wantQuota != "" {
+				var qe *QuotaError
+				if !errors.As(err, &qe) || qe.Reason != tt.wantQuota {
+					t.Fatalf("err = %v, want QuotaError %s", err, tt.wantQuota)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+			if resp.StatusCode != tt.wantCode {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantCode)
+			}
+			if tt.wantCode == 403 {
+				if b, _ := io.ReadAll(resp.Body); !strings.Contains(string(b), "insufficientPermissions") {
+					t.Errorf("body not preserved: %q", b)
+				}
+			}
+		})
+	}
+}
+
+func TestRetriesAtMaxBackoff(t *testing.T) {
+	// Without Retry-After the backoff grows past MaxBackoff after the first retry; every retry must
+	// still be made.
+	const failures = 4
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	t.Cleanup(srv.Close)
+
+	client := &http.Client{Transport: New(nil, Config{MaxRetries: failures, BaseBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond, Limits: map[string]Limit{}})}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("err = %v after %d calls, want all %d retries made", err, calls.Load(), failures)
+	}
+	_ = resp.Body.Close()
+	if got := calls.Load(); got != failures+1 || resp.StatusCode != http.StatusOK {
+		t.Errorf("calls = %d, status = %d, want %d calls ending in 200", got, resp.StatusCode, failures+1)
+	}
+}
+
+func TestDisabledAPI(t *testing.T) {
+	srv, calls := server(t, []int{403}, `{"error": {"code": 403, "message": "Google Keep API has not been used in project 123 before or it is disabled.", "status": "PERMISSION_DENIED", "errors": [{"reason": "accessNotConfigured"}]}}`)
+	req, _ := http.NewRequest("GET", srv.URL+"/v1/notes", nil)
+	_, err := testClient().Do(req)
+	var de *DisabledError
+	if !errors.As(err, &de) {
+		t.Fatalf("err = %v, want DisabledError", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1 (not retried)", calls.Load())
+	}
+	if !strings.Contains(err.Error(), "https://console.cloud.google.com/apis/library/") {
+		t.Errorf("error does not say where to enable the API: %v", err)
+	}
+}
+
+func TestInsufficientScope(t *testing.T) {
+	srv, calls := server(t, []int{403}, `{"error": {"code": 403, "message": "Request had insufficient authentication scopes.", "status": "PERMISSION_DENIED", "details": [{"reason": "ACCESS_TOKEN_SCOPE_INSUFFICIENT"}]}}`)
+	req, _ := http.NewRequest("GET", srv.URL+"/v1/notes", nil)
+	_, err := testClient().Do(req)
+	var se *ScopeError
+	if !errors.As(err, &se) {
+		t.Fatalf("err = %v, want ScopeError", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1 (not retried)", calls.Load())
+	}
+	if !strings.Contains(err.Error(), "auth login --add-scopes") {
+		t.Errorf("error does not say how to grant the scope: %v", err)
+	}
+}
+
+func TestAPIName(t *testing.T) {
+	for url, want := range map[string]string{
+		"https://sheets.googleapis.com/v4/spreadsheets/x":       "sheets",
+		"https://www.googleapis.com/drive/v3/files":             "drive",
+		"https://www.googleapis.com/upload/drive/v3/files":      "drive",
+		"https://www.googleapis.com/calendar/v3/calendars/x":    "calendar",
+		"https://gmail.googleapis.com/gmail/v1/users/me/labels": "gmail",
+	} {
+		req, _ := http.NewRequest("GET", url, nil)
+		if got := apiName(req); got != want {
+			t.Errorf("apiName(%s) = %q, want %q", url, got, want)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"", 0, false},
+		{"7", 7 * time.Second, true},
+		{"Wed, 01 May 2024 12:00:30 GMT", 30 * time.Second, true},
+		{"Wed, 01 May 2024 11:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := parseRetryAfter(tt.in, now); got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestBucket(t *testing.T) {
+	b := newBucket(Limit{PerSecond: 2, Burst: 2})
+	now := b.last
+	if b.take(now) != 0 || b.take(now) != 0 {
+		t.Fatal("burst should be available immediately")
+	}
+	if d := b.take(now); d != 500*time.Millisecond {
+		t.Errorf("wait = %v, want 500ms", d)
+	}
+	if d := b.take(now.Add(500 * time.Millisecond)); d != 0 {
+		t.Errorf("wait after refill = %v, want 0", d)
+	}
+	if d := newBucket(Limit{}).take(now); d != 0 {
+		t.Errorf("unlimited bucket waited %v", d)
+	}
+}